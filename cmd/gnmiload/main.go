@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// The gnmiload command opens parallel gNMI subscriptions to generate
+// synthetic load on gNMI targets and reports the aggregate throughput,
+// latency and memory usage, for benchmarking.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aristanetworks/goarista/gnmi"
+
+	"github.com/aristanetworks/glog"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func main() {
+	numSubs := flag.Int("n", 1, "Number of parallel subscriptions per address")
+	duration := flag.Duration("duration", 0,
+		"Duration of the load test (0 to run until interrupted)")
+	reportInterval := flag.Duration("report_interval", 10*time.Second,
+		"Interval between reports")
+	mode := flag.String("mode", "stream", "Subscription mode (stream | once | poll)")
+	streamMode := flag.String("stream_mode", "target_defined",
+		"Stream mode of the subscriptions (target_defined | sample | on_change)")
+	sampleInterval := flag.Duration("sample_interval", 0,
+		"Sample interval of the subscriptions in sample stream mode")
+	origin := flag.String("origin", "", "Origin of the subscription paths")
+	cfg, paths := gnmi.ParseFlags()
+	if err := cfg.LoadCredentials(); err != nil {
+		glog.Fatal(err)
+	}
+
+	if len(paths) == 0 || paths[0] == "" {
+		glog.Fatal("You need to specify paths to subscribe to using -subscribe")
+	}
+	addrs, err := gnmi.ParseHostnames(cfg.Addr)
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), *duration)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigc
+		cancel()
+	}()
+
+	subscribeOptions := &gnmi.SubscribeOptions{
+		Mode:           *mode,
+		StreamMode:     *streamMode,
+		SampleInterval: uint64(*sampleInterval),
+		Paths:          gnmi.SplitPaths(paths),
+		Origin:         *origin,
+	}
+	stats := newLoadStats()
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		addrCfg := *cfg
+		addrCfg.Addr = addr
+		client, err := gnmi.Dial(&addrCfg)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		subCtx := gnmi.NewContext(ctx, &addrCfg)
+		for i := 0; i < *numSubs; i++ {
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				subscribe(subCtx, client, subscribeOptions, stats, addr)
+			}(addr)
+		}
+	}
+
+	go stats.report(ctx, os.Stdout, *reportInterval)
+	wg.Wait()
+	stats.writeSummary(os.Stdout)
+}
+
+// subscribe runs one subscription until ctx is done, recording the responses
+// received in stats.
+func subscribe(ctx context.Context, client pb.GNMIClient, subscribeOptions *gnmi.SubscribeOptions,
+	stats *loadStats, addr string) {
+	stats.subscriptionStarted()
+	respChan := make(chan *pb.SubscribeResponse)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for resp := range respChan {
+			stats.record(resp, time.Now())
+		}
+	}()
+	err := gnmi.SubscribeErr(ctx, client, subscribeOptions, respChan)
+	<-done
+	if err != nil && ctx.Err() == nil {
+		glog.Errorf("Subscription to %s failed: %s", addr, err)
+		stats.subscriptionFailed()
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// loadStats aggregates the statistics of all the subscriptions.
+type loadStats struct {
+	start time.Time
+
+	mu            sync.Mutex
+	subscriptions int
+	failed        int
+	notifs        uint64
+	updates       uint64
+	// latencies are the latencies recorded since the last report.
+	latencies latencySample
+	// allLatencies are the latencies recorded since the start.
+	allLatencies latencySample
+}
+
+// maxLatencySamples is the number of latencies kept for the percentiles.
+const maxLatencySamples = 100000
+
+// latencySample is a uniform random sample of the latencies recorded,
+// kept with reservoir sampling so that its size stays bounded by
+// maxLatencySamples however long the load runs.
+type latencySample struct {
+	latencies []time.Duration
+	// recorded is the number of latencies recorded.
+	recorded uint64
+}
+
+func (l *latencySample) add(latency time.Duration) {
+	l.recorded++
+	if len(l.latencies) < maxLatencySamples {
+		l.latencies = append(l.latencies, latency)
+		return
+	}
+	if i := rand.Int63n(int64(l.recorded)); i < maxLatencySamples {
+		l.latencies[i] = latency
+	}
+}
+
+func newLoadStats() *loadStats {
+	return &loadStats{start: time.Now()}
+}
+
+func (s *loadStats) subscriptionStarted() {
+	s.mu.Lock()
+	s.subscriptions++
+	s.mu.Unlock()
+}
+
+func (s *loadStats) subscriptionFailed() {
+	s.mu.Lock()
+	s.failed++
+	s.mu.Unlock()
+}
+
+// record records resp, received at now. The latency of a notification is
+// the difference between now and its timestamp.
+func (s *loadStats) record(resp *pb.SubscribeResponse, now time.Time) {
+	notif := resp.GetUpdate()
+	if notif == nil {
+		return
+	}
+	latency := now.Sub(time.Unix(0, notif.GetTimestamp()))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifs++
+	s.updates += uint64(len(notif.GetUpdate()) + len(notif.GetDelete()))
+	s.latencies.add(latency)
+	s.allLatencies.add(latency)
+}
+
+// report writes the statistics to w every interval until ctx is done.
+func (s *loadStats) report(ctx context.Context, w io.Writer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastNotifs, lastUpdates uint64
+	lastTime := s.start
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			s.mu.Lock()
+			notifs, updates := s.notifs, s.updates
+			active := s.subscriptions - s.failed
+			latencies := s.latencies.latencies
+			s.latencies = latencySample{}
+			s.mu.Unlock()
+
+			since := t.Sub(lastTime).Seconds()
+			fmt.Fprintf(w, "%s: %d subscriptions %.1f notifs/s %.1f updates/s %s %s\n",
+				t.Format(time.RFC3339), active,
+				float64(notifs-lastNotifs)/since, float64(updates-lastUpdates)/since,
+				latencySummary(latencies), memSummary())
+			lastNotifs, lastUpdates, lastTime = notifs, updates, t
+		}
+	}
+}
+
+// writeSummary writes the statistics since the start to w.
+func (s *loadStats) writeSummary(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := time.Since(s.start)
+	latencies := slices.Clone(s.allLatencies.latencies)
+	fmt.Fprintf(w, "total: %d subscriptions (%d failed) %d notifs %d updates in %s "+
+		"%.1f notifs/s %.1f updates/s %s %s\n",
+		s.subscriptions, s.failed, s.notifs, s.updates, elapsed.Round(time.Millisecond),
+		float64(s.notifs)/elapsed.Seconds(), float64(s.updates)/elapsed.Seconds(),
+		latencySummary(latencies), memSummary())
+}
+
+// latencySummary returns the p50 and p99 of latencies. It sorts latencies.
+func latencySummary(latencies []time.Duration) string {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return fmt.Sprintf("latency_p50=%s latency_p99=%s",
+		percentile(latencies, 50), percentile(latencies, 99))
+}
+
+// percentile returns the p-th percentile of sorted, using the nearest-rank
+// method, or 0 if sorted is empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func memSummary() string {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return fmt.Sprintf("heap_alloc_mib=%.1f sys_mib=%.1f",
+		float64(m.HeapAlloc)/(1<<20), float64(m.Sys)/(1<<20))
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	for _, tc := range []struct {
+		sorted []time.Duration
+		p      float64
+		exp    time.Duration
+	}{
+		{sorted: nil, p: 50, exp: 0},
+		{sorted: sorted[:1], p: 99, exp: time.Millisecond},
+		{sorted: sorted, p: 50, exp: 50 * time.Millisecond},
+		{sorted: sorted, p: 99, exp: 99 * time.Millisecond},
+		{sorted: sorted, p: 100, exp: 100 * time.Millisecond},
+		{sorted: sorted[:10], p: 99, exp: 10 * time.Millisecond},
+	} {
+		if got := percentile(tc.sorted, tc.p); got != tc.exp {
+			t.Errorf("p%v of %d latencies: expected %s, got %s", tc.p, len(tc.sorted), tc.exp, got)
+		}
+	}
+}
+
+func TestLoadStats(t *testing.T) {
+	s := newLoadStats()
+	s.subscriptionStarted()
+	s.subscriptionStarted()
+	s.subscriptionFailed()
+	now := time.Unix(1000, 0)
+	for i := 1; i <= 4; i++ {
+		s.record(&pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{
+			Update: &pb.Notification{
+				Timestamp: now.Add(-time.Duration(i) * time.Second).UnixNano(),
+				Update:    []*pb.Update{{}, {}},
+				Delete:    []*pb.Path{{}},
+			}}}, now)
+	}
+	s.record(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true}}, now)
+
+	var buf bytes.Buffer
+	s.writeSummary(&buf)
+	for _, exp := range []string{
+		"2 subscriptions (1 failed)",
+		" 4 notifs 12 updates ",
+		"latency_p50=2s latency_p99=4s",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected %q in summary, got %q", exp, buf.String())
+		}
+	}
+}
+
+func TestLatencySample(t *testing.T) {
+	var l latencySample
+	for i := 0; i < 3*maxLatencySamples; i++ {
+		l.add(time.Duration(i))
+	}
+	if len(l.latencies) != maxLatencySamples || l.recorded != 3*maxLatencySamples {
+		t.Fatalf("expected %d of %d latencies, got %d of %d", maxLatencySamples,
+			3*maxLatencySamples, len(l.latencies), l.recorded)
+	}
+	// The sample is uniform, so about two thirds of it are later latencies.
+	later := 0
+	for _, d := range l.latencies {
+		if d >= maxLatencySamples {
+			later++
+		}
+	}
+	if later < maxLatencySamples*6/10 || later > maxLatencySamples*7/10 {
+		t.Errorf("expected about 2/3 of the sample to be later latencies, got %d", later)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	Timestamp string           `xml:"timestamp,attr,omitempty"`
+	Cases     []*junitTestCase `xml:"testcase"`
+	SystemOut *junitOutput     `xml:"system-out,omitempty"`
+
+	failed bool
+	output []string
+	cases  map[string]*junitTestCase
+}
+
+type junitTestCase struct {
+	ClassName string       `xml:"classname,attr"`
+	Name      string       `xml:"name,attr"`
+	Time      string       `xml:"time,attr"`
+	Failure   *junitResult `xml:"failure,omitempty"`
+	Skipped   *junitResult `xml:"skipped,omitempty"`
+
+	output []string
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Output  string `xml:",cdata"`
+}
+
+type junitOutput struct {
+	Output string `xml:",cdata"`
+}
+
+// junitReport converts test events into a JUnit XML report, with a
+// test suite per package and a test case per test.
+type junitReport struct {
+	suites []*junitTestSuite
+	byPkg  map[string]*junitTestSuite
+}
+
+func newJUnitReport() *junitReport {
+	return &junitReport{byPkg: map[string]*junitTestSuite{}}
+}
+
+func formatElapsed(elapsed float64) string {
+	return fmt.Sprintf("%.3f", elapsed)
+}
+
+func (r *junitReport) add(e testEvent) {
+	s, ok := r.byPkg[e.Package]
+	if !ok {
+		s = &junitTestSuite{
+			Name:  e.Package,
+			Time:  formatElapsed(0),
+			cases: map[string]*junitTestCase{},
+		}
+		if !e.Time.IsZero() {
+			s.Timestamp = e.Time.Format("2006-01-02T15:04:05")
+		}
+		r.byPkg[e.Package] = s
+		r.suites = append(r.suites, s)
+	}
+	if e.Test == "" {
+		switch e.Action {
+		case "output":
+			s.output = append(s.output, e.Output)
+		case "pass", "skip":
+			s.Time = formatElapsed(e.Elapsed)
+		case "fail":
+			s.Time = formatElapsed(e.Elapsed)
+			s.failed = true
+		}
+		return
+	}
+	c, ok := s.cases[e.Test]
+	if !ok {
+		c = &junitTestCase{ClassName: e.Package, Name: e.Test, Time: formatElapsed(0)}
+		s.cases[e.Test] = c
+		s.Cases = append(s.Cases, c)
+	}
+	switch e.Action {
+	case "output":
+		c.output = append(c.output, e.Output)
+	case "pass":
+		c.Time = formatElapsed(e.Elapsed)
+	case "fail":
+		c.Time = formatElapsed(e.Elapsed)
+		c.Failure = &junitResult{Message: "Failed", Output: strings.Join(c.output, "")}
+	case "skip":
+		c.Time = formatElapsed(e.Elapsed)
+		c.Skipped = &junitResult{Message: "Skipped", Output: strings.Join(c.output, "")}
+	}
+}
+
+// write writes the report as JUnit XML to w.
+func (r *junitReport) write(w io.Writer) error {
+	for _, s := range r.suites {
+		s.Tests, s.Failures, s.Skipped = len(s.Cases), 0, 0
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				s.Failures++
+			} else if c.Skipped != nil {
+				s.Skipped++
+			}
+		}
+		if s.failed && s.Failures == 0 {
+			// The package failed without any failing test, e.g. because
+			// it did not build or exited early: report it as a failed
+			// test case so that the failure is not lost.
+			s.Cases = append(s.Cases, &junitTestCase{
+				ClassName: s.Name,
+				Name:      s.Name,
+				Time:      s.Time,
+				Failure: &junitResult{
+					Message: "Failed",
+					Output:  strings.Join(s.output, ""),
+				},
+			})
+			s.Tests++
+			s.Failures++
+		}
+		if len(s.output) > 0 {
+			s.SystemOut = &junitOutput{Output: strings.Join(s.output, "")}
+		}
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(junitTestSuites{Suites: r.suites}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/kylelemons/godebug/diff"
+)
+
+func TestJUnitReport(t *testing.T) {
+	input, err := os.Open("testdata/input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+	cfg := config{junit: newJUnitReport()}
+	if err := writeTestOutput(input, io.Discard, cfg); err != errTestFailure {
+		t.Error("expected test failure")
+	}
+	var out bytes.Buffer
+	if err := cfg.junit.write(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile("testdata/gold-junit.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Error("output does not match testdata/gold-junit.xml")
+		t.Error("\n" + diff.Diff(string(expected), out.String()))
+	}
+}
+
+func TestJUnitReportPackageFailure(t *testing.T) {
+	r := newJUnitReport()
+	for _, e := range []testEvent{
+		{Action: "output", Package: "pkg/broken", Output: "build failed\n"},
+		{Action: "fail", Package: "pkg/broken", Elapsed: 0.5},
+	} {
+		r.add(e)
+	}
+	var out bytes.Buffer
+	if err := r.write(&out); err != nil {
+		t.Fatal(err)
+	}
+	s := r.suites[0]
+	if s.Tests != 1 || s.Failures != 1 {
+		t.Fatalf("expected 1 failed test, got tests=%d failures=%d", s.Tests, s.Failures)
+	}
+	if c := s.Cases[0]; c.Name != "pkg/broken" || c.Failure == nil ||
+		c.Failure.Output != "build failed\n" || c.Time != "0.500" {
+		t.Errorf("unexpected test case: %+v", c)
+	}
+}
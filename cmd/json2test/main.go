@@ -7,6 +7,12 @@
 // analyze go test -json output, but still want a human readable test
 // log.
 //
+// With -summary, a trailer with the number of passed, failed and
+// skipped tests of each package is written at the end of the output.
+// With -failures-only, only the output of the failing tests is written.
+// With -junit, a JUnit XML report of the test results is also written to
+// the given file.
+//
 // Usage:
 //
 //	go test -json > out.txt; <analysis program> out.txt; cat out.txt | json2test
@@ -27,10 +33,24 @@ import (
 var errTestFailure = errors.New("testfailure")
 
 func main() {
-	verbose := flag.Bool("v", false, "Verbose output. "+
+	var cfg config
+	flag.BoolVar(&cfg.verbose, "v", false, "Verbose output. "+
 		"By default only failed tests emit verbose output in test result summary.")
+	flag.BoolVar(&cfg.summary, "summary", false,
+		"Write the number of passed, failed and skipped tests of each package at the end")
+	flag.BoolVar(&cfg.failuresOnly, "failures-only", false,
+		"Only write the output of failed tests. Takes precedence over -v.")
+	junitFile := flag.String("junit", "", "Also write a JUnit XML report to this file")
 	flag.Parse()
-	err := writeTestOutput(os.Stdin, os.Stdout, *verbose)
+	if *junitFile != "" {
+		cfg.junit = newJUnitReport()
+	}
+	err := writeTestOutput(os.Stdin, os.Stdout, cfg)
+	if cfg.junit != nil {
+		if jerr := writeJUnitFile(*junitFile, cfg.junit); jerr != nil {
+			log.Fatal(jerr)
+		}
+	}
 	if err == errTestFailure {
 		os.Exit(1)
 	} else if err != nil {
@@ -38,6 +58,25 @@ func main() {
 	}
 }
 
+type config struct {
+	verbose      bool
+	summary      bool
+	failuresOnly bool
+	junit        *junitReport
+}
+
+func writeJUnitFile(path string, r *junitReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 type testEvent struct {
 	Time    time.Time // encodes as an RFC3339-format string
 	Action  string
@@ -65,9 +104,59 @@ type testFailure struct {
 	o outputBuffer
 }
 
-func writeTestOutput(in io.Reader, out io.Writer, verbose bool) error {
+// packageCounts holds the number of tests of a package per result.
+type packageCounts struct {
+	pass, fail, skip int
+}
+
+// summary counts the test results of each package, in the order the
+// packages are first seen.
+type summary struct {
+	pkgs   []string
+	counts map[string]*packageCounts
+}
+
+func (s *summary) add(e testEvent) {
+	c, ok := s.counts[e.Package]
+	if !ok {
+		c = &packageCounts{}
+		s.counts[e.Package] = c
+		s.pkgs = append(s.pkgs, e.Package)
+	}
+	if e.Test == "" {
+		return
+	}
+	switch e.Action {
+	case "pass":
+		c.pass++
+	case "fail":
+		c.fail++
+	case "skip":
+		c.skip++
+	}
+}
+
+func (s *summary) write(w *bufio.Writer) {
+	var total packageCounts
+	w.WriteString("Test summary:\n")
+	for _, pkg := range s.pkgs {
+		c := s.counts[pkg]
+		fmt.Fprintf(w, "%s\tpass=%d\tfail=%d\tskip=%d\n", pkg, c.pass, c.fail, c.skip)
+		total.pass += c.pass
+		total.fail += c.fail
+		total.skip += c.skip
+	}
+	fmt.Fprintf(w, "total\tpass=%d\tfail=%d\tskip=%d\n", total.pass, total.fail, total.skip)
+}
+
+func writeTestOutput(in io.Reader, out io.Writer, cfg config) error {
 	testOutputBuffer := map[test]*outputBuffer{}
 	var failures []testFailure
+	sum := summary{counts: map[string]*packageCounts{}}
+	// In failures-only mode nothing but the output of failed tests is
+	// written, so neither the verbose output nor the results are.
+	verbose := cfg.verbose && !cfg.failuresOnly
+	results := !verbose && !cfg.failuresOnly
 	d := json.NewDecoder(in)
 
 	buf := bufio.NewWriter(out)
@@ -77,14 +166,21 @@ func writeTestOutput(in io.Reader, out io.Writer, verbose bool) error {
 		if err := d.Decode(&e); err != nil {
 			break
 		}
+		if cfg.junit != nil {
+			cfg.junit.add(e)
+		}
 
 		switch e.Action {
 		default:
 			continue
 		case "run":
 			testOutputBuffer[test{pkg: e.Package, test: e.Test}] = new(outputBuffer)
+		case "skip":
+			sum.add(e)
+			delete(testOutputBuffer, test{pkg: e.Package, test: e.Test})
 		case "pass":
-			if !verbose && e.Test == "" {
+			sum.add(e)
+			if results && e.Test == "" {
 				// Match go test output:
 				// 	ok  	foo/bar	2.109s
 				fmt.Fprintf(buf, "ok  \t%s\t%.3fs\n", e.Package, e.Elapsed)
@@ -92,7 +188,8 @@ func writeTestOutput(in io.Reader, out io.Writer, verbose bool) error {
 			// Don't hold onto text for passing
 			delete(testOutputBuffer, test{pkg: e.Package, test: e.Test})
 		case "fail":
-			if !verbose {
+			sum.add(e)
+			if results {
 				if e.Test != "" {
 					// Match go test output:
 					// 	--- FAIL: TestFooBar (0.00s)
@@ -124,18 +221,28 @@ func writeTestOutput(in io.Reader, out io.Writer, verbose bool) error {
 			}
 		}
 	}
-	if len(failures) == 0 {
-		return nil
-	}
-	buf.WriteString("\nTest failures:\n")
-	for i, f := range failures {
-		fmt.Fprintf(buf, "[%d] %s.%s\n", i+1, f.t.pkg, f.t.test)
-		for _, s := range f.o.output {
-			buf.WriteString(s)
+	var err error
+	if len(failures) > 0 {
+		err = errTestFailure
+		if !cfg.failuresOnly {
+			buf.WriteByte('\n')
 		}
-		if i < len(failures)-1 {
+		buf.WriteString("Test failures:\n")
+		for i, f := range failures {
+			fmt.Fprintf(buf, "[%d] %s.%s\n", i+1, f.t.pkg, f.t.test)
+			for _, s := range f.o.output {
+				buf.WriteString(s)
+			}
+			if i < len(failures)-1 {
+				buf.WriteByte('\n')
+			}
+		}
+	}
+	if cfg.summary {
+		if !cfg.failuresOnly || len(failures) > 0 {
 			buf.WriteByte('\n')
 		}
+		sum.write(buf)
 	}
-	return errTestFailure
+	return err
 }
@@ -15,20 +15,29 @@ import (
 
 func TestWriteTestOutput(t *testing.T) {
 	for name, tc := range map[string]struct {
-		verbose   bool
+		cfg       config
 		inputFile string
 		goldFile  string
 	}{
 		"quiet": {
-			verbose:   false,
 			inputFile: "testdata/input.txt",
 			goldFile:  "testdata/gold-quiet.txt",
 		},
 		"verbose": {
-			verbose:   true,
+			cfg:       config{verbose: true},
 			inputFile: "testdata/input.txt",
 			goldFile:  "testdata/gold-verbose.txt",
 		},
+		"summary": {
+			cfg:       config{summary: true},
+			inputFile: "testdata/input.txt",
+			goldFile:  "testdata/gold-summary.txt",
+		},
+		"failures-only": {
+			cfg:       config{verbose: true, failuresOnly: true, summary: true},
+			inputFile: "testdata/input.txt",
+			goldFile:  "testdata/gold-failures-only.txt",
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			input, err := os.Open(tc.inputFile)
@@ -36,7 +45,7 @@ func TestWriteTestOutput(t *testing.T) {
 				t.Fatal(err)
 			}
 			var out bytes.Buffer
-			if err := writeTestOutput(input, &out, tc.verbose); err != errTestFailure {
+			if err := writeTestOutput(input, &out, tc.cfg); err != errTestFailure {
 				t.Error("expected test failure")
 			}
 
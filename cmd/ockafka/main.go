@@ -10,8 +10,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	client "github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/kafka"
@@ -28,9 +32,85 @@ var keysFlag = flag.String("kafkakeys", "",
 	"Keys for kafka messages (comma-separated, default: the value of -addrs). The key '"+
 		client.HostnameArg+"' is replaced by the current hostname.")
 
-func newProducer(addresses []string, topic, key, dataset string) (producer.Producer, error) {
+var keyModeFlag = flag.String("key_mode", "device",
+	"Kafka keys of the messages, which select their partitions: 'device' keys all the "+
+		"messages of a device with its -kafkakeys key, 'path' with the path of their update "+
+		"and 'device+path-hash' with the device key and a hash of the path of their update, "+
+		"spreading the messages across partitions while keeping the order of each path "+
+		"(the delete of a subtree is not ordered with the updates of its children)")
+
+var aggregationWindowFlag = flag.Duration("aggregation_window", 0,
+	"Combine the consecutive notifications of a prefix with the same timestamp received "+
+		"within this duration (e.g. 50ms) into one before encoding them (0 to not combine them)")
+
+var downsampleIntervalFlag = flag.Duration("downsample_interval", 0,
+	"Forward at most one update of each path within this duration (e.g. 10s), the latest, "+
+		"to cut the volume of the paths updated at a high rate. Deletes are always forwarded "+
+		"(0 to forward all the updates)")
+
+var expandDeletesFlag = flag.Bool("expand_deletes", false,
+	"Replace the deletes of subtrees with the deletes of each leaf updated under them, "+
+		"for consumers handling leaf deletes only. The paths of all the leaves updated "+
+		"are kept in memory")
+
+var transformsFlag = client.TransformFlag()
+
+var drainTimeoutFlag = flag.Duration("drain_timeout", 10*time.Second,
+	"On SIGINT or SIGTERM, how long to wait for Kafka to acknowledge the messages in flight "+
+		"before exiting")
+
+var dlqFileFlag = flag.String("dlqfile", "",
+	"File where notifications failing to encode are written. When subscribing to several "+
+		"addresses, the Kafka key of each address is appended to the file name as a suffix.")
+
+var dlqTopicFlag = flag.String("dlqtopic", "",
+	"Kafka topic where notifications failing to encode are written")
+
+func newDeadLetterQueue(addresses []string, key string,
+	multipleKeys bool) (producer.DeadLetterQueue, error) {
+	switch {
+	case *dlqFileFlag != "" && *dlqTopicFlag != "":
+		return nil, fmt.Errorf("only one of -dlqfile and -dlqtopic can be specified")
+	case *dlqFileFlag != "":
+		return producer.NewFileDeadLetterQueue(dlqFileName(key, multipleKeys))
+	case *dlqTopicFlag != "":
+		config, err := newKafkaConfig()
+		if err != nil {
+			return nil, err
+		}
+		return producer.NewTopicDeadLetterQueue(addresses, *dlqTopicFlag,
+			sarama.StringEncoder(key), config)
+	}
+	return nil, nil
+}
+
+func dlqFileName(key string, multipleKeys bool) string {
+	if multipleKeys {
+		return *dlqFileFlag + "." + key
+	}
+	return *dlqFileFlag
+}
+
+// newKafkaConfig returns the configuration of the producers, with the TLS
+// and SASL authentication set by the command-line flags.
+func newKafkaConfig() (*sarama.Config, error) {
+	config, err := kafka.NewConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	return config, nil
+}
+
+func newProducer(addresses []string, topic, key, dataset string, keyMode gnmi.KeyMode,
+	dlq producer.DeadLetterQueue) (producer.Producer, error) {
+	config, err := newKafkaConfig()
+	if err != nil {
+		return nil, err
+	}
 	encodedKey := sarama.StringEncoder(key)
-	p, err := producer.New(gnmi.NewEncoder(topic, encodedKey, dataset), addresses, nil)
+	encoder := gnmi.NewEncoderWithKeyMode(topic, encodedKey, dataset, keyMode)
+	p, err := producer.NewWithDeadLetterQueue(encoder, addresses, config, dlq)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create Kafka brokers: %s", err)
 	}
@@ -38,9 +118,30 @@ func newProducer(addresses []string, topic, key, dataset string) (producer.Produ
 	return p, nil
 }
 
+// stopProducer drains p, or stops it if it cannot be drained, and logs the
+// counts of its messages.
+func stopProducer(p producer.Producer, grpcAddr string) {
+	d, ok := p.(producer.Drainer)
+	if !ok {
+		p.Stop()
+		return
+	}
+	if err := d.Drain(*drainTimeoutFlag); err != nil {
+		glog.Errorf("Failed to drain the Kafka producer for %s: %s", grpcAddr, err)
+	}
+	s := d.Stats()
+	glog.Infof("Stopped the Kafka producer for %s: %d messages sent, %d failed, %d not "+
+		"acknowledged, %d dropped", grpcAddr, s.Sent, s.Failed, s.Produced-s.Sent-s.Failed,
+		s.Dropped)
+}
+
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	config, subscriptions := client.ParseFlags()
+	if err := config.LoadCredentials(); err != nil {
+		glog.Fatal(err)
+	}
 	ctx = client.NewContext(ctx, config)
 	grpcAddrs := strings.Split(config.Addr, ",")
 
@@ -58,19 +159,42 @@ func main() {
 		glog.Fatal("Please provide the same number of addresses and Kafka keys")
 	}
 	addresses := strings.Split(*kafka.Addresses, ",")
+	keyMode, err := gnmi.ParseKeyMode(*keyModeFlag)
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "replay" || len(args) != 1 {
+			glog.Fatalf("Unexpected arguments: %q", args)
+		}
+		if len(grpcAddrs) != 1 {
+			glog.Fatal("Replay requires exactly one address in -addrs")
+		}
+		if err := replay(addresses, *kafka.Topic, keys[0], grpcAddrs[0]); err != nil {
+			glog.Fatal(err)
+		}
+		return
+	}
+
 	wg := new(sync.WaitGroup)
 	for i, grpcAddr := range grpcAddrs {
 		key := keys[i]
-		p, err := newProducer(addresses, *kafka.Topic, key, grpcAddr)
+		dlq, err := newDeadLetterQueue(addresses, key, len(keys) > 1)
+		if err != nil {
+			glog.Fatalf("Failed to create dead letter queue: %s", err)
+		}
+		p, err := newProducer(addresses, *kafka.Topic, key, grpcAddr, keyMode, dlq)
 		if err != nil {
 			glog.Fatal(err)
 		} else {
 			glog.Infof("Initialized Kafka producer for %s", grpcAddr)
 		}
 		wg.Add(1)
-		go func() {
+		go func(grpcAddr string) {
+			defer wg.Done()
 			p.Start()
-			defer p.Stop()
+			defer stopProducer(p, grpcAddr)
 			respChan := make(chan *pb.SubscribeResponse)
 			errChan := make(chan error)
 			c, err := client.Dial(config)
@@ -81,18 +205,43 @@ func main() {
 				Paths: client.SplitPaths(subscriptions),
 			}
 			go client.Subscribe(ctx, c, subscribeOptions, respChan, errChan)
+			responses := respChan
+			transforms := *transformsFlag
+			if *expandDeletesFlag {
+				transforms = append(client.Transformers{&client.DeleteExpander{}},
+					transforms...)
+			}
+			if len(transforms) > 0 {
+				responses = client.TransformSubscribeResponses(responses, transforms)
+			}
+			if *downsampleIntervalFlag > 0 {
+				responses = client.DownsampleSubscribeResponses(responses,
+					*downsampleIntervalFlag)
+			}
+			if *aggregationWindowFlag > 0 {
+				responses = client.AggregateSubscribeResponses(responses, *aggregationWindowFlag)
+			}
+			// On shutdown, the subscription ends and the responses
+			// received until then are written before draining p.
 			for {
 				select {
-				case resp, open := <-respChan:
+				case resp, open := <-responses:
 					if !open {
 						return
 					}
 					p.Write(resp)
-				case err := <-errChan:
-					glog.Fatal(err)
+				case err, open := <-errChan:
+					if !open {
+						errChan = nil
+					} else if ctx.Err() == nil {
+						glog.Fatal(err)
+					}
 				}
 			}
-		}()
+		}(grpcAddr)
 	}
 	wg.Wait()
+	if ctx.Err() != nil {
+		glog.Info("Shut down on signal")
+	}
 }
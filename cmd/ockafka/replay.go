@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aristanetworks/goarista/kafka/gnmi"
+	"github.com/aristanetworks/goarista/kafka/producer"
+
+	"github.com/IBM/sarama"
+	"github.com/aristanetworks/glog"
+	"google.golang.org/protobuf/proto"
+)
+
+// replay re-encodes the dead letters found in -dlqfile or -dlqtopic and
+// produces them to topic. Dead letters still failing to encode are logged
+// and skipped.
+func replay(addresses []string, topic, key, dataset string) error {
+	config, err := newKafkaConfig()
+	if err != nil {
+		return err
+	}
+	kafkaProducer, err := sarama.NewSyncProducer(addresses, config)
+	if err != nil {
+		return fmt.Errorf("Failed to create Kafka producer: %s", err)
+	}
+	defer kafkaProducer.Close()
+
+	encoder := gnmi.NewEncoder(topic, sarama.StringEncoder(key), dataset)
+	var replayed, failed int
+	replayMessage := func(msg proto.Message) error {
+		messages, err := encoder.Encode(msg)
+		if err != nil {
+			glog.Errorf("Failed to encode dead letter: %s", err)
+			failed++
+			return nil
+		}
+		if err := kafkaProducer.SendMessages(messages); err != nil {
+			return fmt.Errorf("Failed to produce replayed messages: %s", err)
+		}
+		replayed++
+		return nil
+	}
+
+	switch {
+	case *dlqFileFlag != "":
+		f, err := os.Open(*dlqFileFlag)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		err = producer.ReadDeadLetters(f, replayMessage)
+		if err != nil {
+			return err
+		}
+	case *dlqTopicFlag != "":
+		if err := consumeDeadLetters(addresses, key, replayMessage); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("replay requires -dlqfile or -dlqtopic")
+	}
+	glog.Infof("Replayed %d dead letters, %d failed to encode", replayed, failed)
+	return nil
+}
+
+// consumeDeadLetters reads all the messages with the given key currently in
+// -dlqtopic and calls fn for each of them.
+func consumeDeadLetters(addresses []string, key string, fn func(proto.Message) error) error {
+	config, err := newKafkaConfig()
+	if err != nil {
+		return err
+	}
+	client, err := sarama.NewClient(addresses, config)
+	if err != nil {
+		return fmt.Errorf("Failed to create Kafka client: %s", err)
+	}
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return fmt.Errorf("Failed to create Kafka consumer: %s", err)
+	}
+	defer consumer.Close()
+
+	partitions, err := client.Partitions(*dlqTopicFlag)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		// Only replay the messages present when the replay started.
+		newest, err := client.GetOffset(*dlqTopicFlag, partition, sarama.OffsetNewest)
+		if err != nil {
+			return err
+		}
+		oldest, err := client.GetOffset(*dlqTopicFlag, partition, sarama.OffsetOldest)
+		if err != nil {
+			return err
+		}
+		if oldest >= newest {
+			continue
+		}
+		pc, err := consumer.ConsumePartition(*dlqTopicFlag, partition, oldest)
+		if err != nil {
+			return err
+		}
+		for m := range pc.Messages() {
+			if string(m.Key) == key {
+				msg, err := producer.UnmarshalDeadLetter(m.Value)
+				if err != nil {
+					pc.Close()
+					return fmt.Errorf("Failed to decode dead letter at offset %d: %s",
+						m.Offset, err)
+				}
+				if err := fn(msg); err != nil {
+					pc.Close()
+					return err
+				}
+			}
+			if m.Offset >= newest-1 {
+				break
+			}
+		}
+		if err := pc.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"container/list"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -12,12 +13,12 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/aristanetworks/glog"
 	"github.com/aristanetworks/goarista/gnmi"
-	gnmiUtils "github.com/aristanetworks/goarista/gnmi"
 	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/protobuf/proto"
@@ -29,7 +30,9 @@ var labelRegex = regexp.MustCompile(`[a-zA-Z0-9_-]+`)
 // A metric source.
 type source struct {
 	addr string
-	path string
+	// origin of the path, empty for openconfig.
+	origin string
+	path   string
 }
 
 // Since the labels are fixed per-path and per-device we can cache them here,
@@ -40,24 +43,79 @@ type labelledMetric struct {
 	defaultValue float64
 	floatVal     float64
 	stringMetric bool
+	// path is the gNMI path of the metric, which its key labels are taken
+	// from.
+	path *pb.Path `deepequal:"ignore"`
+	// Time of the last update of the metric, used to expire stale metrics.
+	lastUpdate time.Time `deepequal:"ignore"`
+	// elem is the element of the metric in the lru list of the collector.
+	elem *list.Element `deepequal:"ignore"`
 }
 
+var (
+	seriesDesc = prometheus.NewDesc("ocprometheus_series",
+		"Number of series currently exported", nil, nil)
+	expiredDesc = prometheus.NewDesc("ocprometheus_series_expired_total",
+		"Number of series dropped because they were not updated within the TTL", nil, nil)
+	evictedDesc = prometheus.NewDesc("ocprometheus_series_evicted_total",
+		"Number of series evicted to stay under the maximum number of series", nil, nil)
+	pathDeletesDesc = prometheus.NewDesc("telemetry_path_deletes_total",
+		"Number of gNMI deletes received, by deleted path without its list keys",
+		[]string{"prefix"}, nil)
+	clockSkewDesc = prometheus.NewDesc("ocprometheus_clock_skew_seconds",
+		"Receive time minus the timestamp of the last notification measured, by device",
+		[]string{"device"}, nil)
+	clockSkewMeasuredDesc = prometheus.NewDesc("ocprometheus_clock_skew_notifications_total",
+		"Number of notifications whose clock skew was measured, by device",
+		[]string{"device"}, nil)
+	clockSkewedDesc = prometheus.NewDesc("ocprometheus_clock_skewed_notifications_total",
+		"Number of notifications whose clock skew exceeded the maximum clock skew, by device",
+		[]string{"device"}, nil)
+	retimestampedDesc = prometheus.NewDesc("ocprometheus_retimestamped_notifications_total",
+		"Number of notifications whose timestamp was rewritten to their receive time, "+
+			"by device", []string{"device"}, nil)
+)
+
 type collector struct {
-	// Protects access to metrics map
+	// Protects access to metrics map and config
 	m       sync.Mutex
 	metrics map[source]*labelledMetric
+	// lru holds the sources of metrics from the least to the most recently
+	// updated, to expire and evict them without scanning metrics.
+	lru list.List
+	// The series of the derived metrics.
+	derived map[derivedKey]*derivedSeries
 
 	config            *Config
 	descRegex         *regexp.Regexp
 	descriptionLabels map[string]map[string]string
+
+	// Metrics not updated within ttl are dropped, if ttl is not zero.
+	ttl time.Duration
+	// If maxSeries is not zero, the least recently updated metric is
+	// evicted when adding a metric would exceed maxSeries.
+	maxSeries int
+	expired   uint64
+	evicted   uint64
+	now       func() time.Time
+	// pathDeletes counts the deletes received by deletePrefix, if not nil.
+	pathDeletes map[string]uint64
+	// skew measures the clock skew of the notifications of each device,
+	// and rewrites the timestamps exceeding the maximum clock skew if set.
+	skew *gnmi.TargetSkewDetector
+	// transforms are applied to the notifications before they are
+	// converted to metrics.
+	transforms gnmi.Transformers
 }
 
 func newCollector(config *Config, descRegex *regexp.Regexp) *collector {
 	return &collector{
 		metrics:           make(map[source]*labelledMetric),
+		derived:           make(map[derivedKey]*derivedSeries),
 		config:            config,
 		descriptionLabels: make(map[string]map[string]string),
 		descRegex:         descRegex,
+		now:               time.Now,
 	}
 }
 
@@ -68,21 +126,21 @@ func (c *collector) addInitialDescriptionData(p *pb.Path, val string) {
 	if len(labels) == 0 {
 		return
 	}
-	c.descriptionLabels[gnmiUtils.StrPath(p)] = labels
+	c.descriptionLabels[gnmi.StrPath(p)] = labels
 }
 
 // gets updates from the descriptin nodes and updates the map accordingly.
 func (c *collector) deleteDescriptionTags(p *pb.Path) {
 	c.m.Lock()
 	defer c.m.Unlock()
-	strP := gnmiUtils.StrPath(p)
+	strP := gnmi.StrPath(p)
 	delete(c.descriptionLabels, strP)
 	for s, m := range c.metrics {
 		if !strings.Contains(s.path, strP) {
 			continue
 		}
 
-		metric := c.config.getMetricValues(s, c.descriptionLabels)
+		metric := c.config.getMetricValues(s, m.path, c.descriptionLabels)
 		lm := prometheus.MustNewConstMetric(metric.desc, prometheus.GaugeValue, m.floatVal,
 			metric.labels...)
 		c.metrics[s].metric = lm
@@ -93,7 +151,7 @@ func (c *collector) updateDescriptionTags(p *pb.Path, val string) {
 	c.m.Lock()
 	defer c.m.Unlock()
 
-	strP := gnmiUtils.StrPath(p)
+	strP := gnmi.StrPath(p)
 	labels := extractLabelsFromDesc(val, c.descRegex)
 	c.descriptionLabels[strP] = labels
 
@@ -102,7 +160,7 @@ func (c *collector) updateDescriptionTags(p *pb.Path, val string) {
 			continue
 		}
 
-		met := c.config.getMetricValues(s, c.descriptionLabels)
+		met := c.config.getMetricValues(s, m.path, c.descriptionLabels)
 		lm := prometheus.MustNewConstMetric(met.desc, prometheus.GaugeValue, m.floatVal,
 			met.labels...)
 		c.metrics[s].metric = lm
@@ -206,7 +264,9 @@ func extractLabelsFromDesc(desc string, re *regexp.Regexp) map[string]string {
 }
 
 // Process a notification and update or create the corresponding metrics.
-func (c *collector) update(addr string, message proto.Message) {
+// The origin of the paths is taken from the prefix of the notification, or
+// is the origin of the subscription if the prefix has none.
+func (c *collector) update(addr, origin string, message proto.Message) {
 	resp, ok := message.(*pb.SubscribeResponse)
 	if !ok {
 		glog.Errorf("Unexpected type of message: %T", message)
@@ -219,23 +279,35 @@ func (c *collector) update(addr string, message proto.Message) {
 	}
 
 	device := strings.Split(addr, ":")[0]
+	if o := notif.GetPrefix().GetOrigin(); o != "" {
+		origin = o
+	}
+	origin = normalizeOrigin(origin)
 	prefix := gnmi.StrPath(notif.Prefix)
+	ts := c.now()
+	if notif.GetTimestamp() != 0 {
+		ts = time.Unix(0, notif.GetTimestamp())
+	}
 	// Process deletes first
 	for _, del := range notif.Delete {
 		path := path.Join(prefix, gnmi.StrPath(del))
-		key := source{addr: device, path: path}
+		key := source{addr: device, origin: origin, path: path}
 		c.m.Lock()
 		if _, ok := c.metrics[key]; ok {
-			delete(c.metrics, key)
+			c.deleteMetric(key)
 		} else {
 			// TODO: replace this with a prefix tree
 			p := path + "/"
 			for k := range c.metrics {
-				if k.addr == device && strings.HasPrefix(k.path, p) {
-					delete(c.metrics, k)
+				if k.addr == device && k.origin == origin && strings.HasPrefix(k.path, p) {
+					c.deleteMetric(k)
 				}
 			}
 		}
+		c.deleteDerived(device, origin, path)
+		if c.pathDeletes != nil {
+			c.pathDeletes[deletePrefix(origin, path)]++
+		}
 		c.m.Unlock()
 	}
 
@@ -264,8 +336,11 @@ func (c *collector) update(addr string, message proto.Message) {
 			path += "/" + suffix
 		}
 
-		src := source{addr: device, path: path}
+		src := source{addr: device, origin: origin, path: path}
 		c.m.Lock()
+		if !strUpdate {
+			c.updateDerived(src, floatVal, ts)
+		}
 		// Use the cached labels and descriptor if available
 		if m, ok := c.metrics[src]; ok {
 			if strUpdate {
@@ -282,12 +357,20 @@ func (c *collector) update(addr string, message proto.Message) {
 			m.metric = prometheus.MustNewConstMetric(m.metric.Desc(), prometheus.GaugeValue,
 				floatVal, m.labels...)
 			m.floatVal = floatVal
+			m.lastUpdate = c.now()
+			c.lru.MoveToBack(m.elem)
 			c.m.Unlock()
 			continue
 		}
 
 		// Get the descriptor and labels for this source
-		metric := c.config.getMetricValues(src, c.descriptionLabels)
+		p := gnmi.JoinPaths(notif.Prefix, update.Path)
+		if suffix != "" {
+			for _, name := range strings.Split(suffix, "/") {
+				p.Elem = append(p.Elem, &pb.PathElem{Name: name})
+			}
+		}
+		metric := c.config.getMetricValues(src, p, c.descriptionLabels)
 		if metric == nil || metric.desc == nil {
 			glog.V(8).Infof("Ignoring unmatched update %v at %s:%s with value %+v",
 				update, device, path, value)
@@ -309,13 +392,18 @@ func (c *collector) update(addr string, message proto.Message) {
 		// Save the metric and labels in the cache
 		lm := prometheus.MustNewConstMetric(metric.desc, prometheus.GaugeValue,
 			floatVal, metric.labels...)
-		c.metrics[src] = &labelledMetric{
+		if c.maxSeries > 0 && len(c.metrics) >= c.maxSeries {
+			c.evictOldest()
+		}
+		c.addMetric(src, &labelledMetric{
 			metric:       lm,
 			floatVal:     floatVal,
 			labels:       metric.labels,
 			defaultValue: metric.defaultValue,
 			stringMetric: metric.stringMetric,
-		}
+			path:         p,
+			lastUpdate:   c.now(),
+		})
 		c.m.Unlock()
 	}
 }
@@ -380,16 +468,185 @@ func parseValue(update *pb.Update) (interface{}, string, bool) {
 	return getValue(intf)
 }
 
+// setConfig atomically replaces the config of the collector and recomputes
+// the cached metrics according to the new metric definitions. Metrics no
+// longer matching any definition are dropped.
+func (c *collector) setConfig(config *Config) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.config = config
+	// The derived series are computed again from the next updates of their
+	// inputs.
+	c.derived = make(map[derivedKey]*derivedSeries)
+	for src, m := range c.metrics {
+		metric := config.getMetricValues(src, m.path, c.descriptionLabels)
+		if metric == nil || metric.desc == nil || (m.stringMetric && !metric.stringMetric) {
+			c.deleteMetric(src)
+			continue
+		}
+		floatVal := m.floatVal
+		if metric.stringMetric {
+			strVal := fmt.Sprintf("%.0f", floatVal)
+			if m.stringMetric {
+				strVal = m.labels[len(m.labels)-1]
+			}
+			floatVal = metric.defaultValue
+			metric.labels[len(metric.labels)-1] = strVal
+		}
+		c.metrics[src] = &labelledMetric{
+			metric: prometheus.MustNewConstMetric(metric.desc, prometheus.GaugeValue,
+				floatVal, metric.labels...),
+			floatVal:     floatVal,
+			labels:       metric.labels,
+			defaultValue: metric.defaultValue,
+			stringMetric: metric.stringMetric,
+			lastUpdate:   m.lastUpdate,
+			elem:         m.elem,
+		}
+	}
+}
+
+// addMetric adds the metric of src, as the most recently updated one. Must
+// be called with c.m held.
+func (c *collector) addMetric(src source, m *labelledMetric) {
+	m.elem = c.lru.PushBack(src)
+	c.metrics[src] = m
+}
+
+// deleteMetric drops the metric of src, if any. Must be called with c.m
+// held.
+func (c *collector) deleteMetric(src source) {
+	if m, ok := c.metrics[src]; ok {
+		c.lru.Remove(m.elem)
+		delete(c.metrics, src)
+	}
+}
+
+// evictOldest drops the least recently updated metric, after dropping the
+// expired ones. Must be called with c.m held.
+func (c *collector) evictOldest() {
+	c.expireMetrics()
+	if len(c.metrics) < c.maxSeries {
+		return
+	}
+	oldest := c.lru.Front().Value.(source)
+	glog.V(8).Infof("Evicting metric %s:%s last updated at %s",
+		oldest.addr, oldest.path, c.metrics[oldest].lastUpdate)
+	c.deleteMetric(oldest)
+	c.evicted++
+}
+
+// expireMetrics drops the metrics not updated within the TTL, which are at
+// the front of c.lru. Must be called with c.m held.
+func (c *collector) expireMetrics() {
+	if c.ttl == 0 {
+		return
+	}
+	deadline := c.now().Add(-c.ttl)
+	for e := c.lru.Front(); e != nil; e = c.lru.Front() {
+		src := e.Value.(source)
+		m := c.metrics[src]
+		if !m.lastUpdate.Before(deadline) {
+			break
+		}
+		glog.V(8).Infof("Expiring metric %s:%s last updated at %s",
+			src.addr, src.path, m.lastUpdate)
+		c.deleteMetric(src)
+		c.expired++
+	}
+}
+
+// expire drops the metrics and the derived series not updated within the
+// TTL. Must be called with c.m held.
+func (c *collector) expire() {
+	if c.ttl == 0 {
+		return
+	}
+	c.expireMetrics()
+	deadline := c.now().Add(-c.ttl)
+	for key, s := range c.derived {
+		if s.lastUpdate.Before(deadline) {
+			glog.V(8).Infof("Expiring derived metric %s of %s last updated at %s",
+				key.name, key.addr, s.lastUpdate)
+			delete(c.derived, key)
+			if s.metric != nil {
+				c.expired++
+			}
+		}
+	}
+}
+
 // Describe implements prometheus.Collector interface
 func (c *collector) Describe(ch chan<- *prometheus.Desc) {
-	c.config.getAllDescs(ch)
+	c.m.Lock()
+	config := c.config
+	c.m.Unlock()
+	config.getAllDescs(ch)
+	ch <- seriesDesc
+	ch <- expiredDesc
+	ch <- evictedDesc
+	if c.pathDeletes != nil {
+		ch <- pathDeletesDesc
+	}
+	if c.skew != nil {
+		ch <- clockSkewDesc
+		ch <- clockSkewMeasuredDesc
+		ch <- clockSkewedDesc
+		ch <- retimestampedDesc
+	}
 }
 
 // Collect implements prometheus.Collector interface
 func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	c.m.Lock()
+	c.expire()
 	for _, m := range c.metrics {
 		ch <- m.metric
 	}
+	series := len(c.metrics)
+	for _, s := range c.derived {
+		if s.metric != nil {
+			ch <- s.metric
+			series++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(seriesDesc, prometheus.GaugeValue, float64(series))
+	ch <- prometheus.MustNewConstMetric(expiredDesc, prometheus.CounterValue,
+		float64(c.expired))
+	ch <- prometheus.MustNewConstMetric(evictedDesc, prometheus.CounterValue,
+		float64(c.evicted))
+	for prefix, n := range c.pathDeletes {
+		ch <- prometheus.MustNewConstMetric(pathDeletesDesc, prometheus.CounterValue,
+			float64(n), prefix)
+	}
 	c.m.Unlock()
+	if c.skew != nil {
+		for device, s := range c.skew.Snapshot() {
+			ch <- prometheus.MustNewConstMetric(clockSkewDesc, prometheus.GaugeValue,
+				s.Last.Seconds(), device)
+			ch <- prometheus.MustNewConstMetric(clockSkewMeasuredDesc,
+				prometheus.CounterValue, float64(s.Notifications), device)
+			ch <- prometheus.MustNewConstMetric(clockSkewedDesc, prometheus.CounterValue,
+				float64(s.Skewed), device)
+			ch <- prometheus.MustNewConstMetric(retimestampedDesc, prometheus.CounterValue,
+				float64(s.Retimestamped), device)
+		}
+	}
+}
+
+// deletePrefix returns the path of a delete without its list keys, so that
+// the deletes of the entries of a list are counted together, prefixed by
+// its origin if any.
+func deletePrefix(origin, p string) string {
+	elems := gnmi.SplitPath(p)
+	for i, elem := range elems {
+		if j := strings.IndexByte(elem, '['); j >= 0 {
+			elems[i] = elem[:j]
+		}
+	}
+	p = "/" + strings.Join(elems, "/")
+	if origin != "" {
+		p = origin + ":" + p
+	}
+	return p
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"maps"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -18,6 +19,7 @@ import (
 	"github.com/aristanetworks/goarista/test"
 	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func makeMetrics(cfg *Config, expValues map[source]float64, notification *pb.Notification,
@@ -29,7 +31,7 @@ func makeMetrics(cfg *Config, expValues map[source]float64, notification *pb.Not
 		expMetrics = prevMetrics
 	}
 	for src, v := range expValues {
-		metric := cfg.getMetricValues(src, descLabels)
+		metric := cfg.getMetricValues(src, gnmiPath(src.path), descLabels)
 		if metric == nil || metric.desc == nil || metric.labels == nil {
 			panic("cfg.getMetricValues returned nil")
 		}
@@ -206,7 +208,7 @@ metrics:
 				"remoteSystem/17/sysName/value/value",
 		}: 1,
 	}
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	expMetrics := makeMetrics(cfg, expValues, notif, nil, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -242,7 +244,7 @@ metrics:
 	}
 	expValues[src] = 52
 
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	expMetrics = makeMetrics(cfg, expValues, notif, expMetrics, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -263,7 +265,7 @@ metrics:
 	src.addr = "10.1.1.2"
 	expValues[src] = 42
 
-	coll.update("10.1.1.2:6042", makeResponse(notif))
+	coll.update("10.1.1.2:6042", "", makeResponse(notif))
 	expMetrics = makeMetrics(cfg, expValues, notif, expMetrics, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -277,7 +279,7 @@ metrics:
 	src.addr = "10.1.1.1"
 	delete(expValues, src)
 
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	// Delete a path
 	notif = &pb.Notification{
 		Prefix: nil,
@@ -285,7 +287,7 @@ metrics:
 	}
 	src.path = "/Sysdb/environment/cooling/status/fan/name"
 	delete(expValues, src)
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	expMetrics = makeMetrics(cfg, expValues, notif, expMetrics, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -304,7 +306,7 @@ metrics:
 		},
 	}
 
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	src.addr = "10.1.1.1"
 	src.path = "/Sysdb/lag/intfCounterDir/Ethernet1/intfCounter"
 	expValues[src] = 0
@@ -329,7 +331,7 @@ metrics:
 		path: "/Sysdb/lag/intfCounterDir/Ethernet1/intfCounter",
 	}
 	expValues[src] = 62
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	expMetrics = makeMetrics(cfg, expValues, notif, expMetrics, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -409,7 +411,7 @@ metrics:
 		}: 1,
 	}
 
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	expMetrics := makeMetrics(cfg, expValues, notif, nil, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -426,7 +428,7 @@ metrics:
 	}
 	delete(expValues, src)
 
-	coll.update("10.1.1.1:6042", makeResponse(notif))
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
 	expMetrics = makeMetrics(cfg, expValues, notif, expMetrics, descLabels)
 	if !test.DeepEqual(expMetrics, coll.metrics) {
 		t.Errorf("Mismatched metrics: %v", test.Diff(expMetrics, coll.metrics))
@@ -750,7 +752,7 @@ metrics:
 				}
 			}
 
-			coll.update("10.1.1.1:6042", makeResponse(tc.notif))
+			coll.update("10.1.1.1:6042", "", makeResponse(tc.notif))
 			prevExpMetrics = expMetrics
 			expMetrics = makeMetrics(cfg, tc.expValues, tc.notif, nil, coll.descriptionLabels)
 
@@ -818,3 +820,213 @@ func TestParseValue(t *testing.T) {
 		})
 	}
 }
+
+func TestExpireAndEvict(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+metrics:
+        - name: intfCounter
+          path: /Sysdb/intfCounterDir/(?P<intf>.+)/intfCounter
+          help: Per-Interface Counters`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	coll := newCollector(cfg, nil)
+	coll.now = func() time.Time { return now }
+	coll.ttl = time.Minute
+	coll.maxSeries = 2
+
+	updateIntf := func(intf string) {
+		coll.update("10.1.1.1:6042", "", makeResponse(&pb.Notification{
+			Prefix: makePath("Sysdb"),
+			Update: []*pb.Update{{
+				Path: makePath("intfCounterDir/" + intf + "/intfCounter"),
+				Val:  &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: []byte("42")}},
+			}},
+		}))
+	}
+	checkMetrics := func(intfs ...string) {
+		t.Helper()
+		var got []string
+		for src := range coll.metrics {
+			got = append(got, src.path)
+		}
+		var exp []string
+		for _, intf := range intfs {
+			exp = append(exp, "/Sysdb/intfCounterDir/"+intf+"/intfCounter")
+		}
+		sort.Strings(got)
+		sort.Strings(exp)
+		if !test.DeepEqual(exp, got) {
+			t.Errorf("unexpected metrics: %v", test.Diff(exp, got))
+		}
+		if coll.lru.Len() != len(coll.metrics) {
+			t.Errorf("expected %d series in the LRU list, got %d", len(coll.metrics),
+				coll.lru.Len())
+		}
+	}
+	collect := func() {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			coll.Collect(ch)
+			close(ch)
+		}()
+		for range ch {
+		}
+	}
+
+	updateIntf("Ethernet1")
+	now = now.Add(10 * time.Second)
+	updateIntf("Ethernet2")
+	now = now.Add(10 * time.Second)
+	updateIntf("Ethernet1")
+	now = now.Add(10 * time.Second)
+	// Ethernet2 is the least recently updated series
+	updateIntf("Ethernet3")
+	checkMetrics("Ethernet1", "Ethernet3")
+	if coll.evicted != 1 || coll.expired != 0 {
+		t.Errorf("expected 1 eviction and no expiry, got %d and %d",
+			coll.evicted, coll.expired)
+	}
+
+	now = now.Add(55 * time.Second)
+	collect()
+	checkMetrics("Ethernet3")
+	if coll.evicted != 1 || coll.expired != 1 {
+		t.Errorf("expected 1 eviction and 1 expiry, got %d and %d",
+			coll.evicted, coll.expired)
+	}
+
+	// Expired series make room for new series without evicting others
+	now = now.Add(time.Minute)
+	updateIntf("Ethernet4")
+	updateIntf("Ethernet5")
+	checkMetrics("Ethernet4", "Ethernet5")
+	if coll.evicted != 1 || coll.expired != 2 {
+		t.Errorf("expected 1 eviction and 2 expiries, got %d and %d",
+			coll.evicted, coll.expired)
+	}
+}
+
+func TestPathDeletes(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+metrics:
+        - name: intfCounter
+          path: /Sysdb/intfCounterDir/(?P<intf>.+)/intfCounter
+          help: Per-Interface Counters`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := newCollector(cfg, nil)
+	coll.pathDeletes = map[string]uint64{}
+	for _, p := range []string{
+		"lag/member[name=Ethernet1]",
+		"lag/member[name=Ethernet2]",
+		"intfCounterDir/Ethernet1",
+	} {
+		coll.update("10.1.1.1:6042", "eos_native", makeResponse(&pb.Notification{
+			Prefix: makePath("Sysdb"),
+			Delete: []*pb.Path{makePath(p)},
+		}))
+	}
+	coll.update("10.1.1.1:6042", "", makeResponse(&pb.Notification{
+		Delete: []*pb.Path{makePath("interfaces/interface[name=Ethernet1]")},
+	}))
+	expected := map[string]uint64{
+		"eos_native:/Sysdb/lag/member":               2,
+		"eos_native:/Sysdb/intfCounterDir/Ethernet1": 1,
+		"/interfaces/interface":                      1,
+	}
+	if !test.DeepEqual(expected, coll.pathDeletes) {
+		t.Errorf("unexpected deletes: %v", test.Diff(expected, coll.pathDeletes))
+	}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		coll.Collect(ch)
+		close(ch)
+	}()
+	deletes := map[string]float64{}
+	for m := range ch {
+		if m.Desc() != pathDeletesDesc {
+			continue
+		}
+		var dm dto.Metric
+		if err := m.Write(&dm); err != nil {
+			t.Fatal(err)
+		}
+		deletes[dm.GetLabel()[0].GetValue()] = dm.GetCounter().GetValue()
+	}
+	if deletes["eos_native:/Sysdb/lag/member"] != 2 || len(deletes) != 3 {
+		t.Errorf("unexpected delete metrics: %v", deletes)
+	}
+}
+
+func TestUpdateOrigins(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+subscriptions:
+        - /fan
+        - eos_native:/fan
+metrics:
+        - name: speed
+          path: /fan/speed
+          help: Fan speed`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.addOriginLabels()
+	coll := newCollector(cfg, nil)
+	update := func(val string) *pb.Update {
+		return &pb.Update{Path: makePath("fan/speed"),
+			Val: &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: []byte(val)}}}
+	}
+	coll.update("10.1.1.1:6042", "", makeResponse(&pb.Notification{
+		Update: []*pb.Update{update("1")}}))
+	coll.update("10.1.1.1:6042", "eos_native", makeResponse(&pb.Notification{
+		Update: []*pb.Update{update("2")}}))
+	// The origin of the prefix wins over the origin of the subscription.
+	coll.update("10.1.1.1:6042", "eos_native", makeResponse(&pb.Notification{
+		Prefix: &pb.Path{Origin: "openconfig"},
+		Update: []*pb.Update{update("3")}}))
+
+	exp := map[source]float64{
+		{addr: "10.1.1.1", path: "/fan/speed"}:                       3,
+		{addr: "10.1.1.1", origin: "eos_native", path: "/fan/speed"}: 2,
+	}
+	got := map[source]float64{}
+	for src, m := range coll.metrics {
+		got[src] = m.floatVal
+	}
+	if !test.DeepEqual(exp, got) {
+		t.Errorf("unexpected metrics: %s", test.Diff(exp, got))
+	}
+
+	// The series of the origins are told apart by their origin label.
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(coll)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	speeds := map[string]float64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "origin" {
+					speeds[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	if exp := map[string]float64{"openconfig": 3, "eos_native": 2}; !test.DeepEqual(exp,
+		speeds) {
+		t.Errorf("unexpected speeds: %s", test.Diff(exp, speeds))
+	}
+
+	coll.update("10.1.1.1:6042", "eos_native", makeResponse(&pb.Notification{
+		Delete: []*pb.Path{makePath("fan")}}))
+	if _, ok := coll.metrics[source{addr: "10.1.1.1", path: "/fan/speed"}]; !ok ||
+		len(coll.metrics) != 1 {
+		t.Errorf("expected only the openconfig metric to be left, got %v", coll.metrics)
+	}
+}
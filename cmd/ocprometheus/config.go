@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"maps"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/aristanetworks/glog"
 	gnmiUtils "github.com/aristanetworks/goarista/gnmi"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/yaml.v2"
 )
@@ -23,11 +26,14 @@ type Config struct {
 	DeviceLabels map[string]prometheus.Labels
 
 	// Prefixes to subscribe to.
-	Subscriptions []string
+	Subscriptions subscriptionList
 
 	// Metrics to collect and how to munge them.
 	Metrics []*MetricDef
 
+	// Metrics computed from the values of several paths.
+	DerivedMetrics []*DerivedMetricDef `yaml:"derived-metrics,omitempty"`
+
 	// Subscribed paths by their origin
 	subsByOrigin map[string][]string
 
@@ -45,6 +51,35 @@ type MetricDef struct {
 	// Path compiled as a regexp.
 	re *regexp.Regexp `deepequal:"ignore"`
 
+	// GNMIPath, used instead of Path, matches the updates on the elements
+	// of their gNMI path rather than with a regexp. An element name or key
+	// value of "*" matches any name or value, for example
+	// /interfaces/interface[name=*]/state/counters/in-octets. The labels of
+	// the metric are then taken from its KeyLabels.
+	GNMIPath string
+
+	// Elements of GNMIPath.
+	elems []*pb.PathElem
+
+	// Origin of the updates the metric applies to (openconfig,
+	// eos_native, ...). If empty, updates of any origin match, and the
+	// metric has an origin label if more than one origin is subscribed to.
+	Origin string
+
+	// Whether the metric has an origin label.
+	originLabel bool
+
+	// KeyLabels maps gNMI PathElem keys to label names. A key is either
+	// specified as "<key>", matching the key on any element of the path,
+	// or as "<elem>[<key>]", matching the key only on the named element.
+	// For example "interface[name]: intf" extracts the value of the name
+	// key of the interface element into the label intf.
+	KeyLabels map[string]string
+
+	// KeyLabels parsed and sorted by key, in the order the labels are
+	// added to the metric.
+	keyLabels []keyLabel
+
 	// Metric name.
 	Name string
 
@@ -67,6 +102,59 @@ type MetricDef struct {
 	desc *promDesc
 }
 
+type keyLabel struct {
+	elem  string // empty to match any element
+	key   string
+	label string
+}
+
+// parseKeyLabels parses the KeyLabels of a metric definition.
+func parseKeyLabels(keyLabels map[string]string) ([]keyLabel, error) {
+	kls := make([]keyLabel, 0, len(keyLabels))
+	for spec, label := range keyLabels {
+		if label == "" {
+			return nil, fmt.Errorf("empty label name for key %q", spec)
+		}
+		kl := keyLabel{key: spec, label: label}
+		if i := strings.IndexByte(spec, '['); i >= 0 {
+			if !strings.HasSuffix(spec, "]") {
+				return nil, fmt.Errorf("invalid key %q, expected <elem>[<key>]", spec)
+			}
+			kl.elem = spec[:i]
+			kl.key = spec[i+1 : len(spec)-1]
+		}
+		if kl.key == "" {
+			return nil, fmt.Errorf("invalid key %q, key name is empty", spec)
+		}
+		kls = append(kls, kl)
+	}
+	sort.Slice(kls, func(i, j int) bool {
+		if kls[i].elem != kls[j].elem {
+			return kls[i].elem < kls[j].elem
+		}
+		return kls[i].key < kls[j].key
+	})
+	return kls, nil
+}
+
+// keyLabelValues returns the values of the key labels found in the given path.
+// The value of a key is taken from the last element of the path carrying it.
+// Keys absent from the path produce an empty label value.
+func keyLabelValues(kls []keyLabel, path *pb.Path) []string {
+	values := make([]string, len(kls))
+	for i, kl := range kls {
+		for _, elem := range path.GetElem() {
+			if kl.elem != "" && kl.elem != elem.Name {
+				continue
+			}
+			if v, ok := elem.Key[kl.key]; ok {
+				values[i] = v
+			}
+		}
+	}
+	return values
+}
+
 type promDesc struct {
 	fqName        string
 	help          string
@@ -104,80 +192,174 @@ func parseConfig(cfg []byte) (*Config, error) {
 	config.DescriptionLabelSubscriptions = descNodes
 
 	for _, def := range config.Metrics {
-		def.re = regexp.MustCompile(def.Path)
-		// Extract label names
-		reNames := def.re.SubexpNames()[1:]
-		labelNames := make([]string, len(reNames))
-		for i, n := range reNames {
-			labelNames[i] = n
-			if n == "" {
-				labelNames[i] = "unnamedLabel" + strconv.Itoa(i+1)
+		var labelNames []string
+		if def.GNMIPath != "" {
+			if def.Path != "" {
+				return nil, fmt.Errorf("Metric %q has both a path and a gnmipath", def.Name)
+			}
+			p, err := gnmiUtils.ParseGNMIElements(gnmiUtils.SplitPath(def.GNMIPath))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse gnmipath of metric %q: %v",
+					def.Name, err)
+			}
+			def.elems = p.Elem
+		} else {
+			def.re = regexp.MustCompile(def.Path)
+			// Extract label names
+			reNames := def.re.SubexpNames()[1:]
+			labelNames = make([]string, len(reNames))
+			for i, n := range reNames {
+				labelNames[i] = n
+				if n == "" {
+					labelNames[i] = "unnamedLabel" + strconv.Itoa(i+1)
+				}
+			}
+		}
+		if len(def.KeyLabels) > 0 {
+			kls, err := parseKeyLabels(def.KeyLabels)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse keylabels of metric %q: %v",
+					def.Name, err)
+			}
+			def.keyLabels = kls
+			for _, kl := range kls {
+				if slices.Contains(labelNames, kl.label) {
+					return nil, fmt.Errorf("Label %q of the keylabels of metric %q is "+
+						"already a label of the metric", kl.label, def.Name)
+				}
+				labelNames = append(labelNames, kl.label)
 			}
 		}
 		if def.ValueLabel != "" {
 			labelNames = append(labelNames, def.ValueLabel)
 			def.stringMetric = true
 		}
-		// Create a default descriptor only if there aren't any per-device labels,
-		// or if it's explicitly declared
-		if len(config.DeviceLabels) == 0 || len(config.DeviceLabels["*"]) > 0 {
-			def.desc = &promDesc{
-				fqName:        def.Name,
-				help:          def.Help,
-				varLabels:     labelNames,
-				devPermLabels: config.DeviceLabels["*"],
-			}
-		}
-		// Add per-device descriptors
-		def.devDesc = make(map[string]*promDesc)
-		for device, labels := range config.DeviceLabels {
-			if device == "*" {
-				continue
-			}
-			def.devDesc[device] = &promDesc{
-				fqName:        def.Name,
-				help:          def.Help,
-				varLabels:     labelNames,
-				devPermLabels: labels,
-			}
+		def.desc, def.devDesc = config.newPromDescs(def.Name, def.Help, labelNames)
+	}
+
+	for _, def := range config.DerivedMetrics {
+		if err := def.parse(); err != nil {
+			return nil, fmt.Errorf("Failed to parse derived metric %q: %v", def.Name, err)
 		}
+		def.desc, def.devDesc = config.newPromDescs(def.Name, def.Help, def.labelNames)
 	}
 
 	return config, nil
 }
 
+// newPromDescs returns the default descriptor and the per-device
+// descriptors of a metric.
+func (c *Config) newPromDescs(name, help string,
+	labelNames []string) (*promDesc, map[string]*promDesc) {
+	var desc *promDesc
+	// Create a default descriptor only if there aren't any per-device labels,
+	// or if it's explicitly declared
+	if len(c.DeviceLabels) == 0 || len(c.DeviceLabels["*"]) > 0 {
+		desc = &promDesc{
+			fqName:        name,
+			help:          help,
+			varLabels:     labelNames,
+			devPermLabels: c.DeviceLabels["*"],
+		}
+	}
+	// Add per-device descriptors
+	devDesc := make(map[string]*promDesc)
+	for device, labels := range c.DeviceLabels {
+		if device == "*" {
+			continue
+		}
+		devDesc[device] = &promDesc{
+			fqName:        name,
+			help:          help,
+			varLabels:     labelNames,
+			devPermLabels: labels,
+		}
+	}
+	return desc, devDesc
+}
+
 // Returns a struct containing the descriptor corresponding to the device and path, labels
 // extracted from the path, the default value for the metric and if it accepts string values.
+// p is the gNMI path of s, which the key labels are taken from.
 // If the device and path doesn't match any metrics, returns nil.
-func (c *Config) getMetricValues(s source,
+func (c *Config) getMetricValues(s source, p *pb.Path,
 	descriptionLabels map[string]map[string]string) *metricValues {
 	for _, def := range c.Metrics {
-		if groups := def.re.FindStringSubmatch(s.path); groups != nil {
-			if def.ValueLabel != "" {
-				groups = append(groups, def.ValueLabel)
+		if def.Origin != "" && normalizeOrigin(def.Origin) != s.origin {
+			continue
+		}
+		var labels []string
+		if def.elems != nil {
+			if !matchElems(def.elems, p.GetElem()) {
+				continue
+			}
+			labels = keyLabelValues(def.keyLabels, p)
+		} else {
+			groups := def.re.FindStringSubmatch(s.path)
+			if groups == nil {
+				continue
 			}
-			promdescVal, ok := def.devDesc[s.addr]
-			if !ok {
-				promdescVal = def.desc
+			labels = groups[1:]
+			if len(def.keyLabels) > 0 {
+				labels = append(labels, keyLabelValues(def.keyLabels, p)...)
 			}
+		}
+		if def.originLabel {
+			labels = append(labels, originLabelValue(s.origin))
+		}
+		if def.ValueLabel != "" {
+			labels = append(labels, def.ValueLabel)
+		}
+		promdescVal, ok := def.devDesc[s.addr]
+		if !ok {
+			promdescVal = def.desc
+		}
 
-			permLabels := make(map[string]string)
-			maps.Copy(permLabels, promdescVal.devPermLabels)
+		permLabels := make(map[string]string)
+		maps.Copy(permLabels, promdescVal.devPermLabels)
 
-			closestListParent := findClosestList(s.path)
-			if labels, ok := descriptionLabels[closestListParent]; ok {
-				maps.Copy(permLabels, labels)
-			}
-			desc := prometheus.NewDesc(promdescVal.fqName, promdescVal.help, promdescVal.varLabels,
-				permLabels)
-			return &metricValues{desc: desc, labels: groups[1:], defaultValue: def.DefaultValue,
-				stringMetric: def.stringMetric}
+		closestListParent := findClosestList(s.path)
+		if labels, ok := descriptionLabels[closestListParent]; ok {
+			maps.Copy(permLabels, labels)
 		}
+		desc := prometheus.NewDesc(promdescVal.fqName, promdescVal.help, promdescVal.varLabels,
+			permLabels)
+		return &metricValues{desc: desc, labels: labels, defaultValue: def.DefaultValue,
+			stringMetric: def.stringMetric}
 	}
 
 	return nil
 }
 
+// matchElems returns whether the elements of a path match the elements of a
+// GNMIPath, where a name or key value of "*" matches any.
+func matchElems(pattern, elems []*pb.PathElem) bool {
+	if len(elems) != len(pattern) {
+		return false
+	}
+	for i, pe := range pattern {
+		e := elems[i]
+		if pe.Name != "*" && pe.Name != e.Name {
+			return false
+		}
+		for k, v := range pe.Key {
+			if ev, ok := e.Key[k]; !ok || (v != "*" && v != ev) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// normalizeOrigin returns the origin used in sources, where the default
+// openconfig origin is empty.
+func normalizeOrigin(origin string) string {
+	if origin == "openconfig" {
+		return ""
+	}
+	return origin
+}
+
 func findClosestList(s string) string {
 	vals := gnmiUtils.SplitPath(s)
 	for i := len(vals) - 2; i >= 0; i-- {
@@ -193,19 +375,65 @@ func findClosestList(s string) string {
 // Sends all the descriptors to the channel.
 func (c *Config) getAllDescs(ch chan<- *prometheus.Desc) {
 	for _, def := range c.Metrics {
-		// Default descriptor might not be present
+		sendDescs(ch, def.desc, def.devDesc)
+	}
+	for _, def := range c.DerivedMetrics {
+		sendDescs(ch, def.desc, def.devDesc)
+	}
+}
+
+func sendDescs(ch chan<- *prometheus.Desc, desc *promDesc, devDesc map[string]*promDesc) {
+	// Default descriptor might not be present
+	if desc != nil {
+		ch <- prometheus.NewDesc(desc.fqName, desc.help, desc.varLabels, desc.devPermLabels)
+	}
+
+	for _, desc := range devDesc {
+		ch <- prometheus.NewDesc(desc.fqName, desc.help, desc.varLabels, desc.devPermLabels)
+	}
+}
+
+// originLabel is the label added to the metrics matching several origins.
+const originLabel = "origin"
+
+// addOriginLabels adds an origin label to the metrics without origin if
+// the subscriptions are to more than one origin, so that the series of the
+// same path in different origins are kept apart. It must be called once
+// all the subscriptions are added.
+func (c *Config) addOriginLabels() {
+	if len(c.subsByOrigin) < 2 {
+		return
+	}
+	withOrigin := func(d *promDesc, valueLabel bool) {
+		// The value label stays last.
+		i := len(d.varLabels)
+		if valueLabel {
+			i--
+		}
+		d.varLabels = slices.Insert(slices.Clip(d.varLabels), i, originLabel)
+	}
+	for _, def := range c.Metrics {
+		if def.Origin != "" || def.originLabel {
+			continue
+		}
+		def.originLabel = true
 		if def.desc != nil {
-			ch <- prometheus.NewDesc(def.desc.fqName, def.desc.help,
-				def.desc.varLabels, def.desc.devPermLabels)
+			withOrigin(def.desc, def.ValueLabel != "")
 		}
-
-		for _, desc := range def.devDesc {
-			ch <- prometheus.NewDesc(desc.fqName, desc.help,
-				desc.varLabels, desc.devPermLabels)
+		for _, d := range def.devDesc {
+			withOrigin(d, def.ValueLabel != "")
 		}
 	}
 }
 
+// originLabelValue returns the value of the origin label of a source.
+func originLabelValue(origin string) string {
+	if origin == "" {
+		return "openconfig"
+	}
+	return origin
+}
+
 func (c *Config) addSubscriptions(subscriptions []string) {
 	for _, sub := range subscriptions {
 		parts := strings.SplitN(sub, ":", 2)
@@ -217,3 +445,43 @@ func (c *Config) addSubscriptions(subscriptions []string) {
 		}
 	}
 }
+
+// subscriptionList is the list of subscriptions of the config file. An
+// entry is either a path, optionally prefixed with its origin as in
+// "eos_native:/Sysdb/hardware", or a mapping with a path and an origin.
+// Entries are stored in the prefixed form.
+type subscriptionList []string
+
+func (l *subscriptionList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entries []subscriptionEntry
+	if err := unmarshal(&entries); err != nil {
+		return err
+	}
+	*l = make(subscriptionList, len(entries))
+	for i, e := range entries {
+		(*l)[i] = e.Path
+		if e.Origin != "" {
+			(*l)[i] = e.Origin + ":" + e.Path
+		}
+	}
+	return nil
+}
+
+type subscriptionEntry struct {
+	Path   string
+	Origin string
+}
+
+func (e *subscriptionEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&e.Path); err == nil {
+		return nil
+	}
+	type entry subscriptionEntry
+	if err := unmarshal((*entry)(e)); err != nil {
+		return err
+	}
+	if e.Path == "" {
+		return fmt.Errorf("subscription with origin %q has no path", e.Origin)
+	}
+	return nil
+}
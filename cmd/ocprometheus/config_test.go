@@ -6,13 +6,25 @@ package main
 
 import (
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/test"
 
+	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// gnmiPath returns the gNMI path of the string path of a source.
+func gnmiPath(s string) *pb.Path {
+	p, err := gnmi.ParseGNMIElements(gnmi.SplitPath(s))
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
 func TestParseConfig(t *testing.T) {
 	tCases := []struct {
 		input  []byte
@@ -421,7 +433,7 @@ metrics:
 	}
 	descLabels := make(map[string]map[string]string)
 	for i, c := range tCases {
-		metric := cfg.getMetricValues(c.src, descLabels)
+		metric := cfg.getMetricValues(c.src, gnmiPath(c.src.path), descLabels)
 		if metric == nil {
 			// Avoids error from trying to access metric.desc when metric is nil
 			metric = &metricValues{}
@@ -435,6 +447,125 @@ metrics:
 	}
 }
 
+func TestGetMetricValuesKeyLabels(t *testing.T) {
+	config := []byte(`
+subscriptions:
+        - /interfaces/interface/state/counters
+metrics:
+        - name: intfCounter
+          path: /interfaces/interface\[.+\]/subinterfaces/subinterface\[.+\]/state/(?P<type>.+)
+          help: Per-Subinterface Counters
+          keylabels:
+                interface[name]: intf
+                index: subintf
+                vlan: vlan
+        - name: intfDescription
+          path: /interfaces/interface\[name=.+\]/state/description
+          help: Interface Description
+          valuelabel: description
+          keylabels:
+                name: intf
+        - name: intfInOctets
+          gnmipath: /interfaces/interface[name=*]/state/counters/in-octets
+          help: Interface Inbound Octets
+          keylabels:
+                name: intf`)
+	cfg, err := parseConfig(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tCases := []struct {
+		src    source
+		desc   *prometheus.Desc
+		labels []string
+	}{
+		{
+			src: source{
+				addr: "10.1.1.1",
+				path: "/interfaces/interface[name=Ethernet1]/subinterfaces/" +
+					"subinterface[index=2]/state/in-pkts",
+			},
+			desc: prometheus.NewDesc("intfCounter", "Per-Subinterface Counters",
+				[]string{"type", "subintf", "vlan", "intf"}, nil),
+			labels: []string{"in-pkts", "2", "", "Ethernet1"},
+		},
+		{
+			src: source{
+				addr: "10.1.1.1",
+				path: "/interfaces/interface[name=Ethernet2]/state/description",
+			},
+			desc: prometheus.NewDesc("intfDescription", "Interface Description",
+				[]string{"intf", "description"}, nil),
+			labels: []string{"Ethernet2", "description"},
+		},
+		{
+			src: source{
+				addr: "10.1.1.1",
+				path: "/interfaces/interface[name=Ethernet3]/state/counters/in-octets",
+			},
+			desc: prometheus.NewDesc("intfInOctets", "Interface Inbound Octets",
+				[]string{"intf"}, nil),
+			labels: []string{"Ethernet3"},
+		},
+		{
+			src: source{
+				addr: "10.1.1.1",
+				path: "/interfaces/interface[name=Ethernet3]/state/counters/out-octets",
+			},
+		},
+	}
+	descLabels := make(map[string]map[string]string)
+	for i, c := range tCases {
+		metric := cfg.getMetricValues(c.src, gnmiPath(c.src.path), descLabels)
+		if metric == nil {
+			if c.desc != nil {
+				t.Errorf("Test case %d: no metric found", i+1)
+			}
+			continue
+		} else if c.desc == nil {
+			t.Errorf("Test case %d: unexpected metric %s", i+1, metric.desc)
+			continue
+		}
+		if !test.DeepEqual(metric.desc, c.desc) {
+			t.Errorf("Test case %d: desc mismatch %v", i+1, test.Diff(metric.desc, c.desc))
+		}
+		if !test.DeepEqual(metric.labels, c.labels) {
+			t.Errorf("Test case %d: labels mismatch %v", i+1, test.Diff(metric.labels, c.labels))
+		}
+	}
+
+	for _, input := range []string{
+		"interface[name: intf",
+		"interface[]: intf",
+		"name: ''",
+	} {
+		_, err := parseConfig([]byte(`
+metrics:
+        - name: m
+          path: /a
+          keylabels:
+                ` + input))
+		if err == nil {
+			t.Errorf("expected error parsing keylabels %q", input)
+		}
+	}
+
+	for _, def := range []string{
+		// Key label named as a regex group.
+		"path: /a\\[name=(?P<intf>.+)\\]\n          keylabels:\n                name: intf",
+		// Two keys with the same label.
+		"path: /a\n          keylabels:\n                name: intf\n                id: intf",
+		"path: /a\n          gnmipath: /a",
+		"gnmipath: /a[name=x",
+	} {
+		config := "metrics:\n        - name: m\n          " + def
+		if _, err := parseConfig([]byte(config)); err == nil {
+			t.Errorf("expected error parsing metric %q", def)
+		}
+	}
+}
+
 func TestGetAllDescs(t *testing.T) {
 	tCases := []struct {
 		config []byte
@@ -523,3 +654,68 @@ metrics:
 		}
 	}
 }
+
+func TestOrigins(t *testing.T) {
+	config := []byte(`
+subscriptions:
+        - /interfaces/interface/state/counters
+        - eos_native:/Sysdb/environment
+        - path: /interfaces/interface/state/counters
+          origin: eos_native
+metrics:
+        - name: ocInOctets
+          path: /interfaces/interface\[name=(?P<intf>.+)\]/state/counters/in-octets
+          help: In octets
+          origin: openconfig
+        - name: nativeInOctets
+          path: /interfaces/interface\[name=(?P<intf>.+)\]/state/counters/in-octets
+          help: In octets of the native model
+          origin: eos_native
+        - name: fanSpeed
+          path: /Sysdb/environment/fan/speed
+          help: Fan Speed`)
+	cfg, err := parseConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expSubs := map[string][]string{
+		"":           {"/interfaces/interface/state/counters"},
+		"eos_native": {"/Sysdb/environment", "/interfaces/interface/state/counters"},
+	}
+	if !test.DeepEqual(expSubs, cfg.subsByOrigin) {
+		t.Errorf("unexpected subscriptions: %s", test.Diff(expSubs, cfg.subsByOrigin))
+	}
+
+	descLabels := map[string]map[string]string{}
+	for _, tc := range []struct {
+		origin string
+		path   string
+		name   string
+	}{
+		{"", "/interfaces/interface[name=Ethernet1]/state/counters/in-octets", "ocInOctets"},
+		{"eos_native", "/interfaces/interface[name=Ethernet1]/state/counters/in-octets",
+			"nativeInOctets"},
+		// Metrics without origin match all origins.
+		{"", "/Sysdb/environment/fan/speed", "fanSpeed"},
+		{"eos_native", "/Sysdb/environment/fan/speed", "fanSpeed"},
+		{"cli", "/interfaces/interface[name=Ethernet1]/state/counters/in-octets", ""},
+	} {
+		metric := cfg.getMetricValues(source{addr: "10.1.1.1", origin: tc.origin,
+			path: tc.path}, gnmiPath(tc.path), descLabels)
+		switch {
+		case tc.name == "":
+			if metric != nil {
+				t.Errorf("%s:%s: expected no metric, got %s", tc.origin, tc.path, metric.desc)
+			}
+		case metric == nil:
+			t.Errorf("%s:%s: expected metric %q, got none", tc.origin, tc.path, tc.name)
+		case !strings.Contains(metric.desc.String(), `"`+tc.name+`"`):
+			t.Errorf("%s:%s: expected metric %q, got %s", tc.origin, tc.path, tc.name,
+				metric.desc)
+		}
+	}
+
+	if _, err := parseConfig([]byte("subscriptions:\n  - origin: cli\n")); err == nil {
+		t.Error("expected an error for a subscription without path")
+	}
+}
@@ -0,0 +1,444 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristanetworks/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DerivedMetricDef is the representation of a metric computed from the
+// values of several paths in the config file.
+type DerivedMetricDef struct {
+	// Metric name.
+	Name string
+
+	// Metric help string.
+	Help string
+
+	// Origin of the updates of the inputs. If empty, updates of any
+	// origin match.
+	Origin string
+
+	// Inputs maps the names used in Expression to regexps matching the
+	// full path of the updates, as the Path of a MetricDef. The named
+	// capture groups are the labels of the metric and must be the same
+	// for all the inputs: the values of the inputs with the same labels
+	// are combined.
+	Inputs map[string]string
+
+	// Expression computing the metric from the latest values of the
+	// inputs, e.g. "rate(inoctets) * 8 / speed".
+	Expression string
+
+	inputs     []derivedInput
+	expr       expr
+	labelNames []string
+
+	// This map contains the metric descriptors for this metric for each device.
+	devDesc map[string]*promDesc
+
+	// This is the default metric descriptor for devices that don't have explicit descs.
+	desc *promDesc
+}
+
+type derivedInput struct {
+	name string
+	re   *regexp.Regexp
+	// groups are the indexes in re of the capture groups of the labels.
+	groups []int
+}
+
+// parse compiles the inputs and the expression of the derived metric.
+func (def *DerivedMetricDef) parse() error {
+	if len(def.Inputs) == 0 {
+		return fmt.Errorf("no inputs")
+	}
+	for name, path := range def.Inputs {
+		re, err := regexp.Compile(path)
+		if err != nil {
+			return fmt.Errorf("invalid path of input %q: %s", name, err)
+		}
+		def.inputs = append(def.inputs, derivedInput{name: name, re: re})
+	}
+	sort.Slice(def.inputs, func(i, j int) bool {
+		return def.inputs[i].name < def.inputs[j].name
+	})
+	for _, n := range def.inputs[0].re.SubexpNames()[1:] {
+		if n != "" {
+			def.labelNames = append(def.labelNames, n)
+		}
+	}
+	sort.Strings(def.labelNames)
+	for i := range def.inputs {
+		in := &def.inputs[i]
+		var names []string
+		for _, n := range in.re.SubexpNames()[1:] {
+			if n != "" {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		if strings.Join(names, ",") != strings.Join(def.labelNames, ",") {
+			return fmt.Errorf("input %q has the labels %v, expected %v", in.name, names,
+				def.labelNames)
+		}
+		for _, n := range def.labelNames {
+			in.groups = append(in.groups, in.re.SubexpIndex(n))
+		}
+	}
+	var err error
+	if def.expr, err = parseExpr(def.Expression, def.Inputs); err != nil {
+		return fmt.Errorf("invalid expression %q: %s", def.Expression, err)
+	}
+	return nil
+}
+
+// derivedKey identifies a series of a derived metric.
+type derivedKey struct {
+	name string
+	addr string
+	// labels are the label values joined by NUL characters.
+	labels string
+}
+
+// derivedSeries is the state of a series of a derived metric.
+type derivedSeries struct {
+	labels []string
+	inputs map[string]*derivedValue
+	// metric is nil until the expression could be evaluated.
+	metric prometheus.Metric
+	// Time of the last update of an input, used to expire stale series.
+	lastUpdate time.Time
+}
+
+// derivedValue holds the last two values of an input of a derived series.
+type derivedValue struct {
+	src      source
+	value    float64
+	time     time.Time
+	prev     float64
+	prevTime time.Time
+}
+
+// updateDerived feeds val, the value of src at ts, to the derived metrics
+// with an input matching it and evaluates them. Must be called with c.m
+// held.
+func (c *collector) updateDerived(src source, val float64, ts time.Time) {
+	for _, def := range c.config.DerivedMetrics {
+		if def.Origin != "" && normalizeOrigin(def.Origin) != src.origin {
+			continue
+		}
+		for _, in := range def.inputs {
+			groups := in.re.FindStringSubmatch(src.path)
+			if groups == nil {
+				continue
+			}
+			labels := make([]string, len(in.groups))
+			for i, g := range in.groups {
+				labels[i] = groups[g]
+			}
+			key := derivedKey{name: def.Name, addr: src.addr,
+				labels: strings.Join(labels, "\x00")}
+			s, ok := c.derived[key]
+			if !ok {
+				s = &derivedSeries{labels: labels, inputs: make(map[string]*derivedValue)}
+				c.derived[key] = s
+			}
+			v, ok := s.inputs[in.name]
+			if !ok || v.src != src {
+				v = &derivedValue{src: src}
+				s.inputs[in.name] = v
+			} else {
+				v.prev, v.prevTime = v.value, v.time
+			}
+			v.value, v.time = val, ts
+			s.lastUpdate = c.now()
+			c.evalDerived(def, key.addr, s)
+		}
+	}
+}
+
+// evalDerived evaluates the expression of def on the latest values of the
+// inputs of s and updates its metric. The metric keeps its previous value
+// if an input is missing or the result is not a number.
+func (c *collector) evalDerived(def *DerivedMetricDef, addr string, s *derivedSeries) {
+	val, ok := def.expr.eval(s.inputs)
+	if !ok || math.IsNaN(val) || math.IsInf(val, 0) {
+		return
+	}
+	desc, ok := def.devDesc[addr]
+	if !ok {
+		desc = def.desc
+	}
+	if desc == nil {
+		glog.V(8).Infof("Ignoring derived metric %s of device %s without descriptor",
+			def.Name, addr)
+		return
+	}
+	s.metric = prometheus.MustNewConstMetric(prometheus.NewDesc(desc.fqName, desc.help,
+		desc.varLabels, desc.devPermLabels), prometheus.GaugeValue, val, s.labels...)
+}
+
+// deleteDerived drops the derived series with an input at or under the
+// deleted path. Must be called with c.m held.
+func (c *collector) deleteDerived(addr, origin, path string) {
+	for key, s := range c.derived {
+		for _, v := range s.inputs {
+			if v.src.addr == addr && v.src.origin == origin &&
+				(v.src.path == path || strings.HasPrefix(v.src.path, path+"/")) {
+				delete(c.derived, key)
+				break
+			}
+		}
+	}
+}
+
+// expr is a node of a derived metric expression.
+type expr interface {
+	// eval returns the value of the expression and false if an input it
+	// uses has no value yet.
+	eval(inputs map[string]*derivedValue) (float64, bool)
+}
+
+type numberExpr float64
+
+func (e numberExpr) eval(map[string]*derivedValue) (float64, bool) {
+	return float64(e), true
+}
+
+type inputExpr string
+
+func (e inputExpr) eval(inputs map[string]*derivedValue) (float64, bool) {
+	v, ok := inputs[string(e)]
+	if !ok {
+		return 0, false
+	}
+	return v.value, true
+}
+
+// rateExpr is the per-second rate of a counter input, computed from its
+// last two values. A counter going backwards has no rate until its next
+// value.
+type rateExpr string
+
+func (e rateExpr) eval(inputs map[string]*derivedValue) (float64, bool) {
+	v, ok := inputs[string(e)]
+	if !ok || v.prevTime.IsZero() || !v.time.After(v.prevTime) || v.value < v.prev {
+		return 0, false
+	}
+	return (v.value - v.prev) / v.time.Sub(v.prevTime).Seconds(), true
+}
+
+type negExpr struct {
+	e expr
+}
+
+func (e negExpr) eval(inputs map[string]*derivedValue) (float64, bool) {
+	v, ok := e.e.eval(inputs)
+	return -v, ok
+}
+
+type binaryExpr struct {
+	op   byte
+	l, r expr
+}
+
+func (e binaryExpr) eval(inputs map[string]*derivedValue) (float64, bool) {
+	l, ok := e.l.eval(inputs)
+	if !ok {
+		return 0, false
+	}
+	r, ok := e.r.eval(inputs)
+	if !ok {
+		return 0, false
+	}
+	switch e.op {
+	case '+':
+		return l + r, true
+	case '-':
+		return l - r, true
+	case '*':
+		return l * r, true
+	default:
+		if r == 0 {
+			return 0, false
+		}
+		return l / r, true
+	}
+}
+
+// exprParser is a recursive descent parser of the derived metric
+// expressions:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = number | input | "rate(" input ")" | "(" expr ")"
+type exprParser struct {
+	s      string
+	pos    int
+	inputs map[string]string
+}
+
+// parseExpr parses s, whose identifiers must be keys of inputs.
+func parseExpr(s string, inputs map[string]string) (expr, error) {
+	p := &exprParser{s: s, inputs: inputs}
+	e, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.s[p.pos:], p.pos)
+	}
+	return e, nil
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume skips c and returns true if it is the next character.
+func (p *exprParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expr() (expr, error) {
+	e, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		if p.consume('+') {
+			op = '+'
+		} else if p.consume('-') {
+			op = '-'
+		} else {
+			return e, nil
+		}
+		r, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		e = binaryExpr{op: op, l: e, r: r}
+	}
+}
+
+func (p *exprParser) term() (expr, error) {
+	e, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		if p.consume('*') {
+			op = '*'
+		} else if p.consume('/') {
+			op = '/'
+		} else {
+			return e, nil
+		}
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		e = binaryExpr{op: op, l: e, r: r}
+	}
+}
+
+func (p *exprParser) unary() (expr, error) {
+	if p.consume('-') {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return negExpr{e: e}, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (expr, error) {
+	if p.consume('(') {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, fmt.Errorf("missing ) at offset %d", p.pos)
+		}
+		return e, nil
+	}
+	p.skipSpaces()
+	start := p.pos
+	if p.pos < len(p.s) && (isDigit(p.s[p.pos]) || p.s[p.pos] == '.') {
+		for p.pos < len(p.s) && (isDigit(p.s[p.pos]) || p.s[p.pos] == '.') {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.s[start:p.pos])
+		}
+		return numberExpr(f), nil
+	}
+	name := p.ident()
+	if name == "" {
+		if p.pos == len(p.s) {
+			return nil, fmt.Errorf("unexpected end of expression")
+		}
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.s[p.pos:], p.pos)
+	}
+	if p.consume('(') {
+		if name != "rate" {
+			return nil, fmt.Errorf("unknown function %q", name)
+		}
+		p.skipSpaces()
+		arg := p.ident()
+		if _, ok := p.inputs[arg]; !ok {
+			return nil, fmt.Errorf("rate of unknown input %q", arg)
+		}
+		if !p.consume(')') {
+			return nil, fmt.Errorf("missing ) at offset %d", p.pos)
+		}
+		return rateExpr(arg), nil
+	}
+	if _, ok := p.inputs[name]; !ok {
+		return nil, fmt.Errorf("unknown input %q", name)
+	}
+	return inputExpr(name), nil
+}
+
+// ident returns the identifier at the current position, if any.
+func (p *exprParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') &&
+			!(p.pos > start && isDigit(c)) {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestParseExpr(t *testing.T) {
+	inputs := map[string]string{"a": "", "b": "", "in_octets": ""}
+	values := map[string]*derivedValue{
+		"a": {value: 6},
+		"b": {value: 2},
+		"in_octets": {value: 300, time: time.Unix(10, 0),
+			prev: 100, prevTime: time.Unix(0, 0)},
+	}
+	for s, exp := range map[string]float64{
+		"1 + 2 * 3":                7,
+		"(1 + 2) * 3":              9,
+		"a - b - 1":                3,
+		"a / b / 3":                1,
+		"-(a - b) * 0.5":           -2,
+		"--a":                      6,
+		"rate(in_octets) * 8 / b":  80,
+		"rate( in_octets )+a*b":    32,
+		"100*rate(in_octets)/1000": 2,
+	} {
+		e, err := parseExpr(s, inputs)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", s, err)
+			continue
+		}
+		if got, ok := e.eval(values); !ok || got != exp {
+			t.Errorf("%q: expected %v, got %v, %v", s, exp, got, ok)
+		}
+	}
+
+	for _, s := range []string{"", "a +", "(a", "a b", "c", "rate(c)", "avg(a)", "1..2", "a)"} {
+		if _, err := parseExpr(s, inputs); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+
+	for _, s := range []string{"a / 0", "rate(a)", "c"} {
+		e, err := parseExpr(s, map[string]string{"a": "", "c": ""})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := e.eval(values); ok {
+			t.Errorf("%q: expected no value, got %v", s, got)
+		}
+	}
+}
+
+func TestDerivedMetrics(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+derived-metrics:
+        - name: intfUtilization
+          help: Interface utilization in percent
+          inputs:
+                inoctets: /interfaces/interface\[name=(?P<intf>[^\]]+)\]/state/counters/in-octets
+                speed: /interfaces/interface\[name=(?P<intf>[^\]]+)\]/state/speed
+          expression: rate(inoctets) * 8 * 100 / speed`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := newCollector(cfg, nil)
+	update := func(ts int64, intf, leaf, val string) {
+		coll.update("10.1.1.1:6042", "", makeResponse(&pb.Notification{
+			Timestamp: ts * int64(time.Second),
+			Prefix:    makePath("interfaces/interface[name=" + intf + "]/state"),
+			Update: []*pb.Update{{
+				Path: makePath(leaf),
+				Val:  &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: []byte(val)}},
+			}},
+		}))
+	}
+	value := func(intf string) (float64, bool) {
+		t.Helper()
+		s, ok := coll.derived[derivedKey{name: "intfUtilization", addr: "10.1.1.1",
+			labels: intf}]
+		if !ok || s.metric == nil {
+			return 0, false
+		}
+		var m dto.Metric
+		if err := s.metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if len(m.Label) != 1 || m.Label[0].GetName() != "intf" ||
+			m.Label[0].GetValue() != intf {
+			t.Errorf("unexpected labels %v", m.Label)
+		}
+		return m.GetGauge().GetValue(), true
+	}
+
+	update(100, "Ethernet1", "speed", "1000")
+	update(100, "Ethernet1", "counters/in-octets", "1000")
+	if v, ok := value("Ethernet1"); ok {
+		t.Fatalf("expected no value without a rate, got %v", v)
+	}
+	update(110, "Ethernet1", "counters/in-octets", "1250")
+	if v, ok := value("Ethernet1"); !ok || v != 20 {
+		t.Fatalf("expected a utilization of 20, got %v, %v", v, ok)
+	}
+	// An update of any input evaluates the expression again.
+	update(111, "Ethernet1", "speed", "400")
+	if v, ok := value("Ethernet1"); !ok || v != 50 {
+		t.Fatalf("expected a utilization of 50, got %v, %v", v, ok)
+	}
+	// The inputs of other labels are not combined.
+	update(110, "Ethernet2", "counters/in-octets", "0")
+	update(120, "Ethernet2", "counters/in-octets", "500")
+	if v, ok := value("Ethernet2"); ok {
+		t.Fatalf("expected no value without a speed, got %v", v)
+	}
+	if v, ok := value("Ethernet1"); !ok || v != 50 {
+		t.Fatalf("expected a utilization of 50, got %v, %v", v, ok)
+	}
+
+	coll.update("10.1.1.1:6042", "", makeResponse(&pb.Notification{
+		Delete: []*pb.Path{makePath("interfaces/interface[name=Ethernet1]")}}))
+	if _, ok := value("Ethernet1"); ok || len(coll.derived) != 1 {
+		t.Errorf("expected the series of Ethernet1 to be deleted, got %v", coll.derived)
+	}
+}
+
+func TestParseDerivedMetricsErrors(t *testing.T) {
+	for name, cfg := range map[string]string{
+		"no inputs": `
+derived-metrics:
+        - name: m
+          expression: "1"`,
+		"different labels": `
+derived-metrics:
+        - name: m
+          inputs:
+                a: /a/(?P<x>.+)
+                b: /b/(?P<y>.+)
+          expression: a + b`,
+		"unknown input": `
+derived-metrics:
+        - name: m
+          inputs:
+                a: /a
+          expression: a + b`,
+	} {
+		if _, err := parseConfig([]byte(cfg)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -9,11 +9,14 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"os"
+	"os/signal"
 	"regexp"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/aristanetworks/goarista/gnmi"
 
@@ -21,7 +24,6 @@ import (
 	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"golang.org/x/sync/errgroup"
 )
 
 // regex to match tags in descriptions e.g. "[foo][bar=baz]"
@@ -45,41 +47,103 @@ func main() {
 		fmt.Sprintf("Set minimum TLS version for connection (%s)", gnmi.TLSVersions))
 	flag.StringVar(&gNMIcfg.TLSMaxVersion, "tls-max-version", "",
 		fmt.Sprintf("Set maximum TLS version for connection (%s)", gnmi.TLSVersions))
+	flag.StringVar(&gNMIcfg.Proxy, "proxy", "", gnmi.ProxyUsage)
+	gnmi.RPCLogFlag(gNMIcfg)
 	subscribePaths := flag.String("subscribe", "/", "Comma-separated list of paths to subscribe to")
+	pathsFile := flag.String("paths_file", "", "File with the paths to subscribe to, one "+
+		"[ORIGIN:]PATH per line, with # comments and 'include FILE' lines")
 
 	// program options
-	listenaddr := flag.String("listenaddr", ":8080", "Address on which to expose the metrics")
+	listenaddr := flag.String("listenaddr", ":8080", "Comma-separated list of addresses "+
+		"on which to expose the metrics, e.g. '0.0.0.0:8080,[::1]:8080'")
+	var web webConfig
+	flag.StringVar(&web.certFile, "web-tls-cert", "",
+		"Path to the TLS certificate file to expose the metrics over HTTPS")
+	flag.StringVar(&web.keyFile, "web-tls-key", "",
+		"Path to the TLS private key file to expose the metrics over HTTPS")
+	flag.StringVar(&web.clientCAFile, "web-client-ca", "",
+		"Path to the CA file to verify the certificates required from the clients with")
+	flag.StringVar(&web.username, "web-basic-auth-username", "",
+		"Username required from the clients with basic auth")
+	flag.StringVar(&web.passwordFile, "web-basic-auth-password-file", "",
+		"Path to a file containing the password required from the clients with basic auth")
 	url := flag.String("url", "/metrics", "URL where to expose the metrics")
 	configFlag := flag.String("config", "",
-		"Config to turn OpenConfig telemetry into Prometheus metrics")
+		"Config to turn OpenConfig telemetry into Prometheus metrics. "+
+			"The config is reloaded on SIGHUP")
+	watchConfigFlag := flag.Bool("watch-config", false,
+		"Also reload the config when the config file changes")
+	metricTTL := flag.Duration("metric-ttl", 0,
+		"Drop the series not updated within this duration (0 to never drop them)")
+	maxClockSkew := flag.Duration("max-clock-skew", 0, "Rewrite the timestamps of the "+
+		"notifications that differ from their receive time by more than this duration to the "+
+		"receive time, for targets with a broken clock (0 to disable)")
+	pathDeletes := flag.Bool("path-deletes-metric", false, "Export the number of gNMI "+
+		"deletes received by path without its list keys as telemetry_path_deletes_total")
+	transforms := gnmi.TransformFlag()
+	maxSeries := flag.Int("max-series", 0, "Maximum number of series exported, "+
+		"the least recently updated series are evicted beyond it (0 for no limit)")
+	remoteWriteURL := flag.String("remote-write-url", "",
+		"URL of a Prometheus remote write endpoint to also push the metrics to")
+	remoteWriteInterval := flag.Duration("remote-write-interval", 15*time.Second,
+		"Interval between the pushes of the metrics to the remote write endpoint")
+	remoteWriteBatchSize := flag.Int("remote-write-batch-size", 500,
+		"Maximum number of series per remote write request")
+	remoteWriteTimeout := flag.Duration("remote-write-timeout", 10*time.Second,
+		"Timeout of the remote write requests")
+
+	flag.String(gnmi.ConfigFileFlag, "", "Path to a YAML or JSON gNMI config file "+
+		"setting the flags not set on the command line, with the flags of the "+
+		"'ocprometheus' section")
 
 	flag.Parse()
+	if err := gnmi.ApplyConfigFile("ocprometheus"); err != nil {
+		glog.Fatal(err)
+	}
 	subscriptions := strings.Split(*subscribePaths, ",")
 	if *configFlag == "" {
 		glog.Fatal("You need specify a config file using -config flag")
 	}
-	cfg, err := ioutil.ReadFile(*configFlag)
-	if err != nil {
-		glog.Fatalf("Can't read config file %q: %v", *configFlag, err)
-	}
-	config, err := parseConfig(cfg)
-	if err != nil {
-		glog.Fatal(err)
+	if *remoteWriteURL != "" && *remoteWriteInterval <= 0 {
+		glog.Fatal("-remote-write-interval must be positive")
 	}
-
 	// Ignore the default "subscribe-to-everything" subscription of the
 	// -subscribe flag.
 	if subscriptions[0] == "/" {
 		subscriptions = subscriptions[1:]
 	}
+	if *pathsFile != "" {
+		entries, err := gnmi.ReadPathsFile(*pathsFile)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		for _, e := range entries {
+			if e.Interval > 0 {
+				glog.Warningf("Ignoring the interval of %s, the paths are subscribed with "+
+					"target_defined", e)
+				e.Interval = 0
+			}
+			subscriptions = append(subscriptions, e.String())
+		}
+	}
 	// Add to the subscriptions in the config file.
-	config.addSubscriptions(subscriptions)
+	config, err := loadConfig(*configFlag, subscriptions)
+	if err != nil {
+		glog.Fatal(err)
+	}
 
 	var r *regexp.Regexp
 	if *enableDynDescs {
 		r = regexp.MustCompile(*descRegex)
 	}
 	coll := newCollector(config, r)
+	coll.ttl = *metricTTL
+	coll.maxSeries = *maxSeries
+	if *pathDeletes {
+		coll.pathDeletes = map[string]uint64{}
+	}
+	coll.skew = gnmi.NewTargetSkewDetector(nil, *maxClockSkew, *maxClockSkew > 0)
+	coll.transforms = *transforms
 	prometheus.MustRegister(coll)
 	ctx := gnmi.NewContext(context.Background(), gNMIcfg)
 	client, err := gnmi.Dial(gNMIcfg)
@@ -87,7 +151,8 @@ func main() {
 		glog.Fatal(err)
 	}
 
-	g, gCtx := errgroup.WithContext(ctx)
+	gCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	if *enableDynDescs {
 		// wait for initial sync to complete before continuing
 		wg := &sync.WaitGroup{}
@@ -101,32 +166,61 @@ func main() {
 		wg.Wait()
 	}
 
-	for origin, paths := range config.subsByOrigin {
-		subscribeOptions := &gnmi.SubscribeOptions{
-			Mode:       "stream",
-			StreamMode: "target_defined",
-			Paths:      gnmi.SplitPaths(paths),
-			Origin:     origin,
+	sub := newSubscriber(gCtx, client, coll, gNMIcfg.Addr)
+	sub.update(config.subsByOrigin)
+
+	// The description label subscriptions are not reloaded.
+	reloadc := make(chan struct{}, 1)
+	if *watchConfigFlag {
+		if err := watchConfig(gCtx, *configFlag, reloadc); err != nil {
+			glog.Fatalf("Failed to watch config file %q: %s", *configFlag, err)
 		}
-		g.Go(func() error {
-			return handleSubscription(gCtx, client, subscribeOptions, coll,
-				gNMIcfg.Addr)
-		})
 	}
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	if *remoteWriteURL != "" {
+		w := newRemoteWriter(*remoteWriteURL, prometheus.DefaultGatherer,
+			*remoteWriteInterval, *remoteWriteTimeout, *remoteWriteBatchSize)
+		go w.run(gCtx)
+	}
+
 	http.Handle(*url, promhttp.Handler())
-	go http.ListenAndServe(*listenaddr, nil)
-	if err := g.Wait(); err != nil {
+	httpErrc := make(chan error, 1)
+	if _, err := web.listenAndServe(*listenaddr, http.DefaultServeMux, httpErrc); err != nil {
 		glog.Fatal(err)
 	}
+	for {
+		select {
+		case err := <-sub.errc:
+			glog.Fatal(err)
+		case err := <-httpErrc:
+			glog.Fatal(err)
+		case <-sighup:
+			reload(*configFlag, subscriptions, coll, sub)
+		case <-reloadc:
+			reload(*configFlag, subscriptions, coll, sub)
+		}
+	}
 }
 
 func handleSubscription(ctx context.Context, client pb.GNMIClient,
 	subscribeOptions *gnmi.SubscribeOptions, coll *collector,
 	addr string) error {
 	respChan := make(chan *pb.SubscribeResponse)
+	var responses <-chan *pb.SubscribeResponse = respChan
+	if coll.skew != nil {
+		// The device of the notifications without target is the one of
+		// their metrics.
+		responses = gnmi.TargetSkewSubscribeResponses(respChan, coll.skew,
+			strings.Split(addr, ":")[0])
+	}
+	if len(coll.transforms) > 0 {
+		responses = gnmi.TransformSubscribeResponses(responses, coll.transforms)
+	}
 	go func() {
-		for resp := range respChan {
-			coll.update(addr, resp)
+		for resp := range responses {
+			coll.update(addr, subscribeOptions.Origin, resp)
 		}
 	}()
 	return gnmi.SubscribeErr(ctx, client, subscribeOptions, respChan)
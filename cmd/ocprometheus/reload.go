@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/aristanetworks/goarista/gnmi"
+
+	"github.com/aristanetworks/fsnotify"
+	"github.com/aristanetworks/glog"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// loadConfig reads and parses the config file at path and adds the
+// subscriptions given on the command line to it.
+func loadConfig(path string, subscriptions []string) (*Config, error) {
+	cfg, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read config file %q: %v", path, err)
+	}
+	config, err := parseConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	config.addSubscriptions(subscriptions)
+	config.addOriginLabels()
+	return config, nil
+}
+
+// subscription is a running gNMI subscription to the paths of one origin.
+type subscription struct {
+	paths  []string
+	cancel context.CancelFunc
+}
+
+// subscriber keeps one gNMI subscription per origin running, feeding the
+// responses to the collector.
+type subscriber struct {
+	ctx    context.Context
+	client pb.GNMIClient
+	coll   *collector
+	addr   string
+	// errc receives the errors of the subscriptions that were not canceled.
+	errc chan error
+	subs map[string]*subscription
+}
+
+func newSubscriber(ctx context.Context, client pb.GNMIClient, coll *collector,
+	addr string) *subscriber {
+	return &subscriber{
+		ctx:    ctx,
+		client: client,
+		coll:   coll,
+		addr:   addr,
+		errc:   make(chan error, 1),
+		subs:   make(map[string]*subscription),
+	}
+}
+
+// update diffs subsByOrigin with the running subscriptions, canceling the
+// subscriptions of the origins removed or whose paths changed and starting
+// the new ones. Subscriptions whose paths didn't change are left untouched.
+func (s *subscriber) update(subsByOrigin map[string][]string) {
+	for origin, sub := range s.subs {
+		paths, ok := subsByOrigin[origin]
+		if ok && equalPaths(paths, sub.paths) {
+			continue
+		}
+		glog.Infof("Removing subscription to %q for origin %q", sub.paths, origin)
+		sub.cancel()
+		delete(s.subs, origin)
+	}
+	for origin, paths := range subsByOrigin {
+		if _, ok := s.subs[origin]; ok || len(paths) == 0 {
+			continue
+		}
+		glog.Infof("Adding subscription to %q for origin %q", paths, origin)
+		origin := origin
+		ctx, cancel := context.WithCancel(s.ctx)
+		s.subs[origin] = &subscription{paths: paths, cancel: cancel}
+		subscribeOptions := &gnmi.SubscribeOptions{
+			Mode:       "stream",
+			StreamMode: "target_defined",
+			Paths:      gnmi.SplitPaths(paths),
+			Origin:     origin,
+		}
+		go func() {
+			err := handleSubscription(ctx, s.client, subscribeOptions, s.coll, s.addr)
+			if ctx.Err() != nil {
+				// Canceled by a reload or on exit
+				return
+			}
+			if err == nil {
+				err = fmt.Errorf("subscription for origin %q ended", origin)
+			}
+			select {
+			case s.errc <- err:
+			default:
+			}
+		}()
+	}
+}
+
+// equalPaths returns whether a and b hold the same paths, in any order.
+func equalPaths(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
+
+// reload reloads the config file at path and applies it to the collector
+// and the subscriptions. On error the current config is kept.
+func reload(path string, subscriptions []string, coll *collector, sub *subscriber) {
+	config, err := loadConfig(path, subscriptions)
+	if err != nil {
+		glog.Errorf("Failed to reload config, keeping the current one: %s", err)
+		return
+	}
+	coll.setConfig(config)
+	sub.update(config.subsByOrigin)
+	glog.Infof("Reloaded config %q", path)
+}
+
+// watchConfig sends to reloadc when the config file at path is written or
+// replaced. The directory of path is watched rather than path itself so that
+// files replaced by a rename, as editors and config management tools do, keep
+// being watched.
+func watchConfig(ctx context.Context, path string, reloadc chan<- struct{}) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return err
+	}
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev := <-w.Events:
+				if filepath.Clean(ev.Name) != filepath.Clean(path) ||
+					ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				select {
+				case reloadc <- struct{}{}:
+				default:
+					// A reload is already pending
+				}
+			case err := <-w.Errors:
+				glog.Errorf("Error watching config file %q: %s", path, err)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestSetConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+subscriptions:
+        - /Sysdb/environment
+metrics:
+        - name: fanSpeed
+          path: /Sysdb/environment/cooling/status/fan/speed/value
+          help: Fan Speed
+        - name: fanName
+          path: /Sysdb/environment/cooling/status/fan/name
+          help: Fan Name
+          valuelabel: name
+        - name: temperature
+          path: /Sysdb/environment/temperature/(?P<sensor>.+)
+          help: Temperature`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := newCollector(cfg, nil)
+	notif := &pb.Notification{
+		Prefix: makePath("Sysdb/environment"),
+		Update: []*pb.Update{{
+			Path: makePath("cooling/status/fan/speed"),
+			Val: &pb.TypedValue{
+				Value: &pb.TypedValue_JsonVal{JsonVal: []byte(`{"value": 45}`)}},
+		}, {
+			Path: makePath("cooling/status/fan/name"),
+			Val: &pb.TypedValue{
+				Value: &pb.TypedValue_JsonVal{JsonVal: []byte(`"Fan1.1"`)}},
+		}, {
+			Path: makePath("temperature/TempSensor1"),
+			Val: &pb.TypedValue{
+				Value: &pb.TypedValue_JsonVal{JsonVal: []byte(`30`)}},
+		}},
+	}
+	coll.update("10.1.1.1:6042", "", makeResponse(notif))
+	if len(coll.metrics) != 3 {
+		t.Fatalf("expected 3 metrics, got %d", len(coll.metrics))
+	}
+
+	// Rename fanSpeed, keep fanName, drop temperature.
+	newCfg, err := parseConfig([]byte(`
+subscriptions:
+        - /Sysdb/environment/cooling
+metrics:
+        - name: fanRPM
+          path: /Sysdb/environment/cooling/status/fan/speed/value
+          help: Fan Speed
+        - name: fanName
+          path: /Sysdb/environment/cooling/status/fan/name
+          help: Fan Name
+          valuelabel: name
+          defaultvalue: 3`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll.setConfig(newCfg)
+
+	expected := map[string]struct {
+		name   string
+		value  float64
+		labels []string
+	}{
+		"/Sysdb/environment/cooling/status/fan/speed/value": {
+			name: "fanRPM", value: 45, labels: []string{}},
+		"/Sysdb/environment/cooling/status/fan/name": {
+			name: "fanName", value: 3, labels: []string{"Fan1.1"}},
+	}
+	if len(coll.metrics) != len(expected) {
+		t.Fatalf("expected %d metrics, got %d", len(expected), len(coll.metrics))
+	}
+	for src, m := range coll.metrics {
+		exp, ok := expected[src.path]
+		if !ok {
+			t.Errorf("unexpected metric for %s", src.path)
+			continue
+		}
+		checkMetric(t, m, exp.name, exp.value, exp.labels)
+	}
+}
+
+func checkMetric(t *testing.T, m *labelledMetric, name string, value float64,
+	labels []string) {
+	t.Helper()
+	if desc := m.metric.Desc().String(); !strings.Contains(desc, `fqName: "`+name+`"`) {
+		t.Errorf("expected metric %s, got %s", name, desc)
+	}
+	if m.floatVal != value {
+		t.Errorf("%s: expected value %v, got %v", name, value, m.floatVal)
+	}
+	if !test.DeepEqual(m.labels, labels) {
+		t.Errorf("%s: expected labels %q, got %q", name, labels, m.labels)
+	}
+}
+
+func TestEqualPaths(t *testing.T) {
+	for _, tc := range []struct {
+		a, b []string
+		exp  bool
+	}{
+		{a: nil, b: nil, exp: true},
+		{a: []string{"/a", "/b"}, b: []string{"/b", "/a"}, exp: true},
+		{a: []string{"/a"}, b: []string{"/a", "/b"}, exp: false},
+		{a: []string{"/a", "/c"}, b: []string{"/a", "/b"}, exp: false},
+	} {
+		if got := equalPaths(tc.a, tc.b); got != tc.exp {
+			t.Errorf("equalPaths(%q, %q): expected %t, got %t", tc.a, tc.b, tc.exp, got)
+		}
+	}
+}
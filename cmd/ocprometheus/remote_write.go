@@ -0,0 +1,240 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aristanetworks/glog"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriter periodically pushes the metrics of a gatherer to a
+// Prometheus remote write endpoint, for the deployments where the exporter
+// cannot be scraped. The samples are not persisted: the samples of a push
+// still failing after retrying for one interval are dropped.
+type remoteWriter struct {
+	url      string
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	// interval is the time between pushes, retries included.
+	interval time.Duration
+	// batchSize is the maximum number of series per remote write request.
+	batchSize int
+
+	// now returns the timestamp of the samples without one.
+	now func() time.Time
+	// newBackOff returns the backoff between the retries of a request.
+	newBackOff func() backoff.BackOff
+}
+
+func newRemoteWriter(url string, gatherer prometheus.Gatherer, interval, timeout time.Duration,
+	batchSize int) *remoteWriter {
+	return &remoteWriter{
+		url:       url,
+		gatherer:  gatherer,
+		client:    &http.Client{Timeout: timeout},
+		interval:  interval,
+		batchSize: batchSize,
+		now:       time.Now,
+		newBackOff: func() backoff.BackOff {
+			bo := backoff.NewExponentialBackOff()
+			bo.MaxElapsedTime = interval
+			return bo
+		},
+	}
+}
+
+// run pushes the metrics every interval until ctx is done.
+func (w *remoteWriter) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.push(ctx); err != nil {
+			glog.Errorf("Failed to push metrics to %s: %s", w.url, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// push gathers the metrics and sends them in batches of at most batchSize
+// series.
+func (w *remoteWriter) push(ctx context.Context) error {
+	families, err := w.gatherer.Gather()
+	if err != nil {
+		// Gather returns the metrics it could gather along with the error.
+		glog.Errorf("Failed to gather some metrics: %s", err)
+	}
+	series := timeSeriesFromFamilies(families, w.now().UnixMilli())
+	ctx, cancel := context.WithTimeout(ctx, w.interval)
+	defer cancel()
+	for len(series) > 0 {
+		n := len(series)
+		if w.batchSize > 0 && n > w.batchSize {
+			n = w.batchSize
+		}
+		if err := w.send(ctx, encodeWriteRequest(series[:n])); err != nil {
+			return err
+		}
+		series = series[n:]
+	}
+	return nil
+}
+
+// send sends a remote write request, retrying on network errors, rate
+// limiting and server errors.
+func (w *remoteWriter) send(ctx context.Context, req []byte) error {
+	body := snappy.Encode(nil, req)
+	op := func() error {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url,
+			bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		httpReq.Header.Set("Content-Encoding", "snappy")
+		httpReq.Header.Set("Content-Type", "application/x-protobuf")
+		httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		resp, err := w.client.Do(httpReq)
+		if err != nil {
+			return err
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		if resp.StatusCode/100 == 2 {
+			return nil
+		}
+		err = fmt.Errorf("remote write returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5 {
+			return err
+		}
+		return backoff.Permanent(err)
+	}
+	return backoff.RetryNotify(op, backoff.WithContext(w.newBackOff(), ctx),
+		func(err error, d time.Duration) {
+			glog.V(1).Infof("Retrying remote write to %s in %s: %s", w.url, d, err)
+		})
+}
+
+type label struct {
+	name, value string
+}
+
+type timeSeries struct {
+	labels    []label
+	value     float64
+	timestamp int64
+}
+
+// timeSeriesFromFamilies returns the time series of the metrics of
+// families, with the series of summaries and histograms expanded as in the
+// text exposition format. Samples without a timestamp are given nowMs.
+func timeSeriesFromFamilies(families []*dto.MetricFamily, nowMs int64) []timeSeries {
+	var series []timeSeries
+	for _, family := range families {
+		name := family.GetName()
+		for _, m := range family.Metric {
+			ts := nowMs
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			add := func(name string, value float64, extra ...label) {
+				labels := make([]label, 0, len(m.Label)+len(extra)+1)
+				labels = append(labels, label{"__name__", name})
+				for _, l := range m.Label {
+					labels = append(labels, label{l.GetName(), l.GetValue()})
+				}
+				labels = append(labels, extra...)
+				// Remote write requires the labels to be sorted by name.
+				sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+				series = append(series, timeSeries{labels: labels, value: value, timestamp: ts})
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.Quantile {
+					add(name, q.GetValue(), label{"quantile", formatFloat(q.GetQuantile())})
+				}
+				add(name+"_sum", s.GetSampleSum())
+				add(name+"_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				var inf bool
+				for _, b := range h.Bucket {
+					inf = inf || math.IsInf(b.GetUpperBound(), 1)
+					add(name+"_bucket", float64(b.GetCumulativeCount()),
+						label{"le", formatFloat(b.GetUpperBound())})
+				}
+				if !inf {
+					add(name+"_bucket", float64(h.GetSampleCount()), label{"le", "+Inf"})
+				}
+				add(name+"_sum", h.GetSampleSum())
+				add(name+"_count", float64(h.GetSampleCount()))
+			default:
+				add(name, m.GetUntyped().GetValue())
+			}
+		}
+	}
+	return series
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest returns the protobuf encoding of a remote write
+// WriteRequest message with series:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []timeSeries) []byte {
+	var b, ts, msg []byte
+	for _, s := range series {
+		ts = ts[:0]
+		for _, l := range s.labels {
+			msg = msg[:0]
+			msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+			msg = protowire.AppendString(msg, l.name)
+			msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+			msg = protowire.AppendString(msg, l.value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, msg)
+		}
+		msg = msg[:0]
+		msg = protowire.AppendTag(msg, 1, protowire.Fixed64Type)
+		msg = protowire.AppendFixed64(msg, math.Float64bits(s.value))
+		msg = protowire.AppendTag(msg, 2, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, uint64(s.timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, msg)
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return b
+}
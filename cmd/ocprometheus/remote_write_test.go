@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest decodes a WriteRequest encoded by encodeWriteRequest.
+func decodeWriteRequest(t *testing.T, b []byte) []timeSeries {
+	t.Helper()
+	// fields calls fn with the number and the value of each field of msg.
+	fields := func(msg []byte, fn func(num protowire.Number, v []byte, u uint64)) {
+		for len(msg) > 0 {
+			num, typ, n := protowire.ConsumeTag(msg)
+			if n < 0 {
+				t.Fatalf("invalid tag: %s", protowire.ParseError(n))
+			}
+			msg = msg[n:]
+			var v []byte
+			var u uint64
+			switch typ {
+			case protowire.BytesType:
+				v, n = protowire.ConsumeBytes(msg)
+			case protowire.Fixed64Type:
+				u, n = protowire.ConsumeFixed64(msg)
+			case protowire.VarintType:
+				u, n = protowire.ConsumeVarint(msg)
+			default:
+				t.Fatalf("unexpected wire type %d", typ)
+			}
+			if n < 0 {
+				t.Fatalf("invalid field %d: %s", num, protowire.ParseError(n))
+			}
+			msg = msg[n:]
+			fn(num, v, u)
+		}
+	}
+	var series []timeSeries
+	fields(b, func(_ protowire.Number, ts []byte, _ uint64) {
+		var s timeSeries
+		fields(ts, func(num protowire.Number, msg []byte, _ uint64) {
+			switch num {
+			case 1:
+				var l label
+				fields(msg, func(num protowire.Number, v []byte, _ uint64) {
+					if num == 1 {
+						l.name = string(v)
+					} else {
+						l.value = string(v)
+					}
+				})
+				s.labels = append(s.labels, l)
+			case 2:
+				fields(msg, func(num protowire.Number, _ []byte, u uint64) {
+					if num == 1 {
+						s.value = math.Float64frombits(u)
+					} else {
+						s.timestamp = int64(u)
+					}
+				})
+			}
+		})
+		series = append(series, s)
+	})
+	return series
+}
+
+func TestRemoteWriter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total"},
+		[]string{"code"})
+	counter.WithLabelValues("200").Add(3)
+	hist := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency",
+		Buckets: []float64{1}})
+	hist.Observe(0.5)
+	hist.Observe(2)
+	reg.MustRegister(counter, hist)
+
+	var mu sync.Mutex
+	var received [][]timeSeries
+	fail := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail > 0 {
+			fail--
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "snappy" ||
+			r.Header.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+			http.Error(w, "unexpected headers", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		req, err := snappy.Decode(nil, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received = append(received, decodeWriteRequest(t, req))
+	}))
+	defer srv.Close()
+
+	w := newRemoteWriter(srv.URL, reg, time.Minute, time.Second, 4)
+	w.now = func() time.Time { return time.UnixMilli(1000) }
+	w.newBackOff = func() backoff.BackOff {
+		return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 3)
+	}
+	if err := w.push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	series := func(name string, value float64, labels ...label) timeSeries {
+		return timeSeries{labels: append([]label{{"__name__", name}}, labels...),
+			value: value, timestamp: 1000}
+	}
+	expected := [][]timeSeries{{
+		series("latency_bucket", 1, label{"le", "1"}),
+		series("latency_bucket", 2, label{"le", "+Inf"}),
+		series("latency_sum", 2.5),
+		series("latency_count", 2),
+	}, {
+		{labels: []label{{"__name__", "requests_total"}, {"code", "200"}},
+			value: 3, timestamp: 1000},
+	}}
+	if !test.DeepEqual(expected, received) {
+		t.Errorf("unexpected requests: %s", test.Diff(expected, received))
+	}
+
+	// Client errors are not retried.
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fail++
+		http.Error(w, "bad request", http.StatusBadRequest)
+	})
+	if err := w.push(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if fail != 1 {
+		t.Errorf("expected 1 request, got %d", fail)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// webConfig secures the HTTP server exposing the metrics.
+type webConfig struct {
+	// certFile and keyFile are the certificate and key of the server. The
+	// server uses plain HTTP without them.
+	certFile string
+	keyFile  string
+	// clientCAFile is the CA the certificates required from the clients
+	// are verified against.
+	clientCAFile string
+	// username and passwordFile are the basic auth credentials required
+	// from the clients.
+	username     string
+	passwordFile string
+}
+
+// tlsConfig returns the TLS config of the server, or nil to use plain HTTP.
+func (c *webConfig) tlsConfig() (*tls.Config, error) {
+	if c.certFile == "" && c.keyFile == "" {
+		if c.clientCAFile != "" {
+			return nil, errors.New("-web-client-ca requires -web-tls-cert and -web-tls-key")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the web TLS certificate: %s", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.clientCAFile != "" {
+		b, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %s", c.clientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// handler wraps h to require the basic auth credentials, if any.
+func (c *webConfig) handler(h http.Handler) (http.Handler, error) {
+	if c.username == "" {
+		if c.passwordFile != "" {
+			return nil, errors.New("-web-basic-auth-password-file requires " +
+				"-web-basic-auth-username")
+		}
+		return h, nil
+	}
+	if c.passwordFile == "" {
+		return nil, errors.New("-web-basic-auth-username requires " +
+			"-web-basic-auth-password-file")
+	}
+	b, err := os.ReadFile(c.passwordFile)
+	if err != nil {
+		return nil, err
+	}
+	// The hashes are compared so that the comparison takes the same time
+	// whatever the length of the credentials.
+	username := sha256.Sum256([]byte(c.username))
+	password := sha256.Sum256([]byte(strings.TrimRight(string(b), "\r\n")))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		uh, ph := sha256.Sum256([]byte(u)), sha256.Sum256([]byte(p))
+		if !ok || subtle.ConstantTimeCompare(uh[:], username[:])&
+			subtle.ConstantTimeCompare(ph[:], password[:]) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="ocprometheus"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}), nil
+}
+
+// listenAndServe listens on each of the comma-separated addrs, e.g. an
+// IPv4 and an IPv6 address, and serves handler on them with the security
+// of c. It returns the addresses listened on or the failure to listen, and
+// the failures to serve afterwards are sent to errc.
+func (c *webConfig) listenAndServe(addrs string, handler http.Handler,
+	errc chan<- error) ([]net.Addr, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if handler, err = c.handler(handler); err != nil {
+		return nil, err
+	}
+	var listeners []net.Listener
+	for _, addr := range strings.Split(addrs, ",") {
+		l, err := net.Listen("tcp", strings.TrimSpace(addr))
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		if tlsConfig != nil {
+			l = tls.NewListener(l, tlsConfig)
+		}
+		listeners = append(listeners, l)
+	}
+	listened := make([]net.Addr, len(listeners))
+	for i, l := range listeners {
+		listened[i] = l.Addr()
+		srv := &http.Server{Handler: handler}
+		go func(l net.Listener) {
+			errc <- srv.Serve(l)
+		}(l)
+	}
+	return listened, nil
+}
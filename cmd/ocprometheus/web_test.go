@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a certificate and its key signed by parent, or self-signed
+// if parent is nil, and returns them with the paths of their files.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey,
+		parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, certFile, keyFile
+}
+
+func TestWebConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caFile, _ := writeCert(t, dir, "ca", nil, nil)
+	_, _, certFile, keyFile := writeCert(t, dir, "server", ca, caKey)
+	_, _, clientCertFile, clientKeyFile := writeCert(t, dir, "client", ca, caKey)
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	web := &webConfig{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: caFile,
+		username:     "prometheus",
+		passwordFile: passwordFile,
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("metrics"))
+	})
+	errc := make(chan error, 2)
+	addrs, err := web.listenAndServe("127.0.0.1:0,127.0.0.1:0", handler, errc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 2 {
+		t.Fatalf("expected 2 addresses, got %v", addrs)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		clientCert bool
+		username   string
+		password   string
+		status     int
+		err        bool
+	}{
+		"authorized": {clientCert: true, username: "prometheus", password: "secret",
+			status: http.StatusOK},
+		"wrong password": {clientCert: true, username: "prometheus", password: "secret\n",
+			status: http.StatusUnauthorized},
+		"no basic auth":  {clientCert: true, status: http.StatusUnauthorized},
+		"no client cert": {username: "prometheus", password: "secret", err: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tlsConfig := &tls.Config{RootCAs: roots}
+			if tc.clientCert {
+				tlsConfig.Certificates = []tls.Certificate{clientCert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			for _, addr := range addrs {
+				req, err := http.NewRequest("GET", "https://"+addr.String()+"/metrics", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tc.username != "" {
+					req.SetBasicAuth(tc.username, tc.password)
+				}
+				resp, err := client.Do(req)
+				if tc.err {
+					if err == nil {
+						resp.Body.Close()
+						t.Error("expected an error")
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tc.status {
+					t.Errorf("expected status %d, got %d", tc.status, resp.StatusCode)
+				}
+			}
+		})
+	}
+
+	for name, web := range map[string]*webConfig{
+		"client CA without cert":    {clientCAFile: caFile},
+		"missing key":               {certFile: certFile},
+		"username without password": {username: "prometheus"},
+		"password without username": {passwordFile: passwordFile},
+	} {
+		if _, err := web.listenAndServe("127.0.0.1:0", handler, errc); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
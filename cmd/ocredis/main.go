@@ -57,6 +57,8 @@ func main() {
 		fmt.Sprintf("Set minimum TLS version for connection (%s)", gnmi.TLSVersions))
 	flag.StringVar(&cfg.TLSMaxVersion, "tls-max-version", "",
 		fmt.Sprintf("Set maximum TLS version for connection (%s)", gnmi.TLSVersions))
+	flag.StringVar(&cfg.Proxy, "proxy", "", gnmi.ProxyUsage)
+	gnmi.RPCLogFlag(cfg)
 	subscribePaths := flag.String("subscribe", "/", "Comma-separated list of paths to subscribe to")
 	flag.Parse()
 	if *redisFlag == "" {
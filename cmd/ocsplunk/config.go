@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the representation of ocsplunk's YAML config file.
+type Config struct {
+	// Fields are static fields added to all the events, e.g. site or role.
+	Fields map[string]string
+
+	// Routes pick the index and sourcetype of the events. The first route
+	// matching the path of a notification applies.
+	Routes []*Route
+}
+
+// Route sends the notifications whose path matches to an index and
+// sourcetype.
+type Route struct {
+	// Path is a regexp matched against the beginning of the path of the
+	// notifications.
+	Path string
+
+	// Path compiled as a regexp.
+	re *regexp.Regexp
+
+	// Index and SourceType of the events. If empty, the -splunkindex flag
+	// and the default sourcetype are used.
+	Index      string
+	SourceType string `yaml:"sourcetype"`
+
+	// Fields are added to the events, overriding the fields of the config.
+	Fields map[string]string
+}
+
+// parseConfig parses the config file and compiles the route regexps.
+func parseConfig(cfg []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(cfg, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %s", err)
+	}
+	for i, r := range config.Routes {
+		re, err := regexp.Compile("^(?:" + r.Path + ")")
+		if err != nil {
+			return nil, fmt.Errorf("invalid path of route %d: %s", i+1, err)
+		}
+		r.re = re
+	}
+	return config, nil
+}
+
+func loadConfig(path string) (*Config, error) {
+	cfg, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config file %q: %s", path, err)
+	}
+	return parseConfig(cfg)
+}
+
+// route returns the index, sourcetype and fields of the events of the
+// notifications at path, defaulting to index and sourceType.
+func (c *Config) route(path, index, sourceType string) (string, string, map[string]string) {
+	fields := c.Fields
+	for _, r := range c.Routes {
+		if !r.re.MatchString(path) {
+			continue
+		}
+		if r.Index != "" {
+			index = r.Index
+		}
+		if r.SourceType != "" {
+			sourceType = r.SourceType
+		}
+		if len(r.Fields) > 0 {
+			fields = make(map[string]string, len(c.Fields)+len(r.Fields))
+			for k, v := range c.Fields {
+				fields[k] = v
+			}
+			for k, v := range r.Fields {
+				fields[k] = v
+			}
+		}
+		break
+	}
+	return index, sourceType, fields
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestRoute(t *testing.T) {
+	config, err := parseConfig([]byte(`
+fields:
+        site: paris
+        role: leaf
+routes:
+        - path: /interfaces/interface\[name=Management.*\]
+          index: mgmt
+          fields:
+                role: management
+        - path: /interfaces
+          index: network
+          sourcetype: openconfig:interfaces
+        - path: /system
+          sourcetype: openconfig:system`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		path       string
+		index      string
+		sourceType string
+		fields     map[string]string
+	}{{
+		path:       "/interfaces/interface[name=Management1]/state",
+		index:      "mgmt",
+		sourceType: "openconfig",
+		fields:     map[string]string{"site": "paris", "role": "management"},
+	}, {
+		path:       "/interfaces/interface[name=Ethernet1]/state",
+		index:      "network",
+		sourceType: "openconfig:interfaces",
+		fields:     map[string]string{"site": "paris", "role": "leaf"},
+	}, {
+		path:       "/system/state",
+		index:      "main",
+		sourceType: "openconfig:system",
+		fields:     map[string]string{"site": "paris", "role": "leaf"},
+	}, {
+		// Routes match the beginning of the path.
+		path:       "/network-instances/network-instance[name=default]/interfaces",
+		index:      "main",
+		sourceType: "openconfig",
+		fields:     map[string]string{"site": "paris", "role": "leaf"},
+	}} {
+		index, sourceType, fields := config.route(tc.path, "main", "openconfig")
+		if index != tc.index || sourceType != tc.sourceType {
+			t.Errorf("%s: expected index %q and sourcetype %q, got %q and %q",
+				tc.path, tc.index, tc.sourceType, index, sourceType)
+		}
+		if !test.DeepEqual(tc.fields, fields) {
+			t.Errorf("%s: unexpected fields: %s", tc.path, test.Diff(tc.fields, fields))
+		}
+	}
+
+	for _, cfg := range []string{
+		"routes:\n  - path: '['\n",
+		"routes:\n  - path: /a\n    indx: typo\n",
+	} {
+		if _, err := parseConfig([]byte(cfg)); err == nil {
+			t.Errorf("expected an error parsing %q", cfg)
+		}
+	}
+}
@@ -41,16 +41,35 @@ func main() {
 		fmt.Sprintf("Set minimum TLS version for connection (%s)", gnmi.TLSVersions))
 	flag.StringVar(&cfg.TLSMaxVersion, "tls-max-version", "",
 		fmt.Sprintf("Set maximum TLS version for connection (%s)", gnmi.TLSVersions))
+	flag.StringVar(&cfg.Proxy, "proxy", "", gnmi.ProxyUsage)
+	gnmi.RPCLogFlag(cfg)
 	subscribePaths := flag.String("paths", "/", "Comma-separated list of paths to subscribe to")
+	transforms := gnmi.TransformFlag()
+	downsampleInterval := flag.Duration("downsample_interval", 0, "Send at most one update "+
+		"of each path within this duration (e.g. 10s), the latest, to cut the volume of the "+
+		"paths updated at a high rate. Deletes are always sent (0 to send all the updates)")
+	aggregationWindow := flag.Duration("aggregation_window", 0, "Combine the consecutive "+
+		"notifications of a prefix with the same timestamp received within this duration "+
+		"(e.g. 50ms) into one event (0 to not combine them)")
 
 	// Splunk options
 	splunkURLs := flag.String("splunkurls", "https://localhost:8088",
 		"Comma-separated list of URLs of the Splunk servers")
 	splunkToken := flag.String("splunktoken", "", "Token to connect to the Splunk servers")
 	splunkIndex := flag.String("splunkindex", "", "Index for the data in Splunk")
+	configFlag := flag.String("config", "",
+		"Config file routing the notifications to Splunk indexes and sourcetypes")
 
 	flag.Parse()
 
+	config := &Config{}
+	if *configFlag != "" {
+		var err error
+		if config, err = loadConfig(*configFlag); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
 	// gNMI connection
 	ctx := gnmi.NewContext(context.Background(), cfg)
 	// Store the address without the port so it can be used as the host in the Splunk event.
@@ -84,8 +103,19 @@ func main() {
 	var g errgroup.Group
 	g.Go(func() error { return gnmi.SubscribeErr(ctx, client, subscribeOptions, respChan) })
 
+	responses := respChan
+	if len(*transforms) > 0 {
+		responses = gnmi.TransformSubscribeResponses(responses, *transforms)
+	}
+	if *downsampleInterval > 0 {
+		responses = gnmi.DownsampleSubscribeResponses(responses, *downsampleInterval)
+	}
+	if *aggregationWindow > 0 {
+		responses = gnmi.AggregateSubscribeResponses(responses, *aggregationWindow)
+	}
+
 	// Forward subscribe responses to Splunk
-	for resp := range respChan {
+	for resp := range responses {
 		// We got a subscribe response
 		response := resp.GetResponse()
 		update, ok := response.(*pb.SubscribeResponse_Update)
@@ -104,11 +134,13 @@ func main() {
 		delete(notification, "path")
 		timestamp := notification["timestamp"].(int64)
 		delete(notification, "timestamp")
-		// Should this be configurable?
-		sourceType := "openconfig"
+		index, sourceType, fields := config.route(path, *splunkIndex, "openconfig")
+		for k, v := range fields {
+			notification[k] = v
+		}
 		event := &hec.Event{
 			Host:       &addr,
-			Index:      splunkIndex,
+			Index:      &index,
 			Source:     &path,
 			SourceType: &sourceType,
 			Event:      notification,
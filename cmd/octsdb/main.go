@@ -38,6 +38,8 @@ func main() {
 		fmt.Sprintf("Set minimum TLS version for connection (%s)", gnmi.TLSVersions))
 	flag.StringVar(&cfg.TLSMaxVersion, "tls-max-version", "",
 		fmt.Sprintf("Set maximum TLS version for connection (%s)", gnmi.TLSVersions))
+	flag.StringVar(&cfg.Proxy, "proxy", "", gnmi.ProxyUsage)
+	gnmi.RPCLogFlag(cfg)
 
 	// Program options
 	subscribePaths := flag.String("paths", "", "Comma-separated list of paths to subscribe to")
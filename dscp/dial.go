@@ -7,7 +7,6 @@ package dscp
 
 import (
 	"net"
-	"syscall"
 	"time"
 )
 
@@ -17,9 +16,7 @@ import (
 func DialTCPWithTOS(laddr, raddr *net.TCPAddr, tos byte) (*net.TCPConn, error) {
 	d := net.Dialer{
 		LocalAddr: laddr,
-		Control: func(network, address string, c syscall.RawConn) error {
-			return SetTOS(network, c, tos)
-		},
+		Control:   Control(tos),
 	}
 	conn, err := d.Dial("tcp", raddr.String())
 	if err != nil {
@@ -35,9 +32,7 @@ func DialTimeoutWithTOS(network, address string, timeout time.Duration, tos byte
 	error) {
 	d := net.Dialer{
 		Timeout: timeout,
-		Control: func(network, address string, c syscall.RawConn) error {
-			return SetTOS(network, c, tos)
-		},
+		Control: Control(tos),
 	}
 	conn, err := d.Dial(network, address)
 	if err != nil {
@@ -53,9 +48,7 @@ func DialTCPTimeoutWithTOS(laddr, raddr *net.TCPAddr, tos byte, timeout time.Dur
 	d := net.Dialer{
 		Timeout:   timeout,
 		LocalAddr: laddr,
-		Control: func(network, address string, c syscall.RawConn) error {
-			return SetTOS(network, c, tos)
-		},
+		Control:   Control(tos),
 	}
 	conn, err := d.Dial("tcp", raddr.String())
 	if err != nil {
@@ -0,0 +1,53 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package dscp
+
+import (
+	"context"
+	"net"
+	"syscall"
+
+	"google.golang.org/grpc"
+)
+
+// Control returns a function setting the given ToS (Type of Service) on
+// sockets, to be used as the Control function of a net.Dialer or
+// net.ListenConfig.
+func Control(tos byte) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		return SetTOS(network, c, tos)
+	}
+}
+
+// ControlWithMark returns a function setting the given ToS (Type of
+// Service) and mark (SO_MARK) on sockets, to be used as the Control
+// function of a net.Dialer or net.ListenConfig. A zero tos or mark is not
+// set, so that ControlWithMark(0, mark) only marks the sockets.
+func ControlWithMark(tos byte, mark uint32) func(network, address string,
+	c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		if tos != 0 {
+			if err := SetTOS(network, c, tos); err != nil {
+				return err
+			}
+		}
+		if mark != 0 {
+			return SetMark(c, mark)
+		}
+		return nil
+	}
+}
+
+// DialOption returns a grpc.DialOption dialing TCP connections with the
+// socket configured to use the given ToS (Type of Service), to specify
+// DSCP / ECN / class of service flags. To also customize the dialer, e.g. to
+// dial in a VRF, set the Control function of a net.Dialer with Control
+// instead.
+func DialOption(tos byte) grpc.DialOption {
+	d := &net.Dialer{Control: Control(tos)}
+	return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", addr)
+	})
+}
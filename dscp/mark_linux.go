@@ -0,0 +1,29 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package dscp
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// SetMark sets the mark (SO_MARK) of the socket, to route its traffic with
+// fwmark-based policy routing rules. It's intended to be used in a
+// net.Dialer's Control function. Setting a mark requires the
+// CAP_NET_ADMIN capability.
+func SetMark(c syscall.RawConn, mark uint32) error {
+	var err error
+	if cerr := c.Control(func(fd uintptr) {
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK, int(mark))
+	}); cerr != nil {
+		return cerr
+	}
+	if err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	return nil
+}
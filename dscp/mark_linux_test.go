@@ -0,0 +1,50 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package dscp_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/aristanetworks/goarista/dscp"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetMark(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	d := net.Dialer{Control: dscp.ControlWithMark(40, 42)}
+	conn, err := d.Dial("tcp", l.Addr().String())
+	if errors.Is(err, syscall.EPERM) {
+		t.Skip("setting a socket mark requires CAP_NET_ADMIN")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	rc, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mark int
+	if cerr := rc.Control(func(fd uintptr) {
+		mark, err = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK)
+	}); cerr != nil {
+		t.Fatal(cerr)
+	}
+	if err != nil {
+		t.Fatal(os.NewSyscallError("getsockopt", err))
+	}
+	if mark != 42 {
+		t.Errorf("expected mark 42, got %d", mark)
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+//go:build !linux
+
+package dscp
+
+import (
+	"errors"
+	"syscall"
+)
+
+// SetMark sets the mark (SO_MARK) of the socket, to route its traffic with
+// fwmark-based policy routing rules. Marks are only supported on Linux.
+func SetMark(c syscall.RawConn, mark uint32) error {
+	if mark != 0 {
+		return errors.New("socket marks are not supported on this platform")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package flag
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DurationMap is a type used to provide mapped durations via command line
+// flags, for example: `-interval /interfaces=10s -interval /system=1m`.
+// It implements the flag.Value interface.
+type DurationMap map[string]time.Duration
+
+// String is the method to format the flag's value, part of the flag.Value interface.
+// The String method's output is used in diagnostics.
+func (o DurationMap) String() string {
+	return fmt.Sprintf("%#v", o)
+}
+
+// Set is the method to set the flag value, part of the flag.Value interface.
+// Set's argument is a key=duration string, the duration being parsed
+// with time.ParseDuration.
+func (o DurationMap) Set(value string) error {
+	k, v, err := splitKeyValue(value)
+	if err != nil {
+		return err
+	}
+	if _, exists := o[k]; exists {
+		return fmt.Errorf("%v is a duplicate option", k)
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("invalid duration for %v: %s", k, err)
+	}
+	o[k] = d
+	return nil
+}
+
+// Type returns the golang type string. This method is required by pflag library.
+func (o DurationMap) Type() string {
+	return "DurationMap"
+}
+
+// Clone returns a copy of flag options
+func (o DurationMap) Clone() DurationMap {
+	options := make(DurationMap, len(o))
+	for k, v := range o {
+		options[k] = v
+	}
+	return options
+}
+
+func splitKeyValue(value string) (string, string, error) {
+	idx := strings.Index(value, "=")
+	if idx == -1 {
+		return "", "", fmt.Errorf("%v is not in the key=value format", value)
+	}
+	return value[:idx], value[idx+1:], nil
+}
+
+// Validator checks the value of a key of a mapped flag before it is set.
+type Validator func(value string) error
+
+// WithValidators returns a flag.Value that sets v, a mapped flag such as
+// Map or DurationMap, after checking the value of each key=value argument
+// with the validator of the key in validators. The value of a key without
+// a validator is not checked, unless validators has a validator for the
+// "*" key, which is then used for all such keys.
+func WithValidators(v flag.Value, validators map[string]Validator) flag.Value {
+	return &validatedValue{Value: v, validators: validators}
+}
+
+type validatedValue struct {
+	flag.Value
+	validators map[string]Validator
+}
+
+func (v *validatedValue) Set(value string) error {
+	k, val := value, ""
+	if idx := strings.Index(value, "="); idx != -1 {
+		k, val = value[:idx], value[idx+1:]
+	}
+	validate, ok := v.validators[k]
+	if !ok {
+		validate = v.validators["*"]
+	}
+	if validate != nil {
+		if err := validate(val); err != nil {
+			return fmt.Errorf("invalid value for %v: %s", k, err)
+		}
+	}
+	return v.Value.Set(value)
+}
+
+// Type returns the golang type string of the validated flag, if it has
+// one. This method is required by pflag library.
+func (v *validatedValue) Type() string {
+	if t, ok := v.Value.(interface{ Type() string }); ok {
+		return t.Type()
+	}
+	return ""
+}
+
+// MinDuration returns a Validator requiring a duration of at least min.
+func MinDuration(min time.Duration) Validator {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if d < min {
+			return fmt.Errorf("%s is less than %s", d, min)
+		}
+		return nil
+	}
+}
+
+// OneOf returns a Validator requiring one of the values.
+func OneOf(values ...string) Validator {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(values, ", "))
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package flag
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestDurationMap(t *testing.T) {
+	intervals := DurationMap{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(intervals, "interval", "")
+	if err := fs.Parse([]string{"-interval", "/a=10s", "-interval", "/b/c=1m30s"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := DurationMap{"/a": 10 * time.Second, "/b/c": 90 * time.Second}
+	if !test.DeepEqual(expected, intervals) {
+		t.Errorf("expected %v, got %v", expected, intervals)
+	}
+	if !test.DeepEqual(expected, intervals.Clone()) {
+		t.Errorf("expected clone %v, got %v", expected, intervals.Clone())
+	}
+
+	for _, value := range []string{"/a=5s", "/d", "/d=5", "/d=soon"} {
+		if err := intervals.Set(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+func TestWithValidators(t *testing.T) {
+	intervals := DurationMap{}
+	options := Map{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(WithValidators(intervals, map[string]Validator{
+		"*":     MinDuration(time.Second),
+		"/fast": MinDuration(100 * time.Millisecond),
+	}), "interval", "")
+	fs.Var(WithValidators(options, map[string]Validator{
+		"mode": OneOf("sample", "on_change"),
+	}), "option", "")
+
+	for _, tc := range []struct {
+		arg string
+		ok  bool
+	}{
+		{arg: "-interval=/a=10s", ok: true},
+		{arg: "-interval=/b=500ms"},
+		{arg: "-interval=/fast=500ms", ok: true},
+		{arg: "-interval=/faster=50ms"},
+		{arg: "-interval=/c=bad"},
+		{arg: "-option=mode=sample", ok: true},
+		{arg: "-option=mode=poll"},
+		{arg: "-option=other=anything", ok: true},
+	} {
+		err := fs.Parse([]string{tc.arg})
+		if tc.ok && err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.arg, err)
+		} else if !tc.ok && err == nil {
+			t.Errorf("%s: expected an error", tc.arg)
+		}
+	}
+	expectedIntervals := DurationMap{"/a": 10 * time.Second, "/fast": 500 * time.Millisecond}
+	if !test.DeepEqual(expectedIntervals, intervals) {
+		t.Errorf("expected %v, got %v", expectedIntervals, intervals)
+	}
+	expectedOptions := Map{"mode": "sample", "other": "anything"}
+	if !test.DeepEqual(expectedOptions, options) {
+		t.Errorf("expected %v, got %v", expectedOptions, options)
+	}
+}
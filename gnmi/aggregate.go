@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// AggregateSubscribeResponses forwards the SubscribeResponses of respChan
+// to the returned channel, combining the update Notifications received
+// within window of the first one into one Notification per prefix and
+// timestamp, to cut the per-message overhead of the consumers at high rates.
+// Only consecutive Notifications of a prefix with the same timestamp are
+// combined, so that each update keeps its timestamp and the updates of a
+// prefix stay in order, and a Notification with deletes is not combined with
+// a previous one with updates so that the deletes are still applied after
+// them. Atomic Notifications are not
+// combined, and they and the other responses, such as the sync_response,
+// flush the Notifications aggregated before them. The returned channel is
+// closed after respChan is.
+func AggregateSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	window time.Duration) chan *pb.SubscribeResponse {
+	aggregated := make(chan *pb.SubscribeResponse)
+	go func() {
+		defer close(aggregated)
+		a := aggregator{open: map[string]*pb.Notification{}}
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		flush := func() {
+			for _, notif := range a.flush() {
+				aggregated <- &pb.SubscribeResponse{
+					Response: &pb.SubscribeResponse_Update{Update: notif}}
+			}
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+		}
+		for {
+			select {
+			case resp, ok := <-respChan:
+				if !ok {
+					flush()
+					return
+				}
+				notif := resp.GetUpdate()
+				if notif == nil || notif.Atomic {
+					flush()
+					aggregated <- resp
+					continue
+				}
+				a.add(notif)
+				if timer == nil {
+					timer = time.NewTimer(window)
+					timeout = timer.C
+				}
+			case <-timeout:
+				timer, timeout = nil, nil
+				flush()
+			}
+		}
+	}()
+	return aggregated
+}
+
+// aggregator combines Notifications by prefix and timestamp.
+type aggregator struct {
+	// pending are the combined Notifications, in the order they started.
+	pending []*pb.Notification
+	// open are the latest Notifications of pending of each prefix, which
+	// can still be combined with the next ones of the same timestamp.
+	open map[string]*pb.Notification
+}
+
+func prefixKey(prefix *pb.Path) string {
+	return prefix.GetTarget() + "\x00" + prefix.GetOrigin() + "\x00" + StrPath(prefix)
+}
+
+func (a *aggregator) add(notif *pb.Notification) {
+	key := prefixKey(notif.Prefix)
+	combined, ok := a.open[key]
+	if ok && (notif.Timestamp != combined.Timestamp ||
+		len(notif.Delete) > 0 && len(combined.Update) > 0) {
+		ok = false
+	}
+	if !ok {
+		combined = &pb.Notification{Timestamp: notif.Timestamp, Prefix: notif.Prefix}
+		a.pending = append(a.pending, combined)
+		a.open[key] = combined
+	}
+	combined.Update = append(combined.Update, notif.Update...)
+	combined.Delete = append(combined.Delete, notif.Delete...)
+}
+
+// flush returns the pending Notifications and resets the aggregator.
+func (a *aggregator) flush() []*pb.Notification {
+	pending := a.pending
+	a.pending = nil
+	for k := range a.open {
+		delete(a.open, k)
+	}
+	return pending
+}
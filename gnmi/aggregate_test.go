@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAggregateSubscribeResponses(t *testing.T) {
+	path := func(s string) *pb.Path {
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	update := func(prefix string, ts int64, paths ...string) *pb.SubscribeResponse {
+		notif := &pb.Notification{Timestamp: ts, Prefix: path(prefix)}
+		for _, p := range paths {
+			notif.Update = append(notif.Update, &pb.Update{Path: path(p), Val: TypedValue(ts)})
+		}
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: notif}}
+	}
+	withDelete := func(resp *pb.SubscribeResponse, paths ...string) *pb.SubscribeResponse {
+		for _, p := range paths {
+			resp.GetUpdate().Delete = append(resp.GetUpdate().Delete, path(p))
+		}
+		return resp
+	}
+	atomic := func(resp *pb.SubscribeResponse) *pb.SubscribeResponse {
+		resp.GetUpdate().Atomic = true
+		return resp
+	}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+
+	for name, tc := range map[string]struct {
+		in       []*pb.SubscribeResponse
+		expected []*pb.SubscribeResponse
+	}{
+		"by prefix and timestamp": {
+			in: []*pb.SubscribeResponse{
+				update("/a", 1, "x"),
+				update("/b", 1, "x"),
+				update("/a", 1, "y", "z"),
+				update("/a", 2, "w"),
+				update("/b", 2, "y"),
+				update("/a", 1, "v"),
+			},
+			expected: []*pb.SubscribeResponse{
+				update("/a", 1, "x", "y", "z"),
+				update("/b", 1, "x"),
+				update("/a", 2, "w"),
+				update("/b", 2, "y"),
+				update("/a", 1, "v"),
+			},
+		},
+		"deletes after updates": {
+			in: []*pb.SubscribeResponse{
+				withDelete(update("/a", 1), "w"),
+				update("/a", 1, "x"),
+				withDelete(update("/a", 1), "x"),
+				withDelete(update("/a", 1), "y"),
+			},
+			expected: []*pb.SubscribeResponse{
+				withDelete(update("/a", 1, "x"), "w"),
+				withDelete(update("/a", 1), "x", "y"),
+			},
+		},
+		"sync and atomic flush": {
+			in: []*pb.SubscribeResponse{
+				update("/a", 1, "x"),
+				sync,
+				update("/a", 2, "y"),
+				atomic(update("/a", 3, "z")),
+				update("/a", 4, "w"),
+			},
+			expected: []*pb.SubscribeResponse{
+				update("/a", 1, "x"),
+				sync,
+				update("/a", 2, "y"),
+				atomic(update("/a", 3, "z")),
+				update("/a", 4, "w"),
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			respChan := make(chan *pb.SubscribeResponse, len(tc.in))
+			for _, resp := range tc.in {
+				respChan <- resp
+			}
+			close(respChan)
+			var actual []*pb.SubscribeResponse
+			for resp := range AggregateSubscribeResponses(respChan, time.Hour) {
+				actual = append(actual, resp)
+			}
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected %d responses, got %d: %v", len(tc.expected), len(actual),
+					actual)
+			}
+			for i := range actual {
+				if !proto.Equal(tc.expected[i], actual[i]) {
+					t.Errorf("response %d: expected %s, got %s", i, tc.expected[i], actual[i])
+				}
+			}
+		})
+	}
+
+	// The aggregated Notifications are flushed after the window.
+	respChan := make(chan *pb.SubscribeResponse)
+	aggregated := AggregateSubscribeResponses(respChan, 10*time.Millisecond)
+	respChan <- update("/a", 1, "x")
+	respChan <- update("/a", 1, "y")
+	select {
+	case resp := <-aggregated:
+		if len(resp.GetUpdate().GetUpdate()) != 2 {
+			t.Errorf("expected 2 updates, got %s", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the aggregated notification was not flushed")
+	}
+	close(respChan)
+	if resp, ok := <-aggregated; ok {
+		t.Errorf("unexpected response %s", resp)
+	}
+}
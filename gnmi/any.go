@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// AnyDecoder decodes the payload of an Any TypedValue into a Go value.
+type AnyDecoder func(*anypb.Any) (interface{}, error)
+
+var (
+	anyDecodersMu sync.RWMutex
+	anyDecoders   = map[string]AnyDecoder{}
+)
+
+// RegisterAnyDecoder registers dec as the decoder used by ExtractValue for
+// Any TypedValues with the given type URL. Registering a nil decoder removes
+// any decoder previously registered for typeURL.
+func RegisterAnyDecoder(typeURL string, dec AnyDecoder) {
+	anyDecodersMu.Lock()
+	defer anyDecodersMu.Unlock()
+	if dec == nil {
+		delete(anyDecoders, typeURL)
+		return
+	}
+	anyDecoders[typeURL] = dec
+}
+
+// RegisterAnyMessage registers a decoder for Any TypedValues holding messages
+// of the same type as m. The type URL is derived from the full name of m and
+// the decoder returns a new message of that type.
+func RegisterAnyMessage(m proto.Message) {
+	typ := m.ProtoReflect().Type()
+	typeURL := "type.googleapis.com/" + string(typ.Descriptor().FullName())
+	RegisterAnyDecoder(typeURL, func(a *anypb.Any) (interface{}, error) {
+		msg := typ.New().Interface()
+		if err := proto.Unmarshal(a.GetValue(), msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %s", a.GetTypeUrl(), err)
+		}
+		return msg, nil
+	})
+}
+
+// decodeAny decodes a using the decoder registered for its type URL,
+// or returns a as is if there is none.
+func decodeAny(a *anypb.Any) (interface{}, error) {
+	anyDecodersMu.RLock()
+	dec, ok := anyDecoders[a.GetTypeUrl()]
+	anyDecodersMu.RUnlock()
+	if !ok {
+		return a, nil
+	}
+	return dec(a)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestExtractValueAny(t *testing.T) {
+	modelData := &pb.ModelData{Name: "foobar", Version: "1.0"}
+	anyModelData, err := anypb.New(modelData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anyCustom := &anypb.Any{TypeUrl: "example.com/custom", Value: []byte("custom")}
+	anyBroken := &anypb.Any{TypeUrl: "example.com/broken"}
+	anyUnknown := &anypb.Any{TypeUrl: "example.com/unknown", Value: []byte("unknown")}
+
+	RegisterAnyMessage(&pb.ModelData{})
+	RegisterAnyDecoder(anyCustom.TypeUrl, func(a *anypb.Any) (interface{}, error) {
+		return string(a.Value), nil
+	})
+	RegisterAnyDecoder(anyBroken.TypeUrl, func(a *anypb.Any) (interface{}, error) {
+		return nil, errors.New("broken")
+	})
+	defer func() {
+		RegisterAnyDecoder(anyModelData.TypeUrl, nil)
+		RegisterAnyDecoder(anyCustom.TypeUrl, nil)
+		RegisterAnyDecoder(anyBroken.TypeUrl, nil)
+	}()
+
+	anyUpdate := func(a *anypb.Any) *pb.Update {
+		return &pb.Update{Val: &pb.TypedValue{Value: &pb.TypedValue_AnyVal{AnyVal: a}}}
+	}
+	out, err := ExtractValue(anyUpdate(anyModelData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, ok := out.(*pb.ModelData); !ok || !proto.Equal(msg, modelData) {
+		t.Errorf("expected %v, got %v", modelData, out)
+	}
+	out, err = ExtractValue(anyUpdate(anyCustom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "custom" {
+		t.Errorf("expected %q, got %v", "custom", out)
+	}
+	if _, err := ExtractValue(anyUpdate(anyBroken)); err == nil {
+		t.Error("expected an error from the broken decoder")
+	}
+	out, err = ExtractValue(anyUpdate(anyUnknown))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !test.DeepEqual(out, anyUnknown) {
+		t.Errorf("expected %v, got %v", anyUnknown, out)
+	}
+
+	// Unregistered decoders leave the Any untouched.
+	RegisterAnyDecoder(anyCustom.TypeUrl, nil)
+	out, err = ExtractValue(anyUpdate(anyCustom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !test.DeepEqual(out, anyCustom) {
+		t.Errorf("expected %v, got %v", anyCustom, out)
+	}
+}
@@ -10,21 +10,29 @@ import (
 	"crypto/x509"
 	"flag"
 	"fmt"
+	"io"
 	"math"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/aristanetworks/goarista/dscp"
+	"github.com/aristanetworks/goarista/logger"
 	"github.com/aristanetworks/goarista/netns"
 	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmi/proto/gnmi_ext"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 const (
@@ -93,6 +101,55 @@ type Config struct {
 	DialOptions   []grpc.DialOption
 	Token         string
 	GRPCMetadata  map[string]string
+	// Proxy is the URL of the HTTP CONNECT (http://[user:password@]host:port)
+	// or SOCKS5 (socks5://[user:password@]host:port) proxy to dial through.
+	// If empty, the proxy is taken from the HTTPS_PROXY or HTTP_PROXY
+	// environment variables, unless it is ProxyDirect.
+	Proxy string
+	// Mark is the mark (SO_MARK) set on the connections to the target and
+	// to the proxy, for fwmark-based policy routing. Marks are only
+	// supported on Linux.
+	Mark uint32
+	// InsecureSkipVerify skips the verification of the server certificate,
+	// even if a CA is given. Without a CA, the server certificate is not
+	// verified against a CA in any case.
+	InsecureSkipVerify bool
+	// TLSPins are the hex or base64 encoded SHA-256 fingerprints of the
+	// SubjectPublicKeyInfo of the accepted server certificates (see
+	// SPKIFingerprint). If set, the connection is rejected unless the
+	// certificate of the server matches one of them, in addition to being
+	// verified against the CA if one is given.
+	TLSPins []string
+	// SPIFFEEndpoint is the address of the SPIFFE Workload API, e.g.
+	// unix:///run/spire/agent.sock, or "env" for the SPIFFEEndpointEnv
+	// environment variable. If set, the client certificate and the CAs
+	// the server certificate is verified against are its X.509-SVID,
+	// kept current as it rotates until the connection is closed, instead
+	// of the files or data above.
+	SPIFFEEndpoint string
+	// SPIFFETrustDomain is the trust domain the SPIFFE ID of the server
+	// must be in, e.g. spiffe://example.org. It defaults to the one of the
+	// X.509-SVID of the client.
+	SPIFFETrustDomain string
+	// SPIFFESource, if set, is used instead of SPIFFEEndpoint, for the
+	// caller to close it.
+	SPIFFESource *SPIFFESource
+	// RPCContext, if set, is called with the context of every RPC made on
+	// the connection and returns the context to make it with. It can be used
+	// to add metadata that changes over time, such as refreshed tokens or
+	// tracing headers, to each Get, Set and Subscribe.
+	RPCContext func(ctx context.Context) context.Context
+	// RPCLogger, if set, logs every RPC made on the connection: its method,
+	// the paths of its requests, its sizes and its duration. The values of
+	// the credential metadata are redacted and the values set are truncated.
+	RPCLogger logger.Logger
+
+	// PasswordFile and TokenFile are the files LoadCredentials reads the
+	// Password and Token from, and PasswordPrompt makes it prompt for the
+	// password on the terminal.
+	PasswordFile   string
+	TokenFile      string
+	PasswordPrompt bool
 }
 
 // SubscribeOptions is the gNMI subscription request options
@@ -107,11 +164,25 @@ type SubscribeOptions struct {
 	Paths             [][]string
 	Origin            string
 	Target            string
+	Encoding          pb.Encoding
 	Extensions        []*gnmi_ext.Extension
+	// Compact is how the subscriptions of Paths covered by another one
+	// are dropped, see CompactSubscriptions.
+	Compact CompactPolicy
+	// CompactLogger, if set, logs the subscriptions dropped by Compact.
+	CompactLogger logger.Logger
+	// UnbundleDepth, if positive, makes SubscribeErr split the bundled
+	// Notifications received with UnbundleNotification at this depth.
+	UnbundleDepth int
+	// Reconnect, if set, makes SubscribeErr subscribe again when a STREAM
+	// subscription fails, see SubscribeWithReconnect.
+	Reconnect *ResubscribeOptions
 }
 
 // ParseFlags reads arguments from stdin and returns a populated Config object and a list of
-// paths to subscribe to
+// paths to subscribe to. The caller is expected to call LoadCredentials on the Config.
+// The flags not set on the command line are taken from the config file set with the
+// ConfigFileFlag flag, using the section of the name of the program.
 func ParseFlags() (*Config, []string) {
 	// flags
 	var (
@@ -131,6 +202,12 @@ func ParseFlags() (*Config, []string) {
 		passwordFlag = flag.String("password", "",
 			"Password to authenticate with")
 
+		passwordFileFlag = flag.String("password_file", "",
+			"Path to a file containing the password to authenticate with")
+
+		passwordPromptFlag = flag.Bool("password_prompt", false,
+			"Prompt for the password to authenticate with on the terminal")
+
 		usernameFlag = flag.String("username", "",
 			"Username to authenticate with")
 
@@ -142,15 +219,46 @@ func ParseFlags() (*Config, []string) {
 			fmt.Sprintf("Set minimum TLS version for connection (%s)", TLSVersions))
 
 		compressionFlag = flag.String("compression", "",
-			"Type of compression to use")
+			"Type of compression to use (gzip, zstd or auto to pick the fastest on the "+
+				"first responses)")
 
 		subscribeFlag = flag.String("subscribe", "",
 			"Comma-separated list of paths to subscribe to upon connecting to the server")
 
 		token = flag.String("token", "",
 			"Authentication token")
+
+		tokenFileFlag = flag.String("token_file", "",
+			"Path to a file containing the authentication token")
+
+		proxyFlag = flag.String("proxy", "", ProxyUsage)
+
+		fwmarkFlag = flag.Uint("fwmark", 0,
+			"Mark (SO_MARK) to set on the connections, "+
+				"for fwmark-based policy routing (Linux only)")
+
+		rpcLogFlag = flag.Bool("rpc_log", false, rpcLogUsage)
+
+		spiffeEndpointFlag = flag.String("spiffe_endpoint", "",
+			"Address of the SPIFFE Workload API (unix:///path/to/agent.sock, or 'env' for "+
+				"the "+SPIFFEEndpointEnv+" environment variable) to get the rotating "+
+				"client certificate and server CAs from")
+
+		spiffeTrustDomainFlag = flag.String("spiffe_trust_domain", "",
+			"Trust domain (spiffe://example.org) the SPIFFE ID of the server must be in, "+
+				"defaults to the one of the client")
 	)
+	flag.String(ConfigFileFlag, "", "Path to a YAML or JSON config file "+
+		"setting the flags not set on the command line")
 	flag.Parse()
+	if err := ApplyConfigFile(filepath.Base(os.Args[0])); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *fwmarkFlag > math.MaxUint32 {
+		fmt.Fprintf(os.Stderr, "fwmark must fit in 32 bits, got %d\n", *fwmarkFlag)
+		os.Exit(2)
+	}
 	cfg := &Config{
 		Addr:          *addrsFlag,
 		CAFile:        *caFileFlag,
@@ -163,6 +271,18 @@ func ParseFlags() (*Config, []string) {
 		TLSMaxVersion: *tlsMaxVersion,
 		Compression:   *compressionFlag,
 		Token:         *token,
+		Proxy:         *proxyFlag,
+		Mark:          uint32(*fwmarkFlag),
+
+		SPIFFEEndpoint:    *spiffeEndpointFlag,
+		SPIFFETrustDomain: *spiffeTrustDomainFlag,
+
+		PasswordFile:   *passwordFileFlag,
+		TokenFile:      *tokenFileFlag,
+		PasswordPrompt: *passwordPromptFlag,
+	}
+	if *rpcLogFlag {
+		cfg.RPCLogger = newRPCLogger()
 	}
 	subscriptions := strings.Split(*subscribeFlag, ",")
 	return cfg, subscriptions
@@ -194,6 +314,39 @@ func (a *accessTokenCred) RequireTransportSecurity() bool { return true }
 
 // DialContextConn connects to a gnmi service and return a client connection
 func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error) {
+	if cfg.SPIFFESource != nil || cfg.SPIFFEEndpoint == "" {
+		return dialContextConn(ctx, cfg, cfg.SPIFFESource)
+	}
+	endpoint := cfg.SPIFFEEndpoint
+	if endpoint == "env" {
+		endpoint = os.Getenv(SPIFFEEndpointEnv)
+	}
+	spiffeSource, err := NewSPIFFESource(ctx, endpoint, logger.Std)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialContextConn(ctx, cfg, spiffeSource)
+	if err != nil {
+		spiffeSource.Close()
+		return nil, err
+	}
+	// The SPIFFE source created for the connection lives as long as it.
+	go closeOnShutdown(conn, spiffeSource)
+	return conn, nil
+}
+
+// closeOnShutdown closes c once conn is closed.
+func closeOnShutdown(conn *grpc.ClientConn, c io.Closer) {
+	for state := conn.GetState(); state != connectivity.Shutdown; state = conn.GetState() {
+		conn.WaitForStateChange(context.Background(), state)
+	}
+	c.Close()
+}
+
+// dialContextConn connects to a gnmi service with the TLS configuration of
+// spiffeSource, if not nil.
+func dialContextConn(ctx context.Context, cfg *Config,
+	spiffeSource *SPIFFESource) (*grpc.ClientConn, error) {
 	opts := append([]grpc.DialOption(nil), cfg.DialOptions...)
 
 	if !cfg.BDP {
@@ -215,8 +368,9 @@ func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error)
 
 	switch cfg.Compression {
 	case "":
-	case "gzip":
-		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	case gzip.Name, ZstdName:
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(cfg.Compression)))
+	case CompressionAuto:
 	default:
 		return nil, fmt.Errorf("unsupported compression option: %q", cfg.Compression)
 	}
@@ -241,9 +395,19 @@ func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error)
 		}
 	}
 
-	if cfg.TLS || len(caData) > 0 || len(certData) > 0 || cfg.Token != "" {
+	if spiffeSource != nil && (len(caData) > 0 || len(certData) > 0) {
+		return nil, fmt.Errorf("both SPIFFE and a CA or client certificate are set")
+	}
+
+	useTLS := cfg.TLS || len(caData) > 0 || len(certData) > 0 || cfg.Token != "" ||
+		len(cfg.TLSPins) > 0 || spiffeSource != nil
+	if useTLS {
 		tlsConfig := &tls.Config{}
-		if len(caData) > 0 {
+		if spiffeSource != nil {
+			if tlsConfig, err = spiffeSource.TLSConfig(cfg.SPIFFETrustDomain); err != nil {
+				return nil, err
+			}
+		} else if len(caData) > 0 {
 			cp := x509.NewCertPool()
 			if !cp.AppendCertsFromPEM(caData) {
 				return nil, fmt.Errorf("credentials: failed to append certificates")
@@ -252,6 +416,26 @@ func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error)
 		} else {
 			tlsConfig.InsecureSkipVerify = true
 		}
+		if cfg.InsecureSkipVerify {
+			tlsConfig.InsecureSkipVerify = true
+		}
+		if len(cfg.TLSPins) > 0 {
+			verifyPins, err := spkiPinVerifier(cfg.TLSPins)
+			if err != nil {
+				return nil, err
+			}
+			if verify := tlsConfig.VerifyPeerCertificate; verify != nil {
+				tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte,
+					chains [][]*x509.Certificate) error {
+					if err := verify(rawCerts, chains); err != nil {
+						return err
+					}
+					return verifyPins(rawCerts, chains)
+				}
+			} else {
+				tlsConfig.VerifyPeerCertificate = verifyPins
+			}
+		}
 		if len(certData) > 0 {
 			if len(keyData) == 0 {
 				return nil, fmt.Errorf("no key provided for client certificate")
@@ -293,6 +477,10 @@ func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error)
 		opts = append(opts, grpc.WithInsecure())
 	}
 
+	d := &net.Dialer{}
+	if cfg.Mark != 0 {
+		d.Control = dscp.ControlWithMark(0, cfg.Mark)
+	}
 	dial := func(ctx context.Context, addrIn string) (conn net.Conn, err error) {
 		var network, nsName, addr string
 
@@ -316,13 +504,34 @@ func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error)
 			}
 		}
 
-		err = netns.Do(nsName, func() (err error) {
-			conn, err = (&net.Dialer{}).DialContext(ctx, network, addr)
+		var proxy *url.URL
+		if network == "tcp" {
+			if proxy, err = proxyURL(cfg.Proxy, addr, useTLS); err != nil {
+				return nil, err
+			}
+		}
+		err = netns.DefaultPool.Do(nsName, func() (err error) {
+			if proxy != nil {
+				conn, err = dialProxy(ctx, d, proxy, addr)
+				return
+			}
+			conn, err = d.DialContext(ctx, network, addr)
 			return
 		})
 		return
 	}
 
+	if cfg.RPCContext != nil {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(rpcContextUnaryInterceptor(cfg.RPCContext)),
+			grpc.WithChainStreamInterceptor(rpcContextStreamInterceptor(cfg.RPCContext)))
+	}
+	if cfg.RPCLogger != nil {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(rpcLogUnaryInterceptor(cfg.RPCLogger)),
+			grpc.WithChainStreamInterceptor(rpcLogStreamInterceptor(cfg.RPCLogger)))
+	}
+
 	opts = append(opts,
 		grpc.WithContextDialer(dial),
 
@@ -330,7 +539,41 @@ func DialContextConn(ctx context.Context, cfg *Config) (*grpc.ClientConn, error)
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32)),
 	)
 
-	return grpc.DialContext(ctx, cfg.Addr, opts...)
+	if cfg.Compression != CompressionAuto {
+		return grpc.DialContext(ctx, cfg.Addr, opts...)
+	}
+	auto := newAutoCompressor(cfg.Addr, logger.Std)
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(auto.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(auto.streamInterceptor))
+	conn, err := grpc.DialContext(ctx, cfg.Addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// The probe outlives the dial, it fails once conn is closed.
+	probeCtx, cancel := context.WithTimeout(NewContext(context.Background(), cfg),
+		compressionProbeTimeout)
+	go func() {
+		defer cancel()
+		auto.probe(probeCtx, conn)
+	}()
+	return conn, nil
+}
+
+func rpcContextUnaryInterceptor(
+	hook func(context.Context) context.Context) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(hook(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func rpcContextStreamInterceptor(
+	hook func(context.Context) context.Context) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(hook(ctx), desc, cc, method, opts...)
+	}
 }
 
 // DialContext connects to a gnmi service and returns a client
@@ -365,10 +608,12 @@ func NewContext(ctx context.Context, cfg *Config) context.Context {
 	return ctx
 }
 
-// NewGetRequest returns a GetRequest for the given paths
-func NewGetRequest(paths [][]string, origin string) (*pb.GetRequest, error) {
+// NewGetRequest returns a GetRequest for the given paths and extensions
+func NewGetRequest(paths [][]string, origin string,
+	exts ...*gnmi_ext.Extension) (*pb.GetRequest, error) {
 	req := &pb.GetRequest{
-		Path: make([]*pb.Path, len(paths)),
+		Path:      make([]*pb.Path, len(paths)),
+		Extension: exts,
 	}
 	for i, p := range paths {
 		gnmiPath, err := ParseGNMIElements(p)
@@ -420,6 +665,7 @@ func NewSubscribeRequest(subscribeOptions *SubscribeOptions) (*pb.SubscribeReque
 		Mode:         mode,
 		UpdatesOnly:  subscribeOptions.UpdatesOnly,
 		Prefix:       prefixPath,
+		Encoding:     subscribeOptions.Encoding,
 	}
 	if subscribeOptions.Target != "" {
 		if subList.Prefix == nil {
@@ -441,6 +687,14 @@ func NewSubscribeRequest(subscribeOptions *SubscribeOptions) (*pb.SubscribeReque
 			HeartbeatInterval: subscribeOptions.HeartbeatInterval,
 		}
 	}
+	var compacted []*CompactedSubscription
+	subList.Subscription, compacted = CompactSubscriptions(subList.Subscription,
+		subscribeOptions.Compact)
+	if l := subscribeOptions.CompactLogger; l != nil {
+		for _, c := range compacted {
+			l.Infof("dropped the subscription to %s", c)
+		}
+	}
 	return &pb.SubscribeRequest{
 		Extension: subscribeOptions.Extensions,
 		Request: &pb.SubscribeRequest_Subscribe{
@@ -476,6 +730,74 @@ func HistoryRangeExtension(s, e int64) *gnmi_ext.Extension_History {
 	}
 }
 
+// DepthExtension returns an Extension_Depth limiting the depth of the data
+// returned to level levels below the requested paths. A level of 0 means no
+// limit.
+func DepthExtension(level uint32) *gnmi_ext.Extension_Depth {
+	return &gnmi_ext.Extension_Depth{
+		Depth: &gnmi_ext.Depth{
+			Level: level,
+		},
+	}
+}
+
+// CommitExtension returns an Extension_Commit starting the confirmed
+// commit id with the changes of a SetRequest. The changes are rolled back
+// unless the commit is confirmed within rollback, or the default duration
+// of the target if rollback is 0.
+func CommitExtension(id string, rollback time.Duration) *gnmi_ext.Extension_Commit {
+	req := &gnmi_ext.CommitRequest{}
+	if rollback > 0 {
+		req.RollbackDuration = durationpb.New(rollback)
+	}
+	return &gnmi_ext.Extension_Commit{
+		Commit: &gnmi_ext.Commit{
+			Id:     id,
+			Action: &gnmi_ext.Commit_Commit{Commit: req},
+		},
+	}
+}
+
+// CommitConfirmExtension returns an Extension_Commit confirming the
+// confirmed commit id, to send in a SetRequest without changes.
+func CommitConfirmExtension(id string) *gnmi_ext.Extension_Commit {
+	return &gnmi_ext.Extension_Commit{
+		Commit: &gnmi_ext.Commit{
+			Id:     id,
+			Action: &gnmi_ext.Commit_Confirm{Confirm: &gnmi_ext.CommitConfirm{}},
+		},
+	}
+}
+
+// CommitCancelExtension returns an Extension_Commit canceling the confirmed
+// commit id, rolling back its changes, to send in a SetRequest without
+// changes.
+func CommitCancelExtension(id string) *gnmi_ext.Extension_Commit {
+	return &gnmi_ext.Extension_Commit{
+		Commit: &gnmi_ext.Commit{
+			Id:     id,
+			Action: &gnmi_ext.Commit_Cancel{Cancel: &gnmi_ext.CommitCancel{}},
+		},
+	}
+}
+
+// CommitRollbackDurationExtension returns an Extension_Commit changing the
+// rollback duration of the confirmed commit id to rollback, to send in a
+// SetRequest without changes.
+func CommitRollbackDurationExtension(id string,
+	rollback time.Duration) *gnmi_ext.Extension_Commit {
+	return &gnmi_ext.Extension_Commit{
+		Commit: &gnmi_ext.Commit{
+			Id: id,
+			Action: &gnmi_ext.Commit_SetRollbackDuration{
+				SetRollbackDuration: &gnmi_ext.CommitSetRollbackDuration{
+					RollbackDuration: durationpb.New(rollback),
+				},
+			},
+		},
+	}
+}
+
 // getTLSVersions generates a map of TLS version name to tls version, based on the versions
 // available in the crypto/tls package
 func getTLSVersions(testHook ...func(uint16, *regexp.Regexp)) tlsVersionMap {
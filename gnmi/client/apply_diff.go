@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aristanetworks/goarista/gnmi"
+)
+
+// parseListKeys parses the -diff_list_keys flag, a comma separated list
+// of LIST=KEY[+KEY...], e.g. "interface=name,entry=prefix+vrf".
+func parseListKeys(s string) (map[string][]string, error) {
+	listKeys := map[string][]string{}
+	if s == "" {
+		return listKeys, nil
+	}
+	for _, lk := range strings.Split(s, ",") {
+		list, keys, ok := strings.Cut(lk, "=")
+		if !ok || list == "" || keys == "" {
+			return nil, fmt.Errorf("invalid list keys %q, expected LIST=KEY[+KEY...]", lk)
+		}
+		listKeys[list] = strings.Split(keys, "+")
+	}
+	return listKeys, nil
+}
+
+// readJSONArg returns the content of the file arg, or arg itself if it is
+// not a file.
+func readJSONArg(arg string) []byte {
+	if b, err := os.ReadFile(arg); err == nil {
+		return b
+	}
+	return []byte(arg)
+}
+
+// newApplyDiffOperations parses the arguments of apply-diff:
+//
+//	(origin=ORIGIN) (target=TARGET) PATH BEFORE AFTER
+//
+// and returns the Set operations turning the BEFORE JSON into the AFTER
+// JSON at PATH.
+func newApplyDiffOperations(args []string,
+	listKeys map[string][]string) ([]*gnmi.Operation, error) {
+	pathParams, argsParsed := parsereqParams(args, true)
+	if argsParsed == 0 {
+		return nil, errors.New("missing path for 'apply-diff'")
+	}
+	pathParam := pathParams[0]
+	if pathParam.encoding != "" || pathParam.depth != "" {
+		return nil, errors.New("only origin and target options are supported for 'apply-diff'")
+	}
+	rest := args[argsParsed:]
+	if len(rest) != 2 {
+		return nil, errors.New("'apply-diff' must be followed by a path and " +
+			"the before and after JSON|FILE")
+	}
+	return gnmi.DiffJSON(gnmi.SplitPath(pathParam.paths[0]),
+		readJSONArg(rest[0]), readJSONArg(rest[1]),
+		gnmi.DiffOptions{
+			Origin:   pathParam.origin,
+			Target:   pathParam.target,
+			ListKeys: listKeys,
+		})
+}
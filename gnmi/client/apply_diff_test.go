@@ -0,0 +1,59 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/aristanetworks/goarista/gnmi"
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestParseListKeys(t *testing.T) {
+	listKeys, err := parseListKeys("interface=name,entry=prefix+vrf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string][]string{"interface": {"name"}, "entry": {"prefix", "vrf"}}
+	if diff := test.Diff(exp, listKeys); diff != "" {
+		t.Errorf("unexpected list keys: %s", diff)
+	}
+	for _, s := range []string{"interface", "=name", "interface="} {
+		if _, err := parseListKeys(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestNewApplyDiffOperations(t *testing.T) {
+	ops, err := newApplyDiffOperations([]string{"origin=openconfig", "target=dut",
+		"/interfaces",
+		`{"interface": [{"name": "Ethernet1", "mtu": 1500}]}`,
+		`{"interface": [{"name": "Ethernet1", "mtu": 9000}]}`},
+		map[string][]string{"interface": {"name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*gnmi.Operation{{
+		Type:   "update",
+		Origin: "openconfig",
+		Target: "dut",
+		Path:   []string{"interfaces", "interface[name=Ethernet1]", "mtu"},
+		Val:    "9000",
+	}}
+	if diff := test.Diff(exp, ops); diff != "" {
+		t.Errorf("unexpected operations: %s", diff)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"/interfaces", "{}"},
+		{"depth=1", "/interfaces", "{}", "{}"},
+	} {
+		if _, err := newApplyDiffOperations(args, nil); err == nil {
+			t.Errorf("expected an error for %q", args)
+		}
+	}
+}
@@ -5,18 +5,22 @@
 package client
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path"
 	"runtime/debug"
 	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	aflag "github.com/aristanetworks/goarista/flag"
+	aglog "github.com/aristanetworks/goarista/glog"
 	"github.com/aristanetworks/goarista/gnmi"
 
 	"github.com/aristanetworks/glog"
@@ -24,7 +28,10 @@ import (
 	"github.com/openconfig/gnmi/proto/gnmi_ext"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 )
@@ -33,13 +40,29 @@ import (
 // the client code.
 const clientVersion = "2024.10.31"
 
+// Validator, if not nil, validates the values of the Set operations before
+// they are sent. Binaries wrapping Main may set it, e.g. to a validator
+// built from the YANG modules of the target with goyang: this package does
+// not load YANG modules itself.
+var Validator gnmi.SetValidator
+
+// originPrefixes maps the path prefixes to the origin of the paths under
+// them, to infer the origin of the paths given without origin=.
+var originPrefixes map[string]string
+
 // TODO: Make this more clear
 var help = `Usage of gnmi:
 gnmi -addr [<VRF-NAME>/]ADDRESS:PORT [options...]
-  capabilities
-  get ((encoding=ENCODING) (origin=ORIGIN) (target=TARGET) PATH+)+
-  subscribe ((origin=ORIGIN) (target=TARGET) (sample_interval=SAMPLE_INTERVAL) PATH+)+ 
+  capabilities ((model=MODEL) (encoding=ENCODING))*
+  get ((encoding=ENCODING) (origin=ORIGIN) (target=TARGET) (depth=DEPTH) PATH+)+
+  subscribe ((origin=ORIGIN) (target=TARGET) (mode=STREAM_MODE)
+    (sample_interval=SAMPLE_INTERVAL) PATH+)+
+  expand ((origin=ORIGIN) (target=TARGET) PATH+)+
   set PROTO|FILE
+  set_batch FILE
+  commit confirm|cancel ID
+  commit rollback_duration ID DURATION
+  apply-diff (origin=ORIGIN) (target=TARGET) PATH BEFORE AFTER
   ((update|replace|union_replace (origin=ORIGIN) (target=TARGET) PATH JSON|FILE) |
    (delete (origin=ORIGIN) (target=TARGET) PATH))+
 `
@@ -49,6 +72,8 @@ type reqParams struct {
 	origin         string
 	target         string
 	sampleInterval string
+	mode           string
+	depth          string
 	paths          []string
 }
 
@@ -63,21 +88,45 @@ func usageAndExit(s string) {
 // Main initializes the gNMI client.
 func Main() {
 	cfg := &gnmi.Config{}
-	flag.StringVar(&cfg.Addr, "addr", "", "Address of gNMI gRPC server with optional VRF name")
+	flag.StringVar(&cfg.Addr, "addr", "", "Address of gNMI gRPC server with optional VRF name "+
+		"([VRF/]ADDR:PORT, pid:PID/ADDR:PORT or nspath=PATH/ADDR:PORT)")
 	flag.StringVar(&cfg.CAFile, "cafile", "", "Path to server TLS certificate file")
 	flag.StringVar(&cfg.CertFile, "certfile", "", "Path to client TLS certificate file")
 	flag.StringVar(&cfg.KeyFile, "keyfile", "", "Path to client TLS private key file")
-	flag.StringVar(&cfg.Password, "password", "", "Password to authenticate with")
+	flag.StringVar(&cfg.Password, "password", "", "Password to authenticate with. "+
+		"Defaults to the "+gnmi.PasswordEnv+" environment variable")
+	flag.StringVar(&cfg.PasswordFile, "password_file", "",
+		"Path to a file containing the password to authenticate with")
+	flag.BoolVar(&cfg.PasswordPrompt, "password_prompt", false,
+		"Prompt for the password to authenticate with on the terminal if it is not "+
+			"otherwise set")
 	flag.StringVar(&cfg.Username, "username", "", "Username to authenticate with")
 	flag.StringVar(&cfg.Compression, "compression", "", "Compression method. "+
-		`Supported options: "" and "gzip"`)
+		`Supported options: "", "gzip", "zstd" and "auto" to pick the one of gzip and zstd `+
+		"the fastest on the first responses")
 	flag.BoolVar(&cfg.TLS, "tls", false, "Enable TLS")
 	flag.StringVar(&cfg.TLSMinVersion, "tls-min-version", "",
 		fmt.Sprintf("Set minimum TLS version for connection (%s)", gnmi.TLSVersions))
 	flag.StringVar(&cfg.TLSMaxVersion, "tls-max-version", "",
 		fmt.Sprintf("Set maximum TLS version for connection (%s)", gnmi.TLSVersions))
+	flag.BoolVar(&cfg.InsecureSkipVerify, "insecure_skip_verify", false,
+		"Do not verify the server TLS certificate, even if -cafile is set")
+	tlsPins := (*aflag.StringArrayOption)(&cfg.TLSPins)
+	flag.Var(tlsPins, "tls_pin", "SHA-256 fingerprint (hex or base64) of the "+
+		"SubjectPublicKeyInfo of the accepted server TLS certificate, "+
+		"can be used repeatedly")
+	flag.StringVar(&cfg.SPIFFEEndpoint, "spiffe_endpoint", "", "Address of the SPIFFE "+
+		"Workload API (unix:///path/to/agent.sock, or 'env' for the "+gnmi.SPIFFEEndpointEnv+
+		" environment variable) to get the rotating client certificate and server CAs from")
+	flag.StringVar(&cfg.SPIFFETrustDomain, "spiffe_trust_domain", "", "Trust domain "+
+		"(spiffe://example.org) the SPIFFE ID of the server must be in, "+
+		"defaults to the one of the client")
+	flag.StringVar(&cfg.Proxy, "proxy", "", gnmi.ProxyUsage)
+	fwmark := flag.Uint("fwmark", 0, "Mark (SO_MARK) to set on the connection, "+
+		"for fwmark-based policy routing (Linux only)")
 	flag.BoolVar(&cfg.BDP, "bdp", true,
 		"Enable Bandwidth Delay Product (BDP) estimation and dynamic flow control window")
+	gnmi.RPCLogFlag(cfg)
 	outputVersion := flag.Bool("version", false, "print version information")
 
 	subscribeOptions := &gnmi.SubscribeOptions{}
@@ -104,30 +153,128 @@ func Main() {
 	historySnapshotStr := flag.String("history_snapshot", "", "Historical data subscription "+
 		"snapshot time (nanoseconds since Unix epoch or RFC3339 format with nanosecond "+
 		"precision, e.g., 2006-01-02T15:04:05.999999999+07:00)")
+	encodingStr := flag.String("encoding", "", "Encoding of get and subscribe requests "+
+		"without an encoding= parameter (json | json_ietf | proto | bytes | ascii), "+
+		"overrides -negotiate_encoding")
+	negotiateEncoding := flag.Bool("negotiate_encoding", false, "Fetch the target "+
+		"capabilities before get and subscribe requests and use the first supported "+
+		"encoding among JSON_IETF, JSON and PROTO")
 	dataTypeStr := flag.String("data_type", "all",
 		"Get data type (all | config | state | operational)")
+	compactPaths := flag.String("compact_paths", "none", "Drop the subscribe paths covered "+
+		"by another one, e.g. /interfaces/interface/state by /interfaces, and log them:\n"+
+		"  'none': keep all the paths\n"+
+		"  'duplicates': drop the duplicate paths\n"+
+		"  'same_mode': drop the paths covered by one with the same mode and intervals\n"+
+		"  'all': drop all the covered paths")
+	pathsFile := flag.String("paths_file", "", "File with more paths of get, subscribe "+
+		"and expand, one [ORIGIN:]PATH[@SAMPLE_INTERVAL] per line, with # comments and "+
+		"'include FILE' lines")
+	expandWildcards := flag.Bool("expand_wildcards", false, "Expand the wildcards of the "+
+		"subscribe paths into the instantiated paths with shallow Gets before subscribing, "+
+		"for targets not supporting wildcards")
+	reconnect := flag.Bool("reconnect", false, "Subscribe again, with a backoff, when a "+
+		"stream subscription fails or ends")
+	reconnectUpdatesOnly := flag.Bool("reconnect_updates_only", false, "With -reconnect, "+
+		"set updates_only when subscribing again after a sync_response, for the target "+
+		"not to send the whole state again")
+	reconnectMarker := flag.String("reconnect_marker", "none", "With -reconnect, the "+
+		"notification received before the responses of a new stream:\n"+
+		"  'none': no notification\n"+
+		"  'delete': a delete of the subscribed paths\n"+
+		"  'refresh': a notification with no updates and a reconnect extension")
+	verify := flag.Bool("verify", false, "Report the subscribe paths that produced "+
+		"no update before the sync_response on stderr")
+	exitAfterSync := flag.Bool("exit_after_sync", false, "End the subscribe and exit "+
+		"once all its subscriptions sent their sync_response")
+	maxNotifications := flag.Uint64("max_notifications", 0, "End the subscribe and exit "+
+		"once it received this number of notifications (0 for no limit)")
 	protoRequest := flag.Bool("proto", false,
 		"Parse the Subscribe argument as a SubscribeRequest proto text/file")
-	flag.StringVar(&cfg.Token, "token", "", "Authentication token")
+	flag.StringVar(&cfg.Token, "token", "", "Authentication token. "+
+		"Defaults to the "+gnmi.TokenEnv+" environment variable")
+	flag.StringVar(&cfg.TokenFile, "token_file", "",
+		"Path to a file containing the authentication token")
 	grpcMetadata := aflag.Map{}
 	flag.Var(grpcMetadata, "grpcmetadata",
 		"key=value gRPC metadata fields, can be used repeatedly")
-
+	inferOriginFlag := flag.Bool("infer_origin", false, "Infer the origin of the paths "+
+		"given without origin= from their prefix (e.g. eos_native for /Sysdb)")
+	originPrefixFlag := aflag.Map{}
+	flag.Var(originPrefixFlag, "origin_prefix", "PREFIX=ORIGIN origin inferred for the "+
+		"paths under PREFIX, added to the defaults (/Sysdb, /Smash and /Kernel=eos_native), "+
+		"can be used repeatedly with -infer_origin. An empty ORIGIN disables the inference "+
+		"under PREFIX")
+
+	format := flag.String("format", "text", "Output format of get and subscribe:\n"+
+		"  'text' : human-readable output\n"+
+		"  'csv' : rows of timestamp,target,path,type,value with a header row\n"+
+		"  'json' : JSON CapabilityResponse, for capabilities only")
 	debugMode := flag.String("debug", "", "Enable a debug mode:\n"+
 		"  'proto' : print SubscribeResponses in protobuf text format\n"+
 		"  'latency' : print timing numbers to help debug latency\n"+
-		"  'throughput' : print number of notifications sent in a second\n"+
+		"  'stats' : print gRPC message, byte and latency statistics every 10 seconds\n"+
+		"  'paths' : print the update count, bytes, rate and last timestamp of each\n"+
+		"            subscribed path every 10 seconds\n"+
+		"  'skew' : print the clock skew between the timestamps of the notifications and\n"+
+		"           their receive time every 10 seconds\n"+
 		"  'clog' : start a subscribe and then don't read any of the responses")
 
+	maxClockSkew := flag.Duration("max_clock_skew", 0, "Rewrite the timestamps of the "+
+		"subscribe notifications that differ from their receive time by more than this "+
+		"duration to the receive time, for targets with a broken clock (0 to disable)")
+
+	setBatchSize := flag.Int("set_batch_size", 0, "Maximum number of operations per "+
+		"SetRequest for set_batch (0 sends all operations in one SetRequest)")
+	setConcurrency := flag.Int("set_concurrency", 1, "Maximum number of concurrent "+
+		"SetRequests for set_batch")
+	confirmTimeout := flag.Duration("confirm_timeout", 0, "Commit-confirmed timeout of "+
+		"update|replace|delete|union_replace and apply-diff: the Set is rolled back to the "+
+		"values fetched before it unless 'confirm' is entered on stdin within this duration "+
+		"(30s, 2m, etc. 0 disables the confirmation)")
+	commitID := flag.String("commit_id", "", "Send update|replace|delete|union_replace and "+
+		"apply-diff as the confirmed commit of this ID with the gNMI commit extension: the "+
+		"target rolls the changes back unless 'commit confirm ID' is sent in time")
+	commitRollback := flag.Duration("commit_rollback", 0, "Rollback duration of the "+
+		"confirmed commit of -commit_id (0 uses the default of the target)")
+	diffListKeysStr := flag.String("diff_list_keys", "", "Keys of the YANG lists "+
+		"diffed by apply-diff, as LIST=KEY[+KEY...],... (e.g. interface=name). "+
+		"The entries of other lists are not matched and the lists are replaced as a whole")
+
+	timeout := flag.Duration("timeout", 0, "Deadline of each get, set and capabilities "+
+		"RPC (30s, 2m, etc. 0 waits forever). Does not apply to subscribe")
+	dialTimeout := flag.Duration("dial_timeout", 0, "Time to wait for the connection to "+
+		"the target before giving up (30s, 2m, etc. 0 connects in the background "+
+		"and lets each RPC wait for the connection)")
 	keepaliveTimeStr := flag.String("keepalive_time", "", "Keepalive ping interval. "+
 		"After inactivity of this duration, ping the server (30s, 2m, etc. Default 10s). "+
 		"10s is the minimum value allowed. If a value less than 10s is supplied, 10s will be used")
 
+	errorFormat := flag.String("error_format", "", "Report the errors of the RPCs on "+
+		"stderr and exit with their gRPC status code (2 if they have none):\n"+
+		"  'text' : error: MESSAGE (path: PATH)\n"+
+		"  'json' : {\"code\":...,\"message\":...,\"details\":[...],\"path\":...}\n"+
+		"By default they are logged as fatal glog errors")
+	flag.BoolVar(&errorOutput.quiet, "quiet", false, "Do not print the errors of the RPCs, "+
+		"only exit with their gRPC status code")
+
+	flag.String(gnmi.ConfigFileFlag, "", "Path to a YAML or JSON config file setting the "+
+		"flags not set on the command line, with the flags of the 'gnmi' section")
+
 	flag.Usage = func() {
 		fmt.Fprintln(os.Stderr, help)
 		flag.PrintDefaults()
 	}
 	flag.Parse()
+	if err := gnmi.ApplyConfigFile("gnmi"); err != nil {
+		usageAndExit(fmt.Sprintf("error: %s", err))
+	}
+	switch *errorFormat {
+	case "", "text", "json":
+		errorOutput.format = *errorFormat
+	default:
+		usageAndExit(fmt.Sprintf("error: unknown -error_format %q", *errorFormat))
+	}
 	if *outputVersion {
 		var vcsVersion string
 		if info, ok := debug.ReadBuildInfo(); ok {
@@ -147,7 +294,35 @@ func Main() {
 	if cfg.Addr == "" {
 		usageAndExit("error: address not specified")
 	}
+	if *fwmark > math.MaxUint32 {
+		usageAndExit(fmt.Sprintf("error: fwmark must fit in 32 bits, got %d", *fwmark))
+	}
+	cfg.Mark = uint32(*fwmark)
+	if err := cfg.LoadCredentials(); err != nil {
+		usageAndExit(fmt.Sprintf("error: %s", err))
+	}
+	var csvWriter *gnmi.CSVWriter
+	switch *format {
+	case "text":
+	case "csv":
+		csvWriter = gnmi.NewCSVWriter(os.Stdout)
+	case "json":
+		if flag.Arg(0) != "capabilities" {
+			usageAndExit("error: the json format is only supported by 'capabilities'")
+		}
+	default:
+		usageAndExit(fmt.Sprintf("error: unknown format %q", *format))
+	}
 	cfg.GRPCMetadata = grpcMetadata
+	if *inferOriginFlag {
+		originPrefixes = map[string]string{}
+		for prefix, origin := range gnmi.DefaultOriginPrefixes {
+			originPrefixes[prefix] = origin
+		}
+		for prefix, origin := range originPrefixFlag {
+			originPrefixes[prefix] = origin
+		}
+	}
 
 	var sampleInterval, heartbeatInterval time.Duration
 	var err error
@@ -159,6 +334,21 @@ func Main() {
 		usageAndExit(fmt.Sprintf("error: heartbeat interval (%s) invalid", *heartbeatIntervalStr))
 	}
 	subscribeOptions.HeartbeatInterval = uint64(heartbeatInterval)
+	if subscribeOptions.Compact, err = gnmi.ParseCompactPolicy(*compactPaths); err != nil {
+		usageAndExit("error: " + err.Error())
+	}
+	subscribeOptions.CompactLogger = &aglog.Glog{}
+	if *reconnect {
+		subscribeOptions.Reconnect = &gnmi.ResubscribeOptions{
+			UpdatesOnly: *reconnectUpdatesOnly,
+			Logger:      &aglog.Glog{},
+		}
+		marker, err := gnmi.ParseReconnectMarker(*reconnectMarker)
+		if err != nil {
+			usageAndExit("error: " + err.Error())
+		}
+		subscribeOptions.Reconnect.Marker = marker
+	}
 
 	var histExt *gnmi_ext.Extension_History
 	if *historyStartStr != "" || *historyEndStr != "" || *historySnapshotStr != "" {
@@ -206,12 +396,29 @@ func Main() {
 			grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: timeout}))
 	}
 
+	var rpcStats *gnmi.Stats
+	if *debugMode == "stats" {
+		rpcStats = gnmi.NewStats()
+		cfg.DialOptions = append(cfg.DialOptions, gnmi.WithStats(rpcStats))
+		// Print the final statistics of the commands that return.
+		defer rpcStats.WriteTo(os.Stdout)
+	}
+	var skew *gnmi.SkewDetector
+	if *maxClockSkew > 0 || *debugMode == "skew" {
+		skew = gnmi.NewSkewDetector(nil, *maxClockSkew, *maxClockSkew > 0)
+	}
+
 	args := flag.Args()
 
+	if *timeout > 0 {
+		cfg.DialOptions = append(cfg.DialOptions,
+			grpc.WithChainUnaryInterceptor(timeoutInterceptor(*timeout)))
+	}
+
 	ctx := gnmi.NewContext(context.Background(), cfg)
-	client, err := gnmi.Dial(cfg)
+	client, err := dial(cfg, *dialTimeout)
 	if err != nil {
-		glog.Fatal(err)
+		fatal(err)
 	}
 
 	var setOps []*gnmi.Operation
@@ -223,9 +430,12 @@ func Main() {
 				usageAndExit("error: 'capabilities' not allowed after" +
 					" 'update|replace|delete|union_replace'")
 			}
-			err := gnmi.Capabilities(ctx, client)
-			if err != nil {
-				glog.Fatal(err)
+			if err := capabilities(ctx, client, args[i+1:], *format == "json"); err != nil {
+				if errorOutput.format == "" && !errorOutput.quiet {
+					fmt.Fprintf(os.Stderr, "error: %s\n", err)
+					os.Exit(1)
+				}
+				fatal(err)
 			}
 			return
 		case "get":
@@ -233,19 +443,32 @@ func Main() {
 				usageAndExit("error: 'get' not allowed after" +
 					" 'update|replace|delete|union_replace'")
 			}
-			pathParams, argsParsed := parsereqParams(args[1:], false)
-			if argsParsed == 0 {
-				usageAndExit("error: missing path")
+			pathParams := reqParamsWithFile(args[1:], *pathsFile)
+			encoding, err := requestEncoding(ctx, client, *encodingStr, *negotiateEncoding)
+			if err != nil {
+				fatal(err)
+			}
+			if csvWriter != nil {
+				if err := csvWriter.WriteHeader(); err != nil {
+					fatal(err)
+				}
 			}
 			for _, pathParam := range pathParams {
+				if pathParam.encoding == "" {
+					pathParam.encoding = encoding
+				}
 				req, err := newGetRequest(pathParam, *dataTypeStr)
 				if err != nil {
 					usageAndExit("error: " + err.Error())
 				}
 
-				err = gnmi.GetWithRequest(ctx, client, req)
+				if csvWriter != nil {
+					err = getCSV(ctx, client, req, csvWriter)
+				} else {
+					err = gnmi.GetWithRequest(ctx, client, req)
+				}
 				if err != nil {
-					glog.Fatal(err)
+					fatal(withPaths(err, pathParam.paths))
 				}
 			}
 
@@ -255,7 +478,33 @@ func Main() {
 				usageAndExit("error: 'subscribe' not allowed after" +
 					" 'update|replace|delete|union_replace'")
 			}
+			encoding, err := requestEncoding(ctx, client, *encodingStr, *negotiateEncoding)
+			if err != nil {
+				fatal(err)
+			}
+			if csvWriter != nil {
+				if err := csvWriter.WriteHeader(); err != nil {
+					fatal(err)
+				}
+			}
+			if encoding != "" {
+				if subscribeOptions.Encoding, err = parseEncodingName(encoding); err != nil {
+					usageAndExit("error: " + err.Error())
+				}
+			}
+			subCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			var limits *subscribeLimits
 			var g errgroup.Group
+			subscribe := func(req *pb.SubscribeRequest,
+				respChan chan<- *pb.SubscribeResponse) error {
+				if subscribeOptions.Reconnect != nil &&
+					req.GetSubscribe().GetMode() == pb.SubscriptionList_STREAM {
+					return gnmi.SubscribeWithReconnect(subCtx, client, req, respChan,
+						*subscribeOptions.Reconnect)
+				}
+				return gnmi.SubscribeWithRequest(subCtx, client, req, respChan)
+			}
 			if *protoRequest {
 				if len(args[1:]) != 1 {
 					usageAndExit("error: 'subscribe' with -proto must be followed by a" +
@@ -265,32 +514,72 @@ func Main() {
 				if err != nil {
 					usageAndExit(fmt.Sprintf("error: %s", err))
 				}
+				if *expandWildcards {
+					if err := gnmi.ExpandSubscribeRequest(ctx, client, req); err != nil {
+						fatal(err)
+					}
+				}
+				limits = newSubscribeLimits(*exitAfterSync, *maxNotifications, 1, cancel)
 				respChan := make(chan *pb.SubscribeResponse)
 				g.Go(func() error {
-					return gnmi.SubscribeWithRequest(ctx, client, req, respChan)
+					return subscribe(req, respChan)
 				})
-				handleSubscribeResponses(*debugMode, &g, respChan)
+				respChan = limits.wrap(respChan)
+				if skew != nil {
+					respChan = gnmi.SkewSubscribeResponses(respChan, skew)
+				}
+				if *verify {
+					respChan = verifySubscribeResponses(req, respChan)
+				}
+				handleSubscribeResponses(*debugMode, rpcStats, skew, csvWriter, &g,
+					respChan)
 			} else {
-				pathParams, argsParsed := parsereqParams(args[1:], false)
-				if argsParsed == 0 {
-					usageAndExit("error: missing path")
+				pathParams := reqParamsWithFile(args[1:], *pathsFile)
+				reqs, reqPaths, err := newSubscribeRequests(pathParams, histExt,
+					subscribeOptions)
+				if err != nil {
+					usageAndExit("error: " + err.Error())
 				}
-				for _, pathParam := range pathParams {
-					subOptions, err := newSubscribeOptions(pathParam, histExt, subscribeOptions)
-					if err != nil {
-						usageAndExit("error: " + err.Error())
+				limits = newSubscribeLimits(*exitAfterSync, *maxNotifications, len(reqs),
+					cancel)
+				for i, req := range reqs {
+					paths := reqPaths[i]
+					if *expandWildcards {
+						if err := gnmi.ExpandSubscribeRequest(ctx, client, req); err != nil {
+							fatal(withPaths(err, paths))
+						}
 					}
 
 					respChan := make(chan *pb.SubscribeResponse)
 					g.Go(func() error {
-						return gnmi.SubscribeErr(ctx, client, subOptions, respChan)
+						return withPaths(subscribe(req, respChan), paths)
 					})
-					handleSubscribeResponses(*debugMode, &g, respChan)
+					respChan = limits.wrap(respChan)
+					if skew != nil {
+						respChan = gnmi.SkewSubscribeResponses(respChan, skew)
+					}
+					if *verify {
+						respChan = verifySubscribeResponses(req, respChan)
+					}
+					handleSubscribeResponses(*debugMode, rpcStats, skew, csvWriter, &g,
+						respChan)
 				}
 			}
 
-			if err := g.Wait(); err != nil {
-				glog.Fatal(err)
+			// The subscriptions canceled once the limits are reached are
+			// not a failure.
+			if err := g.Wait(); err != nil && !limits.limitReached() {
+				fatal(err)
+			}
+			return
+		case "expand":
+			if len(setOps) != 0 {
+				usageAndExit("error: 'expand' not allowed after" +
+					" 'update|replace|delete|union_replace'")
+			}
+			pathParams := reqParamsWithFile(args[1:], *pathsFile)
+			if err := expand(ctx, client, pathParams); err != nil {
+				fatal(err)
 			}
 			return
 		case "update", "replace", "delete", "union_replace":
@@ -307,26 +596,212 @@ func Main() {
 				usageAndExit("'set' must be followed by a single proto text/file argument")
 			}
 			if err := setWithProto(ctx, client, args[1]); err != nil {
-				glog.Fatal(err)
+				fatal(err)
+			}
+			return
+		case "commit":
+			if len(setOps) != 0 {
+				usageAndExit("error: 'commit' not allowed after" +
+					" 'update|replace|delete|union_replace'")
+			}
+			ext, err := newCommitExtension(args[1:])
+			if err != nil {
+				usageAndExit("error: " + err.Error())
+			}
+			if err := gnmi.Set(ctx, client, nil, &gnmi_ext.Extension{Ext: ext}); err != nil {
+				fatal(err)
 			}
 			return
+		case "set_batch":
+			if len(args) != 2 {
+				usageAndExit("'set_batch' must be followed by a single file argument")
+			}
+			opts := gnmi.SetBatchOptions{
+				BatchSize:   *setBatchSize,
+				Concurrency: *setConcurrency,
+			}
+			if err := setBatch(ctx, client, args[1], *arbitrationStr, opts); err != nil {
+				fatal(err)
+			}
+			return
+		case "apply-diff":
+			if len(setOps) != 0 {
+				usageAndExit("error: 'apply-diff' not allowed after" +
+					" 'update|replace|delete|union_replace'")
+			}
+			listKeys, err := parseListKeys(*diffListKeysStr)
+			if err != nil {
+				usageAndExit("error: " + err.Error())
+			}
+			setOps, err = newApplyDiffOperations(args[i+1:], listKeys)
+			if err != nil {
+				usageAndExit("error: " + err.Error())
+			}
+			if len(setOps) == 0 {
+				fmt.Println("no changes")
+				return
+			}
+			i = len(args)
 		default:
 			usageAndExit(fmt.Sprintf("error: unknown operation %q", args[i]))
 		}
 	}
+	readStdin, err := readStdinValue(setOps, os.Stdin)
+	if err != nil {
+		usageAndExit("error: " + err.Error())
+	}
+	if readStdin && *confirmTimeout > 0 {
+		usageAndExit("error: -confirm_timeout reads the confirmation on stdin, " +
+			"it cannot be used with a value read from stdin")
+	}
 	arb, err := gnmi.ArbitrationExt(*arbitrationStr)
 	if err != nil {
-		glog.Fatal(err)
+		fatal(err)
 	}
 	var exts []*gnmi_ext.Extension
 	if arb != nil {
 		exts = append(exts, arb)
 	}
+	if *commitID != "" {
+		if *confirmTimeout > 0 {
+			usageAndExit("error: -commit_id and -confirm_timeout cannot be used together")
+		}
+		exts = append(exts, &gnmi_ext.Extension{
+			Ext: gnmi.CommitExtension(*commitID, *commitRollback)})
+	}
+	if Validator != nil {
+		if err := gnmi.ValidateSet(Validator, setOps); err != nil {
+			fatal(err)
+		}
+	}
+	if *confirmTimeout > 0 {
+		if err := setConfirmed(ctx, client, setOps, exts, *confirmTimeout); err != nil {
+			fatal(err)
+		}
+		return
+	}
 	err = gnmi.Set(ctx, client, setOps, exts...)
 	if err != nil {
-		glog.Fatal(err)
+		fatal(err)
+	}
+
+}
+
+// newCommitExtension returns the commit extension of the arguments of the
+// commit operation: confirm|cancel ID or rollback_duration ID DURATION.
+func newCommitExtension(args []string) (*gnmi_ext.Extension_Commit, error) {
+	if len(args) < 2 || args[1] == "" {
+		return nil, errors.New("'commit' must be followed by confirm|cancel ID or " +
+			"rollback_duration ID DURATION")
+	}
+	action, id := args[0], args[1]
+	switch {
+	case action == "confirm" && len(args) == 2:
+		return gnmi.CommitConfirmExtension(id), nil
+	case action == "cancel" && len(args) == 2:
+		return gnmi.CommitCancelExtension(id), nil
+	case action == "rollback_duration" && len(args) == 3:
+		d, err := time.ParseDuration(args[2])
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid rollback duration %q", args[2])
+		}
+		return gnmi.CommitRollbackDurationExtension(id, d), nil
+	}
+	return nil, fmt.Errorf("invalid commit operation %q", strings.Join(args, " "))
+}
+
+// expand prints the instantiated paths matched by the wildcards of the
+// paths of pathParams, one per line.
+func expand(ctx context.Context, client pb.GNMIClient, pathParams []reqParams) error {
+	for _, pathParam := range pathParams {
+		for _, p := range pathParam.paths {
+			path, err := gnmi.ParseGNMIElements(gnmi.SplitPath(p))
+			if err != nil {
+				return err
+			}
+			path.Element = nil
+			path.Origin, path.Target = pathParam.origin, pathParam.target
+			expanded, err := gnmi.ExpandWildcards(ctx, client, path)
+			if err != nil {
+				return fmt.Errorf("failed to expand %s: %s", p, err)
+			}
+			for _, e := range expanded {
+				fmt.Println(pathWithOrigin(e))
+			}
+		}
 	}
+	return nil
+}
 
+// setConfirmed sends setOps and rolls them back unless "confirm" is
+// entered on stdin within timeout.
+func setConfirmed(ctx context.Context, client pb.GNMIClient, setOps []*gnmi.Operation,
+	exts []*gnmi_ext.Extension, timeout time.Duration) error {
+	s, err := gnmi.SetConfirmed(ctx, client, setOps,
+		gnmi.ConfirmedSetOptions{Timeout: timeout, Extensions: exts})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Set applied, enter 'confirm' within %s to keep it, "+
+		"anything else rolls it back: ", timeout)
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer <- strings.TrimSpace(line)
+	}()
+	select {
+	case line := <-answer:
+		if line == "confirm" {
+			if err := s.Confirm(); err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "Set confirmed")
+			return nil
+		}
+		if err := s.Rollback(); err != nil {
+			return err
+		}
+	case <-s.Done():
+		fmt.Fprintln(os.Stderr)
+		if _, err := s.State(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Set rolled back")
+	return nil
+}
+
+// dial connects to the target. If dialTimeout is positive, dial waits for
+// the connection to be established, for at most dialTimeout.
+func dial(cfg *gnmi.Config, dialTimeout time.Duration) (pb.GNMIClient, error) {
+	if dialTimeout <= 0 {
+		return gnmi.Dial(cfg)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithReturnConnectionError())
+	client, err := gnmi.DialContext(ctx, cfg)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("failed to connect to %s within the dial timeout of %s: %s",
+			cfg.Addr, dialTimeout, err)
+	}
+	return client, err
+}
+
+// timeoutInterceptor sets a deadline on each unary RPC, and reports the
+// RPCs exceeding it with the timeout.
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		err := invoker(rpcCtx, method, req, reply, cc, opts...)
+		if status.Code(err) == codes.DeadlineExceeded && ctx.Err() == nil {
+			return status.Errorf(codes.DeadlineExceeded, "%s did not complete within the "+
+				"timeout of %s", path.Base(method), timeout)
+		}
+		return err
+	}
 }
 
 // parseSubscribeRequestProto unmarshals the proto text/file of the SubscribeRequest.
@@ -350,8 +825,8 @@ func parseProtoFileOrText(arg string) []byte {
 	return proto
 }
 
-func handleSubscribeResponses(debugMode string, g *errgroup.Group,
-	respChan chan *pb.SubscribeResponse) {
+func handleSubscribeResponses(debugMode string, rpcStats *gnmi.Stats, skew *gnmi.SkewDetector,
+	csvWriter *gnmi.CSVWriter, g *errgroup.Group, respChan chan *pb.SubscribeResponse) {
 	switch debugMode {
 	case "proto":
 		for resp := range respChan {
@@ -361,19 +836,96 @@ func handleSubscribeResponses(debugMode string, g *errgroup.Group,
 		for resp := range respChan {
 			printLatencyStats(resp)
 		}
-	case "throughput":
-		handleThroughput(respChan)
+	case "stats":
+		handleStats(rpcStats, respChan)
+	case "paths":
+		handlePathStats(respChan)
+	case "skew":
+		handleSkewStats(skew, respChan)
 	case "clog":
 		// Don't read any subscription updates
 		g.Wait()
 	case "":
-		go processSubscribeResponses(respChan)
+		// Process the responses in g so that they are all printed before
+		// exiting once the subscriptions end.
+		if csvWriter != nil {
+			g.Go(func() error {
+				processSubscribeResponsesCSV(csvWriter, respChan)
+				return nil
+			})
+			return
+		}
+		g.Go(func() error {
+			processSubscribeResponses(respChan)
+			return nil
+		})
 
 	default:
 		usageAndExit(fmt.Sprintf("unknown debug option: %q", debugMode))
 	}
 }
 
+// verifySubscribeResponses forwards the responses of respChan to the
+// returned channel and reports on stderr the paths of req that produced no
+// update before the sync_response.
+func verifySubscribeResponses(req *pb.SubscribeRequest,
+	respChan chan *pb.SubscribeResponse) chan *pb.SubscribeResponse {
+	verified := make(chan *pb.SubscribeResponse)
+	go func() {
+		defer close(verified)
+		v := gnmi.NewSubscriptionVerifier(req)
+		for resp := range respChan {
+			if v.Update(resp) {
+				reportMissingPaths(v.Missing())
+			}
+			verified <- resp
+		}
+		if !v.Synced() {
+			fmt.Fprintln(os.Stderr, "verify: subscription ended before the sync_response")
+			reportMissingPaths(v.Missing())
+		}
+	}()
+	return verified
+}
+
+func reportMissingPaths(missing []*pb.Path) {
+	for _, p := range missing {
+		fmt.Fprintf(os.Stderr, "verify: no update received for %s\n", pathWithOrigin(p))
+	}
+}
+
+func pathWithOrigin(p *pb.Path) string {
+	if p.Origin == "" {
+		return gnmi.StrPath(p)
+	}
+	return p.Origin + ":" + gnmi.StrPath(p)
+}
+
+// readStdinValue replaces the "-" value of one of setOps with the content
+// of r, e.g. multi-line CLI commands given in a heredoc, and returns true if
+// it did.
+func readStdinValue(setOps []*gnmi.Operation, r io.Reader) (bool, error) {
+	var stdinOp *gnmi.Operation
+	for _, op := range setOps {
+		if op.Type == "delete" || op.Val != "-" {
+			continue
+		}
+		if stdinOp != nil {
+			return false, errors.New("only one value can be read from stdin")
+		}
+		stdinOp = op
+	}
+	if stdinOp == nil {
+		return false, nil
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the value from stdin: %s", err)
+	}
+	stdinOp.Val = string(b)
+	return true, nil
+}
+
 func newSetOperation(
 	index int,
 	args []string,
@@ -403,6 +955,11 @@ func newSetOperation(
 		return 0, nil, fmt.Errorf("encoding option is not supported for '%s'", op.Type)
 	}
 
+	// check that depth is not set
+	if pathParam.depth != "" {
+		return 0, nil, fmt.Errorf("depth option is not supported for '%s'", op.Type)
+	}
+
 	if len(pathParam.paths) == 0 {
 		return 0, nil, fmt.Errorf("missing path for '%s'", op.Type)
 	}
@@ -438,6 +995,33 @@ func setWithProto(ctx context.Context, client pb.GNMIClient, arg string) error {
 	return nil
 }
 
+// setBatch reads the Set operations in file, sends them with gnmi.SetBatch
+// and prints a summary of the results.
+func setBatch(ctx context.Context, client pb.GNMIClient, file, arbitrationStr string,
+	opts gnmi.SetBatchOptions) error {
+	setOps, err := readSetOperations(file)
+	if err != nil {
+		return err
+	}
+	if Validator != nil {
+		if err := gnmi.ValidateSet(Validator, setOps); err != nil {
+			return err
+		}
+	}
+	arb, err := gnmi.ArbitrationExt(arbitrationStr)
+	if err != nil {
+		return err
+	}
+	if arb != nil {
+		opts.Extensions = append(opts.Extensions, arb)
+	}
+	results, err := gnmi.SetBatch(ctx, client, setOps, opts)
+	if failed := gnmi.WriteSetSummary(os.Stdout, results); failed > 0 && err == nil {
+		err = fmt.Errorf("%d of %d SetRequests failed", failed, len(results))
+	}
+	return err
+}
+
 func newSubscribeOptions(
 	pathParam reqParams,
 	histExt *gnmi_ext.Extension_History,
@@ -472,10 +1056,18 @@ func newSubscribeOptions(
 		return nil, errors.New("encoding option is not supported for 'subscribe'")
 	}
 
+	// check that depth is not set
+	if pathParam.depth != "" {
+		return nil, errors.New("depth option is not supported for 'subscribe'")
+	}
+
 	subOptions := new(gnmi.SubscribeOptions)
 	*subOptions = *subscribeOptions
 	subOptions.Origin = origin
 	subOptions.Target = target
+	if pathParam.mode != "" {
+		subOptions.StreamMode = pathParam.mode
+	}
 
 	// setting sample interval from pathParam only if
 	// -sample_interval flag is not set & sample_interval= is set
@@ -493,13 +1085,58 @@ func newSubscribeOptions(
 	return subOptions, nil
 }
 
+// newSubscribeRequests returns the SubscribeRequests of pathParams along
+// with their paths: one per path group, or, if a group sets its stream
+// mode, one per target with the subscriptions of all its groups in a
+// single SubscriptionList of mixed modes.
+func newSubscribeRequests(pathParams []reqParams, histExt *gnmi_ext.Extension_History,
+	subscribeOptions *gnmi.SubscribeOptions) ([]*pb.SubscribeRequest, [][]string, error) {
+	merge := false
+	for _, pathParam := range pathParams {
+		merge = merge || pathParam.mode != ""
+	}
+	var reqs []*pb.SubscribeRequest
+	var reqPaths [][]string
+	targets := map[string]int{}
+	for _, pathParam := range pathParams {
+		subOptions, err := newSubscribeOptions(pathParam, histExt, subscribeOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		req, err := gnmi.NewSubscribeRequest(subOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i, ok := targets[pathParam.target]; ok && merge {
+			subList := reqs[i].GetSubscribe()
+			subList.Subscription = append(subList.Subscription,
+				req.GetSubscribe().Subscription...)
+			reqPaths[i] = append(reqPaths[i], pathParam.paths...)
+			continue
+		}
+		targets[pathParam.target] = len(reqs)
+		reqs = append(reqs, req)
+		reqPaths = append(reqPaths, append([]string(nil), pathParam.paths...))
+	}
+	return reqs, reqPaths, nil
+}
+
 func newGetRequest(pathParam reqParams, dataTypeStr string) (*pb.GetRequest, error) {
 	origin := pathParam.origin
 	target := pathParam.target
 	paths := pathParam.paths
 	encoding := pathParam.encoding
 
-	req, err := gnmi.NewGetRequest(gnmi.SplitPaths(paths), origin)
+	var exts []*gnmi_ext.Extension
+	if pathParam.depth != "" {
+		depth, err := strconv.ParseUint(pathParam.depth, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid depth (%s): %s", pathParam.depth, err)
+		}
+		exts = append(exts, &gnmi_ext.Extension{Ext: gnmi.DepthExtension(uint32(depth))})
+	}
+
+	req, err := gnmi.NewGetRequest(gnmi.SplitPaths(paths), origin, exts...)
 	if err != nil {
 		glog.Fatal(err)
 	}
@@ -527,20 +1164,68 @@ func newGetRequest(pathParam reqParams, dataTypeStr string) (*pb.GetRequest, err
 	}
 
 	// set encoding
-	switch en := strings.ToLower(encoding); en {
+	if encoding != "" {
+		if req.Encoding, err = parseEncodingName(encoding); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// capabilities prints the capabilities of the target, in JSON if
+// jsonOutput. The model=MODEL and encoding=ENCODING args restrict the
+// printed models and encodings, and capabilities fails if any is not
+// supported.
+func capabilities(ctx context.Context, client pb.GNMIClient, args []string,
+	jsonOutput bool) error {
+	var models []string
+	var encodings []pb.Encoding
+	for _, arg := range args {
+		if m, ok := parseStringOpt(arg, "model"); ok {
+			models = append(models, m)
+		} else if e, ok := parseEncoding(arg); ok {
+			enc, err := parseEncodingName(e)
+			if err != nil {
+				usageAndExit(fmt.Sprintf("error: %s", err))
+			}
+			encodings = append(encodings, enc)
+		} else {
+			usageAndExit(fmt.Sprintf("error: unexpected capabilities argument %q", arg))
+		}
+	}
+	resp, err := client.Capabilities(ctx, &pb.CapabilityRequest{})
+	if err != nil {
+		return err
+	}
+	resp, filterErr := gnmi.FilterCapabilities(resp, models, encodings)
+	if jsonOutput {
+		b, err := protojson.MarshalOptions{Multiline: true}.Marshal(resp)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		fmt.Println(string(b))
+	} else {
+		gnmi.PrintCapabilities(resp)
+	}
+	return filterErr
+}
+
+// parseEncodingName returns the gNMI encoding named s (case insensitive).
+func parseEncodingName(s string) (pb.Encoding, error) {
+	switch en := strings.ToLower(s); en {
 	case "ascii":
-		req.Encoding = pb.Encoding_ASCII
+		return pb.Encoding_ASCII, nil
 	case "json":
-		req.Encoding = pb.Encoding_JSON
+		return pb.Encoding_JSON, nil
 	case "json_ietf":
-		req.Encoding = pb.Encoding_JSON_IETF
+		return pb.Encoding_JSON_IETF, nil
 	case "proto":
-		req.Encoding = pb.Encoding_PROTO
+		return pb.Encoding_PROTO, nil
 	case "bytes":
-		req.Encoding = pb.Encoding_BYTES
-	case "":
+		return pb.Encoding_BYTES, nil
 	default:
-		return nil, fmt.Errorf(
+		return 0, fmt.Errorf(
 			`invalid encoding '%s'
 Supported encodings are (case insensitive):
 - JSON
@@ -549,8 +1234,23 @@ Supported encodings are (case insensitive):
 - ASCII
 - JSON_IETF`, en)
 	}
+}
 
-	return req, nil
+// requestEncoding returns the name of the encoding to use for the requests
+// that don't specify one: encodingStr if set, otherwise the encoding
+// negotiated with the target if negotiate is true, otherwise "" to use the
+// default encoding of the request.
+func requestEncoding(ctx context.Context, client pb.GNMIClient, encodingStr string,
+	negotiate bool) (string, error) {
+	if encodingStr != "" || !negotiate {
+		return encodingStr, nil
+	}
+	enc, err := gnmi.NegotiateEncoding(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("failed to negotiate encoding: %s", err)
+	}
+	glog.V(1).Infof("Negotiated encoding %s", enc)
+	return strings.ToLower(enc.String()), nil
 }
 
 func processSubscribeResponses(respChan chan *pb.SubscribeResponse) {
@@ -561,6 +1261,25 @@ func processSubscribeResponses(respChan chan *pb.SubscribeResponse) {
 	}
 }
 
+func processSubscribeResponsesCSV(csvWriter *gnmi.CSVWriter,
+	respChan chan *pb.SubscribeResponse) {
+	for resp := range respChan {
+		if err := csvWriter.WriteSubscribeResponse(resp); err != nil {
+			glog.Fatal(err)
+		}
+	}
+}
+
+// getCSV sends req and writes the response with csvWriter.
+func getCSV(ctx context.Context, client pb.GNMIClient, req *pb.GetRequest,
+	csvWriter *gnmi.CSVWriter) error {
+	resp, err := client.Get(ctx, req)
+	if err != nil {
+		return err
+	}
+	return csvWriter.WriteGetResponse(resp)
+}
+
 // Parse string timestamp, first trying for ns since epoch, and then
 // for RFC3339.
 func parseTime(ts string) (time.Time, error) {
@@ -589,10 +1308,18 @@ func parseEncoding(s string) (string, bool) {
 	return parseStringOpt(s, "encoding")
 }
 
+func parseDepth(s string) (string, bool) {
+	return parseStringOpt(s, "depth")
+}
+
 func parseSampleInterval(s string) (string, bool) {
 	return parseStringOpt(s, "sample_interval")
 }
 
+func parseMode(s string) (string, bool) {
+	return parseStringOpt(s, "mode")
+}
+
 func parsereqParams(args []string, maxOnePath bool) ([]reqParams, int) {
 	var pathParam *reqParams = new(reqParams)
 	pathParam.sampleInterval = "0" // default sample interval value
@@ -604,12 +1331,14 @@ func parsereqParams(args []string, maxOnePath bool) ([]reqParams, int) {
 	// - PATHS+ still mark the end of a pathParam
 	// - only path is required and everything else is optional
 	// - there can be one or more paths
-	// - there can be zero or one encoding, origin, target and sample_interval.
+	// - there can be zero or one encoding, origin, target, sample_interval, mode and depth.
 
 	var isOriginSet bool
 	var isTargetSet bool
 	var isEncodingSet bool
 	var isSampleIntervalSet bool
+	var isModeSet bool
+	var isDepthSet bool
 
 	// check if the current config forms a pathParam
 	// If yes, reset all the trackers and add it to pathParams
@@ -620,6 +1349,8 @@ func parsereqParams(args []string, maxOnePath bool) ([]reqParams, int) {
 			isTargetSet = false
 			isEncodingSet = false
 			isSampleIntervalSet = false
+			isModeSet = false
+			isDepthSet = false
 			pathParams = append(pathParams, *pathParam)
 			pathParam = new(reqParams)
 			pathParam.sampleInterval = "0"
@@ -644,6 +1375,14 @@ func parsereqParams(args []string, maxOnePath bool) ([]reqParams, int) {
 			checkGroup(isSampleIntervalSet)
 			pathParam.sampleInterval = si
 			isSampleIntervalSet = true
+		} else if m, ok := parseMode(arg); ok {
+			checkGroup(isModeSet)
+			pathParam.mode = m
+			isModeSet = true
+		} else if d, ok := parseDepth(arg); ok {
+			checkGroup(isDepthSet)
+			pathParam.depth = d
+			isDepthSet = true
 		} else {
 			pathParam.paths = append(pathParam.paths, arg)
 			if maxOnePath {
@@ -656,15 +1395,79 @@ func parsereqParams(args []string, maxOnePath bool) ([]reqParams, int) {
 	pathParams = append(pathParams, *pathParam)
 
 	// validate that all reqParams have a valid path
-	for _, param := range pathParams {
+	for i, param := range pathParams {
 		if param.paths == nil {
 			return pathParams, 0 // no path provided
 		}
+		if param.origin == "" {
+			pathParams[i].origin = inferOrigin(param.paths)
+		}
 	}
 
 	return pathParams, argsParsed
 }
 
+// reqParamsWithFile returns the reqParams of args followed by the ones of
+// the paths of pathsFile, if not empty, and exits if there are no paths.
+func reqParamsWithFile(args []string, pathsFile string) []reqParams {
+	var pathParams []reqParams
+	if len(args) > 0 || pathsFile == "" {
+		var argsParsed int
+		if pathParams, argsParsed = parsereqParams(args, false); argsParsed == 0 {
+			usageAndExit("error: missing path")
+		}
+	}
+	if pathsFile != "" {
+		entries, err := gnmi.ReadPathsFile(pathsFile)
+		if err != nil {
+			usageAndExit("error: " + err.Error())
+		}
+		if len(entries) == 0 && len(pathParams) == 0 {
+			usageAndExit(fmt.Sprintf("error: no path in %s", pathsFile))
+		}
+		pathParams = append(pathParams, pathsFileParams(entries)...)
+	}
+	return pathParams
+}
+
+// pathsFileParams groups the consecutive paths of a paths file with the
+// same origin and interval into reqParams.
+func pathsFileParams(entries []gnmi.PathsFileEntry) []reqParams {
+	var pathParams []reqParams
+	for i, e := range entries {
+		if i > 0 && e.Origin == entries[i-1].Origin && e.Interval == entries[i-1].Interval {
+			last := &pathParams[len(pathParams)-1]
+			last.paths = append(last.paths, e.Path)
+			continue
+		}
+		param := reqParams{origin: e.Origin, sampleInterval: "0", paths: []string{e.Path}}
+		if e.Interval > 0 {
+			param.sampleInterval = e.Interval.String()
+		}
+		pathParams = append(pathParams, param)
+	}
+	for i, param := range pathParams {
+		if param.origin == "" {
+			pathParams[i].origin = inferOrigin(param.paths)
+		}
+	}
+	return pathParams
+}
+
+// inferOrigin returns the origin inferred for paths from originPrefixes,
+// or "" if the paths have no or different inferred origins.
+func inferOrigin(paths []string) string {
+	var origin string
+	for i, p := range paths {
+		o, _ := gnmi.InferOrigin(originPrefixes, p)
+		if i > 0 && o != origin {
+			return ""
+		}
+		origin = o
+	}
+	return origin
+}
+
 func printLatencyStats(s *pb.SubscribeResponse) {
 	switch resp := s.Response.(type) {
 	case *pb.SubscribeResponse_SyncResponse:
@@ -685,38 +1488,68 @@ func printLatencyStats(s *pb.SubscribeResponse) {
 	}
 }
 
-func handleThroughput(respChan <-chan *pb.SubscribeResponse) {
-	var notifs uint64
-	var updates uint64
+// handleStats discards the responses from respChan and periodically prints
+// the statistics collected by rpcStats along with the message and byte rates.
+func handleStats(rpcStats *gnmi.Stats, respChan <-chan *pb.SubscribeResponse) {
 	go func() {
-		var (
-			lastNotifs  uint64
-			lastUpdates uint64
-			lastTime    = time.Now()
-		)
+		last := rpcStats.Snapshot()
+		lastTime := time.Now()
 		ticker := time.NewTicker(10 * time.Second)
 		for t := range ticker.C {
-			newNotifs := atomic.LoadUint64(&notifs)
-			newUpdates := atomic.LoadUint64(&updates)
-			dNotifs := newNotifs - lastNotifs
-			dUpdates := newUpdates - lastUpdates
+			snap := rpcStats.Snapshot()
 			since := t.Sub(lastTime).Seconds()
-			lastNotifs = newNotifs
-			lastUpdates = newUpdates
+			fmt.Printf("%s:\n", t)
+			rpcStats.WriteTo(os.Stdout)
+			for method, m := range snap {
+				l := last[method]
+				fmt.Printf("%s: %f msgs/s %f bytes/s in\n", method,
+					float64(m.MsgsIn-l.MsgsIn)/since, float64(m.BytesIn-l.BytesIn)/since)
+			}
+			last = snap
 			lastTime = t
-			fmt.Printf("%s: %f notifs/s %f updates/s\n",
-				t, float64(dNotifs)/since, float64(dUpdates)/since)
 		}
 	}()
 
-	for resp := range respChan {
-		r, ok := resp.Response.(*pb.SubscribeResponse_Update)
-		if !ok {
-			continue
+	for range respChan {
+	}
+}
+
+// handleSkewStats prints the clock skew statistics of skew every 10
+// seconds and when the subscription ends.
+func handleSkewStats(skew *gnmi.SkewDetector, respChan <-chan *pb.SubscribeResponse) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case _, ok := <-respChan:
+			if !ok {
+				skew.WriteTo(os.Stdout)
+				return
+			}
+		case t := <-ticker.C:
+			fmt.Printf("%s: ", t)
+			skew.WriteTo(os.Stdout)
+		}
+	}
+}
+
+// handlePathStats prints the statistics of the paths of the responses
+// every 10 seconds and when the subscription ends.
+func handlePathStats(respChan <-chan *pb.SubscribeResponse) {
+	pathStats := gnmi.NewPathStatsCollector(nil)
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case resp, ok := <-respChan:
+			if !ok {
+				pathStats.WriteTo(os.Stdout)
+				return
+			}
+			pathStats.Update(resp)
+		case t := <-ticker.C:
+			fmt.Printf("%s:\n", t)
+			pathStats.WriteTo(os.Stdout)
 		}
-		notif := r.Update
-		atomic.AddUint64(&notifs, 1)
-		atomic.AddUint64(&updates, uint64(len(notif.Update)+len(notif.Delete)))
 	}
-	return
 }
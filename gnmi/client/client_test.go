@@ -10,11 +10,15 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/test"
 	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -42,6 +46,26 @@ func TestNewGetRequest(t *testing.T) {
 				},
 			},
 		},
+		"depth": {
+			pathParam: &reqParams{
+				depth: "2",
+				paths: []string{"/interfaces"},
+			},
+			exp: &pb.GetRequest{
+				Encoding: pb.Encoding_JSON,
+				Type:     pb.GetRequest_ALL,
+				Path: []*pb.Path{{
+					Element: []string{"interfaces"},
+					Elem: []*pb.PathElem{{
+						Name: "interfaces",
+					}},
+				},
+				},
+				Extension: []*gnmi_ext.Extension{{
+					Ext: &gnmi_ext.Extension_Depth{Depth: &gnmi_ext.Depth{Level: 2}},
+				}},
+			},
+		},
 		"default-cli": {
 			pathParam: &reqParams{
 				origin: "cli",
@@ -605,3 +629,223 @@ func errorHasPrefix(a, b error) bool {
 	}
 	return strings.HasPrefix(a.Error(), b.Error())
 }
+
+func TestParseEncodingName(t *testing.T) {
+	for s, exp := range map[string]pb.Encoding{
+		"json":      pb.Encoding_JSON,
+		"JSON_IETF": pb.Encoding_JSON_IETF,
+		"Proto":     pb.Encoding_PROTO,
+		"bytes":     pb.Encoding_BYTES,
+		"ascii":     pb.Encoding_ASCII,
+	} {
+		got, err := parseEncodingName(s)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", s, err)
+		} else if got != exp {
+			t.Errorf("%s: expected %s, got %s", s, exp, got)
+		}
+	}
+	if _, err := parseEncodingName("xml"); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+}
+
+func TestParseReqParamsDepth(t *testing.T) {
+	pathParams, argsParsed := parsereqParams(
+		[]string{"depth=1", "/a", "/b", "depth=3", "/c"}, false)
+	if argsParsed != 5 {
+		t.Fatalf("expected 5 args parsed, got %d", argsParsed)
+	}
+	exp := []reqParams{
+		{depth: "1", sampleInterval: "0", paths: []string{"/a", "/b"}},
+		{depth: "3", sampleInterval: "0", paths: []string{"/c"}},
+	}
+	if !test.DeepEqual(exp, pathParams) {
+		t.Errorf("expected %+v, got %+v", exp, pathParams)
+	}
+	if _, err := newGetRequest(reqParams{depth: "-1", paths: []string{"/a"}}, "all"); err == nil {
+		t.Error("expected error for invalid depth")
+	}
+}
+
+func TestNewSubscribeRequestsModes(t *testing.T) {
+	args := []string{"mode=on_change", "/system", "mode=sample", "sample_interval=10s",
+		"/interfaces", "target=t", "/c"}
+	pathParams, _ := parsereqParams(args, false)
+	exp := []reqParams{
+		{mode: "on_change", sampleInterval: "0", paths: []string{"/system"}},
+		{mode: "sample", sampleInterval: "10s", paths: []string{"/interfaces"}},
+		{target: "t", sampleInterval: "0", paths: []string{"/c"}},
+	}
+	if !test.DeepEqual(exp, pathParams) {
+		t.Fatalf("expected %+v, got %+v", exp, pathParams)
+	}
+	subscribeOptions := &gnmi.SubscribeOptions{Mode: "stream", StreamMode: "target_defined"}
+	reqs, reqPaths, err := newSubscribeRequests(pathParams, nil, subscribeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The groups of each target are merged into one SubscriptionList.
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %v", reqs)
+	}
+	if !test.DeepEqual([][]string{{"/system", "/interfaces"}, {"/c"}}, reqPaths) {
+		t.Errorf("unexpected paths %v", reqPaths)
+	}
+	subs := reqs[0].GetSubscribe().GetSubscription()
+	if len(subs) != 2 || subs[0].Mode != pb.SubscriptionMode_ON_CHANGE ||
+		subs[1].Mode != pb.SubscriptionMode_SAMPLE ||
+		subs[1].SampleInterval != uint64(10*time.Second) {
+		t.Errorf("unexpected subscriptions %v", subs)
+	}
+	subList := reqs[1].GetSubscribe()
+	if subList.Prefix.GetTarget() != "t" || len(subList.Subscription) != 1 ||
+		subList.Subscription[0].Mode != pb.SubscriptionMode_TARGET_DEFINED {
+		t.Errorf("unexpected request %v", reqs[1])
+	}
+
+	// Without modes, each group has its own request.
+	pathParams, _ = parsereqParams([]string{"/a", "sample_interval=1s", "/b"}, false)
+	if reqs, _, err := newSubscribeRequests(pathParams, nil, subscribeOptions); err != nil {
+		t.Fatal(err)
+	} else if len(reqs) != 2 {
+		t.Errorf("expected 2 requests, got %v", reqs)
+	}
+	pathParams, _ = parsereqParams([]string{"mode=fast", "/a"}, false)
+	if _, _, err := newSubscribeRequests(pathParams, nil, subscribeOptions); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
+
+func TestParseReqParamsInferOrigin(t *testing.T) {
+	originPrefixes = map[string]string{"/Sysdb": "eos_native", "/Sysdb/cell": ""}
+	defer func() { originPrefixes = nil }()
+	pathParams, _ := parsereqParams([]string{"/Sysdb/a", "/Sysdb/b", "target=t",
+		"/Sysdb/a", "/interfaces", "origin=openconfig", "/Sysdb/c", "target=u",
+		"/Sysdb/cell/1"}, false)
+	exp := []reqParams{
+		{origin: "eos_native", sampleInterval: "0", paths: []string{"/Sysdb/a", "/Sysdb/b"}},
+		{target: "t", sampleInterval: "0", paths: []string{"/Sysdb/a", "/interfaces"}},
+		{origin: "openconfig", sampleInterval: "0", paths: []string{"/Sysdb/c"}},
+		{target: "u", sampleInterval: "0", paths: []string{"/Sysdb/cell/1"}},
+	}
+	if !test.DeepEqual(exp, pathParams) {
+		t.Errorf("expected %+v, got %+v", exp, pathParams)
+	}
+}
+
+func TestPathsFileParams(t *testing.T) {
+	originPrefixes = map[string]string{"/Sysdb": "eos_native"}
+	defer func() { originPrefixes = nil }()
+	pathParams := pathsFileParams([]gnmi.PathsFileEntry{
+		{Path: "/Sysdb/a"},
+		{Path: "/Sysdb/b"},
+		{Path: "/c", Interval: time.Second},
+		{Path: "/d", Interval: time.Second},
+		{Origin: "cli", Path: "/d", Interval: time.Second},
+	})
+	exp := []reqParams{
+		{origin: "eos_native", sampleInterval: "0", paths: []string{"/Sysdb/a", "/Sysdb/b"}},
+		{sampleInterval: "1s", paths: []string{"/c", "/d"}},
+		{origin: "cli", sampleInterval: "1s", paths: []string{"/d"}},
+	}
+	if !test.DeepEqual(exp, pathParams) {
+		t.Errorf("expected %+v, got %+v", exp, pathParams)
+	}
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	interceptor := timeoutInterceptor(10 * time.Millisecond)
+	block := func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	err := interceptor(context.Background(), "/gnmi.gNMI/Get", nil, nil, nil, block)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Get did not complete within the timeout of 10ms") {
+		t.Errorf("unexpected error message: %s", err)
+	}
+
+	// A deadline of the caller is reported as is.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	interceptor = timeoutInterceptor(time.Minute)
+	err = interceptor(ctx, "/gnmi.gNMI/Get", nil, nil, nil, block)
+	if status.Code(err) != codes.DeadlineExceeded || strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected the DeadlineExceeded error of the caller, got %v", err)
+	}
+}
+
+func TestReadStdinValue(t *testing.T) {
+	cli := "interface Ethernet1\n   description uplink\n"
+	setOps := []*gnmi.Operation{
+		{Type: "delete", Path: []string{"-"}},
+		{Type: "union_replace", Origin: "cli", Val: "-"},
+	}
+	read, err := readStdinValue(setOps, strings.NewReader(cli))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read || setOps[1].Val != cli {
+		t.Errorf("expected the value to be read from stdin, got %q", setOps[1].Val)
+	}
+
+	read, err = readStdinValue([]*gnmi.Operation{{Type: "update", Val: "true"}},
+		strings.NewReader(cli))
+	if err != nil || read {
+		t.Errorf("expected stdin not to be read, got %t, %v", read, err)
+	}
+
+	_, err = readStdinValue([]*gnmi.Operation{{Type: "update", Val: "-"},
+		{Type: "replace", Val: "-"}}, strings.NewReader(cli))
+	if err == nil {
+		t.Error("expected an error reading two values from stdin")
+	}
+}
+
+func TestNewCommitExtension(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected *gnmi_ext.Extension_Commit
+	}{{
+		args:     []string{"confirm", "c1"},
+		expected: gnmi.CommitConfirmExtension("c1"),
+	}, {
+		args:     []string{"cancel", "c1"},
+		expected: gnmi.CommitCancelExtension("c1"),
+	}, {
+		args:     []string{"rollback_duration", "c1", "5m"},
+		expected: gnmi.CommitRollbackDurationExtension("c1", 5*time.Minute),
+	}, {
+		args: []string{"confirm"},
+	}, {
+		args: []string{"confirm", "c1", "5m"},
+	}, {
+		args: []string{"rollback_duration", "c1", "-1s"},
+	}, {
+		args: []string{"commit", "c1"},
+	}} {
+		ext, err := newCommitExtension(tc.args)
+		if tc.expected == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", tc.args, ext.Commit)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tc.args, err)
+		} else if !proto.Equal(tc.expected.Commit, ext.Commit) {
+			t.Errorf("%q: expected %s, got %s", tc.args, tc.expected.Commit, ext.Commit)
+		}
+	}
+	if d := gnmi.CommitRollbackDurationExtension("c1", 5*time.Minute).Commit.
+		GetSetRollbackDuration().GetRollbackDuration().AsDuration(); d != 5*time.Minute {
+		t.Errorf("expected a rollback duration of 5m, got %s", d)
+	}
+	if gnmi.CommitExtension("c1", 0).Commit.GetCommit().GetRollbackDuration() != nil {
+		t.Error("expected no rollback duration with 0")
+	}
+}
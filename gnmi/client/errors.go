@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aristanetworks/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// errorOutput is how fatal reports the errors, set by the -error_format
+// and -quiet flags.
+var errorOutput struct {
+	format string
+	quiet  bool
+}
+
+// pathsError is an error of an RPC on paths.
+type pathsError struct {
+	paths []string
+	err   error
+}
+
+func (e *pathsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *pathsError) Unwrap() error {
+	return e.err
+}
+
+// withPaths returns err annotated with the paths of the RPC that failed.
+func withPaths(err error, paths []string) error {
+	if err == nil {
+		return nil
+	}
+	return &pathsError{paths: paths, err: err}
+}
+
+// jsonError is the JSON representation of an error with -error_format=json.
+type jsonError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details,omitempty"`
+	Path    string            `json:"path,omitempty"`
+}
+
+// formatError returns the report of err in format, text or json, and the
+// exit status it maps to: the gRPC status code of err, or Unknown (2) if
+// err does not have one.
+func formatError(err error, format string) ([]byte, int) {
+	var path string
+	var pe *pathsError
+	if errors.As(err, &pe) {
+		path = strings.Join(pe.paths, " ")
+		err = pe.err
+	}
+	s := status.Convert(err)
+	code := s.Code()
+	if code == codes.OK {
+		code = codes.Unknown
+	}
+	if format != "json" {
+		msg := "error: " + err.Error()
+		if path != "" {
+			msg += fmt.Sprintf(" (path: %s)", path)
+		}
+		return []byte(msg + "\n"), int(code)
+	}
+	e := jsonError{Code: code.String(), Message: s.Message(), Path: path}
+	for _, d := range s.Proto().GetDetails() {
+		b, err := protojson.Marshal(d)
+		if err != nil {
+			// The type of the detail is not known, keep its type URL.
+			b, _ = json.Marshal(map[string]string{"@type": d.GetTypeUrl()})
+		}
+		e.Details = append(e.Details, b)
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		b, _ = json.Marshal(jsonError{Code: e.Code, Message: e.Message, Path: path})
+	}
+	return append(b, '\n'), int(code)
+}
+
+// fatal reports err and exits. By default err is logged with glog.Fatal,
+// with -error_format or -quiet it is printed on stderr, or not at all, and
+// the exit status is its gRPC status code.
+func fatal(err error) {
+	if errorOutput.format == "" && !errorOutput.quiet {
+		glog.Fatal(err)
+	}
+	b, code := formatError(err, errorOutput.format)
+	if !errorOutput.quiet {
+		os.Stderr.Write(b)
+	}
+	glog.Flush()
+	os.Exit(code)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFormatError(t *testing.T) {
+	detailed, err := status.New(codes.FailedPrecondition, "locked").WithDetails(
+		&errdetails.ErrorInfo{Reason: "LOCKED", Domain: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		err    error
+		format string
+		out    string
+		code   int
+	}{
+		"text": {
+			err:    withPaths(status.Error(codes.NotFound, "no path"), []string{"/a", "/b"}),
+			format: "text",
+			out:    "error: rpc error: code = NotFound desc = no path (path: /a /b)\n",
+			code:   5,
+		},
+		"json": {
+			err:    withPaths(status.Error(codes.Unavailable, "down"), []string{"/a"}),
+			format: "json",
+			out:    `{"code":"Unavailable","message":"down","path":"/a"}` + "\n",
+			code:   14,
+		},
+		"json details": {
+			err:    detailed.Err(),
+			format: "json",
+			out: `{"code":"FailedPrecondition","message":"locked","details":[` +
+				`{"@type":"type.googleapis.com/google.rpc.ErrorInfo",` +
+				`"reason":"LOCKED","domain":"example.com"}]}` + "\n",
+			code: 9,
+		},
+		"wrapped": {
+			err:    fmt.Errorf("set failed: %w", status.Error(codes.Aborted, "conflict")),
+			format: "json",
+			out: `{"code":"Aborted",` +
+				`"message":"set failed: rpc error: code = Aborted desc = conflict"}` + "\n",
+			code: 10,
+		},
+		"no status": {
+			err:    errors.New("failed to dial"),
+			format: "json",
+			out:    `{"code":"Unknown","message":"failed to dial"}` + "\n",
+			code:   2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, code := formatError(tc.err, tc.format)
+			if string(out) != tc.out {
+				t.Errorf("expected output %q, got %q", tc.out, out)
+			}
+			if code != tc.code {
+				t.Errorf("expected exit status %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristanetworks/goarista/gnmi"
+)
+
+// readSetOperations reads the Set operations of file: a list of operations
+// in YAML or JSON, as parsed by parseSetFile, if its extension is .yaml,
+// .yml or .json, else one operation per line, as parsed by
+// parseSetOperations.
+func readSetOperations(file string) ([]*gnmi.Operation, error) {
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml", ".json":
+		return readSetFile(file)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ops, err := parseSetOperations(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", file, err)
+	}
+	return ops, nil
+}
+
+// parseSetOperations reads Set operations from r, one per line, using the same
+// syntax as on the command line:
+//
+//	update|replace|union_replace (origin=ORIGIN) (target=TARGET) PATH JSON|FILE
+//	delete (origin=ORIGIN) (target=TARGET) PATH
+//
+// The value extends to the end of the line so it may contain spaces.
+// Empty lines and lines starting with '#' are ignored.
+func parseSetOperations(r io.Reader) ([]*gnmi.Operation, error) {
+	var ops []*gnmi.Operation
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		op, err := parseSetOperationLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func parseSetOperationLine(line string) (*gnmi.Operation, error) {
+	// nextField returns the first space separated field of s and the rest of s.
+	nextField := func(s string) (string, string) {
+		s = strings.TrimLeft(s, " \t")
+		if i := strings.IndexAny(s, " \t"); i >= 0 {
+			return s[:i], strings.TrimLeft(s[i:], " \t")
+		}
+		return s, ""
+	}
+
+	typ, rest := nextField(line)
+	switch typ {
+	case "update", "replace", "union_replace", "delete":
+	default:
+		return nil, fmt.Errorf("unknown operation %q", typ)
+	}
+	op := &gnmi.Operation{Type: typ}
+	var field string
+	for {
+		field, rest = nextField(rest)
+		if o, ok := parseOrigin(field); ok {
+			op.Origin = o
+		} else if t, ok := parseTarget(field); ok {
+			op.Target = t
+		} else {
+			break
+		}
+	}
+	if field == "" {
+		return nil, fmt.Errorf("missing path for '%s'", typ)
+	}
+	op.Path = gnmi.SplitPath(field)
+	if typ == "delete" {
+		if rest != "" {
+			return nil, fmt.Errorf("unexpected value for 'delete': %q", rest)
+		}
+		return op, nil
+	}
+	if rest == "" {
+		return nil, fmt.Errorf("missing JSON or FILEPATH to data for '%s'", typ)
+	}
+	op.Val = rest
+	return op, nil
+}
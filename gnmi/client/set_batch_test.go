@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aristanetworks/goarista/gnmi"
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestParseSetOperations(t *testing.T) {
+	input := `# interfaces
+update /interfaces/interface[name=Ethernet1]/config/description a description
+
+replace origin=openconfig target=dev1 /system/config/hostname host1
+delete /interfaces/interface[name=Ethernet2]
+union_replace origin=cli / interface Ethernet3
+`
+	expected := []*gnmi.Operation{{
+		Type: "update",
+		Path: []string{"interfaces", "interface[name=Ethernet1]", "config", "description"},
+		Val:  "a description",
+	}, {
+		Type:   "replace",
+		Origin: "openconfig",
+		Target: "dev1",
+		Path:   []string{"system", "config", "hostname"},
+		Val:    "host1",
+	}, {
+		Type: "delete",
+		Path: []string{"interfaces", "interface[name=Ethernet2]"},
+	}, {
+		Type:   "union_replace",
+		Origin: "cli",
+		Path:   []string{},
+		Val:    "interface Ethernet3",
+	}}
+	ops, err := parseSetOperations(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !test.DeepEqual(expected, ops) {
+		t.Errorf("unexpected operations: %s", test.Diff(expected, ops))
+	}
+
+	// The files without YAML or JSON extension have one operation per line.
+	file := filepath.Join(t.TempDir(), "ops.txt")
+	if err := os.WriteFile(file, []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if ops, err := readSetOperations(file); err != nil {
+		t.Fatal(err)
+	} else if !test.DeepEqual(expected, ops) {
+		t.Errorf("unexpected operations: %s", test.Diff(expected, ops))
+	}
+
+	for _, input := range []string{
+		"get /a",
+		"update /a",
+		"delete /a 1",
+		"update origin=cli",
+	} {
+		if _, err := parseSetOperations(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
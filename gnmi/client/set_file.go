@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristanetworks/goarista/gnmi"
+	"gopkg.in/yaml.v2"
+)
+
+// setFileOperation is one of the Set operations of a YAML or JSON file
+// given to 'set_batch', which is a list of them.
+type setFileOperation struct {
+	Op     string `yaml:"op"`
+	Origin string `yaml:"origin"`
+	Target string `yaml:"target"`
+	Path   string `yaml:"path"`
+	// Value is the value inline: a string is used as on the command line,
+	// a list of strings is joined into lines for the cli origin, and any
+	// other value is encoded to JSON.
+	Value interface{} `yaml:"value"`
+	// File is the path to the value, relative to the directory of the
+	// file of the operations.
+	File string `yaml:"file"`
+}
+
+// readSetFile reads the Set operations of the YAML or JSON file.
+func readSetFile(file string) ([]*gnmi.Operation, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := parseSetFile(b, filepath.Dir(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", file, err)
+	}
+	return ops, nil
+}
+
+// parseSetFile parses the Set operations of b, resolving the relative
+// value files against dir.
+func parseSetFile(b []byte, dir string) ([]*gnmi.Operation, error) {
+	var fileOps []setFileOperation
+	if err := yaml.UnmarshalStrict(b, &fileOps); err != nil {
+		return nil, err
+	}
+	if len(fileOps) == 0 {
+		return nil, errors.New("no operation")
+	}
+	ops := make([]*gnmi.Operation, len(fileOps))
+	for i, fileOp := range fileOps {
+		op, err := fileOp.operation(dir)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %s", i+1, err)
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+func (o *setFileOperation) operation(dir string) (*gnmi.Operation, error) {
+	switch o.Op {
+	case "update", "replace", "union_replace", "delete":
+	case "":
+		return nil, errors.New("missing op")
+	default:
+		return nil, fmt.Errorf("unknown op %q", o.Op)
+	}
+	op := &gnmi.Operation{
+		Type:   o.Op,
+		Origin: o.Origin,
+		Target: o.Target,
+		Path:   gnmi.SplitPath(o.Path),
+	}
+	if o.Op == "delete" {
+		if o.Value != nil || o.File != "" {
+			return nil, errors.New("unexpected value for 'delete'")
+		}
+		return op, nil
+	}
+	switch {
+	case o.Value != nil && o.File != "":
+		return nil, fmt.Errorf("both value and file for '%s'", o.Op)
+	case o.File != "":
+		file := o.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		// The file is read when the SetRequest is built, make sure it
+		// is not taken for the value itself if it is missing.
+		if _, err := os.Stat(file); err != nil {
+			return nil, err
+		}
+		op.Val = file
+	case o.Value != nil:
+		val, err := setFileValue(o.Value, op.Origin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for '%s': %s", o.Op, err)
+		}
+		op.Val = val
+	default:
+		return nil, fmt.Errorf("missing value or file for '%s'", o.Op)
+	}
+	return op, nil
+}
+
+// setFileValue returns the value of a Set operation from its YAML value.
+func setFileValue(v interface{}, origin string) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []interface{}:
+		if origin == "cli" {
+			lines := make([]string, len(v))
+			for i, line := range v {
+				s, ok := line.(string)
+				if !ok {
+					return "", fmt.Errorf("CLI command %v is not a string", line)
+				}
+				lines[i] = s
+			}
+			return strings.Join(lines, "\n") + "\n", nil
+		}
+	}
+	v, err := jsonValue(v)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// jsonValue converts the maps decoded by yaml to maps that can be encoded
+// to JSON.
+func jsonValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			s, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v is not a string", k)
+			}
+			var err error
+			if m[s], err = jsonValue(e); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			var err error
+			if l[i], err = jsonValue(e); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	}
+	return v, nil
+}
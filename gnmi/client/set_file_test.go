@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aristanetworks/goarista/gnmi"
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestReadSetFile(t *testing.T) {
+	dir := t.TempDir()
+	valFile := filepath.Join(dir, "ethernet1.json")
+	if err := os.WriteFile(valFile, []byte(`{"description": "uplink"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opsFile := filepath.Join(dir, "ops.yaml")
+	if err := os.WriteFile(opsFile, []byte(`
+- op: replace
+  origin: openconfig
+  target: dev1
+  path: /system/config
+  value:
+    hostname: switch1
+    dns:
+      servers: [10.0.0.1, 10.0.0.2]
+- op: update
+  path: /interfaces/interface[name=Ethernet1]/config
+  file: ethernet1.json
+- op: update
+  path: /system/config/hostname
+  value: switch2
+- op: delete
+  path: /interfaces/interface[name=Ethernet2]
+- op: union_replace
+  origin: cli
+  path: ""
+  value:
+    - interface Ethernet3
+    - description uplink
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expected := []*gnmi.Operation{{
+		Type:   "replace",
+		Origin: "openconfig",
+		Target: "dev1",
+		Path:   []string{"system", "config"},
+		Val:    `{"dns":{"servers":["10.0.0.1","10.0.0.2"]},"hostname":"switch1"}`,
+	}, {
+		Type: "update",
+		Path: []string{"interfaces", "interface[name=Ethernet1]", "config"},
+		Val:  valFile,
+	}, {
+		Type: "update",
+		Path: []string{"system", "config", "hostname"},
+		Val:  "switch2",
+	}, {
+		Type: "delete",
+		Path: []string{"interfaces", "interface[name=Ethernet2]"},
+	}, {
+		Type:   "union_replace",
+		Origin: "cli",
+		Path:   gnmi.SplitPath(""),
+		Val:    "interface Ethernet3\ndescription uplink\n",
+	}}
+	ops, err := readSetOperations(opsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !test.DeepEqual(expected, ops) {
+		t.Errorf("unexpected operations: %s", test.Diff(expected, ops))
+	}
+
+	// JSON is also accepted.
+	ops, err = parseSetFile([]byte(`[{"op": "update", "path": "/a", "value": {"b": 1}}]`), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []*gnmi.Operation{{Type: "update", Path: []string{"a"}, Val: `{"b":1}`}}
+	if !test.DeepEqual(expected, ops) {
+		t.Errorf("unexpected operations: %s", test.Diff(expected, ops))
+	}
+
+	for _, input := range []string{
+		``,
+		`- op: merge
+  path: /a
+  value: 1`,
+		`- path: /a
+  value: 1`,
+		`- op: update
+  path: /a`,
+		`- op: update
+  path: /a
+  value: 1
+  file: ethernet1.json`,
+		`- op: update
+  path: /a
+  file: missing.json`,
+		`- op: delete
+  path: /a
+  value: 1`,
+		`- op: update
+  path: /a
+  valeu: 1`,
+	} {
+		if ops, err := parseSetFile([]byte(input), dir); err == nil {
+			t.Errorf("expected an error for %q, got %v", input, ops)
+		}
+	}
+}
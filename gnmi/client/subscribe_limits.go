@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// subscribeLimits ends the subscriptions of a subscribe once all of them
+// sent their sync_response, with -exit_after_sync, or once they received
+// a total of -max_notifications notifications.
+type subscribeLimits struct {
+	exitAfterSync    bool
+	maxNotifications uint64
+	cancel           context.CancelFunc
+
+	mu            sync.Mutex
+	unsynced      int
+	notifications uint64
+	reached       bool
+}
+
+// newSubscribeLimits returns the subscribeLimits of the given number of
+// subscriptions, calling cancel to end them.
+func newSubscribeLimits(exitAfterSync bool, maxNotifications uint64, subscriptions int,
+	cancel context.CancelFunc) *subscribeLimits {
+	return &subscribeLimits{
+		exitAfterSync:    exitAfterSync,
+		maxNotifications: maxNotifications,
+		cancel:           cancel,
+		unsynced:         subscriptions,
+	}
+}
+
+// enabled returns whether there is a limit to enforce.
+func (l *subscribeLimits) enabled() bool {
+	return l.exitAfterSync || l.maxNotifications > 0
+}
+
+// limitReached returns whether the limits were reached and the
+// subscriptions canceled as a result.
+func (l *subscribeLimits) limitReached() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reached
+}
+
+// update counts resp, a response of the subscription whose sync_response
+// was received already if synced is set, and returns whether it is to be
+// forwarded, that is the limits were not reached before it. The first
+// sync_response of the subscription sets synced: the ones following a
+// reconnection are not counted again.
+func (l *subscribeLimits) update(resp *pb.SubscribeResponse, synced *bool) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.reached {
+		return false
+	}
+	switch resp.Response.(type) {
+	case *pb.SubscribeResponse_Update:
+		l.notifications++
+		l.reached = l.maxNotifications > 0 && l.notifications >= l.maxNotifications
+	case *pb.SubscribeResponse_SyncResponse:
+		if *synced {
+			break
+		}
+		*synced = true
+		l.unsynced--
+		l.reached = l.exitAfterSync && l.unsynced == 0
+	}
+	if l.reached {
+		l.cancel()
+	}
+	return true
+}
+
+// wrap forwards the responses of a subscription from respChan to the
+// returned channel until the limits are reached.
+func (l *subscribeLimits) wrap(respChan chan *pb.SubscribeResponse) chan *pb.SubscribeResponse {
+	if !l.enabled() {
+		return respChan
+	}
+	limited := make(chan *pb.SubscribeResponse)
+	go func() {
+		defer close(limited)
+		var synced bool
+		for resp := range respChan {
+			if l.update(resp, &synced) {
+				limited <- resp
+			}
+		}
+	}()
+	return limited
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestSubscribeLimits(t *testing.T) {
+	update := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{
+		Update: &pb.Notification{}}}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+	for name, tc := range map[string]struct {
+		exitAfterSync    bool
+		maxNotifications uint64
+		// responses of each subscription, sent one subscription after the other
+		responses [][]*pb.SubscribeResponse
+		forwarded int
+		reached   bool
+	}{
+		"no limits": {
+			responses: [][]*pb.SubscribeResponse{{update, sync, update}},
+			forwarded: 3,
+		},
+		"exit after sync": {
+			exitAfterSync: true,
+			responses:     [][]*pb.SubscribeResponse{{update, update, sync, update}},
+			forwarded:     3,
+			reached:       true,
+		},
+		"exit after all the syncs": {
+			exitAfterSync: true,
+			responses: [][]*pb.SubscribeResponse{
+				{update, sync, update},
+				{update, sync, update},
+			},
+			forwarded: 5,
+			reached:   true,
+		},
+		"sync after a reconnection": {
+			exitAfterSync: true,
+			responses: [][]*pb.SubscribeResponse{
+				{update, sync, update, sync, update},
+				{update},
+			},
+			forwarded: 6,
+		},
+		"no sync": {
+			exitAfterSync: true,
+			responses:     [][]*pb.SubscribeResponse{{update, sync}, {update}},
+			forwarded:     3,
+		},
+		"max notifications": {
+			maxNotifications: 2,
+			responses:        [][]*pb.SubscribeResponse{{update, sync}, {update, update}},
+			forwarded:        3,
+			reached:          true,
+		},
+		"max notifications not reached": {
+			maxNotifications: 3,
+			responses:        [][]*pb.SubscribeResponse{{update, sync, update}},
+			forwarded:        3,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var canceled bool
+			l := newSubscribeLimits(tc.exitAfterSync, tc.maxNotifications,
+				len(tc.responses), func() { canceled = true })
+			var forwarded int
+			for _, responses := range tc.responses {
+				respChan := make(chan *pb.SubscribeResponse, len(responses))
+				for _, resp := range responses {
+					respChan <- resp
+				}
+				close(respChan)
+				for range l.wrap(respChan) {
+					forwarded++
+				}
+			}
+			if forwarded != tc.forwarded {
+				t.Errorf("expected %d forwarded responses, got %d", tc.forwarded, forwarded)
+			}
+			if l.limitReached() != tc.reached || canceled != tc.reached {
+				t.Errorf("expected reached and canceled to be %t, got %t and %t",
+					tc.reached, l.limitReached(), canceled)
+			}
+		})
+	}
+}
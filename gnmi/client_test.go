@@ -0,0 +1,189 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/logger"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type metadataServer struct {
+	pb.UnimplementedGNMIServer
+}
+
+func tokenOf(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return strings.Join(md.Get("token"), ",")
+}
+
+func (*metadataServer) Capabilities(ctx context.Context,
+	req *pb.CapabilityRequest) (*pb.CapabilityResponse, error) {
+	return &pb.CapabilityResponse{GNMIVersion: tokenOf(ctx)}, nil
+}
+
+func (*metadataServer) Subscribe(stream pb.GNMI_SubscribeServer) error {
+	return status.Error(codes.Unavailable, tokenOf(stream.Context()))
+}
+
+func TestDialRPCContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterGNMIServer(s, &metadataServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	var calls int
+	cfg := &Config{
+		Addr: l.Addr().String(),
+		RPCContext: func(ctx context.Context) context.Context {
+			calls++
+			return metadata.AppendToOutgoingContext(ctx, "token", fmt.Sprint(calls))
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := DialContext(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"1", "2"} {
+		resp, err := client.Capabilities(ctx, &pb.CapabilityRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GNMIVersion != expected {
+			t.Errorf("expected token %q, got %q", expected, resp.GNMIVersion)
+		}
+	}
+	stream, err := client.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Convert(err).Message() != "3" {
+		t.Errorf("expected token \"3\", got error %v", err)
+	}
+}
+
+type recordLogger struct {
+	logger.Logger
+	lines  []string
+	errors []string
+}
+
+func (l *recordLogger) Infof(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *recordLogger) Errorf(format string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestDialRPCLogger(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterGNMIServer(s, &metadataServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	rl := &recordLogger{}
+	cfg := &Config{
+		Addr:      l.Addr().String(),
+		Username:  "admin",
+		Password:  "hunter2",
+		RPCLogger: rl,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := DialContext(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = NewContext(ctx, cfg)
+	if _, err := client.Capabilities(ctx, &pb.CapabilityRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	setReq := &pb.SetRequest{
+		Prefix: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}}},
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "motd"}}},
+			Val: &pb.TypedValue{
+				Value: &pb.TypedValue_StringVal{StringVal: strings.Repeat("x", 100)}},
+		}},
+	}
+	if _, err := client.Set(ctx, setReq); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected an Unimplemented error, got %v", err)
+	}
+	stream, err := client.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{
+		Subscribe: &pb.SubscriptionList{
+			Subscription: []*pb.Subscription{{Path: &pb.Path{Elem: []*pb.PathElem{
+				{Name: "interfaces"}}}}},
+		}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected an Unavailable error, got %v", err)
+	}
+
+	expected := []string{
+		"RPC /gnmi.gNMI/Capabilities metadata=[password:<redacted> username:admin]: sent 0 bytes",
+		"RPC /gnmi.gNMI/Set metadata=[password:<redacted> username:admin] update=[/system/motd:" +
+			strings.Repeat("x", rpcLogValueLimit) + "...(100 bytes)]",
+		"RPC /gnmi.gNMI/Subscribe metadata=[password:<redacted> username:admin] started",
+		"RPC /gnmi.gNMI/Subscribe mode=STREAM paths=[/interfaces]: sent",
+		"RPC /gnmi.gNMI/Subscribe: received 0 responses, 0 bytes in",
+	}
+	if len(rl.lines) != len(expected) {
+		t.Fatalf("expected %d log lines, got %q", len(expected), rl.lines)
+	}
+	for i, line := range rl.lines {
+		if !strings.HasPrefix(line, expected[i]) {
+			t.Errorf("expected a log line starting with %q, got %q", expected[i], line)
+		}
+		if strings.Contains(line, cfg.Password) {
+			t.Errorf("password leaked in log line %q", line)
+		}
+	}
+}
+
+func TestRPCLogFlag(t *testing.T) {
+	cfg := &Config{}
+	v := rpcLogValue{cfg: cfg}
+	if err := v.Set("true"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RPCLogger == nil || v.String() != "true" {
+		t.Errorf("expected an RPC logger, got %v", cfg.RPCLogger)
+	}
+	if err := v.Set("false"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RPCLogger != nil || v.String() != "false" {
+		t.Errorf("unexpected RPC logger %v", cfg.RPCLogger)
+	}
+	if err := v.Set("yes please"); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+var _ backoff.Clock = RealClock
+
+func TestFakeClockAfter(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewFakeClock(start)
+	ch := c.After(time.Second)
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("After fired early")
+	default:
+	}
+	c.Advance(time.Millisecond)
+	select {
+	case got := <-ch:
+		if want := start.Add(time.Second); !got.Equal(want) {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	default:
+		t.Fatal("After did not fire")
+	}
+	if got := c.Since(start); got != time.Second {
+		t.Errorf("expected Since to return 1s, got %s", got)
+	}
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewFakeClock(start)
+	ticker := c.NewTicker(10 * time.Second)
+	for i := 1; i <= 3; i++ {
+		c.Advance(10 * time.Second)
+		select {
+		case got := <-ticker.C():
+			if want := start.Add(time.Duration(i) * 10 * time.Second); !got.Equal(want) {
+				t.Errorf("tick %d: expected %s, got %s", i, want, got)
+			}
+		default:
+			t.Fatalf("tick %d: ticker did not fire", i)
+		}
+	}
+	ticker.Stop()
+	c.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
+
+func TestFakeClockSleep(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Minute)
+		close(done)
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Sleep did not return")
+	}
+}
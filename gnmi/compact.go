@@ -0,0 +1,152 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"fmt"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+// CompactPolicy is how CompactSubscriptions treats the subscriptions whose
+// paths are covered by the path of another one.
+type CompactPolicy int
+
+const (
+	// CompactNone keeps all the subscriptions.
+	CompactNone CompactPolicy = iota
+	// CompactDuplicates drops the subscriptions identical to another one.
+	CompactDuplicates
+	// CompactSameMode drops the subscriptions covered by another one with
+	// the same mode and intervals. The covered subscriptions with a
+	// different mode are kept, the subscriptions being split per mode.
+	CompactSameMode
+	// CompactAll drops all the covered subscriptions, the mode of the
+	// subscription covering them then applying to their paths.
+	CompactAll
+)
+
+// ParseCompactPolicy parses a CompactPolicy from none, duplicates,
+// same_mode or all.
+func ParseCompactPolicy(s string) (CompactPolicy, error) {
+	switch s {
+	case "", "none":
+		return CompactNone, nil
+	case "duplicates":
+		return CompactDuplicates, nil
+	case "same_mode":
+		return CompactSameMode, nil
+	case "all":
+		return CompactAll, nil
+	}
+	return 0, fmt.Errorf("invalid compaction policy %q, expected none, duplicates, "+
+		"same_mode or all", s)
+}
+
+// CompactedSubscription is a subscription dropped by CompactSubscriptions.
+type CompactedSubscription struct {
+	Subscription *pb.Subscription
+	// CoveredBy is the kept subscription covering it.
+	CoveredBy *pb.Subscription
+}
+
+func (c *CompactedSubscription) String() string {
+	return fmt.Sprintf("%s covered by %s", pathWithOrigin(c.Subscription.GetPath()),
+		pathWithOrigin(c.CoveredBy.GetPath()))
+}
+
+func pathWithOrigin(p *pb.Path) string {
+	if p.GetOrigin() != "" {
+		return p.GetOrigin() + ":" + StrPath(p)
+	}
+	return StrPath(p)
+}
+
+// CompactSubscriptions drops the subscriptions of subs covered by another
+// one according to policy, e.g. /interfaces/interface/state is covered by
+// /interfaces, and returns the subscriptions kept, in their order, and
+// the ones dropped. Of identical subscriptions, the first one is kept.
+func CompactSubscriptions(subs []*pb.Subscription,
+	policy CompactPolicy) ([]*pb.Subscription, []*CompactedSubscription) {
+	if policy == CompactNone {
+		return subs, nil
+	}
+	dropped := make([]bool, len(subs))
+	for i, sub := range subs {
+		for j, other := range subs {
+			// Of subscriptions covering each other, the first one is kept.
+			if i != j && subscriptionCovers(other, sub, policy) &&
+				(j < i || !subscriptionCovers(sub, other, policy)) {
+				dropped[i] = true
+				break
+			}
+		}
+	}
+	var kept []*pb.Subscription
+	var compacted []*CompactedSubscription
+	for i, sub := range subs {
+		if !dropped[i] {
+			kept = append(kept, sub)
+			continue
+		}
+		// As covering is transitive, a kept subscription covers sub.
+		for j, other := range subs {
+			if !dropped[j] && subscriptionCovers(other, sub, policy) {
+				compacted = append(compacted,
+					&CompactedSubscription{Subscription: sub, CoveredBy: other})
+				break
+			}
+		}
+	}
+	return kept, compacted
+}
+
+func subscriptionCovers(a, b *pb.Subscription, policy CompactPolicy) bool {
+	sameMode := a.Mode == b.Mode && a.SampleInterval == b.SampleInterval &&
+		a.SuppressRedundant == b.SuppressRedundant &&
+		a.HeartbeatInterval == b.HeartbeatInterval
+	switch policy {
+	case CompactDuplicates:
+		return sameMode && proto.Equal(a.Path, b.Path)
+	case CompactSameMode:
+		return sameMode && PathCovers(a.Path, b.Path)
+	case CompactAll:
+		return PathCovers(a.Path, b.Path)
+	}
+	return false
+}
+
+// PathCovers returns whether the data at path b is included in the data
+// at path a: a and b have the same origin and target, and each element of
+// a matches the element of b at the same position, its name and keys
+// being either equal or wildcards, a missing key matching any value.
+func PathCovers(a, b *pb.Path) bool {
+	if a.GetOrigin() != b.GetOrigin() || a.GetTarget() != b.GetTarget() {
+		return false
+	}
+	a, b = upgradePath(a), upgradePath(b)
+	if len(a.GetElem()) > len(b.GetElem()) {
+		return false
+	}
+	for i, elem := range a.GetElem() {
+		if elem.Name == "..." {
+			return true
+		}
+		other := b.Elem[i]
+		if elem.Name != "*" && elem.Name != other.Name {
+			return false
+		}
+		for k, v := range elem.Key {
+			if v == "*" {
+				continue
+			}
+			if ov, ok := other.Key[k]; !ok || ov != v {
+				return false
+			}
+		}
+	}
+	return true
+}
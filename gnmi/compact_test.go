@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestPathCovers(t *testing.T) {
+	path := func(s string) *pb.Path {
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for _, tc := range []struct {
+		a, b   string
+		covers bool
+	}{
+		{a: "/", b: "/interfaces", covers: true},
+		{a: "/interfaces", b: "/interfaces", covers: true},
+		{a: "/interfaces", b: "/interfaces/interface/state", covers: true},
+		{a: "/interfaces/interface/state", b: "/interfaces"},
+		{a: "/interfaces", b: "/system"},
+		{a: "/interfaces/interface", b: "/interfaces/interface[name=Ethernet1]", covers: true},
+		{a: "/interfaces/interface[name=*]", b: "/interfaces/interface[name=Ethernet1]/state",
+			covers: true},
+		{a: "/interfaces/interface[name=Ethernet1]", b: "/interfaces/interface[name=*]"},
+		{a: "/interfaces/interface[name=Ethernet1]", b: "/interfaces/interface"},
+		{a: "/interfaces/interface[name=Ethernet1]", b: "/interfaces/interface[name=Ethernet2]"},
+		{a: "/interfaces/*/state", b: "/interfaces/interface/state/counters", covers: true},
+		{a: "/interfaces/.../counters", b: "/interfaces/interface/state", covers: true},
+	} {
+		if covers := PathCovers(path(tc.a), path(tc.b)); covers != tc.covers {
+			t.Errorf("PathCovers(%s, %s): expected %t, got %t", tc.a, tc.b, tc.covers, covers)
+		}
+	}
+	a, b := path("/interfaces"), path("/interfaces/interface")
+	b.Origin = "openconfig"
+	if PathCovers(a, b) {
+		t.Errorf("%s covers a path of another origin", a)
+	}
+}
+
+func TestCompactSubscriptions(t *testing.T) {
+	sub := func(p string, mode pb.SubscriptionMode) *pb.Subscription {
+		path, err := ParseGNMIElements(SplitPath(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pb.Subscription{Path: path, Mode: mode}
+	}
+	onChange, sample := pb.SubscriptionMode_ON_CHANGE, pb.SubscriptionMode_SAMPLE
+	subs := []*pb.Subscription{
+		sub("/interfaces/interface/state", onChange),
+		sub("/system", onChange),
+		sub("/interfaces", onChange),
+		sub("/interfaces/interface/state/counters", sample),
+		sub("/system", onChange),
+	}
+	for name, tc := range map[string]struct {
+		policy    CompactPolicy
+		kept      []string
+		compacted []string
+	}{
+		"none": {
+			policy: CompactNone,
+			kept: []string{"/interfaces/interface/state", "/system", "/interfaces",
+				"/interfaces/interface/state/counters", "/system"},
+		},
+		"duplicates": {
+			policy: CompactDuplicates,
+			kept: []string{"/interfaces/interface/state", "/system", "/interfaces",
+				"/interfaces/interface/state/counters"},
+			compacted: []string{"/system covered by /system"},
+		},
+		"same mode": {
+			policy: CompactSameMode,
+			kept:   []string{"/system", "/interfaces", "/interfaces/interface/state/counters"},
+			compacted: []string{"/interfaces/interface/state covered by /interfaces",
+				"/system covered by /system"},
+		},
+		"all": {
+			policy: CompactAll,
+			kept:   []string{"/system", "/interfaces"},
+			compacted: []string{"/interfaces/interface/state covered by /interfaces",
+				"/interfaces/interface/state/counters covered by /interfaces",
+				"/system covered by /system"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			kept, compacted := CompactSubscriptions(subs, tc.policy)
+			var keptPaths, compactedStrs []string
+			for _, s := range kept {
+				keptPaths = append(keptPaths, StrPath(s.Path))
+			}
+			for _, c := range compacted {
+				compactedStrs = append(compactedStrs, c.String())
+			}
+			if !test.DeepEqual(tc.kept, keptPaths) {
+				t.Errorf("expected to keep %q, got %q", tc.kept, keptPaths)
+			}
+			if !test.DeepEqual(tc.compacted, compactedStrs) {
+				t.Errorf("expected to drop %q, got %q", tc.compacted, compactedStrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aristanetworks/goarista/logger"
+	"github.com/klauspost/compress/zstd"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ZstdName is the name of the zstd gRPC compressor, registered by
+	// this package.
+	ZstdName = "zstd"
+	// CompressionAuto is the Config.Compression value picking the one
+	// of gzip and zstd the fastest on the first Get and Subscribe
+	// responses.
+	CompressionAuto = "auto"
+)
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+}
+
+// zstdCompressor implements encoding.Compressor with pooled encoders
+// and decoders, like the gzip compressor of grpc.
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	defer w.pool.Put(w)
+	return w.Encoder.Close()
+}
+
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	n, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r)
+	}
+	return n, err
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if zw, ok := c.encoders.Get().(*zstdWriter); ok {
+		zw.Encoder.Reset(w)
+		return zw, nil
+	}
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if zr, ok := c.decoders.Get().(*zstdReader); ok {
+		if err := zr.Decoder.Reset(r); err != nil {
+			c.decoders.Put(zr)
+			return nil, err
+		}
+		return zr, nil
+	}
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{Decoder: dec, pool: &c.decoders}, nil
+}
+
+func (c *zstdCompressor) Name() string {
+	return ZstdName
+}
+
+const (
+	// compressionProbeTimeout bounds the time spent checking the
+	// compressors supported by the target.
+	compressionProbeTimeout = time.Minute
+	// compressionSampleSize is the size of the Get and Subscribe
+	// responses the compressors are benchmarked on.
+	compressionSampleSize = 1 << 20
+	// compressionSampleCount is the number of responses the compressors
+	// are benchmarked on if they add up to less than
+	// compressionSampleSize.
+	compressionSampleCount = 1000
+)
+
+// autoCompressor compresses the RPCs of a connection with the one of gzip
+// and zstd supported by the target compressing and decompressing the
+// first Get and Subscribe responses the fastest. The RPCs started before
+// it is picked are not compressed.
+type autoCompressor struct {
+	addr   string
+	logger logger.Logger
+	name   atomic.Value // string
+	// sampling is false once the sample of responses is complete.
+	sampling atomic.Bool
+
+	mu sync.Mutex
+	// supported are the compressors supported by the target, once probed.
+	supported  []string
+	probed     bool
+	sample     [][]byte
+	sampleSize int
+}
+
+func newAutoCompressor(addr string, l logger.Logger) *autoCompressor {
+	a := &autoCompressor{addr: addr, logger: l}
+	a.sampling.Store(true)
+	return a
+}
+
+// probe checks the compressors supported by the target of conn with a
+// Capabilities RPC compressed with each.
+func (a *autoCompressor) probe(ctx context.Context, conn *grpc.ClientConn) {
+	client := pb.NewGNMIClient(conn)
+	var supported []string
+	_, err := client.Capabilities(ctx, &pb.CapabilityRequest{}, grpc.WaitForReady(true),
+		grpc.UseCompressor("identity"))
+	for _, name := range []string{gzip.Name, ZstdName} {
+		if err != nil {
+			break
+		}
+		_, err = client.Capabilities(ctx, &pb.CapabilityRequest{}, grpc.UseCompressor(name))
+		if err == nil {
+			supported = append(supported, name)
+		} else if status.Code(err) == codes.Unimplemented {
+			// The target does not support the compressor.
+			err = nil
+		}
+	}
+	switch {
+	case err != nil:
+		a.logger.Errorf("failed to probe the compressors supported by %s, "+
+			"not compressing: %s", a.addr, err)
+	case len(supported) == 0:
+		a.logger.Infof("%s supports neither gzip nor zstd, not compressing", a.addr)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.supported = supported
+	a.probed = true
+	if len(supported) == 0 {
+		a.sampling.Store(false)
+		a.sample = nil
+		return
+	}
+	a.pickLocked()
+}
+
+// record adds the response m to the sample, picking the compressor once
+// the sample is complete.
+func (a *autoCompressor) record(m interface{}) {
+	if !a.sampling.Load() {
+		return
+	}
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.sampling.Load() {
+		return
+	}
+	a.sample = append(a.sample, b)
+	a.sampleSize += len(b)
+	a.pickLocked()
+}
+
+// pickLocked picks the supported compressor compressing and decompressing
+// the sample the fastest, once the target is probed and the sample is
+// complete. Must be called with a.mu held.
+func (a *autoCompressor) pickLocked() {
+	if !a.probed ||
+		(a.sampleSize < compressionSampleSize && len(a.sample) < compressionSampleCount) {
+		return
+	}
+	a.sampling.Store(false)
+	var best string
+	var bestTime time.Duration
+	for _, name := range a.supported {
+		d, err := benchmarkCompressor(name, a.sample)
+		if err != nil {
+			a.logger.Errorf("failed to benchmark %s: %s", name, err)
+			continue
+		}
+		if best == "" || d < bestTime {
+			best, bestTime = name, d
+		}
+	}
+	if best != "" {
+		a.logger.Infof("compressing the RPCs to %s with %s, the fastest on %d bytes "+
+			"of responses (%s)", a.addr, best, a.sampleSize, bestTime)
+		a.name.Store(best)
+	}
+	a.sample = nil
+}
+
+// benchmarkCompressor returns the time the registered compressor name
+// takes to compress and decompress the messages of sample.
+func benchmarkCompressor(name string, sample [][]byte) (time.Duration, error) {
+	c := encoding.GetCompressor(name)
+	start := time.Now()
+	var buf bytes.Buffer
+	for _, b := range sample {
+		buf.Reset()
+		w, err := c.Compress(&buf)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(b); err != nil {
+			return 0, err
+		}
+		if err := w.Close(); err != nil {
+			return 0, err
+		}
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return 0, err
+		}
+	}
+	return time.Since(start), nil
+}
+
+func (a *autoCompressor) callOptions(opts []grpc.CallOption) []grpc.CallOption {
+	if name, _ := a.name.Load().(string); name != "" {
+		// The options of the call come last to override the compressor.
+		return append([]grpc.CallOption{grpc.UseCompressor(name)}, opts...)
+	}
+	return opts
+}
+
+func (a *autoCompressor) unaryInterceptor(ctx context.Context, method string,
+	req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, a.callOptions(opts)...)
+	if err == nil && method == "/gnmi.gNMI/Get" {
+		a.record(reply)
+	}
+	return err
+}
+
+func (a *autoCompressor) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc,
+	cc *grpc.ClientConn, method string, streamer grpc.Streamer,
+	opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, a.callOptions(opts)...)
+	if err != nil || method != "/gnmi.gNMI/Subscribe" || !a.sampling.Load() {
+		return stream, err
+	}
+	return &sampledStream{ClientStream: stream, auto: a}, nil
+}
+
+// sampledStream records the responses of a stream in the sample of an
+// autoCompressor.
+type sampledStream struct {
+	grpc.ClientStream
+	auto *autoCompressor
+}
+
+func (s *sampledStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.auto.record(m)
+	}
+	return err
+}
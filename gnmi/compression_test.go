@@ -0,0 +1,255 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+)
+
+func TestZstdCompressor(t *testing.T) {
+	c := encoding.GetCompressor(ZstdName)
+	if c == nil {
+		t.Fatal("zstd compressor not registered")
+	}
+	data := []byte(strings.Repeat("interfaces/interface[name=Ethernet1]/state/counters ", 100))
+	// Twice, to reuse the pooled encoder and decoder.
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() >= len(data) {
+			t.Errorf("expected less than %d compressed bytes, got %d", len(data), buf.Len())
+		}
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("expected %q, got %q", data, got)
+		}
+	}
+}
+
+type capabilitiesServer struct {
+	pb.UnimplementedGNMIServer
+}
+
+func (*capabilitiesServer) Capabilities(ctx context.Context,
+	req *pb.CapabilityRequest) (*pb.CapabilityResponse, error) {
+	return &pb.CapabilityResponse{GNMIVersion: "0.10.0",
+		SupportedEncodings: []pb.Encoding{pb.Encoding_JSON, pb.Encoding_JSON_IETF}}, nil
+}
+
+func (*capabilitiesServer) Get(ctx context.Context,
+	req *pb.GetRequest) (*pb.GetResponse, error) {
+	return &pb.GetResponse{Notification: []*pb.Notification{{
+		Timestamp: 1,
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "state"}}},
+			Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+				JsonIetfVal: []byte(`{"hostname":"switch1","domain-name":"example.com"}`)}},
+		}},
+	}}}, nil
+}
+
+func TestDialCompression(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterGNMIServer(s, &capabilitiesServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	for _, compression := range []string{"gzip", ZstdName, CompressionAuto} {
+		t.Run(compression, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			conn, err := DialContextConn(ctx,
+				&Config{Addr: l.Addr().String(), Compression: compression})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			resp, err := pb.NewGNMIClient(conn).Capabilities(ctx, &pb.CapabilityRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.GNMIVersion != "0.10.0" {
+				t.Errorf("unexpected response: %s", resp)
+			}
+		})
+	}
+}
+
+func TestAutoCompressor(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterGNMIServer(s, &capabilitiesServer{})
+	go s.Serve(l)
+	defer s.Stop()
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rl := &recordLogger{}
+	a := newAutoCompressor(l.Addr().String(), rl)
+	a.probe(ctx, conn)
+	if name, _ := a.name.Load().(string); name != "" {
+		t.Fatalf("expected no compressor before the sample is complete, got %q", name)
+	}
+	resp, err := (&capabilitiesServer{}).Get(ctx, &pb.GetRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < compressionSampleCount; i++ {
+		a.record(resp)
+	}
+	if name, _ := a.name.Load().(string); name != "gzip" && name != ZstdName {
+		t.Errorf("expected gzip or zstd to be picked, got %q", name)
+	}
+	if a.sampling.Load() || a.sample != nil {
+		t.Error("expected the sampling to stop once the compressor is picked")
+	}
+	if len(rl.lines) != 1 || !strings.Contains(rl.lines[0], "the fastest on") {
+		t.Errorf("expected the pick to be logged, got %q", rl.lines)
+	}
+
+	// The probe of a closed connection fails and stops the sampling.
+	closed, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+	rl = &recordLogger{}
+	a = newAutoCompressor(l.Addr().String(), rl)
+	a.probe(ctx, closed)
+	if a.sampling.Load() || len(rl.errors) != 1 {
+		t.Errorf("expected the probe to fail, got %q", rl.errors)
+	}
+}
+
+// compressionStats records the compressor of each RPC received.
+type compressionStats struct {
+	m         sync.Mutex
+	encodings []string
+}
+
+func (h *compressionStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *compressionStats) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if in, ok := s.(*stats.InHeader); ok {
+		h.m.Lock()
+		h.encodings = append(h.encodings, in.Compression)
+		h.m.Unlock()
+	}
+}
+
+func (h *compressionStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *compressionStats) HandleConn(context.Context, stats.ConnStats) {}
+
+func (h *compressionStats) last() string {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.encodings[len(h.encodings)-1]
+}
+
+func TestDialCompressionAuto(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &compressionStats{}
+	s := grpc.NewServer(grpc.StatsHandler(h))
+	pb.RegisterGNMIServer(s, &capabilitiesServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := DialContextConn(ctx,
+		&Config{Addr: l.Addr().String(), Compression: CompressionAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGNMIClient(conn)
+	// The Get responses make the sample the compressors are benchmarked
+	// on, in parallel with the probe.
+	for i := 0; i < compressionSampleCount; i++ {
+		if _, err := client.Get(ctx, &pb.GetRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for {
+		if _, err := client.Get(ctx, &pb.GetRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		if e := h.last(); e == "gzip" || e == ZstdName {
+			break
+		} else if e != "" {
+			t.Fatalf("unexpected compressor %q", e)
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("no compressor picked")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	// The compressor of a call overrides the one picked.
+	if _, err := client.Capabilities(ctx, &pb.CapabilityRequest{},
+		grpc.UseCompressor("identity")); err != nil {
+		t.Fatal(err)
+	}
+	if e := h.last(); e != "identity" {
+		t.Errorf("expected the call not to be compressed, got %q", e)
+	}
+	h.m.Lock()
+	defer h.m.Unlock()
+	probes := map[string]bool{}
+	for _, e := range h.encodings {
+		probes[e] = true
+	}
+	if !probes["gzip"] || !probes[ZstdName] {
+		t.Errorf("expected the probes of gzip and zstd, got %q", h.encodings)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFileFlag is the name of the flag of the commands reading a
+// ConfigFile.
+const ConfigFileFlag = "gnmi_config"
+
+// ConfigFile is a config file shared by the gNMI commands, in YAML or JSON:
+//
+//	addrs: [switch1:6030, switch2:6030]
+//	tls: true
+//	cafile: /etc/gnmi/ca.pem
+//	username: admin
+//	password_file: /etc/gnmi/password
+//	subscriptions: [/interfaces/interface/state/counters]
+//	commands:
+//	  gnmi:
+//	    timeout: 30s
+//	  ockafka:
+//	    kafkaaddrs: kafka1:9092,kafka2:9092
+//
+// Its settings are applied to the flags of a command with ApplyFlags.
+type ConfigFile struct {
+	Addrs         []string          `yaml:"addrs"`
+	TLS           bool              `yaml:"tls"`
+	CAFile        string            `yaml:"cafile"`
+	CertFile      string            `yaml:"certfile"`
+	KeyFile       string            `yaml:"keyfile"`
+	TLSMinVersion string            `yaml:"tls-min-version"`
+	TLSMaxVersion string            `yaml:"tls-max-version"`
+	Username      string            `yaml:"username"`
+	Password      string            `yaml:"password"`
+	PasswordFile  string            `yaml:"password_file"`
+	Token         string            `yaml:"token"`
+	TokenFile     string            `yaml:"token_file"`
+	Compression   string            `yaml:"compression"`
+	Proxy         string            `yaml:"proxy"`
+	GRPCMetadata  map[string]string `yaml:"grpcmetadata"`
+	Subscriptions []string          `yaml:"subscriptions"`
+	// Commands are the values of the flags of each command, by command
+	// name. A list sets a flag repeatedly.
+	Commands map[string]map[string]interface{} `yaml:"commands"`
+}
+
+// LoadConfig reads the ConfigFile at path. Unknown settings are rejected.
+func LoadConfig(path string) (*ConfigFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &ConfigFile{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %s", path, err)
+	}
+	return c, nil
+}
+
+// ApplyFlags sets the flags of fs that were not set on the command line to
+// the values of c, so that the flags override the config file. The common
+// settings are applied to the flags of the same name that fs defines, the
+// addresses to -addr or -addrs and the subscriptions to -subscribe, then
+// the section of command is applied, where unknown flags are an error.
+func (c *ConfigFile) ApplyFlags(fs *flag.FlagSet, command string) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	apply := func(name string, values ...string) error {
+		if set[name] || fs.Lookup(name) == nil {
+			return nil
+		}
+		for _, v := range values {
+			if err := fs.Set(name, v); err != nil {
+				return fmt.Errorf("invalid value %q for flag -%s: %s", v, name, err)
+			}
+		}
+		return nil
+	}
+
+	var metadata []string
+	for k, v := range c.GRPCMetadata {
+		metadata = append(metadata, k+"="+v)
+	}
+	sort.Strings(metadata)
+	common := []struct {
+		name   string
+		values []string
+	}{
+		{"addr", []string{strings.Join(c.Addrs, ",")}},
+		{"addrs", []string{strings.Join(c.Addrs, ",")}},
+		{"tls", []string{fmt.Sprint(c.TLS)}},
+		{"cafile", []string{c.CAFile}},
+		{"certfile", []string{c.CertFile}},
+		{"keyfile", []string{c.KeyFile}},
+		{"tls-min-version", []string{c.TLSMinVersion}},
+		{"tls-max-version", []string{c.TLSMaxVersion}},
+		{"username", []string{c.Username}},
+		{"password", []string{c.Password}},
+		{"password_file", []string{c.PasswordFile}},
+		{"token", []string{c.Token}},
+		{"token_file", []string{c.TokenFile}},
+		{"compression", []string{c.Compression}},
+		{"proxy", []string{c.Proxy}},
+		{"grpcmetadata", metadata},
+		{"subscribe", []string{strings.Join(c.Subscriptions, ",")}},
+	}
+	for _, s := range common {
+		// Only apply the settings present in the file.
+		if len(s.values) == 0 || s.values[0] == "" || s.values[0] == "false" {
+			continue
+		}
+		if err := apply(s.name, s.values...); err != nil {
+			return err
+		}
+	}
+
+	section := c.Commands[command]
+	names := make([]string, 0, len(section))
+	for name := range section {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown flag -%s in the %s section of the config file",
+				name, command)
+		}
+		var values []string
+		if list, ok := section[name].([]interface{}); ok {
+			for _, v := range list {
+				values = append(values, fmt.Sprint(v))
+			}
+		} else {
+			values = []string{fmt.Sprint(section[name])}
+		}
+		if err := apply(name, values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyConfigFile loads the config file set with the ConfigFileFlag flag,
+// if any, and applies it to the flags of the command line of command. It
+// must be called after flag.Parse.
+func ApplyConfigFile(command string) error {
+	f := flag.Lookup(ConfigFileFlag)
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	c, err := LoadConfig(f.Value.String())
+	if err != nil {
+		return err
+	}
+	return c.ApplyFlags(flag.CommandLine, command)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	aflag "github.com/aristanetworks/goarista/flag"
+	"github.com/aristanetworks/goarista/test"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigFileApplyFlags(t *testing.T) {
+	yamlPath := writeConfigFile(t, "gnmi.yaml", `
+addrs: [switch1:6030, switch2:6030]
+tls: true
+username: admin
+password: secret
+grpcmetadata:
+  b: "2"
+  a: "1"
+subscriptions: [/interfaces, /system]
+commands:
+  test:
+    timeout: 30s
+    path: [/a, /b]
+  other:
+    unknown: 1
+`)
+	jsonPath := writeConfigFile(t, "gnmi.json", `{
+  "addrs": ["switch1:6030", "switch2:6030"],
+  "tls": true,
+  "username": "admin",
+  "password": "secret",
+  "grpcmetadata": {"a": "1", "b": "2"},
+  "subscriptions": ["/interfaces", "/system"],
+  "commands": {"test": {"timeout": "30s", "path": ["/a", "/b"]}}
+}`)
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			c, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			cfg := &Config{}
+			fs.StringVar(&cfg.Addr, "addrs", "", "")
+			fs.BoolVar(&cfg.TLS, "tls", false, "")
+			fs.StringVar(&cfg.Username, "username", "", "")
+			fs.StringVar(&cfg.Password, "password", "", "")
+			metadata := aflag.Map{}
+			fs.Var(metadata, "grpcmetadata", "")
+			subscribe := fs.String("subscribe", "/", "")
+			timeout := fs.Duration("timeout", 0, "")
+			var paths aflag.StringArrayOption
+			fs.Var(&paths, "path", "")
+			if err := fs.Parse([]string{"-username", "ops"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.ApplyFlags(fs, "test"); err != nil {
+				t.Fatal(err)
+			}
+			expected := &Config{Addr: "switch1:6030,switch2:6030", TLS: true,
+				Username: "ops", Password: "secret"}
+			if !test.DeepEqual(expected, cfg) {
+				t.Errorf("unexpected config: %s", test.Diff(expected, cfg))
+			}
+			if !test.DeepEqual(aflag.Map{"a": "1", "b": "2"}, metadata) {
+				t.Errorf("unexpected metadata: %v", metadata)
+			}
+			if *subscribe != "/interfaces,/system" {
+				t.Errorf("unexpected subscriptions %q", *subscribe)
+			}
+			if *timeout != 30*time.Second {
+				t.Errorf("unexpected timeout %s", *timeout)
+			}
+			if !test.DeepEqual(aflag.StringArrayOption{"/a", "/b"}, paths) {
+				t.Errorf("unexpected paths %q", paths)
+			}
+		})
+	}
+
+	c, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.ApplyFlags(flag.NewFlagSet("other", flag.ContinueOnError), "other")
+	if err == nil || !strings.Contains(err.Error(), "unknown flag -unknown") {
+		t.Errorf("expected an unknown flag error, got %v", err)
+	}
+	if _, err := LoadConfig(writeConfigFile(t, "bad.yaml", "adrs: [switch1]")); err == nil {
+		t.Error("expected an error for an unknown setting")
+	}
+}
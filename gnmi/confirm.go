@@ -0,0 +1,272 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConfirmedSetOptions configures SetConfirmed.
+type ConfirmedSetOptions struct {
+	// Timeout is the time given to confirm the Set before it is rolled
+	// back.
+	Timeout time.Duration
+	// Extensions are added to the SetRequest and to its rollback.
+	Extensions []*gnmi_ext.Extension
+	// Clock defaults to RealClock.
+	Clock Clock
+}
+
+// ConfirmedSetState is the state of a ConfirmedSet.
+type ConfirmedSetState int
+
+const (
+	// SetStatePending is the state of a Set waiting for confirmation.
+	SetStatePending ConfirmedSetState = iota
+	// SetStateConfirmed is the state of a confirmed Set, which is kept.
+	SetStateConfirmed
+	// SetStateRolledBack is the state of a Set that was rolled back, or
+	// whose rollback failed.
+	SetStateRolledBack
+)
+
+func (s ConfirmedSetState) String() string {
+	switch s {
+	case SetStatePending:
+		return "pending"
+	case SetStateConfirmed:
+		return "confirmed"
+	case SetStateRolledBack:
+		return "rolled back"
+	default:
+		return fmt.Sprintf("ConfirmedSetState(%d)", int(s))
+	}
+}
+
+// ConfirmedSet is a Set sent by SetConfirmed, rolled back unless it is
+// confirmed in time.
+type ConfirmedSet struct {
+	client   pb.GNMIClient
+	ctx      context.Context
+	rollback []*pb.SetRequest
+	done     chan struct{}
+
+	mu    sync.Mutex
+	state ConfirmedSetState
+	err   error
+}
+
+// SetConfirmed implements the commit-confirmed pattern: it fetches the
+// current config values of the paths of setOps with Get, sends setOps,
+// and rolls them back by setting the fetched values again unless Confirm
+// is called within opts.Timeout. Paths that did not exist are deleted by
+// the rollback. Only the operations of the JSON origins can be rolled
+// back. The rollback sends one SetRequest per target of setOps.
+//
+// The rollback is sent with the values of ctx, such as the gRPC metadata,
+// even if ctx is canceled.
+func SetConfirmed(ctx context.Context, client pb.GNMIClient, setOps []*Operation,
+	opts ConfirmedSetOptions) (*ConfirmedSet, error) {
+	rollback, err := newRollbackRequests(ctx, client, setOps, opts.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	if err := Set(ctx, client, setOps, opts.Extensions...); err != nil {
+		return nil, err
+	}
+	clock := opts.Clock
+	if clock == nil {
+		clock = RealClock
+	}
+	s := &ConfirmedSet{
+		client:   client,
+		ctx:      context.WithoutCancel(ctx),
+		rollback: rollback,
+		done:     make(chan struct{}),
+	}
+	timeout := clock.After(opts.Timeout)
+	go func() {
+		select {
+		case <-s.done:
+		case <-timeout:
+			s.Rollback()
+		}
+	}()
+	return s, nil
+}
+
+// Confirm keeps the Set. It returns an error if the Set was already
+// rolled back.
+func (s *ConfirmedSet) Confirm() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.state {
+	case SetStateConfirmed:
+		return nil
+	case SetStateRolledBack:
+		return errors.New("set was already rolled back")
+	}
+	s.state = SetStateConfirmed
+	close(s.done)
+	return nil
+}
+
+// Rollback rolls the Set back now, if it is still pending, and returns
+// the error of the rollback. If the Set is being rolled back, it waits for
+// the rollback to complete.
+func (s *ConfirmedSet) Rollback() error {
+	s.mu.Lock()
+	switch s.state {
+	case SetStateConfirmed:
+		s.mu.Unlock()
+		return errors.New("set was already confirmed")
+	case SetStateRolledBack:
+		s.mu.Unlock()
+		<-s.done
+		_, err := s.State()
+		return err
+	}
+	s.state = SetStateRolledBack
+	s.mu.Unlock()
+
+	var errs []error
+	for _, req := range s.rollback {
+		resp, err := s.client.Set(s.ctx, req)
+		if err == nil && resp.Message != nil && codes.Code(resp.Message.Code) != codes.OK {
+			err = errors.New(resp.Message.Message)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to roll back set of target %q: %s",
+				req.GetPrefix().GetTarget(), err))
+		}
+	}
+	s.mu.Lock()
+	s.err = errors.Join(errs...)
+	s.mu.Unlock()
+	close(s.done)
+	return s.err
+}
+
+// Done returns a channel closed when the Set is confirmed or rolled back.
+func (s *ConfirmedSet) Done() <-chan struct{} {
+	return s.done
+}
+
+// State returns the state of the Set and the error of its rollback, if
+// any.
+func (s *ConfirmedSet) State() (ConfirmedSetState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, s.err
+}
+
+// RollbackRequests returns the SetRequests restoring the values fetched
+// before the Set, one per target in the order of the operations.
+func (s *ConfirmedSet) RollbackRequests() []*pb.SetRequest {
+	return s.rollback
+}
+
+// newRollbackRequests fetches the current config value of each path of
+// setOps and returns the SetRequests restoring them, one per target.
+func newRollbackRequests(ctx context.Context, client pb.GNMIClient, setOps []*Operation,
+	exts []*gnmi_ext.Extension) ([]*pb.SetRequest, error) {
+	var reqs []*pb.SetRequest
+	byTarget := map[string]*pb.SetRequest{}
+	seen := map[string]bool{}
+	for _, op := range setOps {
+		p, err := ParseGNMIElements(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		p.Origin = op.Origin
+		req, ok := byTarget[op.Target]
+		if !ok {
+			req = &pb.SetRequest{Extension: exts}
+			if op.Target != "" {
+				req.Prefix = &pb.Path{Target: op.Target}
+			}
+			byTarget[op.Target] = req
+			reqs = append(reqs, req)
+		}
+		key := op.Target + "|" + op.Origin + ":" + StrPath(p)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		val, err := getConfigValue(ctx, client, op.Target, p)
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			req.Delete = append(req.Delete, p)
+		} else {
+			req.Replace = append(req.Replace, &pb.Update{Path: p, Val: val})
+		}
+	}
+	return reqs, nil
+}
+
+// getConfigValue returns the config value at p, or nil if p does not
+// exist. It fails if Get does not return a single update at p.
+func getConfigValue(ctx context.Context, client pb.GNMIClient, target string,
+	p *pb.Path) (*pb.TypedValue, error) {
+	var encoding pb.Encoding
+	switch p.Origin {
+	case "", "openconfig":
+		encoding = pb.Encoding_JSON_IETF
+	case "eos_native":
+		encoding = pb.Encoding_JSON
+	default:
+		return nil, fmt.Errorf("cannot roll back operations of origin %q", p.Origin)
+	}
+	req := &pb.GetRequest{
+		Path:     []*pb.Path{p},
+		Type:     pb.GetRequest_CONFIG,
+		Encoding: encoding,
+	}
+	if target != "" {
+		req.Prefix = &pb.Path{Target: target}
+	}
+	resp, err := client.Get(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get the value of %s to roll back: %s",
+			StrPath(p), err)
+	}
+	var updates []*pb.Update
+	var paths []*pb.Path
+	for _, notif := range resp.Notification {
+		for _, u := range notif.Update {
+			updates = append(updates, u)
+			paths = append(paths, JoinPaths(notif.Prefix, u.Path))
+		}
+	}
+	switch len(updates) {
+	case 0:
+		return nil, nil
+	case 1:
+		// The value of a child of p is not the value of p, restoring it at
+		// p would replace the whole subtree with it.
+		if strP, strU := StrPath(p), StrPath(paths[0]); strU != strP {
+			return nil, fmt.Errorf("cannot roll back %s: Get returned the value of %s",
+				strP, strU)
+		}
+		return proto.Clone(updates[0].Val).(*pb.TypedValue), nil
+	default:
+		return nil, fmt.Errorf("cannot roll back %s: Get returned %d updates instead of one",
+			StrPath(p), len(updates))
+	}
+}
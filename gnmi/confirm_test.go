@@ -0,0 +1,200 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// configClient is a pb.GNMIClient holding the config values of /a and /b,
+// returning the value of /d/e for /d, and recording the SetRequests.
+type configClient struct {
+	pb.GNMIClient
+	mu   sync.Mutex
+	sets []*pb.SetRequest
+}
+
+func (c *configClient) Get(ctx context.Context, req *pb.GetRequest,
+	opts ...grpc.CallOption) (*pb.GetResponse, error) {
+	p := req.Path[0]
+	var val string
+	switch StrPath(p) {
+	case "/a":
+		val = `{"x":1}`
+	case "/b":
+		val = `"b"`
+	case "/d":
+		return &pb.GetResponse{Notification: []*pb.Notification{{
+			Prefix: &pb.Path{Elem: []*pb.PathElem{{Name: "d"}}},
+			Update: []*pb.Update{{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "e"}}},
+				Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+					JsonIetfVal: []byte("1")}}}},
+		}}}, nil
+	default:
+		return nil, status.Errorf(codes.NotFound, "%s not found", StrPath(p))
+	}
+	return &pb.GetResponse{Notification: []*pb.Notification{{
+		Update: []*pb.Update{{Path: p, Val: &pb.TypedValue{
+			Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(val)}}}},
+	}}}, nil
+}
+
+func (c *configClient) Set(ctx context.Context, req *pb.SetRequest,
+	opts ...grpc.CallOption) (*pb.SetResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sets = append(c.sets, req)
+	return &pb.SetResponse{}, nil
+}
+
+func (c *configClient) setCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sets)
+}
+
+func TestSetConfirmed(t *testing.T) {
+	ops := []*Operation{
+		{Type: "update", Path: []string{"a"}, Val: `{"x":2}`},
+		{Type: "delete", Path: []string{"b"}},
+		{Type: "replace", Path: []string{"c"}, Val: "3"},
+		{Type: "update", Path: []string{"a"}, Val: `{"y":2}`},
+	}
+	expected := &pb.SetRequest{
+		Delete: []*pb.Path{{Element: []string{"c"}, Elem: []*pb.PathElem{{Name: "c"}}}},
+		Replace: []*pb.Update{{
+			Path: &pb.Path{Element: []string{"a"}, Elem: []*pb.PathElem{{Name: "a"}}},
+			Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+				JsonIetfVal: []byte(`{"x":1}`)}},
+		}, {
+			Path: &pb.Path{Element: []string{"b"}, Elem: []*pb.PathElem{{Name: "b"}}},
+			Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+				JsonIetfVal: []byte(`"b"`)}},
+		}},
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		client := &configClient{}
+		clock := NewFakeClock(time.Unix(0, 0))
+		s, err := SetConfirmed(context.Background(), client, ops,
+			ConfirmedSetOptions{Timeout: time.Minute, Clock: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reqs := s.RollbackRequests(); !test.DeepEqual([]*pb.SetRequest{expected}, reqs) {
+			t.Errorf("unexpected rollback requests: %s",
+				test.Diff([]*pb.SetRequest{expected}, reqs))
+		}
+		clock.Advance(time.Minute)
+		<-s.Done()
+		if state, err := s.State(); state != SetStateRolledBack || err != nil {
+			t.Errorf("expected state %s, got %s (%v)", SetStateRolledBack, state, err)
+		}
+		if n := client.setCount(); n != 2 {
+			t.Fatalf("expected the Set and its rollback, got %d SetRequests", n)
+		}
+		if client.sets[1] != s.RollbackRequests()[0] {
+			t.Errorf("expected the rollback request, got %s", client.sets[1])
+		}
+		if err := s.Confirm(); err == nil {
+			t.Error("expected an error confirming a rolled back Set")
+		}
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		client := &configClient{}
+		clock := NewFakeClock(time.Unix(0, 0))
+		s, err := SetConfirmed(context.Background(), client, ops,
+			ConfirmedSetOptions{Timeout: time.Minute, Clock: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Confirm(); err != nil {
+			t.Fatal(err)
+		}
+		<-s.Done()
+		clock.Advance(time.Minute)
+		if state, _ := s.State(); state != SetStateConfirmed {
+			t.Errorf("expected state %s, got %s", SetStateConfirmed, state)
+		}
+		if err := s.Rollback(); err == nil {
+			t.Error("expected an error rolling back a confirmed Set")
+		}
+		if n := client.setCount(); n != 1 {
+			t.Errorf("expected only the Set, got %d SetRequests", n)
+		}
+	})
+
+	t.Run("targets", func(t *testing.T) {
+		client := &configClient{}
+		clock := NewFakeClock(time.Unix(0, 0))
+		s, err := SetConfirmed(context.Background(), client, []*Operation{
+			{Type: "delete", Target: "dut1", Path: []string{"b"}},
+			{Type: "delete", Target: "dut2", Path: []string{"b"}},
+			{Type: "replace", Target: "dut1", Path: []string{"c"}, Val: "3"},
+		}, ConfirmedSetOptions{Timeout: time.Minute, Clock: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reqs := s.RollbackRequests()
+		if len(reqs) != 2 {
+			t.Fatalf("expected a rollback request per target, got %v", reqs)
+		}
+		for i, target := range []string{"dut1", "dut2"} {
+			if reqs[i].GetPrefix().GetTarget() != target {
+				t.Errorf("expected the rollback request of %s, got %s", target, reqs[i])
+			}
+		}
+		if len(reqs[0].Replace) != 1 || len(reqs[0].Delete) != 1 ||
+			len(reqs[1].Replace) != 1 || len(reqs[1].Delete) != 0 {
+			t.Errorf("unexpected rollback requests: %v", reqs)
+		}
+		if err := s.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+		if n := client.setCount(); n != 3 {
+			t.Errorf("expected the Set and 2 rollbacks, got %d SetRequests", n)
+		}
+		// Rolling back again returns the error of the rollback.
+		if err := s.Rollback(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unsupported origin", func(t *testing.T) {
+		client := &configClient{}
+		_, err := SetConfirmed(context.Background(), client,
+			[]*Operation{{Type: "update", Origin: "cli", Val: "hostname x"}},
+			ConfirmedSetOptions{Timeout: time.Minute})
+		if err == nil {
+			t.Fatal("expected an error for the cli origin")
+		}
+		if n := client.setCount(); n != 0 {
+			t.Errorf("expected no SetRequest, got %d", n)
+		}
+	})
+
+	t.Run("value of a child", func(t *testing.T) {
+		client := &configClient{}
+		_, err := SetConfirmed(context.Background(), client,
+			[]*Operation{{Type: "update", Path: []string{"d"}, Val: `{"e":2}`}},
+			ConfirmedSetOptions{Timeout: time.Minute})
+		if err == nil {
+			t.Fatal("expected an error for the value of /d/e returned for /d")
+		}
+		if n := client.setCount(); n != 0 {
+			t.Errorf("expected no SetRequest, got %d", n)
+		}
+	})
+}
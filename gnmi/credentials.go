@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// The environment variables LoadCredentials falls back to.
+const (
+	PasswordEnv = "GNMI_PASSWORD"
+	TokenEnv    = "GNMI_TOKEN"
+)
+
+// promptPassword prints prompt on stderr and reads a password on the
+// terminal, it is overridden in tests.
+var promptPassword = func(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := readPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the password: %s", err)
+	}
+	return string(b), nil
+}
+
+// LoadCredentials sets the Password and Token of cfg that are not set
+// directly, to avoid leaking them in the process listings. The password is
+// read from PasswordFile, else from the PasswordEnv environment variable,
+// else on the terminal if PasswordPrompt is set. The token is read from
+// TokenFile, else from the TokenEnv environment variable. The trailing
+// newline of the files is ignored.
+func (cfg *Config) LoadCredentials() error {
+	password, err := loadSecret("password", cfg.Password, cfg.PasswordFile, PasswordEnv)
+	if err != nil {
+		return err
+	}
+	if password == "" && cfg.PasswordPrompt {
+		prompt := "Password: "
+		if cfg.Username != "" {
+			prompt = fmt.Sprintf("Password for %s: ", cfg.Username)
+		}
+		if password, err = promptPassword(prompt); err != nil {
+			return err
+		}
+	}
+	token, err := loadSecret("token", cfg.Token, cfg.TokenFile, TokenEnv)
+	if err != nil {
+		return err
+	}
+	cfg.Password = password
+	cfg.Token = token
+	return nil
+}
+
+func loadSecret(name, value, file, env string) (string, error) {
+	if file != "" {
+		if value != "" {
+			return "", fmt.Errorf("both the %s and the %s file are set", name, name)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the %s file: %s", name, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	if value != "" {
+		return value, nil
+	}
+	return os.Getenv(env), nil
+}
+
+// readLine reads r up to the end of the line, one byte at a time to not
+// consume what follows it.
+func readLine(r io.Reader) ([]byte, error) {
+	var line []byte
+	var b [1]byte
+	for {
+		n, err := r.Read(b[:])
+		if n > 0 {
+			if b[0] == '\n' {
+				return bytes.TrimSuffix(line, []byte{'\r'}), nil
+			}
+			line = append(line, b[0])
+		}
+		if err == io.EOF {
+			if len(line) == 0 {
+				return nil, errors.New("no password entered")
+			}
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("filepass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("filetoken\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer func(f func(string) (string, error)) { promptPassword = f }(promptPassword)
+	promptPassword = func(prompt string) (string, error) {
+		if prompt != "Password for admin: " {
+			return "", errors.New("unexpected prompt " + prompt)
+		}
+		return "promptpass", nil
+	}
+
+	for name, tc := range map[string]struct {
+		cfg      Config
+		env      map[string]string
+		password string
+		token    string
+		err      string
+	}{
+		"direct": {
+			cfg:      Config{Password: "pass", Token: "token"},
+			env:      map[string]string{PasswordEnv: "envpass", TokenEnv: "envtoken"},
+			password: "pass",
+			token:    "token",
+		},
+		"files": {
+			cfg:      Config{PasswordFile: passwordFile, TokenFile: tokenFile},
+			env:      map[string]string{PasswordEnv: "envpass", TokenEnv: "envtoken"},
+			password: "filepass",
+			token:    "filetoken",
+		},
+		"environment": {
+			cfg:      Config{PasswordPrompt: true},
+			env:      map[string]string{PasswordEnv: "envpass", TokenEnv: "envtoken"},
+			password: "envpass",
+			token:    "envtoken",
+		},
+		"prompt": {
+			cfg:      Config{Username: "admin", PasswordPrompt: true},
+			password: "promptpass",
+		},
+		"password and file": {
+			cfg: Config{Password: "pass", PasswordFile: passwordFile},
+			err: "both the password and the password file are set",
+		},
+		"missing file": {
+			cfg: Config{TokenFile: filepath.Join(dir, "missing")},
+			err: "failed to read the token file",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(PasswordEnv, tc.env[PasswordEnv])
+			t.Setenv(TokenEnv, tc.env[TokenEnv])
+			cfg := tc.cfg
+			err := cfg.LoadCredentials()
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Password != tc.password || cfg.Token != tc.token {
+				t.Errorf("expected password %q and token %q, got %q and %q",
+					tc.password, tc.token, cfg.Password, cfg.Token)
+			}
+		})
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	r := strings.NewReader("secret\r\nnext\n")
+	for _, expected := range []string{"secret", "next"} {
+		line, err := readLine(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != expected {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	}
+	if _, err := readLine(r); err == nil {
+		t.Error("expected an error at EOF")
+	}
+}
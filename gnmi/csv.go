@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// CSVHeader is the header row written by CSVWriter.WriteHeader.
+var CSVHeader = []string{"timestamp", "target", "path", "type", "value"}
+
+// CSVWriter writes gNMI notifications as CSV rows of timestamp, target, path,
+// type and value, one row per update or delete. The timestamp is in RFC3339
+// format with nanoseconds, the type is the type of the TypedValue of the
+// update (e.g. "json_ietf", "uint") or "delete", and JSON values are written
+// compact. It is safe for concurrent use.
+type CSVWriter struct {
+	mu sync.Mutex
+	w  *csv.Writer
+}
+
+// NewCSVWriter returns a CSVWriter writing to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// WriteHeader writes the CSVHeader row.
+func (c *CSVWriter) WriteHeader() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.w.Write(CSVHeader)
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// WriteGetResponse writes the notifications of resp.
+func (c *CSVWriter) WriteGetResponse(resp *pb.GetResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, notif := range resp.GetNotification() {
+		c.writeNotification(notif)
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// WriteSubscribeResponse writes the notification of resp, if any. Like
+// LogSubscribeResponse, it returns an error for error responses and failed
+// initial syncs.
+func (c *CSVWriter) WriteSubscribeResponse(resp *pb.SubscribeResponse) error {
+	switch r := resp.GetResponse().(type) {
+	case *pb.SubscribeResponse_Error:
+		return errors.New(r.Error.Message)
+	case *pb.SubscribeResponse_SyncResponse:
+		if !r.SyncResponse {
+			return errors.New("initial sync failed")
+		}
+	case *pb.SubscribeResponse_Update:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.writeNotification(r.Update)
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *CSVWriter) writeNotification(notif *pb.Notification) {
+	ts := time.Unix(0, notif.GetTimestamp()).UTC().Format(time.RFC3339Nano)
+	target := notif.GetPrefix().GetTarget()
+	prefix := StrPath(notif.GetPrefix())
+	for _, del := range notif.GetDelete() {
+		c.w.Write([]string{ts, target, path.Join(prefix, StrPath(del)), "delete", ""})
+	}
+	for _, update := range notif.GetUpdate() {
+		c.w.Write([]string{ts, target, path.Join(prefix, StrPath(update.GetPath())),
+			updateType(update), StrUpdateValCompactJSON(update)})
+	}
+}
+
+// updateType returns the type of the value of u, named after the TypedValue
+// field holding it without its "_val" suffix.
+func updateType(u *pb.Update) string {
+	if u.GetVal() == nil {
+		// Backwards compatibility with pre-v0.4 gnmi
+		return strings.ToLower(u.GetValue().GetType().String())
+	}
+	field := u.GetVal().ProtoReflect().WhichOneof(
+		u.GetVal().ProtoReflect().Descriptor().Oneofs().ByName("value"))
+	if field == nil {
+		return ""
+	}
+	return strings.TrimSuffix(string(field.Name()), "_val")
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	if err := w.WriteHeader(); err != nil {
+		t.Fatal(err)
+	}
+	notif := &pb.Notification{
+		Timestamp: 1500000000123456789,
+		Prefix: &pb.Path{Target: "dut", Elem: []*pb.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": "Ethernet1"}},
+		}},
+		Delete: []*pb.Path{{Elem: []*pb.PathElem{{Name: "config"}}}},
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "state"}, {Name: "description"}}},
+			Val: &pb.TypedValue{
+				Value: &pb.TypedValue_StringVal{StringVal: `uplink, "core"`}},
+		}, {
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "state"}, {Name: "mtu"}}},
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: 1500}},
+		}, {
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "state"}, {Name: "counters"}}},
+			Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+				JsonIetfVal: []byte("{\n  \"in-pkts\": 1,\n  \"out-pkts\": 2\n}")}},
+		}},
+	}
+	if err := w.WriteSubscribeResponse(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_Update{Update: notif}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteSubscribeResponse(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteGetResponse(&pb.GetResponse{Notification: []*pb.Notification{{
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "up"}}},
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_BoolVal{BoolVal: true}},
+		}},
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteSubscribeResponse(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: false}}); err == nil {
+		t.Error("expected error for failed initial sync")
+	}
+
+	const ts = "2017-07-14T02:40:00.123456789Z"
+	const prefix = "/interfaces/interface[name=Ethernet1]"
+	expected := "timestamp,target,path,type,value\n" +
+		ts + ",dut," + prefix + "/config,delete,\n" +
+		ts + ",dut," + prefix + `/state/description,string,"uplink, ""core"""` + "\n" +
+		ts + ",dut," + prefix + "/state/mtu,uint,1500\n" +
+		ts + ",dut," + prefix + `/state/counters,json_ietf,"{""in-pkts"":1,""out-pkts"":2}"` +
+		"\n" +
+		"1970-01-01T00:00:00Z,,/system/up,bool,true\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
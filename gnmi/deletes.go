@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"github.com/aristanetworks/goarista/key"
+	"github.com/aristanetworks/goarista/path"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// DeleteExpander expands the deletes of subtrees, which targets send e.g.
+// when an interface is removed, into the deletes of each leaf previously
+// updated under them, for the consumers that only handle leaf deletes. It
+// caches the paths of all the leaves updated, so its memory grows with the
+// size of the state subscribed to. The zero value is ready to use. It is
+// not safe for concurrent use, so each subscription needs its own.
+type DeleteExpander struct {
+	// leaves are the leaves updated and not deleted since, by target,
+	// origin and path.
+	leaves path.MapOf[*cachedLeaf]
+}
+
+type cachedLeaf struct {
+	key   key.Path
+	elems []*pb.PathElem
+}
+
+func leafKey(target string, p *pb.Path) key.Path {
+	return append(key.Path{key.New(target), key.New(NormalizeOrigin(p.Origin))},
+		path.FromGNMI(p)...)
+}
+
+// Notification records the leaves updated by notif, and replaces each of
+// its deletes with the deletes of the leaves recorded under it. The
+// deletes with wildcards and the ones of paths without recorded leaves
+// are left as is.
+func (e *DeleteExpander) Notification(notif *pb.Notification) {
+	prefix := upgradePath(notif.Prefix)
+	target := prefix.GetTarget()
+	if len(notif.Delete) > 0 {
+		n := len(prefix.GetElem())
+		deletes := make([]*pb.Path, 0, len(notif.Delete))
+		for _, d := range notif.Delete {
+			full := fullPath(prefix, d)
+			if hasWildcards(full) {
+				deletes = append(deletes, d)
+				continue
+			}
+			var leaves []*cachedLeaf
+			for it := e.leaves.IterPrefixed(leafKey(target, full)); it.Next(); {
+				leaves = append(leaves, it.Value())
+			}
+			if len(leaves) == 0 {
+				deletes = append(deletes, d)
+				continue
+			}
+			for _, leaf := range leaves {
+				e.leaves.Delete(leaf.key)
+				deletes = append(deletes, &pb.Path{Origin: d.Origin, Elem: leaf.elems[n:]})
+			}
+		}
+		notif.Delete = deletes
+	}
+	for _, u := range notif.Update {
+		full := fullPath(prefix, u.Path)
+		k := leafKey(target, full)
+		e.leaves.Set(k, &cachedLeaf{key: k, elems: full.Elem})
+	}
+}
+
+func hasWildcards(p *pb.Path) bool {
+	for _, elem := range p.Elem {
+		if hasWildcard(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of leaves recorded.
+func (e *DeleteExpander) Len() int {
+	return e.leaves.Len()
+}
+
+// Transform expands the deletes of the notification of resp, if any, and
+// returns resp. It makes e a Transformer, to chain it before the other
+// transforms with TransformSubscribeResponses.
+func (e *DeleteExpander) Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+	if notif := resp.GetUpdate(); notif != nil {
+		e.Notification(notif)
+	}
+	return []*pb.SubscribeResponse{resp}
+}
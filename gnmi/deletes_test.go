@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"slices"
+	"sort"
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestDeleteExpander(t *testing.T) {
+	var e DeleteExpander
+	path := func(s string) *pb.Path {
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	deletes := func(prefix string, dels ...string) []string {
+		t.Helper()
+		notif := &pb.Notification{Prefix: path(prefix)}
+		notif.Prefix.Target = "dut"
+		for _, d := range dels {
+			notif.Delete = append(notif.Delete, path(d))
+		}
+		resp := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: notif}}
+		if resps := e.Transform(resp); len(resps) != 1 || resps[0] != resp {
+			t.Fatalf("expected the response to be transformed in place, got %v", resps)
+		}
+		var paths []string
+		for _, d := range notif.Delete {
+			paths = append(paths, StrPath(d))
+		}
+		sort.Strings(paths)
+		return paths
+	}
+	update := func(prefix string, paths ...string) {
+		t.Helper()
+		notif := &pb.Notification{Prefix: path(prefix)}
+		notif.Prefix.Target = "dut"
+		for _, p := range paths {
+			notif.Update = append(notif.Update, &pb.Update{Path: path(p), Val: TypedValue(1)})
+		}
+		e.Notification(notif)
+	}
+	check := func(expected, actual []string) {
+		t.Helper()
+		if !slices.Equal(expected, actual) {
+			t.Errorf("expected deletes %v, got %v", expected, actual)
+		}
+	}
+
+	update("/interfaces", "interface[name=Ethernet1]/state/name",
+		"interface[name=Ethernet1]/state/counters/in-octets",
+		"interface[name=Ethernet2]/state/name")
+	update("/interfaces/interface[name=Ethernet1]/state", "mtu")
+	if n := e.Len(); n != 4 {
+		t.Errorf("expected 4 leaves, got %d", n)
+	}
+
+	// The deletes are relative to the prefix of their notification.
+	check([]string{"/interface[name=Ethernet1]/state/counters/in-octets",
+		"/interface[name=Ethernet1]/state/mtu", "/interface[name=Ethernet1]/state/name"},
+		deletes("/interfaces", "interface[name=Ethernet1]"))
+	// The leaves deleted are forgotten, the unknown paths kept as is.
+	check([]string{"/interface[name=Ethernet1]"},
+		deletes("/interfaces", "interface[name=Ethernet1]"))
+	check([]string{"/interface[name=*]"}, deletes("/interfaces", "interface[name=*]"))
+	check([]string{"/name"}, deletes("/interfaces/interface[name=Ethernet2]/state", "name"))
+	if n := e.Len(); n != 0 {
+		t.Errorf("expected no leaves, got %d", n)
+	}
+}
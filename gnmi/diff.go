@@ -0,0 +1,232 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffOptions configures DiffJSON.
+type DiffOptions struct {
+	// Origin and Target are set in the returned operations.
+	Origin string
+	Target string
+	// ListKeys maps the name of YANG lists to the names of their
+	// keys, e.g. {"interface": {"name"}}. In JSON_IETF a list is
+	// encoded as an array of objects: the entries of the lists found
+	// in ListKeys are matched by their keys and diffed one by one,
+	// while any other array is treated as a leaf-list and replaced as
+	// a whole when it changes.
+	ListKeys map[string][]string
+}
+
+// DiffJSON compares the before and after JSON trees found at path and
+// returns the Set operations that turn before into after: a delete for
+// each node that was removed, an update for each node that was added or
+// whose value changed, and a replace for each leaf-list or unkeyed list
+// that changed. Nodes that did not change are left out, so applying the
+// operations only touches what differs.
+func DiffJSON(path []string, before, after []byte, opts DiffOptions) ([]*Operation, error) {
+	b, err := decodeJSONTree(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse before JSON: %s", err)
+	}
+	a, err := decodeJSONTree(after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse after JSON: %s", err)
+	}
+	d := differ{opts: opts}
+	if err := d.diff(path, b, a); err != nil {
+		return nil, err
+	}
+	return d.ops, nil
+}
+
+func decodeJSONTree(data []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type differ struct {
+	opts DiffOptions
+	ops  []*Operation
+}
+
+func (d *differ) add(typ string, path []string, val interface{}) error {
+	op := &Operation{
+		Type:   typ,
+		Origin: d.opts.Origin,
+		Target: d.opts.Target,
+		Path:   append([]string(nil), path...),
+	}
+	if typ != "delete" {
+		b, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		op.Val = string(b)
+	}
+	d.ops = append(d.ops, op)
+	return nil
+}
+
+func (d *differ) diff(path []string, before, after interface{}) error {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	if after == nil {
+		return d.add("delete", path, nil)
+	}
+	b, bok := before.(map[string]interface{})
+	a, aok := after.(map[string]interface{})
+	if !bok || !aok {
+		if _, ok := after.([]interface{}); ok {
+			return d.add("replace", path, after)
+		}
+		return d.add("update", path, after)
+	}
+	for _, name := range sortedKeys(b, a) {
+		bv, inBefore := b[name]
+		av, inAfter := a[name]
+		child := append(path[:len(path):len(path)], name)
+		switch {
+		case !inAfter:
+			if err := d.add("delete", child, nil); err != nil {
+				return err
+			}
+		case !inBefore:
+			if err := d.diff(child, nil, av); err != nil {
+				return err
+			}
+		default:
+			if err := d.diffList(path, name, bv, av); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffList diffs the child name of the node at path, matching the
+// entries by their keys if the child is a known keyed list.
+func (d *differ) diffList(path []string, name string, before, after interface{}) error {
+	keyNames, ok := d.opts.ListKeys[listName(name)]
+	b, bok := before.([]interface{})
+	a, aok := after.([]interface{})
+	if !ok || !bok || !aok {
+		return d.diff(append(path[:len(path):len(path)], name), before, after)
+	}
+	bEntries, bOrder, err := listEntries(name, keyNames, b)
+	if err != nil {
+		return err
+	}
+	aEntries, aOrder, err := listEntries(name, keyNames, a)
+	if err != nil {
+		return err
+	}
+	for _, elem := range bOrder {
+		if _, ok := aEntries[elem]; !ok {
+			if err := d.add("delete", append(path[:len(path):len(path)], elem), nil); err != nil {
+				return err
+			}
+		}
+	}
+	for _, elem := range aOrder {
+		entry := append(path[:len(path):len(path)], elem)
+		bv, ok := bEntries[elem]
+		if !ok {
+			if err := d.add("update", entry, aEntries[elem]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.diff(entry, bv, aEntries[elem]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listEntries indexes the entries of a keyed list by their path
+// element, e.g. interface[name=Ethernet1].
+func listEntries(name string, keyNames []string,
+	entries []interface{}) (map[string]interface{}, []string, error) {
+	m := make(map[string]interface{}, len(entries))
+	order := make([]string, 0, len(entries))
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("entry of list %q is not an object: %v", name, e)
+		}
+		keys := make(map[string]string, len(keyNames))
+		for _, k := range keyNames {
+			v, ok := entry[k]
+			if !ok {
+				return nil, nil, fmt.Errorf("entry of list %q is missing key %q", name, k)
+			}
+			keys[k] = keyString(v)
+		}
+		elem := name + KeyToString(keys)
+		if _, ok := m[elem]; ok {
+			return nil, nil, fmt.Errorf("duplicate entry %s in list %q", elem, name)
+		}
+		m[elem] = entry
+		order = append(order, elem)
+	}
+	sort.Strings(order)
+	return m, order, nil
+}
+
+// listName strips the optional YANG module prefix of a JSON_IETF
+// member name, e.g. "openconfig-interfaces:interface".
+func listName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func keyString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	var keys []string
+	seen := map[string]struct{}{}
+	for _, m := range maps {
+		for k := range m {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestDiffJSON(t *testing.T) {
+	listKeys := map[string][]string{"interface": {"name"}, "entry": {"id", "vrf"}}
+	for name, tc := range map[string]struct {
+		before string
+		after  string
+		exp    []*Operation
+	}{
+		"equal": {
+			before: `{"a": 1, "b": {"c": "x"}}`,
+			after:  `{"b": {"c": "x"}, "a": 1}`,
+		},
+		"leaf changed": {
+			before: `{"a": 1, "b": {"c": "x", "d": true}}`,
+			after:  `{"a": 1, "b": {"c": "y", "d": true}}`,
+			exp: []*Operation{
+				{Type: "update", Path: []string{"sys", "b", "c"}, Val: `"y"`},
+			},
+		},
+		"added and removed": {
+			before: `{"a": 1, "b": {"c": "x"}}`,
+			after:  `{"a": 1, "e": {"f": 2}}`,
+			exp: []*Operation{
+				{Type: "delete", Path: []string{"sys", "b"}},
+				{Type: "update", Path: []string{"sys", "e"}, Val: `{"f":2}`},
+			},
+		},
+		"leaf-list": {
+			before: `{"servers": ["a", "b"]}`,
+			after:  `{"servers": ["a", "c"]}`,
+			exp: []*Operation{
+				{Type: "replace", Path: []string{"sys", "servers"}, Val: `["a","c"]`},
+			},
+		},
+		"keyed list": {
+			before: `{"interface": [
+				{"name": "Ethernet1", "mtu": 1500},
+				{"name": "Ethernet2", "mtu": 1500}]}`,
+			after: `{"interface": [
+				{"name": "Ethernet3", "mtu": 9000},
+				{"name": "Ethernet1", "mtu": 9214}]}`,
+			exp: []*Operation{
+				{Type: "delete", Path: []string{"sys", "interface[name=Ethernet2]"}},
+				{Type: "update", Path: []string{"sys", "interface[name=Ethernet1]", "mtu"},
+					Val: `9214`},
+				{Type: "update", Path: []string{"sys", "interface[name=Ethernet3]"},
+					Val: `{"mtu":9000,"name":"Ethernet3"}`},
+			},
+		},
+		"multiple keys with module prefix": {
+			before: `{"mod:entry": [{"id": 1, "vrf": "red", "v": "a"}]}`,
+			after:  `{"mod:entry": [{"id": 1, "vrf": "red", "v": "b"}]}`,
+			exp: []*Operation{
+				{Type: "update", Path: []string{"sys", "mod:entry[id=1][vrf=red]", "v"},
+					Val: `"b"`},
+			},
+		},
+		"empty before": {
+			before: ``,
+			after:  `{"a": 1}`,
+			exp: []*Operation{
+				{Type: "update", Path: []string{"sys"}, Val: `{"a":1}`},
+			},
+		},
+		"empty after": {
+			before: `{"a": 1}`,
+			after:  ``,
+			exp: []*Operation{
+				{Type: "delete", Path: []string{"sys"}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ops, err := DiffJSON([]string{"sys"}, []byte(tc.before), []byte(tc.after),
+				DiffOptions{ListKeys: listKeys})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := test.Diff(tc.exp, ops); diff != "" {
+				t.Errorf("unexpected operations: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffJSONErrors(t *testing.T) {
+	listKeys := map[string][]string{"interface": {"name"}}
+	for name, tc := range map[string]struct {
+		before string
+		after  string
+	}{
+		"invalid JSON":  {before: `{`, after: `{}`},
+		"missing key":   {before: `{"interface": [{"mtu": 1}]}`, after: `{"interface": []}`},
+		"not an object": {before: `{"interface": [1]}`, after: `{"interface": []}`},
+		"duplicate entry": {
+			before: `{"interface": []}`,
+			after:  `{"interface": [{"name": "a"}, {"name": "a"}]}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DiffJSON(nil, []byte(tc.before), []byte(tc.after),
+				DiffOptions{ListKeys: listKeys}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
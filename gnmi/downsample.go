@@ -0,0 +1,278 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"container/heap"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// DownsampleSubscribeResponses forwards the SubscribeResponses of respChan
+// to the returned channel, forwarding at most one update per path every
+// interval, to cut the cost of the paths updated at a high rate. The
+// first update of a path is forwarded when it is received, and the
+// following ones received within interval are held back and replaced by
+// the later ones, so that the latest value is forwarded once interval has
+// elapsed. Deletes are always forwarded, and drop the updates held back
+// for the deleted paths. Atomic Notifications and the other responses,
+// such as the sync_response, are forwarded as is. The returned channel is
+// closed after respChan is, once the updates held back are forwarded.
+func DownsampleSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	interval time.Duration) chan *pb.SubscribeResponse {
+	return downsampleSubscribeResponses(respChan, interval, RealClock)
+}
+
+func downsampleSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	interval time.Duration, clock Clock) chan *pb.SubscribeResponse {
+	downsampled := make(chan *pb.SubscribeResponse)
+	go func() {
+		defer close(downsampled)
+		d := NewDownsampler(interval)
+		var timeout <-chan time.Time
+		var scheduled time.Time
+		send := func(notif *pb.Notification) {
+			downsampled <- &pb.SubscribeResponse{
+				Response: &pb.SubscribeResponse_Update{Update: notif}}
+		}
+		for {
+			select {
+			case resp, ok := <-respChan:
+				if !ok {
+					for _, notif := range d.FlushAll() {
+						send(notif)
+					}
+					return
+				}
+				notif := resp.GetUpdate()
+				if notif == nil || notif.Atomic {
+					downsampled <- resp
+					continue
+				}
+				if notif = d.Filter(notif, clock.Now()); notif != nil {
+					send(notif)
+				}
+			case <-timeout:
+				timeout, scheduled = nil, time.Time{}
+				for _, notif := range d.Flush(clock.Now()) {
+					send(notif)
+				}
+			}
+			// Only wait again if the next deadline is earlier than the
+			// scheduled one, the later deadlines are handled once it
+			// fires.
+			if next, ok := d.Next(); ok && (timeout == nil || next.Before(scheduled)) {
+				timeout, scheduled = clock.After(next.Sub(clock.Now())), next
+			}
+		}
+	}()
+	return downsampled
+}
+
+// Downsampler limits the updates of each path to at most one per
+// interval, keeping the latest value of the updates held back. It is not
+// safe for concurrent use.
+type Downsampler struct {
+	interval time.Duration
+	paths    map[string]*sampledPath
+	// deadlines orders the paths by the end of their interval.
+	deadlines sampledPaths
+}
+
+// sampledPath is the state of a path of a Downsampler.
+type sampledPath struct {
+	key string
+	// sent is when the last update of the path was forwarded.
+	sent time.Time
+	// pending is the latest update held back, if any, with the prefix
+	// and the timestamp of its Notification.
+	pending   *pb.Update
+	prefix    *pb.Path
+	timestamp int64
+	index     int
+}
+
+// NewDownsampler returns a Downsampler forwarding at most one update per
+// path every interval.
+func NewDownsampler(interval time.Duration) *Downsampler {
+	return &Downsampler{interval: interval, paths: map[string]*sampledPath{}}
+}
+
+// Filter returns the Notification with the deletes of notif and the
+// updates of notif to forward at now, or nil if there are none. The other
+// updates are held back until Flush returns them.
+func (d *Downsampler) Filter(notif *pb.Notification, now time.Time) *pb.Notification {
+	for _, del := range notif.Delete {
+		d.delete(notif.Prefix, del)
+	}
+	var updates []*pb.Update
+	for _, u := range notif.Update {
+		key := pathKey(notif.Prefix, u.Path)
+		p, ok := d.paths[key]
+		if !ok {
+			p = &sampledPath{key: key, sent: now}
+			d.paths[key] = p
+			heap.Push(&d.deadlines, p)
+			updates = append(updates, u)
+			continue
+		}
+		if now.Sub(p.sent) >= d.interval {
+			p.sent, p.pending, p.prefix = now, nil, nil
+			heap.Fix(&d.deadlines, p.index)
+			updates = append(updates, u)
+			continue
+		}
+		p.pending, p.prefix, p.timestamp = u, notif.Prefix, notif.Timestamp
+	}
+	if len(updates) == 0 && len(notif.Delete) == 0 {
+		return nil
+	}
+	return &pb.Notification{
+		Timestamp: notif.Timestamp,
+		Prefix:    notif.Prefix,
+		Update:    updates,
+		Delete:    notif.Delete,
+	}
+}
+
+// delete forgets the paths at or under the deleted path, dropping their
+// updates held back.
+func (d *Downsampler) delete(prefix, del *pb.Path) {
+	key := pathKey(prefix, del)
+	root := strings.HasSuffix(key, "\x00/")
+	for k, p := range d.paths {
+		if k == key || (root && strings.HasPrefix(k, key)) ||
+			strings.HasPrefix(k, key+"/") {
+			heap.Remove(&d.deadlines, p.index)
+			delete(d.paths, k)
+		}
+	}
+}
+
+// Flush returns the updates held back whose interval elapsed at now, in
+// one Notification per prefix and timestamp, ordered by timestamp.
+func (d *Downsampler) Flush(now time.Time) []*pb.Notification {
+	var f flushed
+	for len(d.deadlines) > 0 {
+		p := d.deadlines[0]
+		if now.Sub(p.sent) < d.interval {
+			break
+		}
+		if p.pending == nil {
+			// The path was not updated during its interval, forget it.
+			heap.Pop(&d.deadlines)
+			delete(d.paths, p.key)
+			continue
+		}
+		f.add(p)
+		p.sent, p.pending, p.prefix = now, nil, nil
+		heap.Fix(&d.deadlines, p.index)
+	}
+	return f.notifications()
+}
+
+// FlushAll returns all the updates held back, whether their interval
+// elapsed or not, like Flush does.
+func (d *Downsampler) FlushAll() []*pb.Notification {
+	var f flushed
+	for _, p := range d.deadlines {
+		if p.pending != nil {
+			f.add(p)
+			p.pending, p.prefix = nil, nil
+		}
+	}
+	return f.notifications()
+}
+
+// flushed groups the updates flushed by a Downsampler by prefix and
+// timestamp, so that each update keeps the timestamp of its Notification.
+type flushed struct {
+	notifs []*pb.Notification
+	keys   []string
+	index  map[string]int
+}
+
+func (f *flushed) add(p *sampledPath) {
+	key := prefixKey(p.prefix) + "\x00" + strconv.FormatInt(p.timestamp, 10)
+	i, ok := f.index[key]
+	if !ok {
+		if f.index == nil {
+			f.index = map[string]int{}
+		}
+		i = len(f.notifs)
+		f.index[key] = i
+		f.notifs = append(f.notifs, &pb.Notification{Timestamp: p.timestamp, Prefix: p.prefix})
+		f.keys = append(f.keys, key)
+	}
+	f.notifs[i].Update = append(f.notifs[i].Update, p.pending)
+}
+
+// notifications returns the Notifications ordered by timestamp, and then by
+// prefix.
+func (f *flushed) notifications() []*pb.Notification {
+	sort.Sort(f)
+	return f.notifs
+}
+
+func (f *flushed) Len() int { return len(f.notifs) }
+
+func (f *flushed) Less(i, j int) bool {
+	if f.notifs[i].Timestamp != f.notifs[j].Timestamp {
+		return f.notifs[i].Timestamp < f.notifs[j].Timestamp
+	}
+	return f.keys[i] < f.keys[j]
+}
+
+func (f *flushed) Swap(i, j int) {
+	f.notifs[i], f.notifs[j] = f.notifs[j], f.notifs[i]
+	f.keys[i], f.keys[j] = f.keys[j], f.keys[i]
+}
+
+// Next returns when Flush should be called next, and false if no path is
+// tracked.
+func (d *Downsampler) Next() (time.Time, bool) {
+	if len(d.deadlines) == 0 {
+		return time.Time{}, false
+	}
+	return d.deadlines[0].sent.Add(d.interval), true
+}
+
+// pathKey returns the key of the path of an update or a delete, made of
+// the target, the origin and the full path.
+func pathKey(prefix, path *pb.Path) string {
+	return prefix.GetTarget() + "\x00" + prefix.GetOrigin() + "\x00" +
+		StrPath(JoinPaths(prefix, path))
+}
+
+// sampledPaths is a heap of the paths of a Downsampler ordered by the time
+// their last update was forwarded.
+type sampledPaths []*sampledPath
+
+func (h sampledPaths) Len() int           { return len(h) }
+func (h sampledPaths) Less(i, j int) bool { return h[i].sent.Before(h[j].sent) }
+
+func (h sampledPaths) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *sampledPaths) Push(x interface{}) {
+	p := x.(*sampledPath)
+	p.index = len(*h)
+	*h = append(*h, p)
+}
+
+func (h *sampledPaths) Pop() interface{} {
+	old := *h
+	p := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return p
+}
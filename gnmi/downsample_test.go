@@ -0,0 +1,142 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDownsampler(t *testing.T) {
+	path := func(s string) *pb.Path {
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	notif := func(prefix string, ts int64, updates map[string]int64,
+		deletes ...string) *pb.Notification {
+		n := &pb.Notification{Timestamp: ts, Prefix: path(prefix)}
+		for _, p := range []string{"x", "y", "z/w"} {
+			if v, ok := updates[p]; ok {
+				n.Update = append(n.Update, &pb.Update{Path: path(p), Val: TypedValue(v)})
+			}
+		}
+		for _, p := range deletes {
+			n.Delete = append(n.Delete, path(p))
+		}
+		return n
+	}
+	check := func(expected, actual *pb.Notification) {
+		t.Helper()
+		if !proto.Equal(expected, actual) {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+
+	start := time.Unix(1000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	d := NewDownsampler(10 * time.Second)
+	check(notif("/a", 1, map[string]int64{"x": 1, "y": 1}),
+		d.Filter(notif("/a", 1, map[string]int64{"x": 1, "y": 1}), at(0)))
+	// The updates within the interval are held back, keeping the latest.
+	check(nil, d.Filter(notif("/a", 2, map[string]int64{"x": 2}), at(time.Second)))
+	check(notif("/a", 3, map[string]int64{"z/w": 3}),
+		d.Filter(notif("/a", 3, map[string]int64{"x": 3, "z/w": 3}), at(2*time.Second)))
+	if next, ok := d.Next(); !ok || !next.Equal(at(10*time.Second)) {
+		t.Errorf("expected the next flush at %s, got %s, %t", at(10*time.Second), next, ok)
+	}
+	if notifs := d.Flush(at(9 * time.Second)); len(notifs) != 0 {
+		t.Errorf("unexpected flush before the end of the interval: %v", notifs)
+	}
+	notifs := d.Flush(at(10 * time.Second))
+	if len(notifs) != 1 {
+		t.Fatalf("expected 1 notification, got %v", notifs)
+	}
+	check(notif("/a", 3, map[string]int64{"x": 3}), notifs[0])
+	// y was not updated during its interval and is forgotten.
+	if _, ok := d.paths[pathKey(path("/a"), path("y"))]; ok {
+		t.Error("expected y to be forgotten")
+	}
+	check(notif("/a", 4, map[string]int64{"y": 4}),
+		d.Filter(notif("/a", 4, map[string]int64{"x": 4, "y": 4}), at(11*time.Second)))
+
+	// Deletes are forwarded and drop the updates held back under them.
+	check(nil, d.Filter(notif("/a", 5, map[string]int64{"z/w": 5}), at(11*time.Second)))
+	check(notif("/a", 6, nil, "z"), d.Filter(notif("/a", 6, nil, "z"), at(11*time.Second)))
+	notifs = d.Flush(at(time.Minute))
+	if len(notifs) != 1 {
+		t.Fatalf("expected 1 notification, got %v", notifs)
+	}
+	check(notif("/a", 4, map[string]int64{"x": 4}), notifs[0])
+	check(notif("/a", 7, map[string]int64{"z/w": 7}),
+		d.Filter(notif("/a", 7, map[string]int64{"z/w": 7}), at(time.Minute)))
+	check(notif("/", 8, nil, "/"), d.Filter(notif("/", 8, nil, "/"), at(time.Minute)))
+	if len(d.paths) != 0 || len(d.deadlines) != 0 {
+		t.Errorf("expected all the paths to be deleted, got %v", d.paths)
+	}
+
+	// The updates held back keep the timestamps of their notifications.
+	d.Filter(notif("/a", 9, map[string]int64{"x": 9, "y": 9}), at(time.Minute))
+	check(nil, d.Filter(notif("/a", 11, map[string]int64{"x": 11}), at(time.Minute)))
+	check(nil, d.Filter(notif("/a", 10, map[string]int64{"y": 10}), at(time.Minute)))
+	notifs = d.FlushAll()
+	if len(notifs) != 2 {
+		t.Fatalf("expected 2 notifications, got %v", notifs)
+	}
+	check(notif("/a", 10, map[string]int64{"y": 10}), notifs[0])
+	check(notif("/a", 11, map[string]int64{"x": 11}), notifs[1])
+	if notifs = d.Flush(at(2 * time.Minute)); len(notifs) != 0 {
+		t.Errorf("unexpected notifications flushed twice: %v", notifs)
+	}
+}
+
+func TestDownsampleSubscribeResponses(t *testing.T) {
+	update := func(v int64) *pb.SubscribeResponse {
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{
+			Update: &pb.Notification{Timestamp: v, Update: []*pb.Update{{
+				Path: &pb.Path{Elem: []*pb.PathElem{{Name: "x"}}},
+				Val:  TypedValue(v),
+			}}},
+		}}
+	}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+	receive := func(c <-chan *pb.SubscribeResponse, expected *pb.SubscribeResponse) {
+		t.Helper()
+		select {
+		case resp := <-c:
+			if !proto.Equal(expected, resp) {
+				t.Errorf("expected %s, got %s", expected, resp)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", expected)
+		}
+	}
+
+	clock := NewFakeClock(time.Unix(1000, 0))
+	respChan := make(chan *pb.SubscribeResponse)
+	downsampled := downsampleSubscribeResponses(respChan, time.Second, clock)
+	respChan <- update(1)
+	receive(downsampled, update(1))
+	respChan <- update(2)
+	respChan <- sync
+	receive(downsampled, sync)
+	respChan <- update(3)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	receive(downsampled, update(3))
+	// The updates held back are forwarded when respChan is closed.
+	respChan <- update(4)
+	close(respChan)
+	receive(downsampled, update(4))
+	if resp, ok := <-downsampled; ok {
+		t.Errorf("unexpected response %s", resp)
+	}
+}
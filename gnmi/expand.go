@@ -0,0 +1,247 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// ExpandWildcards returns the instantiated paths matched by path, whose
+// elements may have a "*" name or "*" key values, for the targets that do
+// not support wildcards. The children matching a "*" name are discovered
+// with ShallowChildren, and the entries of a list with a "*" key value
+// with a Get of the list limited to one level by the depth extension, from
+// which their keys are read. A path without wildcards is returned as is.
+func ExpandWildcards(ctx context.Context, client pb.GNMIClient,
+	path *pb.Path) ([]*pb.Path, error) {
+	path = upgradePath(path)
+	return expandWildcards(ctx, client, path, nil, path.GetElem())
+}
+
+func expandWildcards(ctx context.Context, client pb.GNMIClient, path *pb.Path,
+	prefix, rest []*pb.PathElem) ([]*pb.Path, error) {
+	i := 0
+	for ; i < len(rest) && !hasWildcard(rest[i]); i++ {
+	}
+	parent := append(prefix[:len(prefix):len(prefix)], rest[:i]...)
+	if i == len(rest) {
+		return []*pb.Path{{Origin: path.Origin, Target: path.Target, Elem: parent}}, nil
+	}
+	elem := rest[i]
+	if elem.Name == "..." {
+		return nil, errors.New("the '...' wildcard cannot be expanded")
+	}
+	var matches []*pb.PathElem
+	if elem.Name == "*" {
+		if len(elem.Key) > 0 {
+			return nil, fmt.Errorf("keys of the '*' wildcard cannot be expanded: %s",
+				ElemToString(elem))
+		}
+		children, err := ShallowChildren(ctx, client,
+			&pb.Path{Origin: path.Origin, Target: path.Target, Elem: parent})
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		for _, child := range children {
+			// The entries of a list are matched by its name.
+			if !seen[child.Name] {
+				seen[child.Name] = true
+				matches = append(matches, &pb.PathElem{Name: child.Name})
+			}
+		}
+	} else {
+		listPath := &pb.Path{Origin: path.Origin, Target: path.Target,
+			Elem: append(parent[:len(parent):len(parent)], &pb.PathElem{Name: elem.Name})}
+		entries, err := listEntryKeys(ctx, client, listPath, elem.Key)
+		if err != nil {
+			return nil, err
+		}
+		for _, keys := range entries {
+			if keysMatch(elem.Key, keys) {
+				matches = append(matches, &pb.PathElem{Name: elem.Name, Key: keys})
+			}
+		}
+	}
+	var paths []*pb.Path
+	for _, match := range matches {
+		expanded, err := expandWildcards(ctx, client, path,
+			append(parent[:len(parent):len(parent)], match), rest[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded...)
+	}
+	return paths, nil
+}
+
+func hasWildcard(elem *pb.PathElem) bool {
+	if elem.Name == "*" || elem.Name == "..." {
+		return true
+	}
+	for _, v := range elem.Key {
+		if v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// keysMatch returns whether the keys of an entry match the keys of a path
+// element, which may be "*".
+func keysMatch(pattern, keys map[string]string) bool {
+	for k, v := range pattern {
+		if kv, ok := keys[k]; !ok || (v != "*" && v != kv) {
+			return false
+		}
+	}
+	return true
+}
+
+// listEntryKeys returns the keys of the entries of the list at listPath,
+// whose key names are those of pattern, sorted.
+func listEntryKeys(ctx context.Context, client pb.GNMIClient, listPath *pb.Path,
+	pattern map[string]string) ([]map[string]string, error) {
+	resp, err := client.Get(ctx, &pb.GetRequest{
+		Path:     []*pb.Path{listPath},
+		Encoding: pb.Encoding_JSON_IETF,
+		Extension: []*gnmi_ext.Extension{{
+			Ext: &gnmi_ext.Extension_Depth{Depth: &gnmi_ext.Depth{Level: 1}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	name := listPath.Elem[len(listPath.Elem)-1].Name
+	entries := map[string]map[string]string{}
+	add := func(keys map[string]string) {
+		entries[KeyToString(keys)] = keys
+	}
+	for _, notif := range resp.Notification {
+		prefix := notif.GetPrefix().GetElem()
+		for _, update := range notif.Update {
+			elems := append(prefix[:len(prefix):len(prefix)], update.GetPath().GetElem()...)
+			// The entries are either in keyed paths, or in the JSON value
+			// of the list.
+			if n := len(listPath.Elem); len(elems) >= n {
+				if e := elems[n-1]; e.Name == name && len(e.Key) > 0 {
+					add(e.Key)
+					continue
+				}
+			}
+			for _, keys := range jsonListKeys(update.GetVal(), name, pattern) {
+				add(keys)
+			}
+		}
+	}
+	sorted := make([]string, 0, len(entries))
+	for k := range entries {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	keys := make([]map[string]string, len(sorted))
+	for i, k := range sorted {
+		keys[i] = entries[k]
+	}
+	return keys, nil
+}
+
+// jsonListKeys returns the keys of the entries of the JSON value of a list,
+// either a JSON array of entries or a JSON object with a member holding them.
+func jsonListKeys(val *pb.TypedValue, name string,
+	pattern map[string]string) []map[string]string {
+	var b []byte
+	switch v := val.GetValue().(type) {
+	case *pb.TypedValue_JsonIetfVal:
+		b = v.JsonIetfVal
+	case *pb.TypedValue_JsonVal:
+		b = v.JsonVal
+	default:
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil
+	}
+	if obj, ok := value.(map[string]interface{}); ok {
+		for member, v := range obj {
+			if listName(member) == name {
+				value = v
+			}
+		}
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	var entries []map[string]string
+	for _, e := range list {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keys := map[string]string{}
+		for member, v := range entry {
+			if _, ok := pattern[listName(member)]; ok {
+				keys[listName(member)] = keyString(v)
+			}
+		}
+		if len(keys) == len(pattern) {
+			entries = append(entries, keys)
+		}
+	}
+	return entries
+}
+
+// ExpandSubscribeRequest replaces the subscriptions of req whose paths
+// have wildcards by one subscription per path they match, expanded with
+// ExpandWildcards. It returns an error if no path matches.
+func ExpandSubscribeRequest(ctx context.Context, client pb.GNMIClient,
+	req *pb.SubscribeRequest) error {
+	subList := req.GetSubscribe()
+	if subList == nil {
+		return nil
+	}
+	prefix := upgradePath(subList.Prefix)
+	var subs []*pb.Subscription
+	for _, sub := range subList.Subscription {
+		path := upgradePath(sub.Path)
+		full := JoinPaths(prefix, path)
+		full.Origin, full.Target = path.GetOrigin(), prefix.GetTarget()
+		if full.Origin == "" {
+			full.Origin = prefix.GetOrigin()
+		}
+		expanded, err := ExpandWildcards(ctx, client, full)
+		if err != nil {
+			return fmt.Errorf("failed to expand %s: %s", StrPath(full), err)
+		}
+		n := len(prefix.GetElem())
+		for _, p := range expanded {
+			s := &pb.Subscription{
+				Path:              &pb.Path{Origin: path.GetOrigin(), Elem: p.Elem[n:]},
+				Mode:              sub.Mode,
+				SampleInterval:    sub.SampleInterval,
+				SuppressRedundant: sub.SuppressRedundant,
+				HeartbeatInterval: sub.HeartbeatInterval,
+			}
+			subs = append(subs, s)
+		}
+	}
+	if len(subs) == 0 {
+		return errors.New("no instantiated path matches the subscriptions")
+	}
+	subList.Subscription = subs
+	return nil
+}
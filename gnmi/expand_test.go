@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+// expandClient is a pb.GNMIClient answering the Gets limited to one level
+// of the interfaces list with JSON, of the network instances list with
+// keyed paths, and of the state of the interfaces with JSON.
+type expandClient struct {
+	pb.GNMIClient
+}
+
+func (c *expandClient) Get(ctx context.Context, req *pb.GetRequest,
+	opts ...grpc.CallOption) (*pb.GetResponse, error) {
+	p := req.Path[0]
+	if p.Target != "dev1" {
+		return nil, fmt.Errorf("unexpected target %q", p.Target)
+	}
+	jsonUpdate := func(s string) *pb.Update {
+		return &pb.Update{Path: &pb.Path{}, Val: &pb.TypedValue{
+			Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(s)}}}
+	}
+	notif := &pb.Notification{Prefix: p}
+	switch StrPath(p) {
+	case "/interfaces/interface":
+		notif.Update = []*pb.Update{jsonUpdate(`[
+			{"name": "Ethernet2", "openconfig-interfaces:config": {}},
+			{"openconfig-interfaces:name": "Ethernet1"}
+		]`)}
+	case "/network-instances/network-instance":
+		notif.Prefix = &pb.Path{}
+		for _, name := range []string{"default", "mgmt"} {
+			notif.Update = append(notif.Update, &pb.Update{
+				Path: &pb.Path{Elem: []*pb.PathElem{{Name: "network-instances"},
+					{Name: "network-instance", Key: map[string]string{"name": name}}}},
+				Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+					JsonIetfVal: []byte("{}")}}})
+		}
+	case "/interfaces/interface[name=Ethernet1]/state",
+		"/interfaces/interface[name=Ethernet2]/state":
+		notif.Update = []*pb.Update{jsonUpdate(`{"counters": {}, "oper-status": "UP"}`)}
+	default:
+		return nil, fmt.Errorf("unexpected Get of %s", StrPath(p))
+	}
+	return &pb.GetResponse{Notification: []*pb.Notification{notif}}, nil
+}
+
+func TestExpandWildcards(t *testing.T) {
+	for path, expected := range map[string][]string{
+		"/system/config": {"/system/config"},
+		"/interfaces/interface[name=*]/state/counters": {
+			"/interfaces/interface[name=Ethernet1]/state/counters",
+			"/interfaces/interface[name=Ethernet2]/state/counters",
+		},
+		"/interfaces/interface[name=Ethernet2]/state/*": {
+			"/interfaces/interface[name=Ethernet2]/state/counters",
+			"/interfaces/interface[name=Ethernet2]/state/oper-status",
+		},
+		"/interfaces/interface[name=*]/state/*": {
+			"/interfaces/interface[name=Ethernet1]/state/counters",
+			"/interfaces/interface[name=Ethernet1]/state/oper-status",
+			"/interfaces/interface[name=Ethernet2]/state/counters",
+			"/interfaces/interface[name=Ethernet2]/state/oper-status",
+		},
+		"/network-instances/network-instance[name=*]/protocols": {
+			"/network-instances/network-instance[name=default]/protocols",
+			"/network-instances/network-instance[name=mgmt]/protocols",
+		},
+	} {
+		p, err := ParseGNMIElements(SplitPath(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Target = "dev1"
+		expanded, err := ExpandWildcards(context.Background(), &expandClient{}, p)
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+			continue
+		}
+		var actual []string
+		for _, e := range expanded {
+			if e.Target != "dev1" {
+				t.Errorf("%s: unexpected target %q", path, e.Target)
+			}
+			actual = append(actual, StrPath(e))
+		}
+		if !test.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %q, got %q", path, expected, actual)
+		}
+	}
+
+	p, err := ParseGNMIElements(SplitPath("/interfaces/.../counters"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExpandWildcards(context.Background(), &expandClient{}, p); err == nil {
+		t.Error("expected an error expanding '...'")
+	}
+}
+
+func TestExpandSubscribeRequest(t *testing.T) {
+	req, err := NewSubscribeRequest(&SubscribeOptions{
+		Prefix:     "/interfaces",
+		Target:     "dev1",
+		StreamMode: "sample",
+		Paths: [][]string{
+			{"interface[name=*]", "state", "counters"},
+			{"interface[name=Ethernet1]", "config"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExpandSubscribeRequest(context.Background(), &expandClient{}, req); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, sub := range req.GetSubscribe().Subscription {
+		if sub.Mode != pb.SubscriptionMode_SAMPLE {
+			t.Errorf("unexpected mode %s", sub.Mode)
+		}
+		paths = append(paths, StrPath(sub.Path))
+	}
+	expected := []string{
+		"/interface[name=Ethernet1]/state/counters",
+		"/interface[name=Ethernet2]/state/counters",
+		"/interface[name=Ethernet1]/config",
+	}
+	if !test.DeepEqual(expected, paths) {
+		t.Errorf("expected %q, got %q", expected, paths)
+	}
+}
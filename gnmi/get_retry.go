@@ -0,0 +1,193 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetRetryOptions configures GetWithRetry.
+type GetRetryOptions struct {
+	// Timeout is the timeout of each Get. If zero, the Get of the whole
+	// request is given half of the time left before the deadline of the
+	// context, if any, to leave time for the Gets of the paths, which
+	// are only bound by the context.
+	Timeout time.Duration
+	// Retries is the number of times the Get of a path is retried after
+	// a retryable error.
+	Retries int
+	// Backoff is the time waited before a retry. A retry is not attempted
+	// if the deadline of the context would be reached by then.
+	Backoff time.Duration
+	// Retryable reports whether the Get of a path failing with err is
+	// retried. If nil, RetryableGetError is used.
+	Retryable func(err error) bool
+	// ExpandDepth is the number of levels a path still failing with a
+	// retryable error is expanded to: its children, discovered with
+	// Children, are fetched with their own Gets instead.
+	ExpandDepth int
+	// Children discovers the children of a path to expand. If nil,
+	// ShallowChildren is used.
+	Children ChildrenFunc
+	// Clock, used to wait for the backoff, defaults to RealClock.
+	Clock Clock
+}
+
+// RetryableGetError reports whether err is a timeout or a temporary
+// failure of the target, after which a Get is worth retrying.
+func RetryableGetError(err error) bool {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// GetPathError is the failure to get a path of a GetRequest.
+type GetPathError struct {
+	// Path is relative to the prefix of the GetRequest.
+	Path *pb.Path
+	Err  error
+}
+
+func (e *GetPathError) Error() string {
+	return fmt.Sprintf("%s: %s", StrPath(e.Path), e.Err)
+}
+
+func (e *GetPathError) Unwrap() error {
+	return e.Err
+}
+
+// GetErrors are the failures to get some paths of a GetRequest, returned
+// by GetWithRetry along with the notifications of the other paths.
+type GetErrors []*GetPathError
+
+func (e GetErrors) Error() string {
+	errs := make([]string, len(e))
+	for i, err := range e {
+		errs[i] = err.Error()
+	}
+	return fmt.Sprintf("failed to get %d paths: %s", len(e), strings.Join(errs, "; "))
+}
+
+// GetWithRetry sends req and, if it fails, gets its paths individually
+// so that the paths that can be fetched, e.g. in time, are not failed by
+// the others, such as big tables. The Get of a path is retried according
+// to opts, and expanded into its children if it still fails. The
+// notifications received are combined in the returned GetResponse, in the
+// order of the paths. If some paths could not be fetched, the returned
+// error is the GetErrors listing them, along with the partial GetResponse.
+func GetWithRetry(ctx context.Context, client pb.GNMIClient, req *pb.GetRequest,
+	opts GetRetryOptions) (*pb.GetResponse, error) {
+	if opts.Retryable == nil {
+		opts.Retryable = RetryableGetError
+	}
+	if opts.Children == nil {
+		opts.Children = ShallowChildren
+	}
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	timeout := opts.Timeout
+	if deadline, ok := ctx.Deadline(); ok && timeout == 0 && len(req.Path) > 1 {
+		timeout = time.Until(deadline) / 2
+	}
+	resp, err := getWithTimeout(ctx, client, req, timeout)
+	if err == nil {
+		return resp, nil
+	}
+	if len(req.Path) == 1 && !opts.Retryable(err) {
+		return nil, err
+	}
+	g := &getRetry{ctx: ctx, client: client, req: req, opts: opts}
+	resp = &pb.GetResponse{}
+	for _, path := range req.Path {
+		g.getPath(path, 0, resp)
+	}
+	if len(g.errs) > 0 {
+		return resp, g.errs
+	}
+	return resp, nil
+}
+
+type getRetry struct {
+	ctx    context.Context
+	client pb.GNMIClient
+	req    *pb.GetRequest
+	opts   GetRetryOptions
+	errs   GetErrors
+}
+
+// getPath adds the notifications of path, expanded down to depth, to
+// resp.
+func (g *getRetry) getPath(path *pb.Path, depth int, resp *pb.GetResponse) {
+	req := proto.Clone(g.req).(*pb.GetRequest)
+	req.Path = []*pb.Path{path}
+	var err error
+	for attempt := 0; ; attempt++ {
+		var pathResp *pb.GetResponse
+		pathResp, err = getWithTimeout(g.ctx, g.client, req, g.opts.Timeout)
+		if err == nil {
+			resp.Notification = append(resp.Notification, pathResp.Notification...)
+			return
+		}
+		if attempt >= g.opts.Retries || !g.opts.Retryable(err) || !g.wait() {
+			break
+		}
+	}
+	if depth < g.opts.ExpandDepth && g.opts.Retryable(err) && g.ctx.Err() == nil {
+		full := JoinPaths(g.req.Prefix, path)
+		full.Origin, full.Target = path.Origin, g.req.Prefix.GetTarget()
+		if full.Origin == "" {
+			full.Origin = g.req.Prefix.GetOrigin()
+		}
+		children, childrenErr := g.opts.Children(g.ctx, g.client, full)
+		if childrenErr == nil && len(children) > 0 {
+			for _, child := range children {
+				childPath := proto.Clone(path).(*pb.Path)
+				childPath.Elem = append(childPath.Elem, child)
+				g.getPath(childPath, depth+1, resp)
+			}
+			return
+		}
+	}
+	g.errs = append(g.errs, &GetPathError{Path: path, Err: err})
+}
+
+// wait waits for the backoff before a retry, and reports whether the
+// retry can still complete before the deadline of the context.
+func (g *getRetry) wait() bool {
+	if deadline, ok := g.ctx.Deadline(); ok &&
+		time.Until(deadline) <= g.opts.Backoff {
+		return false
+	}
+	if g.opts.Backoff <= 0 {
+		return g.ctx.Err() == nil
+	}
+	select {
+	case <-g.opts.Clock.After(g.opts.Backoff):
+		return true
+	case <-g.ctx.Done():
+		return false
+	}
+}
+
+func getWithTimeout(ctx context.Context, client pb.GNMIClient, req *pb.GetRequest,
+	timeout time.Duration) (*pb.GetResponse, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return client.Get(ctx, req)
+}
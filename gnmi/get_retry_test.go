@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryClient is a pb.GNMIClient failing the Gets of multiple paths and
+// the Gets of a path with its errs in turn, the last one repeating, a nil
+// error letting the Get succeed.
+type retryClient struct {
+	pb.GNMIClient
+	errs map[string][]error
+	gets []string
+}
+
+func (c *retryClient) Get(ctx context.Context, req *pb.GetRequest,
+	opts ...grpc.CallOption) (*pb.GetResponse, error) {
+	if len(req.Path) > 1 {
+		c.gets = append(c.gets, "all")
+		return nil, status.Error(codes.DeadlineExceeded, "timeout")
+	}
+	p := StrPath(req.Path[0])
+	c.gets = append(c.gets, p)
+	if errs := c.errs[p]; len(errs) > 0 {
+		if len(errs) > 1 {
+			c.errs[p] = errs[1:]
+		}
+		if errs[0] != nil {
+			return nil, errs[0]
+		}
+	}
+	return &pb.GetResponse{Notification: []*pb.Notification{{
+		Prefix: req.Prefix, Update: []*pb.Update{{Path: req.Path[0]}}}}}, nil
+}
+
+func TestGetWithRetry(t *testing.T) {
+	timeout := status.Error(codes.DeadlineExceeded, "timeout")
+	notFound := status.Error(codes.NotFound, "not found")
+	client := &retryClient{errs: map[string][]error{
+		"/b":   {timeout, nil},
+		"/c":   {timeout},
+		"/c/y": {notFound},
+		"/d":   {timeout},
+		"/e":   {notFound},
+	}}
+	var req pb.GetRequest
+	for _, p := range []string{"a", "b", "c", "d", "e"} {
+		path, err := ParseGNMIElements([]string{p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Path = append(req.Path, path)
+	}
+	req.Prefix = &pb.Path{Target: "dev1"}
+	children := func(ctx context.Context, client pb.GNMIClient,
+		path *pb.Path) ([]*pb.PathElem, error) {
+		if path.Target != "dev1" {
+			return nil, errors.New("missing target")
+		}
+		if StrPath(path) == "/c" {
+			return []*pb.PathElem{{Name: "x"}, {Name: "y"}}, nil
+		}
+		return nil, nil
+	}
+	resp, err := GetWithRetry(context.Background(), client, &req, GetRetryOptions{
+		Retries:     1,
+		ExpandDepth: 1,
+		Children:    children,
+	})
+
+	expectedGets := []string{"all", "/a", "/b", "/b", "/c", "/c", "/c/x", "/c/y", "/d", "/d",
+		"/e"}
+	if !test.DeepEqual(expectedGets, client.gets) {
+		t.Errorf("expected Gets %v, got %v", expectedGets, client.gets)
+	}
+	var paths []string
+	for _, notif := range resp.GetNotification() {
+		if notif.Prefix.GetTarget() != "dev1" {
+			t.Errorf("expected the prefix of the request, got %s", notif.Prefix)
+		}
+		paths = append(paths, StrPath(notif.Update[0].Path))
+	}
+	if expected := []string{"/a", "/b", "/c/x"}; !test.DeepEqual(expected, paths) {
+		t.Errorf("expected notifications for %v, got %v", expected, paths)
+	}
+	var getErrs GetErrors
+	if !errors.As(err, &getErrs) {
+		t.Fatalf("expected GetErrors, got %v", err)
+	}
+	expectedErrs := map[string]codes.Code{
+		"/c/y": codes.NotFound,
+		"/d":   codes.DeadlineExceeded,
+		"/e":   codes.NotFound,
+	}
+	if len(getErrs) != len(expectedErrs) {
+		t.Fatalf("expected %d errors, got %s", len(expectedErrs), err)
+	}
+	for _, pathErr := range getErrs {
+		if code := status.Code(pathErr); code != expectedErrs[StrPath(pathErr.Path)] {
+			t.Errorf("unexpected error %s", pathErr)
+		}
+	}
+
+	// A single path is not retried after an error that is not retryable.
+	client = &retryClient{errs: map[string][]error{"/e": {notFound}}}
+	resp, err = GetWithRetry(context.Background(), client,
+		&pb.GetRequest{Path: req.Path[4:]}, GetRetryOptions{Retries: 3})
+	if status.Code(err) != codes.NotFound || resp != nil {
+		t.Errorf("expected NotFound, got %v, %v", resp, err)
+	}
+	if expected := []string{"/e"}; !test.DeepEqual(expected, client.gets) {
+		t.Errorf("expected Gets %v, got %v", expected, client.gets)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/protobuf/proto"
+)
+
+// ChildrenFunc returns the child path elements of the node at path.
+type ChildrenFunc func(ctx context.Context, client pb.GNMIClient,
+	path *pb.Path) ([]*pb.PathElem, error)
+
+// GetSubtreeOptions configures GetSubtree.
+type GetSubtreeOptions struct {
+	// Depth is the number of levels below the root split into separate
+	// Gets, defaulting to 1: the root is never fetched as a whole, each
+	// of its children is fetched with its own Get.
+	Depth int
+	// Encoding of the GetRequests.
+	Encoding pb.Encoding
+	// Extensions are added to every GetRequest.
+	Extensions []*gnmi_ext.Extension
+	// Children discovers the children of a node. If nil, ShallowChildren
+	// is used. A schema-driven ChildrenFunc avoids the extra Gets.
+	Children ChildrenFunc
+}
+
+// GetSubtree fetches the subtree at root in chunks, to stay below the
+// message size limit of the target: down to opts.Depth levels below root,
+// the children of each node are discovered and fetched with their own Get.
+// The notifications received are sent to notifs, which is closed before
+// GetSubtree returns. The origin and target of root are used for all the
+// Gets.
+func GetSubtree(ctx context.Context, client pb.GNMIClient, root *pb.Path,
+	opts GetSubtreeOptions, notifs chan<- *pb.Notification) error {
+	defer close(notifs)
+	if opts.Depth <= 0 {
+		opts.Depth = 1
+	}
+	if opts.Children == nil {
+		opts.Children = ShallowChildren
+	}
+	return getSubtree(ctx, client, root, opts, 0, notifs)
+}
+
+func getSubtree(ctx context.Context, client pb.GNMIClient, path *pb.Path,
+	opts GetSubtreeOptions, level int, notifs chan<- *pb.Notification) error {
+	if level < opts.Depth {
+		children, err := opts.Children(ctx, client, path)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			for _, child := range children {
+				childPath := proto.Clone(path).(*pb.Path)
+				childPath.Elem = append(childPath.Elem, child)
+				if err := getSubtree(ctx, client, childPath, opts, level+1, notifs); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	resp, err := client.Get(ctx, &pb.GetRequest{
+		Path:      []*pb.Path{path},
+		Encoding:  opts.Encoding,
+		Extension: opts.Extensions,
+	})
+	if err != nil {
+		return err
+	}
+	for _, notif := range resp.Notification {
+		select {
+		case notifs <- notif:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// ShallowChildren is a ChildrenFunc discovering the children of path with
+// a Get limited to one level by the depth extension. The children are
+// the first element below path of the paths of the updates received, or
+// the members of the JSON objects received for path itself. Entries of
+// lists are only discovered from keyed paths, a JSON array is fetched as
+// a whole.
+func ShallowChildren(ctx context.Context, client pb.GNMIClient,
+	path *pb.Path) ([]*pb.PathElem, error) {
+	resp, err := client.Get(ctx, &pb.GetRequest{
+		Path:     []*pb.Path{path},
+		Encoding: pb.Encoding_JSON_IETF,
+		Extension: []*gnmi_ext.Extension{{
+			Ext: &gnmi_ext.Extension_Depth{Depth: &gnmi_ext.Depth{Level: 1}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	children := map[string]*pb.PathElem{}
+	add := func(elem *pb.PathElem) {
+		children[ElemToString(elem)] = elem
+	}
+	for _, notif := range resp.Notification {
+		prefix := notif.GetPrefix().GetElem()
+		for _, update := range notif.Update {
+			elems := append(prefix[:len(prefix):len(prefix)], update.GetPath().GetElem()...)
+			if len(elems) > len(path.Elem) {
+				add(elems[len(path.Elem)])
+				continue
+			}
+			for _, name := range jsonMembers(update.GetVal()) {
+				add(&pb.PathElem{Name: name})
+			}
+		}
+	}
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	elems := make([]*pb.PathElem, len(names))
+	for i, name := range names {
+		elems[i] = children[name]
+	}
+	return elems, nil
+}
+
+// jsonMembers returns the names of the members of a JSON object value,
+// without their module prefix.
+func jsonMembers(val *pb.TypedValue) []string {
+	var b []byte
+	switch v := val.GetValue().(type) {
+	case *pb.TypedValue_JsonIetfVal:
+		b = v.JsonIetfVal
+	case *pb.TypedValue_JsonVal:
+		b = v.JsonVal
+	default:
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, listName(name))
+	}
+	return names
+}
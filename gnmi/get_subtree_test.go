@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+// getClient is a pb.GNMIClient answering the shallow Gets of the root with
+// JSON and of /interfaces with keyed paths.
+type getClient struct {
+	pb.GNMIClient
+	gets []string
+}
+
+func (c *getClient) Get(ctx context.Context, req *pb.GetRequest,
+	opts ...grpc.CallOption) (*pb.GetResponse, error) {
+	p := req.Path[0]
+	shallow := req.Extension != nil && req.Extension[0].GetDepth().GetLevel() == 1
+	if shallow {
+		c.gets = append(c.gets, "shallow "+StrPath(p))
+	} else {
+		c.gets = append(c.gets, StrPath(p))
+	}
+	notif := &pb.Notification{Prefix: p}
+	switch {
+	case shallow && StrPath(p) == "/":
+		notif.Update = []*pb.Update{{Path: &pb.Path{}, Val: &pb.TypedValue{
+			Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(
+				`{"openconfig-interfaces:interfaces":{},"system":{}}`)}}}}
+	case shallow && StrPath(p) == "/interfaces":
+		notif.Prefix = &pb.Path{}
+		for _, name := range []string{"Ethernet2", "Ethernet1"} {
+			notif.Update = append(notif.Update, &pb.Update{
+				Path: &pb.Path{Elem: []*pb.PathElem{{Name: "interfaces"},
+					{Name: "interface", Key: map[string]string{"name": name}}}},
+				Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{
+					JsonIetfVal: []byte("{}")}}})
+		}
+	case shallow:
+	default:
+		notif.Update = []*pb.Update{{Path: &pb.Path{}, Val: &pb.TypedValue{
+			Value: &pb.TypedValue_StringVal{StringVal: "x"}}}}
+	}
+	return &pb.GetResponse{Notification: []*pb.Notification{notif}}, nil
+}
+
+func TestGetSubtree(t *testing.T) {
+	for name, tc := range map[string]struct {
+		root  string
+		depth int
+		gets  []string
+		paths []string
+	}{
+		"interfaces": {
+			root: "/interfaces",
+			gets: []string{
+				"shallow /interfaces",
+				"/interfaces/interface[name=Ethernet1]",
+				"/interfaces/interface[name=Ethernet2]",
+			},
+			paths: []string{
+				"/interfaces/interface[name=Ethernet1]",
+				"/interfaces/interface[name=Ethernet2]",
+			},
+		},
+		"root depth 2": {
+			root:  "/",
+			depth: 2,
+			gets: []string{
+				"shallow /",
+				"shallow /interfaces",
+				"/interfaces/interface[name=Ethernet1]",
+				"/interfaces/interface[name=Ethernet2]",
+				"shallow /system",
+				"/system",
+			},
+			paths: []string{
+				"/interfaces/interface[name=Ethernet1]",
+				"/interfaces/interface[name=Ethernet2]",
+				"/system",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			root, err := ParseGNMIElements(SplitPath(tc.root))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &getClient{}
+			notifs := make(chan *pb.Notification, 10)
+			if err := GetSubtree(context.Background(), client, root,
+				GetSubtreeOptions{Depth: tc.depth}, notifs); err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for notif := range notifs {
+				paths = append(paths, StrPath(notif.Prefix))
+			}
+			if !test.DeepEqual(tc.gets, client.gets) {
+				t.Errorf("unexpected Gets: %s", test.Diff(tc.gets, client.gets))
+			}
+			if !test.DeepEqual(tc.paths, paths) {
+				t.Errorf("unexpected notifications: %s", test.Diff(tc.paths, paths))
+			}
+		})
+	}
+}
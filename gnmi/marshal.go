@@ -0,0 +1,329 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+var (
+	typedValueType = reflect.TypeOf((*pb.TypedValue)(nil))
+	decimalType    = reflect.TypeOf((*pb.Decimal64)(nil))
+)
+
+// MarshalUpdates returns an Update for each leaf of v, a struct or map or a
+// pointer to one, with a path relative to v. It is the reverse of
+// ExtractValue for whole trees, letting test servers and agents publish
+// telemetry from Go values.
+//
+// The path element of a struct field is named by its gnmi tag, defaulting
+// to the field name, and the tag options control how the field is marshaled:
+//
+//	Name string            `gnmi:"name"`
+//	MTU  *uint16           `gnmi:"mtu,omitempty"`          // skipped if nil or zero
+//	Ifs  map[string]*Iface `gnmi:"interface,key=name"`     // list keyed by map key
+//	Subs []*Subintf        `gnmi:"subinterface,key=index"` // key read from entries
+//	Cfg  *Config           `gnmi:"config,json"`            // one JSON_IETF leaf
+//	Priv string            `gnmi:"-"`                      // not marshaled
+//
+// Embedded structs are inlined. The entries of a map without key option are
+// containers named by the map keys. Slices of leaves are leaf-lists and
+// nil pointers, maps, slices and interfaces are not marshaled. The leaves are
+// marshaled with TypedValue after conversion to their underlying type, and
+// *pb.TypedValue and *pb.Decimal64 values are used as is.
+func MarshalUpdates(v interface{}) ([]*pb.Update, error) {
+	var m marshaler
+	if err := m.marshal(nil, reflect.ValueOf(v), fieldOptions{}); err != nil {
+		return nil, err
+	}
+	return m.updates, nil
+}
+
+// MarshalNotification returns a Notification with prefix, timestamp and
+// the updates of MarshalUpdates(v).
+func MarshalNotification(prefix *pb.Path, timestamp time.Time,
+	v interface{}) (*pb.Notification, error) {
+	updates, err := MarshalUpdates(v)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Notification{
+		Timestamp: timestamp.UnixNano(),
+		Prefix:    prefix,
+		Update:    updates,
+	}, nil
+}
+
+type fieldOptions struct {
+	omitEmpty bool
+	json      bool
+	// key is the name of the key of the list.
+	key string
+}
+
+func parseFieldTag(f reflect.StructField) (string, fieldOptions, error) {
+	var opts fieldOptions
+	tag, ok := f.Tag.Lookup("gnmi")
+	if !ok {
+		return f.Name, opts, nil
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		switch {
+		case opt == "omitempty":
+			opts.omitEmpty = true
+		case opt == "json":
+			opts.json = true
+		case strings.HasPrefix(opt, "key="):
+			opts.key = strings.TrimPrefix(opt, "key=")
+		default:
+			return "", opts, fmt.Errorf("unknown gnmi tag option %q of field %s", opt, f.Name)
+		}
+	}
+	return name, opts, nil
+}
+
+type marshaler struct {
+	updates []*pb.Update
+}
+
+func (m *marshaler) add(path []*pb.PathElem, val *pb.TypedValue) {
+	m.updates = append(m.updates, &pb.Update{
+		Path: &pb.Path{Elem: append([]*pb.PathElem(nil), path...)},
+		Val:  val,
+	})
+}
+
+func (m *marshaler) marshal(path []*pb.PathElem, v reflect.Value, opts fieldOptions) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.Type() == typedValueType || v.Type() == decimalType {
+			break
+		}
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Map || v.Kind() == reflect.Slice ||
+		v.Kind() == reflect.Pointer) && v.IsNil() {
+		return nil
+	}
+	if opts.json {
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %s", elemsString(path), err)
+		}
+		m.add(path, &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: b}})
+		return nil
+	}
+	if val, ok, err := leafValue(v); err != nil {
+		return fmt.Errorf("failed to marshal %s: %s", elemsString(path), err)
+	} else if ok {
+		m.add(path, val)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return m.marshalStruct(path, v)
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			name := fmt.Sprint(k.Interface())
+			var entryPath []*pb.PathElem
+			if opts.key != "" {
+				entryPath = withKey(path, opts.key, name)
+			} else {
+				entryPath = append(path[:len(path):len(path)], &pb.PathElem{Name: name})
+			}
+			if err := m.marshal(entryPath, v.MapIndex(k), fieldOptions{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if opts.key == "" {
+			return fmt.Errorf("failed to marshal %s: list of %s without key option",
+				elemsString(path), v.Type().Elem())
+		}
+		for i := 0; i < v.Len(); i++ {
+			entry := v.Index(i)
+			keyVal, err := listKey(entry, opts.key)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %s", elemsString(path), err)
+			}
+			if err := m.marshal(withKey(path, opts.key, keyVal), entry,
+				fieldOptions{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("failed to marshal %s: unsupported type %s",
+			elemsString(path), v.Type())
+	}
+}
+
+func (m *marshaler) marshalStruct(path []*pb.PathElem, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Tag.Get("gnmi") == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && !hasTag(f) {
+			if err := m.marshal(path, fv, fieldOptions{}); err != nil {
+				return err
+			}
+			continue
+		}
+		name, opts, err := parseFieldTag(f)
+		if err != nil {
+			return err
+		}
+		if opts.omitEmpty && fv.IsZero() {
+			continue
+		}
+		fieldPath := append(path[:len(path):len(path)], &pb.PathElem{Name: name})
+		if err := m.marshal(fieldPath, fv, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasTag(f reflect.StructField) bool {
+	_, ok := f.Tag.Lookup("gnmi")
+	return ok
+}
+
+// withKey returns a copy of path whose last element has the key name set
+// to val.
+func withKey(path []*pb.PathElem, name, val string) []*pb.PathElem {
+	last := path[len(path)-1]
+	key := map[string]string{name: val}
+	for k, v := range last.Key {
+		key[k] = v
+	}
+	return append(path[:len(path)-1:len(path)-1], &pb.PathElem{Name: last.Name, Key: key})
+}
+
+// listKey returns the value of the field of entry named key.
+func listKey(entry reflect.Value, key string) (string, error) {
+	for entry.Kind() == reflect.Pointer || entry.Kind() == reflect.Interface {
+		if entry.IsNil() {
+			return "", fmt.Errorf("nil list entry")
+		}
+		entry = entry.Elem()
+	}
+	if entry.Kind() != reflect.Struct {
+		return "", fmt.Errorf("list entry of type %s has no key %q", entry.Type(), key)
+	}
+	t := entry.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, err := parseFieldTag(f)
+		if err != nil {
+			return "", err
+		}
+		if f.IsExported() && name == key {
+			v := entry.Field(i)
+			for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					return "", fmt.Errorf("list entry of type %s has a nil key %q", t, key)
+				}
+				v = v.Elem()
+			}
+			return fmt.Sprint(v.Interface()), nil
+		}
+	}
+	return "", fmt.Errorf("list entry of type %s has no key %q", t, key)
+}
+
+// leafValue returns the TypedValue of v if it is a leaf or a leaf-list.
+func leafValue(v reflect.Value) (*pb.TypedValue, bool, error) {
+	switch v.Type() {
+	case typedValueType:
+		return v.Interface().(*pb.TypedValue), true, nil
+	case decimalType:
+		return &pb.TypedValue{Value: &pb.TypedValue_DecimalVal{
+			DecimalVal: v.Interface().(*pb.Decimal64)}}, true, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return TypedValue(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return TypedValue(v.Int()), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypedValue(v.Uint()), true, nil
+	case reflect.Float32:
+		return TypedValue(float32(v.Float())), true, nil
+	case reflect.Float64:
+		return TypedValue(v.Float()), true, nil
+	case reflect.String:
+		return TypedValue(v.String()), true, nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return TypedValue(b), true, nil
+		}
+		if !isLeafType(v.Type().Elem()) {
+			return nil, false, nil
+		}
+		elems := make([]*pb.TypedValue, v.Len())
+		for i := range elems {
+			// The only leaf types that are pointers are *pb.TypedValue and
+			// *pb.Decimal64.
+			e := v.Index(i)
+			if e.Kind() == reflect.Pointer && e.IsNil() {
+				return nil, false, fmt.Errorf("nil leaf-list element %d", i)
+			}
+			val, _, err := leafValue(e)
+			if err != nil {
+				return nil, false, err
+			}
+			elems[i] = val
+		}
+		return &pb.TypedValue{Value: &pb.TypedValue_LeaflistVal{
+			LeaflistVal: &pb.ScalarArray{Element: elems}}}, true, nil
+	}
+	return nil, false, nil
+}
+
+func isLeafType(t reflect.Type) bool {
+	if t == typedValueType || t == decimalType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func elemsString(path []*pb.PathElem) string {
+	return StrPath(&pb.Path{Elem: path})
+}
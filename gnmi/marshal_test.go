@@ -0,0 +1,183 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+type marshalCounters struct {
+	InOctets  uint64 `gnmi:"in-octets"`
+	OutOctets uint64 `gnmi:"out-octets,omitempty"`
+}
+
+type marshalSubintf struct {
+	Index uint32 `gnmi:"index"`
+	VLAN  []uint16
+}
+
+type marshalState struct {
+	Enabled  bool             `gnmi:"enabled"`
+	MTU      *uint16          `gnmi:"mtu,omitempty"`
+	Counters *marshalCounters `gnmi:"counters"`
+}
+
+type marshalIntf struct {
+	Name  string `gnmi:"name"`
+	State marshalState
+	Subs  []*marshalSubintf `gnmi:"subinterface,key=index"`
+	Cfg   map[string]string `gnmi:"config,json"`
+	priv  int
+	Skip  string `gnmi:"-"`
+}
+
+func TestMarshalUpdates(t *testing.T) {
+	mtu := uint16(9214)
+	v := struct {
+		Interfaces map[string]*marshalIntf `gnmi:"interface,key=name"`
+		Hostname   string                  `gnmi:"hostname"`
+		Extra      map[string]interface{}
+	}{
+		Interfaces: map[string]*marshalIntf{
+			"Ethernet2": {
+				Name:  "Ethernet2",
+				State: marshalState{Counters: &marshalCounters{InOctets: 42}},
+				Skip:  "skipped",
+				priv:  1,
+			},
+			"Ethernet1": {
+				Name:  "Ethernet1",
+				State: marshalState{Enabled: true, MTU: &mtu},
+				Subs:  []*marshalSubintf{{Index: 1, VLAN: []uint16{10, 20}}},
+				Cfg:   map[string]string{"description": "uplink"},
+			},
+		},
+		Hostname: "switch1",
+		Extra:    map[string]interface{}{"decimal": &pb.Decimal64{Digits: 314, Precision: 2}},
+	}
+	updates, err := MarshalUpdates(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]*pb.TypedValue{
+		"/interface[name=Ethernet1]/name":                        TypedValue("Ethernet1"),
+		"/interface[name=Ethernet1]/State/enabled":               TypedValue(true),
+		"/interface[name=Ethernet1]/State/mtu":                   TypedValue(uint64(9214)),
+		"/interface[name=Ethernet1]/subinterface[index=1]/index": TypedValue(uint64(1)),
+		"/interface[name=Ethernet1]/subinterface[index=1]/VLAN": {
+			Value: &pb.TypedValue_LeaflistVal{LeaflistVal: &pb.ScalarArray{
+				Element: []*pb.TypedValue{TypedValue(uint64(10)), TypedValue(uint64(20))}}}},
+		"/interface[name=Ethernet1]/config": {
+			Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{"description":"uplink"}`)}},
+		"/interface[name=Ethernet2]/name":                     TypedValue("Ethernet2"),
+		"/interface[name=Ethernet2]/State/enabled":            TypedValue(false),
+		"/interface[name=Ethernet2]/State/counters/in-octets": TypedValue(uint64(42)),
+		"/hostname": TypedValue("switch1"),
+		"/Extra/decimal": {Value: &pb.TypedValue_DecimalVal{
+			DecimalVal: &pb.Decimal64{Digits: 314, Precision: 2}}},
+	}
+	got := map[string]*pb.TypedValue{}
+	var order []string
+	for _, u := range updates {
+		p := StrPath(u.Path)
+		got[p] = u.Val
+		order = append(order, p)
+	}
+	if !test.DeepEqual(expected, got) {
+		t.Errorf("unexpected updates: %s", test.Diff(expected, got))
+	}
+	// Map entries are marshaled in the order of their keys.
+	if order[0] != "/interface[name=Ethernet1]/name" || order[len(order)-2] != "/hostname" {
+		t.Errorf("unexpected order of updates: %v", order)
+	}
+}
+
+func TestMarshalNotification(t *testing.T) {
+	prefix := &pb.Path{Elem: []*pb.PathElem{{Name: "system"}}}
+	ts := time.Unix(1, 2)
+	notif, err := MarshalNotification(prefix, ts, struct {
+		Hostname string `gnmi:"hostname"`
+	}{Hostname: "switch1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &pb.Notification{
+		Timestamp: ts.UnixNano(),
+		Prefix:    prefix,
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "hostname"}}},
+			Val:  TypedValue("switch1"),
+		}},
+	}
+	if !test.DeepEqual(expected, notif) {
+		t.Errorf("unexpected notification: %s", test.Diff(expected, notif))
+	}
+}
+
+func TestMarshalUpdatesErrors(t *testing.T) {
+	for name, v := range map[string]interface{}{
+		"unsupported type": struct{ C chan int }{C: make(chan int)},
+		"list without key": struct{ L []marshalCounters }{L: []marshalCounters{{}}},
+		"missing key": struct {
+			L []marshalCounters `gnmi:"l,key=name"`
+		}{L: []marshalCounters{{}}},
+		"unknown option": struct {
+			A int `gnmi:"a,bogus"`
+		}{},
+		"nil key": struct {
+			L []struct {
+				Name *string `gnmi:"name"`
+			} `gnmi:"l,key=name"`
+		}{L: []struct {
+			Name *string `gnmi:"name"`
+		}{{}}},
+		"nil leaf-list element": struct {
+			L []*pb.TypedValue
+		}{L: []*pb.TypedValue{TypedValue(1), nil}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := MarshalUpdates(v); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestMarshalUpdatesNilValues(t *testing.T) {
+	name := "Ethernet1"
+	type entry struct {
+		Name *string        `gnmi:"name"`
+		Val  *pb.TypedValue `gnmi:"val"`
+		Dec  *pb.Decimal64  `gnmi:"dec"`
+	}
+	updates, err := MarshalUpdates(struct {
+		L  []entry `gnmi:"l,key=name"`
+		LL []*pb.TypedValue
+	}{
+		L:  []entry{{Name: &name}},
+		LL: []*pb.TypedValue{TypedValue(1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The nil values are not marshaled.
+	expected := map[string]*pb.TypedValue{
+		"/l[name=Ethernet1]/name": TypedValue("Ethernet1"),
+		"/LL": {Value: &pb.TypedValue_LeaflistVal{LeaflistVal: &pb.ScalarArray{
+			Element: []*pb.TypedValue{TypedValue(1)}}}},
+	}
+	got := map[string]*pb.TypedValue{}
+	for _, u := range updates {
+		got[StrPath(u.Path)] = u.Val
+	}
+	if !test.DeepEqual(expected, got) {
+		t.Errorf("unexpected updates: %s", test.Diff(expected, got))
+	}
+}
@@ -60,6 +60,13 @@ func Capabilities(ctx context.Context, client pb.GNMIClient) error {
 	if err != nil {
 		return err
 	}
+	PrintCapabilities(resp)
+	return nil
+}
+
+// PrintCapabilities prints the version, supported models and supported
+// encodings of resp.
+func PrintCapabilities(resp *pb.CapabilityResponse) {
 	fmt.Printf("Version: %s\n", resp.GNMIVersion)
 	for _, mod := range resp.SupportedModels {
 		fmt.Printf("SupportedModel: %s\n", mod)
@@ -67,14 +74,112 @@ func Capabilities(ctx context.Context, client pb.GNMIClient) error {
 	for _, enc := range resp.SupportedEncodings {
 		fmt.Printf("SupportedEncoding: %s\n", enc)
 	}
-	return nil
+}
+
+// FilterCapabilities returns a copy of resp with only the supported models
+// whose names match one of the shell patterns models (see path.Match) and
+// the supported encodings among encodings. An empty models or encodings
+// keeps all the models or encodings. The error names the patterns matching
+// no supported model and the encodings not supported, if any.
+func FilterCapabilities(resp *pb.CapabilityResponse, models []string,
+	encodings []pb.Encoding) (*pb.CapabilityResponse, error) {
+	filtered := &pb.CapabilityResponse{
+		GNMIVersion:        resp.GNMIVersion,
+		SupportedModels:    resp.SupportedModels,
+		SupportedEncodings: resp.SupportedEncodings,
+		Extension:          resp.Extension,
+	}
+	var missing []string
+	if len(models) > 0 {
+		filtered.SupportedModels = nil
+		matched := make([]bool, len(models))
+		for _, mod := range resp.SupportedModels {
+			var match bool
+			for i, pattern := range models {
+				if ok, _ := path.Match(pattern, mod.Name); ok {
+					matched[i], match = true, true
+				}
+			}
+			if match {
+				filtered.SupportedModels = append(filtered.SupportedModels, mod)
+			}
+		}
+		for i, pattern := range models {
+			if !matched[i] {
+				missing = append(missing, "model "+pattern)
+			}
+		}
+	}
+	if len(encodings) > 0 {
+		filtered.SupportedEncodings = nil
+		for _, enc := range encodings {
+			var supported bool
+			for _, s := range resp.SupportedEncodings {
+				supported = supported || s == enc
+			}
+			if supported {
+				filtered.SupportedEncodings = append(filtered.SupportedEncodings, enc)
+			} else {
+				missing = append(missing, "encoding "+enc.String())
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return filtered, fmt.Errorf("not supported: %s", strings.Join(missing, ", "))
+	}
+	return filtered, nil
+}
+
+// DefaultEncodings is the order of preference of the encodings used by
+// NegotiateEncoding when none are given.
+var DefaultEncodings = []pb.Encoding{
+	pb.Encoding_JSON_IETF,
+	pb.Encoding_JSON,
+	pb.Encoding_PROTO,
+}
+
+// NegotiateEncoding fetches the capabilities of the target and returns the
+// first of the preferred encodings it supports. If no preferred encodings are
+// given, DefaultEncodings is used.
+func NegotiateEncoding(ctx context.Context, client pb.GNMIClient,
+	preferred ...pb.Encoding) (pb.Encoding, error) {
+	resp, err := client.Capabilities(ctx, &pb.CapabilityRequest{})
+	if err != nil {
+		return 0, err
+	}
+	return SelectEncoding(resp.SupportedEncodings, preferred...)
+}
+
+// SelectEncoding returns the first of the preferred encodings that is in
+// supported. If no preferred encodings are given, DefaultEncodings is used.
+// If supported is empty, the target did not advertise its encodings and the
+// first preferred encoding is returned.
+func SelectEncoding(supported []pb.Encoding, preferred ...pb.Encoding) (pb.Encoding, error) {
+	if len(preferred) == 0 {
+		preferred = DefaultEncodings
+	}
+	if len(supported) == 0 {
+		return preferred[0], nil
+	}
+	for _, p := range preferred {
+		for _, s := range supported {
+			if p == s {
+				return p, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("target supports none of the encodings %v, supported encodings: %v",
+		preferred, supported)
 }
 
 // val may be a path to a file or it may be json. First see if it is a
-// file, if so return its contents, otherwise return val
+// file, if so return its contents, otherwise return val. A value spanning
+// several lines, like CLI commands, is never a file.
 func extractContent(val string, origin string) []byte {
-	if jsonBytes, err := ioutil.ReadFile(val); err == nil {
-		return jsonBytes
+	if !strings.Contains(val, "\n") {
+		if jsonBytes, err := ioutil.ReadFile(val); err == nil {
+			return jsonBytes
+		}
 	}
 	// for CLI commands we don't need to add the outer quotes
 	if origin == "cli" {
@@ -316,6 +421,9 @@ func TypedValue(val interface{}) *pb.TypedValue {
 //	*any.Any
 //	[]interface{}
 //	map[string]interface{}
+//
+// Any values are decoded with the AnyDecoder registered for their type URL
+// with RegisterAnyDecoder or RegisterAnyMessage, if any.
 func ExtractValue(update *pb.Update) (interface{}, error) {
 	var i interface{}
 	var err error
@@ -360,7 +468,7 @@ func extractValueV04(val *pb.TypedValue) (interface{}, error) {
 		}
 		return l, nil
 	case *pb.TypedValue_AnyVal:
-		return v.AnyVal, nil
+		return decodeAny(v.AnyVal)
 	case *pb.TypedValue_JsonVal:
 		return decode(v.JsonVal)
 	case *pb.TypedValue_JsonIetfVal:
@@ -409,7 +517,7 @@ func update(p *pb.Path, val string) (*pb.Update, error) {
 			Value: &pb.TypedValue_JsonVal{JsonVal: extractContent(val, p.Origin)}}
 	case "cli", "test-regen-cli":
 		v = &pb.TypedValue{
-			Value: &pb.TypedValue_AsciiVal{AsciiVal: string(extractContent(val, p.Origin))}}
+			Value: &pb.TypedValue_AsciiVal{AsciiVal: cliCommands(extractContent(val, p.Origin))}}
 	case "p4_config":
 		b, err := ioutil.ReadFile(val)
 		if err != nil {
@@ -424,6 +532,13 @@ func update(p *pb.Path, val string) (*pb.Update, error) {
 	return &pb.Update{Path: p, Val: v}, nil
 }
 
+// cliCommands returns the CLI commands of b, one per line, converting the
+// CRLF line endings of files edited on Windows that the CLI would see as
+// part of the commands.
+func cliCommands(b []byte) string {
+	return strings.ReplaceAll(string(b), "\r\n", "\n")
+}
+
 // Operation describes an gNMI operation.
 type Operation struct {
 	Type   string
@@ -513,12 +628,21 @@ func SubscribeErr(ctx context.Context, client pb.GNMIClient, subscribeOptions *S
 	if err != nil {
 		return err
 	}
-	return SubscribeWithRequest(ctx, client, req, respChan)
+	if subscribeOptions.Reconnect != nil {
+		return subscribeWithReconnect(ctx, client, req, respChan, *subscribeOptions.Reconnect,
+			subscribeOptions.UnbundleDepth)
+	}
+	return subscribeWithRequest(ctx, client, req, respChan, subscribeOptions.UnbundleDepth)
 }
 
 // SubscribeWithRequest calls gNMI.Subscribe with the SubscribeRequest.
 func SubscribeWithRequest(ctx context.Context, client pb.GNMIClient, req *pb.SubscribeRequest,
 	respChan chan<- *pb.SubscribeResponse) error {
+	return subscribeWithRequest(ctx, client, req, respChan, 0)
+}
+
+func subscribeWithRequest(ctx context.Context, client pb.GNMIClient, req *pb.SubscribeRequest,
+	respChan chan<- *pb.SubscribeResponse, unbundleDepth int) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	defer close(respChan)
@@ -546,10 +670,12 @@ func SubscribeWithRequest(ctx context.Context, client pb.GNMIClient, req *pb.Sub
 			return err
 		}
 
-		select {
-		case respChan <- resp:
-		case <-ctx.Done():
-			return ctx.Err()
+		for _, resp := range UnbundleSubscribeResponse(resp, unbundleDepth) {
+			select {
+			case respChan <- resp:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 
 		// For POLL subscriptions, initiate a poll request by pressing ENTER
@@ -103,6 +103,28 @@ hostname new`,
 				}},
 			},
 		},
+		"cli-union_replace CRLF": {
+			setOps: []*Operation{{Type: "union_replace", Origin: "cli",
+				Val: "hostname foo\r\ninterface Ethernet1\r\n   description uplink\r\n"}},
+			exp: &pb.SetRequest{
+				UnionReplace: []*pb.Update{{
+					Path: pathCli,
+					Val: &pb.TypedValue{Value: &pb.TypedValue_AsciiVal{
+						AsciiVal: "hostname foo\ninterface Ethernet1\n   description uplink\n"}},
+				}},
+			},
+		},
+		"cli multi-line named like a file": {
+			setOps: []*Operation{{Type: "update", Origin: "cli",
+				Val: fileNames[1] + "\n"}},
+			exp: &pb.SetRequest{
+				Update: []*pb.Update{{
+					Path: pathCli,
+					Val: &pb.TypedValue{
+						Value: &pb.TypedValue_AsciiVal{AsciiVal: fileNames[1] + "\n"}},
+				}},
+			},
+		},
 		"p4_config": {
 			setOps: []*Operation{{Type: "replace", Origin: "p4_config",
 				Val: fileNames[0]}},
@@ -543,3 +565,95 @@ func TestExtractValue(t *testing.T) {
 		}
 	}
 }
+
+func TestSelectEncoding(t *testing.T) {
+	for name, tc := range map[string]struct {
+		supported []pb.Encoding
+		preferred []pb.Encoding
+		exp       pb.Encoding
+		err       bool
+	}{
+		"default preference": {
+			supported: []pb.Encoding{pb.Encoding_JSON, pb.Encoding_JSON_IETF},
+			exp:       pb.Encoding_JSON_IETF,
+		},
+		"fallback to JSON": {
+			supported: []pb.Encoding{pb.Encoding_ASCII, pb.Encoding_JSON},
+			exp:       pb.Encoding_JSON,
+		},
+		"fallback to PROTO": {
+			supported: []pb.Encoding{pb.Encoding_PROTO},
+			exp:       pb.Encoding_PROTO,
+		},
+		"explicit preference": {
+			supported: []pb.Encoding{pb.Encoding_JSON, pb.Encoding_ASCII},
+			preferred: []pb.Encoding{pb.Encoding_ASCII, pb.Encoding_JSON},
+			exp:       pb.Encoding_ASCII,
+		},
+		"nothing advertised": {
+			exp: pb.Encoding_JSON_IETF,
+		},
+		"no match": {
+			supported: []pb.Encoding{pb.Encoding_ASCII},
+			err:       true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := SelectEncoding(tc.supported, tc.preferred...)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.exp {
+				t.Errorf("expected %s, got %s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestFilterCapabilities(t *testing.T) {
+	interfaces := &pb.ModelData{Name: "openconfig-interfaces",
+		Organization: "OpenConfig working group", Version: "3.0.0"}
+	bgp := &pb.ModelData{Name: "openconfig-bgp",
+		Organization: "OpenConfig working group", Version: "9.1.0"}
+	arista := &pb.ModelData{Name: "arista-intf-augments", Organization: "Arista Networks"}
+	resp := &pb.CapabilityResponse{
+		GNMIVersion:        "0.7.0",
+		SupportedModels:    []*pb.ModelData{interfaces, bgp, arista},
+		SupportedEncodings: []pb.Encoding{pb.Encoding_JSON, pb.Encoding_JSON_IETF},
+	}
+
+	filtered, err := FilterCapabilities(resp, nil, nil)
+	if err != nil || !proto.Equal(resp, filtered) {
+		t.Errorf("expected all the capabilities, got %s, %v", filtered, err)
+	}
+
+	filtered, err = FilterCapabilities(resp, []string{"openconfig-*", "arista-intf-augments"},
+		[]pb.Encoding{pb.Encoding_JSON_IETF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &pb.CapabilityResponse{
+		GNMIVersion:        "0.7.0",
+		SupportedModels:    []*pb.ModelData{interfaces, bgp, arista},
+		SupportedEncodings: []pb.Encoding{pb.Encoding_JSON_IETF},
+	}
+	if !proto.Equal(exp, filtered) {
+		t.Errorf("expected %s, got %s", exp, filtered)
+	}
+
+	filtered, err = FilterCapabilities(resp, []string{"openconfig-bgp", "openconfig-isis"},
+		[]pb.Encoding{pb.Encoding_PROTO})
+	if err == nil || err.Error() != "not supported: model openconfig-isis, encoding PROTO" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(filtered.SupportedModels) != 1 || filtered.SupportedModels[0] != bgp ||
+		len(filtered.SupportedEncodings) != 0 {
+		t.Errorf("unexpected filtered capabilities %s", filtered)
+	}
+}
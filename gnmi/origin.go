@@ -0,0 +1,55 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import "strings"
+
+// DefaultOriginPrefixes maps the roots of the EOS native paths to their
+// origin, for InferOrigin. The paths of the default openconfig origin need
+// no entry.
+var DefaultOriginPrefixes = map[string]string{
+	"/Sysdb":  "eos_native",
+	"/Smash":  "eos_native",
+	"/Kernel": "eos_native",
+}
+
+// InferOrigin returns the origin of the longest of prefixes that path is
+// under, and whether there is one. The keys of prefixes are paths matching
+// whole path elements: "/Sysdb" matches "/Sysdb/interface" but not
+// "/SysdbFoo". Prefix elements without keys match the elements with any
+// keys: "/interfaces/interface" matches "/interfaces/interface[name=Et1]".
+// An empty origin excludes a subtree of a shorter prefix, and "/" sets the
+// origin of the paths under no other prefix.
+func InferOrigin(prefixes map[string]string, path string) (string, bool) {
+	elems := SplitPath(path)
+	var origin string
+	longest := -1
+	for prefix, o := range prefixes {
+		prefixElems := SplitPath(prefix)
+		if len(prefixElems) <= longest || !hasElemsPrefix(elems, prefixElems) {
+			continue
+		}
+		origin, longest = o, len(prefixElems)
+	}
+	return origin, longest >= 0
+}
+
+func hasElemsPrefix(elems, prefix []string) bool {
+	if len(prefix) > len(elems) {
+		return false
+	}
+	for i, e := range prefix {
+		elem := elems[i]
+		if !strings.ContainsRune(e, '[') {
+			if j := strings.IndexByte(elem, '['); j >= 0 {
+				elem = elem[:j]
+			}
+		}
+		if elem != e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import "testing"
+
+func TestInferOrigin(t *testing.T) {
+	prefixes := map[string]string{
+		"/Sysdb":                "eos_native",
+		"/Sysdb/cell/1/agent":   "",
+		"/network-instances/x":  "openconfig",
+		"/interfaces/interface": "openconfig",
+	}
+	for path, expected := range map[string]struct {
+		origin string
+		ok     bool
+	}{
+		"/Sysdb":                        {"eos_native", true},
+		"Sysdb/interface/status":        {"eos_native", true},
+		"/SysdbFoo":                     {"", false},
+		"/Sysdb/cell/1/agent/Bgp":       {"", true},
+		"/interfaces/interface[name=x]": {"openconfig", true},
+		"/interfaces/interface/state":   {"openconfig", true},
+		"/":                             {"", false},
+		"":                              {"", false},
+	} {
+		origin, ok := InferOrigin(prefixes, path)
+		if origin != expected.origin || ok != expected.ok {
+			t.Errorf("InferOrigin(%q): expected %q, %t, got %q, %t",
+				path, expected.origin, expected.ok, origin, ok)
+		}
+	}
+	if origin, _ := InferOrigin(DefaultOriginPrefixes, "/Smash/routing"); origin != "eos_native" {
+		t.Errorf("expected the eos_native origin for /Smash, got %q", origin)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+// PathStats holds the statistics of the updates received for one path.
+type PathStats struct {
+	// Path is the path of the updates, prefixed with its origin if any.
+	Path string
+	// Updates and Deletes are the number of updates and deletes received.
+	Updates uint64
+	Deletes uint64
+	// Bytes is the size of the updates and deletes received, as encoded
+	// in the notifications.
+	Bytes uint64
+	// First and Last are the receive times of the first and last update.
+	First time.Time
+	Last  time.Time
+	// LastTimestamp is the timestamp of the notification of the last update.
+	LastTimestamp time.Time
+}
+
+// Rate returns the number of updates and deletes per second received
+// between the first update and now.
+func (s PathStats) Rate(now time.Time) float64 {
+	elapsed := now.Sub(s.First).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Updates+s.Deletes) / elapsed
+}
+
+// PathStatsCollector collects the PathStats of the paths of the
+// notifications of a subscription, to find which paths dominate the
+// bandwidth of a target. It is safe for concurrent use.
+type PathStatsCollector struct {
+	clock Clock
+	mu    sync.Mutex
+	paths map[string]*PathStats
+}
+
+// NewPathStatsCollector returns a PathStatsCollector using clock as the
+// source of the receive times, or RealClock if nil.
+func NewPathStatsCollector(clock Clock) *PathStatsCollector {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &PathStatsCollector{clock: clock, paths: map[string]*PathStats{}}
+}
+
+// Update records the updates and deletes of the notification of resp.
+func (c *PathStatsCollector) Update(resp *pb.SubscribeResponse) {
+	if notif := resp.GetUpdate(); notif != nil {
+		c.Notification(notif)
+	}
+}
+
+// Notification records the updates and deletes of notif.
+func (c *PathStatsCollector) Notification(notif *pb.Notification) {
+	now := c.clock.Now()
+	ts := time.Unix(0, notif.Timestamp)
+	prefix := StrPath(notif.Prefix)
+	if origin := notif.Prefix.GetOrigin(); origin != "" {
+		prefix = origin + ":" + prefix
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, u := range notif.Update {
+		s := c.get(path.Join(prefix, StrPath(u.Path)), now)
+		s.Updates++
+		s.Bytes += uint64(proto.Size(u))
+		s.LastTimestamp = ts
+	}
+	for _, p := range notif.Delete {
+		s := c.get(path.Join(prefix, StrPath(p)), now)
+		s.Deletes++
+		s.Bytes += uint64(proto.Size(p))
+		s.LastTimestamp = ts
+	}
+}
+
+func (c *PathStatsCollector) get(p string, now time.Time) *PathStats {
+	s, ok := c.paths[p]
+	if !ok {
+		s = &PathStats{Path: p, First: now}
+		c.paths[p] = s
+	}
+	s.Last = now
+	return s
+}
+
+// Snapshot returns a copy of the statistics collected so far, sorted by
+// decreasing number of bytes.
+func (c *PathStatsCollector) Snapshot() []PathStats {
+	c.mu.Lock()
+	snap := make([]PathStats, 0, len(c.paths))
+	for _, s := range c.paths {
+		snap = append(snap, *s)
+	}
+	c.mu.Unlock()
+	sort.Slice(snap, func(i, j int) bool {
+		if snap[i].Bytes != snap[j].Bytes {
+			return snap[i].Bytes > snap[j].Bytes
+		}
+		return snap[i].Path < snap[j].Path
+	})
+	return snap
+}
+
+// WriteTo writes the statistics collected so far to w as a table, one
+// row per path sorted by decreasing number of bytes.
+func (c *PathStatsCollector) WriteTo(w io.Writer) (int64, error) {
+	now := c.clock.Now()
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "UPDATES\tDELETES\tBYTES\tUPDATES/S\tLAST TIMESTAMP\tPATH\n")
+	for _, s := range c.Snapshot() {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%.2f\t%s\t%s\n", s.Updates, s.Deletes, s.Bytes,
+			s.Rate(now), s.LastTimestamp.UTC().Format(time.RFC3339Nano), s.Path)
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPathStatsCollector(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	c := NewPathStatsCollector(clock)
+	mustParse := func(s string) *pb.Path {
+		t.Helper()
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	description := &pb.Update{Path: mustParse("state/description"),
+		Val: TypedValue("uplink to the spine switches")}
+	notif := func(ts int64) *pb.SubscribeResponse {
+		prefix := mustParse("/interfaces/interface[name=Ethernet1]")
+		prefix.Origin = "openconfig"
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{
+			Update: &pb.Notification{
+				Timestamp: ts,
+				Prefix:    prefix,
+				Update: []*pb.Update{description,
+					{Path: mustParse("state/mtu"), Val: TypedValue(uint64(9214))}},
+			}}}
+	}
+	c.Update(notif(1))
+	clock.Advance(2 * time.Second)
+	c.Update(notif(2))
+	c.Update(&pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{
+		Update: &pb.Notification{Timestamp: 3, Delete: []*pb.Path{mustParse("/system")}}}})
+	c.Update(&pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{}})
+
+	snap := c.Snapshot()
+	if len(snap) != 3 {
+		t.Fatalf("expected 3 paths, got %v", snap)
+	}
+	s := snap[0]
+	if s.Path != "openconfig:/interfaces/interface[name=Ethernet1]/state/description" ||
+		s.Updates != 2 || s.Bytes != 2*uint64(proto.Size(description)) ||
+		!s.First.Equal(time.Unix(1000, 0)) || !s.Last.Equal(time.Unix(1002, 0)) ||
+		!s.LastTimestamp.Equal(time.Unix(0, 2)) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if rate := s.Rate(clock.Now()); rate != 1 {
+		t.Errorf("expected 1 update/s, got %f", rate)
+	}
+	if s := snap[2]; s.Path != "/system" || s.Deletes != 1 || s.Updates != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	var b strings.Builder
+	n, err := c.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if int(n) != b.Len() || len(lines) != 4 || !strings.HasPrefix(lines[0], "UPDATES") ||
+		!strings.HasSuffix(lines[1], "/state/description") {
+		t.Errorf("unexpected table:\n%s", b.String())
+	}
+}
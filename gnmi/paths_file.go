@@ -0,0 +1,146 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PathsFileEntry is a path of a paths file.
+type PathsFileEntry struct {
+	// Origin of the path, empty if the line has none.
+	Origin string
+	// Path is the path, without its origin.
+	Path string
+	// Interval is the sample interval of the path, zero if the line has
+	// none.
+	Interval time.Duration
+}
+
+func (e PathsFileEntry) String() string {
+	s := e.Path
+	if e.Origin != "" {
+		s = e.Origin + ":" + s
+	}
+	if e.Interval > 0 {
+		s += "@" + e.Interval.String()
+	}
+	return s
+}
+
+// ReadPathsFile reads the paths of the paths file at name. Each line of
+// the file holds a path, optionally prefixed by its origin and followed
+// by a sample interval, as in "eos_native:/Sysdb/hardware@30s". Blank
+// lines and comments, starting with # at the beginning of a line or after
+// a space, are ignored, and a line "include FILE" reads the paths of
+// FILE, relative to the directory of the including file.
+func ReadPathsFile(name string) ([]PathsFileEntry, error) {
+	return readPathsFile(name, nil)
+}
+
+func readPathsFile(name string, including []string) ([]PathsFileEntry, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range including {
+		if f == abs {
+			return nil, fmt.Errorf("paths file %q includes itself", name)
+		}
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read paths file: %s", err)
+	}
+	defer f.Close()
+	including = append(including, abs)
+	return parsePathsFile(f, name, func(include string) ([]PathsFileEntry, error) {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(name), include)
+		}
+		return readPathsFile(include, including)
+	})
+}
+
+// parsePathsFile parses the paths file r named name, calling include to
+// read the paths of the included files.
+func parsePathsFile(r io.Reader, name string,
+	include func(string) ([]PathsFileEntry, error)) ([]PathsFileEntry, error) {
+	var entries []PathsFileEntry
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := stripComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if fields := strings.Fields(line); fields[0] == "include" {
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: expected include FILE, got %q", name, n, line)
+			}
+			included, err := include(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", name, n, err)
+			}
+			entries = append(entries, included...)
+			continue
+		}
+		entry, err := parsePathsFileEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", name, n, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths file %q: %s", name, err)
+	}
+	return entries, nil
+}
+
+// stripComment returns line without its comment and surrounding spaces.
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			line = line[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// parsePathsFileEntry parses a line [ORIGIN:]PATH[@INTERVAL].
+func parsePathsFileEntry(line string) (PathsFileEntry, error) {
+	var e PathsFileEntry
+	if strings.ContainsAny(line, " \t") {
+		return e, fmt.Errorf("unexpected space in path %q", line)
+	}
+	// The interval follows the last @ after the list keys of the path.
+	if i := strings.LastIndexByte(line, '@'); i > strings.LastIndexByte(line, ']') {
+		interval, err := time.ParseDuration(line[i+1:])
+		if err != nil {
+			return e, fmt.Errorf("invalid interval in %q: %s", line, err)
+		}
+		if interval <= 0 {
+			return e, fmt.Errorf("interval of %q must be positive", line)
+		}
+		e.Interval = interval
+		line = line[:i]
+	}
+	// The origin is before the first element of the path.
+	if i := strings.IndexByte(line, ':'); i > 0 && !strings.ContainsAny(line[:i], "/[") {
+		e.Origin = line[:i]
+		line = line[i+1:]
+	}
+	if line == "" {
+		return e, fmt.Errorf("empty path")
+	}
+	e.Path = line
+	return e, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadPathsFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	write("sub/system.txt", `
+/system/state/hostname
+`)
+	main := write("paths.txt", `# Interface counters.
+/interfaces/interface[name=Ethernet1]/state/counters@30s
+
+eos_native:/Sysdb/hardware   # on change
+	include sub/system.txt
+/a[k=x@y]
+/b#c
+`)
+	entries, err := ReadPathsFile(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PathsFileEntry{
+		{Path: "/interfaces/interface[name=Ethernet1]/state/counters",
+			Interval: 30 * time.Second},
+		{Origin: "eos_native", Path: "/Sysdb/hardware"},
+		{Path: "/system/state/hostname"},
+		{Path: "/a[k=x@y]"},
+		{Path: "/b#c"},
+	}
+	if !reflect.DeepEqual(expected, entries) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+	if s := entries[0].String(); s != "/interfaces/interface[name=Ethernet1]/state/counters@30s" {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	errors := map[string]struct {
+		content string
+		err     string
+	}{
+		"interval.txt":  {content: "/a@1x", err: "interval.txt:1: invalid interval"},
+		"negative.txt":  {content: "\n/a@-1s", err: "negative.txt:2: interval"},
+		"space.txt":     {content: "/a /b", err: "unexpected space"},
+		"include.txt":   {content: "include", err: "expected include FILE"},
+		"missing.txt":   {content: "include nope.txt", err: "failed to read paths file"},
+		"cycle.txt":     {content: "include cycle2.txt", err: "includes itself"},
+		"cycle2.txt":    {content: "include cycle.txt", err: "includes itself"},
+		"emptypath.txt": {content: "origin:", err: "empty path"},
+	}
+	for name, tc := range errors {
+		write(name, tc.content)
+	}
+	for name, tc := range errors {
+		_, err := ReadPathsFile(filepath.Join(dir, name))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", name, tc.err, err)
+		}
+	}
+}
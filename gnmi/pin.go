@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// SPKIFingerprint returns the hex encoded SHA-256 fingerprint of the
+// SubjectPublicKeyInfo of cert, as accepted in Config.TLSPins. It can be
+// computed from a PEM certificate with:
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der |
+//		openssl dgst -sha256
+func SPKIFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseSPKIPin parses a SHA-256 fingerprint, hex encoded with optional
+// colons or base64 encoded.
+func parseSPKIPin(pin string) ([]byte, error) {
+	if b, err := hex.DecodeString(strings.ReplaceAll(pin, ":", "")); err == nil &&
+		len(b) == sha256.Size {
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(pin); err == nil && len(b) == sha256.Size {
+		return b, nil
+	}
+	return nil, fmt.Errorf("invalid TLS pin %q: expected a hex or base64 encoded "+
+		"SHA-256 fingerprint", pin)
+}
+
+// spkiPinVerifier returns a tls.Config.VerifyPeerCertificate function
+// accepting the server certificates whose SubjectPublicKeyInfo matches one
+// of pins.
+func spkiPinVerifier(pins []string) (func([][]byte, [][]*x509.Certificate) error, error) {
+	sums := make([][]byte, len(pins))
+	for i, pin := range pins {
+		var err error
+		if sums[i], err = parseSPKIPin(pin); err != nil {
+			return nil, err
+		}
+	}
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("no server certificate to check the TLS pins against")
+		}
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pinned := range sums {
+			if bytes.Equal(sum[:], pinned) {
+				return nil
+			}
+		}
+		return fmt.Errorf("server certificate SPKI fingerprint %s matches none of the "+
+			"TLS pins", hex.EncodeToString(sum[:]))
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestTLSPins(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "switch"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(
+		&tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key})))
+	pb.RegisterGNMIServer(s, &capabilitiesServer{})
+	go s.Serve(l)
+	defer s.Stop()
+
+	fingerprint := SPKIFingerprint(cert)
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	var colons []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		colons = append(colons, strings.ToUpper(fingerprint[i:i+2]))
+	}
+	other := strings.Repeat("00", sha256.Size)
+	for name, tc := range map[string]struct {
+		pins []string
+		ok   bool
+	}{
+		"hex":      {pins: []string{other, fingerprint}, ok: true},
+		"colons":   {pins: []string{strings.Join(colons, ":")}, ok: true},
+		"base64":   {pins: []string{base64.StdEncoding.EncodeToString(sum[:])}, ok: true},
+		"mismatch": {pins: []string{other}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			conn, err := DialContextConn(ctx, &Config{Addr: l.Addr().String(), TLSPins: tc.pins})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			_, err = pb.NewGNMIClient(conn).Capabilities(ctx, &pb.CapabilityRequest{})
+			if tc.ok && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !tc.ok && (err == nil || !strings.Contains(err.Error(), fingerprint)) {
+				t.Errorf("expected an error with the fingerprint %s, got %v", fingerprint, err)
+			}
+		})
+	}
+
+	if _, err := DialContextConn(context.Background(),
+		&Config{Addr: l.Addr().String(), TLSPins: []string{"abcd"}}); err == nil {
+		t.Error("expected an error for an invalid pin")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// ProxyDirect is the Config.Proxy value disabling the proxy, even if one
+// is set in the environment.
+const ProxyDirect = "direct"
+
+// ProxyUsage describes the values of Config.Proxy, for the usage of the
+// flags setting it.
+const ProxyUsage = "URL of the HTTP CONNECT (http://) or SOCKS5 (socks5://) proxy to dial " +
+	"through. Defaults to the HTTPS_PROXY or HTTP_PROXY environment variables, '" +
+	ProxyDirect + "' to not use a proxy"
+
+// proxyURL returns the URL of the proxy to use to reach addr, or nil if addr
+// must be dialed directly. If cfgProxy is empty, the proxy is taken from the
+// HTTPS_PROXY (with TLS) or HTTP_PROXY environment variables, honoring
+// NO_PROXY.
+func proxyURL(cfgProxy, addr string, useTLS bool) (*url.URL, error) {
+	switch cfgProxy {
+	case ProxyDirect:
+		return nil, nil
+	case "":
+		scheme := "http"
+		if useTLS {
+			scheme = "https"
+		}
+		u, err := httpproxy.FromEnvironment().ProxyFunc()(&url.URL{Scheme: scheme, Host: addr})
+		if err != nil || u == nil {
+			return u, err
+		}
+		return u, checkProxyScheme(u)
+	}
+	u, err := url.Parse(cfgProxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %s", cfgProxy, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", cfgProxy)
+	}
+	return u, checkProxyScheme(u)
+}
+
+// checkProxyScheme rejects the proxies reached over TLS, which dialProxy
+// does not support: the TLS handshake is with the target, not the proxy.
+func checkProxyScheme(u *url.URL) error {
+	if u.Scheme == "https" {
+		return fmt.Errorf("unsupported proxy %q: HTTPS proxies are not supported, "+
+			"use an http:// or socks5:// proxy", u.Redacted())
+	}
+	return nil
+}
+
+// dialProxy connects to addr through the HTTP CONNECT or SOCKS5 proxy at u,
+// dialing the proxy with d. The TLS handshake with the target, if any,
+// happens over the returned connection.
+func dialProxy(ctx context.Context, d *net.Dialer, u *url.URL, addr string) (net.Conn, error) {
+	switch u.Scheme {
+	case "http":
+		return dialHTTPConnect(ctx, d, u, addr)
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			auth = &proxy.Auth{User: u.User.Username()}
+			auth.Password, _ = u.User.Password()
+		}
+		socks, err := proxy.SOCKS5("tcp", proxyHost(u, "1080"), auth, d)
+		if err != nil {
+			return nil, err
+		}
+		return socks.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+func proxyHost(u *url.URL, defaultPort string) string {
+	if u.Port() == "" {
+		return net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	return u.Host
+}
+
+func dialHTTPConnect(ctx context.Context, d *net.Dialer, u *url.URL,
+	addr string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", proxyHost(u, "80"))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to proxy %s: %s", u.Host, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from proxy %s: %s",
+			u.Host, err)
+	}
+	// The body of the response is the tunnel, it must not be read.
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", u.Host, addr, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	if br.Buffered() > 0 {
+		// The target already sent data, which was read along with the
+		// CONNECT response.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose reads go through a bufio.Reader.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
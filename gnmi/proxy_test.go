@@ -0,0 +1,210 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// listen starts a TCP server on the loopback interface handling each
+// connection with handle.
+func listen(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handle(c)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func echo(c net.Conn) {
+	defer c.Close()
+	io.Copy(c, c)
+}
+
+func tunnel(c net.Conn, addr string) {
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		c.Close()
+		return
+	}
+	go func() {
+		io.Copy(target, c)
+		target.Close()
+	}()
+	io.Copy(c, target)
+	c.Close()
+}
+
+func checkEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 5)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello" {
+		t.Errorf("expected hello, got %q", b)
+	}
+}
+
+func TestDialHTTPConnect(t *testing.T) {
+	target := listen(t, echo)
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	proxyServer := &http.Server{Handler: http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodConnect {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Proxy-Authorization") != auth {
+				w.WriteHeader(http.StatusProxyAuthRequired)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			c, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			tunnel(c, r.Host)
+		})}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go proxyServer.Serve(l)
+	defer proxyServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	u := &url.URL{Scheme: "http", User: url.UserPassword("user", "pass"),
+		Host: l.Addr().String()}
+	conn, err := dialProxy(ctx, &net.Dialer{}, u, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEcho(t, conn)
+
+	u.User = url.UserPassword("user", "wrong")
+	if _, err := dialProxy(ctx, &net.Dialer{}, u, target); err == nil {
+		t.Error("expected an error with the wrong password")
+	}
+}
+
+// socks5 handles a SOCKS5 connection without authentication, as per
+// RFC 1928.
+func socks5(c net.Conn) {
+	buf := make([]byte, 262)
+	// Greeting: version, number of methods and methods.
+	if _, err := io.ReadFull(c, buf[:2]); err != nil {
+		c.Close()
+		return
+	}
+	if _, err := io.ReadFull(c, buf[:buf[1]]); err != nil {
+		c.Close()
+		return
+	}
+	c.Write([]byte{5, 0})
+	// Request: version, command, reserved, address type, address, port.
+	if _, err := io.ReadFull(c, buf[:4]); err != nil || buf[1] != 1 {
+		c.Close()
+		return
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		io.ReadFull(c, buf[:4])
+		host = net.IP(buf[:4]).String()
+	case 3:
+		io.ReadFull(c, buf[:1])
+		n := int(buf[0])
+		io.ReadFull(c, buf[:n])
+		host = string(buf[:n])
+	default:
+		c.Close()
+		return
+	}
+	io.ReadFull(c, buf[:2])
+	port := binary.BigEndian.Uint16(buf[:2])
+	c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	tunnel(c, net.JoinHostPort(host, strconv.Itoa(int(port))))
+}
+
+func TestDialSOCKS5(t *testing.T) {
+	target := listen(t, echo)
+	proxyAddr := listen(t, socks5)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := dialProxy(ctx, &net.Dialer{}, &url.URL{Scheme: "socks5", Host: proxyAddr}, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEcho(t, conn)
+}
+
+func TestProxyURL(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://plain-proxy:3128")
+	t.Setenv("HTTPS_PROXY", "socks5://tls-proxy:1080")
+	t.Setenv("NO_PROXY", "direct.example.com")
+	for name, tc := range map[string]struct {
+		proxy  string
+		addr   string
+		useTLS bool
+		exp    string
+	}{
+		"env":          {addr: "dut:6030", exp: "http://plain-proxy:3128"},
+		"env with TLS": {addr: "dut:6030", useTLS: true, exp: "socks5://tls-proxy:1080"},
+		"env no proxy": {addr: "direct.example.com:6030"},
+		"explicit": {proxy: "socks5://bastion:1080", addr: "dut:6030",
+			exp: "socks5://bastion:1080"},
+		"explicit direct": {proxy: ProxyDirect, addr: "dut:6030"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			u, err := proxyURL(tc.proxy, tc.addr, tc.useTLS)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			if u != nil {
+				got = u.String()
+			}
+			if got != tc.exp {
+				t.Errorf("expected proxy %q, got %q", tc.exp, got)
+			}
+		})
+	}
+	if _, err := proxyURL("bastion", "dut:6030", false); err == nil {
+		t.Error("expected an error for a proxy without host")
+	}
+	if _, err := proxyURL("https://bastion:3128", "dut:6030", false); err == nil {
+		t.Error("expected an error for an HTTPS proxy")
+	}
+	t.Setenv("HTTPS_PROXY", "https://tls-proxy:3128")
+	if _, err := proxyURL("", "dut:6030", true); err == nil {
+		t.Error("expected an error for an HTTPS proxy from the environment")
+	}
+}
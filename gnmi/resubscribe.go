@@ -0,0 +1,227 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aristanetworks/goarista/logger"
+	"github.com/cenkalti/backoff/v4"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReconnectMarker is the synthetic response SubscribeWithReconnect sends
+// before the responses of a new stream, for the consumers to know that
+// the subscription reconnected.
+type ReconnectMarker int
+
+const (
+	// NoReconnectMarker sends no marker.
+	NoReconnectMarker ReconnectMarker = iota
+	// DeleteReconnectMarker sends a Notification deleting the subscribed
+	// paths, for the consumers to drop the state that may have changed
+	// while disconnected before it is sent again by the new stream. It
+	// is not meant to be used with ResubscribeOptions.UpdatesOnly, the
+	// state then not being sent again.
+	DeleteReconnectMarker
+	// RefreshReconnectMarker sends a Notification with no updates in a
+	// response carrying an experimental extension, see IsReconnectMarker.
+	RefreshReconnectMarker
+)
+
+// reconnectExtensionMsg is the message of the experimental extension of
+// the response sent by RefreshReconnectMarker.
+const reconnectExtensionMsg = "reconnect"
+
+// ParseReconnectMarker parses a ReconnectMarker from none, delete or
+// refresh.
+func ParseReconnectMarker(s string) (ReconnectMarker, error) {
+	switch s {
+	case "", "none":
+		return NoReconnectMarker, nil
+	case "delete":
+		return DeleteReconnectMarker, nil
+	case "refresh":
+		return RefreshReconnectMarker, nil
+	}
+	return 0, fmt.Errorf("invalid reconnect marker %q, expected none, delete or refresh", s)
+}
+
+// IsReconnectMarker reports whether resp is the response sent by
+// RefreshReconnectMarker.
+func IsReconnectMarker(resp *pb.SubscribeResponse) bool {
+	for _, ext := range resp.GetExtension() {
+		if reg := ext.GetRegisteredExt(); reg.GetId() == gnmi_ext.ExtensionID_EID_EXPERIMENTAL &&
+			string(reg.GetMsg()) == reconnectExtensionMsg {
+			return true
+		}
+	}
+	return false
+}
+
+// ResubscribeOptions configures SubscribeWithReconnect.
+type ResubscribeOptions struct {
+	// UpdatesOnly sets updates_only in the SubscribeRequests sent once a
+	// stream sent its sync_response, for the target not to send the whole
+	// state again on each reconnect.
+	UpdatesOnly bool
+	// Marker is the response sent before the responses of a new stream.
+	Marker ReconnectMarker
+	// Backoff is the time waited before reconnecting, reset once a stream
+	// sent its sync_response. SubscribeWithReconnect gives up when it
+	// returns backoff.Stop. If nil, an exponential backoff retrying
+	// forever is used.
+	Backoff backoff.BackOff
+	// Logger, if set, logs the reconnections.
+	Logger logger.Logger
+	// Clock, used to wait for the backoff, defaults to RealClock.
+	Clock Clock
+}
+
+// SubscribeWithReconnect calls gNMI.Subscribe with the STREAM
+// SubscribeRequest req and writes the responses to respChan, subscribing
+// again when the stream fails or ends until ctx is done or the backoff
+// stops. Before returning respChan will be closed.
+func SubscribeWithReconnect(ctx context.Context, client pb.GNMIClient, req *pb.SubscribeRequest,
+	respChan chan<- *pb.SubscribeResponse, opts ResubscribeOptions) error {
+	return subscribeWithReconnect(ctx, client, req, respChan, opts, 0)
+}
+
+func subscribeWithReconnect(ctx context.Context, client pb.GNMIClient,
+	req *pb.SubscribeRequest, respChan chan<- *pb.SubscribeResponse, opts ResubscribeOptions,
+	unbundleDepth int) error {
+	defer close(respChan)
+	if mode := req.GetSubscribe().GetMode(); mode != pb.SubscriptionList_STREAM {
+		return fmt.Errorf("cannot reconnect a %s subscription", mode)
+	}
+	if opts.Backoff == nil {
+		b := backoff.NewExponentialBackOff()
+		b.MaxElapsedTime = 0
+		opts.Backoff = b
+	}
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	opts.Backoff.Reset()
+
+	var synced, reconnecting bool
+	for {
+		r := req
+		if synced && opts.UpdatesOnly && !req.GetSubscribe().GetUpdatesOnly() {
+			r = proto.Clone(req).(*pb.SubscribeRequest)
+			r.GetSubscribe().UpdatesOnly = true
+		}
+		var marker *pb.SubscribeResponse
+		if reconnecting {
+			marker = reconnectMarker(req.GetSubscribe(), opts.Marker, opts.Clock)
+		}
+		err := subscribeStream(ctx, client, r, respChan, marker, unbundleDepth, func() {
+			if !synced && opts.Logger != nil && reconnecting {
+				opts.Logger.Infof("subscription reconnected")
+			}
+			synced = true
+			opts.Backoff.Reset()
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		wait := opts.Backoff.NextBackOff()
+		if wait == backoff.Stop {
+			return err
+		}
+		if opts.Logger != nil {
+			opts.Logger.Errorf("subscription failed, reconnecting in %s: %s", wait, err)
+		}
+		select {
+		case <-opts.Clock.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		reconnecting = true
+	}
+}
+
+// subscribeStream makes one gNMI.Subscribe call with req and writes marker,
+// if any, once req is sent, then the responses to respChan, unbundled at
+// unbundleDepth, calling onSync on every sync_response. It returns nil if
+// the stream ends.
+func subscribeStream(ctx context.Context, client pb.GNMIClient, req *pb.SubscribeRequest,
+	respChan chan<- *pb.SubscribeResponse, marker *pb.SubscribeResponse, unbundleDepth int,
+	onSync func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	send := func(resp *pb.SubscribeResponse) error {
+		select {
+		case respChan <- resp:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if marker != nil {
+		if err := send(marker); err != nil {
+			return err
+		}
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		for _, resp := range UnbundleSubscribeResponse(resp, unbundleDepth) {
+			if err := send(resp); err != nil {
+				return err
+			}
+		}
+		if resp.GetSyncResponse() {
+			onSync()
+		}
+	}
+}
+
+// reconnectMarker returns the response sent for marker before the responses
+// of a new stream of the subscriptions of list, or nil.
+func reconnectMarker(list *pb.SubscriptionList, marker ReconnectMarker,
+	clock Clock) *pb.SubscribeResponse {
+	notif := &pb.Notification{Timestamp: clock.Now().UnixNano(), Prefix: list.GetPrefix()}
+	switch marker {
+	case DeleteReconnectMarker:
+		for _, sub := range list.GetSubscription() {
+			notif.Delete = append(notif.Delete, sub.GetPath())
+		}
+	case RefreshReconnectMarker:
+		return &pb.SubscribeResponse{
+			Response: &pb.SubscribeResponse_Update{Update: notif},
+			Extension: []*gnmi_ext.Extension{{Ext: &gnmi_ext.Extension_RegisteredExt{
+				RegisteredExt: &gnmi_ext.RegisteredExtension{
+					Id:  gnmi_ext.ExtensionID_EID_EXPERIMENTAL,
+					Msg: []byte(reconnectExtensionMsg),
+				}}}},
+		}
+	default:
+		return nil
+	}
+	return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: notif}}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reconnectServer sends an update and a sync, unless noSync is set, to
+// every subscription, then fails the first failures streams.
+type reconnectServer struct {
+	pb.UnimplementedGNMIServer
+	failures int
+	noSync   bool
+
+	mu       sync.Mutex
+	requests []*pb.SubscribeRequest
+}
+
+func (s *reconnectServer) Subscribe(stream pb.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	n := len(s.requests)
+	s.mu.Unlock()
+	if !req.GetSubscribe().GetUpdatesOnly() {
+		notif := &pb.Notification{Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "hostname"}}},
+			Val:  TypedValue("switch1")}}}
+		if err := stream.Send(&pb.SubscribeResponse{
+			Response: &pb.SubscribeResponse_Update{Update: notif}}); err != nil {
+			return err
+		}
+	}
+	if s.noSync && n <= s.failures {
+		return status.Error(codes.Unavailable, "going away")
+	}
+	if err := stream.Send(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+		return err
+	}
+	if n <= s.failures {
+		return status.Error(codes.Unavailable, "going away")
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func newReconnectClient(t *testing.T, s *reconnectServer) pb.GNMIClient {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	pb.RegisterGNMIServer(srv, s)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewGNMIClient(conn)
+}
+
+func TestSubscribeWithReconnect(t *testing.T) {
+	s := &reconnectServer{failures: 2}
+	client := newReconnectClient(t, s)
+	req, err := NewSubscribeRequest(&SubscribeOptions{Paths: [][]string{{"system"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	respChan := make(chan *pb.SubscribeResponse)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- SubscribeWithReconnect(ctx, client, req, respChan, ResubscribeOptions{
+			UpdatesOnly: true,
+			Marker:      RefreshReconnectMarker,
+			Backoff:     backoff.NewConstantBackOff(time.Millisecond),
+		})
+	}()
+
+	var kinds []string
+	for syncs := 0; syncs < 3; {
+		resp := <-respChan
+		switch {
+		case IsReconnectMarker(resp):
+			kinds = append(kinds, "marker")
+		case resp.GetSyncResponse():
+			kinds = append(kinds, "sync")
+			syncs++
+		default:
+			kinds = append(kinds, "update")
+		}
+	}
+	cancel()
+	for range respChan {
+	}
+	if err := <-errChan; err != context.Canceled {
+		t.Errorf("expected %s, got %v", context.Canceled, err)
+	}
+
+	expected := []string{"update", "sync", "marker", "sync", "marker", "sync"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected responses %q, got %q", expected, kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Fatalf("expected responses %q, got %q", expected, kinds)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, req := range s.requests {
+		if updatesOnly := req.GetSubscribe().GetUpdatesOnly(); updatesOnly != (i > 0) {
+			t.Errorf("request %d: unexpected updates_only %t", i, updatesOnly)
+		}
+	}
+}
+
+func TestSubscribeWithReconnectBackoffStop(t *testing.T) {
+	client := newReconnectClient(t, &reconnectServer{failures: 10, noSync: true})
+	req, err := NewSubscribeRequest(&SubscribeOptions{Paths: [][]string{{"system"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	respChan := make(chan *pb.SubscribeResponse)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- SubscribeWithReconnect(context.Background(), client, req, respChan,
+			ResubscribeOptions{
+				Backoff: backoff.WithMaxRetries(backoff.NewConstantBackOff(time.Millisecond), 1),
+			})
+	}()
+	var updates int
+	for resp := range respChan {
+		if resp.GetUpdate() != nil {
+			updates++
+		}
+	}
+	if updates != 2 {
+		t.Errorf("expected the state sent twice, got %d updates", updates)
+	}
+	if err := <-errChan; status.Code(err) != codes.Unavailable {
+		t.Errorf("expected an Unavailable error, got %v", err)
+	}
+
+	req.GetSubscribe().Mode = pb.SubscriptionList_ONCE
+	if err := SubscribeWithReconnect(context.Background(), client, req,
+		make(chan *pb.SubscribeResponse), ResubscribeOptions{}); err == nil {
+		t.Error("expected an error reconnecting a ONCE subscription")
+	}
+}
+
+func TestReconnectMarker(t *testing.T) {
+	list := &pb.SubscriptionList{
+		Prefix: &pb.Path{Target: "dev1"},
+		Subscription: []*pb.Subscription{
+			{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "interfaces"}}}},
+			{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}}}},
+		},
+	}
+	clock := NewFakeClock(time.Unix(1, 0))
+	if resp := reconnectMarker(list, NoReconnectMarker, clock); resp != nil {
+		t.Errorf("unexpected marker %s", resp)
+	}
+	resp := reconnectMarker(list, DeleteReconnectMarker, clock)
+	notif := resp.GetUpdate()
+	if IsReconnectMarker(resp) || notif.GetTimestamp() != 1e9 ||
+		notif.GetPrefix().GetTarget() != "dev1" || len(notif.GetDelete()) != 2 ||
+		StrPath(notif.Delete[1]) != "/system" {
+		t.Errorf("unexpected delete marker %s", resp)
+	}
+	if resp := reconnectMarker(list, RefreshReconnectMarker, clock); !IsReconnectMarker(resp) ||
+		len(resp.GetUpdate().GetUpdate()) != 0 {
+		t.Errorf("unexpected refresh marker %s", resp)
+	}
+	for s, expected := range map[string]ReconnectMarker{
+		"": NoReconnectMarker, "none": NoReconnectMarker,
+		"delete": DeleteReconnectMarker, "refresh": RefreshReconnectMarker,
+	} {
+		if m, err := ParseReconnectMarker(s); err != nil || m != expected {
+			t.Errorf("%q: expected %d, got %d, %v", s, expected, m, err)
+		}
+	}
+	if _, err := ParseReconnectMarker("foo"); err == nil {
+		t.Error("expected an error parsing foo")
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristanetworks/goarista/logger"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// RPCLogFlag registers the -rpc_log flag, which sets the RPCLogger of cfg
+// to log the RPCs, like the flag of ParseFlags does.
+func RPCLogFlag(cfg *Config) {
+	flag.Var(rpcLogValue{cfg: cfg}, "rpc_log", rpcLogUsage)
+}
+
+const rpcLogUsage = "Log every gNMI RPC, with the credentials redacted"
+
+// newRPCLogger returns the Logger of the -rpc_log flag. It is the standard
+// logger rather than glog, which this package does not depend on.
+func newRPCLogger() logger.Logger {
+	return logger.Std
+}
+
+// rpcLogValue is the flag.Value of the -rpc_log flag.
+type rpcLogValue struct {
+	cfg *Config
+}
+
+func (v rpcLogValue) String() string {
+	return strconv.FormatBool(v.cfg != nil && v.cfg.RPCLogger != nil)
+}
+
+func (v rpcLogValue) Set(s string) error {
+	on, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.cfg.RPCLogger = nil
+	if on {
+		v.cfg.RPCLogger = newRPCLogger()
+	}
+	return nil
+}
+
+func (v rpcLogValue) IsBoolFlag() bool { return true }
+
+// rpcLogValueLimit is the number of bytes of the values of a SetRequest
+// logged before they are truncated.
+const rpcLogValueLimit = 64
+
+// rpcLogRedacted replaces the value of the credential metadata in the logs.
+const rpcLogRedacted = "<redacted>"
+
+// isCredentialMetadata returns whether the metadata key holds credentials
+// that must not be logged.
+func isCredentialMetadata(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"password", "token", "auth", "secret", "cookie"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// rpcLogMetadata returns the outgoing metadata of ctx for the logs, with the
+// values of the credentials redacted.
+func rpcLogMetadata(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok || len(md) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(" metadata=[")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		v := strings.Join(md[k], ",")
+		if isCredentialMetadata(k) {
+			v = rpcLogRedacted
+		}
+		fmt.Fprintf(&b, "%s:%s", k, v)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func truncateValue(s string) string {
+	if len(s) <= rpcLogValueLimit {
+		return s
+	}
+	return fmt.Sprintf("%s...(%d bytes)", s[:rpcLogValueLimit], len(s))
+}
+
+func rpcLogPaths(name string, prefix *pb.Path, paths []*pb.Path) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	strs := make([]string, len(paths))
+	for i, p := range paths {
+		strs[i] = StrPath(JoinPaths(prefix, p))
+	}
+	return fmt.Sprintf(" %s=[%s]", name, strings.Join(strs, " "))
+}
+
+func rpcLogUpdates(name string, prefix *pb.Path, updates []*pb.Update) string {
+	if len(updates) == 0 {
+		return ""
+	}
+	strs := make([]string, len(updates))
+	for i, u := range updates {
+		strs[i] = StrPath(JoinPaths(prefix, u.Path)) + ":" + truncateValue(StrUpdateVal(u))
+	}
+	return fmt.Sprintf(" %s=[%s]", name, strings.Join(strs, " "))
+}
+
+// rpcLogSummary summarizes the paths of a gNMI request for the logs.
+func rpcLogSummary(req interface{}) string {
+	switch req := req.(type) {
+	case *pb.GetRequest:
+		return rpcLogPaths("paths", req.Prefix, req.Path)
+	case *pb.SetRequest:
+		return rpcLogPaths("delete", req.Prefix, req.Delete) +
+			rpcLogUpdates("replace", req.Prefix, req.Replace) +
+			rpcLogUpdates("update", req.Prefix, req.Update) +
+			rpcLogUpdates("union_replace", req.Prefix, req.UnionReplace)
+	case *pb.SubscribeRequest:
+		switch r := req.Request.(type) {
+		case *pb.SubscribeRequest_Subscribe:
+			paths := make([]*pb.Path, len(r.Subscribe.GetSubscription()))
+			for i, s := range r.Subscribe.GetSubscription() {
+				paths[i] = s.Path
+			}
+			return fmt.Sprintf(" mode=%s", r.Subscribe.Mode) +
+				rpcLogPaths("paths", r.Subscribe.Prefix, paths)
+		case *pb.SubscribeRequest_Poll:
+			return " poll"
+		}
+	}
+	return ""
+}
+
+func msgSize(msg interface{}) int {
+	if m, ok := msg.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	return 0
+}
+
+// rpcLogUnaryInterceptor logs the unary RPCs to l once they are done.
+func rpcLogUnaryInterceptor(l logger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		l.Infof("RPC %s%s%s: sent %d bytes, received %d bytes in %s, error: %v",
+			method, rpcLogMetadata(ctx), rpcLogSummary(req), msgSize(req), msgSize(reply),
+			time.Since(start), err)
+		return err
+	}
+}
+
+// rpcLogStreamInterceptor logs the start of the streaming RPCs to l, each
+// of their requests, and the number and size of their responses once they
+// are done.
+func rpcLogStreamInterceptor(l logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			l.Infof("RPC %s%s failed to start: %v", method, rpcLogMetadata(ctx), err)
+			return nil, err
+		}
+		l.Infof("RPC %s%s started", method, rpcLogMetadata(ctx))
+		return &rpcLogStream{ClientStream: stream, logger: l, method: method, start: start}, nil
+	}
+}
+
+type rpcLogStream struct {
+	grpc.ClientStream
+	logger logger.Logger
+	method string
+	start  time.Time
+	// The responses are only received by one goroutine at a time.
+	received      int
+	receivedBytes int
+	done          bool
+}
+
+func (s *rpcLogStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	s.logger.Infof("RPC %s%s: sent %d bytes, error: %v", s.method, rpcLogSummary(m),
+		msgSize(m), err)
+	return err
+}
+
+func (s *rpcLogStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+		s.receivedBytes += msgSize(m)
+		return nil
+	}
+	if !s.done {
+		s.done = true
+		logErr := err
+		if logErr == io.EOF {
+			logErr = nil
+		}
+		s.logger.Infof("RPC %s: received %d responses, %d bytes in %s, error: %v",
+			s.method, s.received, s.receivedBytes, time.Since(s.start), logErr)
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetBatchOptions controls how SetBatch splits and sends operations.
+type SetBatchOptions struct {
+	// BatchSize is the maximum number of operations per SetRequest.
+	// If zero or negative, all operations are sent in a single SetRequest.
+	BatchSize int
+	// Concurrency is the maximum number of SetRequests in flight.
+	// If zero or negative, SetRequests are sent one at a time.
+	Concurrency int
+	// Extensions are added to every SetRequest.
+	Extensions []*gnmi_ext.Extension
+}
+
+// SetResult is the outcome of one SetRequest sent by SetBatch.
+type SetResult struct {
+	Operations []*Operation
+	Request    *pb.SetRequest
+	Response   *pb.SetResponse
+	Err        error
+	Duration   time.Duration
+}
+
+// SetBatch splits setOps into SetRequests according to opts, sends them
+// concurrently and returns one SetResult per SetRequest, in the order of the
+// operations. Errors building or sending a SetRequest are reported in its
+// SetResult and do not stop the other SetRequests. The returned error is
+// only non-nil if ctx is done.
+func SetBatch(ctx context.Context, client pb.GNMIClient, setOps []*Operation,
+	opts SetBatchOptions) ([]*SetResult, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(setOps)
+	}
+	var results []*SetResult
+	for start := 0; start < len(setOps); start += batchSize {
+		end := start + batchSize
+		if end > len(setOps) {
+			end = len(setOps)
+		}
+		results = append(results, &SetResult{Operations: setOps[start:end]})
+	}
+
+	var eg errgroup.Group
+	if opts.Concurrency > 0 {
+		eg.SetLimit(opts.Concurrency)
+	} else {
+		eg.SetLimit(1)
+	}
+	for _, res := range results {
+		if err := ctx.Err(); err != nil {
+			res.Err = err
+			continue
+		}
+		res := res
+		eg.Go(func() error {
+			res.Request, res.Err = newSetRequest(res.Operations, opts.Extensions...)
+			if res.Err != nil {
+				return nil
+			}
+			start := time.Now()
+			res.Response, res.Err = client.Set(ctx, res.Request)
+			res.Duration = time.Since(start)
+			if res.Err == nil && res.Response.GetMessage() != nil &&
+				codes.Code(res.Response.Message.Code) != codes.OK {
+				res.Err = status.Error(codes.Code(res.Response.Message.Code),
+					res.Response.Message.Message)
+			}
+			return nil
+		})
+	}
+	eg.Wait()
+	return results, ctx.Err()
+}
+
+// WriteSetSummary writes a human-readable summary of the results of SetBatch
+// to w: the outcome and timing of each SetRequest, followed by the per-path
+// UpdateResults. It returns the number of failed SetRequests.
+func WriteSetSummary(w io.Writer, results []*SetResult) int {
+	var failed, numOps int
+	for i, res := range results {
+		numOps += len(res.Operations)
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(w, "request %d: %d operations, FAILED after %s: %s\n",
+				i+1, len(res.Operations), res.Duration, status.Code(res.Err))
+			fmt.Fprintf(w, "  error: %s\n", res.Err)
+			for _, op := range res.Operations {
+				fmt.Fprintf(w, "  %s %s: %s\n", op.Type, opPath(op), status.Code(res.Err))
+			}
+			continue
+		}
+		fmt.Fprintf(w, "request %d: %d operations, OK in %s\n",
+			i+1, len(res.Operations), res.Duration)
+		prefix := res.Response.GetPrefix()
+		for _, ur := range res.Response.GetResponse() {
+			code := codes.OK
+			if ur.GetMessage() != nil {
+				code = codes.Code(ur.GetMessage().GetCode())
+			}
+			fmt.Fprintf(w, "  %s %s: %s\n", ur.GetOp(),
+				StrPath(JoinPaths(prefix, ur.GetPath())), code)
+		}
+	}
+	fmt.Fprintf(w, "%d requests, %d operations, %d failed requests\n",
+		len(results), numOps, failed)
+	return failed
+}
+
+func opPath(op *Operation) string {
+	p := "/" + strings.Join(op.Path, "/")
+	if op.Origin != "" {
+		p = op.Origin + ":" + p
+	}
+	return p
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setClient is a pb.GNMIClient whose Set fails for SetRequests deleting /fail.
+type setClient struct {
+	pb.GNMIClient
+	mu   sync.Mutex
+	reqs []*pb.SetRequest
+}
+
+func (c *setClient) Set(ctx context.Context, req *pb.SetRequest,
+	opts ...grpc.CallOption) (*pb.SetResponse, error) {
+	c.mu.Lock()
+	c.reqs = append(c.reqs, req)
+	c.mu.Unlock()
+	resp := &pb.SetResponse{}
+	for _, d := range req.Delete {
+		if StrPath(d) == "/fail" {
+			return nil, status.Error(codes.InvalidArgument, "cannot delete /fail")
+		}
+		resp.Response = append(resp.Response,
+			&pb.UpdateResult{Path: d, Op: pb.UpdateResult_DELETE})
+	}
+	for _, u := range req.Update {
+		resp.Response = append(resp.Response,
+			&pb.UpdateResult{Path: u.Path, Op: pb.UpdateResult_UPDATE})
+	}
+	return resp, nil
+}
+
+func TestSetBatch(t *testing.T) {
+	ops := []*Operation{
+		{Type: "update", Path: []string{"a"}, Val: "1"},
+		{Type: "delete", Path: []string{"b"}},
+		{Type: "delete", Path: []string{"fail"}},
+		{Type: "update", Path: []string{"c"}, Val: "2"},
+		{Type: "delete", Path: []string{"d"}},
+	}
+	client := &setClient{}
+	results, err := SetBatch(context.Background(), client, ops,
+		SetBatchOptions{BatchSize: 2, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || len(client.reqs) != 3 {
+		t.Fatalf("expected 3 results and requests, got %d and %d",
+			len(results), len(client.reqs))
+	}
+	for i, wantErr := range []bool{false, true, false} {
+		if (results[i].Err != nil) != wantErr {
+			t.Errorf("result %d: unexpected error %v", i, results[i].Err)
+		}
+	}
+	if n := len(results[2].Operations); n != 1 {
+		t.Errorf("expected last request to have 1 operation, got %d", n)
+	}
+
+	var buf bytes.Buffer
+	if failed := WriteSetSummary(&buf, results); failed != 1 {
+		t.Errorf("expected 1 failed request, got %d", failed)
+	}
+	summary := buf.String()
+	for _, want := range []string{
+		"  UPDATE /a: OK\n",
+		"  DELETE /b: OK\n",
+		"  delete /fail: InvalidArgument\n",
+		"  update /c: InvalidArgument\n",
+		"  DELETE /d: OK\n",
+		"3 requests, 5 operations, 1 failed requests\n",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, summary)
+		}
+	}
+}
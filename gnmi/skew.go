@@ -0,0 +1,212 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// SkewStats holds the clock skew measured between the timestamps of the
+// notifications and their receive time. The skew is the receive time minus
+// the timestamp, so it is negative for timestamps in the future.
+type SkewStats struct {
+	// Notifications is the number of notifications measured.
+	Notifications uint64
+	// Skewed is the number of notifications whose skew exceeded the
+	// threshold, in either direction.
+	Skewed uint64
+	// Retimestamped is the number of notifications whose timestamp was
+	// rewritten to their receive time.
+	Retimestamped uint64
+	// Last, Min and Max are the skews of the last notification measured,
+	// and the smallest and largest ones.
+	Last time.Duration
+	Min  time.Duration
+	Max  time.Duration
+
+	// mean is the running mean of the skews measured, in nanoseconds,
+	// which unlike their sum cannot overflow.
+	mean float64
+}
+
+// AvgSkew returns the average skew of the notifications measured.
+func (s SkewStats) AvgSkew() time.Duration {
+	return time.Duration(s.mean)
+}
+
+// SkewDetector measures the clock skew between a target and the local
+// host from the timestamps of the notifications received, and optionally
+// rewrites the timestamps that are off by more than a threshold to the
+// receive time, to guard the time series databases downstream against
+// targets with a broken clock. It is safe for concurrent use.
+type SkewDetector struct {
+	clock       Clock
+	threshold   time.Duration
+	retimestamp bool
+
+	mu    sync.Mutex
+	stats SkewStats
+}
+
+// NewSkewDetector returns a SkewDetector using clock as the source of the
+// receive times, or RealClock if nil. The notifications whose skew exceeds
+// threshold are counted as skewed, and their timestamp is rewritten to the
+// receive time if retimestamp is true. A zero threshold disables both.
+func NewSkewDetector(clock Clock, threshold time.Duration, retimestamp bool) *SkewDetector {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &SkewDetector{clock: clock, threshold: threshold, retimestamp: retimestamp}
+}
+
+// Notification measures the skew of notif and rewrites its timestamp if
+// needed. It returns whether the skew of notif exceeded the threshold.
+func (d *SkewDetector) Notification(notif *pb.Notification) bool {
+	return d.notification(notif, true)
+}
+
+// notification measures the skew of notif. Before the sync_response, the
+// timestamps are the last time the values changed and may be arbitrarily
+// old, so only the ones in the future are measured unless synced.
+func (d *SkewDetector) notification(notif *pb.Notification, synced bool) bool {
+	if notif.Timestamp == 0 {
+		return false
+	}
+	now := d.clock.Now()
+	skew := now.Sub(time.Unix(0, notif.Timestamp))
+	if !synced && skew >= 0 {
+		return false
+	}
+	skewed := d.threshold > 0 && (skew > d.threshold || skew < -d.threshold)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := &d.stats
+	if s.Notifications == 0 || skew < s.Min {
+		s.Min = skew
+	}
+	if s.Notifications == 0 || skew > s.Max {
+		s.Max = skew
+	}
+	s.Notifications++
+	s.Last = skew
+	s.mean += (float64(skew) - s.mean) / float64(s.Notifications)
+	if !skewed {
+		return false
+	}
+	s.Skewed++
+	if d.retimestamp {
+		notif.Timestamp = now.UnixNano()
+		s.Retimestamped++
+	}
+	return true
+}
+
+// Snapshot returns a copy of the statistics measured so far.
+func (d *SkewDetector) Snapshot() SkewStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// WriteTo writes the statistics measured so far to w.
+func (d *SkewDetector) WriteTo(w io.Writer) (int64, error) {
+	s := d.Snapshot()
+	n, err := fmt.Fprintf(w, "notifications: %d skewed: %d retimestamped: %d "+
+		"skew last: %s avg: %s min: %s max: %s\n", s.Notifications, s.Skewed,
+		s.Retimestamped, s.Last, s.AvgSkew(), s.Min, s.Max)
+	return int64(n), err
+}
+
+// SkewSubscribeResponses forwards the SubscribeResponses of respChan to the
+// returned channel after measuring the skew of their notifications with d,
+// which rewrites their timestamps if needed. Before the sync_response,
+// only the timestamps in the future are measured, as the ones of the
+// initial updates are the last time the values changed. The returned
+// channel is closed after respChan is.
+func SkewSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	d *SkewDetector) chan *pb.SubscribeResponse {
+	return skewSubscribeResponses(respChan, d.notification)
+}
+
+// TargetSkewSubscribeResponses is SkewSubscribeResponses measuring the
+// skew of each target with its own detector of d. The notifications
+// without target in their prefix are measured as the ones of
+// defaultTarget.
+func TargetSkewSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	d *TargetSkewDetector, defaultTarget string) chan *pb.SubscribeResponse {
+	return skewSubscribeResponses(respChan, func(notif *pb.Notification, synced bool) bool {
+		target := notif.GetPrefix().GetTarget()
+		if target == "" {
+			target = defaultTarget
+		}
+		return d.Detector(target).notification(notif, synced)
+	})
+}
+
+func skewSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	notification func(notif *pb.Notification, synced bool) bool) chan *pb.SubscribeResponse {
+	measured := make(chan *pb.SubscribeResponse)
+	go func() {
+		defer close(measured)
+		synced := false
+		for resp := range respChan {
+			if notif := resp.GetUpdate(); notif != nil {
+				notification(notif, synced)
+			} else if resp.GetSyncResponse() {
+				synced = true
+			}
+			measured <- resp
+		}
+	}()
+	return measured
+}
+
+// TargetSkewDetector measures the clock skew of each target separately,
+// with a SkewDetector per target, as the targets have their own clock. It
+// is safe for concurrent use.
+type TargetSkewDetector struct {
+	clock       Clock
+	threshold   time.Duration
+	retimestamp bool
+
+	mu        sync.Mutex
+	detectors map[string]*SkewDetector
+}
+
+// NewTargetSkewDetector returns a TargetSkewDetector whose detectors are
+// created as NewSkewDetector does.
+func NewTargetSkewDetector(clock Clock, threshold time.Duration,
+	retimestamp bool) *TargetSkewDetector {
+	return &TargetSkewDetector{clock: clock, threshold: threshold, retimestamp: retimestamp,
+		detectors: map[string]*SkewDetector{}}
+}
+
+// Detector returns the SkewDetector of target, creating it if needed.
+func (d *TargetSkewDetector) Detector(target string) *SkewDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	detector, ok := d.detectors[target]
+	if !ok {
+		detector = NewSkewDetector(d.clock, d.threshold, d.retimestamp)
+		d.detectors[target] = detector
+	}
+	return detector
+}
+
+// Snapshot returns a copy of the statistics measured so far, by target.
+func (d *TargetSkewDetector) Snapshot() map[string]SkewStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := make(map[string]SkewStats, len(d.detectors))
+	for target, detector := range d.detectors {
+		stats[target] = detector.Snapshot()
+	}
+	return stats
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestSkewDetector(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := NewFakeClock(now)
+	d := NewSkewDetector(clock, time.Minute, true)
+	notif := func(ts time.Time) *pb.Notification {
+		return &pb.Notification{Timestamp: ts.UnixNano()}
+	}
+
+	if n := notif(now.Add(-time.Second)); d.Notification(n) ||
+		n.Timestamp != now.Add(-time.Second).UnixNano() {
+		t.Errorf("unexpected rewrite of %s", n)
+	}
+	if n := notif(now.Add(time.Hour)); !d.Notification(n) || n.Timestamp != now.UnixNano() {
+		t.Errorf("expected the timestamp of %s to be rewritten", n)
+	}
+	clock.Advance(time.Second)
+	if n := notif(now.Add(-2 * time.Minute)); !d.Notification(n) ||
+		n.Timestamp != now.Add(time.Second).UnixNano() {
+		t.Errorf("expected the timestamp of %s to be rewritten", n)
+	}
+	// Notifications without timestamp are not measured.
+	d.Notification(&pb.Notification{})
+
+	expected := SkewStats{
+		Notifications: 3,
+		Skewed:        2,
+		Retimestamped: 2,
+		Last:          2*time.Minute + time.Second,
+		Min:           -time.Hour,
+		Max:           2*time.Minute + time.Second,
+	}
+	s := d.Snapshot()
+	avg := (time.Second - time.Hour + 2*time.Minute + time.Second) / 3
+	if s.AvgSkew() < avg-1 || s.AvgSkew() > avg+1 {
+		t.Errorf("expected an average skew of %s, got %s", avg, s.AvgSkew())
+	}
+	if s.mean = 0; s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+	var sb strings.Builder
+	if _, err := d.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "notifications: 3 skewed: 2 retimestamped: 2") {
+		t.Errorf("unexpected stats %q", sb.String())
+	}
+
+	// Without retimestamp, the skewed notifications are only counted.
+	d = NewSkewDetector(clock, time.Minute, false)
+	if n := notif(now.Add(time.Hour)); !d.Notification(n) ||
+		n.Timestamp != now.Add(time.Hour).UnixNano() {
+		t.Errorf("unexpected rewrite of %s", n)
+	}
+	if s := d.Snapshot(); s.Skewed != 1 || s.Retimestamped != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestSkewSubscribeResponses(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := NewSkewDetector(NewFakeClock(now), time.Minute, true)
+	update := func(ts time.Time) *pb.SubscribeResponse {
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{
+			Update: &pb.Notification{Timestamp: ts.UnixNano()}}}
+	}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+	respChan := make(chan *pb.SubscribeResponse, 5)
+	// The old timestamps of the initial updates are not skew, but the
+	// ones in the future are.
+	respChan <- update(now.Add(-time.Hour))
+	respChan <- update(now.Add(time.Hour))
+	respChan <- sync
+	respChan <- update(now.Add(-time.Hour))
+	close(respChan)
+
+	var timestamps []int64
+	for resp := range SkewSubscribeResponses(respChan, d) {
+		if notif := resp.GetUpdate(); notif != nil {
+			timestamps = append(timestamps, notif.Timestamp)
+		}
+	}
+	expected := []int64{now.Add(-time.Hour).UnixNano(), now.UnixNano(), now.UnixNano()}
+	if len(timestamps) != len(expected) {
+		t.Fatalf("expected timestamps %v, got %v", expected, timestamps)
+	}
+	for i, ts := range expected {
+		if timestamps[i] != ts {
+			t.Errorf("expected timestamp %d to be %d, got %d", i, ts, timestamps[i])
+		}
+	}
+	if s := d.Snapshot(); s.Notifications != 2 || s.Retimestamped != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestSkewDetectorLargeSkews(t *testing.T) {
+	// The sum of these skews overflows a time.Duration.
+	skew := time.Duration(math.MaxInt64 / 2)
+	now := time.Unix(0, int64(skew)+1)
+	d := NewSkewDetector(NewFakeClock(now), 0, false)
+	for i := 0; i < 4; i++ {
+		d.Notification(&pb.Notification{Timestamp: 1})
+	}
+	if avg := d.Snapshot().AvgSkew(); avg < skew-1024 || avg > skew+1024 {
+		t.Errorf("expected an average skew of about %s, got %s", skew, avg)
+	}
+}
+
+func TestTargetSkewSubscribeResponses(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := NewTargetSkewDetector(NewFakeClock(now), time.Minute, false)
+	update := func(target string, skew time.Duration) *pb.SubscribeResponse {
+		notif := &pb.Notification{Timestamp: now.Add(-skew).UnixNano()}
+		if target != "" {
+			notif.Prefix = &pb.Path{Target: target}
+		}
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: notif}}
+	}
+	respChan := make(chan *pb.SubscribeResponse, 4)
+	respChan <- &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+	respChan <- update("", time.Second)
+	respChan <- update("dut1", time.Hour)
+	respChan <- update("dut2", -time.Second)
+	close(respChan)
+	for range TargetSkewSubscribeResponses(respChan, d, "10.0.0.1") {
+	}
+
+	stats := d.Snapshot()
+	expected := map[string]time.Duration{
+		"10.0.0.1": time.Second,
+		"dut1":     time.Hour,
+		"dut2":     -time.Second,
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("expected the stats of %d targets, got %v", len(expected), stats)
+	}
+	for target, skew := range expected {
+		if s := stats[target]; s.Notifications != 1 || s.Last != skew {
+			t.Errorf("unexpected stats of %s: %+v", target, s)
+		}
+	}
+	if stats["dut1"].Skewed != 1 || stats["dut2"].Skewed != 0 {
+		t.Errorf("expected only dut1 to be skewed, got %v", stats)
+	}
+}
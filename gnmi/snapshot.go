@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetSnapshotViaSubscribe subscribes to paths and returns the values of the
+// leaves sent by the target up to its sync response, keyed by their path
+// as formatted by StrPath and prefixed with their origin, if any, like
+// "eos_native:/Kernel/sysinfo/uptime". Unlike a Get, the values are the
+// individual leaves rather than JSON subtrees. It subscribes in ONCE
+// mode, or in STREAM mode closed after the sync response for the targets
+// not implementing ONCE subscriptions, such as older EOS releases for
+// eos_native paths.
+func GetSnapshotViaSubscribe(ctx context.Context, client pb.GNMIClient,
+	paths []*pb.Path) (map[string]*pb.TypedValue, error) {
+	subs := make([]*pb.Subscription, len(paths))
+	for i, p := range paths {
+		subs[i] = &pb.Subscription{Path: p}
+	}
+	snapshot, err := subscribeSnapshot(ctx, client, pb.SubscriptionList_ONCE, subs)
+	if status.Code(err) == codes.Unimplemented {
+		snapshot, err = subscribeSnapshot(ctx, client, pb.SubscriptionList_STREAM, subs)
+	}
+	return snapshot, err
+}
+
+func subscribeSnapshot(ctx context.Context, client pb.GNMIClient,
+	mode pb.SubscriptionList_Mode, subs []*pb.Subscription) (map[string]*pb.TypedValue, error) {
+	// Cancelling the context closes the STREAM subscription.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Subscribe(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&pb.SubscribeRequest{
+		Request: &pb.SubscribeRequest_Subscribe{
+			Subscribe: &pb.SubscriptionList{Mode: mode, Subscription: subs},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	snapshot := map[string]*pb.TypedValue{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("Subscribe %s ended before the sync response", mode)
+		} else if err != nil {
+			return nil, err
+		}
+		switch resp := resp.Response.(type) {
+		case *pb.SubscribeResponse_SyncResponse:
+			return snapshot, nil
+		case *pb.SubscribeResponse_Update:
+			notif := resp.Update
+			for _, p := range notif.Delete {
+				deleteSnapshotSubtree(snapshot, snapshotKey(notif.Prefix, p))
+			}
+			for _, u := range notif.Update {
+				snapshot[snapshotKey(notif.Prefix, u.Path)] = u.Val
+			}
+		}
+	}
+}
+
+func snapshotKey(prefix, p *pb.Path) string {
+	full := fullPath(prefix, p)
+	if full.Origin == "" {
+		return StrPath(full)
+	}
+	return full.Origin + ":" + StrPath(full)
+}
+
+// deleteSnapshotSubtree deletes the leaf at key and the leaves below it.
+func deleteSnapshotSubtree(snapshot map[string]*pb.TypedValue, key string) {
+	delete(snapshot, key)
+	subtree := strings.TrimSuffix(key, "/") + "/"
+	for k := range snapshot {
+		if strings.HasPrefix(k, subtree) {
+			delete(snapshot, k)
+		}
+	}
+}
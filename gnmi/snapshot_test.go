@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotServer sends a sync to every subscription, in STREAM mode only
+// if onceUnimplemented is set.
+type snapshotServer struct {
+	pb.UnimplementedGNMIServer
+	onceUnimplemented bool
+}
+
+func (s *snapshotServer) Subscribe(stream pb.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	mode := req.GetSubscribe().GetMode()
+	if mode == pb.SubscriptionList_ONCE && s.onceUnimplemented {
+		return status.Error(codes.Unimplemented, "ONCE not supported")
+	}
+	prefix := &pb.Path{Origin: "eos_native", Elem: []*pb.PathElem{{Name: "Kernel"}}}
+	for _, notif := range []*pb.Notification{{
+		Prefix: prefix,
+		Update: []*pb.Update{
+			{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "sysinfo"}, {Name: "uptime"}}},
+				Val: TypedValue(uint64(42))},
+			{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "sysinfo"}, {Name: "load"}}},
+				Val: TypedValue(0.5)},
+			{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "proc"}, {Name: "count"}}},
+				Val: TypedValue(uint64(100))},
+		},
+	}, {
+		Prefix: prefix,
+		Delete: []*pb.Path{{Elem: []*pb.PathElem{{Name: "proc"}}}},
+		Update: []*pb.Update{{Path: &pb.Path{Elem: []*pb.PathElem{{Name: "hostname"}}},
+			Val: TypedValue("switch1")}},
+	}} {
+		resp := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: notif}}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	if err := stream.Send(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+		return err
+	}
+	if mode == pb.SubscriptionList_STREAM {
+		<-stream.Context().Done()
+	}
+	return nil
+}
+
+func TestGetSnapshotViaSubscribe(t *testing.T) {
+	expected := map[string]*pb.TypedValue{
+		"eos_native:/Kernel/sysinfo/uptime": TypedValue(uint64(42)),
+		"eos_native:/Kernel/sysinfo/load":   TypedValue(0.5),
+		"eos_native:/Kernel/hostname":       TypedValue("switch1"),
+	}
+	for name, onceUnimplemented := range map[string]bool{"once": false, "stream": true} {
+		t.Run(name, func(t *testing.T) {
+			test.CheckGoroutines(t)
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := grpc.NewServer()
+			pb.RegisterGNMIServer(s, &snapshotServer{onceUnimplemented: onceUnimplemented})
+			go s.Serve(l)
+			defer s.Stop()
+
+			conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			snapshot, err := GetSnapshotViaSubscribe(ctx, pb.NewGNMIClient(conn),
+				[]*pb.Path{{Origin: "eos_native", Elem: []*pb.PathElem{{Name: "Kernel"}}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.DeepEqual(expected, snapshot) {
+				t.Errorf("unexpected snapshot: %s", test.Diff(expected, snapshot))
+			}
+		})
+	}
+}
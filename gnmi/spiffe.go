@@ -0,0 +1,389 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/goarista/logger"
+	"github.com/cenkalti/backoff/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SPIFFEEndpointEnv is the environment variable with the address of the
+// SPIFFE Workload API, used when Config.SPIFFEEndpoint is "env".
+const SPIFFEEndpointEnv = "SPIFFE_ENDPOINT_SOCKET"
+
+// The Workload API method streaming the X.509-SVIDs, and the header that
+// its requests must carry.
+const (
+	fetchX509SVIDMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+	spiffeHeader        = "workload.spiffe.io"
+)
+
+// X509SVID is an X.509 SPIFFE Verifiable Identity Document and the CA
+// certificates of its trust domain.
+type X509SVID struct {
+	// ID is the SPIFFE ID of the workload, e.g. spiffe://example.org/collector.
+	ID string
+	// Certificate is the certificate chain, leaf first, and private key.
+	Certificate tls.Certificate
+	// Bundle is the CA certificates of the trust domain of ID.
+	Bundle []*x509.Certificate
+}
+
+// SPIFFESource watches the X.509-SVID of the workload on the SPIFFE
+// Workload API, keeping it current as the SPIFFE agent rotates it.
+type SPIFFESource struct {
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+	done   chan struct{}
+	logger logger.Logger
+
+	mu    sync.RWMutex
+	svid  *X509SVID
+	roots *x509.CertPool
+}
+
+// NewSPIFFESource connects to the SPIFFE Workload API at endpoint, e.g.
+// unix:///run/spire/agent.sock, and waits for the first X.509-SVID. It then
+// keeps receiving the rotated X.509-SVIDs, reconnecting with a backoff and
+// logging the failures to log, if set, until Close is called.
+func NewSPIFFESource(ctx context.Context, endpoint string,
+	log logger.Logger) (*SPIFFESource, error) {
+	target, err := spiffeTarget(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(target, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	watchCtx, cancel := context.WithCancel(context.Background())
+	s := &SPIFFESource{conn: conn, cancel: cancel, done: make(chan struct{}), logger: log}
+	first := make(chan error, 1)
+	go s.watch(watchCtx, first)
+	select {
+	case err = <-first:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to fetch the X.509-SVID from %s: %s", endpoint, err)
+	}
+	return s, nil
+}
+
+// spiffeTarget returns the gRPC target of the Workload API endpoint, a
+// unix:// or tcp:// URL.
+func spiffeTarget(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid SPIFFE endpoint %q: %s", endpoint, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return "", fmt.Errorf("invalid SPIFFE endpoint %q: no socket path", endpoint)
+		}
+		return "unix://" + u.Path, nil
+	case "tcp":
+		if u.Host == "" {
+			return "", fmt.Errorf("invalid SPIFFE endpoint %q: no host", endpoint)
+		}
+		return u.Host, nil
+	}
+	return "", fmt.Errorf("invalid SPIFFE endpoint %q: expected a unix:// or tcp:// URL",
+		endpoint)
+}
+
+// Close stops watching the X.509-SVID.
+func (s *SPIFFESource) Close() error {
+	s.cancel()
+	<-s.done
+	return s.conn.Close()
+}
+
+// SVID returns the current X.509-SVID.
+func (s *SPIFFESource) SVID() *X509SVID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.svid
+}
+
+func (s *SPIFFESource) setSVID(svid *X509SVID) {
+	roots := x509.NewCertPool()
+	for _, cert := range svid.Bundle {
+		roots.AddCert(cert)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.svid = svid
+	s.roots = roots
+}
+
+// watch fetches the X.509-SVIDs until ctx is done, sending the outcome of
+// the first fetch to first.
+func (s *SPIFFESource) watch(ctx context.Context, first chan<- error) {
+	defer close(s.done)
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 0
+	for {
+		err := s.fetch(ctx, func() {
+			b.Reset()
+			if first != nil {
+				first <- nil
+				first = nil
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if first != nil {
+			first <- err
+			return
+		}
+		wait := b.NextBackOff()
+		if s.logger != nil {
+			s.logger.Errorf("failed to fetch the X.509-SVID, retrying in %s: %s", wait, err)
+		}
+		select {
+		case <-RealClock.After(wait):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fetch streams the X.509-SVIDs from the Workload API, calling onUpdate
+// once each one is set.
+func (s *SPIFFESource) fetch(ctx context.Context, onUpdate func()) error {
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(ctx, spiffeHeader, "true"))
+	defer cancel()
+	stream, err := s.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true},
+		fetchX509SVIDMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+	// The X509SVIDRequest has no fields.
+	if err := stream.SendMsg([]byte(nil)); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		var b []byte
+		if err := stream.RecvMsg(&b); err != nil {
+			return err
+		}
+		svid, err := parseX509SVIDResponse(b)
+		if err != nil {
+			return err
+		}
+		s.setSVID(svid)
+		onUpdate()
+	}
+}
+
+// rawCodec passes the protobuf messages through as bytes, the Workload API
+// messages being decoded with protowire.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case *[]byte:
+		return *v, nil
+	}
+	return nil, fmt.Errorf("unexpected message type %T", v)
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+// parseX509SVIDResponse parses the first, default, X.509-SVID of an
+// X509SVIDResponse message.
+func parseX509SVIDResponse(b []byte) (*X509SVID, error) {
+	var msg []byte
+	if err := protoBytesFields(b, func(num protowire.Number, v []byte) {
+		if num == 1 && msg == nil { // svids
+			msg = v
+		}
+	}); err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, errors.New("no X.509-SVID in the Workload API response")
+	}
+	var id string
+	var certs, key, bundle []byte
+	if err := protoBytesFields(msg, func(num protowire.Number, v []byte) {
+		switch num {
+		case 1: // spiffe_id
+			id = string(v)
+		case 2: // x509_svid
+			certs = v
+		case 3: // x509_svid_key
+			key = v
+		case 4: // bundle
+			bundle = v
+		}
+	}); err != nil {
+		return nil, err
+	}
+	chain, err := x509.ParseCertificates(certs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X.509-SVID of %s: %s", id, err)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificate in the X.509-SVID of %s", id)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X.509-SVID key of %s: %s", id, err)
+	}
+	roots, err := x509.ParseCertificates(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle of %s: %s", id, err)
+	}
+	svid := &X509SVID{
+		ID:          id,
+		Certificate: tls.Certificate{PrivateKey: privateKey, Leaf: chain[0]},
+		Bundle:      roots,
+	}
+	for _, cert := range chain {
+		svid.Certificate.Certificate = append(svid.Certificate.Certificate, cert.Raw)
+	}
+	return svid, nil
+}
+
+// protoBytesFields calls f with the number and value of the length-delimited
+// fields of the protobuf message b, skipping the other fields.
+func protoBytesFields(b []byte, f func(num protowire.Number, v []byte)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			f(num, v)
+			b = b[n:]
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// TLSConfig returns a tls.Config presenting the current X.509-SVID of s as
+// client certificate and accepting the server certificates with a SPIFFE ID
+// of trustDomain, e.g. spiffe://example.org or example.org, signed by the
+// current bundle. If trustDomain is empty, the trust domain of the
+// X.509-SVID of s is used.
+func (s *SPIFFESource) TLSConfig(trustDomain string) (*tls.Config, error) {
+	if trustDomain != "" {
+		var err error
+		if trustDomain, err = parseTrustDomain(trustDomain); err != nil {
+			return nil, err
+		}
+	}
+	return &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &s.SVID().Certificate, nil
+		},
+		// The server certificate is verified by VerifyPeerCertificate
+		// against the current bundle, and its SPIFFE ID rather than its
+		// name.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return s.verifyPeer(rawCerts, trustDomain)
+		},
+	}, nil
+}
+
+func (s *SPIFFESource) verifyPeer(rawCerts [][]byte, trustDomain string) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no server certificate")
+	}
+	s.mu.RLock()
+	svid, roots := s.svid, s.roots
+	s.mu.RUnlock()
+	if trustDomain == "" {
+		var err error
+		if trustDomain, err = parseTrustDomain(svid.ID); err != nil {
+			return err
+		}
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	var leaf *x509.Certificate
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			leaf = cert
+		} else {
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return fmt.Errorf("failed to verify the server X.509-SVID: %s", err)
+	}
+	if len(leaf.URIs) != 1 || leaf.URIs[0].Scheme != "spiffe" {
+		return errors.New("the server certificate is not an X.509-SVID: " +
+			"expected a single spiffe:// URI")
+	}
+	if id := leaf.URIs[0]; id.Host != trustDomain {
+		return fmt.Errorf("the SPIFFE ID %s of the server is not in the trust domain %s",
+			id, trustDomain)
+	}
+	return nil
+}
+
+// parseTrustDomain returns the trust domain of a SPIFFE ID or trust
+// domain, e.g. example.org for spiffe://example.org/collector.
+func parseTrustDomain(s string) (string, error) {
+	if !strings.Contains(s, "://") {
+		s = "spiffe://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "spiffe" || u.Host == "" {
+		return "", fmt.Errorf("invalid SPIFFE trust domain %q", s)
+	}
+	return u.Host, nil
+}
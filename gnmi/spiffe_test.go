@@ -0,0 +1,273 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// issue returns the DER certificate and PKCS#8 key of the X.509-SVID of id.
+func (ca *testCA) issue(t *testing.T, id string, serial int64) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{u},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, keyDER
+}
+
+// x509SVIDResponse encodes an X509SVIDResponse message.
+func x509SVIDResponse(id string, cert, key, bundle []byte) []byte {
+	var svid []byte
+	svid = protowire.AppendTag(svid, 1, protowire.BytesType)
+	svid = protowire.AppendString(svid, id)
+	svid = protowire.AppendTag(svid, 2, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, cert)
+	svid = protowire.AppendTag(svid, 3, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, key)
+	svid = protowire.AppendTag(svid, 4, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, bundle)
+	svid = protowire.AppendTag(svid, 5, protowire.VarintType)
+	svid = protowire.AppendVarint(svid, 1)
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, svid)
+}
+
+// serveWorkloadAPI serves a Workload API on a unix socket, sending the
+// responses of svids to every FetchX509SVID call, and returns its address
+// and the number of FetchX509SVID calls under way.
+func serveWorkloadAPI(t *testing.T, svids <-chan []byte) (string, *atomic.Int32) {
+	var active atomic.Int32
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}))
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "SpiffeWorkloadAPI",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "FetchX509SVID",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				md, _ := metadata.FromIncomingContext(stream.Context())
+				if v := md.Get(spiffeHeader); len(v) != 1 || v[0] != "true" {
+					return status.Error(codes.InvalidArgument, "missing security header")
+				}
+				active.Add(1)
+				defer active.Add(-1)
+				var req []byte
+				if err := stream.RecvMsg(&req); err != nil {
+					return err
+				}
+				for {
+					select {
+					case b := <-svids:
+						if err := stream.SendMsg(b); err != nil {
+							return err
+						}
+					case <-stream.Context().Done():
+						return nil
+					}
+				}
+			},
+		}},
+	}, nil)
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return "unix://" + sock, &active
+}
+
+func TestSPIFFESource(t *testing.T) {
+	ca := newTestCA(t)
+	svids := make(chan []byte, 1)
+	endpoint, _ := serveWorkloadAPI(t, svids)
+
+	cert, key := ca.issue(t, "spiffe://example.org/collector", 2)
+	svids <- x509SVIDResponse("spiffe://example.org/collector", cert, key, ca.cert.Raw)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := NewSPIFFESource(ctx, endpoint, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	svid := s.SVID()
+	if svid.ID != "spiffe://example.org/collector" ||
+		svid.Certificate.Leaf.SerialNumber.Int64() != 2 || len(svid.Bundle) != 1 {
+		t.Fatalf("unexpected X.509-SVID %+v", svid)
+	}
+
+	tlsConfig, err := s.TLSConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key = ca.issue(t, "spiffe://example.org/collector", 3)
+	svids <- x509SVIDResponse("spiffe://example.org/collector", cert, key, ca.cert.Raw)
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		c, err := tlsConfig.GetClientCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Leaf.SerialNumber.Int64() == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the X.509-SVID was not rotated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	serverCert, _ := ca.issue(t, "spiffe://example.org/switch", 4)
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{serverCert}, nil); err != nil {
+		t.Errorf("unexpected error verifying the server: %s", err)
+	}
+	otherCert, _ := ca.issue(t, "spiffe://other.org/switch", 5)
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{otherCert}, nil); err == nil {
+		t.Error("expected an error verifying a server of another trust domain")
+	}
+	otherCA := newTestCA(t)
+	untrustedCert, _ := otherCA.issue(t, "spiffe://example.org/switch", 6)
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{untrustedCert}, nil); err == nil {
+		t.Error("expected an error verifying a server signed by another CA")
+	}
+
+	tlsConfig, err = s.TLSConfig("spiffe://other.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{otherCert}, nil); err != nil {
+		t.Errorf("unexpected error verifying a server of other.org: %s", err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{serverCert}, nil); err == nil {
+		t.Error("expected an error verifying a server of example.org")
+	}
+}
+
+func TestDialSPIFFESourceLifetime(t *testing.T) {
+	ca := newTestCA(t)
+	svids := make(chan []byte, 1)
+	endpoint, active := serveWorkloadAPI(t, svids)
+	cert, key := ca.issue(t, "spiffe://example.org/collector", 2)
+	svid := x509SVIDResponse("spiffe://example.org/collector", cert, key, ca.cert.Raw)
+	waitActive := func(n int32) {
+		t.Helper()
+		for deadline := time.Now().Add(10 * time.Second); active.Load() != n; {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d watches of the X.509-SVID, got %d", n, active.Load())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The source is closed if the dial fails.
+	svids <- svid
+	_, err := DialContextConn(ctx, &Config{Addr: "127.0.0.1:1", SPIFFEEndpoint: endpoint,
+		CAData: []byte("ca")})
+	if err == nil {
+		t.Fatal("expected an error with both SPIFFE and a CA")
+	}
+	waitActive(0)
+
+	// The source is closed with the connection.
+	svids <- svid
+	conn, err := DialContextConn(ctx, &Config{Addr: "127.0.0.1:1", SPIFFEEndpoint: endpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitActive(1)
+	conn.Close()
+	waitActive(0)
+}
+
+func TestSPIFFETarget(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		"unix:///run/spire/agent.sock": "unix:///run/spire/agent.sock",
+		"tcp://127.0.0.1:8081":         "127.0.0.1:8081",
+		"":                             "",
+		"unix://":                      "",
+		"/run/spire/agent.sock":        "",
+	} {
+		target, err := spiffeTarget(endpoint)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", endpoint, target)
+			}
+		} else if err != nil || target != expected {
+			t.Errorf("%q: expected %q, got %q, %v", endpoint, expected, target, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// RPCStats holds the statistics collected for one gRPC method.
+type RPCStats struct {
+	// RPCs is the number of RPCs started.
+	RPCs uint64
+	// Errors is the number of RPCs that ended with an error.
+	Errors uint64
+	// MsgsIn and MsgsOut are the number of messages received and sent.
+	MsgsIn  uint64
+	MsgsOut uint64
+	// BytesIn and BytesOut are the number of bytes received and sent on the
+	// wire, including gRPC framing and compression.
+	BytesIn  uint64
+	BytesOut uint64
+	// Latency is the total duration of the RPCs that ended.
+	Latency time.Duration
+	// Ended is the number of RPCs that ended.
+	Ended uint64
+}
+
+// AvgLatency returns the average duration of the RPCs that ended.
+func (s RPCStats) AvgLatency() time.Duration {
+	if s.Ended == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Ended)
+}
+
+// Stats is a grpc stats.Handler collecting per-method RPCStats.
+// Install it on a client connection with WithStats.
+type Stats struct {
+	mu      sync.Mutex
+	methods map[string]*RPCStats
+}
+
+var _ stats.Handler = (*Stats)(nil)
+
+// NewStats returns a new Stats.
+func NewStats() *Stats {
+	return &Stats{methods: make(map[string]*RPCStats)}
+}
+
+// WithStats returns a DialOption that collects the statistics of all the
+// RPCs made on the connection into s. It can be added to Config.DialOptions.
+func WithStats(s *Stats) grpc.DialOption {
+	return grpc.WithStatsHandler(s)
+}
+
+type statsMethodKey struct{}
+
+// TagRPC implements stats.Handler.
+func (s *Stats) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, statsMethodKey{}, info.FullMethodName)
+}
+
+// HandleRPC implements stats.Handler.
+func (s *Stats) HandleRPC(ctx context.Context, rs stats.RPCStats) {
+	method, _ := ctx.Value(statsMethodKey{}).(string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.methods[method]
+	if !ok {
+		m = &RPCStats{}
+		s.methods[method] = m
+	}
+	switch rs := rs.(type) {
+	case *stats.Begin:
+		m.RPCs++
+	case *stats.InPayload:
+		m.MsgsIn++
+		m.BytesIn += uint64(rs.WireLength)
+	case *stats.OutPayload:
+		m.MsgsOut++
+		m.BytesOut += uint64(rs.WireLength)
+	case *stats.End:
+		m.Ended++
+		m.Latency += rs.EndTime.Sub(rs.BeginTime)
+		if rs.Error != nil {
+			m.Errors++
+		}
+	}
+}
+
+// TagConn implements stats.Handler.
+func (s *Stats) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler.
+func (s *Stats) HandleConn(ctx context.Context, cs stats.ConnStats) {}
+
+// Snapshot returns a copy of the statistics collected so far, indexed by
+// full method name (e.g. "/gnmi.gNMI/Subscribe").
+func (s *Stats) Snapshot() map[string]RPCStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := make(map[string]RPCStats, len(s.methods))
+	for method, m := range s.methods {
+		snap[method] = *m
+	}
+	return snap
+}
+
+// WriteTo writes the statistics collected so far to w, one line per method.
+func (s *Stats) WriteTo(w io.Writer) (int64, error) {
+	snap := s.Snapshot()
+	methods := make([]string, 0, len(snap))
+	for method := range snap {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	var total int64
+	for _, method := range methods {
+		m := snap[method]
+		n, err := fmt.Fprintf(w, "%s: %d rpcs %d errors %d msgs in %d msgs out "+
+			"%d bytes in %d bytes out %s avg latency\n", method, m.RPCs, m.Errors,
+			m.MsgsIn, m.MsgsOut, m.BytesIn, m.BytesOut, m.AvgLatency())
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+	"google.golang.org/grpc/stats"
+)
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+	begin := time.Unix(1000, 0)
+	for i, rpc := range []struct {
+		method string
+		err    error
+		in     []int
+		out    []int
+	}{{
+		method: "/gnmi.gNMI/Subscribe",
+		in:     []int{10, 20, 30},
+		out:    []int{5},
+	}, {
+		method: "/gnmi.gNMI/Get",
+		in:     []int{100},
+		out:    []int{7},
+	}, {
+		method: "/gnmi.gNMI/Get",
+		err:    errors.New("failed"),
+		out:    []int{8},
+	}} {
+		ctx := s.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: rpc.method})
+		s.HandleRPC(ctx, &stats.Begin{Client: true, BeginTime: begin})
+		for _, n := range rpc.out {
+			s.HandleRPC(ctx, &stats.OutPayload{Client: true, WireLength: n})
+		}
+		for _, n := range rpc.in {
+			s.HandleRPC(ctx, &stats.InPayload{Client: true, WireLength: n})
+		}
+		s.HandleRPC(ctx, &stats.End{Client: true, BeginTime: begin,
+			EndTime: begin.Add(time.Duration(i+1) * time.Second), Error: rpc.err})
+	}
+
+	expected := map[string]RPCStats{
+		"/gnmi.gNMI/Subscribe": {
+			RPCs: 1, MsgsIn: 3, MsgsOut: 1, BytesIn: 60, BytesOut: 5,
+			Latency: time.Second, Ended: 1,
+		},
+		"/gnmi.gNMI/Get": {
+			RPCs: 2, Errors: 1, MsgsIn: 1, MsgsOut: 2, BytesIn: 100, BytesOut: 15,
+			Latency: 5 * time.Second, Ended: 2,
+		},
+	}
+	snap := s.Snapshot()
+	if d := test.Diff(expected, snap); d != "" {
+		t.Errorf("unexpected stats: %s", d)
+	}
+	if l := snap["/gnmi.gNMI/Get"].AvgLatency(); l != 2500*time.Millisecond {
+		t.Errorf("expected average latency of 2.5s, got %s", l)
+	}
+
+	var sb strings.Builder
+	if _, err := s.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	exp := "/gnmi.gNMI/Get: 2 rpcs 1 errors 1 msgs in 2 msgs out " +
+		"100 bytes in 15 bytes out 2.5s avg latency\n" +
+		"/gnmi.gNMI/Subscribe: 1 rpcs 0 errors 3 msgs in 1 msgs out " +
+		"60 bytes in 5 bytes out 1s avg latency\n"
+	if sb.String() != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, sb.String())
+	}
+}
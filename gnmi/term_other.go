@@ -0,0 +1,14 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package gnmi
+
+import "errors"
+
+// stub: readPassword is not supported on this platform.
+func readPassword(fd int) ([]byte, error) {
+	return nil, errors.New("reading a password on the terminal is not supported")
+}
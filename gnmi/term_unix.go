@@ -0,0 +1,44 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package gnmi
+
+import (
+	"io"
+
+	"golang.org/x/sys/unix"
+)
+
+// fdReader reads a file descriptor without taking ownership of it.
+type fdReader int
+
+func (r fdReader) Read(b []byte) (int, error) {
+	n, err := unix.Read(int(r), b)
+	if n < 0 {
+		n = 0
+	}
+	if n == 0 && err == nil {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// readPassword reads a line on the terminal fd with the echo turned off.
+func readPassword(fd int) ([]byte, error) {
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+	noEcho := *state
+	noEcho.Lflag &^= unix.ECHO
+	noEcho.Lflag |= unix.ICANON | unix.ISIG
+	noEcho.Iflag |= unix.ICRNL
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &noEcho); err != nil {
+		return nil, err
+	}
+	defer unix.IoctlSetTermios(fd, ioctlSetTermios, state)
+	return readLine(fdReader(fd))
+}
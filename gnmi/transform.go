@@ -0,0 +1,334 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Transformer transforms the SubscribeResponses received from a target
+// before they are exported, e.g. to rename paths or drop the ones not
+// needed downstream.
+type Transformer interface {
+	// Transform returns the responses resp is transformed into: none to
+	// drop it, or more than one to split it. It may modify resp.
+	Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse
+}
+
+// TransformerFunc adapts a function to a Transformer.
+type TransformerFunc func(resp *pb.SubscribeResponse) []*pb.SubscribeResponse
+
+// Transform calls f(resp).
+func (f TransformerFunc) Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+	return f(resp)
+}
+
+// TransformUsage describes the syntax of the transforms parsed by
+// ParseTransformer, for the usage of the flags setting Transformers.
+const TransformUsage = "transform applied to the notifications received, in order. " +
+	"Can be repeated. One of 'rewrite:REGEX=>REPLACEMENT' to rewrite the paths " +
+	"matching REGEX (with $1 for the submatches), 'scale:REGEX=>FACTOR' to " +
+	"multiply the numeric values of the paths matching REGEX by FACTOR, or " +
+	"'drop:REGEX' to drop the updates and deletes of the paths matching REGEX"
+
+// Transformers is a chain of Transformers, applied in order to the
+// responses each returns. It implements flag.Value, parsing each value
+// with ParseTransformer.
+type Transformers []Transformer
+
+// Transform runs resp through the chain of transformers.
+func (t Transformers) Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+	resps := []*pb.SubscribeResponse{resp}
+	for _, tr := range t {
+		var transformed []*pb.SubscribeResponse
+		for _, resp := range resps {
+			transformed = append(transformed, tr.Transform(resp)...)
+		}
+		if resps = transformed; len(resps) == 0 {
+			break
+		}
+	}
+	return resps
+}
+
+// TransformFlag registers the -transform flag, which can be repeated, and
+// returns the Transformers it sets.
+func TransformFlag() *Transformers {
+	var t Transformers
+	flag.Var(&t, "transform", "Apply a "+TransformUsage)
+	return &t
+}
+
+// String implements flag.Value.
+func (t *Transformers) String() string {
+	if t == nil {
+		return ""
+	}
+	specs := make([]string, len(*t))
+	for i, tr := range *t {
+		specs[i] = fmt.Sprint(tr)
+	}
+	return strings.Join(specs, ",")
+}
+
+// Set implements flag.Value.
+func (t *Transformers) Set(s string) error {
+	tr, err := ParseTransformer(s)
+	if err != nil {
+		return err
+	}
+	*t = append(*t, tr)
+	return nil
+}
+
+// ParseTransformer parses a built-in transform, as described in
+// TransformUsage.
+func ParseTransformer(s string) (Transformer, error) {
+	name, arg, _ := strings.Cut(s, ":")
+	switch name {
+	case "rewrite":
+		expr, repl, ok := strings.Cut(arg, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid transform %q: expected rewrite:REGEX=>REPLACEMENT",
+				s)
+		}
+		return NewPathRewriter(expr, repl)
+	case "scale":
+		expr, factor, ok := strings.Cut(arg, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid transform %q: expected scale:REGEX=>FACTOR", s)
+		}
+		f, err := strconv.ParseFloat(factor, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid factor in transform %q: %s", s, err)
+		}
+		return NewValueScaler(expr, f)
+	case "drop":
+		return NewPathDropper(arg)
+	}
+	return nil, fmt.Errorf("unknown transform %q: expected rewrite, scale or drop", s)
+}
+
+// TransformSubscribeResponses forwards the responses t transforms the
+// SubscribeResponses of respChan into to the returned channel. The
+// returned channel is closed after respChan is.
+func TransformSubscribeResponses(respChan <-chan *pb.SubscribeResponse,
+	t Transformer) chan *pb.SubscribeResponse {
+	transformed := make(chan *pb.SubscribeResponse)
+	go func() {
+		defer close(transformed)
+		for resp := range respChan {
+			for _, resp := range t.Transform(resp) {
+				transformed <- resp
+			}
+		}
+	}()
+	return transformed
+}
+
+// pathMatcher matches the full paths of the updates and deletes of a
+// notification, i.e. joined with its prefix, against a regular expression.
+type pathMatcher struct {
+	re *regexp.Regexp
+}
+
+// joinedStrPath returns the path p joined with prefix, as matched by the
+// transforms, without cloning them.
+func joinedStrPath(prefix, p *pb.Path) string {
+	return StrPath(JoinPaths(prefix, p))
+}
+
+func newPathMatcher(name, expr string) (pathMatcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return pathMatcher{}, fmt.Errorf("invalid regular expression in %s transform: %s",
+			name, err)
+	}
+	return pathMatcher{re: re}, nil
+}
+
+// PathRewriter is a Transformer rewriting the paths of the updates and
+// deletes matching a regular expression. The notifications with a path
+// rewritten have their updates and deletes set to their full paths, and
+// only the origin and target left in their prefix.
+type PathRewriter struct {
+	pathMatcher
+	repl string
+}
+
+// NewPathRewriter returns a PathRewriter replacing the matches of expr in
+// the paths with repl, as regexp.Regexp.ReplaceAllString does.
+func NewPathRewriter(expr, repl string) (*PathRewriter, error) {
+	m, err := newPathMatcher("rewrite", expr)
+	if err != nil {
+		return nil, err
+	}
+	return &PathRewriter{pathMatcher: m, repl: repl}, nil
+}
+
+func (r *PathRewriter) String() string {
+	return "rewrite:" + r.re.String() + "=>" + r.repl
+}
+
+func (r *PathRewriter) rewrite(prefix, path *pb.Path) (*pb.Path, bool) {
+	full := joinedStrPath(prefix, path)
+	rewritten := r.re.ReplaceAllString(full, r.repl)
+	if rewritten == full {
+		return path, false
+	}
+	p, err := ParseGNMIElements(SplitPath(rewritten))
+	if err != nil {
+		// The invalid rewrites are left as is.
+		return path, false
+	}
+	p.Element = nil
+	return p, true
+}
+
+// Transform implements Transformer.
+func (r *PathRewriter) Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+	notif := resp.GetUpdate()
+	if notif == nil {
+		return []*pb.SubscribeResponse{resp}
+	}
+	paths := make([]*pb.Path, 0, len(notif.Update)+len(notif.Delete))
+	changed := false
+	for _, u := range notif.Update {
+		p, ok := r.rewrite(notif.Prefix, u.Path)
+		paths = append(paths, p)
+		changed = changed || ok
+	}
+	for _, d := range notif.Delete {
+		p, ok := r.rewrite(notif.Prefix, d)
+		paths = append(paths, p)
+		changed = changed || ok
+	}
+	if !changed {
+		return []*pb.SubscribeResponse{resp}
+	}
+	// The paths not rewritten are relative to the prefix, which is
+	// replaced by their full paths.
+	joined := func(p *pb.Path) *pb.Path {
+		return JoinPaths(notif.Prefix, p)
+	}
+	for i, u := range notif.Update {
+		if u.Path == paths[i] {
+			u.Path = joined(u.Path)
+		} else {
+			u.Path = paths[i]
+		}
+	}
+	for i, d := range notif.Delete {
+		if p := paths[len(notif.Update)+i]; d == p {
+			notif.Delete[i] = joined(d)
+		} else {
+			notif.Delete[i] = p
+		}
+	}
+	if notif.Prefix != nil {
+		notif.Prefix = &pb.Path{Origin: notif.Prefix.Origin, Target: notif.Prefix.Target}
+	}
+	return []*pb.SubscribeResponse{resp}
+}
+
+// ValueScaler is a Transformer multiplying the numeric values of the
+// updates whose path matches a regular expression by a factor, e.g. to
+// convert units. The scaled values are doubles.
+type ValueScaler struct {
+	pathMatcher
+	factor float64
+}
+
+// NewValueScaler returns a ValueScaler multiplying the values of the
+// paths matching expr by factor.
+func NewValueScaler(expr string, factor float64) (*ValueScaler, error) {
+	m, err := newPathMatcher("scale", expr)
+	if err != nil {
+		return nil, err
+	}
+	return &ValueScaler{pathMatcher: m, factor: factor}, nil
+}
+
+func (s *ValueScaler) String() string {
+	return "scale:" + s.re.String() + "=>" + strconv.FormatFloat(s.factor, 'g', -1, 64)
+}
+
+// Transform implements Transformer.
+func (s *ValueScaler) Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+	notif := resp.GetUpdate()
+	if notif == nil {
+		return []*pb.SubscribeResponse{resp}
+	}
+	for _, u := range notif.Update {
+		var v float64
+		switch val := u.GetVal().GetValue().(type) {
+		case *pb.TypedValue_IntVal:
+			v = float64(val.IntVal)
+		case *pb.TypedValue_UintVal:
+			v = float64(val.UintVal)
+		case *pb.TypedValue_FloatVal:
+			v = float64(val.FloatVal)
+		case *pb.TypedValue_DoubleVal:
+			v = val.DoubleVal
+		default:
+			continue
+		}
+		if s.re.MatchString(joinedStrPath(notif.Prefix, u.Path)) {
+			u.Val = &pb.TypedValue{Value: &pb.TypedValue_DoubleVal{DoubleVal: v * s.factor}}
+		}
+	}
+	return []*pb.SubscribeResponse{resp}
+}
+
+// PathDropper is a Transformer dropping the updates and deletes whose path
+// matches a regular expression. The notifications left without updates
+// and deletes are dropped.
+type PathDropper struct {
+	pathMatcher
+}
+
+// NewPathDropper returns a PathDropper dropping the paths matching expr.
+func NewPathDropper(expr string) (*PathDropper, error) {
+	m, err := newPathMatcher("drop", expr)
+	if err != nil {
+		return nil, err
+	}
+	return &PathDropper{pathMatcher: m}, nil
+}
+
+func (d *PathDropper) String() string {
+	return "drop:" + d.re.String()
+}
+
+// Transform implements Transformer.
+func (d *PathDropper) Transform(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+	notif := resp.GetUpdate()
+	if notif == nil || len(notif.Update)+len(notif.Delete) == 0 {
+		return []*pb.SubscribeResponse{resp}
+	}
+	updates := notif.Update[:0]
+	for _, u := range notif.Update {
+		if !d.re.MatchString(joinedStrPath(notif.Prefix, u.Path)) {
+			updates = append(updates, u)
+		}
+	}
+	deletes := notif.Delete[:0]
+	for _, p := range notif.Delete {
+		if !d.re.MatchString(joinedStrPath(notif.Prefix, p)) {
+			deletes = append(deletes, p)
+		}
+	}
+	notif.Update, notif.Delete = updates, deletes
+	if len(updates)+len(deletes) == 0 {
+		return nil
+	}
+	return []*pb.SubscribeResponse{resp}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"flag"
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTransformers(t *testing.T) {
+	path := func(s string) *pb.Path {
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Element = nil
+		return p
+	}
+	response := func(prefix string, updates map[string]*pb.TypedValue,
+		deletes ...string) *pb.SubscribeResponse {
+		n := &pb.Notification{Timestamp: 1, Prefix: path(prefix)}
+		n.Prefix.Target = "dut"
+		for _, p := range []string{"in-octets", "out-octets", "name"} {
+			if v, ok := updates[p]; ok {
+				n.Update = append(n.Update, &pb.Update{Path: path(p), Val: v})
+			}
+		}
+		for _, p := range deletes {
+			n.Delete = append(n.Delete, path(p))
+		}
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: n}}
+	}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+
+	var transforms Transformers
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&transforms, "transform", TransformUsage)
+	if err := fs.Parse([]string{
+		"-transform", `drop:/name$`,
+		"-transform", `rewrite:^/interfaces/interface\[name=([^\]]+)\]/state/counters/=>` +
+			`/counters[intf=$1]/`,
+		"-transform", `scale:octets$=>8`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if s := transforms.String(); s != `drop:/name$,rewrite:^/interfaces/interface\[name=`+
+		`([^\]]+)\]/state/counters/=>/counters[intf=$1]/,scale:octets$=>8` {
+		t.Errorf("unexpected transforms %s", s)
+	}
+
+	prefix := "/interfaces/interface[name=Ethernet1]/state/counters"
+	resps := transforms.Transform(response(prefix, map[string]*pb.TypedValue{
+		"in-octets":  TypedValue(uint64(10)),
+		"out-octets": TypedValue(int64(20)),
+		"name":       TypedValue("Ethernet1"),
+	}, "name", "out-octets"))
+	expected := &pb.Notification{
+		Timestamp: 1,
+		Prefix:    &pb.Path{Target: "dut"},
+		Update: []*pb.Update{
+			{Path: path("/counters[intf=Ethernet1]/in-octets"), Val: TypedValue(float64(80))},
+			{Path: path("/counters[intf=Ethernet1]/out-octets"), Val: TypedValue(float64(160))},
+		},
+		Delete: []*pb.Path{path("/counters[intf=Ethernet1]/out-octets")},
+	}
+	if len(resps) != 1 || !proto.Equal(resps[0].GetUpdate(), expected) {
+		t.Errorf("expected %s, got %v", expected, resps)
+	}
+
+	// The paths not rewritten are expanded along with the rewritten ones.
+	resps = transforms.Transform(response("/interfaces", map[string]*pb.TypedValue{
+		"in-octets": TypedValue(uint64(1)),
+	}, "interface[name=Ethernet2]/state/counters/in-octets"))
+	expected = &pb.Notification{
+		Timestamp: 1,
+		Prefix:    &pb.Path{Target: "dut"},
+		Update: []*pb.Update{
+			{Path: path("/interfaces/in-octets"), Val: TypedValue(float64(8))},
+		},
+		Delete: []*pb.Path{path("/counters[intf=Ethernet2]/in-octets")},
+	}
+	if len(resps) != 1 || !proto.Equal(resps[0].GetUpdate(), expected) {
+		t.Errorf("expected %s, got %v", expected, resps)
+	}
+
+	// The notifications left empty are dropped, the other responses kept.
+	if resps := transforms.Transform(response(prefix, map[string]*pb.TypedValue{
+		"name": TypedValue("Ethernet1")})); len(resps) != 0 {
+		t.Errorf("expected the notification to be dropped, got %v", resps)
+	}
+	if resps := transforms.Transform(sync); len(resps) != 1 || resps[0] != sync {
+		t.Errorf("expected the sync response, got %v", resps)
+	}
+
+	// The transformers can split the responses.
+	split := Transformers{TransformerFunc(
+		func(resp *pb.SubscribeResponse) []*pb.SubscribeResponse {
+			return []*pb.SubscribeResponse{resp, resp}
+		}), transforms[2]}
+	respChan := make(chan *pb.SubscribeResponse, 1)
+	respChan <- response("/", map[string]*pb.TypedValue{"name": TypedValue("x")})
+	close(respChan)
+	n := 0
+	for range TransformSubscribeResponses(respChan, split) {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("expected 2 responses, got %d", n)
+	}
+
+	for _, s := range []string{"drop:(", "scale:x=>y", "rewrite:x", "upper:x"} {
+		if _, err := ParseTransformer(s); err == nil {
+			t.Errorf("expected an error parsing %q", s)
+		}
+	}
+}
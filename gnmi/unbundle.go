@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// UnbundleNotification splits notif, in which some targets bundle the
+// updates and deletes of many prefixes, into one Notification per group of
+// paths sharing their first depth elements, which are moved to the prefix
+// of the Notification. The last element of a path stays in the path. The
+// Notifications are returned in the order their group first appears in the
+// deletes, then in the updates of notif. An atomic Notification, or one of
+// a single group, is returned as is.
+func UnbundleNotification(notif *pb.Notification, depth int) []*pb.Notification {
+	if depth <= 0 || notif.GetAtomic() {
+		return []*pb.Notification{notif}
+	}
+	var notifs []*pb.Notification
+	groups := map[string]*pb.Notification{}
+	group := func(path *pb.Path) (*pb.Notification, *pb.Path) {
+		path = upgradePath(path)
+		n := depth
+		if l := len(path.GetElem()) - 1; l < n {
+			n = l
+		}
+		if n < 0 {
+			n = 0
+		}
+		groupElems := path.GetElem()[:n]
+		key := StrPath(&pb.Path{Elem: groupElems})
+		g, ok := groups[key]
+		if !ok {
+			prefix := JoinPaths(notif.Prefix, &pb.Path{Elem: groupElems})
+			prefix.Origin, prefix.Target = notif.Prefix.GetOrigin(), notif.Prefix.GetTarget()
+			g = &pb.Notification{Timestamp: notif.Timestamp, Prefix: prefix}
+			groups[key] = g
+			notifs = append(notifs, g)
+		}
+		if path == nil {
+			return g, nil
+		}
+		return g, &pb.Path{Origin: path.Origin, Elem: path.GetElem()[n:]}
+	}
+	for _, del := range notif.Delete {
+		g, path := group(del)
+		g.Delete = append(g.Delete, path)
+	}
+	for _, upd := range notif.Update {
+		g, path := group(upd.Path)
+		g.Update = append(g.Update, &pb.Update{Path: path, Val: upd.Val, Value: upd.Value,
+			Duplicates: upd.Duplicates})
+	}
+	if len(notifs) <= 1 {
+		return []*pb.Notification{notif}
+	}
+	return notifs
+}
+
+// UnbundleSubscribeResponse splits the Notification of resp with
+// UnbundleNotification, and returns one SubscribeResponse per Notification.
+// Other responses are returned as is.
+func UnbundleSubscribeResponse(resp *pb.SubscribeResponse,
+	depth int) []*pb.SubscribeResponse {
+	notif := resp.GetUpdate()
+	if notif == nil {
+		return []*pb.SubscribeResponse{resp}
+	}
+	notifs := UnbundleNotification(notif, depth)
+	if len(notifs) == 1 {
+		return []*pb.SubscribeResponse{resp}
+	}
+	resps := make([]*pb.SubscribeResponse, len(notifs))
+	for i, n := range notifs {
+		resps[i] = &pb.SubscribeResponse{
+			Response:  &pb.SubscribeResponse_Update{Update: n},
+			Extension: resp.Extension,
+		}
+	}
+	return resps
+}
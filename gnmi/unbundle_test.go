@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUnbundleNotification(t *testing.T) {
+	path := func(s string) *pb.Path {
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Element = nil
+		return p
+	}
+	notif := func(prefix string, deletes []string, updates ...string) *pb.Notification {
+		n := &pb.Notification{Timestamp: 42, Prefix: path(prefix)}
+		n.Prefix.Target = "dev1"
+		for _, d := range deletes {
+			n.Delete = append(n.Delete, path(d))
+		}
+		for _, u := range updates {
+			n.Update = append(n.Update, &pb.Update{Path: path(u), Val: TypedValue(u)})
+		}
+		return n
+	}
+	bundled := notif("/interfaces",
+		[]string{"interface[name=Ethernet3]/state/counters"},
+		"interface[name=Ethernet1]/state/counters/in-octets",
+		"interface[name=Ethernet2]/state/counters/in-octets",
+		"interface[name=Ethernet1]/state/counters/out-octets",
+		"interface[name=Ethernet1]/state/oper-status",
+		"enabled")
+	for name, tc := range map[string]struct {
+		in       *pb.Notification
+		depth    int
+		expected []*pb.Notification
+	}{
+		"disabled": {
+			in:       bundled,
+			expected: []*pb.Notification{bundled},
+		},
+		"depth 1": {
+			in:    bundled,
+			depth: 1,
+			expected: []*pb.Notification{
+				notif("/interfaces/interface[name=Ethernet3]", []string{"state/counters"}),
+				func() *pb.Notification {
+					n := notif("/interfaces/interface[name=Ethernet1]", nil,
+						"state/counters/in-octets", "state/counters/out-octets",
+						"state/oper-status")
+					for i, u := range []string{
+						"interface[name=Ethernet1]/state/counters/in-octets",
+						"interface[name=Ethernet1]/state/counters/out-octets",
+						"interface[name=Ethernet1]/state/oper-status",
+					} {
+						n.Update[i].Val = TypedValue(u)
+					}
+					return n
+				}(),
+				func() *pb.Notification {
+					n := notif("/interfaces/interface[name=Ethernet2]", nil,
+						"state/counters/in-octets")
+					n.Update[0].Val = TypedValue(
+						"interface[name=Ethernet2]/state/counters/in-octets")
+					return n
+				}(),
+				notif("/interfaces", nil, "enabled"),
+			},
+		},
+		"single group": {
+			in:    notif("/system", nil, "config/hostname", "config/domain-name"),
+			depth: 1,
+			expected: []*pb.Notification{
+				notif("/system", nil, "config/hostname", "config/domain-name")},
+		},
+		"atomic": {
+			in: func() *pb.Notification {
+				n := proto.Clone(bundled).(*pb.Notification)
+				n.Atomic = true
+				return n
+			}(),
+			depth: 1,
+			expected: []*pb.Notification{func() *pb.Notification {
+				n := proto.Clone(bundled).(*pb.Notification)
+				n.Atomic = true
+				return n
+			}()},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := UnbundleNotification(tc.in, tc.depth)
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected %d notifications, got %d: %v", len(tc.expected),
+					len(actual), actual)
+			}
+			for i := range actual {
+				if !proto.Equal(tc.expected[i], actual[i]) {
+					t.Errorf("notification %d: expected %s, got %s", i, tc.expected[i],
+						actual[i])
+				}
+			}
+		})
+	}
+
+	resps := UnbundleSubscribeResponse(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_Update{Update: bundled}}, 1)
+	if len(resps) != 4 {
+		t.Errorf("expected 4 responses, got %v", resps)
+	}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+	if resps := UnbundleSubscribeResponse(sync, 1); len(resps) != 1 || resps[0] != sync {
+		t.Errorf("expected the sync_response as is, got %v", resps)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// UnmarshalUpdate stores the value of update, as returned by ExtractValue,
+// in the value pointed to by v. JSON and JSON_IETF values are decoded into
+// typed Go structs, letting collectors consume OpenConfig containers without
+// digging into maps.
+//
+// The fields of the structs are matched by their gnmi tag as described in
+// MarshalUpdates, or by the path tag of the structs generated by ygot,
+// without depending on it. The module prefixes of the JSON_IETF names are
+// ignored and the 64-bit numbers and decimals encoded as strings are parsed.
+// The lists are decoded into slices, or maps keyed by the key of their
+// gnmi tag, the key struct of ygot, or the single key returned by the
+// ΛListKeyMap method of ygot. The enumerations generated by ygot are decoded
+// from their names with their ΛMap method. Unknown JSON members are ignored.
+func UnmarshalUpdate(update *pb.Update, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("UnmarshalUpdate needs a non-nil pointer, got %T", v)
+	}
+	val, err := ExtractValue(update)
+	if err != nil {
+		return err
+	}
+	u := unmarshaler{}
+	return u.unmarshal(StrPath(update.GetPath()), val, rv.Elem(), fieldOptions{})
+}
+
+type unmarshaler struct{}
+
+func (u unmarshaler) unmarshal(path string, src interface{}, dst reflect.Value,
+	opts fieldOptions) error {
+	if src == nil {
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		if dst.Type() != typedValueType && dst.Type() != decimalType {
+			return u.unmarshal(path, src, dst.Elem(), opts)
+		}
+	}
+	if sv := reflect.ValueOf(src); sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	if dec, ok := src.(*pb.Decimal64); ok {
+		src = DecimalToFloat(dec)
+	}
+	if err := u.unmarshalKind(path, src, dst, opts); err != nil {
+		if _, ok := err.(*unmarshalError); ok {
+			return err
+		}
+		return &unmarshalError{path: path, err: err}
+	}
+	return nil
+}
+
+// unmarshalError is the error of the innermost value that failed to be
+// unmarshaled.
+type unmarshalError struct {
+	path string
+	err  error
+}
+
+func (e *unmarshalError) Error() string {
+	return fmt.Sprintf("failed to unmarshal %s: %s", e.path, e.err)
+}
+
+func (u unmarshaler) unmarshalKind(path string, src interface{}, dst reflect.Value,
+	opts fieldOptions) error {
+	switch dst.Kind() {
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return fmt.Errorf("unsupported interface type %s", dst.Type())
+		}
+		dst.Set(reflect.ValueOf(src))
+		return nil
+	case reflect.Struct:
+		obj, ok := src.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("can't decode %T into %s", src, dst.Type())
+		}
+		return u.unmarshalStruct(path, obj, dst)
+	case reflect.Map:
+		return u.unmarshalMap(path, src, dst, opts)
+	case reflect.Slice:
+		return u.unmarshalSlice(path, src, dst)
+	case reflect.Bool:
+		switch src := src.(type) {
+		case bool:
+			dst.SetBool(src)
+			return nil
+		case []interface{}:
+			// The JSON_IETF encoding of the empty type is [null].
+			if len(src) == 1 && src[0] == nil {
+				dst.SetBool(true)
+				return nil
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s, ok := src.(string); ok && dst.Kind() == reflect.Int64 {
+			if ok, err := unmarshalEnum(s, dst); ok || err != nil {
+				return err
+			}
+		}
+		s, ok := numberString(src)
+		if !ok {
+			break
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || dst.OverflowInt(i) {
+			return fmt.Errorf("invalid %s %q", dst.Type(), s)
+		}
+		dst.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, ok := numberString(src)
+		if !ok {
+			break
+		}
+		i, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || dst.OverflowUint(i) {
+			return fmt.Errorf("invalid %s %q", dst.Type(), s)
+		}
+		dst.SetUint(i)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if f, ok := src.(float64); ok {
+			dst.SetFloat(f)
+			return nil
+		}
+		s, ok := numberString(src)
+		if !ok {
+			break
+		}
+		f, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", dst.Type(), s)
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.String:
+		if s, ok := src.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	}
+	return fmt.Errorf("can't decode %T into %s", src, dst.Type())
+}
+
+// numberString returns the decimal representation of the number src, which
+// JSON_IETF encodes as a string if it is a 64-bit integer or a decimal.
+func numberString(src interface{}) (string, bool) {
+	switch src := src.(type) {
+	case json.Number:
+		return src.String(), true
+	case string:
+		return src, true
+	case int64, uint64, float32, float64:
+		return fmt.Sprint(src), true
+	}
+	return "", false
+}
+
+// unmarshalEnum sets dst to the value of the ygot enumeration named s, and
+// returns whether dst is such an enumeration.
+func unmarshalEnum(s string, dst reflect.Value) (bool, error) {
+	m := dst.MethodByName("ΛMap")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return false, nil
+	}
+	enums := m.Call(nil)[0]
+	if enums.Kind() != reflect.Map || enums.Type().Key().Kind() != reflect.String {
+		return false, nil
+	}
+	defs := enums.MapIndex(reflect.ValueOf(dst.Type().Name()))
+	if !defs.IsValid() || defs.Kind() != reflect.Map {
+		return false, nil
+	}
+	name := stripModule(s)
+	iter := defs.MapRange()
+	for iter.Next() {
+		def := reflect.Indirect(iter.Value())
+		if def.Kind() != reflect.Struct {
+			return false, nil
+		}
+		if n := def.FieldByName("Name"); n.Kind() == reflect.String && n.String() == name {
+			dst.SetInt(iter.Key().Int())
+			return true, nil
+		}
+	}
+	return true, fmt.Errorf("unknown %s %q", dst.Type(), s)
+}
+
+// stripModule removes the module prefix of a JSON_IETF name or identity.
+func stripModule(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// structField returns the path elements of the JSON members of the field f
+// and its options, or nil if the field is not unmarshaled.
+func structField(f reflect.StructField) ([]string, fieldOptions, error) {
+	if tag, ok := f.Tag.Lookup("gnmi"); ok {
+		if tag == "-" {
+			return nil, fieldOptions{}, nil
+		}
+		name, opts, err := parseFieldTag(f)
+		return []string{name}, opts, err
+	}
+	if tag, ok := f.Tag.Lookup("path"); ok {
+		// The path tags of the compressed ygot structs list the paths of the
+		// leaves of both the config and state containers, the first one is
+		// the one with all the values.
+		tag = strings.Trim(strings.Split(tag, "|")[0], "/")
+		return strings.Split(tag, "/"), fieldOptions{}, nil
+	}
+	return []string{f.Name}, fieldOptions{}, nil
+}
+
+// member returns the member of obj named name, with or without module prefix.
+func member(obj map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := obj[name]; ok {
+		return v, true
+	}
+	for k, v := range obj {
+		if stripModule(k) == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (u unmarshaler) unmarshalStruct(path string, obj map[string]interface{},
+	dst reflect.Value) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && !hasTag(f) && f.Tag.Get("path") == "" {
+			if err := u.unmarshal(path, obj, dst.Field(i), fieldOptions{}); err != nil {
+				return err
+			}
+			continue
+		}
+		elems, opts, err := structField(f)
+		if err != nil {
+			return err
+		}
+		if elems == nil {
+			continue
+		}
+		var val interface{} = obj
+		for _, elem := range elems {
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				val = nil
+				break
+			}
+			val, _ = member(m, elem)
+		}
+		if err := u.unmarshal(path+"/"+strings.Join(elems, "/"), val, dst.Field(i),
+			opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u unmarshaler) unmarshalMap(path string, src interface{}, dst reflect.Value,
+	opts fieldOptions) error {
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(dst.Type()))
+	}
+	keyType, elemType := dst.Type().Key(), dst.Type().Elem()
+	if obj, ok := src.(map[string]interface{}); ok && opts.key == "" {
+		// The entries of a map without key option are containers named by
+		// the map keys.
+		for name, val := range obj {
+			k := reflect.New(keyType).Elem()
+			if err := u.unmarshal(path, name, k, fieldOptions{}); err != nil {
+				return err
+			}
+			e := reflect.New(elemType).Elem()
+			if err := u.unmarshal(path+"/"+name, val, e, fieldOptions{}); err != nil {
+				return err
+			}
+			dst.SetMapIndex(k, e)
+		}
+		return nil
+	}
+	list, ok := src.([]interface{})
+	if !ok {
+		return fmt.Errorf("can't decode %T into %s", src, dst.Type())
+	}
+	for i, entry := range list {
+		entryPath := fmt.Sprintf("%s[%d]", path, i)
+		e := reflect.New(elemType).Elem()
+		if err := u.unmarshal(entryPath, entry, e, fieldOptions{}); err != nil {
+			return err
+		}
+		k := reflect.New(keyType).Elem()
+		if err := u.listKey(entryPath, entry, e, k, opts); err != nil {
+			return err
+		}
+		dst.SetMapIndex(k, e)
+	}
+	return nil
+}
+
+// listKey sets k to the key of the list entry e decoded from entry.
+func (u unmarshaler) listKey(path string, entry interface{}, e, k reflect.Value,
+	opts fieldOptions) error {
+	obj, ok := entry.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("list entry of type %T", entry)
+	}
+	if opts.key != "" {
+		val, _ := member(obj, opts.key)
+		return u.unmarshal(path+"/"+opts.key, val, k, fieldOptions{})
+	}
+	if k.Kind() == reflect.Struct {
+		// The fields of the key structs of ygot are tagged with the names of
+		// the keys, which are members of the entry.
+		return u.unmarshal(path, obj, k, fieldOptions{})
+	}
+	m := e.MethodByName("ΛListKeyMap")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 2 {
+		return fmt.Errorf("list of %s without key option", e.Type())
+	}
+	out := m.Call(nil)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return err
+	}
+	keys, ok := out[0].Interface().(map[string]interface{})
+	if !ok || len(keys) != 1 {
+		return fmt.Errorf("%d keys for map key %s", len(keys), k.Type())
+	}
+	for _, key := range keys {
+		kv := reflect.ValueOf(key)
+		if !kv.IsValid() || !kv.Type().ConvertibleTo(k.Type()) {
+			return fmt.Errorf("key %v is not a %s", key, k.Type())
+		}
+		k.Set(kv.Convert(k.Type()))
+	}
+	return nil
+}
+
+func (u unmarshaler) unmarshalSlice(path string, src interface{}, dst reflect.Value) error {
+	if s, ok := src.(string); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+		// JSON_IETF encodes the binary values in base64.
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		dst.SetBytes(b)
+		return nil
+	}
+	list, ok := src.([]interface{})
+	if !ok {
+		return fmt.Errorf("can't decode %T into %s", src, dst.Type())
+	}
+	s := reflect.MakeSlice(dst.Type(), len(list), len(list))
+	for i, val := range list {
+		if err := u.unmarshal(fmt.Sprintf("%s[%d]", path, i), val, s.Index(i),
+			fieldOptions{}); err != nil {
+			return err
+		}
+	}
+	dst.Set(s)
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// The following types mimic the code generated by ygot.
+
+type enumDefinition struct {
+	Name           string
+	DefiningModule string
+}
+
+type E_Interface_OperStatus int64
+
+const (
+	Interface_OperStatus_UNSET E_Interface_OperStatus = 0
+	Interface_OperStatus_UP    E_Interface_OperStatus = 1
+	Interface_OperStatus_DOWN  E_Interface_OperStatus = 2
+)
+
+func (E_Interface_OperStatus) ΛMap() map[string]map[int64]enumDefinition {
+	return map[string]map[int64]enumDefinition{
+		"E_Interface_OperStatus": {
+			1: {Name: "UP"},
+			2: {Name: "DOWN"},
+		},
+	}
+}
+
+type Interface struct {
+	Name       *string                    `path:"state/name|name" module:"openconfig-interfaces"`
+	Mtu        *uint16                    `path:"state/mtu" module:"openconfig-interfaces"`
+	OperStatus E_Interface_OperStatus     `path:"state/oper-status"`
+	InOctets   *uint64                    `path:"state/counters/in-octets"`
+	Enabled    *bool                      `path:"state/enabled"`
+	Subif      map[uint32]*Subif          `path:"subinterfaces/subinterface"`
+	Neighbor   map[Neighbor_Key]*Neighbor `path:"neighbors/neighbor"`
+}
+
+func (t *Interface) ΛListKeyMap() (map[string]interface{}, error) {
+	return map[string]interface{}{"name": *t.Name}, nil
+}
+
+type Subif struct {
+	Index *uint32 `path:"state/index|index"`
+}
+
+func (t *Subif) ΛListKeyMap() (map[string]interface{}, error) {
+	return map[string]interface{}{"index": *t.Index}, nil
+}
+
+type Neighbor_Key struct {
+	Ip  string `path:"ip"`
+	Vrf string `path:"vrf"`
+}
+
+type Neighbor struct {
+	Ip  *string `path:"ip"`
+	Vrf *string `path:"vrf"`
+	Mac *string `path:"state/mac"`
+}
+
+type Device struct {
+	Interface map[string]*Interface `path:"interfaces/interface" module:"openconfig-interfaces"`
+}
+
+func TestUnmarshalUpdateYgot(t *testing.T) {
+	js := `{"openconfig-interfaces:interfaces": {"interface": [{
+		"name": "Ethernet1",
+		"state": {
+			"name": "Ethernet1",
+			"mtu": 9214,
+			"oper-status": "openconfig-interfaces:UP",
+			"enabled": true,
+			"counters": {"in-octets": "18446744073709551615"}
+		},
+		"subinterfaces": {"subinterface": [{"index": 0, "state": {"index": 0}}]},
+		"neighbors": {"neighbor": [
+			{"ip": "10.0.0.1", "vrf": "default", "state": {"mac": "00:1c:73:00:00:01"}}
+		]},
+		"unknown": {"ignored": true}
+	}]}}`
+	var d Device
+	if err := UnmarshalUpdate(&pb.Update{Val: &pb.TypedValue{
+		Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(js)}}}, &d); err != nil {
+		t.Fatal(err)
+	}
+	intf, ok := d.Interface["Ethernet1"]
+	if !ok {
+		t.Fatalf("missing interface Ethernet1: %v", d.Interface)
+	}
+	if *intf.Name != "Ethernet1" || *intf.Mtu != 9214 || !*intf.Enabled ||
+		*intf.InOctets != 18446744073709551615 || intf.OperStatus != Interface_OperStatus_UP {
+		t.Errorf("unexpected interface %+v", intf)
+	}
+	if s, ok := intf.Subif[0]; !ok || *s.Index != 0 {
+		t.Errorf("unexpected subinterfaces %v", intf.Subif)
+	}
+	n, ok := intf.Neighbor[Neighbor_Key{Ip: "10.0.0.1", Vrf: "default"}]
+	if !ok || *n.Mac != "00:1c:73:00:00:01" {
+		t.Errorf("unexpected neighbors %v", intf.Neighbor)
+	}
+}
+
+func TestUnmarshalUpdate(t *testing.T) {
+	type Member struct {
+		Name string `gnmi:"name"`
+		MTU  int32  `gnmi:"mtu"`
+	}
+	type Config struct {
+		Description string             `gnmi:"description"`
+		Members     map[string]*Member `gnmi:"member,key=name"`
+		List        []Member           `gnmi:"list,key=name"`
+		Tags        []string           `gnmi:"tags"`
+		Counters    map[string]uint64  `gnmi:"counters"`
+		Raw         []byte             `gnmi:"raw"`
+		Rate        float64            `gnmi:"rate"`
+		Any         interface{}        `gnmi:"any"`
+		Skipped     string             `gnmi:"-"`
+	}
+	js := `{
+		"description": "uplink",
+		"member": [{"name": "Et1", "mtu": 1500}, {"name": "Et2", "mtu": "9000"}],
+		"list": [{"name": "Et3"}],
+		"tags": ["a", "b"],
+		"counters": {"in": "12", "out": 34},
+		"raw": "aGVsbG8=",
+		"rate": "2.5",
+		"any": {"x": [1]},
+		"-": "no"
+	}`
+	var c Config
+	if err := UnmarshalUpdate(&pb.Update{Val: &pb.TypedValue{
+		Value: &pb.TypedValue_JsonVal{JsonVal: []byte(js)}}}, &c); err != nil {
+		t.Fatal(err)
+	}
+	expected := Config{
+		Description: "uplink",
+		Members: map[string]*Member{
+			"Et1": {Name: "Et1", MTU: 1500},
+			"Et2": {Name: "Et2", MTU: 9000},
+		},
+		List:     []Member{{Name: "Et3"}},
+		Tags:     []string{"a", "b"},
+		Counters: map[string]uint64{"in": 12, "out": 34},
+		Raw:      []byte("hello"),
+		Rate:     2.5,
+	}
+	anyVal := c.Any
+	c.Any = nil
+	if !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected %+v, got %+v", expected, c)
+	}
+	if m, ok := anyVal.(map[string]interface{}); !ok || len(m["x"].([]interface{})) != 1 {
+		t.Errorf("unexpected any value %v", anyVal)
+	}
+
+	// Scalar values
+	var i int16
+	if err := UnmarshalUpdate(&pb.Update{Val: TypedValue(int64(-3))}, &i); err != nil || i != -3 {
+		t.Errorf("expected -3, got %d (%v)", i, err)
+	}
+	var f float64
+	if err := UnmarshalUpdate(&pb.Update{Val: &pb.TypedValue{Value: &pb.TypedValue_DecimalVal{
+		DecimalVal: &pb.Decimal64{Digits: 125, Precision: 2}}}}, &f); err != nil || f != 1.25 {
+		t.Errorf("expected 1.25, got %v (%v)", f, err)
+	}
+
+	for name, tc := range map[string]struct {
+		update *pb.Update
+		dst    interface{}
+		err    string
+	}{
+		"overflow": {
+			update: &pb.Update{Val: TypedValue(int64(300))},
+			dst:    new(int8),
+			err:    `failed to unmarshal /: invalid int8 "300"`,
+		},
+		"type": {
+			update: &pb.Update{
+				Path: &pb.Path{Elem: []*pb.PathElem{{Name: "a"}}},
+				Val: &pb.TypedValue{Value: &pb.TypedValue_JsonVal{
+					JsonVal: []byte(`{"member": [{"name": "Et1", "mtu": true}]}`)}}},
+			dst: new(Config),
+			err: "failed to unmarshal /a/member[0]/mtu: can't decode bool into int32",
+		},
+		"enum": {
+			update: &pb.Update{Val: TypedValue("SIDEWAYS")},
+			dst:    new(E_Interface_OperStatus),
+			err:    `unknown gnmi.E_Interface_OperStatus "SIDEWAYS"`,
+		},
+		"not a pointer": {
+			update: &pb.Update{Val: TypedValue("a")},
+			dst:    "a",
+			err:    "needs a non-nil pointer",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := UnmarshalUpdate(tc.update, tc.dst)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetValidator validates the values of Set operations before they are
+// sent, e.g. against the YANG schema of the target, so that payload errors
+// are reported locally instead of by the target.
+type SetValidator interface {
+	// Validate returns an error if val, in the JSON encoding of origin,
+	// is not a valid value for path.
+	Validate(origin string, path []string, val []byte) error
+}
+
+// ValidateSet validates the values of the update, replace and
+// union_replace operations in setOps with v. The returned error lists the
+// invalid operations with their path.
+func ValidateSet(v SetValidator, setOps []*Operation) error {
+	var errs []string
+	for _, op := range setOps {
+		switch op.Type {
+		case "update", "replace", "union_replace":
+		default:
+			continue
+		}
+		err := v.Validate(op.Origin, op.Path, extractContent(op.Val, op.Origin))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %s", op.Type, "/"+strings.Join(op.Path, "/"),
+				err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New("invalid Set operations:\n  " + strings.Join(errs, "\n  "))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// leafValidator only accepts integers at /a/b.
+type leafValidator struct{}
+
+func (leafValidator) Validate(origin string, path []string, val []byte) error {
+	if strings.Join(path, "/") != "a/b" {
+		return errors.New("unknown path")
+	}
+	for _, c := range val {
+		if c < '0' || c > '9' {
+			return errors.New("not an integer")
+		}
+	}
+	return nil
+}
+
+func TestValidateSet(t *testing.T) {
+	ops := []*Operation{
+		{Type: "update", Path: []string{"a", "b"}, Val: "42"},
+		{Type: "delete", Path: []string{"c"}},
+		{Type: "replace", Path: []string{"a", "b"}, Val: "forty-two"},
+		{Type: "union_replace", Path: []string{"d"}, Val: "1"},
+	}
+	if err := ValidateSet(leafValidator{}, ops[:2]); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := ValidateSet(leafValidator{}, ops)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	exp := "invalid Set operations:\n" +
+		"  replace /a/b: not an integer\n" +
+		"  union_replace /d: unknown path"
+	if err.Error() != exp {
+		t.Errorf("expected error %q, got %q", exp, err)
+	}
+}
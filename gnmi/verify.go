@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+// SubscriptionVerifier checks that each path of a SubscribeRequest produced
+// at least one update before the sync_response, to detect subscriptions
+// silently returning nothing, e.g. because of a typo in a path or because
+// the target does not support them.
+type SubscriptionVerifier struct {
+	paths  []*pb.Path
+	seen   []bool
+	synced bool
+}
+
+// NewSubscriptionVerifier returns a SubscriptionVerifier of the
+// subscriptions of req.
+func NewSubscriptionVerifier(req *pb.SubscribeRequest) *SubscriptionVerifier {
+	list := req.GetSubscribe()
+	v := &SubscriptionVerifier{}
+	for _, sub := range list.GetSubscription() {
+		v.paths = append(v.paths, fullPath(list.GetPrefix(), sub.GetPath()))
+	}
+	v.seen = make([]bool, len(v.paths))
+	return v
+}
+
+// Update records the updates of resp. It returns true when resp is the
+// sync_response, after which Missing returns the final result.
+func (v *SubscriptionVerifier) Update(resp *pb.SubscribeResponse) bool {
+	if v.synced {
+		return false
+	}
+	if resp.GetSyncResponse() {
+		v.synced = true
+		return true
+	}
+	notif := resp.GetUpdate()
+	if notif == nil {
+		return false
+	}
+	for _, u := range notif.Update {
+		p := fullPath(notif.Prefix, u.Path)
+		for i, sub := range v.paths {
+			if !v.seen[i] && PathsOverlap(sub, p) {
+				v.seen[i] = true
+			}
+		}
+	}
+	return false
+}
+
+// Synced returns true once the sync_response was received.
+func (v *SubscriptionVerifier) Synced() bool {
+	return v.synced
+}
+
+// Missing returns the subscribed paths, including the prefix of the
+// SubscribeRequest, that did not produce any update so far.
+func (v *SubscriptionVerifier) Missing() []*pb.Path {
+	var missing []*pb.Path
+	for i, p := range v.paths {
+		if !v.seen[i] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// PathsOverlap returns true if the update path p is under the subscribed
+// path sub, or is an ancestor of it carrying the subtree as a JSON value.
+// sub may contain "*" and "..." wildcards. Updates without origin match
+// the subscriptions of any origin, as not all targets set it.
+func PathsOverlap(sub, p *pb.Path) bool {
+	if p.Origin != "" && NormalizeOrigin(sub.Origin) != NormalizeOrigin(p.Origin) {
+		return false
+	}
+	for i, elem := range sub.Elem {
+		if elem.Name == "..." {
+			return true
+		}
+		if i >= len(p.Elem) {
+			return true
+		}
+		if !elemMatches(elem, p.Elem[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fullPath joins prefix and p, keeping the origin of either.
+func fullPath(prefix, p *pb.Path) *pb.Path {
+	full := JoinPaths(proto.Clone(prefix).(*pb.Path), proto.Clone(p).(*pb.Path))
+	full.Origin = prefix.GetOrigin()
+	if full.Origin == "" {
+		full.Origin = p.GetOrigin()
+	}
+	return full
+}
+
+// NormalizeOrigin returns origin, or "" for the openconfig origin, which
+// is the same as the empty origin.
+func NormalizeOrigin(origin string) string {
+	if origin == "openconfig" {
+		return ""
+	}
+	return origin
+}
+
+func elemMatches(sub, elem *pb.PathElem) bool {
+	if sub.Name != "*" && sub.Name != elem.Name {
+		return false
+	}
+	if len(elem.Key) == 0 {
+		// The whole list.
+		return true
+	}
+	for k, v := range sub.Key {
+		if v != "*" && elem.Key[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmi
+
+import (
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestSubscriptionVerifier(t *testing.T) {
+	mustParse := func(s string) *pb.Path {
+		t.Helper()
+		p, err := ParseGNMIElements(SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	subscribe := func(paths ...string) *pb.SubscribeRequest {
+		list := &pb.SubscriptionList{}
+		for _, p := range paths {
+			list.Subscription = append(list.Subscription, &pb.Subscription{Path: mustParse(p)})
+		}
+		return &pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{Subscribe: list}}
+	}
+	update := func(prefix string, paths ...string) *pb.SubscribeResponse {
+		notif := &pb.Notification{Prefix: mustParse(prefix)}
+		for _, p := range paths {
+			notif.Update = append(notif.Update, &pb.Update{Path: mustParse(p)})
+		}
+		return &pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: notif}}
+	}
+	sync := &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{
+		SyncResponse: true}}
+
+	for name, tc := range map[string]struct {
+		req     *pb.SubscribeRequest
+		resps   []*pb.SubscribeResponse
+		missing []string
+	}{
+		"all received": {
+			req: subscribe("/interfaces/interface[name=Ethernet1]/state",
+				"/lacp/interfaces/interface[name=*]/members"),
+			resps: []*pb.SubscribeResponse{
+				update("/interfaces/interface[name=Ethernet1]", "state/counters/in-octets"),
+				update("/lacp/interfaces", "interface[name=Port-Channel1]/members/member"),
+			},
+		},
+		"missing": {
+			req: subscribe("/interfaces/interface[name=Ethernet1]/state",
+				"/lacp/interfaces/interface[name=*]/members",
+				"/system/..."),
+			resps: []*pb.SubscribeResponse{
+				update("/interfaces/interface[name=Ethernet2]", "state/counters/in-octets"),
+				update("/", "system/state/hostname"),
+				update("/lacp/interfaces", "interface[name=Port-Channel1]/state"),
+			},
+			missing: []string{"/interfaces/interface[name=Ethernet1]/state",
+				"/lacp/interfaces/interface[name=*]/members"},
+		},
+		"ancestor update": {
+			req:   subscribe("/system/config/hostname"),
+			resps: []*pb.SubscribeResponse{update("/", "system")},
+		},
+		"after sync": {
+			req:     subscribe("/system"),
+			resps:   []*pb.SubscribeResponse{sync, update("/", "system/state")},
+			missing: []string{"/system"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			v := NewSubscriptionVerifier(tc.req)
+			var synced bool
+			for _, resp := range append(tc.resps, sync) {
+				if v.Update(resp) {
+					synced = true
+				}
+			}
+			if !synced || !v.Synced() {
+				t.Error("expected the verifier to be synced")
+			}
+			missing := v.Missing()
+			if len(missing) != len(tc.missing) {
+				t.Fatalf("expected missing paths %v, got %v", tc.missing, missing)
+			}
+			for i, p := range missing {
+				if StrPath(p) != tc.missing[i] {
+					t.Errorf("expected missing paths %v, got %v", tc.missing, missing)
+				}
+			}
+		})
+	}
+}
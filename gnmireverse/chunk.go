@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmireverse
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// ChunkExtensionID is the ID of the registered extension marking a
+// GetResponse as one chunk of a larger GetResponse. The message of the
+// extension is ChunkExtensionMagic followed by the index of the chunk
+// (field 1) and the total number of chunks (field 2) as varints.
+const ChunkExtensionID = gnmi_ext.ExtensionID_EID_EXPERIMENTAL
+
+func chunkExtension(index, total int) *gnmi_ext.Extension {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(index))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(total))
+	return registeredExtension(ChunkExtensionID, ChunkExtensionMagic, b)
+}
+
+// chunkInfo returns the index and total number of chunks of res, and whether
+// res is a chunk.
+func chunkInfo(res *gnmi.GetResponse) (int, int, bool, error) {
+	for _, ext := range res.GetExtension() {
+		b, ok := registeredExtensionMsg(ext, ChunkExtensionID, ChunkExtensionMagic)
+		if !ok {
+			continue
+		}
+		var index, total uint64
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			if n < 0 || typ != protowire.VarintType {
+				return 0, 0, false, errors.New("malformed chunk extension")
+			}
+			b = b[n:]
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, 0, false, errors.New("malformed chunk extension")
+			}
+			b = b[n:]
+			switch num {
+			case 1:
+				index = v
+			case 2:
+				total = v
+			}
+		}
+		if total == 0 || index >= total {
+			return 0, 0, false, fmt.Errorf("invalid chunk %d of %d", index, total)
+		}
+		return int(index), int(total), true, nil
+	}
+	return 0, 0, false, nil
+}
+
+// ChunkGetResponse splits res into GetResponses whose notifications hold at
+// most maxSize bytes, so that large GetResponses can be sent without hitting
+// message size limits. Notifications larger than maxSize are split by
+// updates, each part keeping the prefix and timestamp of the notification.
+// A single update larger than maxSize is sent in a chunk of its own. If res
+// fits in maxSize, or maxSize is not positive, res is returned as is. The
+// chunks are reassembled with a GetResponseAssembler.
+func ChunkGetResponse(res *gnmi.GetResponse, maxSize int) []*gnmi.GetResponse {
+	if maxSize <= 0 || proto.Size(res) <= maxSize {
+		return []*gnmi.GetResponse{res}
+	}
+	var chunks []*gnmi.GetResponse
+	cur := &gnmi.GetResponse{}
+	var curSize int
+	add := func(notif *gnmi.Notification) {
+		size := proto.Size(notif)
+		if curSize+size > maxSize && len(cur.Notification) > 0 {
+			chunks = append(chunks, cur)
+			cur = &gnmi.GetResponse{}
+			curSize = 0
+		}
+		cur.Notification = append(cur.Notification, notif)
+		curSize += size
+	}
+	for _, notif := range res.GetNotification() {
+		if proto.Size(notif) <= maxSize || len(notif.GetUpdate())+len(notif.GetDelete()) <= 1 {
+			add(notif)
+			continue
+		}
+		part := newNotificationPart(notif)
+		for _, u := range notif.GetUpdate() {
+			if proto.Size(part)+proto.Size(u) > maxSize && len(part.Update) > 0 {
+				add(part)
+				part = newNotificationPart(notif)
+			}
+			part.Update = append(part.Update, u)
+		}
+		if len(notif.GetDelete()) > 0 {
+			part.Delete = notif.GetDelete()
+		}
+		add(part)
+	}
+	if len(cur.Notification) > 0 {
+		chunks = append(chunks, cur)
+	}
+	for i, chunk := range chunks {
+		chunk.Extension = append(append([]*gnmi_ext.Extension(nil), res.GetExtension()...),
+			chunkExtension(i, len(chunks)))
+	}
+	return chunks
+}
+
+func newNotificationPart(notif *gnmi.Notification) *gnmi.Notification {
+	return &gnmi.Notification{
+		Timestamp: notif.GetTimestamp(),
+		Prefix:    notif.GetPrefix(),
+		Atomic:    notif.GetAtomic(),
+	}
+}
+
+// GetResponseAssembler reassembles the GetResponses split by
+// ChunkGetResponse. It is not safe for concurrent use.
+type GetResponseAssembler struct {
+	chunks []*gnmi.GetResponse
+}
+
+// Add adds res to the assembler. It returns the complete GetResponse and true
+// once all its chunks were added, or nil and false if more chunks are
+// expected. GetResponses which are not chunks are returned as is. Chunks
+// must be added in order. Notifications split across chunks are returned as
+// several notifications with the same prefix and timestamp.
+func (a *GetResponseAssembler) Add(res *gnmi.GetResponse) (*gnmi.GetResponse, bool, error) {
+	index, total, ok, err := chunkInfo(res)
+	if err != nil {
+		a.chunks = nil
+		return nil, false, err
+	}
+	if !ok {
+		if len(a.chunks) > 0 {
+			a.chunks = nil
+			return nil, false, errors.New("incomplete chunked GetResponse")
+		}
+		return res, true, nil
+	}
+	if index != len(a.chunks) {
+		expected := len(a.chunks)
+		a.chunks = nil
+		return nil, false, fmt.Errorf("unexpected chunk %d of %d, expected chunk %d",
+			index, total, expected)
+	}
+	a.chunks = append(a.chunks, res)
+	if len(a.chunks) < total {
+		return nil, false, nil
+	}
+	full := &gnmi.GetResponse{}
+	for _, chunk := range a.chunks {
+		full.Notification = append(full.Notification, chunk.GetNotification()...)
+	}
+	for _, ext := range res.GetExtension() {
+		if _, ok := registeredExtensionMsg(ext, ChunkExtensionID, ChunkExtensionMagic); !ok {
+			full.Extension = append(full.Extension, ext)
+		}
+	}
+	a.chunks = nil
+	return full, true, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmireverse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/protobuf/proto"
+)
+
+func newUpdate(i int) *gnmi.Update {
+	return &gnmi.Update{
+		Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: fmt.Sprintf("leaf%d", i)}}},
+		Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "0123456789"}},
+	}
+}
+
+func TestChunkGetResponse(t *testing.T) {
+	prefix := &gnmi.Path{Target: "dut", Elem: []*gnmi.PathElem{{Name: "a"}}}
+	var bigUpdates []*gnmi.Update
+	for i := 0; i < 20; i++ {
+		bigUpdates = append(bigUpdates, newUpdate(i))
+	}
+	history := &gnmi_ext.Extension{Ext: &gnmi_ext.Extension_History{
+		History: &gnmi_ext.History{
+			Request: &gnmi_ext.History_SnapshotTime{SnapshotTime: 1},
+		},
+	}}
+	res := &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{
+			{Timestamp: 1, Prefix: prefix, Update: []*gnmi.Update{newUpdate(100)}},
+			{Timestamp: 2, Prefix: prefix, Update: bigUpdates},
+			{Timestamp: 3, Update: []*gnmi.Update{newUpdate(200)}},
+		},
+		Extension: []*gnmi_ext.Extension{history},
+	}
+
+	if chunks := ChunkGetResponse(res, 0); len(chunks) != 1 || chunks[0] != res {
+		t.Fatalf("expected no chunking with max size 0, got %d chunks", len(chunks))
+	}
+	if chunks := ChunkGetResponse(res, proto.Size(res)); len(chunks) != 1 || chunks[0] != res {
+		t.Fatalf("expected no chunking of a small response, got %d chunks", len(chunks))
+	}
+
+	const maxSize = 200
+	chunks := ChunkGetResponse(res, maxSize)
+	if len(chunks) < 3 {
+		t.Fatalf("expected at least 3 chunks, got %d", len(chunks))
+	}
+	var a GetResponseAssembler
+	var full *gnmi.GetResponse
+	for i, chunk := range chunks {
+		var size int
+		for _, notif := range chunk.GetNotification() {
+			size += proto.Size(notif)
+		}
+		if size > maxSize {
+			t.Errorf("chunk %d: notifications size %d larger than %d", i, size, maxSize)
+		}
+		got, ok, err := a.Add(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != (i == len(chunks)-1) {
+			t.Fatalf("chunk %d: unexpected completion %t", i, ok)
+		}
+		full = got
+	}
+
+	// The big notification is split in several notifications, merge them
+	// back to compare with the original response.
+	var merged []*gnmi.Notification
+	for _, notif := range full.GetNotification() {
+		if n := len(merged); n > 0 && merged[n-1].Timestamp == notif.Timestamp {
+			merged[n-1].Update = append(merged[n-1].Update, notif.Update...)
+			continue
+		}
+		merged = append(merged, proto.Clone(notif).(*gnmi.Notification))
+	}
+	full.Notification = merged
+	if !proto.Equal(full, res) {
+		t.Errorf("reassembled response differs:\nexpected: %v\ngot: %v", res, full)
+	}
+}
+
+func TestGetResponseAssemblerErrors(t *testing.T) {
+	notif := &gnmi.Notification{Update: []*gnmi.Update{newUpdate(0)}}
+	chunk := func(index, total int) *gnmi.GetResponse {
+		return &gnmi.GetResponse{
+			Notification: []*gnmi.Notification{notif},
+			Extension:    []*gnmi_ext.Extension{chunkExtension(index, total)},
+		}
+	}
+	var a GetResponseAssembler
+	if _, _, err := a.Add(chunk(1, 2)); err == nil {
+		t.Error("expected error for out of order chunk")
+	}
+	if _, ok, err := a.Add(chunk(0, 2)); ok || err != nil {
+		t.Fatalf("unexpected result: %t %v", ok, err)
+	}
+	if _, _, err := a.Add(&gnmi.GetResponse{}); err == nil {
+		t.Error("expected error for incomplete chunked response")
+	}
+	if _, _, err := a.Add(chunk(2, 2)); err == nil {
+		t.Error("expected error for invalid chunk index")
+	}
+	res := &gnmi.GetResponse{Notification: []*gnmi.Notification{notif}}
+	if got, ok, err := a.Add(res); !ok || err != nil || got != res {
+		t.Errorf("expected unchunked response to be returned as is, got %v %t %v", got, ok, err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
+	"github.com/aristanetworks/goarista/netns"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// checkResolveTimeout bounds the resolution of each address by check.
+const checkResolveTimeout = 5 * time.Second
+
+// check validates the configuration of the client without dialing the
+// target or the collector: it resolves their addresses, loads the TLS
+// material and validates the paths and the intervals. It then writes the
+// effective configuration to w, one "flag: value" per line. With a self
+// config path, the collector and the paths may be left to the target.
+func (c *config) check(w io.Writer, getMode string, selfConfigPath *gnmi.Path) error {
+	sc := c.streamConfig()
+	if selfConfigPath == nil {
+		if err := sc.validate(c.getSampleJitter); err != nil {
+			return err
+		}
+	}
+
+	targetAddrs, err := resolveAddress(c.targetAddr)
+	if err != nil {
+		return fmt.Errorf("invalid target address %q: %s", c.targetAddr, err)
+	}
+	targetTLS := "none"
+	if c.targetTLSInsecure || c.targetCert != "" || c.targetKey != "" || c.targetCA != "" {
+		if _, err := newTLSConfig(c.targetTLSInsecure,
+			c.targetCert, c.targetKey, c.targetCA); err != nil {
+			return fmt.Errorf("error creating TLS config for target: %s", err)
+		}
+		targetTLS = tlsDescription(c.targetTLSInsecure, c.targetCert, c.targetCA)
+	}
+
+	var collectorAddrs []string
+	if c.collectorAddr != "" {
+		if collectorAddrs, err = resolveAddress(c.collectorAddr); err != nil {
+			return fmt.Errorf("invalid collector address %q: %s", c.collectorAddr, err)
+		}
+	}
+	collectorTLS := "none"
+	if c.collectorTLS {
+		if _, err := newTLSConfig(c.collectorSkipVerify,
+			c.collectorCert, c.collectorKey, c.collectorCA); err != nil {
+			return fmt.Errorf("error creating TLS config for collector: %s", err)
+		}
+		collectorTLS = tlsDescription(c.collectorSkipVerify, c.collectorCert, c.collectorCA)
+	}
+	switch c.collectorCompression {
+	case "", "none", gzip.Name, gnmilib.ZstdName:
+	default:
+		return fmt.Errorf("unknown compression method %q", c.collectorCompression)
+	}
+	if _, err := newDialer(c); err != nil {
+		return err
+	}
+
+	password := ""
+	if c.password != "" {
+		password = "<set>"
+	}
+	getPaths := make([]string, 0,
+		len(sc.getPaths.openconfigPaths)+len(sc.getPaths.eosNativePaths))
+	for _, p := range sc.getPaths.openconfigPaths {
+		getPaths = append(getPaths, pathWithOrigin(p))
+	}
+	for _, p := range sc.getPaths.eosNativePaths {
+		getPaths = append(getPaths, pathWithOrigin(p))
+	}
+	lines := []struct{ name, value string }{
+		{"target_addr", addressDescription(c.targetAddr, targetAddrs)},
+		{"target_tls", targetTLS},
+		{"username", c.username},
+		{"password", password},
+		{"target_value", c.targetVal},
+		{"subscribe", subscriptionsDescription(sc.subTargetDefined.subs)},
+		{"sample", subscriptionsDescription(sc.subSample.subs)},
+		{"sample_spread", fmt.Sprint(c.sampleSpread)},
+		{"get", strings.Join(getPaths, ", ")},
+		{"get_sample_interval", sc.getSampleInterval.String()},
+		{"get_sample_jitter", c.getSampleJitter.String()},
+		{"get_mode", getMode},
+		{"self_config_path", pathWithOrigin(selfConfigPath)},
+		{"collector_addr", addressDescription(c.collectorAddr, collectorAddrs)},
+		{"collector_tls", collectorTLS},
+		{"collector_compression", c.collectorCompression},
+		{"collector_dscp", fmt.Sprint(c.dscp)},
+		{"collector_fwmark", fmt.Sprint(c.collectorMark)},
+		{"source_addr", c.sourceAddr},
+		{"collector_get_max_size", fmt.Sprint(c.collectorGetMaxSize)},
+		{"collector_metadata", fmt.Sprint(c.collectorMetadata)},
+	}
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", l.name, l.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAddress resolves the host of the [<vrf-name>/]host:port address
+// in the network namespace of its VRF, and returns its IP addresses.
+func resolveAddress(address string) ([]string, error) {
+	nsName, addr, err := netns.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	var ips []string
+	err = netns.DefaultPool.Do(nsName, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), checkResolveTimeout)
+		defer cancel()
+		ips, err = net.DefaultResolver.LookupHost(ctx, host)
+		return err
+	})
+	return ips, err
+}
+
+func addressDescription(address string, ips []string) string {
+	if len(ips) == 0 {
+		return address
+	}
+	return fmt.Sprintf("%s (%s)", address, strings.Join(ips, ", "))
+}
+
+func tlsDescription(skipVerify bool, certFile, caFile string) string {
+	s := "verify with host's root CAs"
+	if skipVerify {
+		s = "no verification (insecure)"
+	} else if caFile != "" {
+		s = "verify with CA " + caFile
+	}
+	if certFile != "" {
+		s += ", certificate " + certFile
+	}
+	return s
+}
+
+func subscriptionsDescription(subs []subscription) string {
+	s := make([]string, len(subs))
+	for i, sub := range subs {
+		s[i] = pathWithOrigin(sub.p)
+		if sub.interval > 0 {
+			s[i] += "@" + sub.interval.String()
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+func pathWithOrigin(p *gnmi.Path) string {
+	if p == nil {
+		return ""
+	}
+	if p.Origin == "" {
+		return gnmilib.StrPath(p)
+	}
+	return p.Origin + ":" + gnmilib.StrPath(p)
+}
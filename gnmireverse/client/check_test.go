@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigCheck(t *testing.T) {
+	newConfig := func() *config {
+		cfg := &config{
+			targetAddr:           "127.0.0.1:6030",
+			username:             "admin",
+			password:             "secret",
+			collectorAddr:        "mgmt/[::1]:6000",
+			collectorTLS:         true,
+			collectorCompression: "gzip",
+			dscp:                 46,
+		}
+		if err := cfg.subSample.Set("/interfaces/interface/state/counters@30s"); err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.getPaths.Set("eos_native:/Kernel/sysinfo"); err != nil {
+			t.Fatal(err)
+		}
+		cfg.getSampleInterval = time.Minute
+		return cfg
+	}
+
+	var out strings.Builder
+	if err := newConfig().check(&out, "get", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"target_addr: 127.0.0.1:6030 (127.0.0.1)\n",
+		"target_tls: none\n",
+		"password: <set>\n",
+		"sample: /interfaces/interface/state/counters@30s\n",
+		"get: eos_native:/Kernel/sysinfo\n",
+		"get_sample_interval: 1m0s\n",
+		"collector_addr: mgmt/[::1]:6000 (::1)\n",
+		"collector_tls: verify with host's root CAs\n",
+		"collector_dscp: 46\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q in the configuration:\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Errorf("unexpected password in the configuration:\n%s", out.String())
+	}
+
+	badCA := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		modify func(*config)
+		err    string
+	}{
+		"no collector": {
+			modify: func(c *config) { c.collectorAddr = "" },
+			err:    "collector address must be specified",
+		},
+		"no port": {
+			modify: func(c *config) { c.targetAddr = "127.0.0.1" },
+			err:    "invalid target address",
+		},
+		"bad CA": {
+			modify: func(c *config) { c.collectorCA = badCA },
+			err:    "error creating TLS config for collector",
+		},
+		"missing key": {
+			modify: func(c *config) { c.targetCert = "cert.pem" },
+			err:    "provide both certfile and keyfile",
+		},
+		"bad dscp": {
+			modify: func(c *config) { c.dscp = 64 },
+			err:    "DSCP value must be a value in the range 0-63",
+		},
+		"bad compression": {
+			modify: func(c *config) { c.collectorCompression = "lz4" },
+			err:    "unknown compression method",
+		},
+	} {
+		cfg := newConfig()
+		tc.modify(cfg)
+		err := cfg.check(&out, "get", nil)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", name, tc.err, err)
+		}
+	}
+
+	// With a self config path, the collector and paths come from the target.
+	cfg := &config{targetAddr: "127.0.0.1:6030"}
+	selfConfigPath, err := parseSelfConfigPath("eos_native:/Sysdb/gnmireverse/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := cfg.check(&out, "get", selfConfigPath); err != nil {
+		t.Fatal(err)
+	}
+	if line := "self_config_path: eos_native:/Sysdb/gnmireverse/config\n"; !strings.Contains(
+		out.String(), line) {
+		t.Errorf("expected %q in the configuration:\n%s", line, out.String())
+	}
+}
@@ -13,17 +13,21 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"net"
 	"os"
+	"runtime/debug"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/aristanetworks/glog"
 	"github.com/aristanetworks/goarista/dscp"
+	aglog "github.com/aristanetworks/goarista/glog"
 	gnmilib "github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/gnmireverse"
+	"github.com/aristanetworks/goarista/logger"
 	"github.com/aristanetworks/goarista/netns"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -34,6 +38,7 @@ import (
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/grpclog"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"gopkg.in/yaml.v2"
@@ -42,8 +47,18 @@ import (
 const (
 	// errorLoopRetryMaxInterval caps the time between error loop retries.
 	errorLoopRetryMaxInterval = time.Minute
+
+	// rejectedPathRetryInterval is the time before subscribing again to a
+	// path rejected by the target, doubled on each rejection up to
+	// rejectedPathRetryMaxInterval.
+	rejectedPathRetryInterval    = time.Minute
+	rejectedPathRetryMaxInterval = 30 * time.Minute
 )
 
+// retryLog logs the errors of the retry loops, rate-limited so that an
+// error loop does not flood the logs.
+var retryLog = logger.NewRateLimited(&aglog.Glog{}, 5, 10*time.Minute)
+
 type subscriptionList struct {
 	subs []subscription
 }
@@ -192,6 +207,27 @@ func (l *getList) readGetPathsFile(filePath string) {
 	}
 }
 
+// readPathsFile adds the paths of the paths file at filePath to the
+// SAMPLE subscriptions if they have an interval, and to the TARGET_DEFINED
+// subscriptions otherwise.
+func (c *config) readPathsFile(filePath string) error {
+	entries, err := gnmilib.ReadPathsFile(filePath)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		subs := &c.subTargetDefined.subs
+		if e.Interval > 0 {
+			subs = &c.subSample.subs
+		}
+		if err := setSubscriptions(subs, e.Path, e.Interval); err != nil {
+			return fmt.Errorf("invalid path %q in paths file %q: %s", e.Path, filePath, err)
+		}
+		(*subs)[len(*subs)-1].p.Origin = e.Origin
+	}
+	return nil
+}
+
 func (c *config) parseCredentialsFile(data []byte) error {
 	creds := struct {
 		Username string
@@ -239,16 +275,51 @@ type config struct {
 	getSampleInterval time.Duration
 	getPaths          getList
 
+	// rejectedPaths are the subscription paths permanently rejected by
+	// the target, left out of the next Subscribe requests until their
+	// retry time.
+	rejectedPathsMu sync.Mutex
+	rejectedPaths   map[string]*rejectedPath
+	// sampleSpread subscribes to each SAMPLE path in its own stream,
+	// spreading the start of the streams across their sample interval.
+	sampleSpread bool
+	// getSampleJitter is the maximum random delay before the first Get.
+	getSampleJitter time.Duration
+
 	// collector config
-	collectorAddr        string
-	sourceAddr           string
-	dscp                 int
+	collectorAddr string
+	sourceAddr    string
+	dscp          int
+	// collectorMark is the mark (SO_MARK) set on the connection to the
+	// collector, for fwmark-based policy routing.
+	collectorMark        uint
 	collectorTLS         bool
 	collectorSkipVerify  bool
 	collectorCert        string
 	collectorKey         string
 	collectorCA          string
 	collectorCompression string
+	// collectorGetMaxSize is the maximum size in bytes of the notifications
+	// of a GetResponse sent to the collector. Larger GetResponses are split
+	// in chunks. If zero, GetResponses are not split.
+	collectorGetMaxSize int
+	// collectorCompressionStats enables logging the size of each message
+	// sent to the collector before and after compression.
+	collectorCompressionStats bool
+	// collectorMetadata enables sending the Metadata of the client first
+	// on each stream to the collector.
+	collectorMetadata bool
+
+	// clock is used for sample tickers, timestamps and backoff.
+	// If nil, gnmilib.RealClock is used.
+	clock gnmilib.Clock
+}
+
+func (c *config) getClock() gnmilib.Clock {
+	if c.clock == nil {
+		return gnmilib.RealClock
+	}
+	return c.clock
 }
 
 // Main initializes the gNMIReverse client.
@@ -279,6 +350,11 @@ Credentials specified with -username or -password take precedence.`
 			"For example to subscribe to interface counters with a 30 second sample interval:\n"+
 			"  -sample /interfaces/interface/state/counters@30s\n"+
 			"This option can be repeated multiple times.")
+	pathsFile := flag.String("paths_file", "", "Path to file containing a list of paths to\n"+
+		"subscribe to, one [<origin>:]<path>[@<sample interval>] per line. The paths with an\n"+
+		"interval are subscribed with SAMPLE subscription mode, the others with\n"+
+		"TARGET_DEFINED. Lines starting with # are comments, and 'include <file>' lines\n"+
+		"read the paths of another file.")
 	flag.StringVar(&cfg.origin, "origin", "", "value for the origin field of the Subscribe")
 	flag.BoolVar(&cfg.targetTLSInsecure, "target_tls_insecure", false,
 		"use TLS connection with target and do not verify target certificate")
@@ -307,6 +383,19 @@ Credentials specified with -username or -password take precedence.`
              a subtree with Get) and timestamps for each leaf are preserved.
 `
 	getMode := flag.String("get_mode", "get", getModeUsage)
+	flag.DurationVar(&cfg.getSampleJitter, "get_sample_jitter", 0,
+		"Maximum random delay before the first Get (400ms, 2.5s, 1m, etc.), spreading\n"+
+			"the Gets of devices started together across the sample interval.")
+	flag.BoolVar(&cfg.sampleSpread, "sample_spread", false,
+		"Subscribe to each -sample path in its own stream and spread the start of the\n"+
+			"streams sharing a sample interval evenly across it, instead of sampling\n"+
+			"all paths at the same time.")
+
+	selfConfigPathStr := flag.String("self_config_path", "",
+		"Path on the target to read the configuration of the client from, for example\n"+
+			"eos_native:/Sysdb/gnmireverse/config. The leaves collector_addr, subscribe,\n"+
+			"sample, get and get_sample_interval below it override the flags of the same\n"+
+			"name, and the client is reconfigured whenever they change.")
 
 	flag.StringVar(&cfg.collectorAddr, "collector_addr", "",
 		"Address of collector in the form of [<vrf-name>/]host:port.\n"+
@@ -319,8 +408,20 @@ Credentials specified with -username or -password take precedence.`
 			"For example, [::1]:1234")
 	flag.IntVar(&cfg.dscp, "collector_dscp", 0,
 		"DSCP used on connection to collector, valid values 0-63")
+	flag.UintVar(&cfg.collectorMark, "collector_fwmark", 0,
+		"mark (SO_MARK) set on connection to collector, for fwmark-based policy routing\n"+
+			"(Linux only, requires CAP_NET_ADMIN)")
 	flag.StringVar(&cfg.collectorCompression, "collector_compression", "none",
-		"compression method used when streaming to collector (none | gzip)")
+		"compression method used when streaming to collector (none | gzip | zstd)")
+	flag.IntVar(&cfg.collectorGetMaxSize, "collector_get_max_size", 0,
+		"maximum size in bytes of the notifications of a Get response sent to the collector,\n"+
+			"larger Get responses are split in chunks reassembled by the collector (0 to disable)")
+	flag.BoolVar(&cfg.collectorCompressionStats, "collector_compression_stats", false,
+		"log the size of each message sent to the collector before and after compression")
+	flag.BoolVar(&cfg.collectorMetadata, "collector_metadata", false,
+		"send the version of the client, the target value and the collected paths and\n"+
+			"intervals first on each stream to the collector, for the collector to log them\n"+
+			"(the collector must support it)")
 
 	flag.BoolVar(&cfg.collectorTLS, "collector_tls", true, "use TLS in connection with collector")
 	flag.BoolVar(&cfg.collectorSkipVerify, "collector_tls_skipverify", false,
@@ -332,6 +433,11 @@ Credentials specified with -username or -password take precedence.`
 	flag.StringVar(&cfg.collectorCA, "collector_cafile", "",
 		"path to TLS CA file to verify collector (leave empty to use host's root CA set)")
 
+	check := flag.Bool("check", false,
+		"Validate the flags and the files they refer to, resolve the addresses and load the\n"+
+			"TLS material, then print the effective configuration and exit without dialing.\n"+
+			"Exits with status 1 if the configuration is invalid.")
+
 	flag.Parse()
 
 	// No arguments are expected.
@@ -351,14 +457,16 @@ Credentials specified with -username or -password take precedence.`
 		}
 	}
 
-	if cfg.collectorAddr == "" {
-		glog.Fatal("collector address must be specified")
-	}
-
 	if *credentialsFile != "" {
 		cfg.readCredentialsFile(*credentialsFile)
 	}
 
+	if *pathsFile != "" {
+		if err := cfg.readPathsFile(*pathsFile); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
 	if *getPathsFile != "" {
 		cfg.getPaths.readGetPathsFile(*getPathsFile)
 	}
@@ -375,87 +483,125 @@ Credentials specified with -username or -password take precedence.`
 		glog.Fatalf("Get mode %q invalid", *getMode)
 	}
 
-	isSubscribe := len(cfg.subTargetDefined.subs) != 0 || len(cfg.subSample.subs) != 0
-	isGet := len(cfg.getPaths.openconfigPaths) != 0 || len(cfg.getPaths.eosNativePaths) != 0
+	sc := cfg.streamConfig()
+	sc.applyOrigin(cfg.origin)
+	cfg.setStreamConfig(sc)
 
-	if !isSubscribe && !isGet {
-		glog.Fatal("Subscribe paths or Get paths must be specifed")
-	}
-	if !isGet && cfg.getSampleInterval != 0 {
-		glog.Fatal("Get path must be specified with Get sample interval")
+	if *check {
+		var selfConfigPath *gnmi.Path
+		if *selfConfigPathStr != "" {
+			var err error
+			if selfConfigPath, err = parseSelfConfigPath(*selfConfigPathStr); err != nil {
+				fmt.Fprintf(os.Stderr, "error: self config path %q invalid: %s\n",
+					*selfConfigPathStr, err)
+				os.Exit(1)
+			}
+		}
+		if err := cfg.check(os.Stdout, *getMode, selfConfigPath); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-	if isGet && cfg.getSampleInterval == 0 {
-		glog.Fatal("Get sample interval must be specified with Get path")
+
+	targetConn, err := dialTarget(&cfg)
+	if err != nil {
+		glog.Fatalf("error dialing target %q: %s", cfg.targetAddr, err)
 	}
 
-	if cfg.origin != "" {
-		// Workaround for EOS BUG479731: set origin on paths, rather
-		// than on the prefix.
-		for _, sub := range cfg.subTargetDefined.subs {
-			sub.p.Origin = cfg.origin
-		}
-		for _, sub := range cfg.subSample.subs {
-			sub.p.Origin = cfg.origin
-		}
-		for _, get := range cfg.getPaths.openconfigPaths {
-			get.Origin = cfg.origin
-		}
-		// If "eos_native" was specified by the global origin flag,
-		// point Get paths to EOS native Get paths instead.
-		if strings.ToLower(cfg.origin) == "eos_native" {
-			cfg.getPaths.eosNativePaths = cfg.getPaths.openconfigPaths
-			cfg.getPaths.openconfigPaths = nil
+	if *selfConfigPathStr != "" {
+		selfConfigPath, err := parseSelfConfigPath(*selfConfigPathStr)
+		if err != nil {
+			glog.Fatalf("self config path %q invalid: %s", *selfConfigPathStr, err)
 		}
+		runSelfConfig(&cfg, *getMode, targetConn, selfConfigPath)
+		return
 	}
 
+	if err := sc.validate(cfg.getSampleJitter); err != nil {
+		glog.Fatal(err)
+	}
 	destConn, err := dialCollector(&cfg)
 	if err != nil {
 		glog.Fatalf("error dialing destination %q: %s", cfg.collectorAddr, err)
 	}
-	targetConn, err := dialTarget(&cfg)
-	if err != nil {
-		glog.Fatalf("error dialing target %q: %s", cfg.targetAddr, err)
-	}
+	startStreams(context.Background(), &cfg, *getMode, destConn, targetConn)
+	select {} // Wait forever
+}
 
-	if isSubscribe {
-		go streamResponses(streamSubscribeResponses(&cfg, destConn, targetConn))
-	}
-	if isGet {
-		switch *getMode {
+// startStreams streams the subscriptions and Get paths of cfg from the
+// target to the collector until ctx is done. The returned channel is
+// closed once all the streams have stopped.
+func startStreams(ctx context.Context, cfg *config, getMode string,
+	destConn, targetConn *grpc.ClientConn) <-chan struct{} {
+	var wg sync.WaitGroup
+	run := func(streamResponsesFunc func(context.Context, *errgroup.Group)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			streamResponses(ctx, cfg.getClock(), streamResponsesFunc)
+		}()
+	}
+	sc := cfg.streamConfig()
+	if sc.isSubscribe() {
+		run(streamSubscribeResponses(cfg, destConn, targetConn))
+	}
+	if sc.isGet() {
+		switch getMode {
 		case "get":
-			go streamResponses(streamGetResponses(&cfg, destConn, targetConn))
+			run(streamGetResponses(cfg, destConn, targetConn))
 		case "subscribe":
-			go streamResponses(streamGetResponsesModeSubscribe(&cfg, destConn, targetConn))
+			run(streamGetResponsesModeSubscribe(cfg, destConn, targetConn))
 		}
 	}
-	select {} // Wait forever
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
 }
 
-func streamResponses(streamResponsesFunc func(context.Context, *errgroup.Group)) {
-	// Used for error loop detection and backoff retries.
-	var lastErrorTime time.Time
+// newBackOff returns the backoff of the retries, which never stops.
+func newBackOff(clock gnmilib.Clock) *backoff.ExponentialBackOff {
 	bo := backoff.NewExponentialBackOff()
 	bo.MaxElapsedTime = 0 // Never stop
 	bo.MaxInterval = errorLoopRetryMaxInterval
+	bo.Clock = clock
 	bo.Reset()
+	return bo
+}
 
-	for {
+// streamResponses runs streamResponsesFunc until ctx is done, retrying
+// with backoff whenever it fails.
+func streamResponses(ctx context.Context, clock gnmilib.Clock,
+	streamResponsesFunc func(context.Context, *errgroup.Group)) {
+	// Used for error loop detection and backoff retries.
+	var lastErrorTime time.Time
+	bo := newBackOff(clock)
+
+	for ctx.Err() == nil {
 		// Start publisher and client in a loop, each running in
 		// their own goroutine. If either of them encounters an error,
 		// retry.
-		var eg *errgroup.Group
-		eg, ctx := errgroup.WithContext(context.Background())
-		streamResponsesFunc(ctx, eg)
+		eg, egCtx := errgroup.WithContext(ctx)
+		streamResponsesFunc(egCtx, eg)
 		if err := eg.Wait(); err != nil {
-			nowTime := time.Now()
+			if ctx.Err() != nil {
+				return
+			}
+			nowTime := clock.Now()
 			// If the last error was from a while ago, reset the backoff interval because
 			// this error is not from an error loop.
 			if lastErrorTime.Add(errorLoopRetryMaxInterval * 2).Before(nowTime) {
 				bo.Reset()
 			}
 			lastErrorTime = nowTime
-			glog.Infof("encountered error, retrying: %s", err)
-			time.Sleep(bo.NextBackOff())
+			retryLog.Infof("encountered error, retrying: %s", err)
+			select {
+			case <-ctx.Done():
+			case <-clock.After(bo.NextBackOff()):
+			}
 		}
 	}
 }
@@ -465,7 +611,7 @@ func streamSubscribeResponses(cfg *config, destConn, targetConn *grpc.ClientConn
 	return func(ctx context.Context, eg *errgroup.Group) {
 		c := make(chan *gnmi.SubscribeResponse)
 		eg.Go(func() error {
-			return publish(ctx, destConn, c)
+			return publish(ctx, destConn, c, cfg.metadata())
 		})
 		eg.Go(func() error {
 			return subscribe(ctx, cfg, targetConn, c)
@@ -478,7 +624,7 @@ func streamGetResponses(cfg *config, destConn, targetConn *grpc.ClientConn) func
 	return func(ctx context.Context, eg *errgroup.Group) {
 		c := make(chan *gnmi.GetResponse)
 		eg.Go(func() error {
-			return publishGet(ctx, destConn, c)
+			return publishGet(ctx, destConn, c, cfg.collectorGetMaxSize, cfg.metadata())
 		})
 		eg.Go(func() error {
 			return sampleGet(ctx, cfg, targetConn, c)
@@ -491,7 +637,7 @@ func streamGetResponsesModeSubscribe(cfg *config, destConn, targetConn *grpc.Cli
 	return func(ctx context.Context, eg *errgroup.Group) {
 		c := make(chan *gnmi.GetResponse)
 		eg.Go(func() error {
-			return publishGet(ctx, destConn, c)
+			return publishGet(ctx, destConn, c, cfg.collectorGetMaxSize, cfg.metadata())
 		})
 		eg.Go(func() error {
 			return sampleGetModeSubscribe(ctx, cfg, targetConn, c)
@@ -516,13 +662,17 @@ func dialCollector(cfg *config) (*grpc.ClientConn, error) {
 
 	switch cfg.collectorCompression {
 	case "", "none":
-	case "gzip":
+	case gzip.Name, gnmilib.ZstdName:
 		dialOptions = append(dialOptions,
-			grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+			grpc.WithDefaultCallOptions(grpc.UseCompressor(cfg.collectorCompression)))
 	default:
 		return nil, fmt.Errorf("unknown compression method %q", cfg.collectorCompression)
 	}
 
+	if cfg.collectorCompressionStats {
+		dialOptions = append(dialOptions, grpc.WithStatsHandler(compressionStatsHandler{}))
+	}
+
 	nsName, addr, err := netns.ParseAddress(cfg.collectorAddr)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing address: %s", err)
@@ -540,7 +690,7 @@ func dialCollector(cfg *config) (*grpc.ClientConn, error) {
 func newVRFDialer(d *net.Dialer, nsName string) func(context.Context, string) (net.Conn, error) {
 	return func(ctx context.Context, addr string) (net.Conn, error) {
 		var conn net.Conn
-		err := netns.Do(nsName, func() error {
+		err := netns.DefaultPool.Do(nsName, func() error {
 			c, err := d.DialContext(ctx, "tcp", addr)
 			if err != nil {
 				return err
@@ -608,15 +758,19 @@ func newDialer(cfg *config) (*net.Dialer, error) {
 		d.LocalAddr = &localAddr
 	}
 
+	var tos byte
 	if cfg.dscp != 0 {
 		if cfg.dscp < 0 || cfg.dscp >= 64 {
 			return nil, fmt.Errorf("DSCP value must be a value in the range 0-63, got %d", cfg.dscp)
 		}
 		// DSCP is the top 6 bits of the TOS byte
-		tos := byte(cfg.dscp << 2)
-		d.Control = func(network, address string, c syscall.RawConn) error {
-			return dscp.SetTOS(network, c, tos)
-		}
+		tos = byte(cfg.dscp << 2)
+	}
+	if cfg.collectorMark > math.MaxUint32 {
+		return nil, fmt.Errorf("fwmark must fit in 32 bits, got %d", cfg.collectorMark)
+	}
+	if tos != 0 || cfg.collectorMark != 0 {
+		d.Control = dscp.ControlWithMark(tos, uint32(cfg.collectorMark))
 	}
 
 	return &d, nil
@@ -650,13 +804,53 @@ func dialTarget(cfg *config) (*grpc.ClientConn, error) {
 	return grpc.Dial(addr, dialOptions...)
 }
 
+// metadata returns the Metadata sent to the collector, or nil if
+// collectorMetadata is not set.
+func (c *config) metadata() *gnmireverse.Metadata {
+	if !c.collectorMetadata {
+		return nil
+	}
+	md := &gnmireverse.Metadata{AgentVersion: "unknown", Target: c.targetVal}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		md.AgentVersion = info.Main.Version
+	}
+	add := func(p *gnmi.Path, mode string, interval time.Duration) {
+		path := gnmilib.StrPath(p)
+		if p.Origin != "" {
+			path = p.Origin + ":" + path
+		}
+		md.Paths = append(md.Paths, gnmireverse.CollectedPath{Path: path, Mode: mode,
+			Interval: interval})
+	}
+	for _, sub := range c.subTargetDefined.subs {
+		add(sub.p, "target_defined", sub.interval)
+	}
+	for _, sub := range c.subSample.subs {
+		add(sub.p, "sample", sub.interval)
+	}
+	for _, p := range c.getPaths.openconfigPaths {
+		add(p, "get", c.getSampleInterval)
+	}
+	for _, p := range c.getPaths.eosNativePaths {
+		add(p, "get", c.getSampleInterval)
+	}
+	return md
+}
+
+// publish sends the SubscribeResponses from c to the collector, after md
+// if it is not nil.
 func publish(ctx context.Context, destConn *grpc.ClientConn,
-	c <-chan *gnmi.SubscribeResponse) error {
+	c <-chan *gnmi.SubscribeResponse, md *gnmireverse.Metadata) error {
 	client := gnmireverse.NewGNMIReverseClient(destConn)
 	stream, err := client.Publish(ctx, grpc.WaitForReady(true))
 	if err != nil {
 		return fmt.Errorf("error from Publish: %s", err)
 	}
+	if md != nil {
+		if err := stream.Send(md.SubscribeResponse()); err != nil {
+			return fmt.Errorf("error from Publish.Send: %s", err)
+		}
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -669,12 +863,21 @@ func publish(ctx context.Context, destConn *grpc.ClientConn,
 	}
 }
 
-func publishGet(ctx context.Context, destConn *grpc.ClientConn, c <-chan *gnmi.GetResponse) error {
+// publishGet sends the GetResponses from c to the collector, after md if
+// it is not nil, splitting the GetResponses larger than maxSize in chunks
+// if maxSize is positive.
+func publishGet(ctx context.Context, destConn *grpc.ClientConn, c <-chan *gnmi.GetResponse,
+	maxSize int, md *gnmireverse.Metadata) error {
 	client := gnmireverse.NewGNMIReverseClient(destConn)
 	stream, err := client.PublishGet(ctx, grpc.WaitForReady(true))
 	if err != nil {
 		return fmt.Errorf("error from PublishGet: %s", err)
 	}
+	if md != nil {
+		if err := stream.Send(md.GetResponse()); err != nil {
+			return fmt.Errorf("error from PublishGet.Send: %s", err)
+		}
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -687,42 +890,74 @@ func publishGet(ctx context.Context, destConn *grpc.ClientConn, c <-chan *gnmi.G
 			if glog.V(7) {
 				glog.Infof("send Get response to collector: %v", response)
 			}
-			if err := stream.Send(response); err != nil {
-				return fmt.Errorf("error from PublishGet.Send: %s", err)
+			chunks := gnmireverse.ChunkGetResponse(response, maxSize)
+			if len(chunks) > 1 && glog.V(3) {
+				glog.Infof("split Get response in %d chunks", len(chunks))
+			}
+			for _, chunk := range chunks {
+				if err := stream.Send(chunk); err != nil {
+					return fmt.Errorf("error from PublishGet.Send: %s", err)
+				}
 			}
 		}
 	}
 }
 
+// compressionStatsHandler is a grpc stats.Handler logging the size of each
+// message sent before and after compression.
+type compressionStatsHandler struct{}
+
+type methodKey struct{}
+
+func (compressionStatsHandler) TagRPC(ctx context.Context,
+	info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, methodKey{}, info.FullMethodName)
+}
+
+func (compressionStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	out, ok := s.(*stats.OutPayload)
+	if !ok {
+		return
+	}
+	var ratio float64
+	if out.Length > 0 {
+		ratio = float64(out.CompressedLength) / float64(out.Length)
+	}
+	method, _ := ctx.Value(methodKey{}).(string)
+	glog.Infof("sent %s message: size_bytes=%d compressed_bytes=%d wire_bytes=%d ratio=%.3f",
+		method, out.Length, out.CompressedLength, out.WireLength, ratio)
+}
+
+func (compressionStatsHandler) TagConn(ctx context.Context,
+	info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (compressionStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
 func subscribe(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 	c chan<- *gnmi.SubscribeResponse) error {
 	client := gnmi.NewGNMIClient(targetConn)
-	subList := &gnmi.SubscriptionList{
-		Prefix: &gnmi.Path{Target: cfg.targetVal},
-	}
 
+	var targetDefinedSubs, sampleSubs []*gnmi.Subscription
 	for _, sub := range cfg.subTargetDefined.subs {
-		subList.Subscription = append(subList.Subscription,
-			&gnmi.Subscription{
-				Path:              sub.p,
-				Mode:              gnmi.SubscriptionMode_TARGET_DEFINED,
-				HeartbeatInterval: uint64(sub.interval),
-			},
-		)
+		targetDefinedSubs = cfg.appendSubscription(targetDefinedSubs, &gnmi.Subscription{
+			Path:              sub.p,
+			Mode:              gnmi.SubscriptionMode_TARGET_DEFINED,
+			HeartbeatInterval: uint64(sub.interval),
+		})
 	}
 	for _, sub := range cfg.subSample.subs {
-		subList.Subscription = append(subList.Subscription,
-			&gnmi.Subscription{
-				Path:           sub.p,
-				Mode:           gnmi.SubscriptionMode_SAMPLE,
-				SampleInterval: uint64(sub.interval),
-			},
-		)
+		sampleSubs = cfg.appendSubscription(sampleSubs, &gnmi.Subscription{
+			Path:           sub.p,
+			Mode:           gnmi.SubscriptionMode_SAMPLE,
+			SampleInterval: uint64(sub.interval),
+		})
 	}
-	request := &gnmi.SubscribeRequest{
-		Request: &gnmi.SubscribeRequest_Subscribe{
-			Subscribe: subList,
-		},
+	if len(targetDefinedSubs) == 0 && len(sampleSubs) == 0 {
+		glog.Errorf("all subscription paths were rejected by the target")
+		<-ctx.Done()
+		return ctx.Err()
 	}
 
 	if cfg.username != "" {
@@ -732,18 +967,113 @@ func subscribe(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 				"password", cfg.password),
 		)
 	}
+	if !cfg.sampleSpread || len(sampleSubs) < 2 {
+		return subscribeStream(ctx, cfg, client, append(targetDefinedSubs, sampleSubs...), c)
+	}
+
+	// Subscribe to each SAMPLE path in its own stream, delaying the
+	// start of the streams so that the samples of the paths sharing an
+	// interval are spread evenly across it.
+	eg, ctx := errgroup.WithContext(ctx)
+	if len(targetDefinedSubs) > 0 {
+		eg.Go(func() error {
+			return subscribeStream(ctx, cfg, client, targetDefinedSubs, c)
+		})
+	}
+	offsets := sampleOffsets(sampleSubs)
+	for i, sub := range sampleSubs {
+		sub, offset := sub, offsets[i]
+		eg.Go(func() error {
+			glog.V(3).Infof("start SAMPLE subscription to %s in %s",
+				gnmilib.StrPath(sub.Path), offset)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-cfg.getClock().After(offset):
+			}
+			return subscribeStream(ctx, cfg, client, []*gnmi.Subscription{sub}, c)
+		})
+	}
+	return eg.Wait()
+}
+
+// rejectedPath is a subscription path rejected by the target.
+type rejectedPath struct {
+	err error
+	// retry is the time after which the path is subscribed to again, as
+	// the target may accept it once its config is fixed.
+	retry time.Time
+	bo    *backoff.ExponentialBackOff
+}
+
+// appendSubscription appends sub to subs unless its path was rejected by
+// the target and is not due for a retry.
+func (c *config) appendSubscription(subs []*gnmi.Subscription,
+	sub *gnmi.Subscription) []*gnmi.Subscription {
+	p := gnmilib.StrPath(sub.Path)
+	c.rejectedPathsMu.Lock()
+	rejected, ok := c.rejectedPaths[p]
+	c.rejectedPathsMu.Unlock()
+	if ok {
+		if c.getClock().Now().Before(rejected.retry) {
+			glog.V(2).Infof("skipping path %s rejected by the target until %s: %s",
+				p, rejected.retry, rejected.err)
+			return subs
+		}
+		glog.Infof("subscribing again to path %s rejected by the target: %s",
+			p, rejected.err)
+	}
+	return append(subs, sub)
+}
+
+// sampleOffsets returns the start offset of each SAMPLE subscription: the
+// n subscriptions sharing an interval start at every interval/n.
+func sampleOffsets(subs []*gnmi.Subscription) []time.Duration {
+	count := map[uint64]int{}
+	for _, sub := range subs {
+		count[sub.SampleInterval]++
+	}
+	index := map[uint64]int{}
+	offsets := make([]time.Duration, len(subs))
+	for i, sub := range subs {
+		interval := sub.SampleInterval
+		offsets[i] = time.Duration(interval / uint64(count[interval]) * uint64(index[interval]))
+		index[interval]++
+	}
+	return offsets
+}
+
+// subscribeStream subscribes to subs in one Subscribe stream and sends
+// the responses to c.
+func subscribeStream(ctx context.Context, cfg *config, client gnmi.GNMIClient,
+	subs []*gnmi.Subscription, c chan<- *gnmi.SubscribeResponse) error {
+	paths := make([]string, len(subs))
+	for i, sub := range subs {
+		paths[i] = gnmilib.StrPath(sub.Path)
+	}
+	request := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Prefix:       &gnmi.Path{Target: cfg.targetVal},
+				Subscription: subs,
+			},
+		},
+	}
 	stream, err := client.Subscribe(ctx, grpc.WaitForReady(true))
 	if err != nil {
-		return fmt.Errorf("error from Subscribe: %s", err)
+		return cfg.subscribeFailed(newSubscribeError("Subscribe", err, paths))
 	}
 	if err := stream.Send(request); err != nil {
 		return fmt.Errorf("error sending SubscribeRequest: %s", err)
 	}
 
-	for {
+	for accepted := false; ; accepted = true {
 		resp, err := stream.Recv()
 		if err != nil {
-			return fmt.Errorf("error from Subscribe.Recv: %s", err)
+			return cfg.subscribeFailed(newSubscribeError("Subscribe.Recv", err, paths))
+		}
+		if !accepted {
+			cfg.subscribeAccepted(paths)
 		}
 		select {
 		case <-ctx.Done():
@@ -753,6 +1083,58 @@ func subscribe(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 	}
 }
 
+// subscribeFailed records the path permanently rejected by the target, if
+// any, so that it is skipped when subscribing again instead of failing in
+// a loop. The path is retried with backoff.
+func (c *config) subscribeFailed(err *subscribeError) error {
+	if err.permanent && err.path != "" {
+		c.rejectedPathsMu.Lock()
+		defer c.rejectedPathsMu.Unlock()
+		if c.rejectedPaths == nil {
+			c.rejectedPaths = map[string]*rejectedPath{}
+		}
+		rejected, ok := c.rejectedPaths[err.path]
+		if !ok {
+			bo := newBackOff(c.getClock())
+			bo.InitialInterval = rejectedPathRetryInterval
+			bo.MaxInterval = rejectedPathRetryMaxInterval
+			bo.Reset()
+			rejected = &rejectedPath{bo: bo}
+			c.rejectedPaths[err.path] = rejected
+		}
+		rejected.err = err
+		rejected.retry = c.getClock().Now().Add(rejected.bo.NextBackOff())
+		glog.Errorf("skipping path %s until %s: %s", err.path, rejected.retry, err)
+	}
+	return err
+}
+
+// subscribeAccepted forgets the rejections of paths, once the target
+// accepted a Subscribe request for them.
+func (c *config) subscribeAccepted(paths []string) {
+	c.rejectedPathsMu.Lock()
+	defer c.rejectedPathsMu.Unlock()
+	for _, p := range paths {
+		delete(c.rejectedPaths, p)
+	}
+}
+
+// waitGetSampleJitter waits for a random delay shorter than the Get sample
+// jitter, if any.
+func waitGetSampleJitter(ctx context.Context, cfg *config) error {
+	if cfg.getSampleJitter <= 0 {
+		return nil
+	}
+	delay := time.Duration(rand.Int63n(int64(cfg.getSampleJitter)))
+	glog.V(3).Infof("delay first Get by %s", delay)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cfg.getClock().After(delay):
+		return nil
+	}
+}
+
 func sampleGet(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 	c chan<- *gnmi.GetResponse) error {
 	client := gnmi.NewGNMIClient(targetConn)
@@ -773,9 +1155,13 @@ func sampleGet(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 		)
 	}
 
+	if err := waitGetSampleJitter(ctx, cfg); err != nil {
+		return err
+	}
+
 	// Set up a ticker for a consistent interval to exclude the additional time taken
 	// for issuing the Get request(s) and processing the response(s).
-	ticker := time.NewTicker(cfg.getSampleInterval)
+	ticker := cfg.getClock().NewTicker(cfg.getSampleInterval)
 	defer ticker.Stop()
 
 	for {
@@ -812,7 +1198,7 @@ func sampleGet(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 		}
 
 		// Combine the Get responses.
-		currentTime := time.Now().UnixNano()
+		currentTime := cfg.getClock().Now().UnixNano()
 		combinedGetResponse := combineGetResponses(
 			currentTime, cfg.targetVal, openConfigGetResponse, eosNativeGetResponse)
 
@@ -826,7 +1212,7 @@ func sampleGet(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
+		case <-ticker.C():
 		}
 	}
 }
@@ -912,16 +1298,20 @@ func sampleGetModeSubscribe(ctx context.Context, cfg *config, targetConn *grpc.C
 			isEOSNativeSubscribeOnceSupported, eosNativeSubscribeRequest)
 	}
 
+	if err := waitGetSampleJitter(ctx, cfg); err != nil {
+		return err
+	}
+
 	// Set up a ticker for a consistent interval to exclude the additional time taken
 	// for issuing the Subscribe requests and processing the responses.
-	ticker := time.NewTicker(cfg.getSampleInterval)
+	ticker := cfg.getClock().NewTicker(cfg.getSampleInterval)
 	defer ticker.Stop()
 
 	for {
 		// Measure the time taken to process Subscribe notifications.
 		var processingStartTime time.Time
 		if glog.V(5) {
-			processingStartTime = time.Now()
+			processingStartTime = cfg.getClock().Now()
 		}
 
 		// Gather notifications for OpenConfig paths.
@@ -955,14 +1345,14 @@ func sampleGetModeSubscribe(ctx context.Context, cfg *config, targetConn *grpc.C
 		if glog.V(5) {
 			// If the processing time exceeds the sample interval, then
 			// the sample interval is too low.
-			processingTime := time.Since(processingStartTime)
+			processingTime := cfg.getClock().Since(processingStartTime)
 			glog.Infof("wait: get_sample_interval=%s processing_time=%s ",
 				cfg.getSampleInterval, processingTime)
 		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
+		case <-ticker.C():
 		}
 	}
 }
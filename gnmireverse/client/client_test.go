@@ -12,13 +12,16 @@ import (
 	"time"
 
 	"github.com/aristanetworks/glog"
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/gnmireverse"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 func TestSampleList(t *testing.T) {
@@ -316,7 +319,8 @@ func runStreamGetResponsesTest(t *testing.T, cfg *config, collectorErrChan chan
 	glog.V(1).Infof("gNMIReverse client publish Get response from %s to %s",
 		targetConn.Target(), destConn.Target())
 	go func() {
-		streamResponses(streamResponsesFunc(cfg, destConn, targetConn))
+		streamResponses(context.Background(), cfg.getClock(),
+			streamResponsesFunc(cfg, destConn, targetConn))
 	}()
 
 	// Check that the gNMIReverse collector server receives the expected Get response.
@@ -701,3 +705,251 @@ func eosNativeSubscribeStream(stream gnmi.GNMI_SubscribeServer) error {
 	<-stream.Context().Done()
 	return nil
 }
+
+// rejectingGNMIServer rejects the Subscribe requests for /bad, naming it in
+// the details of the status.
+type rejectingGNMIServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (*rejectingGNMIServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	for _, sub := range req.GetSubscribe().GetSubscription() {
+		if p := gnmilib.StrPath(sub.Path); p == "/bad" {
+			st, err := status.New(codes.InvalidArgument, "invalid path "+p).WithDetails(
+				&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: p, Description: "invalid path"}}})
+			if err != nil {
+				return err
+			}
+			return st.Err()
+		}
+	}
+	if err := stream.Send(&gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true},
+	}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestSubscribeSkipsRejectedPaths(t *testing.T) {
+	cfg := &config{targetAddr: getTestAddress(t)}
+	for _, p := range []string{"/good", "/bad"} {
+		if err := cfg.subTargetDefined.Set(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listener, err := net.Listen("tcp", cfg.targetAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	gnmi.RegisterGNMIServer(s, &rejectingGNMIServer{})
+	go s.Serve(listener)
+	defer s.Stop()
+	conn, err := dialTarget(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c := make(chan *gnmi.SubscribeResponse, 1)
+	err = subscribe(ctx, cfg, conn, c)
+	subErr, ok := err.(*subscribeError)
+	if !ok {
+		t.Fatalf("expected a *subscribeError, got %#v", err)
+	}
+	if subErr.code != codes.InvalidArgument || subErr.path != "/bad" || !subErr.permanent {
+		t.Fatalf("unexpected error: %s", subErr)
+	}
+	if _, ok := cfg.rejectedPaths["/bad"]; !ok {
+		t.Fatalf("expected /bad to be rejected, got %v", cfg.rejectedPaths)
+	}
+
+	// The second Subscribe skips /bad and succeeds.
+	errc := make(chan error, 1)
+	subCtx, subCancel := context.WithCancel(ctx)
+	go func() { errc <- subscribe(subCtx, cfg, conn, c) }()
+	select {
+	case resp := <-c:
+		if !resp.GetSyncResponse() {
+			t.Errorf("expected a sync response, got %s", resp)
+		}
+	case err := <-errc:
+		t.Fatalf("unexpected error: %s", err)
+	}
+	subCancel()
+	if err := <-errc; err == nil || err.(*subscribeError).permanent {
+		t.Errorf("expected a transient error once cancelled, got %v", err)
+	}
+}
+
+func TestNewSubscribeError(t *testing.T) {
+	paths := []string{"/interfaces", "/system"}
+	withDetails := func(code codes.Code, details ...protoadapt.MessageV1) error {
+		st, err := status.New(code, "rejected").WithDetails(details...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.Err()
+	}
+	for name, tc := range map[string]struct {
+		err       error
+		path      string
+		permanent bool
+	}{
+		"bad request": {
+			err: withDetails(codes.InvalidArgument, &errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "/system"}}}),
+			path:      "/system",
+			permanent: true,
+		},
+		"resource info": {
+			err: withDetails(codes.PermissionDenied,
+				&errdetails.ResourceInfo{ResourceName: "/interfaces"}),
+			path:      "/interfaces",
+			permanent: true,
+		},
+		"child path": {
+			err: withDetails(codes.NotFound, &errdetails.ResourceInfo{
+				ResourceName: "/interfaces/interface[name=Et1]/state"}),
+			permanent: true,
+		},
+		"message only": {
+			err: status.Errorf(codes.InvalidArgument,
+				"invalid path /interfaces/interface[name=Et1]/state"),
+			permanent: true,
+		},
+		"transient": {
+			err: withDetails(codes.Unavailable,
+				&errdetails.ResourceInfo{ResourceName: "/system"}),
+			path: "/system",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := newSubscribeError("Subscribe", tc.err, paths)
+			if e.path != tc.path || e.permanent != tc.permanent {
+				t.Errorf("expected path %q and permanent %t, got %s", tc.path, tc.permanent, e)
+			}
+		})
+	}
+}
+
+func TestRejectedPathRetry(t *testing.T) {
+	clock := gnmilib.NewFakeClock(time.Unix(0, 0))
+	cfg := &config{clock: clock}
+	sub := &gnmi.Subscription{Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "bad"}}}}
+	reject := func() {
+		cfg.subscribeFailed(&subscribeError{code: codes.PermissionDenied, path: "/bad",
+			permanent: true})
+	}
+	reject()
+	if subs := cfg.appendSubscription(nil, sub); len(subs) != 0 {
+		t.Fatal("expected /bad to be skipped right after its rejection")
+	}
+	// The first retry is after rejectedPathRetryInterval, with jitter.
+	clock.Advance(2 * rejectedPathRetryInterval)
+	if subs := cfg.appendSubscription(nil, sub); len(subs) != 1 {
+		t.Fatal("expected /bad to be retried")
+	}
+	reject()
+	if subs := cfg.appendSubscription(nil, sub); len(subs) != 0 {
+		t.Fatal("expected /bad to be skipped after its second rejection")
+	}
+	cfg.subscribeAccepted([]string{"/bad"})
+	if subs := cfg.appendSubscription(nil, sub); len(subs) != 1 {
+		t.Fatal("expected /bad to be subscribed to once accepted")
+	}
+}
+
+func TestSampleOffsets(t *testing.T) {
+	sub := func(interval time.Duration) *gnmi.Subscription {
+		return &gnmi.Subscription{
+			Mode:           gnmi.SubscriptionMode_SAMPLE,
+			SampleInterval: uint64(interval),
+		}
+	}
+	subs := []*gnmi.Subscription{
+		sub(30 * time.Second),
+		sub(time.Minute),
+		sub(30 * time.Second),
+		sub(30 * time.Second),
+		sub(time.Minute),
+		sub(10 * time.Second),
+	}
+	expected := []time.Duration{
+		0,
+		0,
+		10 * time.Second,
+		20 * time.Second,
+		30 * time.Second,
+		0,
+	}
+	got := sampleOffsets(subs)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d offsets, got %d", len(expected), len(got))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("offset %d: expected %s, got %s", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestConfigMetadata(t *testing.T) {
+	cfg := &config{targetVal: "dut", origin: "openconfig"}
+	if md := cfg.metadata(); md != nil {
+		t.Errorf("unexpected metadata %s", md)
+	}
+	cfg.collectorMetadata = true
+	for _, s := range []string{"/system@1m", "/interfaces"} {
+		if err := cfg.subTargetDefined.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cfg.subSample.Set("/interfaces/interface/state/counters@30s"); err != nil {
+		t.Fatal(err)
+	}
+	// A path of a paths file keeps its own origin under -origin.
+	if err := cfg.subSample.Set("/Kernel/proc@10s"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.subSample.subs[1].p.Origin = "eos_native"
+	for _, s := range []string{"eos_native:/Kernel/sysinfo", "/components"} {
+		if err := cfg.getPaths.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg.getSampleInterval = 10 * time.Second
+	sc := cfg.streamConfig()
+	sc.applyOrigin(cfg.origin)
+	cfg.setStreamConfig(sc)
+	md := cfg.metadata()
+	if md.Target != "dut" || md.AgentVersion == "" {
+		t.Errorf("unexpected metadata %s", md)
+	}
+	expected := []gnmireverse.CollectedPath{
+		{Path: "openconfig:/system", Mode: "target_defined", Interval: time.Minute},
+		{Path: "openconfig:/interfaces", Mode: "target_defined"},
+		{Path: "openconfig:/interfaces/interface/state/counters", Mode: "sample",
+			Interval: 30 * time.Second},
+		{Path: "eos_native:/Kernel/proc", Mode: "sample", Interval: 10 * time.Second},
+		{Path: "openconfig:/components", Mode: "get", Interval: 10 * time.Second},
+		{Path: "eos_native:/Kernel/sysinfo", Mode: "get", Interval: 10 * time.Second},
+	}
+	if len(md.Paths) != len(expected) {
+		t.Fatalf("expected paths %v, got %v", expected, md.Paths)
+	}
+	for i, p := range expected {
+		if md.Paths[i] != p {
+			t.Errorf("expected path %v, got %v", p, md.Paths[i])
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aristanetworks/glog"
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// streamConfig is the part of the config setting what is streamed from
+// the target to the collector. It can be read from the target with
+// -self_config_path.
+type streamConfig struct {
+	collectorAddr     string
+	subTargetDefined  subscriptionList
+	subSample         sampleList
+	getPaths          getList
+	getSampleInterval time.Duration
+}
+
+func (c *config) streamConfig() streamConfig {
+	return streamConfig{
+		collectorAddr:     c.collectorAddr,
+		subTargetDefined:  c.subTargetDefined,
+		subSample:         c.subSample,
+		getPaths:          c.getPaths,
+		getSampleInterval: c.getSampleInterval,
+	}
+}
+
+func (c *config) setStreamConfig(sc streamConfig) {
+	c.collectorAddr = sc.collectorAddr
+	c.subTargetDefined = sc.subTargetDefined
+	c.subSample = sc.subSample
+	c.getPaths = sc.getPaths
+	c.getSampleInterval = sc.getSampleInterval
+}
+
+func (sc *streamConfig) isSubscribe() bool {
+	return len(sc.subTargetDefined.subs) != 0 || len(sc.subSample.subs) != 0
+}
+
+func (sc *streamConfig) isGet() bool {
+	return len(sc.getPaths.openconfigPaths) != 0 || len(sc.getPaths.eosNativePaths) != 0
+}
+
+func (sc *streamConfig) String() string {
+	return fmt.Sprintf("collector %s, subscribe [%s], sample [%s], get [%s] every %s",
+		sc.collectorAddr, &sc.subTargetDefined, &sc.subSample, &sc.getPaths,
+		sc.getSampleInterval)
+}
+
+func (sc *streamConfig) validate(getSampleJitter time.Duration) error {
+	isSubscribe, isGet := sc.isSubscribe(), sc.isGet()
+	switch {
+	case sc.collectorAddr == "":
+		return fmt.Errorf("collector address must be specified")
+	case !isSubscribe && !isGet:
+		return fmt.Errorf("Subscribe paths or Get paths must be specifed")
+	case !isGet && sc.getSampleInterval != 0:
+		return fmt.Errorf("Get path must be specified with Get sample interval")
+	case isGet && sc.getSampleInterval == 0:
+		return fmt.Errorf("Get sample interval must be specified with Get path")
+	case getSampleJitter < 0 || getSampleJitter > sc.getSampleInterval:
+		return fmt.Errorf("Get sample jitter must be between 0 and the Get sample interval")
+	}
+	return nil
+}
+
+// applyOrigin sets origin on the paths of sc without an origin of their
+// own, such as the ones of a paths file.
+func (sc *streamConfig) applyOrigin(origin string) {
+	if origin == "" {
+		return
+	}
+	// Workaround for EOS BUG479731: set origin on paths, rather
+	// than on the prefix.
+	for _, sub := range sc.subTargetDefined.subs {
+		if sub.p.Origin == "" {
+			sub.p.Origin = origin
+		}
+	}
+	for _, sub := range sc.subSample.subs {
+		if sub.p.Origin == "" {
+			sub.p.Origin = origin
+		}
+	}
+	for _, get := range sc.getPaths.openconfigPaths {
+		if get.Origin == "" {
+			get.Origin = origin
+		}
+	}
+	// If "eos_native" was specified by the global origin flag,
+	// point Get paths to EOS native Get paths instead.
+	if strings.ToLower(origin) == "eos_native" {
+		sc.getPaths.eosNativePaths = sc.getPaths.openconfigPaths
+		sc.getPaths.openconfigPaths = nil
+	}
+}
+
+// parseSelfConfigPath parses the -self_config_path flag, a path with an
+// optional origin prefix such as eos_native:/Sysdb/gnmireverse/config.
+func parseSelfConfigPath(s string) (*gnmi.Path, error) {
+	var origin string
+	if i := strings.Index(s, ":/"); i > 0 && !strings.ContainsAny(s[:i], "/[") {
+		origin, s = s[:i], s[i+1:]
+	}
+	p, err := gnmilib.ParseGNMIElements(gnmilib.SplitPath(s))
+	if err != nil {
+		return nil, err
+	}
+	p.Origin = origin
+	return p, nil
+}
+
+// selfConfigLeaves tracks the values of the leaves below the
+// -self_config_path of the target. Each leaf is named after the flag it
+// overrides, with dashes or underscores: collector_addr, subscribe,
+// sample, get and get_sample_interval.
+type selfConfigLeaves struct {
+	path   *gnmi.Path
+	leaves map[string][]string
+}
+
+func newSelfConfigLeaves(p *gnmi.Path) *selfConfigLeaves {
+	return &selfConfigLeaves{path: p, leaves: map[string][]string{}}
+}
+
+func hasPrefix(elems, prefix []*gnmi.PathElem) bool {
+	if len(elems) < len(prefix) {
+		return false
+	}
+	for i, elem := range prefix {
+		if !proto.Equal(elem, elems[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// relativeElems returns the elements of p below the config path, or false
+// if p is not at or below the config path.
+func (l *selfConfigLeaves) relativeElems(p *gnmi.Path) ([]*gnmi.PathElem, bool) {
+	if !hasPrefix(p.Elem, l.path.GetElem()) {
+		return nil, false
+	}
+	return p.Elem[len(l.path.GetElem()):], true
+}
+
+// leafName strips the YANG module from name and replaces its dashes with
+// underscores, so that leaves are named after their flag.
+func leafName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// leafValues returns the values of a leaf: the elements of a leaf-list,
+// or the whitespace separated fields of a string.
+func leafValues(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			values = append(values, leafValues(e)...)
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// update applies the updates and deletes of notif to the leaves.
+func (l *selfConfigLeaves) update(notif *gnmi.Notification) error {
+	for _, p := range notif.Delete {
+		p = gnmilib.JoinPaths(notif.Prefix, p)
+		if rel, ok := l.relativeElems(p); ok && len(rel) > 0 {
+			delete(l.leaves, leafName(rel[0].Name))
+		} else if hasPrefix(l.path.GetElem(), p.Elem) {
+			// The config path or one of its parents was deleted.
+			l.leaves = map[string][]string{}
+		}
+	}
+	for _, u := range notif.Update {
+		rel, ok := l.relativeElems(gnmilib.JoinPaths(notif.Prefix, u.Path))
+		if !ok {
+			continue
+		}
+		v, err := gnmilib.ExtractValue(u)
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %s",
+				gnmilib.StrPath(gnmilib.JoinPaths(notif.Prefix, u.Path)), err)
+		}
+		if len(rel) > 0 {
+			l.leaves[leafName(rel[0].Name)] = leafValues(v)
+			continue
+		}
+		// The config path itself was updated with a JSON container.
+		container, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected value %v at the self config path", v)
+		}
+		l.leaves = map[string][]string{}
+		for name, v := range container {
+			l.leaves[leafName(name)] = leafValues(v)
+		}
+	}
+	return nil
+}
+
+// parseStreamConfig returns defaults overridden by the leaves, with
+// origin set on the paths read from the leaves.
+func parseStreamConfig(defaults streamConfig, leaves map[string][]string,
+	origin string) (streamConfig, error) {
+	var override streamConfig
+	for name, values := range leaves {
+		var err error
+		switch name {
+		case "collector_addr":
+			if len(values) != 1 {
+				return streamConfig{}, fmt.Errorf("expected one collector address, got %q",
+					values)
+			}
+			override.collectorAddr = values[0]
+		case "subscribe":
+			for _, v := range values {
+				if err = override.subTargetDefined.Set(v); err != nil {
+					break
+				}
+			}
+		case "sample":
+			for _, v := range values {
+				if err = override.subSample.Set(v); err != nil {
+					break
+				}
+			}
+		case "get":
+			for _, v := range values {
+				if err = override.getPaths.Set(v); err != nil {
+					break
+				}
+			}
+		case "get_sample_interval":
+			if len(values) != 1 {
+				return streamConfig{}, fmt.Errorf("expected one Get sample interval, got %q",
+					values)
+			}
+			override.getSampleInterval, err = time.ParseDuration(values[0])
+		default:
+			return streamConfig{}, fmt.Errorf("unknown self config leaf %q", name)
+		}
+		if err != nil {
+			return streamConfig{}, fmt.Errorf("invalid self config leaf %q: %s", name, err)
+		}
+	}
+	override.applyOrigin(origin)
+
+	sc := defaults
+	if _, ok := leaves["collector_addr"]; ok {
+		sc.collectorAddr = override.collectorAddr
+	}
+	if _, ok := leaves["subscribe"]; ok {
+		sc.subTargetDefined = override.subTargetDefined
+	}
+	if _, ok := leaves["sample"]; ok {
+		sc.subSample = override.subSample
+	}
+	if _, ok := leaves["get"]; ok {
+		sc.getPaths = override.getPaths
+	}
+	if _, ok := leaves["get_sample_interval"]; ok {
+		sc.getSampleInterval = override.getSampleInterval
+	}
+	return sc, nil
+}
+
+// watchSelfConfig subscribes to the self config path on the target and
+// sends the leaves below it to c once synced, and then on every change.
+func watchSelfConfig(ctx context.Context, cfg *config, targetConn *grpc.ClientConn,
+	p *gnmi.Path, c chan<- map[string][]string) error {
+	if cfg.username != "" {
+		ctx = metadata.NewOutgoingContext(ctx,
+			metadata.Pairs(
+				"username", cfg.username,
+				"password", cfg.password),
+		)
+	}
+	stream, err := gnmi.NewGNMIClient(targetConn).Subscribe(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return fmt.Errorf("error subscribing to self config path %s: %s",
+			gnmilib.StrPath(p), err)
+	}
+	if err := stream.Send(&gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Prefix: &gnmi.Path{Target: cfg.targetVal},
+				Subscription: []*gnmi.Subscription{{
+					Path: p,
+					Mode: gnmi.SubscriptionMode_ON_CHANGE,
+				}},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("error sending SubscribeRequest: %s", err)
+	}
+
+	leaves := newSelfConfigLeaves(p)
+	var synced bool
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("error receiving self config: %s", err)
+		}
+		switch resp := resp.Response.(type) {
+		case *gnmi.SubscribeResponse_Update:
+			if err := leaves.update(resp.Update); err != nil {
+				return err
+			}
+			if !synced {
+				continue
+			}
+		case *gnmi.SubscribeResponse_SyncResponse:
+			synced = true
+		default:
+			continue
+		}
+		snapshot := make(map[string][]string, len(leaves.leaves))
+		for name, values := range leaves.leaves {
+			snapshot[name] = values
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case c <- snapshot:
+		}
+	}
+}
+
+// runSelfConfig streams from the target to the collector with the config
+// read from the self config path p of the target, with the flags as
+// defaults. The streams are restarted whenever the config changes. Dialing
+// the collector is retried with backoff until it succeeds or the config
+// changes.
+func runSelfConfig(cfg *config, getMode string, targetConn *grpc.ClientConn, p *gnmi.Path) {
+	defaults := cfg.streamConfig()
+	leavesChan := make(chan map[string][]string)
+	go streamResponses(context.Background(), cfg.getClock(),
+		func(ctx context.Context, eg *errgroup.Group) {
+			eg.Go(func() error {
+				return watchSelfConfig(ctx, cfg, targetConn, p, leavesChan)
+			})
+		})
+
+	var (
+		current  string
+		destConn *grpc.ClientConn
+		stop     = func() {}
+		leaves   map[string][]string
+		retry    <-chan time.Time
+		bo       = newBackOff(cfg.getClock())
+	)
+	for {
+		select {
+		case leaves = <-leavesChan:
+			retry = nil
+			bo.Reset()
+		case <-retry:
+			retry = nil
+		}
+		sc, err := parseStreamConfig(defaults, leaves, cfg.origin)
+		if err == nil {
+			err = sc.validate(cfg.getSampleJitter)
+		}
+		if err != nil {
+			glog.Errorf("ignoring invalid self config: %s", err)
+			continue
+		}
+		if sc.String() == current {
+			continue
+		}
+		glog.Infof("applying self config: %s", &sc)
+
+		stop()
+		stop = func() {}
+		if destConn != nil && sc.collectorAddr != cfg.collectorAddr {
+			destConn.Close()
+			destConn = nil
+		}
+		cfg.setStreamConfig(sc)
+		cfg.rejectedPathsMu.Lock()
+		cfg.rejectedPaths = nil
+		cfg.rejectedPathsMu.Unlock()
+		current = ""
+		if destConn == nil {
+			if destConn, err = dialCollector(cfg); err != nil {
+				glog.Errorf("error dialing destination %q, retrying: %s", cfg.collectorAddr,
+					err)
+				retry = cfg.getClock().After(bo.NextBackOff())
+				continue
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := startStreams(ctx, cfg, getMode, destConn, targetConn)
+		stop = func() {
+			cancel()
+			<-done
+		}
+		current = sc.String()
+	}
+}
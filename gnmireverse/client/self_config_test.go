@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"testing"
+
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
+	"github.com/aristanetworks/goarista/test"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestSelfConfigLeaves(t *testing.T) {
+	configPath, err := parseSelfConfigPath("eos_native:/Sysdb/gnmireverse/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configPath.Origin != "eos_native" ||
+		gnmilib.StrPath(configPath) != "/Sysdb/gnmireverse/config" {
+		t.Fatalf("unexpected self config path %v", configPath)
+	}
+	mustParse := func(s string) *gnmi.Path {
+		p, err := gnmilib.ParseGNMIElements(gnmilib.SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	l := newSelfConfigLeaves(configPath)
+	for i, tc := range []struct {
+		notif    *gnmi.Notification
+		expected map[string][]string
+	}{{
+		notif: &gnmi.Notification{
+			Prefix: mustParse("/Sysdb/gnmireverse"),
+			Update: []*gnmi.Update{{
+				Path: mustParse("config/collector-addr"),
+				Val:  gnmilib.TypedValue("mgmt/10.0.0.1:6000"),
+			}, {
+				Path: mustParse("config/subscribe"),
+				Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{
+					LeaflistVal: &gnmi.ScalarArray{Element: []*gnmi.TypedValue{
+						gnmilib.TypedValue("/interfaces"), gnmilib.TypedValue("/system@1m"),
+					}}}},
+			}, {
+				Path: mustParse("other/subscribe"),
+				Val:  gnmilib.TypedValue("/ignored"),
+			}},
+		},
+		expected: map[string][]string{
+			"collector_addr": {"mgmt/10.0.0.1:6000"},
+			"subscribe":      {"/interfaces", "/system@1m"},
+		},
+	}, {
+		notif: &gnmi.Notification{
+			Delete: []*gnmi.Path{mustParse("/Sysdb/gnmireverse/config/subscribe")},
+			Update: []*gnmi.Update{{
+				Path: mustParse("/Sysdb/gnmireverse/config/get"),
+				Val:  gnmilib.TypedValue("/a\n/b"),
+			}},
+		},
+		expected: map[string][]string{
+			"collector_addr": {"mgmt/10.0.0.1:6000"},
+			"get":            {"/a", "/b"},
+		},
+	}, {
+		notif: &gnmi.Notification{
+			Update: []*gnmi.Update{{
+				Path: mustParse("/Sysdb/gnmireverse/config"),
+				Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(
+					`{"arista:sample": ["/counters@30s"], "collector-addr": "c:6000"}`)}},
+			}},
+		},
+		expected: map[string][]string{
+			"collector_addr": {"c:6000"},
+			"sample":         {"/counters@30s"},
+		},
+	}, {
+		notif: &gnmi.Notification{
+			Delete: []*gnmi.Path{mustParse("/Sysdb")},
+		},
+		expected: map[string][]string{},
+	}} {
+		if err := l.update(tc.notif); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if !test.DeepEqual(tc.expected, l.leaves) {
+			t.Errorf("%d: unexpected leaves: %s", i, test.Diff(tc.expected, l.leaves))
+		}
+	}
+}
+
+func TestParseStreamConfig(t *testing.T) {
+	var defaults streamConfig
+	defaults.collectorAddr = "collector:6000"
+	if err := defaults.subTargetDefined.Set("/interfaces"); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := parseStreamConfig(defaults, map[string][]string{
+		"sample":              {"/counters@30s", "/system@1m"},
+		"get":                 {"/a", "eos_native:/Sysdb/b"},
+		"get_sample_interval": {"10s"},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.validate(0); err != nil {
+		t.Fatal(err)
+	}
+	expected := "collector collector:6000, subscribe [/interfaces], " +
+		"sample [/counters@30s, /system@1m0s], get [/a, /Sysdb/b] every 10s"
+	if sc.String() != expected {
+		t.Errorf("expected %q, got %q", expected, sc.String())
+	}
+	if sc.getPaths.eosNativePaths[0].Origin != "eos_native" {
+		t.Errorf("unexpected Get paths %v", sc.getPaths.eosNativePaths)
+	}
+
+	sc, err = parseStreamConfig(defaults, map[string][]string{
+		"collector_addr": {"other:6000"},
+		"subscribe":      {"/system"},
+	}, "openconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.collectorAddr != "other:6000" || len(sc.subTargetDefined.subs) != 1 ||
+		sc.subTargetDefined.subs[0].p.Origin != "openconfig" {
+		t.Errorf("unexpected config %s", &sc)
+	}
+	if defaults.subTargetDefined.subs[0].p.Origin != "" {
+		t.Error("the defaults were modified")
+	}
+
+	for _, leaves := range []map[string][]string{
+		{"unknown": {"x"}},
+		{"sample": {"/counters"}},
+		{"collector_addr": {"a:1", "b:2"}},
+		{"get_sample_interval": {"often"}},
+	} {
+		if _, err := parseStreamConfig(defaults, leaves, ""); err == nil {
+			t.Errorf("expected an error for %v", leaves)
+		}
+	}
+	sc, err = parseStreamConfig(defaults, map[string][]string{"get": {"/a"}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.validate(0); err == nil {
+		t.Error("expected an error for Get paths without a sample interval")
+	}
+	sc, err = parseStreamConfig(defaults, map[string][]string{"collector_addr": {}}, "")
+	if err == nil {
+		t.Errorf("expected an error for an empty collector address, got %s", &sc)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// subscribeError is a Subscribe failure with the details of the gRPC
+// status returned by the target.
+type subscribeError struct {
+	op   string
+	code codes.Code
+	msg  string
+	// path is the subscription path rejected by the target, if the
+	// details of the status name one.
+	path string
+	// permanent is true if retrying the same request cannot succeed until
+	// the target or its config changes.
+	permanent bool
+}
+
+func (e *subscribeError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "error from %s: code %s: %s", e.op, e.code, e.msg)
+	if e.path != "" {
+		fmt.Fprintf(&b, " (path %s)", e.path)
+	}
+	if e.permanent {
+		b.WriteString(" [permanent]")
+	} else {
+		b.WriteString(" [transient]")
+	}
+	return b.String()
+}
+
+// permanentCodes are the status codes with which the target rejects the
+// request itself: sending it again fails the same way.
+var permanentCodes = map[codes.Code]bool{
+	codes.InvalidArgument:  true,
+	codes.NotFound:         true,
+	codes.PermissionDenied: true,
+	codes.Unimplemented:    true,
+	codes.OutOfRange:       true,
+}
+
+// newSubscribeError classifies the error err returned by op, looking for
+// the rejected path among paths in the details of its status: the field of
+// a BadRequest violation or the resource of a ResourceInfo.
+func newSubscribeError(op string, err error, paths []string) *subscribeError {
+	st := status.Convert(err)
+	e := &subscribeError{
+		op:        op,
+		code:      st.Code(),
+		msg:       st.Message(),
+		permanent: permanentCodes[st.Code()],
+	}
+	var named []string
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				named = append(named, v.GetField())
+			}
+		case *errdetails.ResourceInfo:
+			named = append(named, d.GetResourceName())
+		}
+	}
+	for _, n := range named {
+		for _, p := range paths {
+			if n == p {
+				e.path = p
+				return e
+			}
+		}
+	}
+	return e
+}
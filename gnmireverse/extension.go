@@ -0,0 +1,49 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmireverse
+
+import (
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// The extensions of gNMIReverse share the experimental registered
+// extension ID, as other experimental extensions may. Their message starts
+// with a magic string telling them apart, followed by their fields.
+const (
+	// ChunkExtensionMagic starts the message of the chunk extensions.
+	ChunkExtensionMagic = "gnmireverse.chunk\x00"
+	// MetadataExtensionMagic starts the message of the metadata
+	// extensions.
+	MetadataExtensionMagic = "gnmireverse.metadata\x00"
+)
+
+func registeredExtension(id gnmi_ext.ExtensionID, magic string,
+	msg []byte) *gnmi_ext.Extension {
+	return &gnmi_ext.Extension{
+		Ext: &gnmi_ext.Extension_RegisteredExt{
+			RegisteredExt: &gnmi_ext.RegisteredExtension{
+				Id:  id,
+				Msg: append([]byte(magic), msg...),
+			},
+		},
+	}
+}
+
+// registeredExtensionMsg returns the fields of the message of ext and true
+// if ext is the registered extension id with the given magic.
+func registeredExtensionMsg(ext *gnmi_ext.Extension, id gnmi_ext.ExtensionID,
+	magic string) ([]byte, bool) {
+	reg := ext.GetRegisteredExt()
+	if reg == nil || reg.GetId() != id {
+		return nil, false
+	}
+	msg, ok := strings.CutPrefix(string(reg.GetMsg()), magic)
+	if !ok {
+		return nil, false
+	}
+	return []byte(msg), true
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmireverse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MetadataExtensionID is the ID of the registered extension of the
+// handshake message a client can send first on its Publish and PublishGet
+// streams, a SubscribeResponse without response or a GetResponse without
+// notifications. The message of the extension is MetadataExtensionMagic
+// followed by the Metadata of the client: the agent version (field 1) and
+// the target (field 2) as strings, and the collected paths (field 3), each
+// a message with the path (field 1) and the mode (field 2) as strings and
+// the interval in nanoseconds (field 3) as a varint.
+const MetadataExtensionID = gnmi_ext.ExtensionID_EID_EXPERIMENTAL
+
+// Metadata describes what a client collects, for the server to log or
+// expose it.
+type Metadata struct {
+	// AgentVersion is the version of the client.
+	AgentVersion string
+	// Target is the target of the notifications sent by the client.
+	Target string
+	// Paths are the paths collected by the client.
+	Paths []CollectedPath
+}
+
+// CollectedPath is a path collected by a client.
+type CollectedPath struct {
+	// Path is the path, prefixed by its origin if any, e.g.
+	// eos_native:/Kernel/sysinfo.
+	Path string
+	// Mode is how the path is collected, e.g. target_defined, sample or get.
+	Mode string
+	// Interval is the heartbeat, sample or Get interval, if any.
+	Interval time.Duration
+}
+
+func (p CollectedPath) String() string {
+	s := p.Mode + ":" + p.Path
+	if p.Interval > 0 {
+		s += "@" + p.Interval.String()
+	}
+	return s
+}
+
+func (m *Metadata) String() string {
+	paths := make([]string, len(m.Paths))
+	for i, p := range m.Paths {
+		paths[i] = p.String()
+	}
+	return fmt.Sprintf("agent_version=%s target=%s paths=[%s]", m.AgentVersion, m.Target,
+		strings.Join(paths, ", "))
+}
+
+func (m *Metadata) extension() *gnmi_ext.Extension {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, m.AgentVersion)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, m.Target)
+	for _, p := range m.Paths {
+		var pb []byte
+		pb = protowire.AppendTag(pb, 1, protowire.BytesType)
+		pb = protowire.AppendString(pb, p.Path)
+		pb = protowire.AppendTag(pb, 2, protowire.BytesType)
+		pb = protowire.AppendString(pb, p.Mode)
+		pb = protowire.AppendTag(pb, 3, protowire.VarintType)
+		pb = protowire.AppendVarint(pb, uint64(p.Interval))
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, pb)
+	}
+	return registeredExtension(MetadataExtensionID, MetadataExtensionMagic, b)
+}
+
+// SubscribeResponse returns the handshake message of m for a Publish
+// stream.
+func (m *Metadata) SubscribeResponse() *gnmi.SubscribeResponse {
+	return &gnmi.SubscribeResponse{Extension: []*gnmi_ext.Extension{m.extension()}}
+}
+
+// GetResponse returns the handshake message of m for a PublishGet stream.
+func (m *Metadata) GetResponse() *gnmi.GetResponse {
+	return &gnmi.GetResponse{Extension: []*gnmi_ext.Extension{m.extension()}}
+}
+
+// SubscribeResponseMetadata returns the Metadata of resp and true if it
+// is a handshake message.
+func SubscribeResponseMetadata(resp *gnmi.SubscribeResponse) (*Metadata, bool, error) {
+	if resp.GetResponse() != nil {
+		return nil, false, nil
+	}
+	return extensionMetadata(resp.GetExtension())
+}
+
+// GetResponseMetadata returns the Metadata of resp and true if it is a
+// handshake message.
+func GetResponseMetadata(resp *gnmi.GetResponse) (*Metadata, bool, error) {
+	if len(resp.GetNotification()) != 0 {
+		return nil, false, nil
+	}
+	return extensionMetadata(resp.GetExtension())
+}
+
+var errMalformedMetadata = errors.New("malformed metadata extension")
+
+func extensionMetadata(exts []*gnmi_ext.Extension) (*Metadata, bool, error) {
+	for _, ext := range exts {
+		msg, ok := registeredExtensionMsg(ext, MetadataExtensionID, MetadataExtensionMagic)
+		if !ok {
+			continue
+		}
+		m := &Metadata{}
+		err := consumeFields(msg, func(num protowire.Number, b []byte) error {
+			switch num {
+			case 1:
+				m.AgentVersion = string(b)
+			case 2:
+				m.Target = string(b)
+			case 3:
+				p, err := collectedPath(b)
+				if err != nil {
+					return err
+				}
+				m.Paths = append(m.Paths, p)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		return m, true, nil
+	}
+	return nil, false, nil
+}
+
+func collectedPath(b []byte) (CollectedPath, error) {
+	var p CollectedPath
+	err := consumeFields(b, func(num protowire.Number, b []byte) error {
+		switch num {
+		case 1:
+			p.Path = string(b)
+		case 2:
+			p.Mode = string(b)
+		}
+		return nil
+	}, func(num protowire.Number, v uint64) {
+		if num == 3 {
+			p.Interval = time.Duration(v)
+		}
+	})
+	return p, err
+}
+
+// consumeFields calls bytesField with the length-delimited fields of the
+// protobuf message b and varintField, if not nil, with its varint fields.
+func consumeFields(b []byte, bytesField func(protowire.Number, []byte) error,
+	varintField func(protowire.Number, uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errMalformedMetadata
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return errMalformedMetadata
+			}
+			if err := bytesField(num, v); err != nil {
+				return err
+			}
+			b = b[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return errMalformedMetadata
+			}
+			if varintField != nil {
+				varintField(num, v)
+			}
+			b = b[n:]
+		default:
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return errMalformedMetadata
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
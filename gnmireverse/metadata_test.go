@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package gnmireverse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/test"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMetadata(t *testing.T) {
+	md := &Metadata{
+		AgentVersion: "v1.2.3",
+		Target:       "dut",
+		Paths: []CollectedPath{
+			{Path: "/system", Mode: "target_defined"},
+			{Path: "/interfaces/interface/state/counters", Mode: "sample",
+				Interval: 30 * time.Second},
+			{Path: "eos_native:/Kernel/sysinfo", Mode: "get", Interval: time.Minute},
+		},
+	}
+	expected := "agent_version=v1.2.3 target=dut paths=[target_defined:/system, " +
+		"sample:/interfaces/interface/state/counters@30s, get:eos_native:/Kernel/sysinfo@1m0s]"
+	if s := md.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	// The handshake messages go through the wire.
+	subResp := &gnmi.SubscribeResponse{}
+	b, err := proto.Marshal(md.SubscribeResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(b, subResp); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := SubscribeResponseMetadata(subResp); err != nil || !ok {
+		t.Errorf("unexpected result: %v, %t, %v", got, ok, err)
+	} else if !test.DeepEqual(md, got) {
+		t.Errorf("expected %v, got %v", md, got)
+	}
+	if got, ok, err := GetResponseMetadata(md.GetResponse()); err != nil || !ok {
+		t.Errorf("unexpected result: %v, %t, %v", got, ok, err)
+	} else if !test.DeepEqual(md, got) {
+		t.Errorf("expected %v, got %v", md, got)
+	}
+
+	// Messages with a response are not handshakes, even with the extension.
+	update := &gnmi.SubscribeResponse{
+		Response:  &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true},
+		Extension: md.SubscribeResponse().Extension,
+	}
+	if _, ok, err := SubscribeResponseMetadata(update); ok || err != nil {
+		t.Errorf("unexpected handshake: %t, %v", ok, err)
+	}
+	chunk := &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{{}},
+		Extension:    []*gnmi_ext.Extension{chunkExtension(0, 2)},
+	}
+	if _, ok, err := GetResponseMetadata(chunk); ok || err != nil {
+		t.Errorf("unexpected handshake: %t, %v", ok, err)
+	}
+
+	// The other extensions sharing the ID are not metadata.
+	other := &gnmi.SubscribeResponse{Extension: []*gnmi_ext.Extension{
+		{Ext: &gnmi_ext.Extension_RegisteredExt{RegisteredExt: &gnmi_ext.RegisteredExtension{
+			Id: MetadataExtensionID, Msg: []byte("reconnect")}}},
+		chunkExtension(0, 2),
+	}}
+	if _, ok, err := SubscribeResponseMetadata(other); ok || err != nil {
+		t.Errorf("unexpected handshake: %t, %v", ok, err)
+	}
+
+	malformed := &gnmi.SubscribeResponse{Extension: []*gnmi_ext.Extension{
+		registeredExtension(MetadataExtensionID, MetadataExtensionMagic,
+			[]byte{0x0a, 0x05, 'a'})}}
+	if _, _, err := SubscribeResponseMetadata(malformed); err == nil {
+		t.Error("expected an error decoding a malformed extension")
+	}
+}
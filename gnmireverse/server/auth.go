@@ -0,0 +1,224 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"expvar"
+	"path"
+	"strings"
+
+	"github.com/aristanetworks/glog"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// TargetVerdict is the result of checking the target of a notification.
+type TargetVerdict int
+
+const (
+	// TargetAllow accepts the notification.
+	TargetAllow TargetVerdict = iota
+	// TargetTag accepts the notification but tags the stream: the
+	// target of the notifications of a tagged stream is prefixed with
+	// TaggedTargetPrefix.
+	TargetTag
+	// TargetReject ends the stream with a PermissionDenied error.
+	TargetReject
+)
+
+// TaggedTargetPrefix prefixes the target of the notifications of tagged
+// streams.
+const TaggedTargetPrefix = "untrusted:"
+
+// Interceptor lets the application embedding the server authenticate the
+// callers of Publish and PublishGet and check the targets of the
+// notifications they send.
+type Interceptor interface {
+	// Authenticate is called when a stream starts, with the stream
+	// context carrying the peer and the gRPC metadata of the caller.
+	// Returning an error rejects the stream.
+	Authenticate(ctx context.Context) error
+	// CheckTarget is called with the target of the prefix of the
+	// notifications received on the stream.
+	CheckTarget(ctx context.Context, target string) TargetVerdict
+}
+
+// Counters of the streams rejected or tagged by the Interceptor.
+var (
+	rejectedAuthStreams   = new(expvar.Int)
+	rejectedTargetStreams = new(expvar.Int)
+	taggedStreams         = new(expvar.Int)
+)
+
+func init() {
+	m := expvar.NewMap("gnmireverseServerStreams")
+	m.Set("rejectedAuth", rejectedAuthStreams)
+	m.Set("rejectedTarget", rejectedTargetStreams)
+	m.Set("tagged", taggedStreams)
+}
+
+// AllowList is an Interceptor accepting the callers and targets in its
+// lists. Empty lists accept everything.
+type AllowList struct {
+	// CommonNames are the accepted common names of the verified client
+	// certificates.
+	CommonNames []string
+	// Tokens are the accepted tokens, sent in the TokenKey gRPC
+	// metadata, optionally prefixed with "Bearer ".
+	Tokens []string
+	// TokenKey defaults to "authorization".
+	TokenKey string
+	// Targets are the accepted notification targets, as path.Match
+	// patterns. Notifications without target only match the "" pattern.
+	Targets []string
+	// If TagTargets is true, streams with notifications for targets
+	// not in Targets are tagged instead of rejected.
+	TagTargets bool
+}
+
+// Authenticate implements Interceptor.
+func (a *AllowList) Authenticate(ctx context.Context) error {
+	if len(a.CommonNames) > 0 {
+		cn := clientCommonName(ctx)
+		if cn == "" {
+			return status.Error(codes.Unauthenticated, "no verified client certificate")
+		}
+		if !contains(a.CommonNames, cn) {
+			return status.Errorf(codes.PermissionDenied,
+				"client certificate common name %q not allowed", cn)
+		}
+	}
+	if len(a.Tokens) > 0 {
+		key := a.TokenKey
+		if key == "" {
+			key = "authorization"
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		tokens := md.Get(key)
+		if len(tokens) == 0 {
+			return status.Errorf(codes.Unauthenticated, "missing %s metadata", key)
+		}
+		token := strings.TrimPrefix(tokens[0], "Bearer ")
+		var ok bool
+		for _, t := range a.Tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				ok = true
+			}
+		}
+		if !ok {
+			return status.Error(codes.PermissionDenied, "invalid token")
+		}
+	}
+	return nil
+}
+
+// CheckTarget implements Interceptor.
+func (a *AllowList) CheckTarget(ctx context.Context, target string) TargetVerdict {
+	if len(a.Targets) == 0 {
+		return TargetAllow
+	}
+	for _, pattern := range a.Targets {
+		if ok, _ := path.Match(pattern, target); ok {
+			return TargetAllow
+		}
+	}
+	if a.TagTargets {
+		return TargetTag
+	}
+	return TargetReject
+}
+
+func clientCommonName(ctx context.Context) string {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := pr.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 ||
+		len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// streamGuard applies an Interceptor to a stream.
+type streamGuard struct {
+	ctx         context.Context
+	interceptor Interceptor
+	clientAddr  string
+	tagged      bool
+	// verdicts caches the verdicts of the targets already checked.
+	verdicts map[string]TargetVerdict
+}
+
+// newStreamGuard authenticates the caller of the stream. A nil interceptor
+// accepts all streams.
+func newStreamGuard(ctx context.Context, interceptor Interceptor) (*streamGuard, error) {
+	g := &streamGuard{
+		ctx:         ctx,
+		interceptor: interceptor,
+		verdicts:    map[string]TargetVerdict{},
+	}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		g.clientAddr = pr.Addr.String()
+	}
+	if interceptor == nil {
+		return g, nil
+	}
+	if err := interceptor.Authenticate(ctx); err != nil {
+		rejectedAuthStreams.Add(1)
+		glog.Warningf("Rejected stream from %s: %s", g.clientAddr, err)
+		return nil, err
+	}
+	return g, nil
+}
+
+// checkNotification checks the target of notif, tagging it if the stream
+// is tagged. It returns an error if the stream must be rejected.
+func (g *streamGuard) checkNotification(notif *gnmi.Notification) error {
+	if g.interceptor == nil || notif == nil {
+		return nil
+	}
+	target := notif.GetPrefix().GetTarget()
+	verdict, ok := g.verdicts[target]
+	if !ok {
+		verdict = g.interceptor.CheckTarget(g.ctx, target)
+		g.verdicts[target] = verdict
+	}
+	switch verdict {
+	case TargetReject:
+		rejectedTargetStreams.Add(1)
+		glog.Warningf("Rejected stream from %s: target %q not allowed", g.clientAddr, target)
+		return status.Errorf(codes.PermissionDenied, "target %q not allowed", target)
+	case TargetTag:
+		if !g.tagged {
+			g.tagged = true
+			taggedStreams.Add(1)
+			glog.Warningf("Tagged stream from %s: target %q not allowed", g.clientAddr, target)
+		}
+	}
+	if g.tagged {
+		if notif.Prefix == nil {
+			notif.Prefix = &gnmi.Path{}
+		}
+		notif.Prefix.Target = TaggedTargetPrefix + notif.Prefix.Target
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(cn string) context.Context {
+	var state tls.ConnectionState
+	if cn != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: state},
+	})
+}
+
+func TestAllowListAuthenticate(t *testing.T) {
+	a := &AllowList{CommonNames: []string{"dut1", "dut2"}, Tokens: []string{"secret"}}
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		code codes.Code
+	}{
+		"allowed": {
+			ctx: metadata.NewIncomingContext(peerContext("dut2"),
+				metadata.Pairs("authorization", "Bearer secret")),
+			code: codes.OK,
+		},
+		"no certificate": {
+			ctx: metadata.NewIncomingContext(peerContext(""),
+				metadata.Pairs("authorization", "secret")),
+			code: codes.Unauthenticated,
+		},
+		"unknown common name": {
+			ctx: metadata.NewIncomingContext(peerContext("dut3"),
+				metadata.Pairs("authorization", "secret")),
+			code: codes.PermissionDenied,
+		},
+		"no token": {
+			ctx:  peerContext("dut1"),
+			code: codes.Unauthenticated,
+		},
+		"invalid token": {
+			ctx: metadata.NewIncomingContext(peerContext("dut1"),
+				metadata.Pairs("authorization", "guess")),
+			code: codes.PermissionDenied,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if code := status.Code(a.Authenticate(tc.ctx)); code != tc.code {
+				t.Errorf("expected code %s, got %s", tc.code, code)
+			}
+		})
+	}
+}
+
+func TestStreamGuard(t *testing.T) {
+	notif := func(target string) *gnmi.Notification {
+		return &gnmi.Notification{Prefix: &gnmi.Path{Target: target}}
+	}
+
+	// Rejected targets
+	rejected := rejectedTargetStreams.Value()
+	g, err := newStreamGuard(context.Background(), &AllowList{Targets: []string{"dc1-*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := notif("dc1-leaf1")
+	if err := g.checkNotification(n); err != nil {
+		t.Fatal(err)
+	}
+	if n.Prefix.Target != "dc1-leaf1" {
+		t.Errorf("unexpected target %q", n.Prefix.Target)
+	}
+	err = g.checkNotification(notif("dc2-leaf1"))
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", err)
+	}
+	if v := rejectedTargetStreams.Value(); v != rejected+1 {
+		t.Errorf("expected %d rejected streams, got %d", rejected+1, v)
+	}
+
+	// Tagged targets
+	tagged := taggedStreams.Value()
+	g, err = newStreamGuard(context.Background(),
+		&AllowList{Targets: []string{"dc1-*"}, TagTargets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ target, exp string }{
+		{"dc1-leaf1", "dc1-leaf1"},
+		{"dc2-leaf1", TaggedTargetPrefix + "dc2-leaf1"},
+		// Once tagged, all the notifications of the stream are tagged.
+		{"dc1-leaf1", TaggedTargetPrefix + "dc1-leaf1"},
+	} {
+		n := notif(tc.target)
+		if err := g.checkNotification(n); err != nil {
+			t.Fatal(err)
+		}
+		if n.Prefix.Target != tc.exp {
+			t.Errorf("expected target %q, got %q", tc.exp, n.Prefix.Target)
+		}
+	}
+	if v := taggedStreams.Value(); v != tagged+1 {
+		t.Errorf("expected %d tagged streams, got %d", tagged+1, v)
+	}
+
+	// Rejected callers
+	rejected = rejectedAuthStreams.Value()
+	if _, err := newStreamGuard(peerContext("dut1"),
+		&AllowList{CommonNames: []string{"dut2"}}); err == nil {
+		t.Error("expected an error")
+	}
+	if v := rejectedAuthStreams.Value(); v != rejected+1 {
+		t.Errorf("expected %d rejected streams, got %d", rejected+1, v)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aristanetworks/glog"
+)
+
+// certReloader serves the TLS certificate and client CAs of the server
+// from disk, so that they can be rotated without restarting the server
+// and dropping the open streams. The certificate is reloaded on the next
+// handshake after its files change, and the client CAs when reload is
+// called, e.g. on SIGHUP. Both only apply to the new connections.
+type certReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	clientAuth   tls.ClientAuthType
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certStamp fileStamp
+	keyStamp  fileStamp
+
+	clientCAs atomic.Pointer[x509.CertPool]
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(name string) (fileStamp, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+func newCertReloader(clientCertAuth bool, certFile, keyFile,
+	clientCAFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if clientCertAuth {
+		if clientCAFile == "" {
+			return nil, fmt.Errorf("client_cert_auth enable, client_cafile must also be set")
+		}
+		r.clientCAFile = clientCAFile
+		r.clientAuth = tls.RequireAndVerifyClientCert
+		if err := r.reloadClientCAs(); err != nil {
+			return nil, err
+		}
+	}
+	if certFile != "" {
+		if keyFile == "" {
+			return nil, fmt.Errorf("please provide both -certfile and -keyfile")
+		}
+		if _, err := r.loadCertificate(true); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// tlsConfig returns the TLS configuration of the server, which gets the
+// current certificate and client CAs of r for every handshake.
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{GetConfigForClient: r.getConfigForClient}
+}
+
+func (r *certReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	config := &tls.Config{
+		ClientAuth: r.clientAuth,
+		ClientCAs:  r.clientCAs.Load(),
+		// The configuration returned replaces the one gRPC sets up, so it
+		// must negotiate HTTP/2 itself.
+		NextProtos: []string{"h2"},
+	}
+	if r.certFile != "" {
+		config.GetCertificate = r.getCertificate
+	}
+	return config, nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.loadCertificate(false)
+}
+
+// loadCertificate returns the certificate of the server, after reloading
+// it if its files changed since it was loaded, or if force is true. If the
+// reload fails, e.g. because the key was not yet rotated along with the
+// certificate, the previous certificate is kept and the reload is retried
+// on the next handshake.
+func (r *certReloader) loadCertificate(force bool) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	certStamp, err := statFile(r.certFile)
+	if err == nil {
+		var keyStamp fileStamp
+		if keyStamp, err = statFile(r.keyFile); err == nil {
+			if !force && r.cert != nil && certStamp == r.certStamp && keyStamp == r.keyStamp {
+				return r.cert, nil
+			}
+			var cert tls.Certificate
+			if cert, err = tls.LoadX509KeyPair(r.certFile, r.keyFile); err == nil {
+				if r.cert != nil {
+					glog.Infof("Reloaded TLS certificate %s", r.certFile)
+				}
+				r.cert, r.certStamp, r.keyStamp = &cert, certStamp, keyStamp
+				return r.cert, nil
+			}
+		}
+	}
+	if r.cert == nil || force {
+		return nil, err
+	}
+	glog.Errorf("Failed to reload TLS certificate %s, keeping the previous one: %s",
+		r.certFile, err)
+	return r.cert, nil
+}
+
+func (r *certReloader) reloadClientCAs() error {
+	b, err := os.ReadFile(r.clientCAFile)
+	if err != nil {
+		return err
+	}
+	cp := x509.NewCertPool()
+	if !cp.AppendCertsFromPEM(b) {
+		return fmt.Errorf("credentials: failed to append certificates")
+	}
+	r.clientCAs.Store(cp)
+	return nil
+}
+
+// reload reloads the certificate and the client CAs from their files. The
+// ones that fail to load are kept.
+func (r *certReloader) reload() error {
+	var errs []error
+	if r.certFile != "" {
+		if _, err := r.loadCertificate(true); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reload TLS certificate %s: %s",
+				r.certFile, err))
+		}
+	}
+	if r.clientCAFile != "" {
+		if err := r.reloadClientCAs(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reload client CAs %s: %s",
+				r.clientCAFile, err))
+		}
+	}
+	return errors.Join(errs...)
+}
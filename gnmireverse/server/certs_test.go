@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate with common name cn and its
+// key to certFile and keyFile, and returns the certificate in DER.
+func writeCert(t *testing.T, cn, certFile, keyFile string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile != "" {
+		if err := os.WriteFile(keyFile, pem.EncodeToMemory(
+			&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return der
+}
+
+// touch sets the modification time of the files to a time later than the
+// previous one, as writes within the granularity of the filesystem may not
+// change it.
+func touch(t *testing.T, mtime time.Time, files ...string) {
+	for _, f := range files {
+		if err := os.Chtimes(f, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	caFile := filepath.Join(dir, "ca.pem")
+	der1 := writeCert(t, "collector1", certFile, keyFile)
+	ca1 := writeCert(t, "ca1", caFile, "")
+	mtime := time.Now()
+	touch(t, mtime, certFile, keyFile)
+
+	r, err := newCertReloader(true, certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := r.tlsConfig().GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := config.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cert.Certificate[0]) != string(der1) {
+		t.Error("expected the initial certificate")
+	}
+	ca, err := x509.ParseCertificate(ca1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := x509.NewCertPool()
+	expected.AddCert(ca)
+	if !config.ClientCAs.Equal(expected) {
+		t.Error("expected the initial client CAs")
+	}
+
+	// A certificate rotated without its key is not loaded yet.
+	writeCert(t, "collector2", certFile, "")
+	mtime = mtime.Add(time.Second)
+	touch(t, mtime, certFile)
+	if cert, err := r.getCertificate(nil); err != nil {
+		t.Fatal(err)
+	} else if string(cert.Certificate[0]) != string(der1) {
+		t.Error("expected the previous certificate while the key does not match")
+	}
+	der2 := writeCert(t, "collector2", certFile, keyFile)
+	mtime = mtime.Add(time.Second)
+	touch(t, mtime, certFile, keyFile)
+	if cert, err := r.getCertificate(nil); err != nil {
+		t.Fatal(err)
+	} else if string(cert.Certificate[0]) != string(der2) {
+		t.Error("expected the rotated certificate")
+	}
+
+	// The client CAs are only reloaded by reload.
+	ca2 := writeCert(t, "ca2", caFile, "")
+	if config, _ := r.getConfigForClient(nil); !config.ClientCAs.Equal(expected) {
+		t.Error("unexpected reload of the client CAs")
+	}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if ca, err = x509.ParseCertificate(ca2); err != nil {
+		t.Fatal(err)
+	}
+	expected = x509.NewCertPool()
+	expected.AddCert(ca)
+	if config, _ := r.getConfigForClient(nil); !config.ClientCAs.Equal(expected) {
+		t.Error("expected the reloaded client CAs")
+	}
+
+	// The credentials failing to reload are kept.
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err == nil {
+		t.Error("expected an error reloading invalid credentials")
+	}
+	config, _ = r.getConfigForClient(nil)
+	if !config.ClientCAs.Equal(expected) {
+		t.Error("expected the previous client CAs")
+	}
+	if cert, err := config.GetCertificate(nil); err != nil {
+		t.Fatal(err)
+	} else if string(cert.Certificate[0]) != string(der2) {
+		t.Error("expected the previous certificate")
+	}
+
+	if _, err := newCertReloader(false, certFile, "", ""); err == nil {
+		t.Error("expected an error without key file")
+	}
+	if _, err := newCertReloader(true, "", "", ""); err == nil {
+		t.Error("expected an error without client CA file")
+	}
+}
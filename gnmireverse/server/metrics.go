@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"time"
+
+	"github.com/aristanetworks/goarista/gnmireverse"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	rpcPublish    = "Publish"
+	rpcPublishGet = "PublishGet"
+)
+
+// Metrics are the Prometheus metrics of the streams received by a
+// gNMIReverse server. A nil *Metrics records nothing.
+type Metrics struct {
+	streams       *prometheus.CounterVec
+	activeStreams *prometheus.GaugeVec
+	receivedBytes *prometheus.CounterVec
+	decodeErrors  *prometheus.CounterVec
+	notifications *prometheus.CounterVec
+	lastReceived  *prometheus.GaugeVec
+	clients       *prometheus.GaugeVec
+}
+
+// NewMetrics returns Metrics registered with reg.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		streams: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gnmireverse_server_streams_total",
+			Help: "Number of streams opened by clients.",
+		}, []string{"rpc"}),
+		activeStreams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gnmireverse_server_active_streams",
+			Help: "Number of streams currently open.",
+		}, []string{"rpc"}),
+		receivedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gnmireverse_server_received_bytes_total",
+			Help: "Number of bytes of the messages received.",
+		}, []string{"rpc"}),
+		decodeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gnmireverse_server_decode_errors_total",
+			Help: "Number of messages that could not be decoded or reassembled.",
+		}, []string{"rpc"}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gnmireverse_server_notifications_total",
+			Help: "Number of notifications received per target.",
+		}, []string{"target"}),
+		lastReceived: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gnmireverse_server_last_received_timestamp_seconds",
+			Help: "Time the last notification of the target was received.",
+		}, []string{"target"}),
+		clients: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gnmireverse_server_client_paths",
+			Help: "Number of paths collected by the clients of the open streams that " +
+				"sent their metadata.",
+		}, []string{"rpc", "target", "agent_version"}),
+	}
+	for _, c := range []prometheus.Collector{m.streams, m.activeStreams, m.receivedBytes,
+		m.decodeErrors, m.notifications, m.lastReceived, m.clients} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// streamStarted records a new stream of rpc and returns the function to
+// call when it ends.
+func (m *Metrics) streamStarted(rpc string) func() {
+	if m == nil {
+		return func() {}
+	}
+	m.streams.WithLabelValues(rpc).Inc()
+	active := m.activeStreams.WithLabelValues(rpc)
+	active.Inc()
+	return active.Dec
+}
+
+// received records a message of rpc.
+func (m *Metrics) received(rpc string, msg proto.Message) {
+	if m == nil {
+		return
+	}
+	m.receivedBytes.WithLabelValues(rpc).Add(float64(proto.Size(msg)))
+}
+
+// recvError records the error returned by the Recv of a stream of rpc,
+// counting the messages that failed to be unmarshaled.
+func (m *Metrics) recvError(rpc string, err error) {
+	if m == nil || status.Code(err) != codes.Internal {
+		return
+	}
+	m.decodeErrors.WithLabelValues(rpc).Inc()
+}
+
+// decodeError records a message of rpc that could not be decoded.
+func (m *Metrics) decodeError(rpc string) {
+	if m == nil {
+		return
+	}
+	m.decodeErrors.WithLabelValues(rpc).Inc()
+}
+
+// clientMetadata records the metadata md sent on a stream of rpc and
+// returns the function to call when the stream ends.
+func (m *Metrics) clientMetadata(rpc string, md *gnmireverse.Metadata) func() {
+	if m == nil {
+		return func() {}
+	}
+	clients := m.clients.WithLabelValues(rpc, md.Target, md.AgentVersion)
+	clients.Add(float64(len(md.Paths)))
+	return func() { clients.Sub(float64(len(md.Paths))) }
+}
+
+// notification records notif, received at t.
+func (m *Metrics) notification(notif *gnmi.Notification, t time.Time) {
+	if m == nil || notif == nil {
+		return
+	}
+	target := notif.GetPrefix().GetTarget()
+	m.notifications.WithLabelValues(target).Inc()
+	m.lastReceived.WithLabelValues(target).Set(float64(t.UnixNano()) / 1e9)
+}
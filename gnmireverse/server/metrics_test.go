@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/gnmireverse"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakePublishStream struct {
+	grpc.ServerStream
+	resps []*gnmi.SubscribeResponse
+	err   error
+}
+
+func (s *fakePublishStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakePublishStream) Recv() (*gnmi.SubscribeResponse, error) {
+	if len(s.resps) == 0 {
+		return nil, s.err
+	}
+	resp := s.resps[0]
+	s.resps = s.resps[1:]
+	return resp, nil
+}
+
+func (s *fakePublishStream) SendAndClose(*emptypb.Empty) error {
+	return nil
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := func(target string) *gnmi.SubscribeResponse {
+		return &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{Prefix: &gnmi.Path{Target: target}}}}
+	}
+	resps := []*gnmi.SubscribeResponse{update("dut1"), update("dut2"), update("dut1")}
+	var size int
+	for _, resp := range resps {
+		size += proto.Size(resp)
+	}
+	s := NewServerWithMetrics(debugSilent, nil, m)
+	err = s.Publish(&fakePublishStream{resps: resps,
+		err: status.Error(codes.Internal, "grpc: failed to unmarshal the received message")})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Publish(&fakePublishStream{err: io.EOF}); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		c        prometheus.Collector
+		expected float64
+	}{
+		"streams":        {m.streams.WithLabelValues(rpcPublish), 2},
+		"active streams": {m.activeStreams.WithLabelValues(rpcPublish), 0},
+		"received bytes": {m.receivedBytes.WithLabelValues(rpcPublish), float64(size)},
+		"decode errors":  {m.decodeErrors.WithLabelValues(rpcPublish), 1},
+		"dut1":           {m.notifications.WithLabelValues("dut1"), 2},
+		"dut2":           {m.notifications.WithLabelValues("dut2"), 1},
+	} {
+		if got := testutil.ToFloat64(tc.c); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", name, tc.expected, got)
+		}
+	}
+	if testutil.ToFloat64(m.lastReceived.WithLabelValues("dut1")) == 0 {
+		t.Error("expected the last received timestamp of dut1 to be set")
+	}
+	if _, err := NewMetrics(reg); err == nil {
+		t.Error("expected an error registering the metrics twice")
+	}
+}
+
+func TestPublishMetadata(t *testing.T) {
+	m, err := NewMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := &gnmireverse.Metadata{AgentVersion: "v1", Target: "dut1",
+		Paths: []gnmireverse.CollectedPath{
+			{Path: "/system", Mode: "target_defined"},
+			{Path: "/interfaces", Mode: "sample", Interval: time.Second},
+		}}
+	resps := []*gnmi.SubscribeResponse{md.SubscribeResponse(),
+		{Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{Prefix: &gnmi.Path{Target: "dut1"}}}}}
+	s := NewServerWithMetrics(debugSilent, nil, m)
+	if err := s.Publish(&fakePublishStream{resps: resps, err: io.EOF}); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clients := m.clients.WithLabelValues(rpcPublish, "dut1", "v1")
+	if got := testutil.ToFloat64(clients); got != 0 {
+		t.Errorf("expected no paths once the stream ended, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.notifications.WithLabelValues("dut1")); got != 1 {
+		t.Errorf("expected 1 notification, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.decodeErrors.WithLabelValues(rpcPublish)); got != 0 {
+		t.Errorf("expected no decode errors, got %v", got)
+	}
+
+	done := m.clientMetadata(rpcPublishGet, md)
+	if got := testutil.ToFloat64(m.clients.WithLabelValues(rpcPublishGet, "dut1", "v1")); got != 2 {
+		t.Errorf("expected 2 paths, got %v", got)
+	}
+	done()
+	if got := testutil.ToFloat64(m.clients.WithLabelValues(rpcPublishGet, "dut1", "v1")); got != 0 {
+		t.Errorf("expected no paths, got %v", got)
+	}
+}
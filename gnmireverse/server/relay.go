@@ -0,0 +1,388 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aristanetworks/glog"
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
+	"github.com/openconfig/gnmi/coalesce"
+	"github.com/openconfig/gnmi/ctree"
+	"github.com/openconfig/gnmi/match"
+	"github.com/openconfig/gnmi/path"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Relay is a gNMI server serving the notifications received from the
+// publishing targets, turning the gNMIReverse server into a relay. It works
+// as the cache and subscribe packages of openconfig/gnmi, which cannot be
+// linked along with github.com/aristanetworks/glog as they register the
+// same flags through github.com/golang/glog: the latest update of each leaf
+// is stored in a ctree.Tree per target, the updates are matched against the
+// subscriptions with a match.Match, and sent to each subscriber through a
+// coalesce.Queue.
+//
+// gNMI clients subscribe to the relay with the target of the notifications
+// in the prefix of their SubscriptionList, or "*" for all the targets. The
+// ONCE, POLL and STREAM modes are supported, the STREAM subscriptions
+// receiving the updates of their paths as they come regardless of their
+// subscription mode. The openconfig origin is stored without origin, and
+// the notifications without target are not relayed. The values of a target
+// are evicted once its last publishing stream ends, the subscribers
+// receiving the delete of the target.
+type Relay struct {
+	gnmi.UnimplementedGNMIServer
+
+	match *match.Match
+
+	mu sync.Mutex
+	// targets maps the targets to their leaves, keyed by their origin and
+	// path as returned by path.ToStrings. Each leaf is a Notification of
+	// its own.
+	targets map[string]*ctree.Tree
+	// publishers counts the publishing streams of each target, whose
+	// leaves are evicted once the last one ends.
+	publishers map[string]int
+}
+
+// NewRelay returns an empty Relay.
+func NewRelay() *Relay {
+	return &Relay{
+		match:      match.New(),
+		targets:    map[string]*ctree.Tree{},
+		publishers: map[string]int{},
+	}
+}
+
+// relaySync is queued for a subscriber once all the matching leaves have
+// been queued.
+type relaySync struct{}
+
+// relayFullPath joins prefix and p with the normalized origin of either,
+// but not the target.
+func relayFullPath(prefix, p *gnmi.Path) *gnmi.Path {
+	full := gnmilib.JoinPaths(prefix, p)
+	full.Origin = prefix.GetOrigin()
+	if full.Origin == "" {
+		full.Origin = p.GetOrigin()
+	}
+	full.Origin = gnmilib.NormalizeOrigin(full.Origin)
+	return full
+}
+
+// Update stores the updates of notif, removes the leaves it deletes, and
+// sends the updated and deleted leaves to their subscribers.
+func (r *Relay) Update(notif *gnmi.Notification) {
+	if r == nil || notif == nil {
+		return
+	}
+	target := notif.GetPrefix().GetTarget()
+	if target == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tree := r.targets[target]
+	if tree == nil {
+		tree = &ctree.Tree{}
+		r.targets[target] = tree
+	}
+	for _, del := range notif.Delete {
+		full := relayFullPath(notif.Prefix, del)
+		var deleted []*gnmi.Notification
+		tree.WalkDeleted(path.ToStrings(full, true),
+			func(v interface{}) bool {
+				return v.(*gnmi.Notification).Timestamp < notif.Timestamp
+			},
+			func(v interface{}) {
+				leaf := v.(*gnmi.Notification)
+				deleted = append(deleted, &gnmi.Notification{
+					Timestamp: notif.Timestamp,
+					Prefix:    leaf.Prefix,
+					Delete:    []*gnmi.Path{leaf.Update[0].Path},
+				})
+			})
+		for _, n := range deleted {
+			r.match.Update(ctree.DetachedLeaf(n), relayMatchPath(n.Prefix, n.Delete[0]))
+		}
+	}
+	for _, u := range notif.Update {
+		full := relayFullPath(notif.Prefix, u.Path)
+		n := &gnmi.Notification{
+			Timestamp: notif.Timestamp,
+			Prefix:    &gnmi.Path{Origin: full.Origin, Target: target},
+			Update:    []*gnmi.Update{{Path: &gnmi.Path{Elem: full.Elem}, Val: u.Val}},
+		}
+		key := path.ToStrings(full, true)
+		leaf := tree.GetLeaf(key)
+		if leaf == nil {
+			if err := tree.Add(key, n); err != nil {
+				glog.V(2).Infof("failed to relay the update of %s: %s", gnmilib.StrPath(full), err)
+				continue
+			}
+			leaf = tree.GetLeaf(key)
+		} else {
+			// As in a cache.Cache, stale updates are dropped and the
+			// unchanged values are not sent.
+			old := leaf.Value().(*gnmi.Notification)
+			if old.Timestamp > notif.Timestamp {
+				continue
+			}
+			leaf.Update(n)
+			if proto.Equal(old.Update[0].Val, u.Val) {
+				continue
+			}
+		}
+		r.match.Update(leaf, relayMatchPath(n.Prefix, n.Update[0].Path))
+	}
+}
+
+// relayMatchPath returns the path the leaf at prefix and p is matched
+// against the subscriptions with.
+func relayMatchPath(prefix, p *gnmi.Path) []string {
+	return append(path.ToStrings(prefix, true), path.ToStrings(p, false)...)
+}
+
+// relayPublisher updates a Relay with the notifications of a publishing
+// stream, and evicts the leaves of their targets once the stream ends and
+// no other stream publishes them.
+type relayPublisher struct {
+	r       *Relay
+	targets map[string]struct{}
+}
+
+// publisher returns the relayPublisher of a publishing stream, whose close
+// method must be called when the stream ends.
+func (r *Relay) publisher() *relayPublisher {
+	return &relayPublisher{r: r, targets: map[string]struct{}{}}
+}
+
+func (p *relayPublisher) update(notif *gnmi.Notification) {
+	if p.r == nil || notif == nil {
+		return
+	}
+	target := notif.GetPrefix().GetTarget()
+	if _, ok := p.targets[target]; !ok && target != "" {
+		p.targets[target] = struct{}{}
+		p.r.mu.Lock()
+		p.r.publishers[target]++
+		p.r.mu.Unlock()
+	}
+	p.r.Update(notif)
+}
+
+func (p *relayPublisher) close() {
+	for target := range p.targets {
+		p.r.endPublisher(target)
+	}
+}
+
+// endPublisher records the end of a publishing stream of target, evicting
+// its leaves if it was the last one: the subscribers receive the delete of
+// the target, and the streams subscribed to it alone end.
+func (r *Relay) endPublisher(target string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.publishers[target]--; r.publishers[target] > 0 {
+		return
+	}
+	delete(r.publishers, target)
+	if _, ok := r.targets[target]; !ok {
+		return
+	}
+	delete(r.targets, target)
+	r.match.Update(ctree.DetachedLeaf(&gnmi.Notification{
+		Timestamp: time.Now().UnixNano(),
+		Prefix:    &gnmi.Path{Target: target},
+		Delete:    []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: match.Glob}}}},
+	}), []string{target})
+}
+
+// isTargetDelete returns whether notif is the delete of its target sent by
+// endPublisher.
+func isTargetDelete(notif *gnmi.Notification) bool {
+	return len(notif.Delete) == 1 && notif.Prefix.GetOrigin() == "" &&
+		len(notif.Delete[0].Elem) == 1 && notif.Delete[0].Elem[0].Name == match.Glob
+}
+
+// relayQueue implements match.Client, queueing the matching leaves for a
+// subscriber.
+type relayQueue struct {
+	q *coalesce.Queue
+}
+
+func (c relayQueue) Update(v interface{}) {
+	c.q.Insert(v)
+}
+
+// queries returns the paths of the subscriptions of list as stored in a
+// Relay, after target if it is not empty.
+func queries(list *gnmi.SubscriptionList, target string) ([][]string, error) {
+	prefix := &gnmi.Path{Origin: gnmilib.NormalizeOrigin(list.Prefix.Origin),
+		Elem: list.Prefix.Elem, Element: list.Prefix.Element}
+	var queries [][]string
+	for _, sub := range list.Subscription {
+		p := sub.GetPath()
+		query, err := path.CompletePath(prefix, &gnmi.Path{
+			Origin: gnmilib.NormalizeOrigin(p.GetOrigin()),
+			Elem:   p.GetElem(), Element: p.GetElement()})
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid path %s: %s",
+				gnmilib.StrPath(p), err)
+		}
+		if target != "" {
+			query = append([]string{target}, query...)
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// hasTarget returns whether target is relayed, "*" matching any target.
+func (r *Relay) hasTarget(target string) bool {
+	if target == match.Glob {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.targets[target]
+	return ok
+}
+
+// query queues the leaves of target matching queries followed by a
+// relaySync into q.
+func (r *Relay) query(target string, queries [][]string, q *coalesce.Queue) error {
+	var trees []*ctree.Tree
+	r.mu.Lock()
+	for t, tree := range r.targets {
+		if target == match.Glob || target == t {
+			trees = append(trees, tree)
+		}
+	}
+	r.mu.Unlock()
+	for _, tree := range trees {
+		for _, query := range queries {
+			if err := tree.Query(query, func(_ []string, l *ctree.Leaf, _ interface{}) error {
+				_, err := q.Insert(l)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := q.Insert(relaySync{})
+	return err
+}
+
+// Subscribe implements the gNMI Subscribe RPC.
+func (r *Relay) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	switch {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	case req.GetSubscribe() == nil:
+		return status.Error(codes.InvalidArgument,
+			"the first request must be a SubscriptionList")
+	case req.GetSubscribe().GetPrefix().GetTarget() == "":
+		return status.Error(codes.InvalidArgument, "missing target")
+	}
+	list := req.GetSubscribe()
+	target := list.Prefix.Target
+	if !r.hasTarget(target) {
+		return status.Errorf(codes.NotFound, "no such target: %q", target)
+	}
+	paths, err := queries(list, "")
+	if err != nil {
+		return err
+	}
+
+	q := coalesce.NewQueue()
+	defer q.Close()
+	// errc is buffered for the goroutines of the subscription, of which
+	// only the first error ends it.
+	errc := make(chan error, 2)
+	switch list.Mode {
+	case gnmi.SubscriptionList_ONCE:
+		go func() {
+			if err := r.query(target, paths, q); err == nil {
+				q.Close()
+			}
+		}()
+	case gnmi.SubscriptionList_POLL:
+		go func() {
+			for {
+				if err := r.query(target, paths, q); err != nil {
+					return
+				}
+				// The following requests are only polls.
+				if _, err := stream.Recv(); err != nil {
+					if err == io.EOF {
+						err = nil
+					}
+					errc <- err
+					return
+				}
+			}
+		}()
+	case gnmi.SubscriptionList_STREAM:
+		matchPaths, err := queries(list, target)
+		if err != nil {
+			return err
+		}
+		if list.UpdatesOnly {
+			q.Insert(relaySync{})
+		}
+		for _, p := range matchPaths {
+			remove := r.match.AddQuery(p, relayQueue{q: q})
+			defer remove()
+		}
+		if !list.UpdatesOnly {
+			go r.query(target, paths, q)
+		}
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown subscription mode %s", list.Mode)
+	}
+	go func() {
+		errc <- r.send(stream, target, q)
+	}()
+	return <-errc
+}
+
+// send sends the leaves queued in q to stream until q is closed or the
+// target of the subscription is deleted.
+func (r *Relay) send(stream gnmi.GNMI_SubscribeServer, target string,
+	q *coalesce.Queue) error {
+	for {
+		item, _, err := q.Next(stream.Context())
+		if coalesce.IsClosedQueue(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if _, ok := item.(relaySync); ok {
+			if err := stream.Send(&gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+				return err
+			}
+			continue
+		}
+		notif := item.(*ctree.Leaf).Value().(*gnmi.Notification)
+		if err := stream.Send(&gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{Update: notif}}); err != nil {
+			return err
+		}
+		if isTargetDelete(notif) && target != match.Glob {
+			return nil
+		}
+	}
+}
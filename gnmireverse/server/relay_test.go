@@ -0,0 +1,198 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
+	"github.com/aristanetworks/goarista/test"
+	"github.com/openconfig/gnmi/ctree"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+func TestRelay(t *testing.T) {
+	mustParse := func(s string) *gnmi.Path {
+		t.Helper()
+		p, err := gnmilib.ParseGNMIElements(gnmilib.SplitPath(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	notif := func(target, prefix string, ts int64, paths ...string) *gnmi.Notification {
+		n := &gnmi.Notification{Timestamp: ts, Prefix: mustParse(prefix)}
+		n.Prefix.Target = target
+		for _, p := range paths {
+			n.Update = append(n.Update, &gnmi.Update{Path: mustParse(p),
+				Val: gnmilib.TypedValue(ts)})
+		}
+		return n
+	}
+
+	relay := NewRelay()
+	s := NewServerWithRelay(debugSilent, nil, nil, relay)
+	// The openconfig origin is relayed without origin.
+	openconfig := notif("dut2", "/interfaces/interface[name=Ethernet1]", 2, "state/mtu")
+	openconfig.Prefix.Origin = "openconfig"
+	for _, n := range []*gnmi.Notification{
+		notif("dut1", "/interfaces/interface[name=Ethernet1]", 1, "state/mtu",
+			"state/description"),
+		openconfig,
+		// Not relayed without target.
+		notif("", "/interfaces/interface[name=Ethernet1]", 2, "state/mtu"),
+		notif("dut1", "/system", 3, "state/hostname"),
+		{
+			Timestamp: 4, Prefix: &gnmi.Path{Target: "dut1"},
+			Delete: []*gnmi.Path{
+				mustParse("/interfaces/interface[name=Ethernet1]/state/description")},
+		},
+	} {
+		relay.Update(n)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	gnmi.RegisterGNMIServer(grpcServer, relay)
+	go grpcServer.Serve(l)
+	defer grpcServer.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := gnmilib.DialContext(ctx, &gnmilib.Config{Addr: l.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscribe := func(target string, mode gnmi.SubscriptionList_Mode,
+		path string) gnmi.GNMI_SubscribeClient {
+		stream, err := client.Subscribe(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stream.Send(&gnmi.SubscribeRequest{
+			Request: &gnmi.SubscribeRequest_Subscribe{Subscribe: &gnmi.SubscriptionList{
+				Prefix:       &gnmi.Path{Target: target},
+				Mode:         mode,
+				Subscription: []*gnmi.Subscription{{Path: mustParse(path)}},
+			}}}); err != nil {
+			t.Fatal(err)
+		}
+		return stream
+	}
+	// recv returns the paths and timestamps of the updates received until
+	// the sync response or the first live notification, deletes having
+	// negative timestamps.
+	recv := func(stream gnmi.GNMI_SubscribeClient) map[string]int64 {
+		t.Helper()
+		updates := map[string]int64{}
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.GetSyncResponse() {
+				return updates
+			}
+			n := resp.GetUpdate()
+			for _, u := range n.Update {
+				p := n.Prefix.Target + gnmilib.StrPath(gnmilib.JoinPaths(n.Prefix, u.Path))
+				updates[p] = n.Timestamp
+			}
+			for _, d := range n.Delete {
+				p := n.Prefix.Target + gnmilib.StrPath(gnmilib.JoinPaths(n.Prefix, d))
+				updates[p] = -n.Timestamp
+			}
+			if len(updates) > 0 && resp.GetUpdate().Timestamp > 3 {
+				return updates
+			}
+		}
+	}
+	leaves := func(target string) int {
+		var n int
+		relay.mu.Lock()
+		defer relay.mu.Unlock()
+		relay.targets[target].Query(nil, func([]string, *ctree.Leaf, interface{}) error {
+			n++
+			return nil
+		})
+		return n
+	}
+
+	once := subscribe("*", gnmi.SubscriptionList_ONCE, "/interfaces")
+	expected := map[string]int64{
+		"dut1/interfaces/interface[name=Ethernet1]/state/mtu": 1,
+		"dut2/interfaces/interface[name=Ethernet1]/state/mtu": 2,
+	}
+	if updates := recv(once); !test.DeepEqual(expected, updates) {
+		t.Errorf("expected %v, got %v", expected, updates)
+	}
+	if _, err := once.Recv(); err != io.EOF {
+		t.Errorf("expected the ONCE subscription to end, got %v", err)
+	}
+
+	poll := subscribe("dut2", gnmi.SubscriptionList_POLL, "/interfaces")
+	expected = map[string]int64{"dut2/interfaces/interface[name=Ethernet1]/state/mtu": 2}
+	if updates := recv(poll); !test.DeepEqual(expected, updates) {
+		t.Errorf("expected %v, got %v", expected, updates)
+	}
+	if err := poll.Send(&gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Poll{Poll: &gnmi.Poll{}}}); err != nil {
+		t.Fatal(err)
+	}
+	if updates := recv(poll); !test.DeepEqual(expected, updates) {
+		t.Errorf("expected %v after a poll, got %v", expected, updates)
+	}
+
+	stream := subscribe("dut1", gnmi.SubscriptionList_STREAM, "/interfaces/interface[name=*]/state")
+	expected = map[string]int64{"dut1/interfaces/interface[name=Ethernet1]/state/mtu": 1}
+	if updates := recv(stream); !test.DeepEqual(expected, updates) {
+		t.Errorf("expected %v, got %v", expected, updates)
+	}
+	relay.Update(notif("dut2", "/interfaces/interface[name=Ethernet1]", 5, "state/mtu"))
+	relay.Update(notif("dut1", "/system", 6, "state/hostname"))
+	relay.Update(&gnmi.Notification{Timestamp: 7, Prefix: &gnmi.Path{Target: "dut1"},
+		Delete: []*gnmi.Path{mustParse("/interfaces")}})
+	expected = map[string]int64{"dut1/interfaces/interface[name=Ethernet1]/state/mtu": -7}
+	if updates := recv(stream); !test.DeepEqual(expected, updates) {
+		t.Errorf("expected %v, got %v", expected, updates)
+	}
+	if n := leaves("dut1"); n != 1 {
+		t.Errorf("expected 1 leaf left for dut1, got %d", n)
+	}
+
+	// The leaves of a target are evicted when its last publishing stream
+	// ends, and the subscribers receive the delete of the target.
+	err = s.Publish(&fakePublishStream{err: io.EOF, resps: []*gnmi.SubscribeResponse{
+		{Response: &gnmi.SubscribeResponse_Update{Update: notif("dut1",
+			"/interfaces/interface[name=Ethernet2]", 8, "state/mtu")}},
+	}})
+	if err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updates := recv(stream)
+	if ts, ok := updates["dut1/interfaces/interface[name=Ethernet2]/state/mtu"]; !ok || ts != 8 {
+		t.Errorf("expected the update of Ethernet2, got %v", updates)
+	}
+	if updates = recv(stream); len(updates) != 1 || updates["dut1/*"] >= 0 {
+		t.Errorf("expected the delete of dut1, got %v", updates)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("expected the subscription to dut1 to end, got %v", err)
+	}
+	if relay.hasTarget("dut1") {
+		t.Error("expected the leaves of dut1 to be evicted")
+	}
+	if n := leaves("dut2"); n != 1 {
+		t.Errorf("expected 1 leaf left for dut2, got %d", n)
+	}
+}
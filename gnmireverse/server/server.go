@@ -7,22 +7,25 @@ package server
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/aristanetworks/glog"
 	gnmilib "github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/gnmireverse"
 	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	_ "google.golang.org/grpc/encoding/gzip" // Enable gzip encoding for the server.
@@ -47,47 +50,17 @@ const (
 // Use log.Logger for thread-safe printing.
 var logger = log.New(os.Stdout, "", 0)
 
-func newTLSConfig(clientCertAuth bool, certFile, keyFile, clientCAFile string) (*tls.Config,
-	error) {
-	tlsConfig := tls.Config{}
-	if clientCertAuth {
-		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
-		if clientCAFile == "" {
-			return nil, fmt.Errorf("client_cert_auth enable, client_cafile must also be set")
-		}
-		b, err := ioutil.ReadFile(clientCAFile)
-		if err != nil {
-			return nil, err
-		}
-		cp := x509.NewCertPool()
-		if !cp.AppendCertsFromPEM(b) {
-			return nil, fmt.Errorf("credentials: failed to append certificates")
-		}
-		tlsConfig.ClientCAs = cp
-	}
-	if certFile != "" {
-		if keyFile == "" {
-			return nil, fmt.Errorf("please provide both -certfile and -keyfile")
-		}
-		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-		if err != nil {
-			return nil, err
-		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
-	}
-	return &tlsConfig, nil
-}
-
 // Main initializes the gNMIReverse server.
 func Main() {
 	addr := flag.String("addr", "127.0.0.1:6035", "address to listen on")
 	useTLS := flag.Bool("tls", true, "set to false to disable TLS in collector")
 	clientCertAuth := flag.Bool("client_cert_auth", false,
 		"require and verify a client certificate. -client_cafile must also be set.")
-	certFile := flag.String("certfile", "", "path to TLS certificate file")
+	certFile := flag.String("certfile", "", "path to TLS certificate file, "+
+		"reloaded for the new connections when it or the key file changes")
 	keyFile := flag.String("keyfile", "", "path to TLS key file")
 	clientCAFile := flag.String("client_cafile", "",
-		"path to TLS CA file to verify client certificate")
+		"path to TLS CA file to verify client certificate, reloaded on SIGHUP")
 
 	debugFlagUsage := `Debug flags. Use bitwise OR to select what to print.
   -1  Enable all debug flags.
@@ -105,15 +78,43 @@ Example: Use 50 (= 2 | 16 | 32) to print the GetResponse summary and the receive
          notification time, timing calculations and path of updates.`
 	debugFlag := flag.Int("debug", 0, debugFlagUsage)
 
+	allowedCNs := flag.String("allowed_client_cns", "", "comma-separated list of the "+
+		"accepted client certificate common names. -client_cert_auth must also be set.")
+	tokenFile := flag.String("token_file", "", "path to a file with one accepted "+
+		"token per line. Clients must send one in the authorization gRPC metadata.")
+	allowedTargets := flag.String("allowed_targets", "", "comma-separated list of "+
+		"the accepted notification targets, as shell patterns (e.g. 'dc1-*')")
+	relayAddr := flag.String("relay_addr", "", "address to serve a gNMI Subscribe "+
+		"service relaying the latest values and the updates received from the "+
+		"targets on (e.g. ':6030')")
+	tagTargets := flag.Bool("tag_targets", false, "tag the streams with "+
+		"notifications for targets not in -allowed_targets instead of rejecting them. "+
+		"The targets of their notifications are prefixed with "+TaggedTargetPrefix)
+	metricsAddr := flag.String("metrics_addr", "", "address to serve the Prometheus "+
+		"metrics of the received streams on, at /metrics (e.g. ':9100')")
+
 	flag.Parse()
 
 	var config *tls.Config
 	if *useTLS {
-		var err error
-		config, err = newTLSConfig(*clientCertAuth, *certFile, *keyFile, *clientCAFile)
+		certs, err := newCertReloader(*clientCertAuth, *certFile, *keyFile, *clientCAFile)
 		if err != nil {
 			glog.Fatal(err)
 		}
+		config = certs.tlsConfig()
+		// The certificate is reloaded when its files change, reload the
+		// client CAs as well on SIGHUP.
+		sighup := make(chan os.Signal, 1)
+		signal.Notify(sighup, syscall.SIGHUP)
+		go func() {
+			for range sighup {
+				if err := certs.reload(); err != nil {
+					glog.Error(err)
+				} else {
+					glog.Info("Reloaded TLS credentials")
+				}
+			}
+		}()
 	}
 
 	var serverOptions []grpc.ServerOption
@@ -125,11 +126,58 @@ Example: Use 50 (= 2 | 16 | 32) to print the GetResponse summary and the receive
 		grpc.MaxRecvMsgSize(math.MaxInt32),
 	)
 
-	grpcServer := grpc.NewServer(serverOptions...)
-	s := &server{
-		debugFlag: *debugFlag,
+	allowList := &AllowList{TagTargets: *tagTargets}
+	if *allowedCNs != "" {
+		if !*clientCertAuth {
+			glog.Fatal("-allowed_client_cns requires -client_cert_auth")
+		}
+		allowList.CommonNames = strings.Split(*allowedCNs, ",")
 	}
-	gnmireverse.RegisterGNMIReverseServer(grpcServer, s)
+	if *tokenFile != "" {
+		b, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		for _, token := range strings.Split(string(b), "\n") {
+			if token = strings.TrimSpace(token); token != "" {
+				allowList.Tokens = append(allowList.Tokens, token)
+			}
+		}
+	}
+	if *allowedTargets != "" {
+		allowList.Targets = strings.Split(*allowedTargets, ",")
+	}
+
+	var metrics *Metrics
+	if *metricsAddr != "" {
+		var err error
+		metrics, err = NewMetrics(prometheus.DefaultRegisterer)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		http.Handle("/metrics", promhttp.Handler())
+		go func() {
+			glog.Fatal(http.ListenAndServe(*metricsAddr, nil))
+		}()
+	}
+
+	var relay *Relay
+	if *relayAddr != "" {
+		relay = NewRelay()
+		relayServer := grpc.NewServer(serverOptions...)
+		gnmi.RegisterGNMIServer(relayServer, relay)
+		listener, err := net.Listen("tcp", *relayAddr)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		go func() {
+			glog.Fatal(relayServer.Serve(listener))
+		}()
+	}
+
+	grpcServer := grpc.NewServer(serverOptions...)
+	gnmireverse.RegisterGNMIReverseServer(grpcServer,
+		NewServerWithRelay(*debugFlag, allowList, metrics, relay))
 
 	listener, err := net.Listen("tcp", *addr)
 	if err != nil {
@@ -141,17 +189,71 @@ Example: Use 50 (= 2 | 16 | 32) to print the GetResponse summary and the receive
 }
 
 type server struct {
-	debugFlag int
+	debugFlag   int
+	interceptor Interceptor
+	metrics     *Metrics
+	relay       *Relay
 	gnmireverse.UnimplementedGNMIReverseServer
 }
 
+// NewServer returns a gNMIReverse server logging the responses it receives
+// according to debugFlag. If interceptor is not nil, it authenticates the
+// streams and checks the targets of their notifications.
+func NewServer(debugFlag int, interceptor Interceptor) gnmireverse.GNMIReverseServer {
+	return NewServerWithMetrics(debugFlag, interceptor, nil)
+}
+
+// NewServerWithMetrics returns a server like NewServer recording the
+// streams it receives in metrics.
+func NewServerWithMetrics(debugFlag int, interceptor Interceptor,
+	metrics *Metrics) gnmireverse.GNMIReverseServer {
+	return NewServerWithRelay(debugFlag, interceptor, metrics, nil)
+}
+
+// NewServerWithRelay returns a server like NewServerWithMetrics also
+// updating relay with the notifications it receives, if relay is not nil.
+func NewServerWithRelay(debugFlag int, interceptor Interceptor, metrics *Metrics,
+	relay *Relay) gnmireverse.GNMIReverseServer {
+	return &server{debugFlag: debugFlag, interceptor: interceptor, metrics: metrics,
+		relay: relay}
+}
+
 func (s *server) Publish(stream gnmireverse.GNMIReverse_PublishServer) error {
+	guard, err := newStreamGuard(stream.Context(), s.interceptor)
+	if err != nil {
+		return err
+	}
+	defer s.metrics.streamStarted(rpcPublish)()
+	// The metadata is recorded until the stream ends or the client sends
+	// other metadata.
+	metadataDone := func() {}
+	defer func() { metadataDone() }()
 	debugger := newDebugger(stream.Context(), "subscribe", s.debugFlag)
+	// The relayed leaves of the targets of the stream are evicted when it
+	// ends.
+	relay := s.relay.publisher()
+	defer relay.close()
 	for {
 		resp, err := stream.Recv()
 		if err != nil {
+			s.metrics.recvError(rpcPublish, err)
 			return err
 		}
+		s.metrics.received(rpcPublish, resp)
+		if md, ok, err := gnmireverse.SubscribeResponseMetadata(resp); err != nil {
+			s.metrics.decodeError(rpcPublish)
+			return err
+		} else if ok {
+			metadataDone()
+			metadataDone = s.metrics.clientMetadata(rpcPublish, md)
+			logMetadata(stream.Context(), rpcPublish, md)
+			continue
+		}
+		if err := guard.checkNotification(resp.GetUpdate()); err != nil {
+			return err
+		}
+		s.metrics.notification(resp.GetUpdate(), time.Now())
+		relay.update(resp.GetUpdate())
 		if s.debugFlag != 0 {
 			debugger.logSubscribeResponse(resp)
 			continue
@@ -164,11 +266,52 @@ func (s *server) Publish(stream gnmireverse.GNMIReverse_PublishServer) error {
 }
 
 func (s *server) PublishGet(stream gnmireverse.GNMIReverse_PublishGetServer) error {
+	guard, err := newStreamGuard(stream.Context(), s.interceptor)
+	if err != nil {
+		return err
+	}
+	defer s.metrics.streamStarted(rpcPublishGet)()
+	// The metadata is recorded until the stream ends or the client sends
+	// other metadata.
+	metadataDone := func() {}
+	defer func() { metadataDone() }()
 	debugger := newDebugger(stream.Context(), "get", s.debugFlag)
+	relay := s.relay.publisher()
+	defer relay.close()
+	var assembler gnmireverse.GetResponseAssembler
 	for {
-		resp, err := stream.Recv()
+		chunk, err := stream.Recv()
 		if err != nil {
+			s.metrics.recvError(rpcPublishGet, err)
+			return err
+		}
+		s.metrics.received(rpcPublishGet, chunk)
+		if md, ok, err := gnmireverse.GetResponseMetadata(chunk); err != nil {
+			s.metrics.decodeError(rpcPublishGet)
 			return err
+		} else if ok {
+			metadataDone()
+			metadataDone = s.metrics.clientMetadata(rpcPublishGet, md)
+			logMetadata(stream.Context(), rpcPublishGet, md)
+			continue
+		}
+		resp, ok, err := assembler.Add(chunk)
+		if err != nil {
+			s.metrics.decodeError(rpcPublishGet)
+			return err
+		}
+		if !ok {
+			continue
+		}
+		for _, notif := range resp.GetNotification() {
+			if err := guard.checkNotification(notif); err != nil {
+				return err
+			}
+		}
+		receiveTime := time.Now()
+		for _, notif := range resp.GetNotification() {
+			s.metrics.notification(notif, receiveTime)
+			relay.update(notif)
 		}
 		if s.debugFlag != 0 {
 			debugger.logGetResponse(resp)
@@ -202,6 +345,18 @@ func (s *server) PublishGet(stream gnmireverse.GNMIReverse_PublishGetServer) err
 	}
 }
 
+// logMetadata logs the metadata md sent by the client of a stream of rpc.
+func logMetadata(ctx context.Context, rpc string, md *gnmireverse.Metadata) {
+	glog.Infof("%s stream of client %s: %s", rpc, clientAddr(ctx), md)
+}
+
+func clientAddr(ctx context.Context) string {
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		return pr.Addr.String()
+	}
+	return ""
+}
+
 // debugger stores debug information related to responses received from a client.
 type debugger struct {
 	clientAddr       string               // Address of the client sending the response.
@@ -214,12 +369,8 @@ type debugger struct {
 }
 
 func newDebugger(ctx context.Context, responseName string, debugFlag int) *debugger {
-	var clientAddr string
-	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
-		clientAddr = pr.Addr.String()
-	}
 	return &debugger{
-		clientAddr:      clientAddr,
+		clientAddr:      clientAddr(ctx),
 		responseName:    responseName,
 		lastUpdateTimes: make(map[string]time.Time),
 		debugFlag:       debugFlag,
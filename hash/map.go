@@ -0,0 +1,43 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// Package hash provides constructors of [github.com/aristanetworks/gomap.Map]
+// for common key types, with their hash and equal functions.
+package hash
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+
+	"github.com/aristanetworks/goarista/key"
+	"github.com/aristanetworks/gomap"
+)
+
+// String hashes a string key.
+func String(seed maphash.Seed, s string) uint64 {
+	return maphash.String(seed, s)
+}
+
+// Uint64 hashes a uint64 key.
+func Uint64(seed maphash.Seed, v uint64) uint64 {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return maphash.Bytes(seed, buf[:])
+}
+
+// NewStringMap returns a Map with string keys, initialized with kes.
+func NewStringMap[V any](kes ...gomap.KeyElem[string, V]) *gomap.Map[string, V] {
+	return gomap.New(func(a, b string) bool { return a == b }, String, kes...)
+}
+
+// NewUint64Map returns a Map with uint64 keys, initialized with kes.
+func NewUint64Map[V any](kes ...gomap.KeyElem[uint64, V]) *gomap.Map[uint64, V] {
+	return gomap.New(func(a, b uint64) bool { return a == b }, Uint64, kes...)
+}
+
+// NewKeyMap returns a Map with key.Key keys, hashed with key.Hash and
+// compared with their Equal method, initialized with kes.
+func NewKeyMap[V any](kes ...gomap.KeyElem[key.Key, V]) *gomap.Map[key.Key, V] {
+	return gomap.New(func(a, b key.Key) bool { return a.Equal(b) }, key.Hash, kes...)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package hash
+
+import (
+	"testing"
+
+	"github.com/aristanetworks/goarista/key"
+	"github.com/aristanetworks/gomap"
+)
+
+func TestNewStringMap(t *testing.T) {
+	m := NewStringMap(gomap.KeyElem[string, int]{Key: "a", Elem: 1})
+	m.Set("b", 2)
+	// A string built at runtime is the same key.
+	if v, ok := m.Get(string([]byte{'b'})); !ok || v != 2 {
+		t.Errorf("expected 2, got %d, %t", v, ok)
+	}
+	if v, ok := m.Get("a"); !ok || v != 1 || m.Len() != 2 {
+		t.Errorf("expected 1, got %d, %t", v, ok)
+	}
+}
+
+func TestNewUint64Map(t *testing.T) {
+	m := NewUint64Map[string]()
+	for i := uint64(0); i < 1000; i++ {
+		m.Set(i<<32, "x")
+	}
+	if m.Len() != 1000 {
+		t.Errorf("expected 1000 keys, got %d", m.Len())
+	}
+	if _, ok := m.Get(1); ok {
+		t.Error("unexpected key 1")
+	}
+}
+
+func TestNewKeyMap(t *testing.T) {
+	m := NewKeyMap[int]()
+	m.Set(key.New("a"), 1)
+	m.Set(key.New(map[string]interface{}{"b": uint32(2)}), 2)
+	if v, ok := m.Get(key.New("a")); !ok || v != 1 {
+		t.Errorf("expected 1, got %d, %t", v, ok)
+	}
+	// Equal keys built separately are the same key.
+	if v, ok := m.Get(key.New(map[string]interface{}{"b": uint32(2)})); !ok || v != 2 {
+		t.Errorf("expected 2, got %d, %t", v, ok)
+	}
+	if _, ok := m.Get(key.New(map[string]interface{}{"b": uint32(3)})); ok {
+		t.Error("unexpected key")
+	}
+}
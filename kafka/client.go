@@ -17,8 +17,9 @@ const (
 	outOfBrokersRetries = 5
 )
 
-// NewClient returns a Kafka client
-func NewClient(addresses []string) (sarama.Client, error) {
+// NewConfig returns the Kafka configuration of a producer, with the TLS
+// and SASL authentication set by the command-line flags.
+func NewConfig() (*sarama.Config, error) {
 	config := sarama.NewConfig()
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -27,6 +28,18 @@ func NewClient(addresses []string) (sarama.Client, error) {
 	config.ClientID = hostname
 	config.Producer.Compression = sarama.CompressionSnappy
 	config.Producer.Return.Successes = true
+	if err := SecurityFromFlags().Apply(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// NewClient returns a Kafka client
+func NewClient(addresses []string) (sarama.Client, error) {
+	config, err := NewConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	var client sarama.Client
 	retries := outOfBrokersRetries + 1
@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// Package consumer decodes the messages produced by the kafka/gnmi encoder
+// back into gNMI Notifications, for the Go consumers of the topics.
+package consumer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aristanetworks/goarista/gnmi"
+	kafkagnmi "github.com/aristanetworks/goarista/kafka/gnmi"
+
+	"github.com/IBM/sarama"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Message is the JSON document of a message produced by the kafka/gnmi
+// encoder, holding one update or delete.
+type Message struct {
+	// Timestamp of the notification in nanoseconds.
+	Timestamp uint64
+	// DatasetID is the dataset given to the encoder, the address of the
+	// device for ockafka.
+	DatasetID string
+	// Path is the full path of the update or delete.
+	Path string
+	// Key is the path relative to the prefix of the notification.
+	Key       []byte
+	KeyString *string `json:",omitempty"`
+	// PathElems and SchemaPath are the structured path and the path
+	// without the list keys.
+	PathElems  []kafkagnmi.PathElem `json:",omitempty"`
+	SchemaPath string               `json:",omitempty"`
+
+	// The value of an update is in one of the following fields, or none
+	// if its type is not supported by the encoder. Value holds the
+	// elements of a leaf-list.
+	Value       []*Element `json:",omitempty"`
+	ValueString *string    `json:",omitempty"`
+	ValueLong   *int64     `json:",omitempty"`
+	ValueBool   *bool      `json:",omitempty"`
+	ValueDouble *float64   `json:",omitempty"`
+
+	// Del is true for a delete.
+	Del *bool `json:",omitempty"`
+}
+
+// Element is an element of a leaf-list value, with its value in one of
+// the fields.
+type Element struct {
+	String *string  `json:",omitempty"`
+	Long   *int64   `json:",omitempty"`
+	Bool   *bool    `json:",omitempty"`
+	Double *float64 `json:",omitempty"`
+}
+
+// Decode decodes the value of a message produced by the kafka/gnmi encoder.
+func Decode(b []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %s", err)
+	}
+	if m.Path == "" && len(m.PathElems) == 0 {
+		return nil, fmt.Errorf("message without path: %s", b)
+	}
+	return &m, nil
+}
+
+// DecodeNotification decodes the value of a message produced by the
+// kafka/gnmi encoder into a Notification with its update or delete.
+func DecodeNotification(b []byte) (*pb.Notification, error) {
+	m, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return m.Notification()
+}
+
+// DecodeConsumerMessage decodes a message consumed from a topic written by
+// the kafka/gnmi encoder into a Notification.
+func DecodeConsumerMessage(msg *sarama.ConsumerMessage) (*pb.Notification, error) {
+	return DecodeNotification(msg.Value)
+}
+
+// IsDelete returns whether m is a delete.
+func (m *Message) IsDelete() bool {
+	return m.Del != nil && *m.Del
+}
+
+// GNMIPath returns the full path of m.
+func (m *Message) GNMIPath() (*pb.Path, error) {
+	if len(m.PathElems) == 0 {
+		p, err := gnmi.ParseGNMIElements(gnmi.SplitPath(m.Path))
+		if err != nil {
+			return nil, err
+		}
+		// Drop the deprecated string elements.
+		return &pb.Path{Elem: p.GetElem()}, nil
+	}
+	elems := make([]*pb.PathElem, len(m.PathElems))
+	for i, e := range m.PathElems {
+		elems[i] = &pb.PathElem{Name: e.Name, Key: e.Keys}
+	}
+	return &pb.Path{Elem: elems}, nil
+}
+
+// TypedValue returns the value of the update of m, or nil if it has
+// none. The encoder does not keep the exact type of the values: integers
+// are decoded as int_val, the floating point and decimal numbers and the
+// numbers of the JSON values as double_val.
+func (m *Message) TypedValue() *pb.TypedValue {
+	switch {
+	case m.ValueString != nil:
+		return &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: *m.ValueString}}
+	case m.ValueLong != nil:
+		return &pb.TypedValue{Value: &pb.TypedValue_IntVal{IntVal: *m.ValueLong}}
+	case m.ValueBool != nil:
+		return &pb.TypedValue{Value: &pb.TypedValue_BoolVal{BoolVal: *m.ValueBool}}
+	case m.ValueDouble != nil:
+		return &pb.TypedValue{Value: &pb.TypedValue_DoubleVal{DoubleVal: *m.ValueDouble}}
+	case m.Value != nil:
+		elems := make([]*pb.TypedValue, 0, len(m.Value))
+		for _, e := range m.Value {
+			if e == nil {
+				continue
+			}
+			v := (&Message{ValueString: e.String, ValueLong: e.Long, ValueBool: e.Bool,
+				ValueDouble: e.Double}).TypedValue()
+			if v != nil {
+				elems = append(elems, v)
+			}
+		}
+		return &pb.TypedValue{Value: &pb.TypedValue_LeaflistVal{
+			LeaflistVal: &pb.ScalarArray{Element: elems}}}
+	}
+	return nil
+}
+
+// Notification returns the Notification of the update or delete of m,
+// with the full path and no prefix. The origin and the target of the
+// paths are not part of the messages.
+func (m *Message) Notification() (*pb.Notification, error) {
+	path, err := m.GNMIPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse path %q: %s", m.Path, err)
+	}
+	notif := &pb.Notification{Timestamp: int64(m.Timestamp)}
+	if m.IsDelete() {
+		notif.Delete = []*pb.Path{path}
+	} else {
+		notif.Update = []*pb.Update{{Path: path, Val: m.TypedValue()}}
+	}
+	return notif, nil
+}
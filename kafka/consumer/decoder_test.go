@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package consumer
+
+import (
+	"testing"
+
+	kafkagnmi "github.com/aristanetworks/goarista/kafka/gnmi"
+
+	"github.com/IBM/sarama"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeNotification(t *testing.T) {
+	elem := func(name string, keys map[string]string) *pb.PathElem {
+		return &pb.PathElem{Name: name, Key: keys}
+	}
+	intf := elem("interface", map[string]string{"name": "Ethernet1"})
+	path := func(elems ...*pb.PathElem) *pb.Path {
+		return &pb.Path{Elem: elems}
+	}
+	notif := &pb.Notification{
+		Timestamp: 42,
+		Prefix:    path(elem("interfaces", nil), intf),
+		Delete:    []*pb.Path{path(elem("config", nil))},
+		Update: []*pb.Update{{
+			Path: path(elem("state", nil), elem("counters", nil), elem("in-octets", nil)),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: 7}},
+		}, {
+			Path: path(elem("state", nil), elem("description", nil)),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "uplink"}},
+		}, {
+			Path: path(elem("state", nil), elem("enabled", nil)),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_BoolVal{BoolVal: true}},
+		}, {
+			Path: path(elem("state", nil), elem("rate", nil)),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: []byte("1.5")}},
+		}, {
+			Path: path(elem("state", nil), elem("vlans", nil)),
+			Val: &pb.TypedValue{Value: &pb.TypedValue_LeaflistVal{
+				LeaflistVal: &pb.ScalarArray{Element: []*pb.TypedValue{
+					{Value: &pb.TypedValue_IntVal{IntVal: 10}},
+					{Value: &pb.TypedValue_StringVal{StringVal: "20"}},
+				}}}},
+		}},
+	}
+	enc := kafkagnmi.NewEncoder("topic", sarama.StringEncoder("key"), "10.0.0.1")
+	messages, err := enc.Encode(&pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_Update{Update: notif}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full := func(elems ...*pb.PathElem) *pb.Path {
+		return path(append([]*pb.PathElem{elem("interfaces", nil), intf}, elems...)...)
+	}
+	update := func(p *pb.Path, v *pb.TypedValue) *pb.Notification {
+		return &pb.Notification{Timestamp: 42, Update: []*pb.Update{{Path: p, Val: v}}}
+	}
+	expected := []*pb.Notification{
+		{Timestamp: 42, Delete: []*pb.Path{full(elem("config", nil))}},
+		update(full(elem("state", nil), elem("counters", nil), elem("in-octets", nil)),
+			&pb.TypedValue{Value: &pb.TypedValue_IntVal{IntVal: 7}}),
+		update(full(elem("state", nil), elem("description", nil)),
+			&pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "uplink"}}),
+		update(full(elem("state", nil), elem("enabled", nil)),
+			&pb.TypedValue{Value: &pb.TypedValue_BoolVal{BoolVal: true}}),
+		update(full(elem("state", nil), elem("rate", nil)),
+			&pb.TypedValue{Value: &pb.TypedValue_DoubleVal{DoubleVal: 1.5}}),
+		update(full(elem("state", nil), elem("vlans", nil)),
+			&pb.TypedValue{Value: &pb.TypedValue_LeaflistVal{
+				LeaflistVal: &pb.ScalarArray{Element: []*pb.TypedValue{
+					{Value: &pb.TypedValue_IntVal{IntVal: 10}},
+					{Value: &pb.TypedValue_StringVal{StringVal: "20"}},
+				}}}}),
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(messages))
+	}
+	for i, msg := range messages {
+		b, err := msg.Value.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := DecodeConsumerMessage(&sarama.ConsumerMessage{Value: b})
+		if err != nil {
+			t.Fatalf("message %d: %s", i, err)
+		}
+		if !proto.Equal(expected[i], actual) {
+			t.Errorf("message %d: expected %s, got %s", i, expected[i], actual)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// Messages without structured path fall back to parsing Path.
+	m, err := Decode([]byte(`{"Timestamp":1,"DatasetID":"d","Path":"/a/b[k=v]",` +
+		`"Del":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notif, err := m.Notification()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &pb.Notification{Timestamp: 1, Delete: []*pb.Path{{Elem: []*pb.PathElem{
+		{Name: "a"}, {Name: "b", Key: map[string]string{"k": "v"}}}}}}
+	if !proto.Equal(expected, notif) {
+		t.Errorf("expected %s, got %s", expected, notif)
+	}
+
+	for _, b := range []string{`not json`, `{"Timestamp":1}`} {
+		if _, err := DecodeNotification([]byte(b)); err == nil {
+			t.Errorf("%s: expected an error", b)
+		}
+	}
+}
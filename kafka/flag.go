@@ -15,3 +15,20 @@ var Addresses = flag.String("kafkaaddrs", "localhost:9092", "kafka's comma-separ
 
 // Topic is the flag for kafka's topic
 var Topic = flag.String("kafkatopic", filepath.Base(os.Args[0]), "kafka's topic")
+
+var (
+	tlsFlag = flag.Bool("kafkatls", false, "use TLS to connect to kafka")
+
+	caFileFlag = flag.String("kafkacafile", "",
+		"path to the CA certificates verifying the kafka brokers (implies -kafkatls)")
+	certFileFlag = flag.String("kafkacertfile", "",
+		"path to the TLS client certificate for kafka (implies -kafkatls)")
+	keyFileFlag = flag.String("kafkakeyfile", "",
+		"path to the TLS client private key for kafka (implies -kafkatls)")
+	tlsSkipVerifyFlag = flag.Bool("kafkatlsskipverify", false,
+		"do not verify the certificates of the kafka brokers (implies -kafkatls)")
+	saslMechanismFlag = flag.String("kafkasaslmechanism", "",
+		"kafka SASL mechanism (PLAIN | SCRAM-SHA-256 | SCRAM-SHA-512)")
+	saslUserFlag     = flag.String("kafkasasluser", "", "kafka SASL user")
+	saslPasswordFlag = flag.String("kafkasaslpassword", "", "kafka SASL password")
+)
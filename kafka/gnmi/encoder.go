@@ -7,9 +7,13 @@ package gnmi
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aristanetworks/goarista/elasticsearch"
+	gnmilib "github.com/aristanetworks/goarista/gnmi"
 	"github.com/aristanetworks/goarista/kafka"
 
 	"github.com/IBM/sarama"
@@ -36,40 +40,151 @@ func (e UnhandledSubscribeResponseError) Error() string {
 	return fmt.Sprintf("Unexpected type %T in subscribe response: %#v", e.response, e.response)
 }
 
+// KeyMode selects the Kafka keys of the messages produced by the encoder,
+// which determine their partitions: the messages with the same key are
+// produced to the same partition, in order.
+type KeyMode int
+
+const (
+	// KeyDevice keys all the messages with the key of the device, producing
+	// them to a single partition.
+	KeyDevice KeyMode = iota
+	// KeyPath keys the messages with the path of their update.
+	KeyPath
+	// KeyDevicePathHash keys the messages with the key of the device
+	// followed by a hash of the path of their update.
+	//
+	// With KeyPath and KeyDevicePathHash, the delete of a subtree is keyed
+	// with the deleted path, so it may be produced to another partition than
+	// the updates of its children and be consumed out of order with them.
+	// Use KeyDevice when deletes must stay ordered with the updates they
+	// affect.
+	KeyDevicePathHash
+)
+
+var keyModeNames = map[KeyMode]string{
+	KeyDevice:         "device",
+	KeyPath:           "path",
+	KeyDevicePathHash: "device+path-hash",
+}
+
+func (m KeyMode) String() string {
+	if name, ok := keyModeNames[m]; ok {
+		return name
+	}
+	return "KeyMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ParseKeyMode returns the KeyMode named s: "device", "path" or
+// "device+path-hash".
+func ParseKeyMode(s string) (KeyMode, error) {
+	for mode, name := range keyModeNames {
+		if name == s {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key mode %q, expected device, path or device+path-hash", s)
+}
+
 type elasticsearchMessageEncoder struct {
 	*kafka.BaseEncoder
 	topic   string
 	dataset string
 	key     sarama.Encoder
+	keyMode KeyMode
+	// keyPrefix is the encoded key followed by a separator, for
+	// KeyDevicePathHash.
+	keyPrefix string
 }
 
 // NewEncoder creates and returns a new elasticsearch MessageEncoder
 func NewEncoder(topic string, key sarama.Encoder, dataset string) kafka.MessageEncoder {
+	return NewEncoderWithKeyMode(topic, key, dataset, KeyDevice)
+}
+
+// NewEncoderWithKeyMode returns an encoder like NewEncoder keying the
+// messages according to keyMode, key being the key of the device.
+func NewEncoderWithKeyMode(topic string, key sarama.Encoder, dataset string,
+	keyMode KeyMode) kafka.MessageEncoder {
 	baseEncoder := kafka.NewBaseEncoder("elasticsearch")
-	return &elasticsearchMessageEncoder{
+	e := &elasticsearchMessageEncoder{
 		BaseEncoder: baseEncoder,
 		topic:       topic,
 		dataset:     dataset,
 		key:         key,
+		keyMode:     keyMode,
 	}
+	if keyMode == KeyDevicePathHash {
+		b, err := key.Encode()
+		if err != nil {
+			glog.Errorf("Failed to encode the Kafka key: %s", err)
+		}
+		e.keyPrefix = string(b) + ":"
+	}
+	return e
 }
 
+// messageKey returns the key of the message of the update or delete of path.
+// The delete of a subtree is keyed with the subtree, not with its children.
+func (e *elasticsearchMessageEncoder) messageKey(path *gnmi.Path) sarama.Encoder {
+	switch e.keyMode {
+	case KeyPath:
+		return sarama.StringEncoder(gnmilib.StrPath(path))
+	case KeyDevicePathHash:
+		h := fnv.New64a()
+		h.Write([]byte(gnmilib.StrPath(path)))
+		return sarama.StringEncoder(e.keyPrefix + strconv.FormatUint(h.Sum64(), 16))
+	}
+	return e.key
+}
+
+// Encode encodes the updates of a *gnmi.SubscribeResponse, or of all the
+// notifications of a *gnmi.GetResponse.
 func (e *elasticsearchMessageEncoder) Encode(message proto.Message) ([]*sarama.ProducerMessage,
 	error) {
-	response, ok := message.(*gnmi.SubscribeResponse)
-	if !ok {
+	switch message := message.(type) {
+	case *gnmi.SubscribeResponse:
+		update := message.GetUpdate()
+		if update == nil {
+			return nil, UnhandledSubscribeResponseError{response: message}
+		}
+		return e.encodeNotification(update)
+	case *gnmi.GetResponse:
+		var messages []*sarama.ProducerMessage
+		for _, notif := range message.Notification {
+			m, err := e.encodeNotification(notif)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, m...)
+		}
+		return messages, nil
+	default:
 		return nil, UnhandledMessageError{message: message}
 	}
-	update := response.GetUpdate()
-	if update == nil {
-		return nil, UnhandledSubscribeResponseError{response: response}
-	}
+}
+
+func (e *elasticsearchMessageEncoder) encodeNotification(
+	update *gnmi.Notification) ([]*sarama.ProducerMessage, error) {
 	updateMaps, err := elasticsearch.NotificationToMaps(e.dataset, update)
 	if err != nil {
 		return nil, err
 	}
+	// NotificationToMaps returns the deletes first, then the updates.
+	paths := make([]*gnmi.Path, 0, len(update.Delete)+len(update.Update))
+	for _, del := range update.Delete {
+		paths = append(paths, gnmilib.JoinPaths(update.Prefix, del))
+	}
+	for _, upd := range update.Update {
+		paths = append(paths, gnmilib.JoinPaths(update.Prefix, upd.Path))
+	}
 	messages := make([]*sarama.ProducerMessage, len(updateMaps))
 	for i, updateMap := range updateMaps {
+		key := e.key
+		if i < len(paths) {
+			setStructuredPath(updateMap, paths[i])
+			key = e.messageKey(paths[i])
+		}
 		updateJSON, err := json.Marshal(updateMap)
 		if err != nil {
 			return nil, err
@@ -78,10 +193,38 @@ func (e *elasticsearchMessageEncoder) Encode(message proto.Message) ([]*sarama.P
 
 		messages[i] = &sarama.ProducerMessage{
 			Topic:    e.topic,
-			Key:      e.key,
+			Key:      key,
 			Value:    sarama.ByteEncoder(updateJSON),
 			Metadata: kafka.Metadata{StartTime: time.Unix(0, update.Timestamp), NumMessages: 1},
 		}
 	}
 	return messages, nil
 }
+
+// PathElem is the structured representation of a gNMI PathElem in the
+// messages produced by the encoder.
+type PathElem struct {
+	Name string            `json:"name"`
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+// setStructuredPath adds to doc the elements of path under "PathElems", and
+// its schema path, the path without the list keys (e.g.
+// "/interfaces/interface/state"), under "SchemaPath".
+func setStructuredPath(doc map[string]interface{}, path *gnmi.Path) {
+	elems := make([]PathElem, len(path.GetElem()))
+	var schemaPath strings.Builder
+	for i, elem := range path.GetElem() {
+		elems[i] = PathElem{Name: elem.GetName()}
+		if len(elem.GetKey()) > 0 {
+			elems[i].Keys = elem.GetKey()
+		}
+		schemaPath.WriteByte('/')
+		schemaPath.WriteString(elem.GetName())
+	}
+	if schemaPath.Len() == 0 {
+		schemaPath.WriteByte('/')
+	}
+	doc["PathElems"] = elems
+	doc["SchemaPath"] = schemaPath.String()
+}
@@ -5,11 +5,14 @@
 package gnmi
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/aristanetworks/goarista/elasticsearch"
 	"github.com/aristanetworks/goarista/test"
 
+	"github.com/IBM/sarama"
 	"github.com/openconfig/gnmi/proto/gnmi"
 )
 
@@ -60,3 +63,159 @@ func TestJsonify(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeStructuredPath(t *testing.T) {
+	enc := &elasticsearchMessageEncoder{topic: "t", dataset: "foo"}
+	notif := &gnmi.Notification{
+		Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": "Ethernet1"}}}},
+		Delete: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "config"}}}},
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+				{Name: "state"}, {Name: "counters"}, {Name: "in-octets"}}},
+			Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 42}},
+		}},
+	}
+	messages, err := enc.Encode(&gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: notif}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		elems      []PathElem
+		schemaPath string
+	}{{
+		elems: []PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Keys: map[string]string{"name": "Ethernet1"}},
+			{Name: "config"}},
+		schemaPath: "/interfaces/interface/config",
+	}, {
+		elems: []PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Keys: map[string]string{"name": "Ethernet1"}},
+			{Name: "state"}, {Name: "counters"}, {Name: "in-octets"}},
+		schemaPath: "/interfaces/interface/state/counters/in-octets",
+	}}
+	if len(messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(messages))
+	}
+	for i, msg := range messages {
+		b, err := msg.Value.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			PathElems  []PathElem
+			SchemaPath string
+		}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if diff := test.Diff(expected[i].elems, doc.PathElems); diff != "" {
+			t.Errorf("message %d: unexpected PathElems: %s", i, diff)
+		}
+		if doc.SchemaPath != expected[i].schemaPath {
+			t.Errorf("message %d: expected SchemaPath %q, got %q",
+				i, expected[i].schemaPath, doc.SchemaPath)
+		}
+	}
+}
+
+func TestEncodeGetResponse(t *testing.T) {
+	enc := &elasticsearchMessageEncoder{topic: "t", dataset: "foo"}
+	notif := func(name string) *gnmi.Notification {
+		return &gnmi.Notification{
+			Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: name}}},
+			Update: []*gnmi.Update{{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "value"}}},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 42}},
+			}},
+		}
+	}
+	messages, err := enc.Encode(&gnmi.GetResponse{
+		Notification: []*gnmi.Notification{notif("a"), notif("b")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	for i, schemaPath := range []string{"/a/value", "/b/value"} {
+		b, err := messages[i].Value.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc["SchemaPath"] != schemaPath {
+			t.Errorf("expected schema path %s, got %v", schemaPath, doc["SchemaPath"])
+		}
+	}
+
+	if _, err := enc.Encode(&gnmi.CapabilityResponse{}); err == nil {
+		t.Error("expected an error for a CapabilityResponse")
+	}
+}
+
+func TestEncoderKeyMode(t *testing.T) {
+	notif := &gnmi.Notification{
+		Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "Sysdb"}}},
+		Delete: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "c"}}}},
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a"}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 1}},
+		}, {
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "b"}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 2}},
+		}},
+	}
+	resp := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notif}}
+	keys := func(mode KeyMode) []string {
+		msgs, err := NewEncoderWithKeyMode("topic", sarama.StringEncoder("dut"), "dut",
+			mode).Encode(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, m := range msgs {
+			b, err := m.Key.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys = append(keys, string(b))
+		}
+		return keys
+	}
+
+	if k := keys(KeyDevice); !test.DeepEqual([]string{"dut", "dut", "dut"}, k) {
+		t.Errorf("unexpected device keys %q", k)
+	}
+	if k := keys(KeyPath); !test.DeepEqual([]string{"/Sysdb/c", "/Sysdb/a", "/Sysdb/b"}, k) {
+		t.Errorf("unexpected path keys %q", k)
+	}
+	hashKeys := keys(KeyDevicePathHash)
+	if len(hashKeys) != 3 || hashKeys[0] == hashKeys[1] || hashKeys[1] == hashKeys[2] {
+		t.Fatalf("unexpected device+path-hash keys %q", hashKeys)
+	}
+	for _, k := range hashKeys {
+		if !strings.HasPrefix(k, "dut:") {
+			t.Errorf("expected the device key prefix in %q", k)
+		}
+	}
+	if k := keys(KeyDevicePathHash); !test.DeepEqual(hashKeys, k) {
+		t.Errorf("expected stable keys %q, got %q", hashKeys, k)
+	}
+
+	for _, mode := range []KeyMode{KeyDevice, KeyPath, KeyDevicePathHash} {
+		if m, err := ParseKeyMode(mode.String()); err != nil || m != mode {
+			t.Errorf("ParseKeyMode(%q): got %v, %v", mode, m, err)
+		}
+	}
+	if _, err := ParseKeyMode("topic"); err == nil {
+		t.Error("expected an error for an unknown key mode")
+	}
+}
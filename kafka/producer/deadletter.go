@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package producer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ErrorHeader is the Kafka record header carrying the encoding error of
+// a message written to a dead letter topic.
+const ErrorHeader = "error"
+
+// DeadLetterQueue stores the raw proto messages that could not be encoded,
+// so they can be replayed once the encoder is fixed.
+type DeadLetterQueue interface {
+	// Put stores a message that failed to encode with the given error.
+	Put(msg proto.Message, err error) error
+	Close() error
+}
+
+// MarshalDeadLetter returns the serialized form of a dead letter. The message is
+// wrapped in an Any so its type can be recovered by UnmarshalDeadLetter.
+func MarshalDeadLetter(msg proto.Message) ([]byte, error) {
+	a, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(a)
+}
+
+// UnmarshalDeadLetter returns the proto message serialized by MarshalDeadLetter.
+// The message type must be linked into the binary.
+func UnmarshalDeadLetter(b []byte) (proto.Message, error) {
+	var a anypb.Any
+	if err := proto.Unmarshal(b, &a); err != nil {
+		return nil, err
+	}
+	return a.UnmarshalNew()
+}
+
+type fileDeadLetterQueue struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *bufio.Writer
+}
+
+// NewFileDeadLetterQueue returns a DeadLetterQueue appending dead letters to
+// the file at path, creating it if needed. Dead letters are stored as
+// size-delimited Any messages and can be read back with ReadDeadLetters.
+func NewFileDeadLetterQueue(path string) (DeadLetterQueue, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileDeadLetterQueue{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (q *fileDeadLetterQueue) Put(msg proto.Message, _ error) error {
+	a, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, err := protodelim.MarshalTo(q.w, a); err != nil {
+		return err
+	}
+	// Flush every message so dead letters survive a crash of the producer.
+	return q.w.Flush()
+}
+
+func (q *fileDeadLetterQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return errors.Join(q.w.Flush(), q.f.Close())
+}
+
+// ReadDeadLetters reads the dead letters written by a file DeadLetterQueue
+// from r and calls fn for each of them, stopping at the first error.
+func ReadDeadLetters(r io.Reader, fn func(proto.Message) error) error {
+	br := bufio.NewReader(r)
+	for {
+		var a anypb.Any
+		if err := protodelim.UnmarshalFrom(br, &a); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		msg, err := a.UnmarshalNew()
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal dead letter of type %q: %s", a.TypeUrl, err)
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+}
+
+type topicDeadLetterQueue struct {
+	producer sarama.SyncProducer
+	topic    string
+	key      sarama.Encoder
+}
+
+// NewTopicDeadLetterQueue returns a DeadLetterQueue producing dead letters to
+// the given Kafka topic with the given key. The record value is the output of
+// MarshalDeadLetter and the encoding error is stored in the ErrorHeader header.
+func NewTopicDeadLetterQueue(addresses []string, topic string, key sarama.Encoder,
+	kafkaConfig *sarama.Config) (DeadLetterQueue, error) {
+	if kafkaConfig == nil {
+		kafkaConfig = sarama.NewConfig()
+		kafkaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	}
+	// Required by sarama.SyncProducer.
+	kafkaConfig.Producer.Return.Successes = true
+	kafkaConfig.Producer.Return.Errors = true
+
+	p, err := sarama.NewSyncProducer(addresses, kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &topicDeadLetterQueue{producer: p, topic: topic, key: key}, nil
+}
+
+func (q *topicDeadLetterQueue) Put(msg proto.Message, encodeErr error) error {
+	b, err := MarshalDeadLetter(msg)
+	if err != nil {
+		return err
+	}
+	m := &sarama.ProducerMessage{
+		Topic: q.topic,
+		Key:   q.key,
+		Value: sarama.ByteEncoder(b),
+	}
+	if encodeErr != nil {
+		m.Headers = []sarama.RecordHeader{{
+			Key:   []byte(ErrorHeader),
+			Value: []byte(encodeErr.Error()),
+		}}
+	}
+	_, _, err = q.producer.SendMessage(m)
+	return err
+}
+
+func (q *topicDeadLetterQueue) Close() error {
+	return q.producer.Close()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package producer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aristanetworks/goarista/kafka"
+
+	"github.com/IBM/sarama"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+type failingEncoder struct {
+	*kafka.BaseEncoder
+}
+
+func (e failingEncoder) Encode(proto.Message) ([]*sarama.ProducerMessage, error) {
+	return nil, errors.New("encode failure")
+}
+
+type chanDeadLetterQueue struct {
+	c chan proto.Message
+}
+
+func (q chanDeadLetterQueue) Put(msg proto.Message, err error) error {
+	q.c <- msg
+	return nil
+}
+
+func (q chanDeadLetterQueue) Close() error { return nil }
+
+func TestProducerDeadLetterQueue(t *testing.T) {
+	dlq := chanDeadLetterQueue{c: make(chan proto.Message, 1)}
+	p := &producer{
+		notifsChan:    make(chan proto.Message),
+		kafkaProducer: newMockAsyncProducer(),
+		encoder:       failingEncoder{kafka.NewBaseEncoder("test")},
+		deadLetters:   dlq,
+		done:          make(chan struct{}),
+	}
+	p.Start()
+	msg := &pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_Update{
+			Update: &pb.Notification{Prefix: newPath("/foo/bar")},
+		},
+	}
+	p.Write(msg)
+	if got := <-dlq.c; !proto.Equal(got, msg) {
+		t.Errorf("unexpected dead letter: got %v, expected %v", got, msg)
+	}
+	p.Stop()
+}
+
+func TestFileDeadLetterQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dlq")
+	msgs := []proto.Message{
+		&pb.SubscribeResponse{
+			Response: &pb.SubscribeResponse_Update{
+				Update: &pb.Notification{Timestamp: 1, Prefix: newPath("/foo/bar")},
+			},
+		},
+		&pb.SubscribeResponse{
+			Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true},
+		},
+	}
+	// Write the messages in two sessions to check that the file is appended to.
+	for _, msg := range msgs {
+		dlq, err := NewFileDeadLetterQueue(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := dlq.Put(msg, errors.New("encode failure")); err != nil {
+			t.Fatal(err)
+		}
+		if err := dlq.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []proto.Message
+	if err := ReadDeadLetters(f, func(msg proto.Message) error {
+		got = append(got, msg)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(msgs) {
+		t.Fatalf("expected %d dead letters, got %d", len(msgs), len(got))
+	}
+	for i := range msgs {
+		if !proto.Equal(got[i], msgs[i]) {
+			t.Errorf("dead letter %d: expected %v, got %v", i, msgs[i], got[i])
+		}
+	}
+}
+
+func TestMarshalDeadLetter(t *testing.T) {
+	msg := &pb.SubscribeResponse{
+		Response: &pb.SubscribeResponse_Update{
+			Update: &pb.Notification{Timestamp: 42, Prefix: newPath("/foo")},
+		},
+	}
+	b, err := MarshalDeadLetter(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalDeadLetter(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, msg) {
+		t.Errorf("expected %v, got %v", msg, got)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package producer
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aristanetworks/goarista/kafka"
+
+	"github.com/IBM/sarama"
+	"github.com/aristanetworks/glog"
+	"google.golang.org/protobuf/proto"
+)
+
+type protoEncoder[T proto.Message] struct {
+	kafka.MessageEncoder
+}
+
+// ProtoEncoder returns the Encoder[T] of a proto message type T, such as
+// *gnmi.SubscribeResponse or *gnmi.GetResponse, encoding with encoder.
+func ProtoEncoder[T proto.Message](encoder kafka.MessageEncoder) Encoder[T] {
+	return protoEncoder[T]{MessageEncoder: encoder}
+}
+
+func (e protoEncoder[T]) Encode(msg T) ([]*sarama.ProducerMessage, error) {
+	return e.MessageEncoder.Encode(msg)
+}
+
+type jsonEncoder[T any] struct {
+	*kafka.BaseEncoder
+	topic string
+	key   func(T) sarama.Encoder
+}
+
+// NewJSONEncoder returns an Encoder producing each message as one JSON
+// record to topic. If key is not nil, it returns the key of the records.
+func NewJSONEncoder[T any](topic string, key func(T) sarama.Encoder) Encoder[T] {
+	return &jsonEncoder[T]{
+		BaseEncoder: kafka.NewBaseEncoder("json"),
+		topic:       topic,
+		key:         key,
+	}
+}
+
+func (e *jsonEncoder[T]) Encode(msg T) ([]*sarama.ProducerMessage, error) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	glog.V(9).Infof("kafka: %s", b)
+	m := &sarama.ProducerMessage{
+		Topic:    e.topic,
+		Value:    sarama.ByteEncoder(b),
+		Metadata: kafka.Metadata{StartTime: time.Now(), NumMessages: 1},
+	}
+	if e.key != nil {
+		m.Key = e.key(msg)
+	}
+	return []*sarama.ProducerMessage{m}, nil
+}
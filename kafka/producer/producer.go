@@ -5,8 +5,12 @@
 package producer
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aristanetworks/goarista/kafka"
 	"github.com/aristanetworks/goarista/kafka/gnmi"
@@ -23,20 +27,111 @@ type Producer interface {
 	Stop()
 }
 
-type producer struct {
-	notifsChan    chan proto.Message
+// Drainer is implemented by the producers that can be stopped gracefully,
+// without losing the messages already written to them.
+type Drainer interface {
+	// Drain stops accepting writes, waits up to timeout for the messages
+	// handed to Kafka to be acknowledged and stops the producer. It returns
+	// an error if they were not all acknowledged in time.
+	Drain(timeout time.Duration) error
+	// Stats returns the counts of messages of the producer.
+	Stats() Stats
+}
+
+// Stats are the counts of messages of a producer.
+type Stats struct {
+	// Produced is the number of Kafka messages handed to Kafka.
+	Produced uint64
+	// Sent is the number of Kafka messages acknowledged by Kafka. It is
+	// only counted if the successes are returned by the Kafka config.
+	Sent uint64
+	// Failed is the number of Kafka messages that failed to be sent.
+	Failed uint64
+	// Dropped is the number of messages written after the producer was
+	// stopped or started draining.
+	Dropped uint64
+}
+
+// GenericProducer forwards the messages of type T written to it to Kafka,
+// encoded by an Encoder[T].
+type GenericProducer[T any] interface {
+	Start()
+	Write(T)
+	Stop()
+}
+
+// Encoder encodes the messages of type T written to a GenericProducer
+// into Kafka messages. kafka.MessageEncoder is an Encoder[proto.Message].
+type Encoder[T any] interface {
+	Encode(T) ([]*sarama.ProducerMessage, error)
+	HandleSuccess(*sarama.ProducerMessage)
+	HandleError(*sarama.ProducerError)
+}
+
+// GenericDeadLetterQueue stores the messages of type T that failed to
+// encode. DeadLetterQueue is a GenericDeadLetterQueue[proto.Message].
+type GenericDeadLetterQueue[T any] interface {
+	Put(msg T, err error) error
+	Close() error
+}
+
+// producer is the Producer of proto messages.
+type producer = genericProducer[proto.Message]
+
+var _ Drainer = (*producer)(nil)
+
+type genericProducer[T any] struct {
+	notifsChan    chan T
 	kafkaProducer sarama.AsyncProducer
-	encoder       kafka.MessageEncoder
+	encoder       Encoder[T]
+	deadLetters   GenericDeadLetterQueue[T]
 	done          chan struct{}
 	wg            sync.WaitGroup
+	// stopWrites is closed by Drain to stop accepting writes.
+	stopWrites chan struct{}
+	// runWg waits for run, which hands the messages to Kafka.
+	runWg    sync.WaitGroup
+	stopOnce sync.Once
+
+	produced atomic.Uint64
+	sent     atomic.Uint64
+	failed   atomic.Uint64
+	dropped  atomic.Uint64
 }
 
 // New creates new Kafka producer
 func New(encoder kafka.MessageEncoder,
 	kafkaAddresses []string, kafkaConfig *sarama.Config) (Producer, error) {
+	return NewWithDeadLetterQueue(encoder, kafkaAddresses, kafkaConfig, nil)
+}
+
+// NewWithDeadLetterQueue creates new Kafka producer which stores the messages
+// that the encoder fails to encode in deadLetters instead of aborting.
+// The producer takes ownership of deadLetters and closes it when stopped.
+func NewWithDeadLetterQueue(encoder kafka.MessageEncoder, kafkaAddresses []string,
+	kafkaConfig *sarama.Config, deadLetters DeadLetterQueue) (Producer, error) {
+	var dlq GenericDeadLetterQueue[proto.Message]
+	if deadLetters != nil {
+		dlq = deadLetters
+	}
+	return newGeneric[proto.Message](encoder, kafkaAddresses, kafkaConfig, dlq)
+}
+
+// NewGeneric creates a Kafka producer of the messages of type T, such as
+// *gnmi.GetResponse or any struct, encoded by encoder. If deadLetters is
+// not nil, the messages that the encoder fails to encode are stored in it
+// instead of aborting, and it is closed when the producer is stopped.
+func NewGeneric[T any](encoder Encoder[T], kafkaAddresses []string,
+	kafkaConfig *sarama.Config,
+	deadLetters GenericDeadLetterQueue[T]) (GenericProducer[T], error) {
+	return newGeneric(encoder, kafkaAddresses, kafkaConfig, deadLetters)
+}
 
+func newGeneric[T any](encoder Encoder[T], kafkaAddresses []string,
+	kafkaConfig *sarama.Config,
+	deadLetters GenericDeadLetterQueue[T]) (*genericProducer[T], error) {
 	if kafkaConfig == nil {
-		kafkaConfig := sarama.NewConfig()
+		kafkaConfig = sarama.NewConfig()
 		hostname, err := os.Hostname()
 		if err != nil {
 			hostname = ""
@@ -52,27 +147,30 @@ func New(encoder kafka.MessageEncoder,
 		return nil, err
 	}
 
-	p := &producer{
-		notifsChan:    make(chan proto.Message),
+	p := &genericProducer[T]{
+		notifsChan:    make(chan T),
 		kafkaProducer: kafkaProducer,
 		encoder:       encoder,
+		deadLetters:   deadLetters,
 		done:          make(chan struct{}),
 		wg:            sync.WaitGroup{},
+		stopWrites:    make(chan struct{}),
 	}
 	return p, nil
 }
 
 // Start makes producer to start processing writes.
 // This method is non-blocking.
-func (p *producer) Start() {
-	p.wg.Add(3)
+func (p *genericProducer[T]) Start() {
+	p.wg.Add(2)
+	p.runWg.Add(1)
 	go p.handleSuccesses()
 	go p.handleErrors()
 	go p.run()
 }
 
-func (p *producer) run() {
-	defer p.wg.Done()
+func (p *genericProducer[T]) run() {
+	defer p.runWg.Done()
 	for {
 		select {
 		case batch, open := <-p.notifsChan:
@@ -81,33 +179,121 @@ func (p *producer) run() {
 			}
 			err := p.produceNotifications(batch)
 			if err != nil {
-				if _, ok := err.(gnmi.UnhandledSubscribeResponseError); !ok {
+				if _, ok := err.(gnmi.UnhandledSubscribeResponseError); ok {
+					continue
+				}
+				if p.deadLetters == nil {
 					panic(err)
 				}
+				glog.Errorf("Failed to encode message, writing it to dead letter queue: %s", err)
+				if err := p.deadLetters.Put(batch, err); err != nil {
+					glog.Errorf("Failed to write message to dead letter queue: %s", err)
+				}
 			}
+		case <-p.stopWrites:
+			return
 		case <-p.done:
 			return
 		}
 	}
 }
 
-func (p *producer) Write(msg proto.Message) {
+func (p *genericProducer[T]) Write(msg T) {
 	select {
 	case p.notifsChan <- msg:
+	case <-p.stopWrites:
+		p.dropped.Add(1)
 	case <-p.done:
 		// TODO: This should probably return an EOF error, but that
 		// would change the API
+		p.dropped.Add(1)
 	}
 }
 
-func (p *producer) Stop() {
-	close(p.done)
-	p.wg.Wait()
-	p.kafkaProducer.Close()
+func (p *genericProducer[T]) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+		p.runWg.Wait()
+		p.kafkaProducer.Close()
+		p.closeDeadLetters()
+	})
+}
+
+// Drain stops accepting writes, lets the message being encoded be handed to
+// Kafka and waits up to timeout for Kafka to acknowledge the messages in
+// flight before stopping the producer.
+func (p *genericProducer[T]) Drain(timeout time.Duration) error {
+	err := errors.New("producer already stopped")
+	p.stopOnce.Do(func() {
+		err = p.drain(timeout)
+	})
+	return err
+}
+
+func (p *genericProducer[T]) drain(timeout time.Duration) error {
+	expired := time.After(timeout)
+	var err error
+	stop := func() {
+		s := p.Stats()
+		err = fmt.Errorf("%d messages not acknowledged by Kafka after %s",
+			s.Produced-s.Sent-s.Failed, timeout)
+		close(p.done)
+	}
+	close(p.stopWrites)
+	ran := make(chan struct{})
+	go func() {
+		p.runWg.Wait()
+		close(ran)
+	}()
+	select {
+	case <-ran:
+	case <-expired:
+		// Kafka does not even take the messages of the current write.
+		stop()
+		<-ran
+	}
+	// The successes and errors channels are closed once the messages in
+	// flight are flushed, ending handleSuccesses and handleErrors.
+	p.kafkaProducer.AsyncClose()
+	flushed := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(flushed)
+	}()
+	if err == nil {
+		select {
+		case <-flushed:
+			close(p.done)
+		case <-expired:
+			stop()
+		}
+	}
+	<-flushed
+	p.closeDeadLetters()
+	return err
+}
+
+// Stats returns the counts of messages of the producer.
+func (p *genericProducer[T]) Stats() Stats {
+	return Stats{
+		Produced: p.produced.Load(),
+		Sent:     p.sent.Load(),
+		Failed:   p.failed.Load(),
+		Dropped:  p.dropped.Load(),
+	}
+}
+
+func (p *genericProducer[T]) closeDeadLetters() {
+	if p.deadLetters != nil {
+		if err := p.deadLetters.Close(); err != nil {
+			glog.Errorf("Failed to close dead letter queue: %s", err)
+		}
+	}
 }
 
-func (p *producer) produceNotifications(protoMessage proto.Message) error {
-	messages, err := p.encoder.Encode(protoMessage)
+func (p *genericProducer[T]) produceNotifications(msg T) error {
+	messages, err := p.encoder.Encode(msg)
 	if err != nil {
 		return err
 	}
@@ -116,6 +302,7 @@ func (p *producer) produceNotifications(protoMessage proto.Message) error {
 		case <-p.done:
 			return nil
 		case p.kafkaProducer.Input() <- m:
+			p.produced.Add(1)
 			glog.V(9).Infof("Message produced to Kafka: %v", m)
 		}
 	}
@@ -124,7 +311,7 @@ func (p *producer) produceNotifications(protoMessage proto.Message) error {
 
 // handleSuccesses reads from the producer's successes channel and collects some
 // information for monitoring
-func (p *producer) handleSuccesses() {
+func (p *genericProducer[T]) handleSuccesses() {
 	defer p.wg.Done()
 	for {
 		select {
@@ -132,6 +319,7 @@ func (p *producer) handleSuccesses() {
 			if !open {
 				return
 			}
+			p.sent.Add(1)
 			p.encoder.HandleSuccess(msg)
 		case <-p.done:
 			return
@@ -141,7 +329,7 @@ func (p *producer) handleSuccesses() {
 
 // handleErrors reads from the producer's errors channel and collects some information
 // for monitoring
-func (p *producer) handleErrors() {
+func (p *genericProducer[T]) handleErrors() {
 	defer p.wg.Done()
 	for {
 		select {
@@ -149,6 +337,7 @@ func (p *producer) handleErrors() {
 			if !open {
 				return
 			}
+			p.failed.Add(1)
 			p.encoder.HandleError(msg)
 		case <-p.done:
 			return
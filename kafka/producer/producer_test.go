@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aristanetworks/goarista/kafka/gnmi"
 	"github.com/aristanetworks/goarista/test"
@@ -22,6 +23,8 @@ type mockAsyncProducer struct {
 	input     chan *sarama.ProducerMessage
 	successes chan *sarama.ProducerMessage
 	errors    chan *sarama.ProducerError
+	// stuck makes AsyncClose never flush the messages.
+	stuck bool
 }
 
 func newMockAsyncProducer() *mockAsyncProducer {
@@ -32,7 +35,9 @@ func newMockAsyncProducer() *mockAsyncProducer {
 }
 
 func (p *mockAsyncProducer) AsyncClose() {
-	panic("Not implemented")
+	if !p.stuck {
+		p.Close()
+	}
 }
 
 func (p *mockAsyncProducer) Close() error {
@@ -234,3 +239,89 @@ func TestProducerStartStop(t *testing.T) {
 	close(done)
 	wg.Wait()
 }
+
+type record struct {
+	Device string `json:"device"`
+	Value  int    `json:"value"`
+}
+
+func TestGenericProducer(t *testing.T) {
+	mock := newMockAsyncProducer()
+	p := &genericProducer[record]{
+		notifsChan:    make(chan record),
+		kafkaProducer: mock,
+		encoder: NewJSONEncoder("records", func(r record) sarama.Encoder {
+			return sarama.StringEncoder(r.Device)
+		}),
+		done: make(chan struct{}),
+	}
+	p.Start()
+	go p.Write(record{Device: "dev1", Value: 42})
+
+	m := <-mock.input
+	if m.Topic != "records" {
+		t.Errorf("unexpected topic %q", m.Topic)
+	}
+	key, _ := m.Key.Encode()
+	value, _ := m.Value.Encode()
+	if string(key) != "dev1" || string(value) != `{"device":"dev1","value":42}` {
+		t.Errorf("unexpected message %q: %q", key, value)
+	}
+	p.Stop()
+}
+
+func TestProducerDrain(t *testing.T) {
+	for name, tc := range map[string]struct {
+		stuck    bool
+		expected Stats
+		err      bool
+	}{
+		"flushed": {
+			expected: Stats{Produced: 2, Sent: 1, Failed: 1, Dropped: 1},
+		},
+		"timeout": {
+			stuck:    true,
+			expected: Stats{Produced: 2, Dropped: 1},
+			err:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock := newMockAsyncProducer()
+			mock.stuck = tc.stuck
+			p := &genericProducer[record]{
+				notifsChan:    make(chan record),
+				kafkaProducer: mock,
+				encoder: NewJSONEncoder("records", func(r record) sarama.Encoder {
+					return sarama.StringEncoder(r.Device)
+				}),
+				done:       make(chan struct{}),
+				stopWrites: make(chan struct{}),
+			}
+			p.Start()
+			go func() {
+				p.Write(record{Device: "dev1", Value: 1})
+				p.Write(record{Device: "dev2", Value: 2})
+			}()
+			m1, m2 := <-mock.input, <-mock.input
+			if !tc.stuck {
+				mock.successes <- m1
+				mock.errors <- &sarama.ProducerError{Msg: m2}
+			}
+
+			err := p.Drain(10 * time.Millisecond)
+			if tc.err && err == nil {
+				t.Error("expected a timeout")
+			} else if !tc.err && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			p.Write(record{Device: "dev3", Value: 3})
+			if s := p.Stats(); s != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, s)
+			}
+			if err := p.Drain(time.Second); err == nil {
+				t.Error("expected an error draining twice")
+			}
+			p.Stop()
+		})
+	}
+}
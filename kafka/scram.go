@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package kafka
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// scramClient implements sarama.SCRAMClient, the client side of the
+// SCRAM authentication of RFC 5802. The user name and password are
+// expected to be in their normalized form, SASLprep is not applied.
+type scramClient struct {
+	newHash func() hash.Hash
+	// nonce returns the client nonce, it is overridden in tests.
+	nonce func() (string, error)
+
+	step            int
+	gs2Header       string
+	clientNonce     string
+	password        string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func newSCRAMClientGenerator(newHash func() hash.Hash) func() sarama.SCRAMClient {
+	return func() sarama.SCRAMClient {
+		return &scramClient{newHash: newHash, nonce: randomNonce}
+	}
+}
+
+var (
+	scramSHA256 = newSCRAMClientGenerator(sha256.New)
+	scramSHA512 = newSCRAMClientGenerator(sha512.New)
+)
+
+func randomNonce() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(b), nil
+}
+
+// scramName escapes a user name as per RFC 5802 section 5.1.
+func scramName(name string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(name)
+}
+
+// Begin implements sarama.SCRAMClient.
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	nonce, err := c.nonce()
+	if err != nil {
+		return err
+	}
+	c.step = 0
+	c.clientNonce = nonce
+	c.password = password
+	c.gs2Header = "n,,"
+	if authzID != "" {
+		c.gs2Header = "n,a=" + scramName(authzID) + ","
+	}
+	c.clientFirstBare = "n=" + scramName(userName) + ",r=" + nonce
+	return nil
+}
+
+// Step implements sarama.SCRAMClient.
+func (c *scramClient) Step(challenge string) (string, error) {
+	c.step++
+	switch c.step {
+	case 1:
+		return c.gs2Header + c.clientFirstBare, nil
+	case 2:
+		return c.clientFinal(challenge)
+	case 3:
+		return "", c.verifyServerFinal(challenge)
+	default:
+		return "", errors.New("SCRAM exchange already done")
+	}
+}
+
+// Done implements sarama.SCRAMClient.
+func (c *scramClient) Done() bool {
+	return c.step >= 3
+}
+
+func (c *scramClient) hmac(key []byte, msg string) []byte {
+	h := hmac.New(c.newHash, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+// scramAttributes parses the attributes of a SCRAM message.
+func scramAttributes(msg string) map[byte]string {
+	attrs := map[byte]string{}
+	for _, attr := range strings.Split(msg, ",") {
+		if len(attr) >= 2 && attr[1] == '=' {
+			attrs[attr[0]] = attr[2:]
+		}
+	}
+	return attrs
+}
+
+func (c *scramClient) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttributes(serverFirst)
+	if e, ok := attrs['e']; ok {
+		return "", fmt.Errorf("SCRAM authentication failed: %s", e)
+	}
+	nonce := attrs['r']
+	if !strings.HasPrefix(nonce, c.clientNonce) || len(nonce) == len(c.clientNonce) {
+		return "", errors.New("invalid SCRAM server nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(attrs['s'])
+	if err != nil {
+		return "", fmt.Errorf("invalid SCRAM salt: %s", err)
+	}
+	iterations, err := strconv.Atoi(attrs['i'])
+	if err != nil || iterations <= 0 {
+		return "", fmt.Errorf("invalid SCRAM iteration count %q", attrs['i'])
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(c.password), salt, iterations,
+		c.newHash().Size(), c.newHash)
+	clientKey := c.hmac(saltedPassword, "Client Key")
+	h := c.newHash()
+	h.Write(clientKey)
+	storedKey := h.Sum(nil)
+
+	clientFinalWithoutProof := "c=" +
+		base64.StdEncoding.EncodeToString([]byte(c.gs2Header)) + ",r=" + nonce
+	authMessage := c.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof
+	clientSignature := c.hmac(storedKey, authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	c.serverSignature = c.hmac(c.hmac(saltedPassword, "Server Key"), authMessage)
+	return clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+func (c *scramClient) verifyServerFinal(serverFinal string) error {
+	attrs := scramAttributes(serverFinal)
+	if e, ok := attrs['e']; ok {
+		return fmt.Errorf("SCRAM authentication failed: %s", e)
+	}
+	signature, err := base64.StdEncoding.DecodeString(attrs['v'])
+	if err != nil {
+		return fmt.Errorf("invalid SCRAM server signature: %s", err)
+	}
+	if !hmac.Equal(signature, c.serverSignature) {
+		return errors.New("SCRAM server signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package kafka
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+// TestSCRAMSHA256 runs the example exchange of RFC 7677 section 3.
+func TestSCRAMSHA256(t *testing.T) {
+	c := &scramClient{
+		newHash: sha256.New,
+		nonce:   func() (string, error) { return "rOprNGfwEbeRWgbNEkqO", nil },
+	}
+	if err := c.Begin("user", "pencil", ""); err != nil {
+		t.Fatal(err)
+	}
+	for i, step := range []struct {
+		challenge string
+		response  string
+	}{{
+		response: "n,,n=user,r=rOprNGfwEbeRWgbNEkqO",
+	}, {
+		challenge: "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096",
+		response: "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=",
+	}, {
+		challenge: "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=",
+	}} {
+		if c.Done() {
+			t.Fatalf("step %d: exchange done too early", i)
+		}
+		response, err := c.Step(step.challenge)
+		if err != nil {
+			t.Fatalf("step %d: %s", i, err)
+		}
+		if response != step.response {
+			t.Errorf("step %d: expected response %q, got %q", i, step.response, response)
+		}
+	}
+	if !c.Done() {
+		t.Error("expected the exchange to be done")
+	}
+
+	// A server that does not know the password cannot sign the exchange.
+	if err := c.Begin("user", "pencil", ""); err != nil {
+		t.Fatal(err)
+	}
+	c.Step("")
+	c.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+		"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	if _, err := c.Step("v=AAAA"); err == nil {
+		t.Error("expected an error for a wrong server signature")
+	}
+
+	// The server nonce must extend the client nonce.
+	if err := c.Begin("user", "pencil", ""); err != nil {
+		t.Fatal(err)
+	}
+	c.Step("")
+	if _, err := c.Step("r=other,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"); err == nil {
+		t.Error("expected an error for an invalid server nonce")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/IBM/sarama"
+)
+
+// Security configures the TLS and SASL authentication with the brokers.
+type Security struct {
+	// TLS enables TLS. It is implied by CAFile, CertFile and KeyFile.
+	TLS bool
+	// CAFile verifies the brokers, defaulting to the system roots.
+	CAFile string
+	// CertFile and KeyFile authenticate the client with TLS.
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify disables the verification of the brokers.
+	InsecureSkipVerify bool
+
+	// SASLMechanism is one of sarama.SASLTypePlaintext ("PLAIN"),
+	// sarama.SASLTypeSCRAMSHA256 ("SCRAM-SHA-256") or
+	// sarama.SASLTypeSCRAMSHA512 ("SCRAM-SHA-512"). Empty disables SASL.
+	SASLMechanism string
+	SASLUser      string
+	SASLPassword  string
+}
+
+// SecurityFromFlags returns the Security set by the command-line flags.
+func SecurityFromFlags() *Security {
+	return &Security{
+		TLS:                *tlsFlag,
+		CAFile:             *caFileFlag,
+		CertFile:           *certFileFlag,
+		KeyFile:            *keyFileFlag,
+		InsecureSkipVerify: *tlsSkipVerifyFlag,
+		SASLMechanism:      *saslMechanismFlag,
+		SASLUser:           *saslUserFlag,
+		SASLPassword:       *saslPasswordFlag,
+	}
+}
+
+// Apply configures config with s.
+func (s *Security) Apply(config *sarama.Config) error {
+	if s.TLS || s.CAFile != "" || s.CertFile != "" || s.KeyFile != "" || s.InsecureSkipVerify {
+		tlsConfig, err := s.tlsConfig()
+		if err != nil {
+			return err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	switch s.SASLMechanism {
+	case "":
+		return nil
+	case sarama.SASLTypePlaintext:
+	case sarama.SASLTypeSCRAMSHA256:
+		config.Net.SASL.SCRAMClientGeneratorFunc = scramSHA256
+	case sarama.SASLTypeSCRAMSHA512:
+		config.Net.SASL.SCRAMClientGeneratorFunc = scramSHA512
+	default:
+		return fmt.Errorf("unsupported SASL mechanism %q", s.SASLMechanism)
+	}
+	if s.SASLUser == "" {
+		return errors.New("SASL authentication requires a user")
+	}
+	if s.SASLMechanism == sarama.SASLTypePlaintext && !config.Net.TLS.Enable {
+		return errors.New("SASL PLAIN authentication requires TLS")
+	}
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Mechanism = sarama.SASLMechanism(s.SASLMechanism)
+	config.Net.SASL.User = s.SASLUser
+	config.Net.SASL.Password = s.SASLPassword
+	return nil
+}
+
+func (s *Security) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: s.InsecureSkipVerify}
+	if s.CAFile != "" {
+		b, err := os.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("failed to parse CA certificates in %s", s.CAFile)
+		}
+	}
+	if s.CertFile != "" || s.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestSecurityApply(t *testing.T) {
+	config := sarama.NewConfig()
+	s := &Security{
+		InsecureSkipVerify: true,
+		SASLMechanism:      sarama.SASLTypeSCRAMSHA512,
+		SASLUser:           "user",
+		SASLPassword:       "pass",
+	}
+	if err := s.Apply(config); err != nil {
+		t.Fatal(err)
+	}
+	if !config.Net.TLS.Enable || !config.Net.TLS.Config.InsecureSkipVerify {
+		t.Error("expected TLS to be enabled without verification")
+	}
+	if !config.Net.SASL.Enable || config.Net.SASL.Mechanism != sarama.SASLTypeSCRAMSHA512 ||
+		config.Net.SASL.User != "user" || config.Net.SASL.Password != "pass" {
+		t.Errorf("unexpected SASL config: %+v", config.Net.SASL)
+	}
+	if config.Net.SASL.SCRAMClientGeneratorFunc == nil {
+		t.Error("expected a SCRAM client generator")
+	}
+	if err := config.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	for name, s := range map[string]*Security{
+		"unknown mechanism": {SASLMechanism: "GSSAPI", SASLUser: "user"},
+		"no user":           {SASLMechanism: sarama.SASLTypeSCRAMSHA256},
+		"plain without TLS": {SASLMechanism: sarama.SASLTypePlaintext, SASLUser: "user"},
+		"missing CA":        {CAFile: "/nonexistent/ca.pem"},
+	} {
+		if err := s.Apply(sarama.NewConfig()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+type addrKey netip.Addr
+type prefixKey netip.Prefix
+
+// hardwareAddrKey holds the bytes of a net.HardwareAddr as a string
+// so that it is comparable.
+type hardwareAddrKey string
+
+// Key interface implementation for netip.Addr
+func (k addrKey) Key() interface{} {
+	return netip.Addr(k)
+}
+
+func (k addrKey) String() string {
+	return netip.Addr(k).String()
+}
+
+func (k addrKey) GoString() string {
+	return fmt.Sprintf("key.New(netip.MustParseAddr(%q))", netip.Addr(k).String())
+}
+
+func (k addrKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(netip.Addr(k).String())
+}
+
+func (k addrKey) Equal(other interface{}) bool {
+	o, ok := other.(addrKey)
+	return ok && k == o
+}
+
+// Key interface implementation for netip.Prefix
+func (k prefixKey) Key() interface{} {
+	return netip.Prefix(k)
+}
+
+func (k prefixKey) String() string {
+	return netip.Prefix(k).String()
+}
+
+func (k prefixKey) GoString() string {
+	return fmt.Sprintf("key.New(netip.MustParsePrefix(%q))", netip.Prefix(k).String())
+}
+
+func (k prefixKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(netip.Prefix(k).String())
+}
+
+func (k prefixKey) Equal(other interface{}) bool {
+	o, ok := other.(prefixKey)
+	return ok && k == o
+}
+
+// Key interface implementation for net.HardwareAddr
+func (k hardwareAddrKey) Key() interface{} {
+	return net.HardwareAddr(k)
+}
+
+func (k hardwareAddrKey) String() string {
+	return net.HardwareAddr(k).String()
+}
+
+func (k hardwareAddrKey) GoString() string {
+	return fmt.Sprintf("key.New(net.HardwareAddr(%q))", []byte(k))
+}
+
+func (k hardwareAddrKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(net.HardwareAddr(k).String())
+}
+
+func (k hardwareAddrKey) Equal(other interface{}) bool {
+	o, ok := other.(hardwareAddrKey)
+	return ok && k == o
+}
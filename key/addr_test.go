@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/maphash"
+	"net"
+	"net/netip"
+	"testing"
+
+	. "github.com/aristanetworks/goarista/key"
+)
+
+func TestAddrKeys(t *testing.T) {
+	mac, err := net.ParseMAC("00:1c:73:01:02:03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherMAC, err := net.ParseMAC("00:1c:73:01:02:04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		val   interface{}
+		same  interface{}
+		other interface{}
+		str   string
+		json  string
+		goStr string
+	}{{
+		val:   netip.MustParseAddr("10.0.0.1"),
+		same:  netip.MustParseAddr("10.0.0.1"),
+		other: netip.MustParseAddr("::ffff:10.0.0.1"),
+		str:   "10.0.0.1",
+		json:  `"10.0.0.1"`,
+		goStr: `key.New(netip.MustParseAddr("10.0.0.1"))`,
+	}, {
+		val:   netip.MustParseAddr("fe80::1%eth0"),
+		same:  netip.MustParseAddr("fe80::1%eth0"),
+		other: netip.MustParseAddr("fe80::1"),
+		str:   "fe80::1%eth0",
+		json:  `"fe80::1%eth0"`,
+		goStr: `key.New(netip.MustParseAddr("fe80::1%eth0"))`,
+	}, {
+		val:   netip.MustParsePrefix("10.0.0.0/8"),
+		same:  netip.MustParsePrefix("10.0.0.0/8"),
+		other: netip.MustParsePrefix("10.0.0.0/16"),
+		str:   "10.0.0.0/8",
+		json:  `"10.0.0.0/8"`,
+		goStr: `key.New(netip.MustParsePrefix("10.0.0.0/8"))`,
+	}, {
+		val:   mac,
+		same:  append(net.HardwareAddr(nil), mac...),
+		other: otherMAC,
+		str:   "00:1c:73:01:02:03",
+		json:  `"00:1c:73:01:02:03"`,
+		goStr: `key.New(net.HardwareAddr("\x00\x1cs\x01\x02\x03"))`,
+	}}
+	seed := maphash.MakeSeed()
+	for _, tc := range tests {
+		t.Run(tc.str, func(t *testing.T) {
+			k := New(tc.val)
+			if !Equal(k.Key(), tc.val) {
+				t.Errorf("Key() returned %#v, expected %#v", k.Key(), tc.val)
+			}
+			if !k.Equal(New(tc.same)) {
+				t.Errorf("expected %v to equal %v", k, tc.same)
+			}
+			if k.Equal(New(tc.other)) {
+				t.Errorf("expected %v to not equal %v", k, tc.other)
+			}
+			if k.Equal(New(tc.str)) {
+				t.Errorf("expected %v to not equal string key", k)
+			}
+			if Hash(seed, k) != Hash(seed, New(tc.same)) {
+				t.Errorf("hash mismatch for equal keys %v", k)
+			}
+			if HashInterface(tc.val) != HashInterface(tc.same) {
+				t.Errorf("HashInterface mismatch for equal values %v", k)
+			}
+			if s := k.String(); s != tc.str {
+				t.Errorf("String() returned %q, expected %q", s, tc.str)
+			}
+			if s := StringKey(k); s != tc.str {
+				t.Errorf("StringKey() returned %q, expected %q", s, tc.str)
+			}
+			if s := fmt.Sprintf("%#v", k); s != tc.goStr {
+				t.Errorf("GoString() returned %s, expected %s", s, tc.goStr)
+			}
+			b, err := json.Marshal(k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.json {
+				t.Errorf("MarshalJSON() returned %s, expected %s", b, tc.json)
+			}
+
+			m := NewMap(k, "value")
+			if v, ok := m.Get(New(tc.same)); !ok || v != "value" {
+				t.Errorf("failed to get %v from Map", k)
+			}
+			gm := map[Key]string{k: "value"}
+			if v, ok := gm[New(tc.same)]; !ok || v != "value" {
+				t.Errorf("failed to get %v from Go map", k)
+			}
+		})
+	}
+}
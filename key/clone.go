@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key
+
+import "sync/atomic"
+
+// frozen makes New and TryNew copy the maps and slices they wrap.
+var frozen atomic.Bool
+
+// SetFrozen enables or disables the frozen mode, in which New and TryNew
+// wrap a deep copy of the maps and slices given to them, making the Keys
+// immutable: mutating a map after creating a Key from it does not change
+// the Key, whose hash would otherwise no longer match in the maps it was
+// inserted in. The mode costs a copy per Key created from a map or a
+// slice, and is meant to be enabled in tests or while chasing aliasing
+// bugs.
+func SetFrozen(enabled bool) {
+	frozen.Store(enabled)
+}
+
+// Frozen returns whether the frozen mode is enabled.
+func Frozen() bool {
+	return frozen.Load()
+}
+
+// NewFrozen is like New but always wraps a deep copy of the maps and
+// slices of intf, whether or not the frozen mode is enabled.
+func NewFrozen(intf interface{}) Key {
+	return New(cloneValue(intf))
+}
+
+// Clone returns a deep copy of k: the maps and slices of the keys wrapping
+// them, including those of the elements of Path and Pointer keys, are
+// copied. Other keys are immutable and returned as is.
+func Clone(k Key) Key {
+	switch k := k.(type) {
+	case mapKey:
+		return mapKey(cloneMap(k))
+	case sliceKey:
+		return sliceKey(cloneSlice(k))
+	case pathKey:
+		return pathKey{sliceKey(cloneSlice(k.sliceKey))}
+	case pointerKey:
+		return pointerKey{sliceKey(cloneSlice(k.sliceKey))}
+	}
+	return k
+}
+
+// cloneValue returns a deep copy of the maps and slices of v, which may be
+// wrapped in Keys.
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		return cloneSlice(v)
+	case Path:
+		if v == nil {
+			return v
+		}
+		p := make(Path, len(v))
+		for i, k := range v {
+			p[i] = Clone(k)
+		}
+		return p
+	case Pointer:
+		return pointer(cloneValue(v.Pointer()).(Path))
+	case Key:
+		return Clone(v)
+	}
+	return v
+}
+
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = cloneValue(v)
+	}
+	return c
+}
+
+func cloneSlice(s []interface{}) []interface{} {
+	if s == nil {
+		return nil
+	}
+	c := make([]interface{}, len(s))
+	for i, v := range s {
+		c[i] = cloneValue(v)
+	}
+	return c
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key_test
+
+import (
+	"testing"
+
+	. "github.com/aristanetworks/goarista/key"
+)
+
+func TestClone(t *testing.T) {
+	m := map[string]interface{}{"a": "1", "b": []interface{}{"x"},
+		"c": map[string]interface{}{"d": int64(2)}}
+	s := []interface{}{"y", map[string]interface{}{"e": true}}
+	elem := map[string]interface{}{"name": "Ethernet1"}
+	path := Path{New("interface"), New(elem)}
+	for name, tc := range map[string]struct {
+		key    Key
+		mutate func()
+	}{
+		"map": {
+			key: New(m),
+			mutate: func() {
+				m["b"].([]interface{})[0] = "z"
+				m["c"].(map[string]interface{})["d"] = int64(3)
+				m["f"] = "new"
+			},
+		},
+		"slice": {
+			key: New(s),
+			mutate: func() {
+				s[0] = "z"
+				s[1].(map[string]interface{})["e"] = false
+			},
+		},
+		"path": {
+			key:    New(path),
+			mutate: func() { elem["name"] = "Ethernet2" },
+		},
+		"pointer": {
+			key:    New(NewPointer(path)),
+			mutate: func() { elem["name"] = "Ethernet3" },
+		},
+		"string": {
+			key:    New("foo"),
+			mutate: func() {},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clone := Clone(tc.key)
+			if !clone.Equal(tc.key) || !tc.key.Equal(clone) {
+				t.Fatalf("clone %s is not equal to %s", clone, tc.key)
+			}
+			before := clone.String()
+			tc.mutate()
+			if s := clone.String(); s != before {
+				t.Errorf("clone changed from %s to %s", before, s)
+			}
+		})
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	m := map[string]interface{}{"a": map[string]interface{}{"b": "c"}}
+	k := NewFrozen(m)
+	m["a"].(map[string]interface{})["b"] = "d"
+	if expected := New(map[string]interface{}{"a": map[string]interface{}{"b": "c"}}); !k.Equal(
+		expected) {
+		t.Errorf("expected %s, got %s", expected, k)
+	}
+
+	if Frozen() {
+		t.Fatal("the frozen mode is enabled by default")
+	}
+	SetFrozen(true)
+	defer SetFrozen(false)
+	m = map[string]interface{}{"a": "1"}
+	k = New(m)
+	m["a"] = "2"
+	if expected := New(map[string]interface{}{"a": "1"}); !k.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, k)
+	}
+	km := NewMap(k, "value")
+	if v, ok := km.Get(New(map[string]interface{}{"a": "1"})); !ok || v != "value" {
+		t.Errorf("expected to find %s in the map, got %v, %t", k, v, ok)
+	}
+}
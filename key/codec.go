@@ -0,0 +1,402 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"sort"
+)
+
+// Type tags of the binary encoding. The values are part of the encoding
+// and must not change.
+const (
+	tagNil byte = iota
+	tagBool
+	tagString
+	tagBytes
+	tagInt8
+	tagInt16
+	tagInt32
+	tagInt64
+	tagUint8
+	tagUint16
+	tagUint32
+	tagUint64
+	tagFloat32
+	tagFloat64
+	tagMap
+	tagSlice
+	tagPath
+	tagPointer
+	tagAddr
+	tagPrefix
+	tagHardwareAddr
+)
+
+var errShortBuffer = errors.New("key: truncated binary encoding")
+
+// EncodeBinary returns the binary encoding of k. The encoding is
+// canonical: equal keys have the same encoding. Each key starts with a
+// type tag, strings and collections are prefixed with their length, and
+// the entries of maps are sorted. Keys wrapping a value.Value cannot be
+// encoded.
+func EncodeBinary(k Key) ([]byte, error) {
+	return appendKey(nil, k)
+}
+
+// DecodeBinary decodes a key encoded by EncodeBinary.
+func DecodeBinary(b []byte) (Key, error) {
+	v, rest, err := decodeValue(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("key: %d trailing bytes after binary encoding", len(rest))
+	}
+	return TryNew(v)
+}
+
+// EncodeText returns the binary encoding of k in unpadded base64url, which
+// can be used in JSON object keys, URLs or file names.
+func EncodeText(k Key) (string, error) {
+	b, err := EncodeBinary(k)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeText decodes a key encoded by EncodeText.
+func DecodeText(s string) (Key, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("key: invalid text encoding: %s", err)
+	}
+	return DecodeBinary(b)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the
+// number of elements of p followed by their EncodeBinary encoding.
+func (p Path) MarshalBinary() ([]byte, error) {
+	return appendPath(nil, p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *Path) UnmarshalBinary(b []byte) error {
+	path, rest, err := decodePath(b)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("key: %d trailing bytes after binary encoding", len(rest))
+	}
+	*p = path
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the binary
+// encoding of p in unpadded base64url. Path.MarshalJSON is unchanged.
+func (p Path) MarshalText() ([]byte, error) {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, base64.RawURLEncoding.EncodedLen(len(b)))
+	base64.RawURLEncoding.Encode(text, b)
+	return text, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Path) UnmarshalText(text []byte) error {
+	b := make([]byte, base64.RawURLEncoding.DecodedLen(len(text)))
+	n, err := base64.RawURLEncoding.Decode(b, text)
+	if err != nil {
+		return fmt.Errorf("key: invalid text encoding: %s", err)
+	}
+	return p.UnmarshalBinary(b[:n])
+}
+
+func appendPath(b []byte, p Path) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(p)))
+	for _, k := range p {
+		var err error
+		if b, err = appendKey(b, k); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendString(b []byte, tag byte, s string) []byte {
+	b = append(b, tag)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendKey(b []byte, k Key) ([]byte, error) {
+	switch k := k.(type) {
+	case nilKey:
+		return append(b, tagNil), nil
+	case boolKey:
+		if k {
+			return append(b, tagBool, 1), nil
+		}
+		return append(b, tagBool, 0), nil
+	case strKey:
+		return appendString(b, tagString, string(k)), nil
+	case bytesKey:
+		return appendString(b, tagBytes, string(k)), nil
+	case int8Key:
+		return binary.AppendVarint(append(b, tagInt8), int64(k)), nil
+	case int16Key:
+		return binary.AppendVarint(append(b, tagInt16), int64(k)), nil
+	case int32Key:
+		return binary.AppendVarint(append(b, tagInt32), int64(k)), nil
+	case int64Key:
+		return binary.AppendVarint(append(b, tagInt64), int64(k)), nil
+	case uint8Key:
+		return binary.AppendUvarint(append(b, tagUint8), uint64(k)), nil
+	case uint16Key:
+		return binary.AppendUvarint(append(b, tagUint16), uint64(k)), nil
+	case uint32Key:
+		return binary.AppendUvarint(append(b, tagUint32), uint64(k)), nil
+	case uint64Key:
+		return binary.AppendUvarint(append(b, tagUint64), uint64(k)), nil
+	case float32Key:
+		return binary.BigEndian.AppendUint32(append(b, tagFloat32),
+			math.Float32bits(float32(k))), nil
+	case float64Key:
+		return binary.BigEndian.AppendUint64(append(b, tagFloat64),
+			math.Float64bits(float64(k))), nil
+	case mapKey:
+		names := make([]string, 0, len(k))
+		for name := range k {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b = append(b, tagMap)
+		b = binary.AppendUvarint(b, uint64(len(k)))
+		for _, name := range names {
+			b = binary.AppendUvarint(b, uint64(len(name)))
+			b = append(b, name...)
+			var err error
+			if b, err = appendValue(b, k[name]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case sliceKey:
+		return appendSlice(append(b, tagSlice), k)
+	case pathKey:
+		return appendSlice(append(b, tagPath), k.sliceKey)
+	case pointerKey:
+		return appendSlice(append(b, tagPointer), k.sliceKey)
+	case addrKey:
+		addr, _ := netip.Addr(k).MarshalBinary()
+		return appendString(b, tagAddr, string(addr)), nil
+	case prefixKey:
+		prefix, _ := netip.Prefix(k).MarshalBinary()
+		return appendString(b, tagPrefix, string(prefix)), nil
+	case hardwareAddrKey:
+		return appendString(b, tagHardwareAddr, string(k)), nil
+	default:
+		return nil, fmt.Errorf("key: cannot encode key of type %T", k)
+	}
+}
+
+func appendSlice(b []byte, s []interface{}) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	for _, v := range s {
+		var err error
+		if b, err = appendValue(b, v); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendValue(b []byte, v interface{}) ([]byte, error) {
+	k, err := TryNew(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendKey(b, k)
+}
+
+func decodeLength(b []byte) (int, []byte, error) {
+	n, size := binary.Uvarint(b)
+	if size <= 0 {
+		return 0, nil, errShortBuffer
+	}
+	b = b[size:]
+	// Each element takes at least one byte.
+	if n > uint64(len(b)) {
+		return 0, nil, errShortBuffer
+	}
+	return int(n), b, nil
+}
+
+func decodeString(b []byte) (string, []byte, error) {
+	n, b, err := decodeLength(b)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b[:n]), b[n:], nil
+}
+
+func decodeInt(b []byte, bits int) (int64, []byte, error) {
+	v, size := binary.Varint(b)
+	if size <= 0 {
+		return 0, nil, errShortBuffer
+	}
+	if bits < 64 && (v < -1<<(bits-1) || v >= 1<<(bits-1)) {
+		return 0, nil, fmt.Errorf("key: int%d out of range: %d", bits, v)
+	}
+	return v, b[size:], nil
+}
+
+func decodeUint(b []byte, bits int) (uint64, []byte, error) {
+	v, size := binary.Uvarint(b)
+	if size <= 0 {
+		return 0, nil, errShortBuffer
+	}
+	if bits < 64 && v >= 1<<bits {
+		return 0, nil, fmt.Errorf("key: uint%d out of range: %d", bits, v)
+	}
+	return v, b[size:], nil
+}
+
+func decodePath(b []byte) (Path, []byte, error) {
+	s, b, err := decodeSlice(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sliceToPath(s), b, nil
+}
+
+func decodeSlice(b []byte) ([]interface{}, []byte, error) {
+	n, b, err := decodeLength(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := make([]interface{}, n)
+	for i := range s {
+		if s[i], b, err = decodeValue(b); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s, b, nil
+}
+
+// decodeValue decodes a key encoded by appendKey into the value it wraps.
+func decodeValue(b []byte) (interface{}, []byte, error) {
+	if len(b) == 0 {
+		return nil, nil, errShortBuffer
+	}
+	tag, b := b[0], b[1:]
+	switch tag {
+	case tagNil:
+		return nil, b, nil
+	case tagBool:
+		if len(b) == 0 {
+			return nil, nil, errShortBuffer
+		}
+		return b[0] != 0, b[1:], nil
+	case tagString:
+		return decodeString(b)
+	case tagBytes:
+		s, b, err := decodeString(b)
+		return []byte(s), b, err
+	case tagInt8:
+		v, b, err := decodeInt(b, 8)
+		return int8(v), b, err
+	case tagInt16:
+		v, b, err := decodeInt(b, 16)
+		return int16(v), b, err
+	case tagInt32:
+		v, b, err := decodeInt(b, 32)
+		return int32(v), b, err
+	case tagInt64:
+		return decodeInt(b, 64)
+	case tagUint8:
+		v, b, err := decodeUint(b, 8)
+		return uint8(v), b, err
+	case tagUint16:
+		v, b, err := decodeUint(b, 16)
+		return uint16(v), b, err
+	case tagUint32:
+		v, b, err := decodeUint(b, 32)
+		return uint32(v), b, err
+	case tagUint64:
+		return decodeUint(b, 64)
+	case tagFloat32:
+		if len(b) < 4 {
+			return nil, nil, errShortBuffer
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), b[4:], nil
+	case tagFloat64:
+		if len(b) < 8 {
+			return nil, nil, errShortBuffer
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), b[8:], nil
+	case tagMap:
+		n, b, err := decodeLength(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			var name string
+			if name, b, err = decodeString(b); err != nil {
+				return nil, nil, err
+			}
+			if m[name], b, err = decodeValue(b); err != nil {
+				return nil, nil, err
+			}
+		}
+		return m, b, nil
+	case tagSlice:
+		return decodeSlice(b)
+	case tagPath:
+		return decodePath(b)
+	case tagPointer:
+		p, b, err := decodePath(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewPointer(p), b, nil
+	case tagAddr:
+		s, b, err := decodeString(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		var addr netip.Addr
+		if err := addr.UnmarshalBinary([]byte(s)); err != nil {
+			return nil, nil, err
+		}
+		return addr, b, nil
+	case tagPrefix:
+		s, b, err := decodeString(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		var prefix netip.Prefix
+		if err := prefix.UnmarshalBinary([]byte(s)); err != nil {
+			return nil, nil, err
+		}
+		return prefix, b, nil
+	case tagHardwareAddr:
+		s, b, err := decodeString(b)
+		return net.HardwareAddr(s), b, err
+	default:
+		return nil, nil, fmt.Errorf("key: unknown type tag %d in binary encoding", tag)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/aristanetworks/goarista/key"
+)
+
+func codecKeys() []key.Key {
+	return []key.Key{
+		key.New(nil),
+		key.New(true),
+		key.New("foo"),
+		key.New("\xff\xfe"),
+		key.New([]byte{0, 1, 2}),
+		key.New(int8(-8)),
+		key.New(int16(-16)),
+		key.New(int32(-32)),
+		key.New(int64(-64)),
+		key.New(uint8(8)),
+		key.New(uint16(16)),
+		key.New(uint32(32)),
+		key.New(uint64(1 << 63)),
+		key.New(float32(1.5)),
+		key.New(float64(-2.5)),
+		key.New(map[string]interface{}{"a": uint32(1), "b": []byte("x"),
+			"c": map[string]interface{}{"d": "e"}}),
+		key.New([]interface{}{"a", int8(1), nil}),
+		key.New(key.Path{key.New("a"), key.New(uint32(1))}),
+		key.New(key.NewPointer(key.Path{key.New("b")})),
+		key.New(netip.MustParseAddr("2001:db8::1")),
+		key.New(netip.MustParsePrefix("10.0.0.0/8")),
+		key.New(net.HardwareAddr{0, 1, 2, 3, 4, 5}),
+	}
+}
+
+func TestKeyCodec(t *testing.T) {
+	for _, k := range codecKeys() {
+		b, err := key.EncodeBinary(k)
+		if err != nil {
+			t.Fatalf("failed to encode %#v: %s", k, err)
+		}
+		got, err := key.DecodeBinary(b)
+		if err != nil {
+			t.Fatalf("failed to decode %#v: %s", k, err)
+		}
+		if !k.Equal(got) {
+			t.Errorf("expected %#v, got %#v", k, got)
+		}
+		s, err := key.EncodeText(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := key.DecodeText(s); err != nil || !k.Equal(got) {
+			t.Errorf("expected %#v from text %q, got %#v (%v)", k, s, got, err)
+		}
+		for i := range b {
+			if _, err := key.DecodeBinary(b[:i]); err == nil {
+				t.Errorf("expected an error decoding %d of the %d bytes of %#v",
+					i, len(b), k)
+			}
+		}
+	}
+}
+
+func TestKeyCodecCanonical(t *testing.T) {
+	// Maps are encoded in the order of their keys.
+	m1 := map[string]interface{}{}
+	m2 := map[string]interface{}{}
+	for _, s := range []string{"a", "b", "c", "d", "e", "f"} {
+		m1[s] = s
+	}
+	for _, s := range []string{"f", "e", "d", "c", "b", "a"} {
+		m2[s] = s
+	}
+	b1, err := key.EncodeBinary(key.New(m1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := key.EncodeBinary(key.New(m2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b1, b2) {
+		t.Errorf("expected equal encodings, got %x and %x", b1, b2)
+	}
+	// Types are part of the encoding.
+	b1, _ = key.EncodeBinary(key.New(uint32(1)))
+	b2, _ = key.EncodeBinary(key.New(uint64(1)))
+	if bytes.Equal(b1, b2) {
+		t.Errorf("expected different encodings for uint32 and uint64, got %x", b1)
+	}
+}
+
+func TestPathCodec(t *testing.T) {
+	p := key.Path{}
+	for _, k := range codecKeys() {
+		p = append(p, k)
+	}
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got key.Path
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Equal(got) {
+		t.Errorf("expected %#v, got %#v", p, got)
+	}
+
+	// JSON strings are decoded with the text encoding.
+	type doc struct {
+		Path key.Path `json:"path"`
+	}
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := json.Marshal(map[string]string{"path": string(text)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d doc
+	if err := json.Unmarshal(js, &d); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Equal(d.Path) {
+		t.Errorf("expected %#v, got %#v", p, d.Path)
+	}
+
+	// gob uses the binary encoding.
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Equal(got) {
+		t.Errorf("expected %#v, got %#v", p, got)
+	}
+}
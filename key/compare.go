@@ -0,0 +1,268 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/aristanetworks/goarista/value"
+)
+
+// Kinds of keys, in the order Compare sorts them.
+const (
+	kindNil = iota
+	kindBool
+	kindNumber
+	kindString
+	kindBytes
+	kindAddr
+	kindPrefix
+	kindHardwareAddr
+	kindSlice
+	kindMap
+	kindPath
+	kindPointer
+	kindValue
+	kindOther
+)
+
+// Compare returns an integer comparing a and b: 0 if a == b, -1 if a < b
+// and +1 if a > b. It defines a total ordering of keys, to sort them
+// deterministically:
+//   - Keys of different kinds are ordered by kind: nil, bools, numbers,
+//     strings, bytes, IP addresses, IP prefixes, hardware addresses,
+//     slices, maps, paths, pointers and finally value.Values.
+//   - false is before true.
+//   - Numbers are ordered by value regardless of their type, NaNs first.
+//     Equal numbers of different types are ordered by type: int8, int16,
+//     int32, int64, uint8, uint16, uint32, uint64, float32, float64.
+//   - Strings and bytes are ordered lexicographically by byte.
+//   - IP addresses are ordered as by netip.Addr.Compare, IP prefixes by
+//     address and then by prefix length, and hardware addresses by byte.
+//   - Slices, paths and pointers are ordered lexicographically by element,
+//     a prefix being before the longer slice.
+//   - Maps are ordered as the lexicographic sequences of their entries
+//     sorted by key, each entry comparing by key and then by value.
+//   - value.Values are ordered by their String representation.
+//
+// Compare is consistent with Equal, except that NaNs compare equal.
+func Compare(a, b Key) int {
+	return compare(a, b)
+}
+
+// Less returns whether a sorts before b, as defined by Compare.
+func Less(a, b Key) bool {
+	return Compare(a, b) < 0
+}
+
+func compare(a, b interface{}) int {
+	ka, a := kindOf(a)
+	kb, b := kindOf(b)
+	if ka != kb {
+		return compareInts(ka, kb)
+	}
+	switch ka {
+	case kindNil:
+		return 0
+	case kindBool:
+		return compareBools(a.(bool), b.(bool))
+	case kindNumber:
+		return compareNumbers(a, b)
+	case kindString, kindBytes:
+		return strings.Compare(a.(string), b.(string))
+	case kindAddr:
+		return a.(netip.Addr).Compare(b.(netip.Addr))
+	case kindPrefix:
+		pa, pb := a.(netip.Prefix), b.(netip.Prefix)
+		if c := pa.Addr().Compare(pb.Addr()); c != 0 {
+			return c
+		}
+		return compareInts(pa.Bits(), pb.Bits())
+	case kindHardwareAddr:
+		return bytes.Compare(a.(net.HardwareAddr), b.(net.HardwareAddr))
+	case kindSlice, kindPath, kindPointer:
+		return compareSlices(a.([]interface{}), b.([]interface{}))
+	case kindMap:
+		return compareMaps(a.(map[string]interface{}), b.(map[string]interface{}))
+	case kindValue:
+		return strings.Compare(a.(value.Value).String(), b.(value.Value).String())
+	default:
+		if c := strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b)); c != 0 {
+			return c
+		}
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+// kindOf returns the kind of v, unwrapping it if it is a Key. Bytes are
+// returned as strings, and paths and pointers as the []interface{} of
+// their elements.
+func kindOf(v interface{}) (int, interface{}) {
+	switch v := v.(type) {
+	case nil:
+		return kindNil, nil
+	case bool:
+		return kindBool, v
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64:
+		return kindNumber, v
+	case string:
+		return kindString, v
+	case []byte:
+		return kindBytes, string(v)
+	case bytesKey:
+		return kindBytes, string(v)
+	case netip.Addr:
+		return kindAddr, v
+	case netip.Prefix:
+		return kindPrefix, v
+	case net.HardwareAddr:
+		return kindHardwareAddr, v
+	case []interface{}:
+		return kindSlice, v
+	case map[string]interface{}:
+		return kindMap, v
+	case pathKey:
+		return kindPath, []interface{}(v.sliceKey)
+	case Path:
+		return kindPath, pathToSlice(v)
+	case pointerKey:
+		return kindPointer, []interface{}(v.sliceKey)
+	case Pointer:
+		return kindPointer, pointerToSlice(v)
+	case value.Value:
+		return kindValue, v
+	case NonUnwrappingKey:
+		return kindOther, v
+	case Key:
+		return kindOf(v.Key())
+	default:
+		return kindOther, v
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// numberRank returns the rank of the type of a number, to order equal
+// numbers of different types.
+func numberRank(v interface{}) int {
+	switch v.(type) {
+	case int8:
+		return 0
+	case int16:
+		return 1
+	case int32:
+		return 2
+	case int64:
+		return 3
+	case uint8:
+		return 4
+	case uint16:
+		return 5
+	case uint32:
+		return 6
+	case uint64:
+		return 7
+	case float32:
+		return 8
+	}
+	return 9
+}
+
+// bigFloat returns v as a big.Float, or false if v is NaN.
+func bigFloat(v interface{}) (*big.Float, bool) {
+	f := new(big.Float)
+	switch v := v.(type) {
+	case int8:
+		f.SetInt64(int64(v))
+	case int16:
+		f.SetInt64(int64(v))
+	case int32:
+		f.SetInt64(int64(v))
+	case int64:
+		f.SetInt64(v)
+	case uint8:
+		f.SetUint64(uint64(v))
+	case uint16:
+		f.SetUint64(uint64(v))
+	case uint32:
+		f.SetUint64(uint64(v))
+	case uint64:
+		f.SetUint64(v)
+	case float32:
+		if math.IsNaN(float64(v)) {
+			return nil, false
+		}
+		f.SetFloat64(float64(v))
+	case float64:
+		if math.IsNaN(v) {
+			return nil, false
+		}
+		f.SetFloat64(v)
+	}
+	return f, true
+}
+
+func compareNumbers(a, b interface{}) int {
+	fa, aOK := bigFloat(a)
+	fb, bOK := bigFloat(b)
+	var c int
+	switch {
+	case !aOK || !bOK:
+		// NaNs are before all the other numbers.
+		c = compareBools(aOK, bOK)
+	default:
+		c = fa.Cmp(fb)
+	}
+	if c != 0 {
+		return c
+	}
+	return compareInts(numberRank(a), numberRank(b))
+}
+
+func compareSlices(a, b []interface{}) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+func compareMaps(a, b map[string]interface{}) int {
+	ka, kb := SortedKeys(a), SortedKeys(b)
+	for i := 0; i < len(ka) && i < len(kb); i++ {
+		if c := strings.Compare(ka[i], kb[i]); c != 0 {
+			return c
+		}
+		if c := compare(a[ka[i]], b[kb[i]]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(ka), len(kb))
+}
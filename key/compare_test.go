@@ -0,0 +1,101 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key_test
+
+import (
+	"math"
+	"math/rand"
+	"net"
+	"net/netip"
+	"sort"
+	"testing"
+
+	"github.com/aristanetworks/goarista/key"
+)
+
+func TestCompare(t *testing.T) {
+	// The keys in increasing order.
+	sorted := []key.Key{
+		key.New(nil),
+		key.New(false),
+		key.New(true),
+		key.New(math.NaN()),
+		key.New(math.Inf(-1)),
+		key.New(int64(math.MinInt64)),
+		key.New(float64(-2.5)),
+		key.New(int8(-1)),
+		key.New(int8(0)),
+		key.New(uint8(0)),
+		key.New(float32(0.5)),
+		key.New(int8(1)),
+		key.New(int16(1)),
+		key.New(uint64(1)),
+		key.New(float64(1)),
+		key.New(uint64(math.MaxUint64)),
+		key.New(float64(1 << 64)),
+		key.New(math.Inf(1)),
+		key.New(""),
+		key.New("a"),
+		key.New("ab"),
+		key.New("b"),
+		key.New([]byte{0}),
+		key.New([]byte{0, 1}),
+		key.New(netip.MustParseAddr("10.0.0.1")),
+		key.New(netip.MustParseAddr("10.0.0.2")),
+		key.New(netip.MustParseAddr("2001:db8::1")),
+		key.New(netip.MustParsePrefix("10.0.0.0/8")),
+		key.New(netip.MustParsePrefix("10.0.0.0/16")),
+		key.New(net.HardwareAddr{0, 1, 2, 3, 4, 5}),
+		key.New([]interface{}{}),
+		key.New([]interface{}{"a"}),
+		key.New([]interface{}{"a", int8(1)}),
+		key.New([]interface{}{"b"}),
+		key.New(map[string]interface{}{}),
+		key.New(map[string]interface{}{"a": int8(1)}),
+		key.New(map[string]interface{}{"a": int8(1), "b": "x"}),
+		key.New(map[string]interface{}{"a": int8(2)}),
+		key.New(map[string]interface{}{"b": int8(0)}),
+		key.New(key.Path{}),
+		key.New(key.Path{key.New("a")}),
+		key.New(key.Path{key.New("a"), key.New("b")}),
+		key.New(key.NewPointer(key.Path{key.New("a")})),
+		key.New(key.NewPointer(key.Path{key.New("b")})),
+	}
+	for i, a := range sorted {
+		for j, b := range sorted {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			if c := key.Compare(a, b); c != expected {
+				t.Errorf("Compare(%#v, %#v): expected %d, got %d", a, b, expected, c)
+			}
+			if l := key.Less(a, b); l != (expected < 0) {
+				t.Errorf("Less(%#v, %#v): expected %t", a, b, expected < 0)
+			}
+		}
+	}
+
+	shuffled := append([]key.Key(nil), sorted...)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	sort.Slice(shuffled, func(i, j int) bool { return key.Less(shuffled[i], shuffled[j]) })
+	for i := range sorted {
+		if key.Compare(sorted[i], shuffled[i]) != 0 {
+			t.Errorf("at %d: expected %#v, got %#v", i, sorted[i], shuffled[i])
+		}
+	}
+
+	if c := key.Compare(key.New(float64(0)), key.New(math.Copysign(0, -1))); c != 0 {
+		t.Errorf("expected 0 and -0 to be equal, got %d", c)
+	}
+	if c := key.Compare(key.New(map[string]interface{}{"a": []interface{}{int8(1)}}),
+		key.New(map[string]interface{}{"a": []interface{}{int8(1)}})); c != 0 {
+		t.Errorf("expected equal maps to compare equal, got %d", c)
+	}
+}
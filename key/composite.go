@@ -4,6 +4,8 @@
 
 package key
 
+import "net"
+
 // HashInterface computes the hash of a Key
 func HashInterface(v interface{}) uintptr {
 	if vv, ok := v.(Key); ok {
@@ -16,6 +18,8 @@ func HashInterface(v interface{}) uintptr {
 		return hashSlice(v)
 	case []byte:
 		return HashInterface(string(v))
+	case net.HardwareAddr:
+		return HashInterface(string(v))
 	case Pointer:
 		// This case applies to pointers used
 		// as values in maps or slices (i.e.
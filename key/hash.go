@@ -8,6 +8,7 @@ import (
 	"encoding/binary"
 	"hash/maphash"
 	"math"
+	"net/netip"
 	"unsafe"
 )
 
@@ -43,6 +44,14 @@ func Hash(seed maphash.Seed, k Key) uint64 {
 		return maphash.String(seed, string(v))
 	case bytesKey:
 		return maphash.Bytes(seed, []byte(v))
+	case addrKey:
+		b, _ := netip.Addr(v).MarshalBinary()
+		return maphash.Bytes(seed, b)
+	case prefixKey:
+		b, _ := netip.Prefix(v).MarshalBinary()
+		return maphash.Bytes(seed, b)
+	case hardwareAddrKey:
+		return maphash.String(seed, string(v))
 	case int8Key:
 		buf[0] = byte(v)
 		return maphash.Bytes(seed, buf[:1])
@@ -10,6 +10,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net"
+	"net/netip"
 	"strconv"
 
 	"github.com/aristanetworks/goarista/value"
@@ -114,7 +116,12 @@ func New(intf interface{}) Key {
 // TryNew wraps the given value in a Key.
 // Returns error if the value passed in isn't allowed in a Key or
 // doesn't implement value.Value.
+// In the frozen mode, see SetFrozen, the maps and slices of the value are
+// copied.
 func TryNew(intf interface{}) (Key, error) {
+	if frozen.Load() {
+		intf = cloneValue(intf)
+	}
 	switch t := intf.(type) {
 	case nil:
 		return nilKey{}, nil
@@ -152,6 +159,12 @@ func TryNew(intf interface{}) (Key, error) {
 		return pointerKey{sliceKey(pointerToSlice(t))}, nil
 	case []byte:
 		return bytesKey(t), nil
+	case netip.Addr:
+		return addrKey(t), nil
+	case netip.Prefix:
+		return prefixKey(t), nil
+	case net.HardwareAddr:
+		return hardwareAddrKey(t), nil
 	case Path:
 		return pathKey{sliceKey(pathToSlice(t))}, nil
 	case Key:
@@ -235,6 +248,9 @@ func keyEqual(a, b interface{}) bool {
 	case []byte:
 		b, ok := b.([]byte)
 		return ok && bytes.Equal(a, b)
+	case net.HardwareAddr:
+		b, ok := b.(net.HardwareAddr)
+		return ok && bytes.Equal(a, b)
 	case Comparable:
 		return a.Equal(b)
 	case Pointer:
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key
+
+// MapOf is a Map from Keys to values of type V, sparing its users the type
+// assertions of the values of a Map. The zero MapOf is an empty map ready
+// to use.
+type MapOf[V any] struct {
+	m Map
+}
+
+// Len returns the length of the MapOf
+func (m *MapOf[V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.m.Len()
+}
+
+// Set adds a key-value pair to the MapOf
+func (m *MapOf[V]) Set(k Key, v V) {
+	m.m.Set(k, v)
+}
+
+// Get retrieves the value stored with key k from the MapOf
+func (m *MapOf[V]) Get(k Key) (V, bool) {
+	if m == nil {
+		var zero V
+		return zero, false
+	}
+	v, ok := m.m.Get(k)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	// The comma-ok form handles nil values of interface types.
+	val, _ := v.(V)
+	return val, true
+}
+
+// Del removes an entry with key k from the MapOf
+func (m *MapOf[V]) Del(k Key) {
+	if m == nil {
+		return
+	}
+	m.m.Del(k)
+}
+
+// Iter applies func f to every key-value pair in the MapOf
+func (m *MapOf[V]) Iter(f func(k Key, v V) error) error {
+	if m == nil {
+		return nil
+	}
+	return m.m.Iter(func(k, v interface{}) error {
+		val, _ := v.(V)
+		return f(k.(Key), val)
+	})
+}
+
+// Keys returns a list of all keys in the MapOf
+func (m *MapOf[V]) Keys() []Key {
+	keys := make([]Key, 0, m.Len())
+	m.Iter(func(k Key, _ V) error {
+		keys = append(keys, k)
+		return nil
+	})
+	return keys
+}
+
+// Values returns a list of all values in the MapOf
+func (m *MapOf[V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	m.Iter(func(_ Key, v V) error {
+		values = append(values, v)
+		return nil
+	})
+	return values
+}
+
+// String outputs the string representation of the MapOf
+func (m *MapOf[V]) String() string {
+	if m == nil {
+		return "key.MapOf(nil)"
+	}
+	return "key.MapOf" + m.m.String()[len("key.Map"):]
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package key
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestMapOf(t *testing.T) {
+	var m MapOf[int]
+	m.Set(New("a"), 1)
+	m.Set(New(map[string]interface{}{"b": uint32(2)}), 2)
+	m.Set(New([]interface{}{"c", int64(3)}), 3)
+	m.Set(New("a"), 4)
+	if m.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %d: %s", m.Len(), &m)
+	}
+	for _, tc := range []struct {
+		k        Key
+		expected int
+	}{
+		{New("a"), 4},
+		{New(map[string]interface{}{"b": uint32(2)}), 2},
+		{New([]interface{}{"c", int64(3)}), 3},
+	} {
+		if v, ok := m.Get(tc.k); !ok || v != tc.expected {
+			t.Errorf("Get(%s): expected %d, got %d, %t", tc.k, tc.expected, v, ok)
+		}
+	}
+	if v, ok := m.Get(New("b")); ok || v != 0 {
+		t.Errorf("Get(b): expected no value, got %d, %t", v, ok)
+	}
+	values := m.Values()
+	sort.Ints(values)
+	if len(values) != 3 || values[0] != 2 || values[1] != 3 || values[2] != 4 {
+		t.Errorf("unexpected values %v", values)
+	}
+	if keys := m.Keys(); len(keys) != 3 {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	expected := "key.MapOf[a:4 c,3:3 map[b:2]:2]"
+	if s := m.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	m.Del(New(map[string]interface{}{"b": uint32(2)}))
+	m.Del(New("missing"))
+	if _, ok := m.Get(New(map[string]interface{}{"b": uint32(2)})); ok || m.Len() != 2 {
+		t.Errorf("unexpected map after Del: %s", &m)
+	}
+
+	var errs MapOf[error]
+	errs.Set(New("nil"), nil)
+	if err, ok := errs.Get(New("nil")); !ok || err != nil {
+		t.Errorf("expected a nil error, got %v, %t", err, ok)
+	}
+
+	var nilMap *MapOf[int]
+	if _, ok := nilMap.Get(New("a")); ok || nilMap.Len() != 0 ||
+		nilMap.String() != "key.MapOf(nil)" {
+		t.Error("unexpected nil MapOf")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// maxDroppedMessages bounds the number of distinct messages dropped from a
+// call site that are summarized individually, the others are only counted.
+const maxDroppedMessages = 16
+
+// RateLimited is a Logger rate-limiting the messages logged from each call
+// site of the Logger it wraps, so that an error logged in a loop, e.g. by
+// a retry loop, does not flood the logs. Each call site has a token bucket
+// holding up to burst messages and refilled at the rate of burst messages
+// per interval. The messages logged while the bucket of their call site is
+// empty are dropped, and interval after the first of them is dropped, each
+// distinct message dropped is logged once followed by the number of times
+// it was repeated. The fatal messages are never dropped.
+//
+// The wrapped Logger sees the RateLimited methods as the callers, so the
+// source locations it may log are the ones of this file.
+type RateLimited struct {
+	l        Logger
+	burst    int
+	interval time.Duration
+
+	// now and afterFunc are overridden by the tests.
+	now       func() time.Time
+	afterFunc func(time.Duration, func())
+
+	mu sync.Mutex
+	// sites are keyed by file:line, as an inlined call site has several
+	// program counters.
+	sites map[string]*callSite
+}
+
+// callSite is the state of a call site of a RateLimited.
+type callSite struct {
+	tokens   float64
+	refilled time.Time
+	// dropped counts the messages dropped since the last summary, in the
+	// order they were first dropped.
+	dropped map[droppedMessage]int
+	order   []droppedMessage
+	// others counts the messages dropped past maxDroppedMessages distinct
+	// ones, and othersErr is true if any of them is an error.
+	others    int
+	othersErr bool
+}
+
+type droppedMessage struct {
+	err bool
+	msg string
+}
+
+// NewRateLimited returns a RateLimited wrapping l, logging at most burst
+// messages from each call site in a row, and then one every
+// interval / burst.
+func NewRateLimited(l Logger, burst int, interval time.Duration) *RateLimited {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimited{
+		l:        l,
+		burst:    burst,
+		interval: interval,
+		now:      time.Now,
+		afterFunc: func(d time.Duration, f func()) {
+			time.AfterFunc(d, f)
+		},
+		sites: map[string]*callSite{},
+	}
+}
+
+// Info logs at the info level
+func (r *RateLimited) Info(args ...interface{}) {
+	r.log(false, fmt.Sprint(args...))
+}
+
+// Infof logs at the info level, with format
+func (r *RateLimited) Infof(format string, args ...interface{}) {
+	r.log(false, fmt.Sprintf(format, args...))
+}
+
+// Error logs at the error level
+func (r *RateLimited) Error(args ...interface{}) {
+	r.log(true, fmt.Sprint(args...))
+}
+
+// Errorf logs at the error level, with format
+func (r *RateLimited) Errorf(format string, args ...interface{}) {
+	r.log(true, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs at the fatal level, after the summaries of the messages
+// dropped
+func (r *RateLimited) Fatal(args ...interface{}) {
+	r.Flush()
+	r.l.Fatal(args...)
+}
+
+// Fatalf logs at the fatal level, with format, after the summaries of the
+// messages dropped
+func (r *RateLimited) Fatalf(format string, args ...interface{}) {
+	r.Flush()
+	r.l.Fatalf(format, args...)
+}
+
+// Flush logs the summaries of the messages dropped so far without waiting
+// for the end of their interval, e.g. before exiting.
+func (r *RateLimited) Flush() {
+	r.mu.Lock()
+	sites := make([]string, 0, len(r.sites))
+	for site := range r.sites {
+		sites = append(sites, site)
+	}
+	r.mu.Unlock()
+	for _, site := range sites {
+		r.summarize(site)
+	}
+}
+
+func (r *RateLimited) log(err bool, msg string) {
+	// Skip log and the exported method calling it.
+	_, file, line, _ := runtime.Caller(2)
+	if r.allow(fmt.Sprintf("%s:%d", file, line), droppedMessage{err: err, msg: msg}) {
+		r.output(err, msg)
+	}
+}
+
+// allow returns whether m can be logged from the call site key, and counts
+// it as dropped otherwise.
+func (r *RateLimited) allow(key string, m droppedMessage) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	site, ok := r.sites[key]
+	if !ok {
+		site = &callSite{tokens: float64(r.burst), refilled: now}
+		r.sites[key] = site
+	}
+	if elapsed := now.Sub(site.refilled); elapsed > 0 && r.interval > 0 {
+		site.tokens += float64(r.burst) * float64(elapsed) / float64(r.interval)
+		if site.tokens > float64(r.burst) {
+			site.tokens = float64(r.burst)
+		}
+	}
+	site.refilled = now
+	if site.tokens >= 1 || r.interval <= 0 {
+		site.tokens--
+		return true
+	}
+	if len(site.order) == 0 && site.others == 0 {
+		r.afterFunc(r.interval, func() { r.summarize(key) })
+	}
+	if _, ok := site.dropped[m]; !ok && len(site.order) >= maxDroppedMessages {
+		site.others++
+		site.othersErr = site.othersErr || m.err
+		return false
+	}
+	if site.dropped == nil {
+		site.dropped = map[droppedMessage]int{}
+	}
+	if site.dropped[m] == 0 {
+		site.order = append(site.order, m)
+	}
+	site.dropped[m]++
+	return false
+}
+
+// summarize logs the messages dropped from the call site key.
+func (r *RateLimited) summarize(key string) {
+	r.mu.Lock()
+	site, ok := r.sites[key]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	order, dropped := site.order, site.dropped
+	others, othersErr := site.others, site.othersErr
+	site.order, site.dropped, site.others, site.othersErr = nil, nil, 0, false
+	r.mu.Unlock()
+
+	for _, m := range order {
+		r.output(m.err, fmt.Sprintf("%s (message repeated %d times)", m.msg, dropped[m]))
+	}
+	if others > 0 {
+		r.output(othersErr, fmt.Sprintf("%d other messages dropped", others))
+	}
+}
+
+func (r *RateLimited) output(err bool, msg string) {
+	if err {
+		r.l.Error(msg)
+	} else {
+		r.l.Info(msg)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	lines []string
+}
+
+func (r *recorder) Info(args ...interface{}) {
+	r.lines = append(r.lines, "I "+fmt.Sprint(args...))
+}
+
+func (r *recorder) Infof(format string, args ...interface{}) {
+	r.lines = append(r.lines, "I "+fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.lines = append(r.lines, "E "+fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.lines = append(r.lines, "E "+fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...interface{}) {
+	r.lines = append(r.lines, "F "+fmt.Sprint(args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.lines = append(r.lines, "F "+fmt.Sprintf(format, args...))
+}
+
+func TestRateLimited(t *testing.T) {
+	rec := &recorder{}
+	r := NewRateLimited(rec, 2, time.Minute)
+	now := time.Unix(1000, 0)
+	r.now = func() time.Time { return now }
+	var summaries []func()
+	r.afterFunc = func(d time.Duration, f func()) {
+		if d != time.Minute {
+			t.Errorf("unexpected summary delay %s", d)
+		}
+		summaries = append(summaries, f)
+	}
+	check := func(expected ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(expected, rec.lines) {
+			t.Errorf("expected %q, got %q", expected, rec.lines)
+		}
+		rec.lines = nil
+	}
+
+	loop := func(n int) {
+		for i := 0; i < n; i++ {
+			r.Errorf("failed: %d", i%2)
+		}
+	}
+	loop(5)
+	// Another call site has its own bucket.
+	r.Info("other")
+	check("E failed: 0", "E failed: 1", "I other")
+	if len(summaries) != 1 {
+		t.Fatalf("expected 1 summary scheduled, got %d", len(summaries))
+	}
+
+	// Half of the interval refills one token.
+	now = now.Add(30 * time.Second)
+	loop(2)
+	check("E failed: 0")
+	summaries[0]()
+	check("E failed: 0 (message repeated 2 times)", "E failed: 1 (message repeated 2 times)")
+
+	// The messages past maxDroppedMessages are only counted.
+	for i := 0; i < maxDroppedMessages+3; i++ {
+		r.Infof("message %d", i)
+	}
+	r.Fatal("fatal")
+	var expected []string
+	for i := 0; i < 2; i++ {
+		expected = append(expected, fmt.Sprintf("I message %d", i))
+	}
+	for i := 2; i < maxDroppedMessages+2; i++ {
+		expected = append(expected, fmt.Sprintf("I message %d (message repeated 1 times)", i))
+	}
+	check(append(expected, "I 1 other messages dropped", "F fatal")...)
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SubsystemKey is the attribute key holding the subsystem of the records
+// logged by a Slog returned by Subsystem.
+const SubsystemKey = "subsystem"
+
+// Levels holds the minimum level of the records logged, for each subsystem.
+// Subsystems without a level of their own use the default level. Levels can be
+// changed at runtime and implements flag.Value with the syntax
+// "[DEFAULT][,SUBSYSTEM=LEVEL]*", e.g. "warn,gnmi=debug".
+type Levels struct {
+	mu         sync.RWMutex
+	def        slog.Level
+	subsystems map[string]slog.Level
+}
+
+// NewLevels returns Levels using def as the default level.
+func NewLevels(def slog.Level) *Levels {
+	return &Levels{def: def, subsystems: map[string]slog.Level{}}
+}
+
+// Level returns the minimum level of the records logged by subsystem.
+func (l *Levels) Level(subsystem string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.subsystems[subsystem]; ok {
+		return level
+	}
+	return l.def
+}
+
+// SetDefault sets the default level.
+func (l *Levels) SetDefault(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.def = level
+}
+
+// SetLevel sets the level of subsystem.
+func (l *Levels) SetLevel(subsystem string, level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subsystems[subsystem] = level
+}
+
+// ResetLevel makes subsystem use the default level again.
+func (l *Levels) ResetLevel(subsystem string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.subsystems, subsystem)
+}
+
+// String implements flag.Value.
+func (l *Levels) String() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	parts := make([]string, 0, len(l.subsystems)+1)
+	parts = append(parts, strings.ToLower(l.def.String()))
+	for subsystem, level := range l.subsystems {
+		parts = append(parts, subsystem+"="+strings.ToLower(level.String()))
+	}
+	sort.Strings(parts[1:])
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value.
+func (l *Levels) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+		subsystem, name, ok := strings.Cut(part, "=")
+		if !ok {
+			name = subsystem
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			return fmt.Errorf("invalid level %q: %s", name, err)
+		}
+		if ok {
+			l.SetLevel(subsystem, level)
+		} else {
+			l.SetDefault(level)
+		}
+	}
+	return nil
+}
+
+// Hook is called with every record logged by a Slog, before it is handled.
+type Hook func(ctx context.Context, r slog.Record)
+
+// Slog implements the Logger interface using the stdlib "log/slog" package,
+// filtering records according to the level of its subsystem.
+type Slog struct {
+	handler   slog.Handler
+	levels    *Levels
+	hooks     []Hook
+	subsystem string
+}
+
+var _ Logger = (*Slog)(nil)
+
+// exit is called by Fatal and Fatalf, overridden in tests.
+var exit = os.Exit
+
+// allLevels lets the handlers of a Slog handle all the records, the
+// filtering being done by the Slog itself.
+const allLevels = slog.Level(math.MinInt32)
+
+// NewSlog returns a Slog logging to h the records at or above the levels in
+// levels, after calling hooks. If levels is nil, records at or above
+// slog.LevelInfo are logged.
+func NewSlog(h slog.Handler, levels *Levels, hooks ...Hook) *Slog {
+	if levels == nil {
+		levels = NewLevels(slog.LevelInfo)
+	}
+	return &Slog{handler: h, levels: levels, hooks: hooks}
+}
+
+// NewJSON returns a Slog writing records to w as JSON objects, one per line.
+func NewJSON(w io.Writer, levels *Levels, hooks ...Hook) *Slog {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: allLevels})
+	return NewSlog(h, levels, hooks...)
+}
+
+// Subsystem returns a Slog logging with the level of subsystem and adding it
+// to the records under SubsystemKey.
+func (s *Slog) Subsystem(subsystem string) *Slog {
+	return &Slog{
+		handler:   s.handler.WithAttrs([]slog.Attr{slog.String(SubsystemKey, subsystem)}),
+		levels:    s.levels,
+		hooks:     s.hooks,
+		subsystem: subsystem,
+	}
+}
+
+// Levels returns the levels used by s.
+func (s *Slog) Levels() *Levels {
+	return s.levels
+}
+
+// Enabled returns whether records at level are logged by s.
+func (s *Slog) Enabled(level slog.Level) bool {
+	return level >= s.levels.Level(s.subsystem)
+}
+
+func (s *Slog) log(level slog.Level, msg string) {
+	if !s.Enabled(level) {
+		return
+	}
+	var pcs [1]uintptr
+	// Skip runtime.Callers, log and the exported method calling it.
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	ctx := context.Background()
+	for _, hook := range s.hooks {
+		hook(ctx, r)
+	}
+	s.handler.Handle(ctx, r)
+}
+
+// Debug logs at the debug level
+func (s *Slog) Debug(args ...interface{}) {
+	s.log(slog.LevelDebug, fmt.Sprint(args...))
+}
+
+// Debugf logs at the debug level, with format
+func (s *Slog) Debugf(format string, args ...interface{}) {
+	s.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Info logs at the info level
+func (s *Slog) Info(args ...interface{}) {
+	s.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+// Infof logs at the info level, with format
+func (s *Slog) Infof(format string, args ...interface{}) {
+	s.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warning logs at the warning level
+func (s *Slog) Warning(args ...interface{}) {
+	s.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+// Warningf logs at the warning level, with format
+func (s *Slog) Warningf(format string, args ...interface{}) {
+	s.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+// Error logs at the error level
+func (s *Slog) Error(args ...interface{}) {
+	s.log(slog.LevelError, fmt.Sprint(args...))
+}
+
+// Errorf logs at the error level, with format
+func (s *Slog) Errorf(format string, args ...interface{}) {
+	s.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs at the error level and exits
+func (s *Slog) Fatal(args ...interface{}) {
+	s.log(slog.LevelError, fmt.Sprint(args...))
+	exit(1)
+}
+
+// Fatalf logs at the error level, with format, and exits
+func (s *Slog) Fatalf(format string, args ...interface{}) {
+	s.log(slog.LevelError, fmt.Sprintf(format, args...))
+	exit(1)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var r map[string]interface{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	var hooked []string
+	levels := NewLevels(slog.LevelInfo)
+	l := NewJSON(&buf, levels, func(ctx context.Context, r slog.Record) {
+		hooked = append(hooked, r.Message)
+	})
+	gnmi := l.Subsystem("gnmi")
+
+	l.Debug("dropped")
+	l.Infof("info %d", 1)
+	gnmi.Debugf("dropped %d", 2)
+	levels.SetLevel("gnmi", slog.LevelDebug)
+	gnmi.Debugf("debug %d", 3)
+	l.Debug("dropped")
+	levels.SetDefault(slog.LevelError)
+	l.Warning("dropped")
+	l.Error("error")
+
+	var exited int
+	defer func(e func(int)) { exit = e }(exit)
+	exit = func(code int) { exited = code }
+	gnmi.Fatalf("fatal %s", "gnmi")
+	if exited != 1 {
+		t.Errorf("expected Fatalf to exit with 1, got %d", exited)
+	}
+
+	expected := []struct {
+		level, msg, subsystem string
+	}{
+		{"INFO", "info 1", ""},
+		{"DEBUG", "debug 3", "gnmi"},
+		{"ERROR", "error", ""},
+		{"ERROR", "fatal gnmi", "gnmi"},
+	}
+	records := decodeRecords(t, &buf)
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records, got %d: %v", len(expected), len(records), records)
+	}
+	for i, exp := range expected {
+		r := records[i]
+		if r["level"] != exp.level || r["msg"] != exp.msg {
+			t.Errorf("record %d: expected %s %q, got %v", i, exp.level, exp.msg, r)
+		}
+		if sub, _ := r[SubsystemKey].(string); sub != exp.subsystem {
+			t.Errorf("record %d: expected subsystem %q, got %q", i, exp.subsystem, sub)
+		}
+		src, _ := r[slog.SourceKey].(map[string]interface{})
+		if file, _ := src["file"].(string); !strings.HasSuffix(file, "slog_test.go") {
+			t.Errorf("record %d: expected source in slog_test.go, got %v", i, src)
+		}
+		if hooked[i] != exp.msg {
+			t.Errorf("hook %d: expected %q, got %q", i, exp.msg, hooked[i])
+		}
+	}
+}
+
+func TestLevelsFlag(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(levels, "log_levels", "")
+	if err := fs.Parse([]string{"-log_levels=warn,gnmi=debug,kafka=ERROR"}); err != nil {
+		t.Fatal(err)
+	}
+	for subsystem, exp := range map[string]slog.Level{
+		"":      slog.LevelWarn,
+		"other": slog.LevelWarn,
+		"gnmi":  slog.LevelDebug,
+		"kafka": slog.LevelError,
+	} {
+		if level := levels.Level(subsystem); level != exp {
+			t.Errorf("%q: expected %s, got %s", subsystem, exp, level)
+		}
+	}
+	if s, exp := levels.String(), "warn,gnmi=debug,kafka=error"; s != exp {
+		t.Errorf("expected %q, got %q", exp, s)
+	}
+	levels.ResetLevel("gnmi")
+	if level := levels.Level("gnmi"); level != slog.LevelWarn {
+		t.Errorf("expected gnmi to use the default level, got %s", level)
+	}
+	if err := levels.Set("gnmi=loud"); err == nil {
+		t.Error("expected error for invalid level")
+	}
+}
@@ -6,6 +6,8 @@ package netns
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -15,10 +17,29 @@ import (
 // will not actually check to see if the VRF name or address are valid.
 // Presumably, when those values are used later, they will fail if they
 // are malformed
+//
+// The address can also be in the network namespace of another process, e.g.
+// a container, with pid:<pid>/address:port, or in the namespace of any
+// namespace file with nspath=<path>/address:port. The namespace returned is
+// then the absolute path of the namespace file, /proc/<pid>/ns/net or path.
 func ParseAddress(address string) (nsName string, addr string, err error) {
+	if path, ok := strings.CutPrefix(address, "nspath="); ok {
+		i := strings.LastIndexByte(path, '/')
+		if i < 0 || !filepath.IsAbs(path[:i]) {
+			return "", "", fmt.Errorf(
+				"Could not parse out an absolute nspath=<path>/address for %s", address)
+		}
+		return filepath.Clean(path[:i]), path[i+1:], nil
+	}
 	split := strings.Split(address, "/")
 	if l := len(split); l == 1 {
 		addr = split[0]
+	} else if pid, ok := strings.CutPrefix(split[0], "pid:"); ok && l == 2 {
+		if n, err := strconv.ParseUint(pid, 10, 32); err != nil || n == 0 {
+			return "", "", fmt.Errorf("Invalid pid %q in %s", pid, address)
+		}
+		nsName = "/proc/" + pid + "/ns/net"
+		addr = split[1]
 	} else if l == 2 {
 		nsName = VRFToNetNS(split[0])
 		addr = split[1]
@@ -33,6 +33,30 @@ func TestParseAddress(t *testing.T) {
 		"",
 		"",
 		true,
+	}, {
+		"Parse address with pid",
+		"pid:1234/[::1]:6030",
+		"/proc/1234/ns/net",
+		"[::1]:6030",
+		false,
+	}, {
+		"Parse invalid pid",
+		"pid:self/1.2.3.4:50",
+		"",
+		"",
+		true,
+	}, {
+		"Parse address with nspath",
+		"nspath=/run/docker/netns/1a2b/1.2.3.4:50",
+		"/run/docker/netns/1a2b",
+		"1.2.3.4:50",
+		false,
+	}, {
+		"Parse relative nspath",
+		"nspath=netns/1a2b/1.2.3.4:50",
+		"",
+		"",
+		true,
 	}}
 
 	for _, tt := range tests {
@@ -0,0 +1,174 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package netns
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// statNs returns the FileInfo of a network namespace file, mocked in tests.
+var statNs = os.Stat
+
+// Handle is a long-lived handle to a network namespace. Unlike Do, which
+// opens the namespace on every call, a Handle keeps the file descriptors of
+// the namespace and of the original namespace open, so that callbacks can
+// be run in the namespace with only the two setns calls.
+type Handle struct {
+	name string
+	info os.FileInfo
+
+	// mu is held for reading by Do and for writing by Close, so that the
+	// file descriptors are not closed while in use.
+	mu     sync.RWMutex
+	ns     handle
+	selfNs handle
+	closed bool
+}
+
+// Open returns a Handle to the network namespace nsName, which is either the
+// name of a namespace under /var/run/netns or the absolute path of a
+// namespace file.
+func Open(nsName string) (*Handle, error) {
+	selfNs, err := getNs(selfNsFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s: %s", selfNsFile, err)
+	}
+	netPath := nsPath(nsName)
+	ns, err := getNs(netPath)
+	if err != nil {
+		selfNs.close()
+		return nil, fmt.Errorf("Failed to open %s: %s", netPath, err)
+	}
+	h := &Handle{name: nsName, ns: ns, selfNs: selfNs}
+	// The FileInfo lets Pool notice namespaces that were recreated.
+	h.info, _ = statNs(netPath)
+	return h, nil
+}
+
+// Name returns the name of the network namespace of h.
+func (h *Handle) Name() string {
+	return h.name
+}
+
+// Do calls cb in the network namespace of h, with the same constraints on
+// cb as the package-level Do.
+func (h *Handle) Do(cb Callback) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return fmt.Errorf("Handle to namespace %s is closed", h.name)
+	}
+	return h.do(cb)
+}
+
+// do calls cb in the network namespace of h, which must be locked.
+func (h *Handle) do(cb Callback) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := setNs(h.ns); err != nil {
+		return fmt.Errorf("Failed to set the namespace to %s: %s", h.name, err)
+	}
+	cbErr := cb()
+	if err := setNs(h.selfNs); err != nil {
+		return fmt.Errorf("Failed to return to the original namespace: %s (callback returned %v)",
+			err, cbErr)
+	}
+	return cbErr
+}
+
+// Close closes the file descriptors of h, once the calls to Do in progress
+// returned.
+func (h *Handle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	err := h.ns.close()
+	if selfErr := h.selfNs.close(); err == nil {
+		err = selfErr
+	}
+	return err
+}
+
+// Pool keeps a Handle per network namespace name. A Handle is reopened
+// when its namespace was deleted and created again. The zero value is
+// ready to use.
+type Pool struct {
+	mu      sync.Mutex
+	handles map[string]*Handle
+}
+
+// DefaultPool is the Pool used by the VRF dialers of gnmi and gnmireverse.
+var DefaultPool = &Pool{}
+
+// Get returns the Handle of the network namespace nsName, opening it if
+// needed. The Handle belongs to the pool and must not be closed.
+func (p *Pool) Get(nsName string) (*Handle, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, ok := p.handles[nsName]
+	if ok {
+		info, err := statNs(nsPath(nsName))
+		if err == nil && h.info != nil && os.SameFile(info, h.info) {
+			return h, nil
+		}
+		// The namespace is gone or was recreated.
+		delete(p.handles, nsName)
+		go h.Close()
+	}
+	h, err := Open(nsName)
+	if err != nil {
+		return nil, err
+	}
+	if p.handles == nil {
+		p.handles = map[string]*Handle{}
+	}
+	p.handles[nsName] = h
+	return h, nil
+}
+
+// Do calls cb in the network namespace nsName like the package-level Do,
+// reusing the Handle of the namespace. If nsName is empty, cb is called in
+// the caller's namespace.
+func (p *Pool) Do(nsName string, cb Callback) error {
+	if nsName == "" {
+		return cb()
+	}
+	for {
+		h, err := p.Get(nsName)
+		if err != nil {
+			return err
+		}
+		h.mu.RLock()
+		if h.closed {
+			// Replaced by a concurrent Get since.
+			h.mu.RUnlock()
+			continue
+		}
+		err = h.do(cb)
+		h.mu.RUnlock()
+		return err
+	}
+}
+
+// Close closes the Handles of the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var err error
+	for name, h := range p.handles {
+		if closeErr := h.Close(); err == nil {
+			err = closeErr
+		}
+		delete(p.handles, name)
+	}
+	return err
+}
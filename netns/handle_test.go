@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package netns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type namedHandle string
+
+func (h namedHandle) close() error {
+	return nil
+}
+
+func (h namedHandle) fd() int {
+	return 0
+}
+
+func TestPool(t *testing.T) {
+	var opened []string
+	oldGetNs := getNs
+	getNs = func(nsName string) (handle, error) {
+		opened = append(opened, nsName)
+		return namedHandle(nsName), nil
+	}
+	defer func() {
+		getNs = oldGetNs
+	}()
+
+	var setNsCalls []string
+	oldSetNs := setNs
+	setNs = func(h handle) error {
+		setNsCalls = append(setNsCalls, string(h.(namedHandle)))
+		return nil
+	}
+	defer func() {
+		setNs = oldSetNs
+	}()
+
+	// Stand-ins for the namespace file, before and after it is recreated.
+	dir := t.TempDir()
+	nsFile := filepath.Join(dir, "ns")
+	recreatedNsFile := filepath.Join(dir, "recreated")
+	for _, f := range []string{nsFile, recreatedNsFile} {
+		if err := os.WriteFile(f, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	currentNsFile := nsFile
+	oldStatNs := statNs
+	statNs = func(string) (os.FileInfo, error) {
+		return os.Stat(currentNsFile)
+	}
+	defer func() {
+		statNs = oldStatNs
+	}()
+
+	var p Pool
+	do := func() {
+		t.Helper()
+		var called bool
+		if err := p.Do("ns-red", func() error {
+			called = true
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !called {
+			t.Fatal("callback not called")
+		}
+	}
+
+	do()
+	do()
+	if len(opened) != 2 {
+		t.Errorf("expected the namespace to be opened once, opened: %v", opened)
+	}
+	nsPath := netNsRunDir + "ns-red"
+	expected := []string{nsPath, selfNsFile, nsPath, selfNsFile}
+	if len(setNsCalls) != len(expected) {
+		t.Fatalf("expected setNs calls %v, got %v", expected, setNsCalls)
+	}
+	for i := range expected {
+		if setNsCalls[i] != expected[i] {
+			t.Fatalf("expected setNs calls %v, got %v", expected, setNsCalls)
+		}
+	}
+
+	// The namespace is recreated: the handle must be reopened.
+	currentNsFile = recreatedNsFile
+	h, err := p.Get("ns-red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != 4 {
+		t.Errorf("expected the namespace to be reopened, opened: %v", opened)
+	}
+	if h2, _ := p.Get("ns-red"); h2 != h {
+		t.Error("expected the reopened handle to be reused")
+	}
+
+	// The namespaces given by path are opened as is.
+	setNsCalls = nil
+	if err := p.Do("/proc/1234/ns/net", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(setNsCalls) != 2 || setNsCalls[0] != "/proc/1234/ns/net" {
+		t.Errorf("expected setNs calls [/proc/1234/ns/net %s], got %v", selfNsFile,
+			setNsCalls)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Do(func() error { return nil }); err == nil {
+		t.Error("expected an error calling Do on a closed handle")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package netns
+
+import "github.com/aristanetworks/goarista/logger"
+
+// Namespaces are the network namespace operations. Code depending on them
+// rather than on the functions of this package can be tested with the fake
+// of the netnstest package, without real namespaces.
+type Namespaces interface {
+	// Do calls cb in the network namespace nsName, like the function Do.
+	Do(nsName string, cb Callback) error
+	// ParseAddress parses a [<vrf-name>/]address:port address, like the
+	// function ParseAddress.
+	ParseAddress(address string) (nsName string, addr string, err error)
+	// NewWatcher returns a Watcher of the network namespaces.
+	NewWatcher(logger logger.Logger) (Watcher, error)
+}
+
+// Watcher watches the creation and deletion of network namespaces, like
+// an NSWatcher.
+type Watcher interface {
+	Watch(nsName string, callbacks NSCallbacks) error
+	Unwatch(nsName string)
+	Close() error
+}
+
+var _ Watcher = (*NSWatcher)(nil)
+
+// System are the Namespaces of the system, implemented by the functions of
+// this package.
+var System Namespaces = systemNamespaces{}
+
+type systemNamespaces struct{}
+
+func (systemNamespaces) Do(nsName string, cb Callback) error {
+	return Do(nsName, cb)
+}
+
+func (systemNamespaces) ParseAddress(address string) (string, string, error) {
+	return ParseAddress(address)
+}
+
+func (systemNamespaces) NewWatcher(logger logger.Logger) (Watcher, error) {
+	return NewNSWatcher(logger)
+}
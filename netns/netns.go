@@ -9,6 +9,7 @@ package netns
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 )
 
@@ -30,10 +31,20 @@ type handle interface {
 // The file descriptor associated with a network namespace
 type nsHandle int
 
+// nsPath returns the path of the file of the network namespace nsName: nsName
+// itself if it is an absolute path, such as /proc/<pid>/ns/net, and its file
+// under /var/run/netns otherwise.
+func nsPath(nsName string) string {
+	if filepath.IsAbs(nsName) {
+		return nsName
+	}
+	return netNsRunDir + nsName
+}
+
 // setNsByName wraps setNs, allowing specification of the network namespace by name.
 // It returns the file descriptor mapped to the given network namespace.
 func setNsByName(nsName string) error {
-	netPath := netNsRunDir + nsName
+	netPath := nsPath(nsName)
 	handle, err := getNs(netPath)
 	if err != nil {
 		return fmt.Errorf("Failed to getNs: %s", err)
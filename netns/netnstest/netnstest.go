@@ -0,0 +1,220 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// Package netnstest provides an in-memory fake of the network namespace
+// operations of the netns package, to test the code using them on any
+// platform and without the privileges required by real namespaces.
+package netnstest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aristanetworks/goarista/logger"
+	"github.com/aristanetworks/goarista/netns"
+)
+
+// Namespaces is a fake netns.Namespaces. The callbacks given to Do are
+// called in the goroutine of the caller and only get the name of the
+// namespace they are called in from Current.
+type Namespaces struct {
+	mu         sync.Mutex
+	namespaces map[string]bool
+	doErrs     map[string]error
+	current    []string
+	calls      []string
+	watchers   map[*Watcher]struct{}
+	// cbMu serializes the calls of the callbacks of the watchers, like
+	// the watch goroutine of an NSWatcher does.
+	cbMu sync.Mutex
+}
+
+var _ netns.Namespaces = (*Namespaces)(nil)
+
+// NewNamespaces returns fake Namespaces with the namespaces nsNames.
+func NewNamespaces(nsNames ...string) *Namespaces {
+	n := &Namespaces{
+		namespaces: map[string]bool{},
+		doErrs:     map[string]error{},
+		watchers:   map[*Watcher]struct{}{},
+	}
+	for _, nsName := range nsNames {
+		n.namespaces[nsName] = true
+	}
+	return n
+}
+
+// Add creates the namespace nsName, calling the Up callbacks watching it.
+func (n *Namespaces) Add(nsName string) {
+	n.cbMu.Lock()
+	defer n.cbMu.Unlock()
+	n.mu.Lock()
+	if n.namespaces[nsName] {
+		n.mu.Unlock()
+		return
+	}
+	n.namespaces[nsName] = true
+	callbacks := n.callbacks(nsName)
+	n.mu.Unlock()
+	for _, cbs := range callbacks {
+		if cbs.Up != nil {
+			cbs.Up()
+		}
+	}
+}
+
+// Remove deletes the namespace nsName, calling the Down callbacks watching
+// it.
+func (n *Namespaces) Remove(nsName string) {
+	n.cbMu.Lock()
+	defer n.cbMu.Unlock()
+	n.mu.Lock()
+	if !n.namespaces[nsName] {
+		n.mu.Unlock()
+		return
+	}
+	delete(n.namespaces, nsName)
+	callbacks := n.callbacks(nsName)
+	n.mu.Unlock()
+	for _, cbs := range callbacks {
+		if cbs.Down != nil {
+			cbs.Down()
+		}
+	}
+}
+
+// callbacks returns the callbacks watching nsName. n.mu must be held.
+func (n *Namespaces) callbacks(nsName string) []netns.NSCallbacks {
+	var callbacks []netns.NSCallbacks
+	for w := range n.watchers {
+		if cbs, ok := w.namespaces[nsName]; ok {
+			callbacks = append(callbacks, cbs)
+		}
+	}
+	return callbacks
+}
+
+// Exists returns whether the namespace nsName exists.
+func (n *Namespaces) Exists(nsName string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.namespaces[nsName]
+}
+
+// SetDoError makes Do fail with err for the namespace nsName, or succeed
+// again if err is nil.
+func (n *Namespaces) SetDoError(nsName string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err == nil {
+		delete(n.doErrs, nsName)
+	} else {
+		n.doErrs[nsName] = err
+	}
+}
+
+// Do calls cb "in" the namespace nsName, which must exist unless it is
+// empty, in which case cb is called in the namespace of the caller as
+// with netns.Do.
+func (n *Namespaces) Do(nsName string, cb netns.Callback) error {
+	n.mu.Lock()
+	n.calls = append(n.calls, nsName)
+	if err := n.doErrs[nsName]; err != nil {
+		n.mu.Unlock()
+		return err
+	}
+	if nsName != "" && !n.namespaces[nsName] {
+		n.mu.Unlock()
+		return fmt.Errorf("Failed to set the namespace to %s: no such namespace", nsName)
+	}
+	n.current = append(n.current, nsName)
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		n.current = n.current[:len(n.current)-1]
+		n.mu.Unlock()
+	}()
+	return cb()
+}
+
+// Current returns the namespace of the innermost callback being called by
+// Do, or "" if there is none. It is only meaningful when Do is not called
+// concurrently.
+func (n *Namespaces) Current() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.current) == 0 {
+		return ""
+	}
+	return n.current[len(n.current)-1]
+}
+
+// Calls returns the namespaces Do was called with, in order.
+func (n *Namespaces) Calls() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.calls...)
+}
+
+// ParseAddress parses address with netns.ParseAddress.
+func (n *Namespaces) ParseAddress(address string) (string, string, error) {
+	return netns.ParseAddress(address)
+}
+
+// NewWatcher returns a Watcher of n.
+func (n *Namespaces) NewWatcher(logger logger.Logger) (netns.Watcher, error) {
+	w := &Watcher{n: n, namespaces: map[string]netns.NSCallbacks{}}
+	n.mu.Lock()
+	n.watchers[w] = struct{}{}
+	n.mu.Unlock()
+	return w, nil
+}
+
+// Watcher is a fake netns.Watcher of the namespaces of a Namespaces. Its
+// callbacks are called by Watch, Add and Remove.
+type Watcher struct {
+	n          *Namespaces
+	namespaces map[string]netns.NSCallbacks
+	closed     bool
+}
+
+// Watch starts watching the namespace nsName, calling Up before returning
+// if it exists.
+func (w *Watcher) Watch(nsName string, callbacks netns.NSCallbacks) error {
+	w.n.cbMu.Lock()
+	defer w.n.cbMu.Unlock()
+	w.n.mu.Lock()
+	if w.closed {
+		w.n.mu.Unlock()
+		return errors.New("NSWatcher is closed")
+	}
+	if _, ok := w.namespaces[nsName]; ok {
+		w.n.mu.Unlock()
+		return fmt.Errorf("namespace %s is already watched", nsName)
+	}
+	w.namespaces[nsName] = callbacks
+	exists := w.n.namespaces[nsName]
+	w.n.mu.Unlock()
+	if exists && callbacks.Up != nil {
+		callbacks.Up()
+	}
+	return nil
+}
+
+// Unwatch stops watching the namespace nsName. Down is not called.
+func (w *Watcher) Unwatch(nsName string) {
+	w.n.mu.Lock()
+	defer w.n.mu.Unlock()
+	delete(w.namespaces, nsName)
+}
+
+// Close stops watching all the namespaces.
+func (w *Watcher) Close() error {
+	w.n.mu.Lock()
+	defer w.n.mu.Unlock()
+	w.closed = true
+	delete(w.n.watchers, w)
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package netnstest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aristanetworks/goarista/netns"
+	"github.com/aristanetworks/goarista/test"
+)
+
+func TestDo(t *testing.T) {
+	n := NewNamespaces("ns-mgmt")
+	var ns netns.Namespaces = n
+	nsName, addr, err := ns.ParseAddress("mgmt/10.0.0.1:6030")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nsName != "ns-mgmt" || addr != "10.0.0.1:6030" {
+		t.Errorf("unexpected namespace %q and address %q", nsName, addr)
+	}
+
+	var current []string
+	record := func() error {
+		current = append(current, n.Current())
+		return nil
+	}
+	if err := ns.Do(nsName, func() error {
+		record()
+		return ns.Do("", record)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ns.Do("ns-missing", record); err == nil {
+		t.Error("expected an error for a missing namespace")
+	}
+	errDo := errors.New("failed")
+	n.SetDoError("ns-mgmt", errDo)
+	if err := ns.Do("ns-mgmt", record); err != errDo {
+		t.Errorf("expected %s, got %v", errDo, err)
+	}
+	n.SetDoError("ns-mgmt", nil)
+	if err := ns.Do("ns-mgmt", record); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"ns-mgmt", "", "ns-mgmt"}; !test.DeepEqual(expected, current) {
+		t.Errorf("expected the callbacks in %q, got %q", expected, current)
+	}
+	expectedCalls := []string{"ns-mgmt", "", "ns-missing", "ns-mgmt", "ns-mgmt"}
+	if calls := n.Calls(); !test.DeepEqual(expectedCalls, calls) {
+		t.Errorf("expected calls %q, got %q", expectedCalls, calls)
+	}
+	if c := n.Current(); c != "" {
+		t.Errorf("expected no current namespace, got %q", c)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	n := NewNamespaces("ns-a")
+	w, err := n.NewWatcher(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	callbacks := func(nsName string) netns.NSCallbacks {
+		return netns.NSCallbacks{
+			Up:   func() { events = append(events, "up "+nsName) },
+			Down: func() { events = append(events, "down "+nsName) },
+		}
+	}
+	for _, nsName := range []string{"ns-a", "ns-b", "ns-c"} {
+		if err := w.Watch(nsName, callbacks(nsName)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Watch("ns-a", callbacks("ns-a")); err == nil {
+		t.Error("expected an error watching ns-a twice")
+	}
+	n.Add("ns-b")
+	n.Add("ns-b")
+	n.Add("ns-d")
+	n.Remove("ns-a")
+	w.Unwatch("ns-b")
+	n.Remove("ns-b")
+	if n.Exists("ns-b") || !n.Exists("ns-d") {
+		t.Error("unexpected namespaces")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	n.Add("ns-c")
+	if err := w.Watch("ns-d", callbacks("ns-d")); err == nil {
+		t.Error("expected an error watching after Close")
+	}
+
+	if expected := []string{"up ns-a", "up ns-b", "down ns-a"}; !test.DeepEqual(expected,
+		events) {
+		t.Errorf("expected events %q, got %q", expected, events)
+	}
+}
@@ -59,3 +59,13 @@ func NewNSListenerWithCustomListener(nsName string, addr *net.TCPAddr, logger lo
 	}
 	return newNSListenerWithDir(nsDir, nsName, addr, logger, listenerCreator)
 }
+
+// NewNSWatcher creates an NSWatcher for the network namespaces in the directory where they are
+// mounted.
+func NewNSWatcher(logger logger.Logger) (*NSWatcher, error) {
+	nsDir, err := getNsDir()
+	if err != nil {
+		return nil, err
+	}
+	return newNSWatcherWithDir(nsDir, logger)
+}
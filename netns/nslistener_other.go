@@ -8,6 +8,7 @@
 package netns
 
 import (
+	"errors"
 	"net"
 
 	"github.com/aristanetworks/goarista/dscp"
@@ -35,3 +36,8 @@ func NewNSListenerWithCustomListener(nsName string, addr *net.TCPAddr, logger lo
 	listenerCreator ListenerCreator) (net.Listener, error) {
 	return makeListener(nsName, listenerCreator)
 }
+
+// NewNSWatcher is not supported outside of Linux.
+func NewNSWatcher(logger logger.Logger) (*NSWatcher, error) {
+	return nil, errors.New("network namespaces are only supported on Linux")
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package netns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aristanetworks/fsnotify"
+	"github.com/aristanetworks/goarista/logger"
+)
+
+// NSCallbacks are the functions an NSWatcher calls for a network namespace.
+type NSCallbacks struct {
+	// Up is called when the namespace is created and mounted, or when it
+	// already is at the time it gets watched.
+	Up func()
+	// Down is called when the namespace is deleted after Up was called.
+	Down func()
+}
+
+type watchedNS struct {
+	callbacks NSCallbacks
+	mounted   bool
+	// gen identifies the last creation or deletion of the namespace, to
+	// discard the mount waits of the previous ones.
+	gen uint64
+}
+
+type mountEvent struct {
+	nsName string
+	gen    uint64
+}
+
+// NSWatcher watches the creation and deletion of many network namespaces with
+// a single inotify instance, where one nsListener uses one per namespace.
+// The callbacks of all the namespaces are called from one goroutine, so they
+// should not block and must not call the methods of the NSWatcher.
+type NSWatcher struct {
+	nsDir      string
+	watcher    *fsnotify.Watcher
+	logger     logger.Logger
+	namespaces map[string]*watchedNS
+	gen        uint64
+	requests   chan func()
+	mounts     chan mountEvent
+	done       chan struct{}
+	stopped    chan struct{}
+	closeOnce  sync.Once
+}
+
+func newNSWatcherWithDir(nsDir string, logger logger.Logger) (*NSWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = w.Add(nsDir); err != nil {
+		w.Close()
+		return nil, err
+	}
+	nsw := &NSWatcher{
+		nsDir:      nsDir,
+		watcher:    w,
+		logger:     logger,
+		namespaces: map[string]*watchedNS{},
+		requests:   make(chan func()),
+		mounts:     make(chan mountEvent),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go nsw.watch()
+	return nsw, nil
+}
+
+// Watch starts watching the network namespace nsName, calling the callbacks
+// when it comes and goes. Up is called before Watch returns if the namespace
+// already exists.
+func (w *NSWatcher) Watch(nsName string, callbacks NSCallbacks) error {
+	errc := make(chan error, 1)
+	if !w.do(func() { errc <- w.add(nsName, callbacks) }) {
+		return errors.New("NSWatcher is closed")
+	}
+	return <-errc
+}
+
+// Unwatch stops watching the network namespace nsName. Down is not called.
+func (w *NSWatcher) Unwatch(nsName string) {
+	done := make(chan struct{})
+	if w.do(func() {
+		delete(w.namespaces, nsName)
+		close(done)
+	}) {
+		<-done
+	}
+}
+
+// Close stops watching all the network namespaces.
+func (w *NSWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+	<-w.stopped
+	return nil
+}
+
+// do runs f in the watch goroutine and returns whether it did.
+func (w *NSWatcher) do(f func()) bool {
+	select {
+	case w.requests <- f:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *NSWatcher) add(nsName string, callbacks NSCallbacks) error {
+	if _, ok := w.namespaces[nsName]; ok {
+		return fmt.Errorf("namespace %s is already watched", nsName)
+	}
+	ns := &watchedNS{callbacks: callbacks}
+	w.namespaces[nsName] = ns
+	if _, err := os.Stat(filepath.Join(w.nsDir, nsName)); err == nil {
+		w.created(nsName, ns)
+	}
+	return nil
+}
+
+func (w *NSWatcher) created(nsName string, ns *watchedNS) {
+	if ns.mounted {
+		return
+	}
+	w.gen++
+	ns.gen = w.gen
+	if hasMount(filepath.Join(w.nsDir, nsName), w.logger) {
+		w.up(ns)
+		return
+	}
+	go w.waitForMount(nsName, ns.gen)
+}
+
+func (w *NSWatcher) up(ns *watchedNS) {
+	ns.mounted = true
+	if ns.callbacks.Up != nil {
+		ns.callbacks.Up()
+	}
+}
+
+func (w *NSWatcher) removed(ns *watchedNS) {
+	w.gen++
+	ns.gen = w.gen
+	if !ns.mounted {
+		return
+	}
+	ns.mounted = false
+	if ns.callbacks.Down != nil {
+		ns.callbacks.Down()
+	}
+}
+
+// waitForMount waits for the namespace file to be mounted without blocking
+// the events of the other namespaces.
+func (w *NSWatcher) waitForMount(nsName string, gen uint64) {
+	nsFile := filepath.Join(w.nsDir, nsName)
+	for !hasMount(nsFile, w.logger) {
+		select {
+		case <-time.After(time.Second):
+		case <-w.done:
+			return
+		}
+		if _, err := os.Stat(nsFile); err != nil {
+			w.logger.Infof("error stating %s: %v", nsFile, err)
+			return
+		}
+	}
+	select {
+	case w.mounts <- mountEvent{nsName: nsName, gen: gen}:
+	case <-w.done:
+	}
+}
+
+func (w *NSWatcher) watch() {
+	defer close(w.stopped)
+	for {
+		select {
+		case <-w.done:
+			go func() {
+				// Drain the events, otherwise closing the watcher will get stuck
+				for range w.watcher.Events {
+				}
+			}()
+			w.watcher.Close()
+			return
+		case f := <-w.requests:
+			f()
+		case m := <-w.mounts:
+			if ns, ok := w.namespaces[m.nsName]; ok && ns.gen == m.gen && !ns.mounted {
+				w.up(ns)
+			}
+		case err := <-w.watcher.Errors:
+			w.logger.Infof("Error watching %s: %v", w.nsDir, err)
+		case ev := <-w.watcher.Events:
+			if filepath.Dir(ev.Name) != filepath.Clean(w.nsDir) {
+				continue
+			}
+			ns, ok := w.namespaces[filepath.Base(ev.Name)]
+			if !ok {
+				continue
+			}
+			if ev.Op&fsnotify.Create == fsnotify.Create {
+				w.created(filepath.Base(ev.Name), ns)
+			}
+			if ev.Op&fsnotify.Remove == fsnotify.Remove {
+				w.removed(ns)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package netns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aristanetworks/goarista/glog"
+	"github.com/aristanetworks/goarista/logger"
+)
+
+func TestNSWatcher(t *testing.T) {
+	hasMount = func(_ string, _ logger.Logger) bool {
+		return true
+	}
+
+	nsDir := t.TempDir()
+	// ns-a exists before being watched, ns-b gets created afterwards.
+	if err := os.WriteFile(filepath.Join(nsDir, "ns-a"), nil, 0777); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newNSWatcherWithDir(nsDir, &glog.Glog{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	events := make(chan string, 10)
+	callbacks := func(nsName string) NSCallbacks {
+		return NSCallbacks{
+			Up:   func() { events <- nsName + " up" },
+			Down: func() { events <- nsName + " down" },
+		}
+	}
+	expect := func(event string) {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e != event {
+				t.Fatalf("expected %q, got %q", event, e)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", event)
+		}
+	}
+
+	for _, nsName := range []string{"ns-a", "ns-b"} {
+		if err := w.Watch(nsName, callbacks(nsName)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect("ns-a up")
+	if err := w.Watch("ns-a", NSCallbacks{}); err == nil {
+		t.Error("expected an error watching ns-a twice")
+	}
+
+	for i := 0; i < 3; i++ {
+		nsFile := filepath.Join(nsDir, "ns-b")
+		if err := os.WriteFile(nsFile, nil, 0777); err != nil {
+			t.Fatal(err)
+		}
+		expect("ns-b up")
+		if err := os.Remove(nsFile); err != nil {
+			t.Fatal(err)
+		}
+		expect("ns-b down")
+	}
+
+	// Unwatched and unknown namespaces are ignored.
+	w.Unwatch("ns-a")
+	if err := os.Remove(filepath.Join(nsDir, "ns-a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nsDir, "ns-c"), nil, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nsDir, "ns-b"), nil, 0777); err != nil {
+		t.Fatal(err)
+	}
+	expect("ns-b up")
+
+	w.Close()
+	if err := w.Watch("ns-d", NSCallbacks{}); err == nil {
+		t.Error("expected an error watching with a closed NSWatcher")
+	}
+}
@@ -6,9 +6,12 @@
 package client
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/aristanetworks/glog"
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -33,21 +36,37 @@ type Client struct {
 
 // New creates a new gRPC client and connects it
 func New(username, password, addr string, opts []grpc.DialOption) *Client {
+	c, _, err := dial(context.Background(), 0, username, password, addr, opts)
+	if err != nil {
+		glog.Fatalf("Failed to dial: %s", err)
+	}
+	return c
+}
+
+// dial connects a new client to addr, giving up after timeout if not zero.
+// The connection must be closed once the client is no longer used.
+func dial(ctx context.Context, timeout time.Duration, username, password, addr string,
+	opts []grpc.DialOption) (*Client, *grpc.ClientConn, error) {
 	device := addr
 	if !strings.ContainsRune(addr, ':') {
 		addr += ":" + defaultPort
 	}
 	// Make sure we don't move past the grpc.Dial() call until we actually
 	// established an HTTP/2 connection successfully.
-	opts = append(opts, grpc.WithBlock())
-	conn, err := grpc.Dial(addr, opts...)
+	opts = append(opts[:len(opts):len(opts)], grpc.WithBlock())
+	dialCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	conn, err := grpc.DialContext(dialCtx, addr, opts...)
 	if err != nil {
-		glog.Fatalf("Failed to dial: %s", err)
+		return nil, nil, err
 	}
 	glog.Infof("Connected to %s", addr)
 	client := gnmi.NewGNMIClient(conn)
 
-	ctx := context.Background()
 	if username != "" {
 		ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(
 			"username", username,
@@ -57,7 +76,7 @@ func New(username, password, addr string, opts []grpc.DialOption) *Client {
 		client: client,
 		device: device,
 		ctx:    ctx,
-	}
+	}, conn, nil
 }
 
 // Get sends a get request and returns the responses
@@ -86,9 +105,17 @@ func (c *Client) Get(path string) []*gnmi.Notification {
 func (c *Client) Subscribe(wg *sync.WaitGroup, subscriptions []string,
 	publish PublishFunc) {
 	defer wg.Done()
+	if err := c.subscribe(subscriptions, publish); err != nil {
+		glog.Fatal(err)
+	}
+}
+
+// subscribe sends the subscriptions and publishes the responses until the
+// stream ends. It returns nil if the server closed the stream.
+func (c *Client) subscribe(subscriptions []string, publish PublishFunc) error {
 	stream, err := c.client.Subscribe(c.ctx)
 	if err != nil {
-		glog.Fatalf("Subscribe failed: %s", err)
+		return fmt.Errorf("subscribe failed: %s", err)
 	}
 	defer stream.CloseSend()
 
@@ -108,7 +135,7 @@ func (c *Client) Subscribe(wg *sync.WaitGroup, subscriptions []string,
 		glog.Infof("Sending subscribe request: %s", sub)
 		err = stream.Send(sub)
 		if err != nil {
-			glog.Fatalf("Failed to subscribe: %s", err)
+			return fmt.Errorf("failed to subscribe: %s", err)
 		}
 	}
 
@@ -116,14 +143,14 @@ func (c *Client) Subscribe(wg *sync.WaitGroup, subscriptions []string,
 		resp, err := stream.Recv()
 		if err != nil {
 			if err != io.EOF {
-				glog.Fatalf("Error received from the server: %s", err)
+				return fmt.Errorf("error received from the server: %s", err)
 			}
-			return
+			return nil
 		}
 		switch resp := resp.Response.(type) {
 		case *gnmi.SubscribeResponse_SyncResponse:
 			if !resp.SyncResponse {
-				panic("initial sync failed," +
+				return errors.New("initial sync failed," +
 					" check that you're using a client compatible with the server")
 			}
 		}
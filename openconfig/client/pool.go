@@ -0,0 +1,152 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// DeviceState is the state of the subscription of a device in a Pool.
+type DeviceState int
+
+const (
+	// DeviceWaiting means the device waits for a dial slot.
+	DeviceWaiting DeviceState = iota
+	// DeviceDialing means the device is being dialed.
+	DeviceDialing
+	// DeviceSubscribed means the device is connected and subscribed.
+	DeviceSubscribed
+	// DeviceFailed means the dial or the subscription failed. The
+	// device is dialed again after the RetryInterval of the Pool,
+	// if any.
+	DeviceFailed
+	// DeviceDone means the subscription of the device ended and will
+	// not be retried.
+	DeviceDone
+)
+
+func (s DeviceState) String() string {
+	switch s {
+	case DeviceWaiting:
+		return "waiting"
+	case DeviceDialing:
+		return "dialing"
+	case DeviceSubscribed:
+		return "subscribed"
+	case DeviceFailed:
+		return "failed"
+	case DeviceDone:
+		return "done"
+	}
+	return "unknown"
+}
+
+// StatusFunc is called on each state change of the subscription of a
+// device, with the error causing it if the state is DeviceFailed. It
+// may be called concurrently for different devices.
+type StatusFunc func(addr string, state DeviceState, err error)
+
+// Pool subscribes to many devices from one process. All the devices
+// share the same dial options, so the TLS config is only loaded once,
+// and the number of concurrent dials is bounded.
+type Pool struct {
+	Username string
+	Password string
+	// Opts are the dial options shared by all the devices.
+	Opts []grpc.DialOption
+	// MaxDials is the maximum number of concurrent dials, defaulting
+	// to DefaultMaxDials.
+	MaxDials int
+	// DialTimeout bounds the time to connect to a device, if not zero.
+	DialTimeout time.Duration
+	// RetryInterval is the time after which a failed device is dialed
+	// again. If zero, failed devices are not retried.
+	RetryInterval time.Duration
+	// Status, if not nil, is called on each state change of a device.
+	Status StatusFunc
+}
+
+// DefaultMaxDials is the default maximum number of concurrent dials of a
+// Pool.
+const DefaultMaxDials = 64
+
+// Run subscribes to the subscriptions on all the addrs and publishes the
+// responses with publish, until ctx is done or no subscription is left.
+func (p *Pool) Run(ctx context.Context, addrs, subscriptions []string, publish PublishFunc) {
+	maxDials := p.MaxDials
+	if maxDials <= 0 {
+		maxDials = DefaultMaxDials
+	}
+	dialSlots := make(chan struct{}, maxDials)
+	var wg sync.WaitGroup
+	wg.Add(len(addrs))
+	for _, addr := range addrs {
+		addr := addr
+		go func() {
+			defer wg.Done()
+			p.runDevice(ctx, dialSlots, addr, subscriptions, publish)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pool) setStatus(addr string, state DeviceState, err error) {
+	if p.Status != nil {
+		p.Status(addr, state, err)
+	}
+}
+
+func (p *Pool) runDevice(ctx context.Context, dialSlots chan struct{}, addr string,
+	subscriptions []string, publish PublishFunc) {
+	defer p.setStatus(addr, DeviceDone, nil)
+	for {
+		err := p.subscribeDevice(ctx, dialSlots, addr, subscriptions, publish)
+		if err == nil || ctx.Err() != nil || p.RetryInterval == 0 {
+			// The device closed the stream, the pool was stopped or
+			// failed devices are not retried.
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.RetryInterval):
+		}
+	}
+}
+
+// subscribeDevice dials addr and subscribes to it until the stream ends.
+// The state changes caused by the dial are reported before releasing the
+// dial slot, so that no more than MaxDials devices are ever reported as
+// dialing.
+func (p *Pool) subscribeDevice(ctx context.Context, dialSlots chan struct{}, addr string,
+	subscriptions []string, publish PublishFunc) error {
+	p.setStatus(addr, DeviceWaiting, nil)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case dialSlots <- struct{}{}:
+	}
+	p.setStatus(addr, DeviceDialing, nil)
+	c, conn, err := dial(ctx, p.DialTimeout, p.Username, p.Password, addr, p.Opts)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.setStatus(addr, DeviceFailed, err)
+		}
+		<-dialSlots
+		return err
+	}
+	defer conn.Close()
+	p.setStatus(addr, DeviceSubscribed, nil)
+	<-dialSlots
+	err = c.subscribe(subscriptions, publish)
+	if err != nil && ctx.Err() == nil {
+		p.setStatus(addr, DeviceFailed, err)
+	}
+	return err
+}
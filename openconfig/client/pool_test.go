@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package client
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// gnmiServer sends a sync response and closes the stream.
+type gnmiServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (*gnmiServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	return stream.Send(&gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
+
+func startServer(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	gnmi.RegisterGNMIServer(s, &gnmiServer{})
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+	return l.Addr().String()
+}
+
+func TestPoolRun(t *testing.T) {
+	var addrs []string
+	for i := 0; i < 5; i++ {
+		addrs = append(addrs, startServer(t))
+	}
+	// Nothing listens on the address of a closed listener.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := l.Addr().String()
+	l.Close()
+	addrs = append(addrs, unreachable)
+
+	var (
+		mu        sync.Mutex
+		dialing   int
+		maxDials  int
+		states    = map[string][]DeviceState{}
+		published = map[string]int{}
+	)
+	p := &Pool{
+		Opts:        []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		MaxDials:    2,
+		DialTimeout: 200 * time.Millisecond,
+		Status: func(addr string, state DeviceState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			states[addr] = append(states[addr], state)
+			switch state {
+			case DeviceDialing:
+				dialing++
+				if dialing > maxDials {
+					maxDials = dialing
+				}
+			case DeviceSubscribed, DeviceFailed:
+				if states[addr][len(states[addr])-2] == DeviceDialing {
+					dialing--
+				}
+			}
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	p.Run(ctx, addrs, []string{"/"}, func(addr string, message proto.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		published[addr]++
+	})
+
+	if maxDials != 2 {
+		t.Errorf("expected at most 2 concurrent dials, got %d", maxDials)
+	}
+	for _, addr := range addrs {
+		exp := []DeviceState{DeviceWaiting, DeviceDialing, DeviceSubscribed, DeviceDone}
+		expPublished := 1
+		if addr == unreachable {
+			exp = []DeviceState{DeviceWaiting, DeviceDialing, DeviceFailed, DeviceDone}
+			expPublished = 0
+		}
+		if !stateEqual(states[addr], exp) {
+			t.Errorf("%s: expected states %v, got %v", addr, exp, states[addr])
+		}
+		if published[addr] != expPublished {
+			t.Errorf("%s: expected %d responses, got %d", addr, expPublished, published[addr])
+		}
+	}
+}
+
+func stateEqual(a, b []DeviceState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -90,6 +91,64 @@ func SubscribeResponseToJSON(resp *gnmi.SubscribeResponse) (string, error) {
 	return string(js), nil
 }
 
+// splitPath builds a Path out of the Elements of a string built by joinPath
+func splitPath(path string) *gnmi.Path {
+	if path == "" {
+		return nil
+	}
+	return &gnmi.Path{Element: strings.Split(path, "/")}
+}
+
+// NotificationFromJSON converts a JSON string built by NotificationToJSON back
+// into a Notification, to replay archived telemetry. The values are JSON
+// encoded, including the ones that were BYTES encoded, which were converted
+// into base64 strings, and the updates are sorted by path.
+func NotificationFromJSON(js string) (*gnmi.Notification, error) {
+	var m struct {
+		Notification *struct {
+			Timestamp json.Number                `json:"timestamp"`
+			Path      string                     `json:"path"`
+			Updates   map[string]json.RawMessage `json:"updates"`
+			Deletes   []string                   `json:"deletes"`
+		} `json:"notification"`
+	}
+	if err := json.Unmarshal([]byte(js), &m); err != nil {
+		return nil, fmt.Errorf("Malformed JSON notification: %s", err)
+	}
+	if m.Notification == nil {
+		return nil, fmt.Errorf("No notification in %q", js)
+	}
+	notif := &gnmi.Notification{
+		Prefix: splitPath(strings.TrimPrefix(m.Notification.Path, "/")),
+	}
+	if m.Notification.Timestamp != "" {
+		var err error
+		if notif.Timestamp, err = m.Notification.Timestamp.Int64(); err != nil {
+			return nil, fmt.Errorf("Malformed timestamp %q: %s",
+				m.Notification.Timestamp, err)
+		}
+	}
+	paths := make([]string, 0, len(m.Notification.Updates))
+	for path := range m.Notification.Updates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		var value bytes.Buffer
+		if err := json.Compact(&value, m.Notification.Updates[path]); err != nil {
+			return nil, err
+		}
+		notif.Update = append(notif.Update, &gnmi.Update{
+			Path:  splitPath(path),
+			Value: &gnmi.Value{Value: value.Bytes(), Type: gnmi.Encoding_JSON},
+		})
+	}
+	for _, del := range m.Notification.Deletes {
+		notif.Delete = append(notif.Delete, splitPath(del))
+	}
+	return notif, nil
+}
+
 // EscapeFunc is the escaping method for attribute names
 type EscapeFunc func(k string) string
 
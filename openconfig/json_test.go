@@ -11,6 +11,7 @@ import (
 	"github.com/aristanetworks/goarista/test"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestNotificationToMap(t *testing.T) {
@@ -142,3 +143,54 @@ func TestNotificationToMap(t *testing.T) {
 		}
 	}
 }
+
+func TestNotificationFromJSON(t *testing.T) {
+	notif := &gnmi.Notification{
+		Timestamp: 1594075239000000000,
+		Prefix:    &gnmi.Path{Element: []string{"foo", "bar"}},
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Element: []string{"a", "b"}},
+			Value: &gnmi.Value{
+				Value: []byte(`{"x":1.5,"y":[1,"z"]}`),
+				Type:  gnmi.Encoding_JSON,
+			},
+		}, {
+			Path:  &gnmi.Path{Element: []string{"c"}},
+			Value: &gnmi.Value{Value: []byte("18446744073709551615"), Type: gnmi.Encoding_JSON},
+		}},
+		Delete: []*gnmi.Path{{Element: []string{"d"}}, {Element: []string{"e", "f"}}},
+	}
+	js, err := NotificationToJSON(notif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := NotificationFromJSON(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(notif, actual) {
+		t.Errorf("expected %s, got %s", notif, actual)
+	}
+
+	actual, err = NotificationFromJSON(`{"notification": {"path": "/", "updates": {"a": "b"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &gnmi.Notification{Update: []*gnmi.Update{{
+		Path:  &gnmi.Path{Element: []string{"a"}},
+		Value: &gnmi.Value{Value: []byte(`"b"`), Type: gnmi.Encoding_JSON},
+	}}}
+	if !proto.Equal(expected, actual) {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	for _, js := range []string{
+		`{"syncResponse": true}`,
+		`{"notification": {"timestamp": 1.5}}`,
+		`not json`,
+	} {
+		if _, err := NotificationFromJSON(js); err == nil {
+			t.Errorf("expected an error for %s", js)
+		}
+	}
+}
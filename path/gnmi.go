@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package path
+
+import (
+	"fmt"
+
+	"github.com/aristanetworks/goarista/key"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// FromGNMI constructs a path from a gNMI path. Each PathElem
+// becomes an element with its name, followed by an element
+// with its keys as a map[string]interface{} of strings if it
+// has any, so that /interfaces/interface[name=Ethernet1]
+// becomes {"interfaces", "interface", {"name": "Ethernet1"}}.
+// The "*" names become Wildcards, the keys of value "*" are
+// kept as such. The deprecated Element field is used if Elem
+// is empty, and the origin and target of the path are dropped.
+// A nil gNMI path returns a key.Path{}.
+func FromGNMI(p *gnmi.Path) key.Path {
+	if len(p.GetElem()) == 0 {
+		result := make(key.Path, len(p.GetElement()))
+		for i, element := range p.GetElement() {
+			result[i] = fromGNMIName(element)
+		}
+		return result
+	}
+	n := len(p.Elem)
+	for _, elem := range p.Elem {
+		if len(elem.Key) > 0 {
+			n++
+		}
+	}
+	result := make(key.Path, 0, n)
+	for _, elem := range p.Elem {
+		result = append(result, fromGNMIName(elem.Name))
+		if len(elem.Key) > 0 {
+			keys := make(map[string]interface{}, len(elem.Key))
+			for k, v := range elem.Key {
+				keys[k] = v
+			}
+			result = append(result, key.New(keys))
+		}
+	}
+	return result
+}
+
+func fromGNMIName(name string) key.Key {
+	if name == "*" {
+		return Wildcard
+	}
+	return key.New(name)
+}
+
+// ToGNMI constructs a gNMI path from a path, the reverse of
+// FromGNMI. An element that is a map[string]interface{} sets
+// the keys of the PathElem of the preceding element, with its
+// values formatted as strings, Wildcards become "*" and the
+// other elements become PathElems named by their string
+// representation. It returns an error if a map follows another
+// map or starts the path.
+func ToGNMI(path key.Path) (*gnmi.Path, error) {
+	elems := make([]*gnmi.PathElem, 0, len(path))
+	for i, k := range path {
+		m, ok := k.Key().(map[string]interface{})
+		if !ok {
+			elems = append(elems, &gnmi.PathElem{Name: k.String()})
+			continue
+		}
+		if len(elems) == 0 || elems[len(elems)-1].Key != nil {
+			return nil, fmt.Errorf("keys %s at index %d of path %s follow no name",
+				k, i, path)
+		}
+		keys := make(map[string]string, len(m))
+		for name, v := range m {
+			keys[name] = keyValueString(v)
+		}
+		elems[len(elems)-1].Key = keys
+	}
+	return &gnmi.Path{Elem: elems}, nil
+}
+
+func keyValueString(v interface{}) string {
+	if k, err := key.TryNew(v); err == nil {
+		return k.String()
+	}
+	return fmt.Sprint(v)
+}
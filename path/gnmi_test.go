@@ -0,0 +1,152 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package path
+
+import (
+	"testing"
+
+	"github.com/aristanetworks/goarista/key"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func elem(name string, keys ...string) *gnmi.PathElem {
+	e := &gnmi.PathElem{Name: name}
+	for i := 0; i < len(keys); i += 2 {
+		if e.Key == nil {
+			e.Key = map[string]string{}
+		}
+		e.Key[keys[i]] = keys[i+1]
+	}
+	return e
+}
+
+func TestGNMI(t *testing.T) {
+	tcases := []struct {
+		gnmi *gnmi.Path
+		path key.Path
+	}{
+		{
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{}},
+			path: key.Path{},
+		}, {
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{elem("foo")}},
+			path: key.Path{key.New("foo")},
+		}, {
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{elem("foo"), elem("bar")}},
+			path: key.Path{key.New("foo"), key.New("bar")},
+		}, {
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{
+				elem("interfaces"), elem("interface", "name", "Ethernet1"), elem("state")}},
+			path: key.Path{key.New("interfaces"), key.New("interface"),
+				key.New(map[string]interface{}{"name": "Ethernet1"}), key.New("state")},
+		}, {
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{
+				elem("neighbor", "ip", "10.0.0.1", "vrf", "default"),
+				elem("route", "prefix", "10.0.0.0/8")}},
+			path: key.Path{key.New("neighbor"),
+				key.New(map[string]interface{}{"ip": "10.0.0.1", "vrf": "default"}),
+				key.New("route"), key.New(map[string]interface{}{"prefix": "10.0.0.0/8"})},
+		}, {
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{
+				elem("interfaces"), elem("*"), elem("interface", "name", "*")}},
+			path: key.Path{key.New("interfaces"), Wildcard, key.New("interface"),
+				key.New(map[string]interface{}{"name": "*"})},
+		}, {
+			gnmi: &gnmi.Path{Elem: []*gnmi.PathElem{elem(""), elem("a/b", "k", "v]")}},
+			path: key.Path{key.New(""), key.New("a/b"),
+				key.New(map[string]interface{}{"k": "v]"})},
+		},
+	}
+	for i, tcase := range tcases {
+		if p := FromGNMI(tcase.gnmi); !Equal(p, tcase.path) {
+			t.Errorf("Test %d: FromGNMI(%s): expected %s, got %s", i, tcase.gnmi, tcase.path, p)
+		}
+		p, err := ToGNMI(tcase.path)
+		if err != nil {
+			t.Errorf("Test %d: ToGNMI(%s): %s", i, tcase.path, err)
+		} else if !proto.Equal(p, tcase.gnmi) {
+			t.Errorf("Test %d: ToGNMI(%s): expected %s, got %s", i, tcase.path, tcase.gnmi, p)
+		}
+	}
+}
+
+func TestFromGNMI(t *testing.T) {
+	tcases := []struct {
+		in  *gnmi.Path
+		out key.Path
+	}{
+		{
+			in:  nil,
+			out: key.Path{},
+		}, {
+			in:  &gnmi.Path{},
+			out: key.Path{},
+		}, {
+			in:  &gnmi.Path{Element: []string{"foo", "*", "bar"}},
+			out: key.Path{key.New("foo"), Wildcard, key.New("bar")},
+		}, {
+			in: &gnmi.Path{Element: []string{"ignored"},
+				Elem: []*gnmi.PathElem{elem("foo")}},
+			out: key.Path{key.New("foo")},
+		}, {
+			in: &gnmi.Path{Origin: "openconfig", Target: "switch",
+				Elem: []*gnmi.PathElem{elem("foo", "k", "v")}},
+			out: key.Path{key.New("foo"), key.New(map[string]interface{}{"k": "v"})},
+		}, {
+			in:  &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "foo", Key: map[string]string{}}}},
+			out: key.Path{key.New("foo")},
+		},
+	}
+	for i, tcase := range tcases {
+		if p := FromGNMI(tcase.in); !Equal(p, tcase.out) {
+			t.Errorf("Test %d: expected %s, got %s", i, tcase.out, p)
+		}
+	}
+}
+
+func TestToGNMI(t *testing.T) {
+	tcases := []struct {
+		in  key.Path
+		out *gnmi.Path
+		err bool
+	}{
+		{
+			in:  nil,
+			out: &gnmi.Path{Elem: []*gnmi.PathElem{}},
+		}, {
+			in: key.Path{key.New("foo"), key.New(int64(5)), key.New(true),
+				key.New(uint32(7))},
+			out: &gnmi.Path{Elem: []*gnmi.PathElem{elem("foo"), elem("5"), elem("true"),
+				elem("7")}},
+		}, {
+			in: key.Path{key.New("foo"),
+				key.New(map[string]interface{}{"i": int64(5), "b": true, "s": "x"})},
+			out: &gnmi.Path{Elem: []*gnmi.PathElem{
+				elem("foo", "i", "5", "b", "true", "s", "x")}},
+		}, {
+			in:  key.Path{key.New(map[string]interface{}{"k": "v"})},
+			err: true,
+		}, {
+			in: key.Path{key.New("foo"), key.New(map[string]interface{}{"k": "v"}),
+				key.New(map[string]interface{}{"l": "w"})},
+			err: true,
+		},
+	}
+	for i, tcase := range tcases {
+		p, err := ToGNMI(tcase.in)
+		if tcase.err {
+			if err == nil {
+				t.Errorf("Test %d: expected an error, got %s", i, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: unexpected error: %s", i, err)
+		} else if !proto.Equal(p, tcase.out) {
+			t.Errorf("Test %d: expected %s, got %s", i, tcase.out, p)
+		}
+	}
+}
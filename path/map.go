@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/aristanetworks/goarista/hash"
 	"github.com/aristanetworks/goarista/key"
 	"github.com/aristanetworks/gomap"
 )
@@ -41,6 +42,9 @@ type MapOf[T any] struct {
 	ok       bool
 	wildcard *MapOf[T]
 	children *gomap.Map[key.Key, *MapOf[T]]
+	// size is the number of values registered in this map and its
+	// children.
+	size int
 }
 
 // Visit calls a function fn for every value in the Map
@@ -62,6 +66,8 @@ func (m *MapOf[T]) VisitPrefixes(p key.Path, fn func(v T) error) error {
 // registerd with a path that is prefixed by p. This method
 // can be used to visit every registered path if p is the
 // empty path (or root path) which prefixes all paths.
+// The order in which the values are visited is unspecified,
+// use IterPrefixed to visit them in a deterministic order.
 func (m *MapOf[T]) VisitPrefixed(p key.Path, fn func(v T) error) error {
 	return m.visit(suffix, p, fn)
 }
@@ -128,11 +134,126 @@ func (m *MapOf[T]) visitSubtree(fn func(v T) error) error {
 	return nil
 }
 
+// MapIterator is a lazily evaluated iterator over values of a MapOf,
+// returned by Iter and IterPrefixed. The values are visited in
+// depth-first order: the value registered with a path comes before
+// the values registered with longer paths it prefixes, a wildcard
+// comes before the other elements, and the other elements are
+// ordered by key.Compare. The Map must not be modified during the
+// iteration.
+//
+// Example:
+//
+//	for it := m.IterPrefixed(p); it.Next(); {
+//		v := it.Value()
+//		...
+//	}
+type MapIterator[T any] struct {
+	subtree bool
+	stack   []iterFrame[T]
+	val     T
+}
+
+type iterFrame[T any] struct {
+	m *MapOf[T]
+	// Remaining path to match to reach the nodes to visit.
+	p key.Path
+}
+
+// Iter returns an iterator over the values registered with a match
+// of a path p, like Visit.
+func (m *MapOf[T]) Iter(p key.Path) *MapIterator[T] {
+	return &MapIterator[T]{stack: []iterFrame[T]{{m: m, p: p}}}
+}
+
+// IterPrefixed returns an iterator over the values registered with a
+// path prefixed by p, like VisitPrefixed.
+func (m *MapOf[T]) IterPrefixed(p key.Path) *MapIterator[T] {
+	return &MapIterator[T]{subtree: true, stack: []iterFrame[T]{{m: m, p: p}}}
+}
+
+// Next advances the iterator to the next value and returns true, or
+// returns false if there are no more values.
+func (it *MapIterator[T]) Next() bool {
+	for len(it.stack) > 0 {
+		f := it.stack[len(it.stack)-1]
+		it.stack = it.stack[:len(it.stack)-1]
+		// Frames are pushed in the reverse order of the visit.
+		if len(f.p) > 0 {
+			if next, ok := f.m.children.Get(f.p[0]); ok {
+				it.stack = append(it.stack, iterFrame[T]{m: next, p: f.p[1:]})
+			}
+			if f.m.wildcard != nil {
+				it.stack = append(it.stack, iterFrame[T]{m: f.m.wildcard, p: f.p[1:]})
+			}
+			continue
+		}
+		if it.subtree {
+			children := make([]key.Key, 0, f.m.children.Len())
+			for cit := f.m.children.Iter(); cit.Next(); {
+				children = append(children, cit.Key())
+			}
+			sort.Slice(children, func(i, j int) bool {
+				return key.Less(children[j], children[i])
+			})
+			for _, k := range children {
+				child, _ := f.m.children.Get(k)
+				it.stack = append(it.stack, iterFrame[T]{m: child})
+			}
+			if f.m.wildcard != nil {
+				it.stack = append(it.stack, iterFrame[T]{m: f.m.wildcard})
+			}
+		}
+		if f.m.ok {
+			it.val = f.m.val
+			return true
+		}
+	}
+	var zeroT T
+	it.val = zeroT
+	return false
+}
+
+// Value returns the current value of the iterator.
+func (it *MapIterator[T]) Value() T {
+	return it.val
+}
+
 // IsEmpty returns true if no paths have been registered, false otherwise.
 func (m *MapOf[T]) IsEmpty() bool {
 	return m.wildcard == nil && m.children.Len() == 0 && !m.ok
 }
 
+// Len returns the number of paths registered.
+func (m *MapOf[T]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.size
+}
+
+// Count returns the number of paths registered with p as a prefix,
+// including p itself. As with Get, a wildcard in p only matches the paths
+// registered with a wildcard at that position. Its time complexity is
+// linear with respect to the length of p.
+func (m *MapOf[T]) Count(p key.Path) int {
+	for _, element := range p {
+		if element.Equal(Wildcard) {
+			if m.wildcard == nil {
+				return 0
+			}
+			m = m.wildcard
+			continue
+		}
+		next, ok := m.children.Get(element)
+		if !ok {
+			return 0
+		}
+		m = next
+	}
+	return m.size
+}
+
 // Get returns the value registered with an exact match of a path p.
 // If there is no exact match for p, Get returns the zero value and false.
 // If p has an exact match and it is set to true, Get
@@ -189,13 +310,16 @@ func (m *MapOf[T]) GetLongestPrefix(p key.Path) (key.Path, T, bool) {
 }
 
 func newKeyMap[T any]() *gomap.Map[key.Key, *MapOf[T]] {
-	return gomap.New[key.Key, *MapOf[T]](func(a, b key.Key) bool { return a.Equal(b) }, key.Hash)
+	return hash.NewKeyMap[*MapOf[T]]()
 }
 
 // Set registers a path p with a value. If the path was already
 // registered with a value it returns false and true otherwise.
 func (m *MapOf[T]) Set(p key.Path, v T) bool {
+	var stack [16]*MapOf[T]
+	maps := stack[:0]
 	for _, element := range p {
+		maps = append(maps, m)
 		if element.Equal(Wildcard) {
 			if m.wildcard == nil {
 				m.wildcard = &MapOf[T]{}
@@ -215,6 +339,12 @@ func (m *MapOf[T]) Set(p key.Path, v T) bool {
 	}
 	set := !m.ok
 	m.val, m.ok = v, true
+	if set {
+		m.size++
+		for _, parent := range maps {
+			parent.size++
+		}
+	}
 	return set
 }
 
@@ -241,6 +371,12 @@ func (m *MapOf[T]) Delete(p key.Path) bool {
 	var zeroT T
 	m.val, m.ok = zeroT, false
 	maps[len(p)] = m
+	if !deleted {
+		return false
+	}
+	for _, m := range maps {
+		m.size--
+	}
 
 	// Remove any empty maps.
 	for i := len(p); i > 0; i-- {
@@ -256,9 +392,159 @@ func (m *MapOf[T]) Delete(p key.Path) bool {
 			parent.children.Delete(element)
 		}
 	}
+	return true
+}
+
+// DeleteMatching unregisters the values registered with the paths of the
+// length of pattern that it matches, a wildcard in pattern matching any
+// element including a wildcard, and returns the number of values deleted.
+// A path registered with a wildcard is thus only deleted by a pattern with
+// a wildcard at that position. Its time complexity is linear with respect
+// to the number of paths matched.
+func (m *MapOf[T]) DeleteMatching(pattern key.Path) int {
+	if len(pattern) == 0 {
+		if !m.ok {
+			return 0
+		}
+		var zeroT T
+		m.val, m.ok = zeroT, false
+		m.size--
+		return 1
+	}
+	element, rest := pattern[0], pattern[1:]
+	var deleted int
+	if element.Equal(Wildcard) {
+		if m.wildcard != nil {
+			deleted += m.wildcard.DeleteMatching(rest)
+			if m.wildcard.IsEmpty() {
+				m.wildcard = nil
+			}
+		}
+		var empty []key.Key
+		for it := m.children.Iter(); it.Next(); {
+			deleted += it.Elem().DeleteMatching(rest)
+			if it.Elem().IsEmpty() {
+				empty = append(empty, it.Key())
+			}
+		}
+		for _, k := range empty {
+			m.children.Delete(k)
+		}
+	} else if next, ok := m.children.Get(element); ok {
+		deleted += next.DeleteMatching(rest)
+		if next.IsEmpty() {
+			m.children.Delete(element)
+		}
+	}
+	m.size -= deleted
 	return deleted
 }
 
+// Merge returns a new Map with the paths registered in m or other. The
+// paths registered in both are associated with conflict(v, otherV), v being
+// the value in m and otherV the value in other, or with otherV if conflict
+// is nil. Wildcards are merged as regular path elements: a path with a
+// wildcard is not merged with the paths it matches. m and other are not
+// modified.
+func (m *MapOf[T]) Merge(other *MapOf[T], conflict func(v, otherV T) T) *MapOf[T] {
+	r := merge(m, other, conflict)
+	if r == nil {
+		r = &MapOf[T]{}
+	}
+	return r
+}
+
+func merge[T any](a, b *MapOf[T], conflict func(v, otherV T) T) *MapOf[T] {
+	if a == nil {
+		return b.clone()
+	}
+	if b == nil {
+		return a.clone()
+	}
+	r := &MapOf[T]{}
+	switch {
+	case a.ok && b.ok:
+		r.val, r.ok = b.val, true
+		if conflict != nil {
+			r.val = conflict(a.val, b.val)
+		}
+	case a.ok:
+		r.val, r.ok = a.val, true
+	case b.ok:
+		r.val, r.ok = b.val, true
+	}
+	if r.ok {
+		r.size = 1
+	}
+	r.wildcard = merge(a.wildcard, b.wildcard, conflict)
+	r.size += r.wildcard.Len()
+	for it := a.children.Iter(); it.Next(); {
+		bChild, _ := b.children.Get(it.Key())
+		r.setChild(it.Key(), merge(it.Elem(), bChild, conflict))
+	}
+	for it := b.children.Iter(); it.Next(); {
+		if _, ok := a.children.Get(it.Key()); !ok {
+			r.setChild(it.Key(), it.Elem().clone())
+		}
+	}
+	return r
+}
+
+// Intersect returns a new Map with the paths registered in both m and
+// other, associated with their value in m. Wildcards are intersected as
+// regular path elements: a path with a wildcard only intersects with the
+// same path. m and other are not modified.
+func (m *MapOf[T]) Intersect(other *MapOf[T]) *MapOf[T] {
+	r := &MapOf[T]{}
+	if other == nil {
+		return r
+	}
+	if m.ok && other.ok {
+		r.val, r.ok, r.size = m.val, true, 1
+	}
+	if m.wildcard != nil && other.wildcard != nil {
+		if w := m.wildcard.Intersect(other.wildcard); !w.IsEmpty() {
+			r.wildcard = w
+			r.size += w.size
+		}
+	}
+	for it := m.children.Iter(); it.Next(); {
+		otherChild, ok := other.children.Get(it.Key())
+		if !ok {
+			continue
+		}
+		if child := it.Elem().Intersect(otherChild); !child.IsEmpty() {
+			r.setChild(it.Key(), child)
+		}
+	}
+	return r
+}
+
+// clone returns a deep copy of the nodes of m, the values are not copied.
+func (m *MapOf[T]) clone() *MapOf[T] {
+	if m == nil {
+		return nil
+	}
+	r := &MapOf[T]{val: m.val, ok: m.ok, wildcard: m.wildcard.clone()}
+	if r.ok {
+		r.size = 1
+	}
+	r.size += r.wildcard.Len()
+	for it := m.children.Iter(); it.Next(); {
+		r.setChild(it.Key(), it.Elem().clone())
+	}
+	return r
+}
+
+// setChild sets the child of m at k, adding its size to the size of m.
+func (m *MapOf[T]) setChild(k key.Key, child *MapOf[T]) {
+	if m.children == nil {
+		m.children = newKeyMap[T]()
+	}
+	m.children.Set(k, child)
+	m.size += child.size
+}
+
 func (m *MapOf[T]) String() string {
 	var b strings.Builder
 	m.write(&b, "")
@@ -281,7 +567,7 @@ func (m *MapOf[T]) write(b *strings.Builder, indent string) {
 		children = append(children, it.Key())
 	}
 	sort.Slice(children, func(i, j int) bool {
-		return children[i].String() < children[j].String()
+		return key.Less(children[i], children[j])
 	})
 
 	for _, key := range children {
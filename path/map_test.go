@@ -7,6 +7,7 @@ package path
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/aristanetworks/goarista/key"
@@ -590,6 +591,84 @@ func TestMapDelete(t *testing.T) {
 	}
 }
 
+func TestMapDeleteMatching(t *testing.T) {
+	newMap := func() *Map {
+		m := &Map{}
+		m.Set(key.Path{}, 0)
+		m.Set(key.Path{Wildcard}, 1)
+		m.Set(key.Path{key.New("foo"), key.New("bar")}, 2)
+		m.Set(key.Path{key.New("foo"), Wildcard}, 3)
+		m.Set(key.Path{key.New("foo")}, 4)
+		m.Set(key.Path{key.New("baz"), key.New("bar")}, 5)
+		m.Set(key.Path{key.New("baz"), key.New("qux")}, 6)
+		return m
+	}
+	for _, tc := range []struct {
+		pattern key.Path
+		deleted []int
+		nodes   int
+	}{{
+		pattern: key.Path{key.New("zap")},
+		nodes:   8,
+	}, {
+		pattern: key.Path{},
+		deleted: []int{0},
+		nodes:   8,
+	}, {
+		pattern: key.Path{key.New("foo")},
+		deleted: []int{4},
+		nodes:   8,
+	}, {
+		pattern: key.Path{Wildcard},
+		deleted: []int{1, 4},
+		nodes:   7, // the wildcard node is removed
+	}, {
+		pattern: key.Path{key.New("foo"), key.New("bar")},
+		deleted: []int{2},
+		nodes:   7,
+	}, {
+		pattern: key.Path{Wildcard, key.New("bar")},
+		deleted: []int{2, 5},
+		nodes:   6,
+	}, {
+		pattern: key.Path{key.New("foo"), Wildcard},
+		deleted: []int{2, 3},
+		nodes:   6, // foo still has a value
+	}, {
+		pattern: key.Path{Wildcard, Wildcard},
+		deleted: []int{2, 3, 5, 6},
+		nodes:   3, // baz is removed
+	}} {
+		m := newMap()
+		var before []int
+		for it := m.IterPrefixed(key.Path{}); it.Next(); {
+			before = append(before, it.Value().(int))
+		}
+		if n := m.DeleteMatching(tc.pattern); n != len(tc.deleted) {
+			t.Errorf("%s: expected %d values deleted, got %d", tc.pattern, len(tc.deleted), n)
+		}
+		var after []int
+		for it := m.IterPrefixed(key.Path{}); it.Next(); {
+			after = append(after, it.Value().(int))
+		}
+		var expected []int
+		for _, v := range before {
+			if !slices.Contains(tc.deleted, v) {
+				expected = append(expected, v)
+			}
+		}
+		if !slices.Equal(expected, after) {
+			t.Errorf("%s: expected values %v, got %v", tc.pattern, expected, after)
+		}
+		if m.Len() != len(after) {
+			t.Errorf("%s: expected Len() == %d, got %d", tc.pattern, len(after), m.Len())
+		}
+		if n := countNodes(m); n != tc.nodes {
+			t.Errorf("%s: expected %d nodes, got %d", tc.pattern, tc.nodes, n)
+		}
+	}
+}
+
 func TestMapVisitPrefixes(t *testing.T) {
 	m := Map{}
 	m.Set(key.Path{}, 0)
@@ -680,6 +759,85 @@ func TestMapVisitPrefixed(t *testing.T) {
 	}
 }
 
+func TestMapIter(t *testing.T) {
+	m := MapOf[int]{}
+	m.Set(key.Path{}, 1)
+	m.Set(key.Path{key.New("foo")}, 2)
+	m.Set(key.Path{key.New("foo"), key.New("bar")}, 3)
+	m.Set(key.Path{key.New("foo"), key.New("baz")}, 4)
+	m.Set(key.Path{key.New("foo"), Wildcard}, 5)
+	m.Set(key.Path{key.New("foo"), key.New("bar"), key.New("qux")}, 6)
+	m.Set(key.Path{Wildcard, key.New("bar")}, 7)
+	m.Set(key.Path{key.New("quux")}, 8)
+
+	collect := func(it *MapIterator[int]) []int {
+		var vals []int
+		for it.Next() {
+			vals = append(vals, it.Value())
+		}
+		return vals
+	}
+	for _, tc := range []struct {
+		p        key.Path
+		match    []int
+		prefixed []int
+	}{{
+		p:        key.Path{},
+		match:    []int{1},
+		prefixed: []int{1, 7, 2, 5, 3, 6, 4, 8},
+	}, {
+		p:        key.Path{key.New("foo")},
+		match:    []int{2},
+		prefixed: []int{7, 2, 5, 3, 6, 4},
+	}, {
+		p:        key.Path{key.New("foo"), key.New("bar")},
+		match:    []int{7, 5, 3},
+		prefixed: []int{7, 5, 3, 6},
+	}, {
+		p:        key.Path{key.New("nope")},
+		prefixed: []int{7},
+	}} {
+		// Check the iterators visit the same values as the visitors.
+		var visited []int
+		m.Visit(tc.p, func(v int) error {
+			visited = append(visited, v)
+			return nil
+		})
+		if match := collect(m.Iter(tc.p)); !test.DeepEqual(tc.match, match) ||
+			len(visited) != len(match) {
+			t.Errorf("Iter(%s): expected %v, got %v", tc.p, tc.match, match)
+		}
+		visited = nil
+		m.VisitPrefixed(tc.p, func(v int) error {
+			visited = append(visited, v)
+			return nil
+		})
+		if prefixed := collect(m.IterPrefixed(tc.p)); !test.DeepEqual(tc.prefixed, prefixed) ||
+			len(visited) != len(prefixed) {
+			t.Errorf("IterPrefixed(%s): expected %v, got %v", tc.p, tc.prefixed, prefixed)
+		}
+	}
+
+	// Stop early
+	it := m.IterPrefixed(key.Path{})
+	if !it.Next() || it.Value() != 1 || !it.Next() || it.Value() != 7 {
+		t.Errorf("unexpected first values")
+	}
+}
+
+func BenchmarkMapIterPrefixedFirst(b *testing.B) {
+	m := MapOf[int]{}
+	for i := 0; i < 1000; i++ {
+		m.Set(key.Path{key.New("foo"), key.New(uint32(i))}, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := m.IterPrefixed(key.Path{key.New("foo"), key.New(uint32(42))})
+		it.Next()
+	}
+}
+
 func TestMapVisitChildren(t *testing.T) {
 	m := Map{}
 	m.Set(key.Path{}, 0)
@@ -750,6 +908,63 @@ Child "foo":
 	}
 }
 
+func TestMapMergeIntersect(t *testing.T) {
+	a := MapOf[int]{}
+	a.Set(key.Path{key.New("foo")}, 1)
+	a.Set(key.Path{key.New("foo"), key.New("bar")}, 2)
+	a.Set(key.Path{key.New("foo"), Wildcard}, 3)
+	a.Set(key.Path{key.New("baz")}, 4)
+	b := MapOf[int]{}
+	b.Set(key.Path{key.New("foo")}, 10)
+	b.Set(key.Path{key.New("foo"), key.New("bar"), key.New("qux")}, 20)
+	b.Set(key.Path{key.New("foo"), Wildcard}, 30)
+	b.Set(key.Path{Wildcard}, 40)
+
+	merged := a.Merge(&b, func(v, otherV int) int { return v + otherV })
+	expected := `Child "*":
+  Val: 40
+Child "baz":
+  Val: 4
+Child "foo":
+  Val: 11
+  Child "*":
+    Val: 33
+  Child "bar":
+    Val: 2
+    Child "qux":
+      Val: 20
+`
+	if got := merged.String(); got != expected {
+		t.Errorf("Unexpected merge. Expected:\n\n%s\n\nGot:\n\n%s", expected, got)
+	}
+	if v, _ := a.Merge(&b, nil).Get(key.Path{key.New("foo")}); v != 10 {
+		t.Errorf("Expected the value of other without conflict function, got %d", v)
+	}
+
+	intersected := a.Intersect(&b)
+	expected = `Child "foo":
+  Val: 1
+  Child "*":
+    Val: 3
+`
+	if got := intersected.String(); got != expected {
+		t.Errorf("Unexpected intersection. Expected:\n\n%s\n\nGot:\n\n%s", expected, got)
+	}
+	if !a.Intersect(&MapOf[int]{}).IsEmpty() {
+		t.Error("Expected an empty intersection")
+	}
+
+	// The results do not share nodes with the operands.
+	merged.Set(key.Path{key.New("foo"), key.New("bar"), key.New("new")}, 5)
+	intersected.Set(key.Path{key.New("foo"), Wildcard, key.New("new")}, 6)
+	if _, ok := b.Get(key.Path{key.New("foo"), key.New("bar"), key.New("new")}); ok {
+		t.Error("Merge result shares nodes with other")
+	}
+	if _, ok := a.Get(key.Path{key.New("foo"), Wildcard, key.New("new")}); ok {
+		t.Error("Intersect result shares nodes with m")
+	}
+}
+
 func genWords(count, wordLength int) key.Path {
 	chars := []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 	if count+wordLength > len(chars) {
@@ -783,6 +998,68 @@ func benchmarkPathMap(pathLength, pathDepth int, b *testing.B) {
 	}
 }
 
+func TestMapLenCount(t *testing.T) {
+	m := MapOf[int]{}
+	foo := key.Path{key.New("foo")}
+	fooBar := key.Path{key.New("foo"), key.New("bar")}
+	fooWildcard := key.Path{key.New("foo"), Wildcard}
+	m.Set(key.Path{}, 0)
+	m.Set(foo, 1)
+	m.Set(fooBar, 2)
+	m.Set(fooBar, 3)
+	m.Set(fooWildcard, 4)
+	m.Set(key.Path{key.New("baz"), key.New("qux")}, 5)
+
+	type count struct {
+		p     key.Path
+		count int
+	}
+	checkCounts := func(len int, counts []count) {
+		t.Helper()
+		if m.Len() != len {
+			t.Errorf("Expected Len() == %d, got %d", len, m.Len())
+		}
+		for _, c := range counts {
+			if got := m.Count(c.p); got != c.count {
+				t.Errorf("Expected Count(%s) == %d, got %d", c.p, c.count, got)
+			}
+		}
+	}
+	baz := key.Path{key.New("baz")}
+	checkCounts(5, []count{
+		{key.Path{}, 5},
+		{foo, 3},
+		{fooBar, 1},
+		{fooWildcard, 1},
+		{baz, 1},
+		{key.Path{key.New("baz"), key.New("qux")}, 1},
+		{key.Path{key.New("baz"), Wildcard}, 0},
+		{key.Path{key.New("missing")}, 0},
+	})
+
+	m.Delete(fooBar)
+	m.Delete(fooBar)
+	m.Delete(baz)
+	m.Delete(key.Path{})
+	checkCounts(3, []count{{key.Path{}, 3}, {foo, 2}, {fooBar, 0}, {baz, 1}})
+
+	other := MapOf[int]{}
+	other.Set(foo, 10)
+	other.Set(key.Path{key.New("other")}, 11)
+	if merged := m.Merge(&other, nil); merged.Len() != 4 ||
+		merged.Count(foo) != 2 {
+		t.Errorf("Unexpected merged counts: Len() == %d, Count(/foo) == %d",
+			merged.Len(), merged.Count(foo))
+	}
+	if intersected := m.Intersect(&other); intersected.Len() != 1 {
+		t.Errorf("Unexpected intersected count %d", intersected.Len())
+	}
+	var nilMap *MapOf[int]
+	if nilMap.Len() != 0 {
+		t.Error("Expected a nil map to be empty")
+	}
+}
+
 func BenchmarkPathMap1x25(b *testing.B)  { benchmarkPathMap(1, 25, b) }
 func BenchmarkPathMap10x50(b *testing.B) { benchmarkPathMap(10, 25, b) }
 func BenchmarkPathMap20x50(b *testing.B) { benchmarkPathMap(20, 25, b) }
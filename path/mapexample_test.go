@@ -76,6 +76,25 @@ func ExampleMap_VisitPrefixed() {
 	// Ouput: [2 3 4]
 }
 
+func ExampleMapOf_IterPrefixed() {
+	var m path.MapOf[int]
+	m.Set(path.New("foo"), 1)
+	m.Set(path.New("foo", "bar"), 2)
+	m.Set(path.New("foo", "bar", "baz"), 3)
+	m.Set(path.New("foo", path.Wildcard), 4)
+	m.Set(path.New("foo", "qux"), 5)
+
+	// Find the first value registered under foo greater than 3
+	for it := m.IterPrefixed(path.New("foo")); it.Next(); {
+		if it.Value() > 3 {
+			fmt.Println(it.Value())
+			break
+		}
+	}
+
+	// Output: 4
+}
+
 func ExampleMap_Get() {
 	var m path.Map
 	m.Set(path.New("foo", "bar"), 1)
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package path
+
+import (
+	"github.com/aristanetworks/goarista/key"
+)
+
+// RefCountedMap associates paths to values like a MapOf, counting the
+// registrations of each value with each path. It is meant for the
+// subscription managers multiplexing many clients on shared
+// subscriptions: the first registration of a value with a path activates
+// it, e.g. subscribes to the path, and it stays active until it is
+// unregistered as many times as it was registered. Several values can be
+// registered with a path. The zero value is an empty map ready to use.
+type RefCountedMap[T comparable] struct {
+	m MapOf[*refCounts[T]]
+	// size is the number of values active.
+	size int
+}
+
+// refCounts are the values registered with a path, in the order they
+// were first registered.
+type refCounts[T comparable] struct {
+	vals   []T
+	counts []int
+}
+
+func (r *refCounts[T]) index(v T) int {
+	for i, val := range r.vals {
+		if val == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// Set registers a value v with a path p, and returns true if v was not
+// already registered with p, i.e. the registration became active.
+func (r *RefCountedMap[T]) Set(p key.Path, v T) bool {
+	counts, ok := r.m.Get(p)
+	if !ok {
+		counts = &refCounts[T]{}
+		r.m.Set(p, counts)
+	}
+	if i := counts.index(v); i >= 0 {
+		counts.counts[i]++
+		return false
+	}
+	counts.vals = append(counts.vals, v)
+	counts.counts = append(counts.counts, 1)
+	r.size++
+	return true
+}
+
+// Delete unregisters a value v from a path p once, and returns true if v
+// is no longer registered with p, i.e. the registration became inactive.
+// It returns false if v was registered more times than it was
+// unregistered, or if v was not registered with p.
+func (r *RefCountedMap[T]) Delete(p key.Path, v T) bool {
+	counts, ok := r.m.Get(p)
+	if !ok {
+		return false
+	}
+	i := counts.index(v)
+	if i < 0 {
+		return false
+	}
+	if counts.counts[i]--; counts.counts[i] > 0 {
+		return false
+	}
+	counts.vals = append(counts.vals[:i], counts.vals[i+1:]...)
+	counts.counts = append(counts.counts[:i], counts.counts[i+1:]...)
+	if len(counts.vals) == 0 {
+		r.m.Delete(p)
+	}
+	r.size--
+	return true
+}
+
+// RefCount returns the number of times v is registered with p.
+func (r *RefCountedMap[T]) RefCount(p key.Path, v T) int {
+	counts, ok := r.m.Get(p)
+	if !ok {
+		return 0
+	}
+	if i := counts.index(v); i >= 0 {
+		return counts.counts[i]
+	}
+	return 0
+}
+
+// Get returns the values registered with an exact match of a path p, in
+// the order they were first registered.
+func (r *RefCountedMap[T]) Get(p key.Path) []T {
+	counts, ok := r.m.Get(p)
+	if !ok {
+		return nil
+	}
+	return append([]T(nil), counts.vals...)
+}
+
+// Visit calls fn once for every value registered with a match of a path p,
+// like MapOf.Visit.
+func (r *RefCountedMap[T]) Visit(p key.Path, fn func(v T) error) error {
+	return r.m.Visit(p, visitRefCounts(fn))
+}
+
+// VisitPrefixes calls fn once for every value registered with a prefix of
+// a path p, like MapOf.VisitPrefixes.
+func (r *RefCountedMap[T]) VisitPrefixes(p key.Path, fn func(v T) error) error {
+	return r.m.VisitPrefixes(p, visitRefCounts(fn))
+}
+
+// VisitPrefixed calls fn once for every value registered with a path
+// prefixed by p, like MapOf.VisitPrefixed.
+func (r *RefCountedMap[T]) VisitPrefixed(p key.Path, fn func(v T) error) error {
+	return r.m.VisitPrefixed(p, visitRefCounts(fn))
+}
+
+func visitRefCounts[T comparable](fn func(v T) error) func(*refCounts[T]) error {
+	return func(counts *refCounts[T]) error {
+		for _, v := range counts.vals {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Len returns the number of values registered with a path, each value
+// counted once per path whatever its number of registrations.
+func (r *RefCountedMap[T]) Len() int {
+	return r.size
+}
+
+// IsEmpty returns true if no value is registered, false otherwise.
+func (r *RefCountedMap[T]) IsEmpty() bool {
+	return r.size == 0
+}
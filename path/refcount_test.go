@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package path
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aristanetworks/goarista/key"
+)
+
+func TestRefCountedMap(t *testing.T) {
+	var m RefCountedMap[string]
+	foo := New("foo")
+	fooBar := New("foo", "bar")
+	wildBar := New(Wildcard, "bar")
+
+	if !m.Set(foo, "a") {
+		t.Error("expected the first registration of a to be active")
+	}
+	if m.Set(foo, "a") {
+		t.Error("expected the second registration of a not to activate it")
+	}
+	if !m.Set(foo, "b") || !m.Set(wildBar, "a") || !m.Set(fooBar, "c") {
+		t.Error("expected the registrations to be active")
+	}
+	if n := m.RefCount(foo, "a"); n != 2 {
+		t.Errorf("expected a to be registered twice with foo, got %d", n)
+	}
+	if n := m.Len(); n != 4 {
+		t.Errorf("expected 4 values, got %d", n)
+	}
+	if vals := m.Get(foo); !slices.Equal(vals, []string{"a", "b"}) {
+		t.Errorf("expected [a b] registered with foo, got %v", vals)
+	}
+
+	var vals []string
+	collect := func(v string) error {
+		vals = append(vals, v)
+		return nil
+	}
+	if err := m.Visit(fooBar, collect); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(vals)
+	if !slices.Equal(vals, []string{"a", "c"}) {
+		t.Errorf("expected to visit [a c] for foo/bar, got %v", vals)
+	}
+	vals = nil
+	if err := m.VisitPrefixes(fooBar, collect); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(vals)
+	if !slices.Equal(vals, []string{"a", "a", "b", "c"}) {
+		t.Errorf("expected to visit [a a b c] for the prefixes of foo/bar, got %v", vals)
+	}
+	vals = nil
+	if err := m.VisitPrefixed(foo, collect); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(vals)
+	// The wildcard of */bar matches foo.
+	if !slices.Equal(vals, []string{"a", "a", "b", "c"}) {
+		t.Errorf("expected to visit [a a b c] for the paths prefixed by foo, got %v", vals)
+	}
+	errStop := errors.New("stop")
+	if err := m.Visit(foo, func(string) error { return errStop }); err != errStop {
+		t.Errorf("expected the error of the visitor, got %v", err)
+	}
+
+	if m.Delete(foo, "a") {
+		t.Error("expected a to stay active after its first unregistration")
+	}
+	if !m.Delete(foo, "a") {
+		t.Error("expected a to become inactive after its last unregistration")
+	}
+	if m.Delete(foo, "a") || m.Delete(foo, "z") || m.Delete(New("nope"), "a") {
+		t.Error("unexpected deletion of a value not registered")
+	}
+	if vals := m.Get(foo); !slices.Equal(vals, []string{"b"}) {
+		t.Errorf("expected [b] registered with foo, got %v", vals)
+	}
+	for _, del := range []struct {
+		p key.Path
+		v string
+	}{{foo, "b"}, {wildBar, "a"}, {fooBar, "c"}} {
+		if !m.Delete(del.p, del.v) {
+			t.Errorf("expected %s to become inactive for %s", del.v, del.p)
+		}
+	}
+	if !m.IsEmpty() || !m.m.IsEmpty() {
+		t.Errorf("expected the map to be empty, got %d values:\n%s", m.Len(), &m.m)
+	}
+}
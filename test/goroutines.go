@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package test
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// GoroutineLeakTimeout is how long CheckGoroutines waits for the
+// goroutines started by a test to exit before reporting them as leaked.
+var GoroutineLeakTimeout = 5 * time.Second
+
+// ignoredGoroutines are the functions in the stacks of the goroutines
+// started lazily by the runtime and the standard library, which are
+// never leaks.
+var ignoredGoroutines = []string{
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+	"(*loggingT).flushDaemon",
+	"runtime/pprof.profileWriter",
+}
+
+// CheckGoroutines snapshots the running goroutines and fails t when it
+// ends if goroutines started during the test are still running, logging
+// their stacks. The goroutines whose stack contains one of ignore, such
+// as the name of a function, are not reported. Goroutines are given
+// GoroutineLeakTimeout to exit after the test, including its deferred
+// calls, returns. It must not be used in parallel tests, as the
+// goroutines of the other tests would be reported.
+func CheckGoroutines(t testing.TB, ignore ...string) {
+	t.Helper()
+	before := goroutines()
+	t.Cleanup(func() {
+		t.Helper()
+		var leaked []string
+		deadline := time.Now().Add(GoroutineLeakTimeout)
+		for {
+			leaked = leakedGoroutines(before, ignore)
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if len(leaked) > 0 {
+			t.Errorf("%d goroutines leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+	})
+}
+
+// leakedGoroutines returns the stacks of the goroutines not running
+// before, sorted by goroutine ID.
+func leakedGoroutines(before map[uint64]string, ignore []string) []string {
+	var ids []uint64
+	after := goroutines()
+	for id, stack := range after {
+		if _, ok := before[id]; ok || containsAny(stack, ignoredGoroutines) ||
+			containsAny(stack, ignore) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	leaked := make([]string, len(ids))
+	for i, id := range ids {
+		leaked[i] = after[id]
+	}
+	return leaked
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns the stacks of all the goroutines, by goroutine ID.
+func goroutines() map[uint64]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := map[uint64]string{}
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		var id uint64
+		if _, err := fmt.Sscanf(stack, "goroutine %d ", &id); err == nil {
+			stacks[id] = strings.TrimSpace(stack)
+		}
+	}
+	return stacks
+}
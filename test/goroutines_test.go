@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTB records the errors of a test and runs its cleanup functions
+// on demand.
+type recordingTB struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (r *recordingTB) Helper()           {}
+func (r *recordingTB) Cleanup(fn func()) { r.cleanups = append(r.cleanups, fn) }
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) end() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func leakingFunction(stop <-chan struct{}) {
+	<-stop
+}
+
+func TestCheckGoroutines(t *testing.T) {
+	defer func(timeout time.Duration) { GoroutineLeakTimeout = timeout }(GoroutineLeakTimeout)
+	GoroutineLeakTimeout = 50 * time.Millisecond
+
+	// A goroutine still running when the test ends is reported.
+	stop := make(chan struct{})
+	tb := &recordingTB{TB: t}
+	CheckGoroutines(tb)
+	go leakingFunction(stop)
+	tb.end()
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "1 goroutines leaked") ||
+		!strings.Contains(tb.errors[0], "test.leakingFunction") {
+		t.Errorf("expected a leak to be reported, got %q", tb.errors)
+	}
+
+	// Unless it is ignored, or it exits within GoroutineLeakTimeout.
+	tb = &recordingTB{TB: t}
+	CheckGoroutines(tb, "test.leakingFunction")
+	go leakingFunction(stop)
+	tb.end()
+	if len(tb.errors) != 0 {
+		t.Errorf("expected no leak to be reported, got %q", tb.errors)
+	}
+	close(stop)
+
+	tb = &recordingTB{TB: t}
+	CheckGoroutines(tb)
+	go time.Sleep(10 * time.Millisecond)
+	tb.end()
+	if len(tb.errors) != 0 {
+		t.Errorf("expected no leak to be reported, got %q", tb.errors)
+	}
+}